@@ -5,6 +5,7 @@ import (
 	"os"
 
 	"mcp-server-go/internal/core"
+	"mcp-server-go/internal/i18n"
 	"mcp-server-go/internal/services"
 	"mcp-server-go/internal/tools"
 
@@ -25,18 +26,28 @@ func main() {
 	// 🚀 [LifeCycle] 探测并尝试自动绑定项目
 	projectRoot := core.DetectProjectRoot()
 	if projectRoot != "" {
-		fmt.Fprintf(os.Stderr, "[MCP-Go] 已锁定项目根目录: %s\n", projectRoot)
+		fmt.Fprint(os.Stderr, i18n.T("[MCP-Go] 已锁定项目根目录: %s\n", projectRoot))
 		m, err := core.NewMemoryLayer(projectRoot)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "[MCP-Go][ERROR] 记忆层初始化受阻: %v\n", err)
+			fmt.Fprint(os.Stderr, i18n.T("[MCP-Go][ERROR] 记忆层初始化受阻: %v\n", err))
 		} else {
 			sm.Memory = m
 			sm.ProjectRoot = projectRoot
-			fmt.Fprintf(os.Stderr, "[MCP-Go] 记忆层（SSOT）与项目上下文已就绪。\n")
+			fmt.Fprint(os.Stderr, i18n.T("[MCP-Go] 记忆层（SSOT）与项目上下文已就绪。\n"))
 
 		}
 	} else {
-		fmt.Fprintf(os.Stderr, "[MCP-Go][WARN] 无法探测项目根目录，请检查环境变量或在项目目录下运行。\n")
+		fmt.Fprint(os.Stderr, i18n.T("[MCP-Go][WARN] 无法探测项目根目录，请检查环境变量或在项目目录下运行。\n"))
+	}
+
+	// 加载并校验意图规则（内置 < ~/.mpm < .mcp-config），无效文件跳过
+	for _, w := range sm.ReloadIntentRules() {
+		fmt.Fprint(os.Stderr, i18n.T("[MCP-Go][WARN] 意图规则: %s\n", w))
+	}
+
+	// 加载并校验项目配置 mpm.yaml（内置 < ~/.mpm < .mcp-config），无效文件跳过；同时确定界面语言，须在注册工具前完成
+	for _, w := range sm.ReloadConfig() {
+		fmt.Fprint(os.Stderr, i18n.T("[MCP-Go][WARN] 项目配置: %s\n", w))
 	}
 
 	// 注：HUD 自动启动已移至 initialize_project 工具，不再在 server 启动时触发
@@ -56,10 +67,10 @@ func main() {
 	tools.RegisterEnhanceTools(s, sm)          // 增强工具 (prompt_enhance, persona)
 	tools.RegisterDocTools(s, sm, ai)          // 文档工具 (wiki_writer)
 
-	fmt.Fprintf(os.Stderr, "[MCP-Go] MyProjectManager 正在启动...\n")
+	fmt.Fprint(os.Stderr, i18n.T("[MCP-Go] MyProjectManager 正在启动...\n"))
 
 	if err := server.ServeStdio(s); err != nil {
-		fmt.Fprintf(os.Stderr, "服务运行错误: %v\n", err)
+		fmt.Fprint(os.Stderr, i18n.T("服务运行错误: %v\n", err))
 		os.Exit(1)
 	}
 }
@@ -156,6 +156,29 @@ func (m *DatabaseManager) healSchema() error {
 			summary TEXT,
 			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
 		)`,
+		`CREATE TABLE IF NOT EXISTS analysis_briefings (
+			briefing_id TEXT PRIMARY KEY,
+			intent TEXT,
+			directive TEXT,
+			state TEXT,
+			chain_id TEXT DEFAULT '',
+			created_at DATETIME,
+			updated_at DATETIME,
+			expires_at DATETIME
+		)`,
+		`CREATE TABLE IF NOT EXISTS constraint_rules (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			rule_name TEXT UNIQUE,
+			category TEXT,
+			rule_definition TEXT,
+			description TEXT,
+			priority INTEGER DEFAULT 0,
+			file_patterns TEXT,
+			expert_scope TEXT,
+			is_active INTEGER DEFAULT 1,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
 	}
 
 	for _, s := range schemas {
@@ -169,6 +192,7 @@ func (m *DatabaseManager) healSchema() error {
 		"CREATE INDEX IF NOT EXISTS idx_memos_entity ON memos(entity)",
 		"CREATE INDEX IF NOT EXISTS idx_memos_category ON memos(category)",
 		"CREATE INDEX IF NOT EXISTS idx_memos_timestamp ON memos(timestamp DESC)",
+		"CREATE INDEX IF NOT EXISTS idx_briefings_updated ON analysis_briefings(updated_at DESC)",
 	}
 	for _, idx := range indexes {
 		if _, err := m.db.Exec(idx); err != nil {
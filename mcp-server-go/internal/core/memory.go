@@ -5,7 +5,9 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"mcp-server-go/internal/i18n"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -15,10 +17,13 @@ import (
 
 // MemoryLayer 记忆层 (SSOT)
 type MemoryLayer struct {
-	dbManager   *DatabaseManager
-	projectRoot string
+	dbManager    *DatabaseManager
+	projectRoot  string
+	devLogWindow func() int // dev-log.md 同步的 memo 条数，未设置时为 defaultDevLogWindow
 }
 
+const defaultDevLogWindow = 100
+
 // NewMemoryLayer 创建记忆层实例
 func NewMemoryLayer(projectRoot string) (*MemoryLayer, error) {
 	mgr, err := GetDBForProject(projectRoot)
@@ -37,6 +42,11 @@ func NewMemoryLayer(projectRoot string) (*MemoryLayer, error) {
 	return ml, nil
 }
 
+// SetDevLogWindow 设置 dev-log.md 同步条数的来源 (每次同步时读取，配置变化即时生效)
+func (m *MemoryLayer) SetDevLogWindow(fn func() int) {
+	m.devLogWindow = fn
+}
+
 // ========== Task Management ==========
 
 // CreateTask 创建任务记录
@@ -299,6 +309,25 @@ func (m *MemoryLayer) AddMemos(ctx context.Context, items []Memo) ([]int64, erro
 	return ids, nil
 }
 
+// ListMemoPaths 返回备忘录中出现过的全部文件路径 (去重)
+func (m *MemoryLayer) ListMemoPaths(ctx context.Context) ([]string, error) {
+	rows, err := m.dbManager.Query("SELECT DISTINCT path FROM memos WHERE path IS NOT NULL AND path != '' AND path != '-'")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var paths []string
+	for rows.Next() {
+		var p string
+		if err := rows.Scan(&p); err != nil {
+			return nil, err
+		}
+		paths = append(paths, p)
+	}
+	return paths, rows.Err()
+}
+
 // SearchMemos 搜索备忘录
 func (m *MemoryLayer) SearchMemos(ctx context.Context, keywords string, category string, limit int) ([]Memo, error) {
 	query := "SELECT id, category, entity, act, path, content, session_id, timestamp FROM memos WHERE 1=1"
@@ -355,10 +384,16 @@ func (m *MemoryLayer) SearchMemos(ctx context.Context, keywords string, category
 
 // SyncDevLog 同步更新 dev-log.md
 func (m *MemoryLayer) SyncDevLog() {
+	window := defaultDevLogWindow
+	if m.devLogWindow != nil {
+		if n := m.devLogWindow(); n > 0 {
+			window = n
+		}
+	}
 	rows, err := m.dbManager.Query(`
 		SELECT 
 			id, content, timestamp, category, entity, act, path, session_id 
-		FROM memos ORDER BY id DESC LIMIT 100`)
+		FROM memos ORDER BY id DESC LIMIT ?`, window)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "[SyncDevLog] Query failed: %v\n", err)
 		return
@@ -651,6 +686,36 @@ func (m *MemoryLayer) GetState(ctx context.Context, key string) (string, error)
 	return value, err
 }
 
+// StateEntry 系统状态条目
+type StateEntry struct {
+	Key       string
+	Value     string
+	Category  string
+	UpdatedAt time.Time
+}
+
+// ListStates 按分类列出系统状态（最近更新的在前）
+func (m *MemoryLayer) ListStates(ctx context.Context, category string) ([]StateEntry, error) {
+	rows, err := m.dbManager.Query(`
+		SELECT key, value, category, updated_at
+		FROM system_state WHERE category = ?
+		ORDER BY updated_at DESC`, category)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []StateEntry
+	for rows.Next() {
+		var e StateEntry
+		if err := rows.Scan(&e.Key, &e.Value, &e.Category, &e.UpdatedAt); err != nil {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
 // ========== Hook Management ==========
 
 // Hook 待办钩子
@@ -741,3 +806,193 @@ func (m *MemoryLayer) ReleaseHook(ctx context.Context, hookID string, resultSumm
 	)
 	return err
 }
+
+// ========== Analysis Briefings ==========
+
+// briefingTimeLayout 与 CURRENT_TIMESTAMP 一致的 UTC 文本格式
+const briefingTimeLayout = "2006-01-02 15:04:05"
+
+// SaveBriefing 保存或更新分析简报（保留原有的创建时间与任务链关联）
+func (m *MemoryLayer) SaveBriefing(ctx context.Context, b *AnalysisBriefing) error {
+	now := time.Now().UTC()
+	if b.CreatedAt.IsZero() {
+		b.CreatedAt = now
+	}
+	b.UpdatedAt = now
+	query := `INSERT INTO analysis_briefings (
+		briefing_id, intent, directive, state, chain_id, created_at, updated_at, expires_at
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	ON CONFLICT(briefing_id) DO UPDATE SET
+		intent=excluded.intent,
+		directive=excluded.directive,
+		state=excluded.state,
+		chain_id=CASE WHEN excluded.chain_id != '' THEN excluded.chain_id ELSE analysis_briefings.chain_id END,
+		updated_at=excluded.updated_at,
+		expires_at=excluded.expires_at`
+	_, err := m.dbManager.Exec(query,
+		b.ID, b.Intent, b.Directive, b.State, b.ChainID,
+		b.CreatedAt.UTC().Format(briefingTimeLayout),
+		b.UpdatedAt.Format(briefingTimeLayout),
+		b.ExpiresAt.UTC().Format(briefingTimeLayout),
+	)
+	return err
+}
+
+// GetBriefing 获取分析简报，不存在时返回 nil（过期与否由调用方判断）
+func (m *MemoryLayer) GetBriefing(ctx context.Context, id string) (*AnalysisBriefing, error) {
+	rows, err := m.dbManager.Query(briefingSelect+" WHERE briefing_id = ?", id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	list := scanBriefings(rows)
+	if len(list) == 0 {
+		return nil, nil
+	}
+	return &list[0], nil
+}
+
+// ListBriefings 列出分析简报（最近更新的在前）
+func (m *MemoryLayer) ListBriefings(ctx context.Context, limit int) ([]AnalysisBriefing, error) {
+	rows, err := m.dbManager.Query(briefingSelect+" ORDER BY updated_at DESC LIMIT ?", limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanBriefings(rows), nil
+}
+
+// LinkBriefing 将简报关联到执行它的任务链
+func (m *MemoryLayer) LinkBriefing(ctx context.Context, id, chainID string) error {
+	res, err := m.dbManager.Exec("UPDATE analysis_briefings SET chain_id = ?, updated_at = ? WHERE briefing_id = ?",
+		chainID, time.Now().UTC().Format(briefingTimeLayout), id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errors.New(i18n.T("分析简报 %s 不存在", id))
+	}
+	return nil
+}
+
+// PurgeExpiredBriefings 删除已过期且未关联任务链的简报，返回删除数
+func (m *MemoryLayer) PurgeExpiredBriefings(ctx context.Context) (int, error) {
+	rows, err := m.dbManager.Query(briefingSelect + " WHERE chain_id = ''")
+	if err != nil {
+		return 0, err
+	}
+	list := scanBriefings(rows)
+	rows.Close()
+
+	now := time.Now()
+	purged := 0
+	for _, b := range list {
+		if !b.Expired(now) {
+			continue
+		}
+		if _, err := m.dbManager.Exec("DELETE FROM analysis_briefings WHERE briefing_id = ?", b.ID); err != nil {
+			return purged, err
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+const briefingSelect = `SELECT briefing_id, intent, directive, state, COALESCE(chain_id, ''),
+	created_at, updated_at, expires_at FROM analysis_briefings`
+
+func scanBriefings(rows *sql.Rows) []AnalysisBriefing {
+	var list []AnalysisBriefing
+	for rows.Next() {
+		var b AnalysisBriefing
+		var created, updated, expires string
+		if err := rows.Scan(&b.ID, &b.Intent, &b.Directive, &b.State, &b.ChainID, &created, &updated, &expires); err != nil {
+			continue
+		}
+		b.CreatedAt = parseBriefingTime(created)
+		b.UpdatedAt = parseBriefingTime(updated)
+		b.ExpiresAt = parseBriefingTime(expires)
+		list = append(list, b)
+	}
+	return list
+}
+
+func parseBriefingTime(raw string) time.Time {
+	for _, layout := range []string{briefingTimeLayout, time.RFC3339Nano} {
+		if t, err := time.ParseInLocation(layout, raw, time.UTC); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// ========== Constraint Rules ==========
+
+// SaveConstraintRule 按规则名新增或更新约束规则，返回规则 ID
+func (m *MemoryLayer) SaveConstraintRule(ctx context.Context, r ConstraintRule) (int64, error) {
+	query := `INSERT INTO constraint_rules (
+		rule_name, category, rule_definition, description, priority,
+		file_patterns, expert_scope, is_active, created_at, updated_at
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+	ON CONFLICT(rule_name) DO UPDATE SET
+		category=excluded.category,
+		rule_definition=excluded.rule_definition,
+		description=excluded.description,
+		priority=excluded.priority,
+		file_patterns=excluded.file_patterns,
+		expert_scope=excluded.expert_scope,
+		is_active=excluded.is_active,
+		updated_at=CURRENT_TIMESTAMP`
+	if _, err := m.dbManager.Exec(query,
+		r.RuleName, r.Category, r.RuleDefinition, r.Description, r.Priority,
+		r.FilePatterns, r.ExpertScope, r.IsActive,
+	); err != nil {
+		return 0, err
+	}
+	var id int64
+	err := m.dbManager.QueryRow("SELECT id FROM constraint_rules WHERE rule_name = ?", r.RuleName).Scan(&id)
+	return id, err
+}
+
+// ListConstraintRules 列出约束规则（优先级高的在前）
+func (m *MemoryLayer) ListConstraintRules(ctx context.Context, activeOnly bool) ([]ConstraintRule, error) {
+	query := `SELECT id, rule_name, COALESCE(category, ''), COALESCE(rule_definition, ''),
+		COALESCE(description, ''), COALESCE(priority, 0), COALESCE(file_patterns, ''),
+		COALESCE(expert_scope, ''), is_active, created_at, updated_at
+		FROM constraint_rules`
+	if activeOnly {
+		query += " WHERE is_active = 1"
+	}
+	query += " ORDER BY priority DESC, id ASC"
+
+	rows, err := m.dbManager.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rules []ConstraintRule
+	for rows.Next() {
+		var r ConstraintRule
+		if err := rows.Scan(&r.ID, &r.RuleName, &r.Category, &r.RuleDefinition,
+			&r.Description, &r.Priority, &r.FilePatterns, &r.ExpertScope, &r.IsActive,
+			&r.CreatedAt, &r.UpdatedAt); err != nil {
+			continue
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+// SetConstraintRuleActive 启用或停用约束规则
+func (m *MemoryLayer) SetConstraintRuleActive(ctx context.Context, ruleName string, active bool) error {
+	res, err := m.dbManager.Exec("UPDATE constraint_rules SET is_active = ?, updated_at = CURRENT_TIMESTAMP WHERE rule_name = ?",
+		active, ruleName)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errors.New(i18n.T("约束规则 %s 不存在", ruleName))
+	}
+	return nil
+}
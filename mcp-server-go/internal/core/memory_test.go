@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestMemoryLayer_AddMemos(t *testing.T) {
@@ -60,3 +61,49 @@ func TestMemoryLayer_AddMemos(t *testing.T) {
 		t.Errorf("Expected Entity 'Unit Test', got %s", results[0].Entity)
 	}
 }
+
+func TestMemoryLayer_Briefings(t *testing.T) {
+	ml, err := NewMemoryLayer(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create MemoryLayer: %v", err)
+	}
+	ctx := context.Background()
+
+	live := &AnalysisBriefing{ID: "analyze_1", Intent: "DEBUG", Directive: "修复登录", State: `{}`, ExpiresAt: time.Now().Add(time.Hour)}
+	stale := &AnalysisBriefing{ID: "analyze_2", Intent: "DEVELOP", Directive: "旧任务", State: `{}`, ExpiresAt: time.Now().Add(-time.Hour)}
+	linked := &AnalysisBriefing{ID: "analyze_3", Intent: "REFACTOR", Directive: "拆分模块", State: `{}`, ExpiresAt: time.Now().Add(-time.Hour)}
+	for _, b := range []*AnalysisBriefing{live, stale, linked} {
+		if err := ml.SaveBriefing(ctx, b); err != nil {
+			t.Fatalf("SaveBriefing failed: %v", err)
+		}
+	}
+	if err := ml.LinkBriefing(ctx, "analyze_3", "chain_x"); err != nil {
+		t.Fatalf("LinkBriefing failed: %v", err)
+	}
+	if err := ml.LinkBriefing(ctx, "missing", "chain_x"); err == nil {
+		t.Error("LinkBriefing should fail for unknown briefing")
+	}
+
+	got, err := ml.GetBriefing(ctx, "analyze_1")
+	if err != nil || got == nil || got.Intent != "DEBUG" || got.Expired(time.Now()) {
+		t.Fatalf("GetBriefing = %+v, %v", got, err)
+	}
+
+	// 重新保存不应清掉任务链关联
+	linkedGot, _ := ml.GetBriefing(ctx, "analyze_3")
+	if err := ml.SaveBriefing(ctx, &AnalysisBriefing{ID: "analyze_3", Intent: "REFACTOR", State: `{}`, ExpiresAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := ml.GetBriefing(ctx, "analyze_3"); again.ChainID != "chain_x" || !again.CreatedAt.Equal(linkedGot.CreatedAt) {
+		t.Errorf("re-save lost link or created_at: %+v", again)
+	}
+
+	purged, err := ml.PurgeExpiredBriefings(ctx)
+	if err != nil || purged != 1 {
+		t.Fatalf("PurgeExpiredBriefings = %d, %v", purged, err)
+	}
+	list, _ := ml.ListBriefings(ctx, 10)
+	if len(list) != 2 {
+		t.Errorf("expected live and linked briefings to remain, got %+v", list)
+	}
+}
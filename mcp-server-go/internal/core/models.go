@@ -59,6 +59,23 @@ type KnownFact struct {
 	CreatedAt time.Time `db:"created_at"`
 }
 
+// AnalysisBriefing manager_analyze 的分析简报（state 为第一步结果的 JSON）
+type AnalysisBriefing struct {
+	ID        string    `db:"briefing_id"`
+	Intent    string    `db:"intent"`
+	Directive string    `db:"directive"`
+	State     string    `db:"state"`    // JSON string
+	ChainID   string    `db:"chain_id"` // 执行该简报的任务链
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+	ExpiresAt time.Time `db:"expires_at"`
+}
+
+// Expired 已过期（关联了任务链的简报作为执行记录保留，不过期）
+func (b AnalysisBriefing) Expired(now time.Time) bool {
+	return b.ChainID == "" && now.After(b.ExpiresAt)
+}
+
 // ConstraintRule 约束规则
 type ConstraintRule struct {
 	ID             int64     `db:"id"`
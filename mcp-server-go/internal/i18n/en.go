@@ -0,0 +1,1598 @@
+package i18n
+
+// en 内置英文目录。key 为中文原文或 "tool.<name>"；新增 i18n.T / i18n.Desc 调用时需同步补充，
+// i18n_test 会扫描调用点检查缺失。
+var en = map[string]string{
+	// ==================== 工具描述 ====================
+
+	"tool.code_impact": `code_impact - Impact analysis for code changes
+
+Purpose:
+  Find what is affected when a function or class changes, and which places must change with it.
+
+Parameters:
+  symbol_name (required)
+    Symbol to analyze (function or class name)
+    Note: must be an exact code symbol; string search is not supported
+
+  direction (default: backward)
+    - backward: who calls me (upstream impact)
+    - forward: whom I call (downstream impact)
+    - both: both directions
+
+Returns:
+  - Risk level (low/medium/high)
+  - Direct callers (first 10)
+  - Number of indirect callers
+  - Change checklist
+
+Example:
+  code_impact(symbol_name="Login", direction="backward")
+    -> who calls Login
+
+Triggers:
+  "mpm 影响", "mpm 依赖", "mpm impact"`,
+
+	"tool.project_map": `project_map - Project navigator (when you don't know where the code is)
+
+Purpose:
+  [Big picture] Lost, or not sure which file to change? Use me for a map with navigation.
+
+Decision guide:
+  level (default: symbols)
+    - New to the project / want the architecture? -> "structure" (directory tree only)
+    - Looking for code / about to change it? -> "symbols" (functions and classes in detail)
+
+  scope (optional)
+    Defaults to the whole project (may be long). Prefer the directory you care about.
+
+  delta_from (optional)
+    Add the complexity trend: "previous" compares with the previous snapshot, or a snapshot ID (see metrics_delta)
+
+  format (default: markdown)
+    - markdown: human-readable map + complexity heat map
+    - json: structured map (schema=project_map/v1), directory -> file -> symbol, with complexity grades and metrics
+    - mermaid: flowchart, directories as subgraphs, colored by complexity
+
+  cursor / page_size (optional)
+    Large projects are paged by file. When the result has next_cursor, pass it back (other parameters unchanged) for the next page.
+
+Returns:
+  Project map with complexity notes; next_cursor when more pages remain.
+
+Triggers:
+  "mpm 地图", "mpm 结构", "mpm map"`,
+
+	"tool.metrics_delta": `metrics_delta - Complexity trend comparison
+
+Purpose:
+  Each index run records a snapshot when metrics change (time + Git revision + complexity per symbol/directory).
+  Compare two snapshots to see whether a module is getting more complex and whether a refactor really helped.
+
+Parameters:
+  mode (default: diff)
+    - diff: compare two snapshots
+    - list: list recent snapshots
+  from / to (optional)
+    Snapshot IDs. Defaults: to=latest, from=the one before to
+  scope (optional)
+    Limit to a directory or file
+
+Returns:
+  Added/removed symbols, complexity increases/decreases, new hotspots (>=50), directory-level changes.
+
+Triggers:
+  "mpm 趋势", "mpm 复杂度变化", "mpm delta"`,
+
+	"tool.structure_diff": `structure_diff - Structural changes (what changed since the last session)
+
+Purpose:
+  Back after a few days and not sure which modules/files/public symbols changed? Use me.
+  Every initialize_project records a symbol snapshot; I compare it with the current code.
+
+Parameters:
+  mode (default: diff)
+    - diff: compare the baseline snapshot with the current code
+    - list: list recorded structure snapshots
+  since (optional)
+    Baseline snapshot ID, or a date ("2026-10-01" / "2026-10-01 18:00") to use the last snapshot before it.
+    Empty = the snapshot taken before this session was initialized
+  scope (optional)
+    Limit to a directory or file
+  public_only (default: false)
+    Public symbols only
+
+Returns:
+  Added/removed files; added/removed/moved/re-signed symbols; a per-directory summary.
+
+Triggers:
+  "mpm 变化", "mpm 改了什么", "mpm changes"`,
+
+	"tool.refactor_preview": `refactor_preview - Rename/move preview (read-only, produces a patch)
+
+Purpose:
+  Before renaming a widely used function or moving it to another file, review the full patch.
+  No files are modified.
+
+Parameters:
+  symbol_name (required)
+    Symbol to refactor
+  new_name (optional)
+    New name
+  destination (optional)
+    Target file relative path (moves the definition; can be combined with new_name)
+  file (optional)
+    File of the definition when the name is defined in several places
+  certain_only (default: false)
+    Only rewrite references confirmed by the AST
+
+Returns:
+  - References marked certain (AST: definition + call sites) / possible (text match only)
+  - A unified diff (ready for git apply)
+
+Triggers:
+  "mpm 重命名", "mpm 移动", "mpm rename"`,
+
+	"tool.config": `config - Project configuration (thresholds, ignored directories and limits)
+
+Purpose:
+  Show or reload mpm.yaml. Layers merge as builtin defaults < global ~/.mpm/mpm.yaml < project .mcp-config/mpm.yaml;
+  each layer only needs the fields it overrides.
+
+Parameters:
+  mode (default: show)
+    - show: effective config, source files, changes from defaults and validation warnings
+    - reload: drop the cache and reload (rarely needed; file changes reload automatically)
+    - init: write a commented mpm.yaml template under .mcp-config/ (never overwrites)
+
+Notes:
+  - Keys: map.page_size / map.max_page_size, recall.limit, analyze.anchor_limit /
+    directive_max_chars / briefing_ttl_hours, search.max_results, ignore.dirs / extra_dirs,
+    dev_log.window, wiki.output_dir, locale.
+  - Unknown keys or out-of-range values cause that layer to be ignored as a whole; this is reported by show and initialize_project.
+  - The legacy .mcp-data/project_config.json has been merged into this config and is removed on init.
+  - A locale change applies to error and result text immediately; tool descriptions update after a server restart.
+
+Examples:
+  config()
+    -> show the effective config
+  config(mode="init")
+    -> write the project config template
+
+Triggers:
+  "mpm 配置", "mpm config"`,
+
+	"tool.wiki_writer": `wiki_writer - Wiki outline generator
+
+Purpose:
+  Plan the Wiki outline and chapters for the project with a chosen writing style,
+  or write Wiki skeleton pages per directory straight from symbols.db.
+
+Parameters:
+  mode (default: outline)
+    - outline: return the project map and outline guidance; writes no files.
+    - generate: one page per package/directory (docs/wiki/ by default) plus a README.md index.
+    - styles: list all writing styles (builtin/global/project) and validate inheritance.
+
+  output_file (optional, outline)
+    Output file name, default wiki_outline.md
+
+  output_dir (optional, generate)
+    Output directory, default docs/wiki
+
+  scope (optional, generate)
+    Only this directory and its subdirectories, e.g. "internal/core"
+
+  style (optional)
+    Writing style:
+    - technical: technical documentation (concise, professional)
+    - tutorial: step-by-step guide
+    - reference: detailed reference material
+    - blog: relaxed blog style
+    - a custom style name (see "Custom styles")
+    - or free-form requirements
+
+Custom styles:
+  Put them in .mcp-config/wiki_styles/*.md (project) or ~/.mpm/wiki_styles/*.md (global);
+  project > global > builtin, same names override. Files may carry YAML frontmatter:
+    ---
+    name: team            # defaults to the file name
+    description: Team documentation style
+    extends: technical    # optional parent style
+    ---
+  Inheritance merges by "## " section: same-named sections override the parent, new ones are appended.
+  Extending a style's own name inherits the same-named style one layer down
+  (e.g. a project technical.md that changes a few sections of the builtin technical).
+
+generate page content:
+  - Overview: file list and symbol counts
+  - Exported symbols: signatures and doc comments (export rules follow each language)
+  - Dependencies: cross-package dependencies and dependents from the call graph
+  - Design decisions: memos whose path lies in the directory
+  Generated content lives inside <!-- mpm:begin xxx --> / <!-- mpm:end xxx --> blocks; re-running only replaces
+  those blocks and keeps hand-written content. Pages whose directory disappeared are reported, not deleted.
+
+Workflow (outline):
+  1. Fetch the project map as reference
+  2. The LLM explores the code and drafts the outline
+  3. Ask the user for a writing style
+  4. Append the style guide to the document
+
+Examples:
+  wiki_writer()
+  wiki_writer(output_file="MPM_Wiki.md", style="technical")
+  wiki_writer(style="beginner friendly, plenty of emoji, well-commented code")
+  wiki_writer(mode="generate")
+  wiki_writer(mode="generate", scope="internal/core")
+  wiki_writer(mode="styles")
+  wiki_writer(style="team")
+
+Triggers:
+  "mpm wiki", "mpm 文档"`,
+
+	"tool.check_edit": `check_edit - Pre-edit compliance check
+
+Purpose:
+  Before editing, check whether the planned files/diff violate the prohibitions of a manager_analyze briefing
+  (READ_ONLY, NO_CODE_EDIT, MD_ONLY, ...) and the enabled constraint rules (manager_constraints).
+
+Parameters:
+  paths (optional)
+    Files you plan to modify.
+
+  diff (optional)
+    Unified diff you plan to apply; added lines are also matched against the rules' forbidden patterns.
+
+  task_id (optional)
+    manager_analyze task_id, or a task chain ID linked to a briefing; without it only constraint rules are checked.
+
+  record_memo (default false)
+    Record the result as a memo (category "检查").
+
+Notes:
+  Which path checks implement which prohibition is defined by guardrail_checks in intent_rules.yaml;
+  prohibitions without a check are listed as "needs manual confirmation".
+
+Returns:
+  ✅ pass, or ❌ violations (source, rule, file, line)
+
+Triggers:
+  "mpm 合规", "mpm check", "mpm 检查修改"`,
+
+	"tool.manager_constraints": `manager_constraints - Manage project constraint rules
+
+Purpose:
+  Maintain the rules check_edit applies, e.g. "no calls to the old API during the migration", "do not touch vendor/".
+  Rules live in the project database and persist across sessions.
+
+Parameters:
+  mode (required)
+    - add: add a rule (same name overrides); needs name and at least one of forbid_paths / forbid_patterns
+    - list: list all rules
+    - enable / disable: enable or disable a rule; needs name
+
+  file_patterns / forbid_paths (optional)
+    gitignore syntax, e.g. "*.go", "vendor/", "internal/legacy/**".
+
+  forbid_patterns (optional)
+    Regular expressions; an added diff line matching one is a violation, e.g. "legacy\\.Client".
+
+Returns:
+  Operation result or the rule list
+
+Triggers:
+  "mpm 约束", "mpm constraints"`,
+
+	"tool.prompt_enhance": `prompt_enhance - Intent enhancement and tactical planning
+
+Purpose:
+  Inject a tactical execution protocol for small but tricky tasks. It makes the LLM: set boundaries -> probe history -> parse intent -> map the current state -> output a checklist, then start executing right away.
+
+Parameters:
+  task_description (optional)
+    Task to enhance.
+
+  mode (default: inject)
+    - inject: inject the protocol and start the task.
+    - explain: show the selected protocol, its source and the full rendering, and list available protocols (does not activate).
+
+  protocol (optional)
+    Protocol template name. Without it, a template declaring the intent is chosen, falling back to tactical.
+
+  intent (optional)
+    Task intent (DEBUG/REFACTOR/DESIGN...). Inferred from task_description when empty.
+
+Notes:
+  - After injection the LLM prints a [ ] checklist and starts executing in order without asking for confirmation.
+  - Protocol templates live in .mcp-config/protocols/ (project) or ~/.mpm/protocols/ (global), as *.md or *.tmpl;
+    the project overrides the global one with the same name, and tactical.md overrides the builtin protocol.
+  - Templates use Go text/template; frontmatter may declare name/description/intents. Variables:
+    {{.ProjectName}} {{.ProjectRoot}} {{.TechStack}} {{.Task}} {{.Intent}} {{.Date}}
+    {{.Hooks}} (ID/Summary/Priority/Description/TaskID) {{.Facts}}
+    {{.TaskChain}} (ID/Description/Done/Total/Current/Next); functions join/upper/lower.
+  - With task_description, the most relevant skills are recommended (same as skill_search).
+
+Example:
+  prompt_enhance(task_description="Refactor the error handling of the login module")
+    -> activates the tactical protocol for this refactor
+
+  - A task_description matching persona triggers activates that persona automatically.
+
+Triggers:
+  "mpm 增强", "mpm pe", "mpm enhance"`,
+
+	"tool.persona": `persona - AI persona management
+
+Purpose:
+  Switch or list AI personas (roles). Changing tone, reply style and thinking protocol improves the interaction or suits specific scenarios.
+
+Parameters:
+  mode (default: list)
+    - list: list all available personas.
+    - activate: activate a persona.
+    - create: add a persona (written to .mcp-config/personas.json).
+    - update: update a persona (renaming supported).
+    - delete: delete a persona (refused while other personas extend it).
+    - deactivate: drop the current persona and return to the default style.
+    - export: export a persona pack (all when names is empty; parents are included). Writes to path (relative to the project) if given, otherwise returns JSON; an existing file needs overwrite=true.
+    - import: import a persona pack (path or content). Existing names are skipped unless overwrite=true.
+
+  name (required for activate/update/delete)
+    Persona name or alias.
+
+Natural language examples:
+  - "activate persona kongming"
+  - "switch to the baiqi persona"
+  - "list all personas"
+  - "create persona xxx"
+  - "delete persona xxx"
+
+  Optional create/update fields:
+    - new_name, display_name, hard_directive, aliases
+    - style_must, style_signature, style_taboo, triggers
+    - extends: parent persona. Unset directives/avatar/style lists are inherited.
+    - style_merge: replace (default, style lists replace the parent's) / append (appended after the parent's)
+
+Notes:
+  - Once activated, the LLM strictly follows the persona's language and directives.
+  - Built-in personas include Zhuge (Kongming), Trump, Doraemon and others.
+  - Show a brief result in the conversation (e.g. the activated persona name) instead of long internal prompts.
+  - The active persona is restored on the next initialize_project.
+  - manager_analyze / prompt_enhance activate a persona when the task description contains its triggers.
+
+Examples:
+  persona(mode="activate", name="zhuge")
+    -> switch to Kongming, answering in classical Chinese
+
+  persona(mode="create", name="my_architect", display_name="Architect", hard_directive="Be concise and rigorous")
+    -> add a custom persona
+
+  persona(mode="create", name="strict_zhuge", extends="zhuge", style_taboo=["exaggeration"])
+    -> extend Kongming, replacing only the taboo list
+
+  persona(mode="export", names=["strict_zhuge"], path="personas/pack.json")
+    -> export a pack (including the parent zhuge)
+
+Triggers:
+  "mpm 人格", "mpm persona", "激活人格", "切换人格", "切换到.*人格", "列出人格", "创建人格", "删除人格", "导出人格", "导入人格"`,
+
+	"tool.manager_analyze": `manager_analyze - Task intelligence and tactical briefing (two-step)
+
+Purpose:
+  [Required] Entry point for complex tasks, in two self-iterating steps:
+
+  Step 1 (step=1): real analysis
+    - Locate code via AST search
+    - Load rules and memos by relevance (anchor files/symbols, callers, task keywords; deduplicated within a token budget)
+    - Estimate complexity
+    - Generate constraints
+    - Recommend skills by triggers/description/category (suggested_skills)
+    Returns: analysis + task_id
+
+  Step 2 (step=2): dynamic strategy
+    - Based on the real results of step 1
+    - Generates tactical advice
+    - Returns the full Mission Briefing (with strategic_handoff)
+    - Can be called repeatedly; briefings are stored in the project database and survive restarts
+
+  Briefings are kept for 72 hours by default (adjust with ttl_hours); list them with manager_list_briefings.
+  Linking with task_chain(mode="step", analysis_id=...) stops them from expiring.
+
+  ⚠️ This tool does not understand natural language.
+  You must first extract the intent and the key symbols from the user's request and fill in the parameters.
+
+Parameters:
+  task_description (required)
+    The user's original request, verbatim.
+
+  intent (required)
+    The task type, as you understand it:
+    - DEBUG: troubleshooting
+    - DEVELOP: new feature
+    - REFACTOR: refactoring
+    - RESEARCH: technical research
+    - and custom intents from intent_rules.yaml (e.g. SECURITY, MIGRATION)
+
+  symbols (required)
+    Core function, class or file names involved in the request.
+    (The tool locates code only from this list; missing entries lose context)
+
+  step (optional, default=1)
+    1=analyze, 2=generate strategy
+
+  task_id (required for step 2)
+    The task_id returned by step 1.
+
+  ttl_hours (optional, step 1)
+    Briefing retention in hours, default 72, at most 720.
+
+Returns:
+  Step 1: analysis + task_id
+  Step 2: the full Mission Briefing JSON
+
+Triggers:
+  "mpm 分析", "mpm 任务", "mpm mg", "mpm analyze"`,
+
+	"tool.known_facts": `known_facts - Atomic fact archive
+
+Purpose:
+  Store verified code rules, hard rules or important pitfalls in memory. manager_analyze loads them automatically so the same mistake is not repeated.
+
+Parameters:
+  type (required)
+    Fact type, e.g. "铁律" (hard rule), "避坑" (pitfall), "规范" (convention), "逻辑" (logic).
+
+  summarize (required)
+    A concise description of the fact.
+
+Example:
+  known_facts(type="避坑", summarize="Back up session data before changing the context logic")
+    -> stores an important rule of thumb
+
+Triggers:
+  "mpm 铁律", "mpm 避坑", "mpm fact"`,
+
+	"tool.manager_list_briefings": `manager_list_briefings - List manager_analyze briefings
+
+Purpose:
+  List the briefings stored in the project database (intent, directive, expiry, linked task chain),
+  or show one briefing's anchors and constraints. Use it to continue step=2 after a restart or in a new window.
+
+Parameters:
+  task_id (optional)
+    task_id returned by manager_analyze; shows that briefing in detail.
+
+  include_expired (default false)
+    Include expired briefings.
+
+  limit (default 20)
+    Maximum number of entries.
+
+Returns:
+  Briefing list or briefing detail
+
+Triggers:
+  "mpm 简报", "mpm briefings"`,
+
+	"tool.memo": `memo - The project's "black box" (if it isn't recorded, it didn't happen)
+
+Purpose:
+  [Required after changes] Never finish a code/doc change without a record.
+  It is not just for the user: it is how you will later find out "why it was changed this way". It is the single source of truth (SSOT) of the project's evolution.
+
+Parameters:
+  items (required - JSON array):
+    ⚠️ items is an array; wrap even a single record in [{...}]
+
+    Each element has these fields (all required):
+    - category: e.g. "修改" (change), "开发" (develop), "决策" (decision), "重构" (refactor), "避坑" (pitfall)
+    - entity: the changed entity (file, function or module name)
+    - act: short action, e.g. "fix bug", "new feature", "technology choice"
+    - path: file path
+    - content: details explaining why, not just what
+
+  lang (optional, defaults to the interface language):
+    Record language; affects auto-filled entity/action names
+
+Full call example (JSON):
+  {
+    "items": [
+      {
+        "category": "修改",
+        "entity": "SessionManager",
+        "act": "Fix nil pointer",
+        "path": "core/session.go",
+        "content": "Add a nil check so an uninitialized config no longer panics"
+      }
+    ],
+    "lang": "en"
+  }
+
+Triggers:
+  "mpm memo", "mpm 记录", "mpm 存档"`,
+
+	"tool.code_search": `code_search - Code symbol lookup (understands code better than grep)
+
+Purpose:
+  [Precise location] When you know a name (function/class) but not its file, use me instead of grep.
+  I also search definitions within a scope; I am your code-reading navigator.
+
+Parameter strategy:
+  query (required)
+    Preferably a code symbol (e.g. "SessionManager" or "HandleRequest").
+    Only know what it does? Give a few keywords (e.g. "persist hook expiry") for concept search:
+    camelCase/snake_case are split and names, signatures, doc comments and paths are ranked with BM25.
+
+  mode (optional)
+    - "auto" (default): single identifiers are looked up by name; multi-word descriptions use concept search first
+    - "symbol": name lookup only, falling back to text search
+    - "concept": concept search only
+
+  scope (optional)
+    Roughly know the directory? Fill it in (e.g. "internal/core") for much better accuracy.
+
+  search_type (optional)
+    - a function implementation? -> "function"
+    - a data structure? -> "class"
+    - any code? -> "any" (default)
+
+Returns:
+  The exact file path and line of the symbol definition.
+
+Triggers:
+  "mpm 搜索", "mpm 定位", "mpm 符号", "mpm find"`,
+
+	"tool.code_grep": `code_grep - Full text search (with enclosing symbols)
+
+Purpose:
+  [Text search] Strings, error messages, config keys, regex patterns.
+  Each match is annotated with its enclosing function/class; results are grouped by file.
+  Use code_search for symbol definitions.
+
+Parameters:
+  pattern (required)
+    Search text, literal by default
+  regex / case_sensitive / word_match / multiline (optional)
+    Regex, case sensitivity, whole word, multi-line matching
+  extensions / include / exclude (optional)
+    Extension and glob filters, comma separated
+  context_lines (optional)
+    Context lines
+  scope (optional)
+    Limit to a directory or file
+
+Returns:
+  Matching lines grouped by file, with enclosing symbols.
+
+Triggers:
+  "mpm grep", "mpm 文本搜索"`,
+
+	"tool.skill_list": `skill_list - List available skills (domain knowledge)
+
+Purpose:
+  Scan and list all available skills. A skill is an expert guide for a specific domain or task.
+
+Parameters:
+  none
+
+Notes:
+  - Scans the MPM install directory, the global (~/.mpm/skills) and the project skill directories, labeling each skill's scope.
+  - Same-named skills resolve as project > global > install directory; shadowed skills and directory name conflicts are listed at the end.
+  - SKILL.md changes reload automatically; no refresh needed.
+
+Example:
+  skill_list()
+    -> all skills with short descriptions
+
+Triggers:
+  "mpm 技能列表", "mpm skills"`,
+
+	"tool.skill_load": `skill_load - Load and read a skill (expert guidance)
+
+Purpose:
+  [Essential] When working with unfamiliar technology or domains, load the matching skill for detailed guidance and best practices.
+
+Parameters:
+  name (required)
+    Skill name or folder name.
+
+  level (default: standard)
+    - standard: standard summary.
+    - full: full metadata and content.
+
+  resource (optional)
+    A sub-resource of the skill (e.g. references/manual.md).
+
+  refresh (default: false)
+    true drops the cache and re-parses all skills (rarely needed; file changes reload automatically).
+
+Notes:
+  - After loading, the LLM must read the content carefully before taking any real action.
+  - Bundled scripts, reference docs and resource lists can be loaded.
+
+Example:
+  skill_load(name="Refactoring", level="full")
+    -> detailed refactoring guidance
+
+Triggers:
+  "mpm 加载技能", "mpm skill", "mpm loadskill"`,
+
+	"tool.skill_search": `skill_search - Match skills to a task description
+
+Purpose:
+  Score and rank every skill against the task description to find the one to load, instead of guessing by name.
+
+Parameters:
+  query (required)
+    Task description or keywords.
+
+  intent (optional)
+    Task intent (e.g. DESIGN, DEBUG), used for category matching.
+
+  limit (default: 5)
+    Maximum number of results.
+
+Notes:
+  - Scoring: frontmatter trigger words (highest weight), name and description terms (weighted by specificity), category.
+  - manager_analyze and prompt_enhance include the top 3 automatically.
+
+Example:
+  skill_search(query="write end-to-end tests for the login page")
+    -> webapp-testing and other relevant skills, with the reasons they matched
+
+Triggers:
+  "mpm 找技能", "mpm skill search"`,
+
+	"tool.skill_manage": `skill_manage - Install, validate and uninstall skill packs
+
+Purpose:
+  Install skills from a local directory or archive into the project or global scope, validating SKILL.md and the resource layout first and recording the version.
+
+Parameters:
+  mode (required)
+    - install: install a pack (needs source)
+    - uninstall: uninstall a skill (needs name)
+    - versions: list installed skills and versions by scope
+    - validate: validate without installing (needs source)
+
+  source (install/validate)
+    Pack path: directory, .zip, .tar, .tar.gz/.tgz. Archives may wrap one extra directory level.
+
+  scope (default: project)
+    - project: the project's skills/ directory
+    - global: ~/.mpm/skills (directory configurable via MPM_HOME)
+
+  force (default: false)
+    Allow downgrades or reinstalling the same version.
+
+Notes:
+  - frontmatter must contain name and description; version must be semantic (e.g. 1.2.0), 0.0.0 when missing.
+  - Resource directories are references/ scripts/ assets/; symlinks are not supported.
+  - The install record is written to .mpm-install.json in the skill directory.
+
+Example:
+  skill_manage(mode="install", source="~/Downloads/sql-tuning.zip", scope="global")
+    -> install into the global skill library
+
+Triggers:
+  "mpm 安装技能", "mpm skill install"`,
+
+	"tool.skill_run": `skill_run - Run a skill's bundled script
+
+Purpose:
+  Run helper scripts from a skill's scripts/ directory (e.g. webapp-testing's with_server.py)
+  without assembling commands by hand. Returns stdout, stderr and the exit code.
+
+Parameters:
+  skill (required)
+    Skill name.
+
+  script (required)
+    Script file name under scripts/; only scripts listed by skill_load.
+
+  args (optional)
+    Arguments passed to the script.
+
+  cwd (optional)
+    Working directory relative to the project root; defaults to the root and cannot leave the project.
+
+  timeout_seconds (default: 60, max 600)
+    The script is killed on timeout and timed_out=true is reported.
+
+  env (optional)
+    Extra environment variables. Only allow-listed host variables such as PATH, HOME and LANG are inherited;
+    interpreter-hijacking variables such as LD_PRELOAD and NODE_OPTIONS are rejected.
+    Scripts can read MPM_PROJECT_ROOT and MPM_SKILL_DIR.
+
+Notes:
+  - The interpreter follows the extension: .py → python3, .sh → bash, .js → node, .rb → ruby, .ps1 → pwsh.
+  - Only the first 64KB of each output is kept; *_truncated marks the rest.
+  - Scripts run with the current user's permissions; confirm what a script does first.
+
+Returns:
+  JSON: command, cwd, exit_code, timed_out, duration_ms, stdout, stderr
+
+Triggers:
+  "mpm 运行技能脚本", "mpm skill run"`,
+
+	"tool.initialize_project": `initialize_project - Initialize the project environment and database
+
+Purpose:
+  Must be called before any other MPM operation. It builds the database index, detects the tech stack and generates project rules.
+
+Parameters:
+  project_root (required)
+    Absolute path of the project root. When empty, the tool tries to detect it.
+
+Notes:
+  - An explicit project_root must be an absolute path.
+  - On success, _MPM_PROJECT_RULES.md is generated for the LLM.
+
+Example:
+  initialize_project(project_root="D:/AI_Project/MyProject")
+    -> initialize the project at that path
+
+Triggers:
+  "mpm 初始化", "mpm init"`,
+
+	"tool.open_timeline": `open_timeline - Project evolution timeline
+
+Purpose:
+  Generate and show an interactive timeline of the project's development history and decisions.
+
+Parameters:
+  none
+
+Notes:
+  - project_timeline.html is generated from memo records.
+  - The file is opened in the default browser when possible.
+
+Example:
+  open_timeline()
+    -> open the project timeline in the browser
+
+Triggers:
+  "mpm 时间线", "mpm timeline"`,
+
+	"tool.system_recall": `system_recall - Memory recall (avoid detours)
+
+Purpose:
+  [Recommended before changes] About to change a feature but not sure whether similar logic exists or there are known pitfalls?
+  Check the memory store to avoid reinventing the wheel or repeating mistakes.
+
+Parameter strategy:
+  keywords (required)
+    Whatever you are looking for; fuzzy matching, split on spaces.
+
+  category (optional)
+    Narrow it down, e.g. "避坑" (pitfall) / "开发" (develop) / "决策" (decision)
+
+Triggers:
+  "mpm 召回", "mpm 历史", "mpm recall"`,
+
+	"tool.manager_create_hook": `manager_create_hook - Create a pending hook (to-do)
+
+Purpose:
+  When a task cannot continue because information is missing, the user must confirm, or something blocks it, create a hook to park the progress so it can be resumed in a later session.
+
+Parameters:
+  description (required)
+    The to-do or the reason for blocking.
+
+  priority (default: medium)
+    Priority (high/medium/low).
+
+  task_id (optional)
+    Related task ID.
+
+  tag (optional)
+    Category tag.
+
+  expires_in_hours (default: 0)
+    Expiry in hours; 0 means never.
+
+Notes:
+  - Open hooks are discovered and shown by manager_analyze.
+
+Example:
+  manager_create_hook(description="Waiting for the user's API key", priority="high")
+    -> creates a high-priority blocker
+
+Triggers:
+  "mpm 挂起", "mpm 待办", "mpm hook"`,
+
+	"tool.manager_list_hooks": `manager_list_hooks - List hooks
+
+Purpose:
+  List the project's hooks that are open or closed.
+
+Parameters:
+  status (default: open)
+    Filter by status (open: pending / closed: done).
+
+Notes:
+  - Use it to find tasks paused by blockers.
+
+Example:
+  manager_list_hooks(status="open")
+    -> all open to-dos
+
+Triggers:
+  "mpm 待办列表", "mpm listhooks"`,
+
+	"tool.manager_release_hook": `manager_release_hook - Release and close a hook
+
+Purpose:
+  When a pending item is done or its blocker is gone, close the hook and record the result.
+
+Parameters:
+  hook_id (required)
+    Hook identifier (e.g. "#001" or a UUID).
+
+  result_summary (optional)
+    Summary of the outcome.
+
+Notes:
+  - Closed hooks no longer appear in the default to-do list.
+
+Example:
+  manager_release_hook(hook_id="#001", result_summary="API key configured and tested")
+    -> releases the hook
+
+Triggers:
+  "mpm 释放", "mpm 完成"`,
+
+	"tool.task_chain": `task_chain - Sequential task runner V2 (adaptive task chain)
+
+Purpose:
+  [Multi-step tasks] Rather than letting the LLM do everything at once (prone to conflicts), let me drive the steps in order.
+  V2 supports adaptive checkpoints: each step must submit a summary when completed, after which later steps can be adjusted.
+
+Parameters:
+  mode (required):
+    [V2 modes - recommended]
+    - step: initialize the chain and start the first step (needs task_id + description + plan)
+    - start: start a step (needs task_id + step_number)
+    - complete: complete a step with a summary (needs task_id + step_number + summary)
+    - insert: insert steps (needs task_id + after + insert_plan; decimal numbers such as 1.1, 1.2)
+    - update: update steps (needs task_id + from + update_plan)
+    - delete: delete steps (needs task_id + step_to_delete or delete_scope)
+    - continue: recover after losing context or in a new session; returns the real state: running chains and current steps, open hooks, recent memos
+
+    [V1 modes - backward compatible]
+    - next: run the next step (V1)
+    - finish: finish the task (needs task_id)
+
+  task_id (required except in continue mode)
+    Unique task identifier
+
+  plan (required in step mode - JSON array)
+    Each element has:
+    - name: step name
+    - input: (optional) suggested tool call
+
+  description (optional in step mode)
+    Overall task description
+
+  analysis_id (optional in step mode)
+    task_id from manager_analyze; linked briefings no longer expire and the strategy can be regenerated at any time
+
+  step_number (required in start/complete mode)
+    Step number (decimals allowed: 1.0, 1.5, 2.0, ...)
+
+  summary (required in complete mode)
+    Step summary, covering:
+    - what the step did
+    - the key findings
+    - the impact on later steps
+
+  after (required in insert mode)
+    Step number to insert after
+
+Core value:
+  V2 adaptive mode:
+    - every step is an independent checkpoint
+    - completing a step requires a summary, capturing knowledge
+    - every checkpoint is a decision point for adjusting later steps
+    - decimal numbering (1.1, 1.2) for flexible insertion
+    - progress is persisted and restored by initialize_project after a restart
+
+  V1 compatible mode:
+    - keeps context coherent and records progress automatically
+
+Examples:
+  # V2 (recommended)
+  task_chain(mode="step", task_id="TASK_001", description="Analyze the code and write tests", plan=[
+    {"name": "Find the function definition", "input": "code_search(query='foo')"},
+    {"name": "Analyze the impact", "input": "code_impact(symbol='foo')"},
+    {"name": "Write tests"}
+  ])
+  → starts Step 1.0
+
+  task_chain(mode="complete", task_id="TASK_001", step_number=1.0,
+    summary="foo is at bar.go:123 and is called by 3 other functions")
+
+  → decision point: continue with Step 2.0, insert steps, or change the plan
+
+  task_chain(mode="insert", task_id="TASK_001", after=1.0, insert_plan=[
+    {"name": "Read bar.go", "input": "Read('bar.go')"}
+  ])
+  → inserts Step 1.1
+
+Triggers:
+  "mpm 任务链", "mpm 续传", "mpm chain"`,
+
+	// ==================== 错误消息 ====================
+
+	"参数格式错误: %v": "Invalid arguments: %v",
+	"参数错误: %v":   "Invalid arguments: %v",
+	"记忆层尚未初始化":   "Memory layer is not initialized",
+	"记忆层尚未初始化，请先执行 initialize_project。":    "Memory layer is not initialized; run initialize_project first.",
+	"记忆层尚未初始化，请先执行 initialize_project 任务。": "Memory layer is not initialized; run initialize_project first.",
+	"项目尚未初始化，请先执行 initialize_project。":     "Project is not initialized; run initialize_project first.",
+	"项目尚未初始化，请先执行 initialize_project":      "Project is not initialized; run initialize_project first",
+	"项目未初始化，请先执行 initialize_project":       "Project is not initialized; run initialize_project first",
+	"⚠️ 项目未初始化，请先调用 initialize_project。":   "⚠️ Project is not initialized; call initialize_project first.",
+	"❌ 项目未初始化，请先调用 initialize_project":     "❌ Project is not initialized; call initialize_project first",
+	"项目未初始化":   "Project is not initialized",
+	"未知模式: %s": "Unknown mode: %s",
+	"不支持的 output_format: %s (可选 markdown/json)": "Unsupported output_format: %s (markdown/json)",
+	"JSON 序列化失败: %v":                            "JSON serialization failed: %v",
+
+	"查询简报失败: %v":   "Failed to query briefings: %v",
+	"未找到分析简报 %s":   "Briefing %s not found",
+	"保存分析简报失败: %v": "Failed to save the briefing: %v",
+	"关联分析简报失败: %v": "Failed to link the briefing: %v",
+	"保存事实失败: %v":   "Failed to save the fact: %v",
+	"⚠️ 项目未初始化，无法执行任务分析。请先调用 initialize_project。": "⚠️ Project is not initialized, so the task cannot be analyzed. Call initialize_project first.",
+	"⚠️ Step 2 需要提供 task_id 参数（来自 Step 1 的返回值）":   "⚠️ Step 2 needs the task_id parameter (returned by Step 1)",
+
+	"AST 分析失败: %v": "AST analysis failed: %v",
+	"不支持的 format: %s (可选 markdown/json/mermaid)": "Unsupported format: %s (markdown/json/mermaid)",
+	"生成地图失败: %v":                                 "Failed to build the map: %v",
+	"序列化地图失败: %v":                                "Failed to serialize the map: %v",
+	"读取快照失败: %v":                                 "Failed to read snapshots: %v",
+	"趋势对比失败: %v":                                 "Trend comparison failed: %v",
+	"结构对比失败: %v":                                 "Structure comparison failed: %v",
+	"尚无结构快照，initialize_project 时会自动记录":           "No structure snapshots yet; initialize_project records them automatically",
+	"重构预览失败: %v":                                 "Refactor preview failed: %v",
+	"搜索失败: %v":                                   "Search failed: %v",
+	"概念搜索失败: %v":                                 "Concept search failed: %v",
+	"pattern 不能为空":                               "pattern must not be empty",
+	"scope %s 不在项目目录内":                           "scope %s is outside the project directory",
+
+	"%s 已存在，请直接编辑":                      "%s already exists; edit it directly",
+	"创建配置目录失败: %v":                      "Failed to create the config directory: %v",
+	"写入配置失败: %v":                        "Failed to write the config: %v",
+	"未知 mode: %s (可选 show/reload/init)": "Unknown mode: %s (show/reload/init)",
+
+	"生成 Wiki 失败: %v":                        "Failed to generate the Wiki: %v",
+	"生成项目地图失败: %v":                          "Failed to build the project map: %v",
+	"书写风格 %s 无效: %v":                        "Writing style %s is invalid: %v",
+	"查询约束规则失败: %v":                          "Failed to query constraint rules: %v",
+	"保存约束规则失败: %v":                          "Failed to save the constraint rule: %v",
+	"未知模式: %s (可选 add/list/enable/disable)": "Unknown mode: %s (add/list/enable/disable)",
+	"请提供 name":                              "Please provide name",
+	"forbid_paths 与 forbid_patterns 至少填写一项": "Provide at least one of forbid_paths and forbid_patterns",
+
+	"加载人格库失败: %v": "Failed to load personas: %v",
+	"人格 '%s' 已存在": "Persona '%s' already exists",
+	"人格定义无效: %v":  "Invalid persona definition: %v",
+	"保存人格库失败: %v": "Failed to save personas: %v",
+	"未找到人格: %s":   "Persona not found: %s",
+	"新名称冲突: %s":   "New name conflicts: %s",
+	"人格 %s 仍被继承: %s，请先删除或修改这些人格":          "Persona %s is still extended by: %s; delete or change those personas first",
+	"写入人格包失败: %v":                         "Failed to write the persona pack: %v",
+	"读取人格包失败: %v":                         "Failed to read the persona pack: %v",
+	"导入失败: %v":                            "Import failed: %v",
+	"activate 模式需要提供 name 参数":             "activate mode needs the name parameter",
+	"create 模式需要先 initialize_project":     "create mode needs initialize_project first",
+	"create 模式需要提供 name":                  "create mode needs name",
+	"update 模式需要先 initialize_project":     "update mode needs initialize_project first",
+	"update 模式需要提供 name":                  "update mode needs name",
+	"delete 模式需要先 initialize_project":     "delete mode needs initialize_project first",
+	"delete 模式需要提供 name":                  "delete mode needs name",
+	"deactivate 模式需要先 initialize_project": "deactivate mode needs initialize_project first",
+	"人格包路径需要先 initialize_project":         "Persona pack paths need initialize_project first",
+	"人格包路径须为相对项目根的路径: %s":                 "Persona pack paths must be relative to the project root: %s",
+	"人格包路径不在项目目录内: %s":                    "Persona pack path is outside the project directory: %s",
+	"文件 %s 已存在，如需覆盖请设置 overwrite=true":    "File %s already exists; set overwrite=true to replace it",
+	"import 模式需要先 initialize_project":     "import mode needs initialize_project first",
+	"import 模式需要提供 path 或 content":        "import mode needs path or content",
+
+	"保存备忘录失败： %v": "Failed to save memos: %v",
+
+	"扫描技能库失败: %v":  "Failed to scan skills: %v",
+	"无法加载资源: %s":   "Cannot load resource: %s",
+	"读取技能文件失败: %v": "Failed to read the skill file: %v",
+	"请提供 query":    "Please provide query",
+	"禁止访问技能目录外的资源": "Access to resources outside the skill directory is not allowed",
+
+	"路径解析失败： %v": "Failed to resolve the path: %v",
+	"⛔ 敏感路径（系统或 IDE 目录），禁止在此初始化项目： %s": "⛔ Sensitive path (system or IDE directory); a project cannot be initialized here: %s",
+	"创建数据目录失败： %v":                              "Failed to create the data directory: %v",
+	"初始化记忆层失败： %v":                              "Failed to initialize the memory layer: %v",
+	"❌ 找不到生成脚本: %s (checked scripts/ and root)": "❌ Generator script not found: %s (checked scripts/ and root)",
+	"❌ 生成 Timeline 失败:\n%s\nOutput: %s":         "❌ Failed to generate the timeline:\n%s\nOutput: %s",
+	"❌ 脚本执行成功但未生成 project_timeline.html":        "❌ The script succeeded but project_timeline.html was not generated",
+	"检索 memos 失败: %v":                           "Failed to search memos: %v",
+	"检索 known_facts 失败: %v":                     "Failed to search known_facts: %v",
+
+	"创建 Hook 失败: %v": "Failed to create the hook: %v",
+	"查询 Hook 失败: %v": "Failed to query hooks: %v",
+	"释放 Hook 失败: %v": "Failed to release the hook: %v",
+
+	"任务 %s 不存在，请先使用 mode='step' 初始化": "Task %s does not exist; initialize it with mode='step' first",
+	"任务 %s 不存在":                                                  "Task %s does not exist",
+	"步骤 %.1f 不存在":                                                "Step %.1f does not exist",
+	"步骤 %.1f 状态为 %s，无法开始":                                        "Step %.1f is %s and cannot be started",
+	"步骤 %.1f 状态为 %s，无法完成":                                        "Step %.1f is %s and cannot be completed",
+	"无法删除正在执行的步骤 %.1f，请先完成":                                      "Cannot delete step %.1f while it is running; complete it first",
+	"step 模式需要 task_id 参数":                                       "step mode needs the task_id parameter",
+	"step 模式需要 plan 参数":                                          "step mode needs the plan parameter",
+	"next 模式需要 task_id 参数":                                       "next mode needs the task_id parameter",
+	"resume 模式需要 task_id 参数":                                     "resume mode needs the task_id parameter",
+	"insert 模式需要 task_id 参数":                                     "insert mode needs the task_id parameter",
+	"insert 模式需要 insert_plan 参数":                                 "insert mode needs the insert_plan parameter",
+	"delete 模式需要 task_id 参数":                                     "delete mode needs the task_id parameter",
+	"finish 模式需要 task_id 参数":                                     "finish mode needs the task_id parameter",
+	"start 模式需要 task_id 参数":                                      "start mode needs the task_id parameter",
+	"complete 模式需要 task_id 参数":                                   "complete mode needs the task_id parameter",
+	"complete 模式必须提供 summary 参数":                                 "complete mode requires the summary parameter",
+	"update 模式需要 task_id 参数":                                     "update mode needs the task_id parameter",
+	"update 模式需要 update_plan 参数":                                 "update mode needs the update_plan parameter",
+	"请指定删除目标：subtask_id、step_order 或 delete_scope=\"remaining\"": "Specify what to delete: subtask_id, step_order or delete_scope=\"remaining\"",
+	"请指定删除目标：step_to_delete 或 delete_scope=\"remaining\"":        "Specify what to delete: step_to_delete or delete_scope=\"remaining\"",
+
+	// ==================== 结果文本 ====================
+
+	"❌ 无法自动识别项目路径，请手动指定 project_root（需为绝对路径）。":                "❌ Could not detect the project path; pass project_root explicitly (absolute path).",
+	"✅ 项目初始化成功！\n\n项目目录: %s\n数据库已准备就绪。\nAST 索引: %s%s%s%s%s%s": "✅ Project initialized!\n\nProject directory: %s\nDatabase ready.\nAST index: %s%s%s%s%s%s",
+	"⚠️ Timeline 已生成但无法自动打开。\n路径: %s":                         "⚠️ Timeline generated but could not be opened automatically.\nPath: %s",
+	"✅ Timeline 已生成并尝试打开。\n文件: %s":                            "✅ Timeline generated and opened.\nFile: %s",
+	"未找到相关记录":                 "No matching records",
+	"已成功录入 %d 条记录 (IDs: %v)。": "Recorded %d entries (IDs: %v).",
+	"系统":   "System",
+	"信息":   "Info",
+	"手动录入": "Manual Entry",
+	"✅ 事实已存入数据库 (ID: %d): [%s] %s": "✅ Fact saved (ID: %d): [%s] %s",
+	"⚠️ 未找到「%s」":                   "⚠️ No matches for \"%s\"",
+	"✅ 已生成配置模板: %s\n\n按需修改后，下一次工具调用自动生效。":     "✅ Config template written: %s\n\nEdit as needed; changes apply on the next tool call.",
+	"✅ 约束规则 %s 已保存 (ID: %d)，check_edit 将自动应用": "✅ Constraint rule %s saved (ID: %d); check_edit applies it automatically",
+
+	"未找到人格 '%s'。可用人格: %s": "Persona '%s' not found. Available personas: %s",
+	"✅ 已创建人格: %s":         "✅ Persona created: %s",
+	"✅ 已更新人格: %s":         "✅ Persona updated: %s",
+	"✅ 已删除人格: %s":         "✅ Persona deleted: %s",
+	"✅ 已取消人格，恢复默认风格。":     "✅ Persona deactivated; default style restored.",
+	"✅ 已导出 %d 个人格到 %s":    "✅ Exported %d personas to %s",
+
+	"未找到与 \"%s\" 相关的技能 (共 %d 个技能)。可用 `skill_list()` 浏览全部。": "No skills related to \"%s\" (%d skills in total). Browse them all with `skill_list()`.",
+
+	"📌 Hook 已创建 (ID: %s)\n\n**描述**: %s\n**优先级**: %s\n\n> 使用 `manager_release_hook(hook_id=\"%s\")` 释放此 Hook。": "📌 Hook created (ID: %s)\n\n**Description**: %s\n**Priority**: %s\n\n> Release it with `manager_release_hook(hook_id=\"%s\")`.",
+	"暂无 %s 状态的 Hook。":                "No hooks with status %s.",
+	"✅ Hook %s 已释放。\n\n**结果摘要**: %s": "✅ Hook %s released.\n\n**Result summary**: %s",
+	"📍 任务 %s 进行中 (无状态模式)。\n\n请继续执行下一步。完成后再次调用此工具。": "📍 Task %s in progress (stateless mode).\n\nContinue with the next step and call this tool again when done.",
+	"🔄 正在恢复任务 %s...\n%s\n\n请根据上下文判断当前进度并继续执行。":     "🔄 Resuming task %s...\n%s\n\nJudge the current progress from the context and continue.",
+	"%s\n新增: %s": "%s\nAdded: %s",
+	"✅ 已删除任务 %s 的所有剩余步骤。":                          "✅ Deleted all remaining steps of task %s.",
+	"✅ 已删除任务 %s 的第 %d 步。":                          "✅ Deleted step %[2]d of task %[1]s.",
+	"✅ 已删除子任务 %s。":                                 "✅ Deleted subtask %s.",
+	"✅ 已删除 %d 个待执行步骤，保留 %d 个已完成/进行中的步骤":            "✅ Deleted %d pending steps; kept %d completed/in-progress steps",
+	"✅ 已删除步骤 %.1f: %s":                             "✅ Deleted step %.1f: %s",
+	"[MCP-Go] 已锁定项目根目录: %s\n":                      "[MCP-Go] Project root locked: %s\n",
+	"[MCP-Go][ERROR] 记忆层初始化受阻: %v\n":               "[MCP-Go][ERROR] Memory layer initialization failed: %v\n",
+	"[MCP-Go] 记忆层（SSOT）与项目上下文已就绪。\n":               "[MCP-Go] Memory layer (SSOT) and project context are ready.\n",
+	"[MCP-Go][WARN] 无法探测项目根目录，请检查环境变量或在项目目录下运行。\n": "[MCP-Go][WARN] Could not detect the project root; check the environment variables or run inside the project directory.\n",
+	"[MCP-Go][WARN] 意图规则: %s\n":                    "[MCP-Go][WARN] Intent rules: %s\n",
+	"[MCP-Go][WARN] 项目配置: %s\n":                    "[MCP-Go][WARN] Project config: %s\n",
+	"[MCP-Go] MyProjectManager 正在启动...\n":          "[MCP-Go] MyProjectManager is starting...\n",
+	"服务运行错误: %v\n":                                 "Server error: %v\n",
+	"分析简报 %s 不存在":                                  "Analysis briefing %s does not exist",
+	"约束规则 %s 不存在":                                  "Constraint rule %s does not exist",
+	"项目地图生成失败: %v":                                 "Project map generation failed: %v",
+	"读取地图结果失败: %v":                                 "Failed to read the map result: %v",
+	"解析地图结果失败: %v":                                 "Failed to parse the map result: %v",
+	"符号搜索失败: %v":                                   "Symbol search failed: %v",
+	"读取搜索结果失败: %v":                                 "Failed to read the search result: %v",
+	"解析搜索结果失败: %v":                                 "Failed to parse the search result: %v",
+	"定位符号失败: %v":                                   "Failed to locate the symbol: %v",
+	"读取定位结果失败: %v":                                 "Failed to read the locate result: %v",
+	"解析定位结果失败: %v":                                 "Failed to parse the locate result: %v",
+	"影响分析执行失败: %v":                                 "Impact analysis failed: %v",
+	"读取分析结果失败: %v":                                 "Failed to read the analysis result: %v",
+	"解析分析结果失败: %v":                                 "Failed to parse the analysis result: %v",
+	"索引刷新失败: 全量扫描失败(%v); 扩展模式重试失败(%v)":             "Index refresh failed: full scan failed (%v); retry in extension mode failed (%v)",
+	"索引刷新失败: %v":                                   "Index refresh failed: %v",
+	"打开数据库失败: %v":                                  "Failed to open the database: %v",
+	"查询符号失败: %v":                                   "Symbol query failed: %v",
+	"概念索引刷新失败: %v":                                 "Concept index refresh failed: %v",
+	"路径为空":                                         "Path is empty",
+	"%s 不在项目目录内":                                   "%s is outside the project directory",
+	"尚无指标快照，请先执行一次索引 (initialize_project / project_map)": "No metric snapshots yet; run an index first (initialize_project / project_map)",
+	"快照 #%d 之前没有更早的快照，至少需要两次索引才能对比":                      "There is no snapshot before #%d; comparing needs at least two indexing runs",
+	"快照 %d 不存在":                                                     "Snapshot %d does not exist",
+	"不支持的 version: %d (当前为 %d)":                                     "Unsupported version: %d (current is %d)",
+	"new_name 与 destination 至少填写一个":                                 "Provide at least one of new_name and destination",
+	"new_name 不是合法标识符: %s":                                          "new_name is not a valid identifier: %s",
+	"文本引用搜索失败: %v":                                                  "Text reference search failed: %v",
+	"目标文件与定义所在文件相同: %s":                                             "The destination is the file that holds the definition: %s",
+	"destination 必须是项目内的文件路径: %s":                                   "destination must be a file path inside the project: %s",
+	"无法读取定义源码: %s L%d-%d":                                           "Cannot read the definition source: %s L%d-%d",
+	"未找到符号定义: %s":                                                   "Symbol definition not found: %s",
+	"符号 `%s` 有 %d 处定义，请用 file 参数指定: %s":                             "Symbol `%s` has %d definitions; use the file argument to pick one: %s",
+	"无效的正则表达式: %v":                                                  "Invalid regular expression: %v",
+	"索引不存在，请先执行 initialize_project":                                 "Index does not exist; run initialize_project first",
+	"结构快照 #%d 不存在":                                                  "Structure snapshot #%d does not exist",
+	"since 无效: %s (支持快照 ID、2006-01-02、2006-01-02 15:04 或 RFC3339)":  "Invalid since: %s (use a snapshot ID, 2006-01-02, 2006-01-02 15:04 or RFC3339)",
+	"### 🧠 分析简报\n\n":                                                "### 🧠 Analysis Briefings\n\n",
+	"暂无分析简报。调用 manager_analyze(step=1) 生成。\n":                       "No analysis briefings yet. Call manager_analyze(step=1) to create one.\n",
+	"\n_已隐藏 %d 个过期简报 (include_expired=true 查看)_\n":                  "\n_%d expired briefings hidden (include_expired=true to show)_\n",
+	"### 🧠 分析简报 %s\n\n":                                             "### 🧠 Analysis Briefing %s\n\n",
+	"**意图**: %s\n":                                                  "**Intent**: %s\n",
+	"**指令**: %s\n":                                                  "**Directive**: %s\n",
+	"**创建**: %s | **更新**: %s\n":                                     "**Created**: %s | **Updated**: %s\n",
+	"**任务链**: %s%s\n":                                               "**Task chain**: %s%s\n",
+	"**状态**: 已过期 (%s)\n":                                            "**Status**: expired (%s)\n",
+	"**过期**: %s\n":                                                  "**Expires**: %s\n",
+	"\n**锚点** (%d):\n":                                              "\n**Anchors** (%d):\n",
+	"\n**约束**:\n":                                                   "\n**Constraints**:\n",
+	"\n→ `manager_analyze(step=2, task_id=\"%s\")` 重新生成战术策略\n":      "\n→ `manager_analyze(step=2, task_id=\"%s\")` to regenerate the tactical strategy\n",
+	"未找到分析简报 %s，请先调用 manager_analyze(step=1)":                       "Analysis briefing %s not found; call manager_analyze(step=1) first",
+	"分析简报 %s 已于 %s 过期，请重新调用 manager_analyze(step=1)":                "Analysis briefing %s expired at %s; call manager_analyze(step=1) again",
+	"记忆层尚未初始化，无法关联分析简报":                                             "The memory layer is not initialized; cannot link the analysis briefing",
+	"⚠️ `%s` 不是代码函数/类定义。\n\n":                                       "⚠️ `%s` is not a function or class definition.\n\n",
+	"> 如果要搜索**字符串**，用 **Grep** 工具\n":                                "> To search for a **string**, use the **Grep** tool\n",
+	"> 如果要查找**函数定义**，用 **code_search** 工具":                          "> To find a **function definition**, use the **code_search** tool",
+	"## `%s` 影响分析\n\n":                                              "## `%s` Impact Analysis\n\n",
+	"**风险**: %s | **复杂度**: %.0f | **耦合度**: %.0f | **影响节点**: %d\n\n": "**Risk**: %s | **Complexity**: %.0f | **Coupling**: %.0f | **Affected nodes**: %d\n\n",
+	"**函数指标**: 圈复杂度 %d | 认知复杂度 %d | %d 行 | %d 参数 | 嵌套 %d 层\n\n": "**Function metrics**: cyclomatic %d | cognitive %d | %d lines | %d params | nesting %d\n\n",
+	"### 直接调用者（修改前必须检查）\n":                                      "### Direct callers (check before changing)\n",
+	"✅ 无直接调用者，可安全修改\n":                                          "✅ No direct callers; safe to change\n",
+	"\n_间接影响: %d 个函数_\n":                                        "\n_Indirect impact: %d functions_\n",
+	"delta_from 无效: `%s` (应为 previous 或快照 ID)":                  "Invalid delta_from: `%s` (use previous or a snapshot ID)",
+	"无法生成趋势: %v":                                                "Cannot build the trend: %v",
+	"🔄 已重新加载配置":                                                 "🔄 Config reloaded",
+	"### ⚙️ 项目配置\n\n":                                           "### ⚙️ Project Config\n\n",
+	"- 项目: `%s`\n":                                              "- Project: `%s`\n",
+	"- 项目: 未初始化 (仅内置默认与全局配置)\n":                                 "- Project: not initialized (built-in defaults and global config only)\n",
+	"- 本次初始化: %s\n":                                             "- Initialized: %s\n",
+	"- 生效来源: %s\n":                                              "- Sources in effect: %s\n",
+	"\n**相对默认值的改动**：\n":                                         "\n**Changes from the defaults**:\n",
+	"\n⚠️ %d 个配置文件被忽略：\n":                                       "\n⚠️ %d config files were ignored:\n",
+	"\n> 💡 项目尚无 mpm.yaml，可用 `config(mode=\"init\")` 生成模板。\n":    "\n> 💡 The project has no mpm.yaml yet; run `config(mode=\"init\")` to create a template.\n",
+	"锚点文件 %s":                                                   "anchor file %s",
+	"调用方文件 %s":                                                  "caller file %s",
+	"提及 %s":                                                     "mentions %s",
+	"符号 %s":                                                     "symbol %s",
+	"调用方 %s":                                                    "caller %s",
+	"关键词 %d":                                                    "%d keywords",
+	"最新":                                                        "latest",
+	"                    【Wiki 大纲生成】\n":                         "                    [Wiki Outline]\n",
+	"## 📋 参考资料（项目地图）\n\n":                                       "## 📋 Reference (Project Map)\n\n",
+	"> 📄 完整地图较长 (%d 字符)，请用 `project_map(level=\"symbols\")` 按 cursor 分页查看\n\n": "> 📄 The full map is long (%d characters); use `project_map(level=\"symbols\")` and page with cursor\n\n",
+	"**摘要**：\n\n":    "**Summary**:\n\n",
+	"## ✍️ 你的任务\n\n": "## ✍️ Your Task\n\n",
+	"基于上述项目地图，生成一套完整的 Wiki 大纲和章节规划文档。\n\n": "Based on the project map above, produce a complete wiki outline and chapter plan.\n\n",
+	"**要求**：\n":                          "**Requirements**:\n",
+	"- 用最流行的方式组织章节\n":                    "- Organize chapters in the most common way\n",
+	"- 可以自主使用 code_search 查找符号\n":        "- Use code_search freely to find symbols\n",
+	"- 可以使用 Read 阅读具体实现\n":               "- Use Read to study specific implementations\n",
+	"- 输出完整的大纲文档\n\n":                    "- Output the complete outline document\n\n",
+	"## 🎨 书写风格选择\n\n":                    "## 🎨 Writing Style\n\n",
+	"**预置模板**（输入数字或名称）：\n":               "**Built-in templates** (enter a number or name):\n",
+	"- `%d` 或 `%s` → %s\n":               "- `%d` or `%s` → %s\n",
+	"**团队风格**（输入名称）：\n":                  "**Team styles** (enter a name):\n",
+	"**自定义要求**：\n":                       "**Custom requirements**:\n",
+	"- 直接输入你的风格要求\n":                     "- Type your style requirements directly\n",
+	"- 例如：\"面向新手，多用 emoji，代码要详细注释\"\n\n": "- For example: \"for beginners, plenty of emoji, well-commented code\"\n\n",
+	"---\n\n**当前选择**：%s\n\n":             "---\n\n**Current choice**: %s\n\n",
+	"**生成的书写指南**：\n\n":                   "**Generated writing guide**:\n\n",
+	"\n---\n\n💾 **保存到**：`%s`\n":          "\n---\n\n💾 **Save to**: `%s`\n",
+	"项目地图获取失败":                           "Failed to get the project map",
+	"**📁 目录结构**:\n\n":                    "**📁 Directory Structure**:\n\n",
+	"- `%s/` (%d 符号)\n":                  "- `%s/` (%d symbols)\n",
+	"请提供 paths 或 diff (diff 中未识别到文件)":    "Provide paths or diff (no files found in the diff)",
+	"简报 %s 已过期，仍按其禁令检查":                  "Briefing %s has expired; still checking against its prohibitions",
+	"读取约束规则失败: %v":                       "Failed to read constraint rules: %v",
+	"仅提供了 paths，未检查约束规则中的代码正则 (提供 diff 可检查新增行)": "Only paths were given, so code patterns in constraint rules were not checked (provide diff to check added lines)",
+	"\n⚠️ 记录 memo 失败: %v\n":                         "\n⚠️ Failed to record memo: %v\n",
+	"\n📝 检查结果已记为 memo\n":                            "\n📝 Check result recorded as a memo\n",
+	"读取分析简报失败: %v":                                  "Failed to read the analysis briefing: %v",
+	"未找到 %s 对应的分析简报 (可用 manager_list_briefings 查看)": "No analysis briefing found for %s (see manager_list_briefings)",
+	"分析简报 %s 已损坏: %v":                               "Analysis briefing %s is corrupted: %v",
+	"约束规则 %s 的 file_patterns 无效，已跳过":                "Constraint rule %s has invalid file_patterns; skipped",
+	"约束规则 %s 的 rule_definition 无效，已跳过":              "Constraint rule %s has an invalid rule_definition; skipped",
+	"### 🛡️ 修改合规检查\n\n":                             "### 🛡️ Edit Compliance Check\n\n",
+	"未识别":                                           "unknown",
+	"**简报**: %s (意图 %s)\n":                          "**Briefing**: %s (intent %s)\n",
+	"**简报**: 未指定，仅检查约束规则\n":                         "**Briefing**: none given; checking constraint rules only\n",
+	"**文件**: %d 个\n\n":                              "**Files**: %d\n\n",
+	"✅ 未发现违规\n":                                     "✅ No violations found\n",
+	"❌ 发现 %d 处违规\n\n":                               "❌ Found %d violations\n\n",
+	"- ... 另有 %d 处未显示\n":                            "- ... %d more not shown\n",
+	"禁令":                                            "prohibition",
+	"约束":                                            "constraint",
+	"\n**需人工确认** (无法自动检查的禁令):\n":                    "\n**Needs manual confirmation** (prohibitions that cannot be checked automatically):\n",
+	"\n**说明**:\n":                                   "\n**Notes**:\n",
+	"\n> 请调整修改范围，或先与用户确认是否放宽约束。\n": "\n> Narrow the change, or confirm with the user first whether the constraint can be relaxed.\n",
+	"通过":            "passed",
+	"%d 个文件未发现违规":   "No violations in %d files",
+	"违规":            "violation",
+	"等 %d 处":        "and %d more",
+	"违规: %s":        "Violation: %s",
+	" (简报 %s)":      " (briefing %s)",
+	"检查":            "check",
+	"✅ 约束规则 %s 已启用": "✅ Constraint rule %s enabled",
+	"✅ 约束规则 %s 已停用": "✅ Constraint rule %s disabled",
+	"暂无约束规则。使用 manager_constraints(mode=\"add\") 添加。": "No constraint rules yet. Add one with manager_constraints(mode=\"add\").",
+	"### 📏 约束规则 (%d)\n\n":                             "### 📏 Constraint Rules (%d)\n\n",
+	"  - 适用: %s\n":                                    "  - Applies to: %s\n",
+	"  - 定义: %s\n":                                    "  - Definition: %s\n",
+	"**意图增强协议** (Prompt Enhancement Protocol)\n\n":    "**Prompt Enhancement Protocol**\n\n",
+	"📜 **协议**: %s (%s)\n":                             "📜 **Protocol**: %s (%s)\n",
+	"**选择依据**: %s":                                    "**Chosen because**: %s",
+	"；意图 %s":                                          "; intent %s",
+	"\n\n**可用协议**:\n":                                 "\n\n**Available protocols**:\n",
+	" (意图: %s)":                                       " (intent: %s)",
+	"\n---\n**渲染结果**:\n":                              "\n---\n**Rendered**:\n",
+	"⚡ **【意图增强协议已激活】**\n\n":                           "⚡ **[Prompt Enhancement Protocol Active]**\n\n",
+	"⚡ **【意图增强协议已激活: %s】**\n\n":                       "⚡ **[Prompt Enhancement Protocol Active: %s]**\n\n",
+	"\n⚠️ 以下协议文件有误，已忽略:\n- %s\n":                      "\n⚠️ These protocol files are invalid and were ignored:\n- %s\n",
+	"\n\n请立即按照上述协议处理以下任务：\n":                          "\n\nHandle the following task right away, following the protocol above:\n",
+	"🔹 第一步：输出 `[ ]` 格式的任务清单\n":                        "🔹 Step 1: output a task list in `[ ]` format\n",
+	"🔹 第二步：立即开始执行，不要等待确认":                             "🔹 Step 2: start right away without waiting for confirmation",
+	"通用问题求解与任务执行":                                     "General problem solving and task execution",
+	"项目未初始化，无法持久化人格库":                                 "The project is not initialized; cannot persist personas",
+	"[RELAY_REQUIRED] 请将以下内容直接输出到对话框，不要省略。\n":         "[RELAY_REQUIRED] Output the following verbatim in the conversation without omitting anything.\n",
+	"可用人格列表（共 %d 个）\n":                                "Available personas (%d)\n",
+	" ★当前":                                            " ★current",
+	"  继承: %s\n":                                      "  Extends: %s\n",
+	"  别名: %s\n":                                      "  Aliases: %s\n",
+	"  触发词: %s\n":                                     "  Triggers: %s\n",
+	"\n可直接说：激活人格 <名称>\n":                              "\nJust say: activate persona <name>\n",
+	"[RELAY_REQUIRED] 请将以下内容直接输出到对话框，不要省略。\n🎭 人格已激活：%s (%s)\n> %s\n\n[POST_ACTIVATE_BEHAVIOR] 请立刻以该人格语气再回复用户一句“已到位”的开场白（1 句话）。": "[RELAY_REQUIRED] Output the following verbatim in the conversation without omitting anything.\n🎭 Persona activated: %s (%s)\n> %s\n\n[POST_ACTIVATE_BEHAVIOR] Right away, reply to the user with one \"ready\" opening line in this persona's voice (one sentence).",
+	"请立刻以当前人格语气向用户说一句到位开场白（仅一句）。":                        "Right away, say one opening line to the user in the current persona's voice (one sentence only).",
+	"从现在开始，请在后续回复中遵循该人格的语言与风格设定；仅改变表达风格，不得污染代码、日志与命令输出。": "From now on, follow this persona's language and style in your replies; change only the tone, never code, logs or command output.",
+	"回答保持专业、准确、简洁。":                   "Keep answers professional, accurate and concise.",
+	"✅ 人格包导入完成 (新增 %d，覆盖 %d，跳过 %d)\n": "✅ Persona pack imported (%d added, %d overwritten, %d skipped)\n",
+	"- 新增: %s\n": "- Added: %s\n",
+	"- 覆盖: %s\n": "- Overwritten: %s\n",
+	"- 跳过 (已存在，需 overwrite=true): %s\n":                                                     "- Skipped (already exists; needs overwrite=true): %s\n",
+	"调用 manager_analyze(step=2, task_id=\"%s\") 生成战术策略":                                     "Call manager_analyze(step=2, task_id=\"%s\") to generate the tactical strategy",
+	"执行时用 task_chain(mode=\"step\", task_id=\"...\", analysis_id=\"%s\", plan=[...]) 关联本简报": "When executing, link this briefing with task_chain(mode=\"step\", task_id=\"...\", analysis_id=\"%s\", plan=[...])",
+	"本简报由任务链 %s 执行中":                                                                        "This briefing is being executed by task chain %s",
+	"[任务意图]: %s":                                                                            "[Task intent]: %s",
+	"[情报评估与建议]":                                                                             "[Assessment and advice]",
+	"!!! CRITICAL: 未定位到任何代码符号 !!!":                                                          "!!! CRITICAL: no code symbols located !!!",
+	"建议：使用 project_map 查看项目结构，或检查 symbols 参数是否正确":                                           "Advice: use project_map to view the project structure, or check the symbols argument",
+	"已定位到 %d 个代码符号":                                                                         "Located %d code symbols",
+	"!!! 任务复杂度极高 !!!":                                                                       "!!! Task complexity is very high !!!",
+	"建议：使用 code_impact 先分析影响范围，避免遗漏依赖关系":                                                    "Advice: run code_impact first to map the impact and avoid missing dependencies",
+	"任务复杂度中等，建议谨慎处理":                                                                        "Task complexity is moderate; proceed carefully",
+	"任务复杂度较低，可直接开始":                                                                         "Task complexity is low; you can start right away",
+	"[Vibe Coding 规范]":                                                                      "[Vibe Coding Rules]",
+	"✅ 建议：AI友好命名，函数名即文档，最简代码，如无必要勿增实体":                                                      "✅ Do: AI-friendly names, function names as documentation, minimal code, no entities without need",
+	"❌ 禁止：并行调试系统，遗留代码不清理，走捷径保留原路，擅自写文档":                                                     "❌ Don't: parallel debugging systems, leaving legacy code behind, shortcuts that keep the old path, writing docs unprompted",
+	"[Tool Strategy - 基于情报分析]":                                                              "[Tool Strategy - from the assessment]",
+	"• 优先使用 project_map 了解项目结构":                                                             "• Start with project_map to learn the project structure",
+	"• 使用 code_search 精确定位代码符号":                                                             "• Use code_search to locate code symbols precisely",
+	"• 已定位代码，可直接使用 code_impact 分析影响范围":                                                      "• Code is located; use code_impact to analyze the impact",
+	"• 修改代码后务必使用 memo 记录":                                                                   "• Always record changes with memo after editing code",
+	"• 相关技能：%s，动手前可用 skill_load 加载":                                                         "• Related skills: %s; load them with skill_load before starting",
+	"[你的判断]": "[Your judgment]",
+	"以上情报基于实际代码分析生成。请根据情报充分性判断是否需要补充调研。": "The intelligence above comes from real code analysis. Judge whether it is sufficient or whether more research is needed.",
+	"你拥有完全自主权。":                       "You have full autonomy.",
+	"cursor 无效，请使用上一页返回的 next_cursor": "Invalid cursor; use the next_cursor returned by the previous page",
+	"项目地图已变化 (索引更新或 level/scope 不同)，cursor 已失效，请去掉 cursor 重新获取第一页": "The project map has changed (index updated or different level/scope), so the cursor is no longer valid; drop cursor to fetch the first page again",
+	"### 🗺️ 项目地图 (Symbols) · 文件 %d-%d / %d\n\n":                    "### 🗺️ Project Map (Symbols) · files %d-%d / %d\n\n",
+	"**🔥 复杂度**: High: %d | Med: %d | Low: %d | Avg: %.1f\n":        "**🔥 Complexity**: High: %d | Med: %d | Low: %d | Avg: %.1f\n",
+	"\n---\n➡️ 还有 %d 个文件，继续查看：`cursor=\"%s\"` (其余参数保持不变)\n":        "\n---\n➡️ %d more files; continue with `cursor=\"%s\"` (keep the other arguments unchanged)\n",
+	"cursor 越界，请去掉 cursor 重新获取第一页":                                 "Cursor is out of range; drop cursor to fetch the first page again",
+	"- ... (还有 %d 个低复杂度目录)\n":                                      "- ... (%d more low-complexity directories)\n",
+	"### 🗺️ 项目地图 (Symbols)\n\n":                                    "### 🗺️ Project Map (Symbols)\n\n",
+	"**📊 范围统计**: %d files | %d symbols\n":                          "**📊 Scope**: %d files | %d symbols\n",
+	"**🔥 复杂度**: High: %d | Med: %d | Low: %d | Avg: %.1f\n\n":      "**🔥 Complexity**: High: %d | Med: %d | Low: %d | Avg: %.1f\n\n",
+	"### 🗺️ 项目地图 (Structure)\n\n":                                  "### 🗺️ Project Map (Structure)\n\n",
+	"**📊 统计**: %d 文件 | %d 符号\n\n":                                  "**📊 Stats**: %d files | %d symbols\n\n",
+	"  - ... (还有 %d 个低复杂度文件)\n":                                    "  - ... (%d more low-complexity files)\n",
+	"**📁 目录结构** (按复杂度排序):\n":                                       "**📁 Directory Structure** (by complexity):\n",
+	"**🎯 Top复杂符号**:\n":                                             "**🎯 Most Complex Symbols**:\n",
+	" (CC:%d, Cog:%d, Nest:%d, %d行)":                               " (CC:%d, Cog:%d, Nest:%d, %d lines)",
+	"开发":                                                           "Development",
+	"#### 📁 目录变化\n":                                                "#### 📁 Directory Changes\n",
+	", 热点 %+d":                                                     ", hotspots %+d",
+	"- **%s/**: Avg %.1f → %.1f, 符号 %+d%s\n":                       "- **%s/**: Avg %.1f → %.1f, symbols %+d%s\n",
+	"### 📸 指标快照\n\n":                                               "### 📸 Metric Snapshots\n\n",
+	"暂无快照。每次索引 (initialize_project / project_map / code_impact) 会在指标变化时自动记录。\n": "No snapshots yet. Each index run (initialize_project / project_map / code_impact) records one automatically when metrics change.\n",
+	"| ID | 时间 | Git | 文件 | 符号 | 平均复杂度 | 热点 | 最近索引 |\n":                         "| ID | Time | Git | Files | Symbols | Avg complexity | Hotspots | Last indexed |\n",
+	"%s @ `%s` (共 %d 次)": "%s @ `%s` (%d runs)",
+	"### 📈 复杂度趋势\n\n":    "### 📈 Complexity Trend\n\n",
+	"**对比**: %s → %s\n":  "**Comparing**: %s → %s\n",
+	"**概览**: +%d 新增 | -%d 删除 | ↑%d 变复杂 | ↓%d 变简单 | 🔥%d 新热点\n\n": "**Overview**: +%d added | -%d removed | ↑%d more complex | ↓%d simpler | 🔥%d new hotspots\n\n",
+	"✅ 两个快照之间无复杂度变化\n":                                          "✅ No complexity changes between the two snapshots\n",
+	"#### 🔥 新热点 (复杂度 ≥ 50)\n":                                   "#### 🔥 New Hotspots (complexity ≥ 50)\n",
+	"#### ↑ 复杂度上升\n":                                            "#### ↑ Complexity Up\n",
+	"#### ↓ 复杂度下降\n":                                            "#### ↓ Complexity Down\n",
+	"输出格式：markdown=给人读的文本；json=结构化结果 (schema=%s，成功与失败结构一致)": "Output format: markdown = human-readable text; json = structured result (schema=%s, same shape for success and failure)",
+	"人格 %s 继承的 %s 不存在":                               "Persona %s extends %s, which does not exist",
+	"人格 %s 的继承链存在循环 (%s)":                            "Persona %s has an inheritance cycle (%s)",
+	"人格 %s 的继承链超过 %d 层":                              "Persona %s has an inheritance chain deeper than %d levels",
+	"第 %d 个人格缺少 name":                                "Persona #%d is missing name",
+	"人格 %s 的 style_merge 无效: %s (可选 replace/append)": "Persona %s has an invalid style_merge: %s (use replace/append)",
+	"人格包不是有效的 JSON: %v":                              "The persona pack is not valid JSON: %v",
+	"人格包版本 %d 高于当前支持的版本 %d":                          "Persona pack version %d is newer than the supported version %d",
+	"人格包中没有人格":                                       "The persona pack contains no personas",
+	"人格包中存在缺少 name 的人格":                              "The persona pack contains a persona without name",
+	"人格包中人格 %s 重复":                                   "Persona %s appears more than once in the pack",
+	"🎭 **人格已自动激活**: %s (%s)，触发词「%s」\n%s\n":           "🎭 **Persona auto-activated**: %s (%s), trigger \"%s\"\n%s\n",
+	"\n\n⚠️ 上次激活的人格 %s 无法加载 (%v)，已恢复默认风格。":           "\n\n⚠️ The last active persona %s could not be loaded (%v); the default style is restored.",
+	"\n\n### 🎭 人格恢复\n已恢复上次激活的人格: %s (%s)\n%s":        "\n\n### 🎭 Persona Restored\nRestored the last active persona: %s (%s)\n%s",
+	"战术执行协议：建立边界 -> 历史探测 -> 意图解析 -> 现状映射 -> 输出清单 -> 立即执行": "Tactical execution protocol: set boundaries -> probe history -> parse intent -> map current state -> output checklist -> execute now",
+	"协议名无效: %q (仅限字母、数字、- 和 _)":                           "Invalid protocol name: %q (letters, digits, - and _ only)",
+	"协议正文为空":                  "The protocol body is empty",
+	"模板语法错误: %v":              "Template syntax error: %v",
+	"指定名称":                    "specified by name",
+	"未找到协议 %s (可用: %s)":       "Protocol %s not found (available: %s)",
+	"意图 %s":                   "intent %s",
+	"默认":                      "default",
+	"协议 %s 模板语法错误: %v":        "Protocol %s has a template syntax error: %v",
+	"协议 %s 渲染失败: %v":          "Failed to render protocol %s: %v",
+	"_无需修改任何文件_\n":            "_No files need changes_\n",
+	"### 📝 补丁 (%d 个文件，未应用)\n": "### 📝 Patch (%d files, not applied)\n",
+	"_补丁包含 possible 引用；如需只改 AST 确认的位置，设置 certain_only=true_\n": "_The patch includes possible references; to change only AST-confirmed locations, set certain_only=true_\n",
+	"重命名为 `%s` 并移动到 `%s`":                                 "rename to `%s` and move to `%s`",
+	"重命名为 `%s`":                                           "rename to `%s`",
+	"移动到 `%s`":                                            "move to `%s`",
+	"## 🔧 重构预览: `%s` %s\n\n":                              "## 🔧 Refactor Preview: `%s` %s\n\n",
+	"**定义**: %s `%s` @ `%s` L%d-%d\n":                     "**Definition**: %s `%s` @ `%s` L%d-%d\n",
+	"**引用**: ✅ %d certain (AST) | ❓ %d possible (文本)\n\n": "**References**: ✅ %d certain (AST) | ❓ %d possible (text)\n\n",
+	"- ... 还有 %d 处\n":                                     "- ... %d more\n",
+	"### ✅ Certain (AST 确认)":                              "### ✅ Certain (AST-confirmed)",
+	"### ❓ Possible (仅文本匹配，请人工确认)":                        "### ❓ Possible (text match only; confirm manually)",
+	"### 🔎 「%s」文本搜索: 超过 %d 处匹配，%d 个文件\n\n":                "### 🔎 Text search \"%s\": more than %d matches in %d files\n\n",
+	"_仅显示前 %d 处，可缩小 scope 或提高 max_results_\n\n":           "_Showing only the first %d; narrow scope or raise max_results_\n\n",
+	"### 🔎 「%s」文本搜索: %d 处匹配，%d 个文件\n\n":                   "### 🔎 Text search \"%s\": %d matches in %d files\n\n",
+	"### 关于「%s」的搜索结果\n\n":                                 "### Search results for \"%s\"\n\n",
+	"✅ **精确定义** (%s):\n":                                  "✅ **Exact definition** (%s):\n",
+	"🔍 **相似符号** (AST):\n":                                 "🔍 **Similar symbols** (AST):\n",
+	"🕵️ **文本搜索结果** (Ripgrep found %d matches):\n":         "🕵️ **Text search results** (Ripgrep found %d matches):\n",
+	"... (剩余 %d 个文件的匹配已省略)\n":                             "... (matches in %d more files omitted)\n",
+	"  ... (本文件还有 %d 处匹配)\n":                              "  ... (%d more matches in this file)\n",
+	"⚠️ **未找到「%s」** → 换词重试（同义词/缩写/驼峰变体），或用 `project_map` 先看结构\n": "⚠️ **\"%s\" not found** → try other words (synonyms/abbreviations/camelCase variants), or look at the structure with `project_map` first\n",
+	"### 🧠 「%s」概念搜索结果\n\n":                                         "### 🧠 Concept search results for \"%s\"\n\n",
+	"⚠️ **未找到与「%s」相关的符号** → 换个说法，或直接用符号名搜索\n":                      "⚠️ **No symbols related to \"%s\"** → rephrase, or search by symbol name directly\n",
+	"%d. [%s] `%s` @ `%s` L%d-%d (score: %.2f, 命中: %s)\n":          "%d. [%s] `%s` @ `%s` L%d-%d (score: %.2f, matched: %s)\n",
+	"\n_排序基于名称/签名/文档注释/路径的 BM25 相关度，确认目标后可用 code_impact 查看影响范围_\n": "\n_Ranked by BM25 relevance over name/signature/doc comment/path; once you find the target, use code_impact to see its impact_\n",
+	"#### 🪝 待办钩子 (open %d，已过期 %d)\n":                               "#### 🪝 Open Hooks (open %d, overdue %d)\n",
+	"- ... 还有 %d 个，用 `manager_list_hooks` 查看\n":                    "- ... %d more; see `manager_list_hooks`\n",
+	"#### 📝 最近备忘 (%d)\n":                                           "#### 📝 Recent Memos (%d)\n",
+	"#### 📌 最近铁律 (%d)\n":                                           "#### 📌 Recent Facts (%d)\n",
+	"#### 📄 上次会话后修改的文件 (%d)\n":                                     "#### 📄 Files Changed Since the Last Session (%d)\n",
+	"%d 分钟前":         "%d minutes ago",
+	"%d 小时前":         "%d hours ago",
+	"%d 天前":          "%d days ago",
+	"### 📋 会话交接\n\n": "### 📋 Session Handoff\n\n",
+	"**上次会话**: 无记录（首次初始化）\n":                            "**Last session**: none (first initialization)\n",
+	"**上次会话**: %s (%s)\n":                               "**Last session**: %s (%s)\n",
+	"\n✅ 没有进行中的任务链、待办或历史记录，可以直接开始新任务。\n":                "\n✅ No running task chains, open hooks or history; you can start a new task right away.\n",
+	"#### 🔗 进行中的任务链 (%d)\n":                             "#### 🔗 Running Task Chains (%d)\n",
+	"- **%s**%s — %d/%d 完成，当前: %s\n":                    "- **%s**%s — %d/%d done, current: %s\n",
+	"未知模式: %s (可选 install/uninstall/versions/validate)": "Unknown mode: %s (use install/uninstall/versions/validate)",
+	"项目尚未初始化，无法安装到 project 范围":                          "The project is not initialized; cannot install into the project scope",
+	"无法确定全局目录，请设置 MPM_HOME":                             "Cannot determine the global directory; set MPM_HOME",
+	"未知范围: %s (可选 project/global)":                      "Unknown scope: %s (use project/global)",
+	"技能包校验失败:\n- %s":                                    "Skill package validation failed:\n- %s",
+	"%s@%s 已安装，使用 force=true 重新安装":                      "%s@%s is already installed; use force=true to reinstall",
+	"拒绝降级 %s: 已安装 %s，待安装 %s (使用 force=true 强制)":         "Refusing to downgrade %s: %s is installed, %s requested (use force=true to force)",
+	"移除旧版本失败: %v":                                       "Failed to remove the old version: %v",
+	"安装失败: %v":                                          "Install failed: %v",
+	"✅ 已将 %s 从 %s 更新到 %s (%s)\n":                        "✅ Updated %s from %s to %s (%s)\n",
+	"✅ 已安装 %s@%s (%s)\n":                                "✅ Installed %s@%s (%s)\n",
+	"\n> 使用 `skill_load(name=\"%s\")` 加载。":              "\n> Load it with `skill_load(name=\"%s\")`.",
+	"%s 范围内未安装技能 %s":                                    "Skill %[2]s is not installed in the %[1]s scope",
+	"卸载失败: %v":                                          "Uninstall failed: %v",
+	"🗑️ 已卸载 %s@%s (%s)":                                 "🗑️ Uninstalled %s@%s (%s)",
+	"#### 📦 已安装技能\n":                                    "#### 📦 Installed Skills\n",
+	"\n**%s** (%s): %d 个\n":                             "\n**%s** (%s): %d\n",
+	"手动放置":                                              "placed manually",
+	"%s 安装自 %s":                                         "%s installed from %s",
+	"未标注":                                               "unspecified",
+	"请提供 source":                                        "Provide source",
+	"✅ 技能包有效: %s@%s\n":                                  "✅ Valid skill package: %s@%s\n",
+	"❌ 技能包无效 (%d 个错误)\n":                                "❌ Invalid skill package (%d errors)\n",
+	"缺少 SKILL.md":                                       "SKILL.md is missing",
+	"读取 SKILL.md 失败: %v":                                "Failed to read SKILL.md: %v",
+	"SKILL.md 缺少 frontmatter (--- 包裹的 YAML 头)":          "SKILL.md has no frontmatter (a YAML header wrapped in ---)",
+	"frontmatter 缺少 name":                               "frontmatter is missing name",
+	"name %q 只能包含字母、数字、点、下划线和连字符":                       "name %q may only contain letters, digits, dots, underscores and hyphens",
+	"frontmatter 缺少 description":                        "frontmatter is missing description",
+	"未声明 version，按 0.0.0 记录":                            "No version declared; recorded as 0.0.0",
+	"version %q 不是有效的语义化版本 (如 1.2.0)":                   "version %q is not a valid semantic version (e.g. 1.2.0)",
+	"trigger 中有空项":                                      "trigger has empty entries",
+	"%s 应为目录":                                           "%s should be a directory",
+	"%s/ 为空":                                            "%s/ is empty",
+	"非约定目录 %s/ (约定: references/ scripts/ assets/)，skill_load 不会列出": "Non-standard directory %s/ (standard: references/ scripts/ assets/); skill_load will not list it",
+	"无法读取技能包: %v": "Cannot read the skill package: %v",
+	"不支持的技能包格式: %s (支持目录、.zip、.tar、.tar.gz、.tgz)": "Unsupported skill package format: %s (directory, .zip, .tar, .tar.gz and .tgz are supported)",
+	"压缩包包含绝对路径: %s":                               "The archive contains an absolute path: %s",
+	"压缩包路径越界: %s":                                 "The archive path escapes the target: %s",
+	"技能包解压后超过 %d MB":                              "The extracted skill package exceeds %d MB",
+	"打开 zip 失败: %v":                               "Failed to open the zip: %v",
+	"解压 gzip 失败: %v":                              "Failed to decompress gzip: %v",
+	"读取 tar 失败: %v":                               "Failed to read the tar: %v",
+	"不支持符号链接: %s":                                 "Symbolic links are not supported: %s",
+	"描述 %s":                                       "description %s",
+	"分类 %s":                                       "category %s",
+	"📚 **推荐技能**:\n":                               "📚 **Recommended skills**:\n",
+	"> 需要时用 `skill_load(name=\"...\")` 加载专家指导。\n": "> Load expert guidance with `skill_load(name=\"...\")` when needed.\n",
+	"触发词 %s":   "trigger %s",
+	"名称 %s":    "name %s",
+	"未找到技能 %s": "Skill %s not found",
+	"无":        "none",
+	"技能 %s 的 scripts/ 中没有 %s (可用: %s)": "Skill %s has no %s in scripts/ (available: %s)",
+	"启动脚本失败: %v":                       "Failed to start the script: %v",
+	"工作目录无效: %v":                       "Invalid working directory: %v",
+	"工作目录不存在: %s":                      "Working directory does not exist: %s",
+	"无法解析工作目录: %s":                     "Cannot resolve the working directory: %s",
+	"工作目录 %s 不在项目目录内":                  "Working directory %s is outside the project directory",
+	"未找到 %s 脚本的解释器 (%s)":               "No interpreter found for the %s script (%s)",
+	"%s 不是可执行脚本 (支持 .py .sh .js .rb .ps1 或带可执行权限的文件)": "%s is not an executable script (.py .sh .js .rb .ps1 or files with the executable bit are supported)",
+	"环境变量名无效: %q":                                "Invalid environment variable name: %q",
+	"不允许设置环境变量 %s":                               "Setting environment variable %s is not allowed",
+	"#### 发现 %d 个可用技能\n\n":                       "#### Found %d available skills\n\n",
+	"\n**⚠️ 冲突 (%d)**:\n":                        "\n**⚠️ Conflicts (%d)**:\n",
+	"\n> 使用 `skill_load(name=\"...\")` 加载完整内容。":  "\n> Load the full content with `skill_load(name=\"...\")`.",
+	"#### 🔎 技能匹配 (%d/%d)\n\n":                    "#### 🔎 Skill Matches (%d/%d)\n\n",
+	"   - 命中: %s\n":                              "   - Matched: %s\n",
+	"\n> 使用 `skill_load(name=\"%s\")` 加载最匹配的技能。": "\n> Load the best match with `skill_load(name=\"%s\")`.",
+	"未匹配到技能 \"%s\"。":                             "No skill matches \"%s\".",
+	" 你是不是想找: %s?":                               " Did you mean: %s?",
+	"\n\n## 可用资源 (Bundled Resources)\n":          "\n\n## Bundled Resources\n",
+	"\n> 若需加载子资源，请使用 `skill_load(name=\"...\", resource=\"references/xxx.md\")`。": "\n> To load a sub-resource, use `skill_load(name=\"...\", resource=\"references/xxx.md\")`.",
+	"\n> scripts/ 中的脚本可用 `skill_run(skill=\"%s\", script=\"...\")` 执行。":           "\n> Scripts in scripts/ can be run with `skill_run(skill=\"%s\", script=\"...\")`.",
+	"同名技能":                             "Same-name skill",
+	"目录别名":                             "Directory alias",
+	"- %s `%s` 有歧义，已停用: %s\n":          "- %s `%s` is ambiguous and disabled: %s\n",
+	"- %s `%s` 使用 %s，已遮蔽: %s\n":        "- %s `%s` uses %s; shadowed: %s\n",
+	"移入 %d":                            "moved in %d",
+	"签名变化 %d":                          "signature changes %d",
+	"#### 📄 文件\n":                      "#### 📄 Files\n",
+	"#### ✏️ 签名变化\n":                   "#### ✏️ Signature Changes\n",
+	"- %s\n  - 旧: `%s`\n  - 新: `%s`\n": "- %s\n  - Old: `%s`\n  - New: `%s`\n",
+	"#### ↪ 移动\n":                      "#### ↪ Moves\n",
+	"#### ➕ 新增符号\n":                    "#### ➕ Added Symbols\n",
+	"#### ➖ 删除符号\n":                    "#### ➖ Removed Symbols\n",
+	"\n\n⚠️ 结构快照记录失败: %v":              "\n\n⚠️ Failed to record the structure snapshot: %v",
+	"\n\n📸 已记录结构快照，下次初始化时将汇总期间的结构变化 (structure_diff)。": "\n\n📸 Structure snapshot recorded; the next initialization will summarize the structure changes since then (structure_diff).",
+	"### 📸 结构快照\n\n": "### 📸 Structure Snapshots\n\n",
+	"暂无快照。每次 initialize_project 会在结构变化时自动记录。\n":                "No snapshots yet. Each initialize_project records one automatically when the structure changes.\n",
+	"| ID | 时间 | Git | 文件 | 符号 |\n":                            "| ID | Time | Git | Files | Symbols |\n",
+	"### 🧭 结构变化\n\n":                                           "### 🧭 Structure Changes\n\n",
+	"**对比**: 快照 %s → 当前代码\n":                                   "**Comparing**: snapshot %s → current code\n",
+	"**范围**: `%s`\n":                                           "**Scope**: `%s`\n",
+	"**过滤**: 仅公开符号\n":                                          "**Filter**: exported symbols only\n",
+	"**概览**: 文件 +%d/-%d | 符号 +%d/-%d | ↪%d 移动 | ✏️%d 签名变化\n\n": "**Overview**: files +%d/-%d | symbols +%d/-%d | ↪%d moved | ✏️%d signature changes\n\n",
+	"✅ 快照之后没有结构变化\n":                                           "✅ No structure changes since the snapshot\n",
+	"#### 📁 模块\n":                                              "#### 📁 Modules\n",
+	"文件 +%d/-%d":                                               "files +%d/-%d",
+	"符号 +%d/-%d":                                               "symbols +%d/-%d",
+	"\n\n⚠️ 意图规则文件有误，已回退:\n- %s":                               "\n\n⚠️ The intent rules file is invalid; fell back to defaults:\n- %s",
+	"\n\n⚠️ 项目配置 (mpm.yaml) 有误，已回退:\n- %s":                     "\n\n⚠️ The project config (mpm.yaml) is invalid; fell back to defaults:\n- %s",
+	"⚠️ (索引失败: %v)":                                            "⚠️ (indexing failed: %v)",
+	"\n\n[NEW] 已同步项目规则模板: _MPM_PROJECT_RULES.md\nIDE 将自动加载更新后的规则。": "\n\n[NEW] Project rules template synced: _MPM_PROJECT_RULES.md\nThe IDE will load the updated rules automatically.",
+	"所有步骤已完成，待 finish": "All steps done; waiting for finish",
+	"执行中":              "running",
+	"待开始":              "pending",
+	"✅ 已插入 %d 个新步骤到 Step %.1f 之后\n\n": "✅ Inserted %d new steps after Step %.1f\n\n",
+	"**新增步骤**:\n":                    "**New steps**:\n",
+	"\n**当前步骤总数**: %d\n":             "\n**Total steps now**: %d\n",
+	"✅ 已从 Step %.1f 开始更新 %d 个步骤\n\n": "✅ Updated steps from Step %.1f onward (%d steps)\n\n",
+	"**更新后的步骤**:\n":                  "**Updated steps**:\n",
+	"### 📋 Hook 列表 (%s)\n\n":         "### 📋 Hooks (%s)\n\n",
+	"\n---\n**下一步**:\n1️⃣ 有进行中的任务链 → 按上方 → 提示的调用继续\n2️⃣ 有到期/待办钩子 → 先处理或用 manager_release_hook 释放\n3️⃣ 都已完成 → 调用 memo 记录结果并向用户汇报\n": "\n---\n**Next**:\n1️⃣ Running task chains → continue with the call shown after → above\n2️⃣ Due or open hooks → handle them first or release them with manager_release_hook\n3️⃣ All done → record the result with memo and report to the user\n",
+	"%s (用 full 模式查看完整代码)":         "%s (use full mode to see the complete code)",
+	"%s (用 overview 模式)":           "%s (use overview mode)",
+	"%s (用 standard 模式)":           "%s (use standard mode)",
+	"%s (设置 search_type=function)": "%s (set search_type=function)",
+	"%s (设置 search_type=class)":    "%s (set search_type=class)",
+	"%s (设置 direction=backward)":   "%s (set direction=backward)",
+	"%s (设置 direction=forward)":    "%s (set direction=forward)",
+	"### 🚀 任务链已初始化: %s\n\n":        "### 🚀 Task Chain Initialized: %s\n\n",
+	"**总步骤**: %d\n\n":              "**Total steps**: %d\n\n",
+	"**执行计划**:\n":                  "**Plan**:\n",
+	"\n\n> 请执行第 1 步，完成后调用 `task_chain(mode=\"next\", task_id=\"%s\")`。": "\n\n> Run step 1, then call `task_chain(mode=\"next\", task_id=\"%s\")`.",
+	"👉 **Next Step (%d/%d)**: %s\n\n_(Remaining Steps: %d)_\n\n---\n💡 **Dynamic Decision**:\n- 如步骤合理 -> **执行**\n- 如发现遗漏 -> 调用 `task_chain(mode='insert')` **增加**步骤\n- 如步骤多余 -> 调用 `task_chain(mode='delete')` **跳过**": "👉 **Next Step (%d/%d)**: %s\n\n_(Remaining Steps: %d)_\n\n---\n💡 **Dynamic Decision**:\n- Step makes sense -> **run it**\n- Something is missing -> call `task_chain(mode='insert')` to **add** steps\n- Step is unnecessary -> call `task_chain(mode='delete')` to **skip** it",
+	"(无内存状态)":            "(no in-memory state)",
+	"进度: %d/%d, 当前步: %s": "Progress: %d/%d, current step: %s",
+	"✅ 已插入 %d 个新步骤到当前位置之后 (Total: %d)。": "✅ Inserted %d new steps after the current position (Total: %d).",
+	"✅ 已插入 %d 个新步骤 (无状态模式)。":            "✅ Inserted %d new steps (stateless mode).",
+	"\n══════════════════════════════════════════════════════════════\n                    【任务链完成】%s\n══════════════════════════════════════════════════════════════\n\n任务已标记为完成。\n\n下一步建议：\n  → 调用 memo 工具记录最终结果\n  → 向用户汇报任务完成\n": "\n══════════════════════════════════════════════════════════════\n                    [Task Chain Complete] %s\n══════════════════════════════════════════════════════════════\n\nThe task is marked as complete.\n\nSuggested next steps:\n  → Record the final result with the memo tool\n  → Report completion to the user\n",
+	"\n══════════════════════════════════════════════════════════════\n                    【Step %.1f 开始】%s\n══════════════════════════════════════════════════════════════\n\n**任务描述**: %s\n\n**当前步骤**: %s\n":                      "\n══════════════════════════════════════════════════════════════\n                    [Step %.1f Started] %s\n══════════════════════════════════════════════════════════════\n\n**Task**: %s\n\n**Current step**: %s\n",
+	"**分析简报**: %s (manager_analyze(step=2, task_id=\"%s\") 可重新生成策略)\n": "**Analysis briefing**: %s (manager_analyze(step=2, task_id=\"%s\") can regenerate the strategy)\n",
+	"\n**建议调用**: %s\n": "\n**Suggested call**: %s\n",
+	"\n---\n\n⚠️ **重要**: 完成此步骤后，必须调用：\n\ntask_chain(mode=\"complete\", task_id=\"%s\", step_number=%.1f, summary=\"你的总结\")\n\n**总结应包含**:\n- 这一步做了什么\n- 得到了什么关键结论\n- 对后续步骤的影响\n\n**💡 提示**: 在此步骤中，你可以调用任意工具来完成目标。\n所有中间过程的 context 都应在最终的 summary 中提炼总结。\n\n══════════════════════════════════════════════════════════════\n": "\n---\n\n⚠️ **Important**: when this step is done you must call:\n\ntask_chain(mode=\"complete\", task_id=\"%s\", step_number=%.1f, summary=\"your summary\")\n\n**The summary should cover**:\n- What this step did\n- The key conclusions\n- The impact on later steps\n\n**💡 Tip**: you may call any tool during this step to reach the goal.\nDistill all intermediate context into the final summary.\n\n══════════════════════════════════════════════════════════════\n",
+	"\n══════════════════════════════════════════════════════════════\n                    【Step %.1f 已完成】%s\n══════════════════════════════════════════════════════════════\n\n**Summary**: %s\n\n---\n\n## 🤔 决策时间\n\n根据此步骤的总结，请决定下一步操作：\n\n":                                                                              "\n══════════════════════════════════════════════════════════════\n                    [Step %.1f Done] %s\n══════════════════════════════════════════════════════════════\n\n**Summary**: %s\n\n---\n\n## 🤔 Decision Time\n\nBased on this step's summary, decide what to do next:\n\n",
+	"1️⃣ **继续下一步** (Step %.1f)\n   task_chain(mode=\"start\", task_id=\"%s\", step_number=%.1f)\n\n": "1️⃣ **Continue to the next step** (Step %.1f)\n   task_chain(mode=\"start\", task_id=\"%s\", step_number=%.1f)\n\n",
+	"1️⃣ **完成整个任务链**\n   task_chain(mode=\"finish\", task_id=\"%s\")\n\n":                            "1️⃣ **Finish the whole task chain**\n   task_chain(mode=\"finish\", task_id=\"%s\")\n\n",
+	"2️⃣ **插入新步骤**（在当前步骤 %.1f 之后）\n   task_chain(mode=\"insert\", task_id=\"%s\", after=%.1f, insert_plan=[\n     {name: \"新步骤名称\", input: \"建议的工具调用\"}\n   ])\n\n3️⃣ **删除剩余步骤**\n   task_chain(mode=\"delete\", task_id=\"%s\", from=%.1f, delete_scope=\"remaining\")\n\n---\n\n## 📋 已完成步骤预览\n": "2️⃣ **Insert new steps** (after the current step %.1f)\n   task_chain(mode=\"insert\", task_id=\"%s\", after=%.1f, insert_plan=[\n     {name: \"new step name\", input: \"suggested tool call\"}\n   ])\n\n3️⃣ **Delete the remaining steps**\n   task_chain(mode=\"delete\", task_id=\"%s\", from=%.1f, delete_scope=\"remaining\")\n\n---\n\n## 📋 Completed Steps\n",
+	"\n## 📋 剩余步骤预览\n\n": "\n## 📋 Remaining Steps\n\n",
+	"🎉 所有步骤已完成！\n":      "🎉 All steps are done!\n",
+	"(项目根目录)":           "(project root)",
+	"# 📦 %s\n\n%s\n\n## 概述\n\n_在此补充本包的职责与使用方式（手写内容，重新生成时保留）。_\n\n%s\n\n%s\n\n%s\n": "# 📦 %s\n\n%s\n\n## Overview\n\n_Describe what this package does and how to use it here (hand-written; kept on regeneration)._\n\n%s\n\n%s\n\n%s\n",
+	"# 📚 %s Wiki\n\n_在此补充项目简介（手写内容，重新生成时保留）。_\n\n{{index}}\n":                      "# 📚 %s Wiki\n\n_Add a project introduction here (hand-written; kept on regeneration)._\n\n{{index}}\n",
+	"- **文件** (%d): %s\n":        "- **Files** (%d): %s\n",
+	"- **符号**: %d 个，其中导出 %d 个\n": "- **Symbols**: %d, %d exported\n",
+	"## 导出符号\n\n":                "## Exported Symbols\n\n",
+	"_暂无导出符号_\n":                 "_No exported symbols_\n",
+	"\n_... 还有 %d 个导出符号未列出，可用 `code_search` 查询_\n": "\n_... %d more exported symbols not listed; query them with `code_search`_\n",
+	"## 依赖关系\n\n":         "## Dependencies\n\n",
+	"- %s — %d 次调用\n":     "- %s — %d calls\n",
+	"**依赖** (本包调用的其他包):":  "**Depends on** (packages this package calls):",
+	"**被依赖** (调用本包的其他包):": "**Used by** (packages that call this package):",
+	"_索引中没有跨包调用_\n":       "_No cross-package calls in the index_\n",
+	"## 设计决策\n\n":         "## Design Decisions\n\n",
+	"_暂无相关备忘（用 `memo` 记录本包的变更与决策后重新生成）_\n":                                              "_No related memos yet (record this package's changes and decisions with `memo`, then regenerate)_\n",
+	"## 包索引\n\n| 包 | 文件 | 导出符号 | 依赖 | 被依赖 |\n|----|------|---------|------|--------|\n": "## Package Index\n\n| Package | Files | Exported symbols | Depends on | Used by |\n|----|------|---------|------|--------|\n",
+	"区块起始标记不完整":    "Incomplete block start marker",
+	"区块 %s 缺少结束标记": "Block %s is missing its end marker",
+	"区块 %s 重复":     "Block %s appears more than once",
+	"> 🤖 本区块由 `wiki_writer(mode=\"generate\")` 根据索引生成，重新生成时会被覆盖；区块外的手写内容会保留。\n": "> 🤖 This block is generated from the index by `wiki_writer(mode=\"generate\")` and is overwritten on regeneration; hand-written content outside the block is kept.\n",
+	"✅ Wiki 已生成到 `%s/` (新建 %d，更新 %d，未变化 %d)\n":                                  "✅ Wiki generated in `%s/` (%d created, %d updated, %d unchanged)\n",
+	"- ... 还有 %d 个\n": "- ... %d more\n",
+	"新建":              "created",
+	"更新":              "updated",
+	"⚠️ 已跳过 (未写入)":    "⚠️ Skipped (not written)",
+	"输出目录不能是项目根目录，请指定子目录 (如 docs/wiki)":                     "The output directory cannot be the project root; use a subdirectory (e.g. docs/wiki)",
+	"页面中没有 mpm:begin 区块，不是 wiki_writer 生成的文件":               "The page has no mpm:begin blocks, so it was not generated by wiki_writer",
+	"⚠️ 对应目录已不在索引中 (未删除，请手动处理)":                             "⚠️ The directory is no longer in the index (not deleted; handle it manually)",
+	"\n> 手写内容请写在 `<!-- mpm:begin/end -->` 区块之外，重新生成时会保留。\n": "\n> Put hand-written content outside the `<!-- mpm:begin/end -->` blocks; it is kept on regeneration.\n",
+	"项目尚未初始化":                   "The project is not initialized",
+	"输出目录无效: %v":                "Invalid output directory: %v",
+	"索引中没有符号 (scope=%q)":        "No symbols in the index (scope=%q)",
+	"frontmatter 解析失败: %v":      "Failed to parse frontmatter: %v",
+	"风格名无效: %q (仅限字母、数字、- 和 _)": "Invalid style name: %q (letters, digits, - and _ only)",
+	"风格正文为空且未继承其他风格":            "The style body is empty and it extends no other style",
+	"风格 %s 存在循环继承":              "Style %s has an inheritance cycle",
+	"风格 %s 继承层级超过 %d":           "Style %s is nested deeper than %d levels",
+	"风格 %s 不存在":                 "Style %s does not exist",
+	"风格 %s 继承的 %s 不存在":          "Style %s extends %s, which does not exist",
+	"### 🎨 Wiki 书写风格\n\n":       "### 🎨 Wiki Writing Styles\n\n",
+	" ← 继承 `%s`":                " ← extends `%s`",
+	"\n✅ 全部风格校验通过\n":            "\n✅ All styles are valid\n",
+	"\n⚠️ 发现 %d 个问题：\n":         "\n⚠️ Found %d problems:\n",
+	"\n> 自定义风格放在 `.mcp-config/wiki_styles/*.md` (项目) 或 `~/.mpm/wiki_styles/*.md` (全局)，同名覆盖内置风格。\n": "\n> Put custom styles in `.mcp-config/wiki_styles/*.md` (project) or `~/.mpm/wiki_styles/*.md` (global); same-name styles override the built-ins.\n",
+	"技术文档风格（简洁专业）":                                     "Technical documentation (concise and professional)",
+	"教程指南风格（循序渐进）":                                     "Tutorial guide (step by step)",
+	"参考资料风格（详细完整）":                                     "Reference (detailed and complete)",
+	"博客风格（轻松活泼）":                                       "Blog (light and lively)",
+	"alert %s: 缺少 message":                             "alert %s: message is missing",
+	"alert %s: when 只能是 any/write/read_only":           "alert %s: when must be any/write/read_only",
+	"%s: 正则 %q 无效 (%v)":                                "%s: invalid regular expression %q (%v)",
+	"intents[%d]: 缺少 name":                             "intents[%d]: name is missing",
+	"%s 必须在 %d-%d 之间 (当前 %d)":                          "%s must be between %d and %d (got %d)",
+	"alerts[%d]: 重复的警告 %s":                             "alerts[%d]: duplicate alert %s",
+	"intents[%d]: 重复的意图 %s":                            "intents[%d]: duplicate intent %s",
+	"locale %q 无效 (需为语言代码，如 zh、en)":                    "Invalid locale %q (use a language code such as zh or en)",
+	"内置意图规则无效: %v":                                     "Built-in intent rules are invalid: %v",
+	"guardrail_checks.%s: deny 与 allow 至少填写一个":         "guardrail_checks.%s: set at least one of deny and allow",
+	"[Concept][WARN] 概念索引刷新失败: %v\n":                   "[Concept][WARN] Concept index refresh failed: %v\n",
+	"目标文件不存在，补丁将新建该文件 (需自行补充 package/import 声明)":       "The destination file does not exist; the patch creates it (add the package/import declarations yourself)",
+	"[Metrics][WARN] 指标快照记录失败: %v\n":                   "[Metrics][WARN] Failed to record the metric snapshot: %v\n",
+	"%s 之前没有结构快照，已使用最早的快照 #%d (%s)":                    "There is no structure snapshot before %s; using the oldest snapshot #%d (%s)",
+	"%s: %v (已忽略此文件)":                                  "%s: %v (file ignored)",
+	"%s: 合并后无效: %v (已忽略此文件)":                           "%s: invalid after merging: %v (file ignored)",
+	"intents[%d]: name %q 只能包含大写字母、数字和下划线":             "intents[%d]: name %q may only contain uppercase letters, digits and underscores",
+	"intent %s: 关键词不能为空":                               "intent %s: keywords must not be empty",
+	"ignore: 目录名 %q 无效 (不支持空值与通配符，复杂规则请写入 .mpmignore)": "ignore: invalid directory name %q (empty values and wildcards are not supported; put complex rules in .mpmignore)",
+	"调用点未改写：跨包/跨模块时请手动补充 import 或包名限定":                 "Call sites are not rewritten: add imports or package qualifiers by hand across packages/modules",
+	"wiki.output_dir %q 必须是项目内的相对路径":                   "wiki.output_dir %q must be a relative path inside the project",
+	"移动 `%s` (%d 行) 到 `%s`":                            "Move `%s` (%d lines) to `%s`",
+	"alerts[%d]: 缺少 id":                                "alerts[%d]: id is missing",
+	"read_only_intent %s 未定义":                          "read_only_intent %s is not defined",
+	"map.page_size (%d) 不能大于 map.max_page_size (%d)":   "map.page_size (%d) must not exceed map.max_page_size (%d)",
+	"新增行命中 %s: %s":                                     "Added line matches %s: %s",
+	"[Metrics][WARN] 复杂度指标刷新失败: %v\n":                  "[Metrics][WARN] Complexity metrics refresh failed: %v\n",
+	"guardrail_checks: 代码 %q 只能包含大写字母、数字和下划线":          "guardrail_checks: code %q may only contain uppercase letters, digits and underscores",
+	"alert %s: 缺少 keywords":                            "alert %s: keywords are missing",
+}
@@ -0,0 +1,209 @@
+// Package i18n 工具描述、错误与结果文本的多语言目录。
+//
+// 源语言为中文：消息以中文原文作为 key (gettext 风格)，工具描述以 "tool.<name>" 作为 key、
+// 中文原文保留在注册处。其他语言的目录按 key 提供译文，缺失时回退到中文原文。
+// 内置 en；可在 ~/.mpm/locales/<lang>.yaml 与 .mcp-config/locales/<lang>.yaml 中补充或新增语言。
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// LocaleZH 源语言
+	LocaleZH = "zh"
+	// LocaleEN 内置英文目录
+	LocaleEN = "en"
+	// EnvLocale 环境变量优先于配置文件
+	EnvLocale = "MPM_LANG"
+
+	localeDirName = "locales"
+)
+
+var localePattern = regexp.MustCompile(`^[a-z]{2,3}$`)
+
+var (
+	mu      sync.RWMutex
+	current = LocaleZH
+	// catalogs 语言 -> key -> 译文；源语言不需要目录
+	catalogs = map[string]map[string]string{LocaleEN: en}
+)
+
+// NormalizeLocale "en_US.UTF-8"、"EN"、"zh-CN" 等写法归一为 "en"、"zh"；无法识别时返回空串
+func NormalizeLocale(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if i := strings.IndexAny(s, "_-.@"); i >= 0 {
+		s = s[:i]
+	}
+	if !localePattern.MatchString(s) {
+		return ""
+	}
+	return s
+}
+
+// ResolveLocale 环境变量 MPM_LANG > 配置 > 源语言
+func ResolveLocale(configured string) string {
+	if l := NormalizeLocale(os.Getenv(EnvLocale)); l != "" {
+		return l
+	}
+	if l := NormalizeLocale(configured); l != "" {
+		return l
+	}
+	return LocaleZH
+}
+
+// SetLocale 设置当前语言；没有对应目录的语言会回退到中文原文
+func SetLocale(locale string) {
+	if l := NormalizeLocale(locale); l != "" {
+		mu.Lock()
+		current = l
+		mu.Unlock()
+	}
+}
+
+// Locale 当前语言
+func Locale() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return current
+}
+
+// Locales 可用语言 (含源语言)
+func Locales() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	out := []string{LocaleZH}
+	for l := range catalogs {
+		out = append(out, l)
+	}
+	sort.Strings(out[1:])
+	return out
+}
+
+// T 按当前语言翻译消息 (key 为中文原文)，带参数时按 fmt 格式化
+func T(key string, args ...interface{}) string {
+	return TL(Locale(), key, args...)
+}
+
+// TL 按指定语言翻译 (如 memo 的 lang 参数)
+func TL(locale, key string, args ...interface{}) string {
+	msg := lookup(NormalizeLocale(locale), key)
+	if msg == "" {
+		msg = key
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(msg, args...)
+	}
+	return msg
+}
+
+// Desc 工具描述：当前语言有 "tool.<name>" 译文时使用译文，否则使用注册处的中文原文
+func Desc(tool, zh string) string {
+	if msg := lookup(Locale(), "tool."+tool); msg != "" {
+		return msg
+	}
+	return zh
+}
+
+func lookup(locale, key string) string {
+	if locale == "" || locale == LocaleZH {
+		return ""
+	}
+	mu.RLock()
+	defer mu.RUnlock()
+	return catalogs[locale][key]
+}
+
+// LoadExternal 加载全局与项目的 locales/<lang>.yaml (项目覆盖全局，均覆盖内置)，返回警告。
+// 文件为 key: 译文 的平铺映射。
+func LoadExternal(globalDir, projectConfigDir string) []string {
+	var warnings []string
+	loaded := make(map[string]map[string]string)
+	for _, dir := range []string{globalDir, projectConfigDir} {
+		if dir == "" {
+			continue
+		}
+		entries, err := os.ReadDir(filepath.Join(dir, localeDirName))
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			ext := filepath.Ext(e.Name())
+			if e.IsDir() || (ext != ".yaml" && ext != ".yml") {
+				continue
+			}
+			path := filepath.Join(dir, localeDirName, e.Name())
+			locale := NormalizeLocale(strings.TrimSuffix(e.Name(), ext))
+			if locale == "" || locale == LocaleZH {
+				warnings = append(warnings, fmt.Sprintf("%s: 文件名需为语言代码 (如 en.yaml、ja.yaml)，且不能是源语言 zh", path))
+				continue
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				warnings = append(warnings, fmt.Sprintf("%s: %v", path, err))
+				continue
+			}
+			var entries map[string]string
+			if err := yaml.Unmarshal(data, &entries); err != nil {
+				warnings = append(warnings, fmt.Sprintf("%s: %v", path, err))
+				continue
+			}
+			if loaded[locale] == nil {
+				loaded[locale] = make(map[string]string)
+			}
+			for k, v := range entries {
+				loaded[locale][k] = v
+			}
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	catalogs = map[string]map[string]string{LocaleEN: en}
+	for locale, entries := range loaded {
+		merged := make(map[string]string, len(catalogs[locale])+len(entries))
+		for k, v := range catalogs[locale] {
+			merged[k] = v
+		}
+		for k, v := range entries {
+			merged[k] = v
+		}
+		catalogs[locale] = merged
+	}
+	return warnings
+}
+
+// Keys 内置英文目录的全部 key (作为完整 key 集合的参照)
+func Keys() []string {
+	keys := make([]string, 0, len(en))
+	for k := range en {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// MissingKeys 指定语言相对完整 key 集合缺少的 key (运行时会回退到中文原文)
+func MissingKeys(locale string) []string {
+	locale = NormalizeLocale(locale)
+	if locale == "" || locale == LocaleZH {
+		return nil
+	}
+	mu.RLock()
+	defer mu.RUnlock()
+	var missing []string
+	for _, k := range Keys() {
+		if _, ok := catalogs[locale][k]; !ok {
+			missing = append(missing, k)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,291 @@
+package i18n
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+var verbRe = regexp.MustCompile(`%(?:\[\d+\])?[-+# 0]*\d*(?:\.\d+)?([a-zA-Z%])`)
+
+// verbs 格式化动词 (忽略 %% 与显式下标的顺序差异)
+func verbs(s string) string {
+	var out []string
+	for _, m := range verbRe.FindAllStringSubmatch(s, -1) {
+		if m[1] != "%" {
+			out = append(out, m[1])
+		}
+	}
+	sort.Strings(out)
+	return strings.Join(out, "")
+}
+
+// usedKeys 扫描源码中 i18n.T / i18n.TL / i18n.Desc 的字面量 key
+func usedKeys(t *testing.T, dirs ...string) map[string]string {
+	t.Helper()
+	keys := make(map[string]string)
+	fset := token.NewFileSet()
+	for _, dir := range dirs {
+		err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+			if err != nil || d.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+				return err
+			}
+			f, err := parser.ParseFile(fset, path, nil, 0)
+			if err != nil {
+				return err
+			}
+			ast.Inspect(f, func(n ast.Node) bool {
+				call, ok := n.(*ast.CallExpr)
+				if !ok {
+					return true
+				}
+				sel, ok := call.Fun.(*ast.SelectorExpr)
+				if !ok {
+					return true
+				}
+				if pkg, ok := sel.X.(*ast.Ident); !ok || pkg.Name != "i18n" {
+					return true
+				}
+				argIdx, prefix := 0, ""
+				switch sel.Sel.Name {
+				case "T":
+				case "TL":
+					argIdx = 1
+				case "Desc":
+					prefix = "tool."
+				default:
+					return true
+				}
+				if len(call.Args) <= argIdx {
+					return true
+				}
+				lit, ok := call.Args[argIdx].(*ast.BasicLit)
+				if !ok || lit.Kind != token.STRING {
+					return true
+				}
+				s, err := strconv.Unquote(lit.Value)
+				if err != nil {
+					t.Errorf("%s: %v", fset.Position(lit.Pos()), err)
+					return true
+				}
+				keys[prefix+s] = fset.Position(lit.Pos()).String()
+				return true
+			})
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return keys
+}
+
+func TestCatalogCoversSource(t *testing.T) {
+	keys := usedKeys(t, "..", filepath.Join("..", "..", "cmd"))
+	if len(keys) < 100 {
+		t.Fatalf("expected to find the wrapped messages, got %d keys", len(keys))
+	}
+	for key, pos := range keys {
+		msg, ok := en[key]
+		if !ok {
+			t.Errorf("%s: missing en translation for %q", pos, key)
+			continue
+		}
+		if !strings.HasPrefix(key, "tool.") && verbs(key) != verbs(msg) {
+			t.Errorf("%s: placeholders differ: %q -> %q", pos, key, msg)
+		}
+	}
+	for key := range en {
+		if _, ok := keys[key]; !ok {
+			t.Errorf("unused en entry %q", key)
+		}
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	defer SetLocale(Locale())
+
+	SetLocale("zh")
+	if got := T("任务 %s 不存在", "A"); got != "任务 A 不存在" {
+		t.Fatalf("zh should keep the source text: %q", got)
+	}
+	if got := Desc("memo", "中文描述"); got != "中文描述" {
+		t.Fatalf("zh description: %q", got)
+	}
+
+	SetLocale("en_US.UTF-8")
+	if Locale() != LocaleEN {
+		t.Fatalf("locale should normalize to en: %q", Locale())
+	}
+	if got := T("任务 %s 不存在", "A"); got != "Task A does not exist" {
+		t.Fatalf("en translation: %q", got)
+	}
+	if got := T("✅ 已删除任务 %s 的第 %d 步。", "A", 2); got != "✅ Deleted step 2 of task A." {
+		t.Fatalf("indexed verbs: %q", got)
+	}
+	if got := T("没有译文的消息"); got != "没有译文的消息" {
+		t.Fatalf("missing keys should fall back to the source: %q", got)
+	}
+	if got := TL("zh", "系统"); got != "系统" {
+		t.Fatalf("TL should ignore the current locale: %q", got)
+	}
+	if !strings.HasPrefix(Desc("memo", "中文描述"), "memo - ") {
+		t.Fatalf("en description missing")
+	}
+}
+
+func TestResolveLocale(t *testing.T) {
+	t.Setenv(EnvLocale, "")
+	if got := ResolveLocale("EN"); got != LocaleEN {
+		t.Fatalf("config locale: %q", got)
+	}
+	if got := ResolveLocale("!!"); got != LocaleZH {
+		t.Fatalf("invalid locale should fall back to zh: %q", got)
+	}
+	t.Setenv(EnvLocale, "ja-JP")
+	if got := ResolveLocale("en"); got != "ja" {
+		t.Fatalf("env should win: %q", got)
+	}
+}
+
+func TestLoadExternal(t *testing.T) {
+	defer LoadExternal("", "")
+
+	global, project := t.TempDir(), t.TempDir()
+	write := func(dir, name, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Join(dir, "locales"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "locales", name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(global, "ja.yaml", "\"任务 %s 不存在\": \"タスク %s は存在しません\"\n\"项目未初始化\": \"未初期化\"\n")
+	write(project, "ja.yaml", "\"项目未初始化\": \"プロジェクトが初期化されていません\"\n")
+	write(project, "en.yml", "\"项目未初始化\": \"Not initialized yet\"\n")
+	write(project, "zh.yaml", "\"项目未初始化\": \"x\"\n")
+
+	warnings := LoadExternal(global, project)
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "zh.yaml") {
+		t.Fatalf("zh.yaml should be rejected: %v", warnings)
+	}
+	if got := TL("ja", "任务 %s 不存在", "A"); got != "タスク A は存在しません" {
+		t.Fatalf("global ja: %q", got)
+	}
+	if got := TL("ja", "项目未初始化"); got != "プロジェクトが初期化されていません" {
+		t.Fatalf("project should override global: %q", got)
+	}
+	if got := TL("en", "项目未初始化"); got != "Not initialized yet" {
+		t.Fatalf("project should override builtin en: %q", got)
+	}
+	if got := TL("en", "任务 %s 不存在", "A"); got != "Task A does not exist" {
+		t.Fatalf("builtin en should stay available: %q", got)
+	}
+	if locales := Locales(); strings.Join(locales, ",") != "zh,en,ja" {
+		t.Fatalf("locales: %v", locales)
+	}
+	if missing := MissingKeys("ja"); len(missing) != len(en)-2 {
+		t.Fatalf("ja should miss all but 2 keys, got %d of %d", len(missing), len(en))
+	}
+	if len(MissingKeys("en")) != 0 {
+		t.Fatalf("en should be complete")
+	}
+
+	LoadExternal("", "")
+	if got := TL("en", "项目未初始化"); got != "Project is not initialized" {
+		t.Fatalf("reload should drop external entries: %q", got)
+	}
+}
+
+// contentDecls 存放内容而不是界面消息的声明 (提示词正文、写入项目的文件模板、匹配用户输入的关键词)
+var contentDecls = map[string]string{
+	"tacticalProtocol":         "内置协议正文",
+	"protocolContextBlock":     "协议上下文模板",
+	"getDefaultPersonaLibrary": "内置人格正文",
+	"generateProjectRules":     "写入项目的规则文件",
+	"projectConfigTemplate":    "mpm.yaml 模板",
+	"WikiStyleTemplates":       "Wiki 书写指南正文",
+	"DefaultWikiStyleTemplate": "Wiki 书写指南正文",
+	"MergeWikiStyleTemplate":   "Wiki 书写指南正文",
+	"VisualizeHistoryScript":   "Timeline 脚本",
+	"stepHintKeywords":         "匹配步骤名的关键词",
+	"defaultIntentRulesYAML":   "内置意图规则 (匹配用户输入的关键词)",
+	"SyncDevLog":               "写入项目的 dev-log.md",
+	"commandNotFoundMarkers":   "匹配系统错误输出的片段",
+}
+
+var hanRe = regexp.MustCompile(`\p{Han}`)
+
+// TestNoUntranslatedLiterals 工具、服务、核心与入口中的中文字面量必须作为 i18n.T / TL / Desc 的 key
+func TestNoUntranslatedLiterals(t *testing.T) {
+	fset := token.NewFileSet()
+	for _, dir := range []string{filepath.Join("..", "tools"), filepath.Join("..", "services"), filepath.Join("..", "core"), filepath.Join("..", "..", "cmd")} {
+		err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+			if err != nil || d.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+				return err
+			}
+			f, err := parser.ParseFile(fset, path, nil, 0)
+			if err != nil {
+				return err
+			}
+			for _, decl := range f.Decls {
+				if _, ok := contentDecls[declName(decl)]; ok {
+					continue
+				}
+				allowed := make(map[ast.Node]bool)
+				ast.Inspect(decl, func(n ast.Node) bool {
+					switch x := n.(type) {
+					case *ast.Field:
+						if x.Tag != nil {
+							allowed[x.Tag] = true
+						}
+					case *ast.CallExpr:
+						if sel, ok := x.Fun.(*ast.SelectorExpr); ok {
+							if pkg, ok := sel.X.(*ast.Ident); ok && pkg.Name == "i18n" {
+								argIdx := 0
+								if sel.Sel.Name == "TL" || sel.Sel.Name == "Desc" {
+									argIdx = 1
+								}
+								if len(x.Args) > argIdx {
+									allowed[x.Args[argIdx]] = true
+								}
+							}
+						}
+					case *ast.BasicLit:
+						if x.Kind == token.STRING && !allowed[x] && hanRe.MatchString(x.Value) {
+							t.Errorf("%s: untranslated literal %.60s", fset.Position(x.Pos()), x.Value)
+						}
+					}
+					return true
+				})
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// declName 顶层声明的名称 (函数名或第一个变量/常量名)
+func declName(decl ast.Decl) string {
+	switch d := decl.(type) {
+	case *ast.FuncDecl:
+		return d.Name.Name
+	case *ast.GenDecl:
+		for _, spec := range d.Specs {
+			if vs, ok := spec.(*ast.ValueSpec); ok {
+				return vs.Names[0].Name
+			}
+		}
+	}
+	return ""
+}
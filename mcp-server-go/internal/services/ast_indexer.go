@@ -3,9 +3,12 @@ package services
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"mcp-server-go/internal/i18n"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
 	"regexp"
 	"runtime"
@@ -29,6 +32,7 @@ type Node struct {
 	LineStart     int      `json:"line_start"`
 	LineEnd       int      `json:"line_end"`
 	Signature     string   `json:"signature,omitempty"`
+	Doc           string   `json:"doc,omitempty"` // 文档注释 / docstring
 	Calls         []string `json:"calls,omitempty"`
 }
 
@@ -40,10 +44,11 @@ type Stats struct {
 
 // MapResult 项目地图结果 (--mode map)
 type MapResult struct {
-	Statistics    Stats              `json:"statistics"`
-	Structure     map[string][]Node  `json:"structure"`
-	Elapsed       string             `json:"elapsed"`
-	ComplexityMap map[string]float64 `json:"complexity_map,omitempty"` // 符号名 -> 复杂度分数
+	Statistics    Stats                    `json:"statistics"`
+	Structure     map[string][]Node        `json:"structure"`
+	Elapsed       string                   `json:"elapsed"`
+	ComplexityMap map[string]float64       `json:"complexity_map,omitempty"` // 符号名 -> 复杂度分数
+	MetricsMap    map[string]SymbolMetrics `json:"metrics_map,omitempty"`    // 符号名 -> 函数级指标
 }
 
 // CandidateMatch 候选匹配
@@ -71,16 +76,19 @@ type QueryResult struct {
 
 // ImpactResult 影响分析结果 (--mode analyze)
 type ImpactResult struct {
-	Status                string       `json:"status"`
-	NodeID                string       `json:"node_id"`
-	ComplexityScore       float64      `json:"complexity_score"`
-	ComplexityLevel       string       `json:"complexity_level"`
-	RiskLevel             string       `json:"risk_level"`
-	AffectedNodes         int          `json:"affected_nodes"`
-	DirectCallers         []CallerInfo `json:"direct_callers"`
-	IndirectCallers       []CallerInfo `json:"indirect_callers"`
-	ModificationChecklist []string     `json:"modification_checklist"`
-	Message               string       `json:"message,omitempty"`
+	Status                string         `json:"status"`
+	NodeID                string         `json:"node_id"`
+	ComplexityScore       float64        `json:"complexity_score"`
+	ComplexityLevel       string         `json:"complexity_level"`
+	CouplingScore         float64        `json:"coupling_score"`
+	Metrics               *SymbolMetrics `json:"metrics,omitempty"`
+	RiskLevel             string         `json:"risk_level"`
+	AffectedNodes         int            `json:"affected_nodes"`
+	DirectCallers         []CallerInfo   `json:"direct_callers"`
+	IndirectCallers       []CallerInfo   `json:"indirect_callers"`
+	ModificationChecklist []string       `json:"modification_checklist"`
+	Message               string         `json:"message,omitempty"`
+	Doc                   string         `json:"doc,omitempty"` // 目标符号的文档注释
 }
 
 // IndexResult 索引结果 (--mode index)
@@ -185,23 +193,18 @@ func getOutputPath(projectRoot string, mode string) string {
 // ============================================================================
 
 // detectTechStackAndConfig 智能检测技术栈，返回(允许的扩展名, 忽略的目录)
+// 忽略目录由两部分组成：按目录名匹配的内置/技术栈目录，以及按 gitignore 语义
+// (含嵌套 .gitignore、.git/info/exclude、.mpmignore) 扫描出的被忽略目录相对路径
 func detectTechStackAndConfig(projectRoot string) (extensions string, ignoreDirs string) {
 	var stackDetected []string
 	var exts []string
 
 	// 基础忽略目录
-	ignores := []string{
-		".git", "__pycache__", "node_modules", ".venv", "venv",
-		"dist", "build", ".idea", ".vscode",
-		"release", "releases", "archive", "backup", "old",
-	}
-
-	// 从 .gitignore 解析额外的忽略目录
-	gitignoreDirs := parseGitignoreDirs(projectRoot)
-	ignores = append(ignores, gitignoreDirs...)
+	ignores := append([]string{}, IgnoreDirsFor(projectRoot)...)
 
 	// 一次性递归扫描文件扩展名，避免只看根目录导致误判
-	extSet := scanProjectExtensions(projectRoot, ignores, 8)
+	matcher := NewIgnoreMatcher(projectRoot)
+	extSet, ignoredPaths := scanProjectExtensions(projectRoot, matcher, 8)
 	hasExt := func(ext string) bool {
 		ext = strings.TrimPrefix(strings.ToLower(ext), ".")
 		return extSet[ext]
@@ -254,139 +257,94 @@ func detectTechStackAndConfig(projectRoot string) (extensions string, ignoreDirs
 		ignores = append(ignores, ".gradle")
 	}
 
+	// 被 "!name/" 重新纳入的目录名不能整体排除，其余仍被忽略的同名目录已在 ignoredPaths 中
+	ignoreDirs = joinIgnoreDirs(matcher.BlanketIgnoreDirs(ignores), ignoredPaths)
+
 	// 如果没有检测到特定栈，不限制扩展名
 	if len(stackDetected) == 0 {
-		return "", uniqueJoin(ignores)
+		return "", ignoreDirs
 	}
 
-	return uniqueJoin(exts), uniqueJoin(ignores)
+	return uniqueJoin(exts), ignoreDirs
 }
 
-// scanProjectExtensions 递归扫描项目内出现过的扩展名
-func scanProjectExtensions(projectRoot string, ignoreDirs []string, maxDepth int) map[string]bool {
+// scanProjectExtensions 递归扫描项目内出现过的扩展名，
+// 同时返回被忽略规则命中的目录 (相对路径，不再深入)
+func scanProjectExtensions(projectRoot string, matcher *IgnoreMatcher, maxDepth int) (map[string]bool, []string) {
 	result := make(map[string]bool)
-	ignoreSet := make(map[string]bool)
+	var ignored []string
 
-	for _, dir := range ignoreDirs {
-		d := strings.TrimSpace(strings.ToLower(strings.Trim(dir, "/\\")))
-		if d != "" {
-			ignoreSet[d] = true
-		}
-	}
-
-	var walk func(dir string, depth int)
-	walk = func(dir string, depth int) {
+	var walk func(rel string, depth int)
+	walk = func(rel string, depth int) {
 		if depth > maxDepth {
 			return
 		}
 
-		entries, err := os.ReadDir(dir)
+		entries, err := os.ReadDir(filepath.Join(projectRoot, filepath.FromSlash(rel)))
 		if err != nil {
 			return
 		}
 
 		for _, e := range entries {
-			name := e.Name()
-			nameLower := strings.ToLower(name)
+			child := path.Join(rel, e.Name())
 
 			if e.IsDir() {
-				if shouldSkipDetectDir(nameLower, ignoreSet) {
+				if matcher != nil && matcher.Match(child, true) {
+					ignored = append(ignored, child)
 					continue
 				}
-				walk(filepath.Join(dir, name), depth+1)
+				walk(child, depth+1)
 				continue
 			}
 
-			ext := strings.TrimPrefix(strings.ToLower(filepath.Ext(name)), ".")
+			if matcher != nil && matcher.Match(child, false) {
+				continue
+			}
+			ext := strings.TrimPrefix(strings.ToLower(filepath.Ext(e.Name())), ".")
 			if ext != "" {
 				result[ext] = true
 			}
 		}
 	}
 
-	walk(projectRoot, 0)
-	return result
+	walk("", 0)
+	return result, ignored
 }
 
-func shouldSkipDetectDir(name string, ignoreSet map[string]bool) bool {
-	if ignoreSet[name] {
-		return true
-	}
+// joinIgnoreDirs 合并目录名与被忽略目录的相对路径，作为 --ignore-dirs 参数
+// 目录名按名称匹配任意层级；以 ./ 开头的项由索引器按相对项目根的路径匹配。
+// 已被目录名覆盖的相对路径不再重复传递，避免参数过长
+func joinIgnoreDirs(names []string, relPaths []string) string {
+	joined := uniqueJoin(names)
+	nameSet := toLowerSet(names)
 
-	switch name {
-	case ".git", "node_modules", "vendor", "target", "dist", "build", "coverage", ".next", ".nuxt", "out",
-		"__pycache__", ".pytest_cache", ".venv", "venv", "site-packages", ".idea", ".vscode", ".mcp-data",
-		"release", "releases", "archive", "backup", "old":
-		return true
-	default:
-		return false
+	var extra []string
+	for _, rel := range relPaths {
+		if nameSet[strings.ToLower(path.Base(rel))] {
+			continue
+		}
+		extra = append(extra, "./"+rel)
 	}
-}
-
-// parseGitignoreDirs 解析 .gitignore 文件，提取目录忽略规则
-func parseGitignoreDirs(projectRoot string) []string {
-	gitignorePath := filepath.Join(projectRoot, ".gitignore")
-	data, err := os.ReadFile(gitignorePath)
-	if err != nil {
-		return nil
+	if len(extra) == 0 {
+		return joined
 	}
-
-	var ignoredDirs []string
-	fileExtensions := map[string]bool{
-		"txt": true, "md": true, "json": true, "yml": true, "yaml": true,
-		"toml": true, "lock": true, "log": true, "py": true, "js": true,
-		"ts": true, "rs": true, "go": true, "java": true, "c": true,
-		"cpp": true, "h": true, "hpp": true, "sql": true, "db": true,
+	if joined == "" {
+		return strings.Join(extra, ",")
 	}
+	return joined + "," + strings.Join(extra, ",")
+}
 
-	lines := strings.Split(string(data), "\n")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-
-		// 跳过注释和空行
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
-		}
-		// 跳过否定规则
-		if strings.HasPrefix(line, "!") {
-			continue
-		}
-
-		// 优先级 1: 以 / 结尾的明确目录
-		if strings.HasSuffix(line, "/") {
-			dirName := strings.TrimSuffix(line, "/")
-			dirName = strings.TrimPrefix(dirName, "/")
-			dirName = strings.ReplaceAll(dirName, "**/", "")
-			if dirName != "" && !strings.HasPrefix(dirName, "**") {
-				ignoredDirs = append(ignoredDirs, dirName)
-			}
-			continue
-		}
-
-		// 优先级 2: 包含 / 的路径模式
-		if strings.Contains(line, "/") {
-			parts := strings.Split(line, "/")
-			pathPart := strings.ReplaceAll(parts[len(parts)-1], "*", "")
-			if pathPart != "" && !strings.HasPrefix(pathPart, "**") {
-				// 检查是否是纯文件名（包含扩展名）
-				if strings.Contains(pathPart, ".") {
-					extParts := strings.Split(pathPart, ".")
-					ext := strings.ToLower(extParts[len(extParts)-1])
-					if fileExtensions[ext] {
-						continue
-					}
-				}
-				ignoredDirs = append(ignoredDirs, pathPart)
-			}
-		}
+func toLowerSet(items []string) map[string]bool {
+	set := make(map[string]bool, len(items))
+	for _, item := range items {
+		set[strings.ToLower(item)] = true
 	}
-
-	return ignoredDirs
+	return set
 }
 
 // hasFilesWithExt 检查目录下是否有指定扩展名的文件
 func hasFilesWithExt(dir string, ext string) bool {
-	extSet := scanProjectExtensions(dir, nil, 8)
+	extSet, _ := scanProjectExtensions(dir, NewIgnoreMatcher(dir), 8)
 	ext = strings.TrimPrefix(strings.ToLower(ext), ".")
 	return extSet[ext]
 }
@@ -484,19 +442,27 @@ func (ai *ASTIndexer) MapProjectWithScope(projectRoot string, detail string, sco
 	cmd.Dir = projectRoot // 设置工作目录
 
 	if err := cmd.Run(); err != nil {
-		return nil, fmt.Errorf("项目地图生成失败: %v", err)
+		return nil, errors.New(i18n.T("项目地图生成失败: %v", err))
 	}
 
 	// 读取输出文件
 	data, err := os.ReadFile(outputPath)
 	if err != nil {
-		return nil, fmt.Errorf("读取地图结果失败: %v", err)
+		return nil, errors.New(i18n.T("读取地图结果失败: %v", err))
 	}
 
 	var result MapResult
 	if err := json.Unmarshal(data, &result); err != nil {
-		return nil, fmt.Errorf("解析地图结果失败: %v", err)
+		return nil, errors.New(i18n.T("解析地图结果失败: %v", err))
+	}
+
+	var nodes []*Node
+	for path := range result.Structure {
+		for i := range result.Structure[path] {
+			nodes = append(nodes, &result.Structure[path][i])
+		}
 	}
+	ai.AttachDocs(projectRoot, nodes...)
 
 	return &result, nil
 }
@@ -529,23 +495,94 @@ func (ai *ASTIndexer) SearchSymbolWithScope(projectRoot string, query string, sc
 	cmd.Dir = projectRoot
 
 	if err := cmd.Run(); err != nil {
-		return nil, fmt.Errorf("符号搜索失败: %v", err)
+		return nil, errors.New(i18n.T("符号搜索失败: %v", err))
 	}
 
 	// 读取输出文件
 	data, err := os.ReadFile(outputPath)
 	if err != nil {
-		return nil, fmt.Errorf("读取搜索结果失败: %v", err)
+		return nil, errors.New(i18n.T("读取搜索结果失败: %v", err))
 	}
 
 	var result QueryResult
 	if err := json.Unmarshal(data, &result); err != nil {
-		return nil, fmt.Errorf("解析搜索结果失败: %v", err)
+		return nil, errors.New(i18n.T("解析搜索结果失败: %v", err))
 	}
 
+	var nodes []*Node
+	if result.FoundSymbol != nil {
+		nodes = append(nodes, result.FoundSymbol)
+	}
+	for i := range result.Candidates {
+		nodes = append(nodes, &result.Candidates[i].Node)
+	}
+	for i := range result.RelatedNodes {
+		nodes = append(nodes, &result.RelatedNodes[i].Node)
+	}
+	ai.AttachDocs(projectRoot, nodes...)
+
 	return &result, nil
 }
 
+// SymbolsInFiles 批量读取多个文件的符号 (一次查询，避免逐行调用 GetSymbolAtLine)
+// files 为项目相对路径，返回 相对路径 -> 符号列表 (按起始行排序)
+func (ai *ASTIndexer) SymbolsInFiles(projectRoot string, files []string) (map[string][]Node, error) {
+	result := make(map[string][]Node)
+	dbPath := getDBPath(projectRoot)
+	if len(files) == 0 || !fileExists(dbPath) {
+		return result, nil
+	}
+
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	placeholders := make([]string, len(files))
+	args := make([]interface{}, len(files))
+	for i, f := range files {
+		placeholders[i] = "?"
+		args[i] = filepath.ToSlash(f)
+	}
+
+	rows, err := db.Query(fmt.Sprintf(`
+		SELECT s.canonical_id, s.name, COALESCE(s.qualified_name, ''), s.symbol_type, f.file_path,
+		       s.line_start, s.line_end, COALESCE(s.signature, '')
+		FROM symbols s JOIN files f ON s.file_id = f.file_id
+		WHERE f.file_path IN (%s)
+		ORDER BY f.file_path, s.line_start`, strings.Join(placeholders, ",")), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var n Node
+		if err := rows.Scan(&n.ID, &n.Name, &n.QualifiedName, &n.NodeType, &n.FilePath,
+			&n.LineStart, &n.LineEnd, &n.Signature); err != nil {
+			continue
+		}
+		n.FilePath = filepath.ToSlash(n.FilePath)
+		result[n.FilePath] = append(result[n.FilePath], n)
+	}
+	return result, nil
+}
+
+// EnclosingSymbol 返回包含指定行的最内层符号
+func EnclosingSymbol(nodes []Node, line int) *Node {
+	var best *Node
+	for i := range nodes {
+		n := &nodes[i]
+		if n.LineStart <= line && line <= n.LineEnd {
+			if best == nil || n.LineEnd-n.LineStart < best.LineEnd-best.LineStart {
+				best = n
+			}
+		}
+	}
+	return best
+}
+
 // GetSymbolAtLine 获取指定文件行号处的符号信息 (--mode query --file --line)
 func (ai *ASTIndexer) GetSymbolAtLine(projectRoot string, filePath string, line int) (*Node, error) {
 	dbPath := getDBPath(projectRoot)
@@ -577,18 +614,18 @@ func (ai *ASTIndexer) GetSymbolAtLine(projectRoot string, filePath string, line
 	cmd.Dir = projectRoot
 
 	if err := cmd.Run(); err != nil {
-		return nil, fmt.Errorf("定位符号失败: %v", err)
+		return nil, errors.New(i18n.T("定位符号失败: %v", err))
 	}
 
 	// 读取输出文件
 	data, err := os.ReadFile(outputPath)
 	if err != nil {
-		return nil, fmt.Errorf("读取定位结果失败: %v", err)
+		return nil, errors.New(i18n.T("读取定位结果失败: %v", err))
 	}
 
 	var result QueryResult
 	if err := json.Unmarshal(data, &result); err != nil {
-		return nil, fmt.Errorf("解析定位结果失败: %v", err)
+		return nil, errors.New(i18n.T("解析定位结果失败: %v", err))
 	}
 
 	return result.FoundSymbol, nil
@@ -620,19 +657,40 @@ func (ai *ASTIndexer) Analyze(projectRoot string, symbol string, direction strin
 	cmd.Dir = projectRoot
 
 	if err := cmd.Run(); err != nil {
-		return nil, fmt.Errorf("影响分析执行失败: %v", err)
+		return nil, errors.New(i18n.T("影响分析执行失败: %v", err))
 	}
 
 	// 读取输出文件
 	data, err := os.ReadFile(outputPath)
 	if err != nil {
-		return nil, fmt.Errorf("读取分析结果失败: %v", err)
+		return nil, errors.New(i18n.T("读取分析结果失败: %v", err))
 	}
 
 	var result ImpactResult
 	if err := json.Unmarshal(data, &result); err != nil {
-		return nil, fmt.Errorf("解析分析结果失败: %v", err)
+		return nil, errors.New(i18n.T("解析分析结果失败: %v", err))
+	}
+
+	// Rust 端的 complexity_score 基于调用图，归入耦合度；复杂度改用函数体指标
+	result.CouplingScore = result.ComplexityScore
+	if result.Status == "success" && result.NodeID != "" {
+		if metrics, err := ai.GetSymbolMetrics(projectRoot, result.NodeID); err == nil && metrics != nil {
+			result.Metrics = metrics
+			result.ComplexityScore = metrics.Score()
+			result.ComplexityLevel = metricsLevel(result.ComplexityScore)
+			result.RiskLevel = escalateRisk(result.RiskLevel, result.ComplexityScore)
+		}
+		result.Doc = ai.docByCanonicalID(projectRoot, result.NodeID)
+	}
+
+	var callers []*Node
+	for i := range result.DirectCallers {
+		callers = append(callers, &result.DirectCallers[i].Node)
+	}
+	for i := range result.IndirectCallers {
+		callers = append(callers, &result.IndirectCallers[i].Node)
 	}
+	ai.AttachDocs(projectRoot, callers...)
 
 	return &result, nil
 }
@@ -689,13 +747,18 @@ func (ai *ASTIndexer) Index(projectRoot string) (*IndexResult, error) {
 			_ = os.Remove(outputPath)
 			retryArgs := buildIndexArgs(projectRoot, dbPath, outputPath, ignoreDirs, extensions, true)
 			if retryErr := ai.runIndexCommand(projectRoot, retryArgs); retryErr != nil {
-				return nil, fmt.Errorf("索引刷新失败: 全量扫描失败(%v); 扩展模式重试失败(%v)", err, retryErr)
+				return nil, errors.New(i18n.T("索引刷新失败: 全量扫描失败(%v); 扩展模式重试失败(%v)", err, retryErr))
 			}
 		} else {
-			return nil, fmt.Errorf("索引刷新失败: %v", err)
+			return nil, errors.New(i18n.T("索引刷新失败: %v", err))
 		}
 	}
 
+	refreshMetricsAfterIndex(projectRoot)
+	if err := refreshConceptIndex(projectRoot); err != nil {
+		fmt.Fprint(os.Stderr, i18n.T("[Concept][WARN] 概念索引刷新失败: %v\n", err))
+	}
+
 	// 读取输出文件
 	data, err := os.ReadFile(outputPath)
 	if err != nil {
@@ -711,6 +774,17 @@ func (ai *ASTIndexer) Index(projectRoot string) (*IndexResult, error) {
 	return &result, nil
 }
 
+// refreshMetricsAfterIndex 索引完成后补算复杂度指标并记录趋势快照，失败只告警不影响索引结果
+func refreshMetricsAfterIndex(projectRoot string) {
+	if err := refreshSymbolMetrics(projectRoot); err != nil {
+		fmt.Fprint(os.Stderr, i18n.T("[Metrics][WARN] 复杂度指标刷新失败: %v\n", err))
+		return
+	}
+	if _, _, err := RecordMetricSnapshot(projectRoot); err != nil {
+		fmt.Fprint(os.Stderr, i18n.T("[Metrics][WARN] 指标快照记录失败: %v\n", err))
+	}
+}
+
 // AnalyzeNamingStyle 分析项目命名风格
 func (ai *ASTIndexer) AnalyzeNamingStyle(projectRoot string) (*NamingAnalysis, error) {
 	// 1. 确保索引存在 (且尝试刷新)
@@ -726,7 +800,7 @@ func (ai *ASTIndexer) AnalyzeNamingStyle(projectRoot string) (*NamingAnalysis, e
 
 	db, err := sql.Open("sqlite", dbPath)
 	if err != nil {
-		return nil, fmt.Errorf("打开数据库失败: %v", err)
+		return nil, errors.New(i18n.T("打开数据库失败: %v", err))
 	}
 	defer db.Close()
 
@@ -744,7 +818,7 @@ func (ai *ASTIndexer) AnalyzeNamingStyle(projectRoot string) (*NamingAnalysis, e
 	// 3. 提取所有函数名
 	rows, err := db.Query("SELECT name FROM symbols WHERE symbol_type IN ('function', 'method') LIMIT 1000")
 	if err != nil {
-		return nil, fmt.Errorf("查询符号失败: %v", err)
+		return nil, errors.New(i18n.T("查询符号失败: %v", err))
 	}
 	defer rows.Close()
 
@@ -827,9 +901,13 @@ func (ai *ASTIndexer) AnalyzeNamingStyle(projectRoot string) (*NamingAnalysis, e
 
 // RiskInfo 风险信息
 type RiskInfo struct {
-	SymbolName string  `json:"symbol_name"`
-	Score      float64 `json:"score"`
-	Reason     string  `json:"reason"`
+	SymbolName string         `json:"symbol_name"`
+	Score      float64        `json:"score"`             // 函数级复杂度综合分 (0-100)
+	Metrics    *SymbolMetrics `json:"metrics,omitempty"` // 最复杂的同名定义的指标
+	FanIn      int            `json:"fan_in"`            // 被调用次数
+	FanOut     int            `json:"fan_out"`           // 调用他人次数
+	Coupling   float64        `json:"coupling"`          // 耦合度 = FanOut + FanIn*0.5 (独立于复杂度)
+	Reason     string         `json:"reason"`
 }
 
 // ComplexityReport 复杂度报告
@@ -838,8 +916,9 @@ type ComplexityReport struct {
 	TotalAnalyzed   int        `json:"total_analyzed"`
 }
 
-// AnalyzeComplexity 分析符号复杂度 (基于调用关系)
-// 简单的中心度分析：Fan-out (出度) 高代表依赖复杂，Fan-in (入度) 高代表影响范围广/责任重
+// AnalyzeComplexity 分析符号复杂度
+// 复杂度来自函数体的词法指标 (圈复杂度/认知复杂度/行数/参数/嵌套)，
+// 耦合度 (Fan-in/Fan-out) 作为独立指标单独给出，不再混入复杂度分数
 func (ai *ASTIndexer) AnalyzeComplexity(projectRoot string, symbolNames []string) (*ComplexityReport, error) {
 	if len(symbolNames) == 0 {
 		return &ComplexityReport{}, nil
@@ -850,6 +929,11 @@ func (ai *ASTIndexer) AnalyzeComplexity(projectRoot string, symbolNames []string
 		return nil, nil // No DB, no analysis
 	}
 
+	// 确保指标已计算（增量，仅补算缺失符号）
+	if err := refreshSymbolMetrics(projectRoot); err != nil {
+		fmt.Fprint(os.Stderr, i18n.T("[Metrics][WARN] 复杂度指标刷新失败: %v\n", err))
+	}
+
 	db, err := sql.Open("sqlite", dbPath)
 	if err != nil {
 		return nil, err
@@ -860,6 +944,7 @@ func (ai *ASTIndexer) AnalyzeComplexity(projectRoot string, symbolNames []string
 	report.TotalAnalyzed = len(symbolNames)
 
 	hasCalleeID := hasColumn(db, "calls", "callee_id")
+	hasMetrics := hasTable(db, "symbol_metrics")
 
 	for _, name := range symbolNames {
 		// 1. 获取 Symbol 信息（ID + canonical_id）
@@ -889,7 +974,7 @@ func (ai *ASTIndexer) AnalyzeComplexity(projectRoot string, symbolNames []string
 			continue
 		}
 
-		// 聚合所有同名符号的指标
+		// 2. 耦合度：聚合所有同名符号的 Fan-in / Fan-out
 		var maxFanIn, maxFanOut int
 
 		for _, sym := range symbols {
@@ -915,12 +1000,23 @@ func (ai *ASTIndexer) AnalyzeComplexity(projectRoot string, symbolNames []string
 			}
 		}
 
-		// 简单的评分模型
-		// FanOut > 10 -> Complex Logic
-		// FanIn > 20 -> High Impact Core
-		score := float64(maxFanOut)*1.0 + float64(maxFanIn)*0.5
+		// 3. 复杂度：取同名定义中最复杂的一个
+		var worst *SymbolMetrics
+		if hasMetrics {
+			for _, m := range loadMetricsByName(db, name) {
+				if worst == nil || m.Score() > worst.Score() {
+					mc := m
+					worst = &mc
+				}
+			}
+		}
 
 		var reasons []string
+		score := 0.0
+		if worst != nil {
+			score = worst.Score()
+			reasons = append(reasons, worst.Reasons()...)
+		}
 		if maxFanOut > 10 {
 			reasons = append(reasons, fmt.Sprintf("High Coupling (Calls: %d)", maxFanOut))
 		}
@@ -932,6 +1028,10 @@ func (ai *ASTIndexer) AnalyzeComplexity(projectRoot string, symbolNames []string
 		report.HighRiskSymbols = append(report.HighRiskSymbols, RiskInfo{
 			SymbolName: name,
 			Score:      score,
+			Metrics:    worst,
+			FanIn:      maxFanIn,
+			FanOut:     maxFanOut,
+			Coupling:   float64(maxFanOut)*1.0 + float64(maxFanIn)*0.5,
 			Reason:     strings.Join(reasons, ", "),
 		})
 	}
@@ -939,6 +1039,56 @@ func (ai *ASTIndexer) AnalyzeComplexity(projectRoot string, symbolNames []string
 	return &report, nil
 }
 
+// DirectCallers 直接调用这些符号的函数（与 code_impact 的 direct_callers 同源，直接查 calls 表，不触发重新索引）
+// 每个符号最多取 limit 个调用方，结果按 canonical_id 去重
+func (ai *ASTIndexer) DirectCallers(projectRoot string, symbolNames []string, limit int) ([]Node, error) {
+	dbPath := getDBPath(projectRoot)
+	if !fileExists(dbPath) || len(symbolNames) == 0 {
+		return nil, nil
+	}
+
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	if !hasTable(db, "calls") {
+		return nil, nil
+	}
+
+	seen := make(map[string]bool)
+	var callers []Node
+	for _, name := range symbolNames {
+		rows, err := db.Query(`
+			SELECT DISTINCT s.canonical_id, s.name, COALESCE(s.qualified_name, ''), s.symbol_type,
+				f.file_path, s.line_start, s.line_end
+			FROM calls c
+			JOIN symbols s ON c.caller_id = s.symbol_id
+			JOIN files f ON s.file_id = f.file_id
+			WHERE c.callee_name = ?
+			ORDER BY f.file_path, s.line_start
+			LIMIT ?`, name, limit)
+		if err != nil {
+			continue
+		}
+		for rows.Next() {
+			var n Node
+			if err := rows.Scan(&n.ID, &n.Name, &n.QualifiedName, &n.NodeType, &n.FilePath, &n.LineStart, &n.LineEnd); err != nil {
+				continue
+			}
+			if seen[n.ID] {
+				continue
+			}
+			seen[n.ID] = true
+			n.FilePath = filepath.ToSlash(n.FilePath)
+			callers = append(callers, n)
+		}
+		rows.Close()
+	}
+	return callers, nil
+}
+
 func max(a, b int) int {
 	if a > b {
 		return a
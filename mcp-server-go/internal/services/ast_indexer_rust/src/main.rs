@@ -283,6 +283,52 @@ fn main() -> anyhow::Result<()> {
     Ok(())
 }
 
+/// 构建目录遍历器：遵循 gitignore 语义 (嵌套 .gitignore / .git/info/exclude / .mpmignore)，
+/// 非 git 仓库同样生效；--ignore-dirs 中的目录名按名称匹配，"./" 开头的项按相对项目根的路径匹配
+fn build_walker(args: &Args) -> WalkBuilder {
+    let mut builder = WalkBuilder::new(&args.project);
+    builder.hidden(false);
+    builder.git_ignore(true);
+    builder.git_exclude(true);
+    builder.require_git(false);
+    builder.add_custom_ignore_filename(".mpmignore");
+
+    let project_root = PathBuf::from(&args.project);
+    let mut names: HashSet<String> = HashSet::new();
+    let mut rel_paths: HashSet<String> = HashSet::new();
+    for item in args.ignore_dirs.as_deref().unwrap_or("").split(',') {
+        let item = item.trim();
+        if item.is_empty() {
+            continue;
+        }
+        match item.strip_prefix("./") {
+            Some(rel) => rel_paths.insert(rel.trim_end_matches('/').to_string()),
+            None => names.insert(item.to_string()),
+        };
+    }
+    names.insert(".git".to_string());
+    names.insert(".mcp-data".to_string());
+
+    builder.filter_entry(move |entry| {
+        if !entry.file_type().map(|f| f.is_dir()).unwrap_or(false) {
+            return true;
+        }
+        if names.contains(entry.file_name().to_str().unwrap_or("")) {
+            return false;
+        }
+        if rel_paths.is_empty() {
+            return true;
+        }
+        let rel = entry
+            .path()
+            .strip_prefix(&project_root)
+            .map(|p| p.to_string_lossy().replace('\\', "/"))
+            .unwrap_or_default();
+        !rel_paths.contains(&rel)
+    });
+    builder
+}
+
 fn run_indexer(args: &Args, heartbeat_path: &Path) -> anyhow::Result<()> {
     println!("Starting indexer for: {}", args.project);
 
@@ -298,20 +344,7 @@ fn run_indexer(args: &Args, heartbeat_path: &Path) -> anyhow::Result<()> {
         .unwrap_or_default();
 
     // 2. Discover Files
-    let mut builder = WalkBuilder::new(&args.project);
-    builder.hidden(false); // Process .git ? No, usually we want to ignore .git
-    builder.git_ignore(true); // Respect .gitignore
-
-    if let Some(ignores) = &args.ignore_dirs {
-        let ignore_set: HashSet<String> =
-            ignores.split(',').map(|s| s.trim().to_string()).collect();
-        builder.filter_entry(move |entry| {
-            if !entry.file_type().map(|f| f.is_dir()).unwrap_or(false) {
-                return true;
-            }
-            !ignore_set.contains(entry.file_name().to_str().unwrap_or(""))
-        });
-    }
+    let builder = build_walker(args);
 
     let allowed_exts: HashSet<String> = args
         .extensions
@@ -2145,21 +2178,7 @@ fn run_structure(args: &Args) -> anyhow::Result<()> {
     let project_path = Path::new(&args.project);
 
     // 构建目录遍历器
-    let mut builder = WalkBuilder::new(&args.project);
-    builder.hidden(false);
-    builder.git_ignore(true);
-
-    // 应用忽略目录过滤
-    if let Some(ignores) = &args.ignore_dirs {
-        let ignore_set: HashSet<String> =
-            ignores.split(',').map(|s| s.trim().to_string()).collect();
-        builder.filter_entry(move |entry| {
-            if !entry.file_type().map(|f| f.is_dir()).unwrap_or(false) {
-                return true;
-            }
-            !ignore_set.contains(entry.file_name().to_str().unwrap_or(""))
-        });
-    }
+    let builder = build_walker(args);
 
     // 应用扩展名过滤
     let allowed_exts: HashSet<String> = args
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// ChangedFile 修改时间晚于基准时刻的文件
+type ChangedFile struct {
+	Path    string    `json:"path"` // 相对项目根 (正斜杠)
+	ModTime time.Time `json:"mod_time"`
+}
+
+// ChangedFilesSince 列出 since 之后修改过的文件（遵循忽略规则，最近修改的在前）
+// skip 为需要排除的相对路径 (如 MPM 自身生成的文件)；返回前 limit 个及总数
+func ChangedFilesSince(projectRoot string, since time.Time, limit int, skip map[string]bool) ([]ChangedFile, int, error) {
+	matcher := NewIgnoreMatcher(projectRoot)
+	var changed []ChangedFile
+
+	err := filepath.WalkDir(projectRoot, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if p == projectRoot {
+			return nil
+		}
+		rel, relErr := filepath.Rel(projectRoot, p)
+		if relErr != nil {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			if matcher.Match(rel, true) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if skip[rel] || matcher.Match(rel, false) {
+			return nil
+		}
+		info, infoErr := d.Info()
+		if infoErr != nil || !info.ModTime().After(since) {
+			return nil
+		}
+		changed = append(changed, ChangedFile{Path: rel, ModTime: info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	sort.Slice(changed, func(i, j int) bool {
+		if !changed[i].ModTime.Equal(changed[j].ModTime) {
+			return changed[i].ModTime.After(changed[j].ModTime)
+		}
+		return changed[i].Path < changed[j].Path
+	})
+	total := len(changed)
+	if limit > 0 && len(changed) > limit {
+		changed = changed[:limit]
+	}
+	return changed, total, nil
+}
@@ -0,0 +1,458 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"math"
+	"mcp-server-go/internal/i18n"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// ============================================================================
+// 概念搜索 (BM25，无向量，完全离线)
+// 每个符号的文档 = 名称 (拆分驼峰/下划线) + 签名 + 文档注释 + 文件路径，
+// 分词结果缓存在 symbols.db 的 concept_docs 表中，随索引增量刷新
+// ============================================================================
+
+const conceptDocsSchema = `CREATE TABLE IF NOT EXISTS concept_docs (
+	symbol_id INTEGER PRIMARY KEY,
+	doc TEXT,
+	tokens TEXT
+)`
+
+// BM25 参数
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+
+	// 名称在文档中重复的次数，相当于字段权重
+	conceptNameWeight = 2
+	// 同义词扩展词项的权重
+	conceptSynonymWeight = 0.5
+)
+
+// ConceptHit 概念搜索命中
+type ConceptHit struct {
+	Node    Node     `json:"node"`
+	Score   float64  `json:"score"`
+	Matched []string `json:"matched"` // 命中的查询词项 (词干形式)
+	Doc     string   `json:"doc,omitempty"`
+}
+
+// conceptStopwords 不参与检索的英文虚词与语言关键字
+var conceptStopwords = map[string]bool{
+	"a": true, "an": true, "the": true, "of": true, "to": true, "and": true, "or": true,
+	"in": true, "on": true, "for": true, "is": true, "are": true, "be": true, "with": true,
+	"by": true, "it": true, "its": true, "this": true, "that": true, "as": true, "at": true,
+	"from": true, "into": true, "how": true, "what": true, "where": true, "which": true,
+	"func": true, "def": true, "fn": true, "function": true, "class": true, "struct": true,
+	"interface": true, "type": true, "public": true, "private": true, "protected": true,
+	"static": true, "const": true, "let": true, "var": true, "return": true, "self": true,
+	"pub": true, "void": true, "async": true, "await": true, "impl": true, "mut": true,
+}
+
+// conceptSynonyms 常见编程概念的同义词，仅用于扩展查询 (查询时统一词干化)
+var conceptSynonyms = map[string][]string{
+	"persist": {"save", "store", "write", "insert", "create"},
+	"save":    {"persist", "store", "write"},
+	"store":   {"save", "persist"},
+	"expiry":  {"ttl", "timeout", "deadline"},
+	"ttl":     {"expiry"},
+	"delete":  {"remove", "drop", "clear"},
+	"remove":  {"delete", "drop"},
+	"fetch":   {"get", "load", "read"},
+	"load":    {"read", "fetch"},
+	"find":    {"search", "lookup", "query"},
+	"search":  {"find", "lookup", "query"},
+	"config":  {"setting", "option"},
+	"init":    {"setup", "start", "bootstrap"},
+	"error":   {"err", "fail"},
+	"fail":    {"error"},
+}
+
+// SplitIdentifier 拆分驼峰/下划线/数字边界，返回小写子词
+// 例: "parseHTTPResponse_v2" -> [parse http response v 2]
+func SplitIdentifier(ident string) []string {
+	var parts []string
+	runes := []rune(ident)
+	start := -1
+	flush := func(end int) {
+		if start >= 0 && end > start {
+			parts = append(parts, strings.ToLower(string(runes[start:end])))
+		}
+		start = -1
+	}
+
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			flush(i)
+			continue
+		}
+		if start < 0 {
+			start = i
+			continue
+		}
+		prev := runes[i-1]
+		switch {
+		case unicode.IsDigit(r) != unicode.IsDigit(prev):
+			flush(i)
+			start = i
+		case unicode.IsUpper(r) && unicode.IsLower(prev):
+			flush(i)
+			start = i
+		case unicode.IsUpper(r) && unicode.IsUpper(prev) && i+1 < len(runes) && unicode.IsLower(runes[i+1]):
+			// HTTPServer: 在最后一个大写字母前断开
+			flush(i)
+			start = i
+		}
+	}
+	flush(len(runes))
+	return parts
+}
+
+// ConceptTerms 将任意文本转换为检索词项：拆分标识符、去停用词、词干化，
+// 中文按二元组切分
+func ConceptTerms(text string) []string {
+	var terms []string
+	var word []rune
+	var han []rune
+
+	flushWord := func() {
+		if len(word) == 0 {
+			return
+		}
+		for _, part := range SplitIdentifier(string(word)) {
+			if len(part) < 2 || conceptStopwords[part] {
+				continue
+			}
+			terms = append(terms, stemTerm(part))
+		}
+		word = word[:0]
+	}
+	flushHan := func() {
+		switch {
+		case len(han) == 1:
+			terms = append(terms, string(han))
+		case len(han) > 1:
+			for i := 0; i+1 < len(han); i++ {
+				terms = append(terms, string(han[i:i+2]))
+			}
+		}
+		han = han[:0]
+	}
+
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			flushWord()
+			han = append(han, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_':
+			flushHan()
+			word = append(word, r)
+		default:
+			flushWord()
+			flushHan()
+		}
+	}
+	flushWord()
+	flushHan()
+	return terms
+}
+
+// stemSuffixes 轻量词干规则，按顺序尝试，只剥离一个后缀
+var stemSuffixes = []string{"ations", "ation", "ings", "ing", "ies", "ied", "ed", "es", "s", "y", "e"}
+
+// stemTerm 轻量英文词干化：让 expiry/expires/expired、hook/hooks 归一到同一词干
+func stemTerm(w string) string {
+	if !isASCIIWord(w) {
+		return w
+	}
+	for _, suf := range stemSuffixes {
+		if !strings.HasSuffix(w, suf) {
+			continue
+		}
+		stem := w[:len(w)-len(suf)]
+		minLen := 3
+		if suf == "ing" || suf == "ings" {
+			minLen = 4
+		}
+		if len(stem) < minLen {
+			continue
+		}
+		if suf == "s" && (strings.HasSuffix(w, "ss") || strings.HasSuffix(w, "us") || strings.HasSuffix(w, "is")) {
+			continue
+		}
+		if suf == "ing" || suf == "ings" || suf == "ed" {
+			// running -> run, stopped -> stop (ll/ss/zz 保留)
+			n := len(stem)
+			if n >= 2 && stem[n-1] == stem[n-2] && !strings.ContainsRune("lsz", rune(stem[n-1])) && !strings.ContainsRune("aeiou", rune(stem[n-1])) {
+				stem = stem[:n-1]
+			}
+		}
+		return stem
+	}
+	return w
+}
+
+func isASCIIWord(w string) bool {
+	for i := 0; i < len(w); i++ {
+		c := w[i]
+		if !(c >= 'a' && c <= 'z') {
+			return false
+		}
+	}
+	return true
+}
+
+// conceptDocTokens 构造符号的检索文档
+func conceptDocTokens(name, qualifiedName, signature, doc, path string) []string {
+	var tokens []string
+	nameTerms := ConceptTerms(name)
+	for i := 0; i < conceptNameWeight; i++ {
+		tokens = append(tokens, nameTerms...)
+	}
+	if qualifiedName != "" && qualifiedName != name {
+		tokens = append(tokens, ConceptTerms(qualifiedName)...)
+	}
+	tokens = append(tokens, ConceptTerms(signature)...)
+	tokens = append(tokens, ConceptTerms(doc)...)
+	tokens = append(tokens, ConceptTerms(strings.TrimSuffix(path, filepath.Ext(path)))...)
+	return tokens
+}
+
+// refreshConceptIndex 为尚未分词的符号提取文档注释并写入 concept_docs
+func refreshConceptIndex(projectRoot string) error {
+	dbPath := getDBPath(projectRoot)
+	if !fileExists(dbPath) {
+		return nil
+	}
+
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if !hasTable(db, "symbols") {
+		return nil
+	}
+	if _, err := db.Exec(conceptDocsSchema); err != nil {
+		return err
+	}
+	if _, err := db.Exec("DELETE FROM concept_docs WHERE symbol_id NOT IN (SELECT symbol_id FROM symbols)"); err != nil {
+		return err
+	}
+
+	type pending struct {
+		id                   int64
+		name, qualified, sig string
+		lineStart, lineEnd   int
+	}
+	rows, err := db.Query(`
+		SELECT s.symbol_id, s.name, COALESCE(s.qualified_name, ''), COALESCE(s.signature, ''),
+			COALESCE(s.line_start, 0), COALESCE(s.line_end, 0), f.file_path
+		FROM symbols s
+		JOIN files f ON s.file_id = f.file_id
+		LEFT JOIN concept_docs c ON c.symbol_id = s.symbol_id
+		WHERE c.symbol_id IS NULL
+		ORDER BY f.file_path, s.line_start`)
+	if err != nil {
+		return err
+	}
+
+	byFile := make(map[string][]pending)
+	var order []string
+	for rows.Next() {
+		var p pending
+		var path string
+		if err := rows.Scan(&p.id, &p.name, &p.qualified, &p.sig, &p.lineStart, &p.lineEnd, &path); err != nil {
+			continue
+		}
+		if _, ok := byFile[path]; !ok {
+			order = append(order, path)
+		}
+		byFile[path] = append(byFile[path], p)
+	}
+	rows.Close()
+
+	if len(order) == 0 {
+		return nil
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	stmt, err := tx.Prepare("INSERT OR REPLACE INTO concept_docs (symbol_id, doc, tokens) VALUES (?, ?, ?)")
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+
+	for _, path := range order {
+		var lines []string
+		if data, err := os.ReadFile(filepath.Join(projectRoot, path)); err == nil {
+			lines = strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+		}
+		lang := metricsLanguage(path)
+		for _, p := range byFile[path] {
+			doc := ExtractDocComment(lang, lines, p.lineStart, p.lineEnd)
+			tokens := conceptDocTokens(p.name, p.qualified, p.sig, doc, path)
+			if _, err := stmt.Exec(p.id, doc, strings.Join(tokens, " ")); err != nil {
+				tx.Rollback()
+				return err
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+// conceptQuery 查询词项及其权重 (同义词扩展权重较低)
+func conceptQuery(query string) map[string]float64 {
+	weights := make(map[string]float64)
+	for _, t := range ConceptTerms(query) {
+		weights[t] = 1
+	}
+	synonyms := make(map[string][]string, len(conceptSynonyms))
+	for word, syns := range conceptSynonyms {
+		key := stemTerm(word)
+		for _, syn := range syns {
+			synonyms[key] = append(synonyms[key], stemTerm(syn))
+		}
+	}
+	for _, t := range sortedKeys(weights) {
+		for _, syn := range synonyms[t] {
+			if _, ok := weights[syn]; !ok {
+				weights[syn] = conceptSynonymWeight
+			}
+		}
+	}
+	return weights
+}
+
+// ConceptSearch 用 BM25 按概念检索符号
+// scope 为相对路径前缀，kind 为 function/class/any
+func (ai *ASTIndexer) ConceptSearch(projectRoot, query, scope, kind string, limit int) ([]ConceptHit, error) {
+	if err := refreshConceptIndex(projectRoot); err != nil {
+		return nil, errors.New(i18n.T("概念索引刷新失败: %v", err))
+	}
+	weights := conceptQuery(query)
+	if len(weights) == 0 {
+		return nil, nil
+	}
+	if limit <= 0 {
+		limit = 10
+	}
+
+	db, err := sql.Open("sqlite", getDBPath(projectRoot))
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	if !hasTable(db, "concept_docs") {
+		return nil, nil
+	}
+
+	rows, err := db.Query(`
+		SELECT s.name, COALESCE(s.qualified_name, ''), s.symbol_type, COALESCE(s.signature, ''),
+			COALESCE(s.line_start, 0), COALESCE(s.line_end, 0), f.file_path, COALESCE(c.doc, ''), c.tokens
+		FROM concept_docs c
+		JOIN symbols s ON s.symbol_id = c.symbol_id
+		JOIN files f ON s.file_id = f.file_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	type candidate struct {
+		hit    ConceptHit
+		tf     map[string]int
+		length int
+	}
+	var docs []candidate
+	df := make(map[string]int)
+	totalLen := 0
+
+	for rows.Next() {
+		var n Node
+		var doc, tokens string
+		if err := rows.Scan(&n.Name, &n.QualifiedName, &n.NodeType, &n.Signature, &n.LineStart, &n.LineEnd, &n.FilePath, &doc, &tokens); err != nil {
+			continue
+		}
+		terms := strings.Fields(tokens)
+		totalLen += len(terms)
+
+		// df 统计全部文档，过滤只影响候选集合
+		tf := make(map[string]int)
+		for _, t := range terms {
+			if _, ok := weights[t]; ok {
+				tf[t]++
+			}
+		}
+		for t := range tf {
+			df[t]++
+		}
+		if len(tf) == 0 || !conceptKindMatches(kind, n.NodeType) {
+			continue
+		}
+		if !inScope(filepath.ToSlash(n.FilePath), scope) {
+			continue
+		}
+		docs = append(docs, candidate{hit: ConceptHit{Node: n, Doc: doc}, tf: tf, length: len(terms)})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(docs) == 0 {
+		return nil, nil
+	}
+
+	// 文档总数需要包含未命中的符号
+	var totalDocs int
+	_ = db.QueryRow("SELECT COUNT(*) FROM concept_docs").Scan(&totalDocs)
+	if totalDocs == 0 {
+		totalDocs = len(docs)
+	}
+	avgLen := float64(totalLen) / float64(totalDocs)
+
+	hits := make([]ConceptHit, 0, len(docs))
+	for _, d := range docs {
+		score := 0.0
+		for t, f := range d.tf {
+			idf := math.Log(1 + (float64(totalDocs)-float64(df[t])+0.5)/(float64(df[t])+0.5))
+			norm := float64(f) * (bm25K1 + 1) / (float64(f) + bm25K1*(1-bm25B+bm25B*float64(d.length)/avgLen))
+			score += weights[t] * idf * norm
+			d.hit.Matched = append(d.hit.Matched, t)
+		}
+		sort.Strings(d.hit.Matched)
+		d.hit.Score = score
+		hits = append(hits, d.hit)
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].Node.Name < hits[j].Node.Name
+	})
+	if len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits, nil
+}
+
+func conceptKindMatches(kind, nodeType string) bool {
+	switch kind {
+	case "function":
+		return nodeType == "function" || nodeType == "method"
+	case "class":
+		return nodeType == "class" || nodeType == "struct" || nodeType == "interface"
+	default:
+		return true
+	}
+}
@@ -0,0 +1,148 @@
+package services
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSplitIdentifier(t *testing.T) {
+	cases := map[string][]string{
+		"CreateHook":           {"create", "hook"},
+		"list_hooks":           {"list", "hooks"},
+		"parseHTTPResponse_v2": {"parse", "http", "response", "v", "2"},
+		"ID":                   {"id"},
+		"expiresHours":         {"expires", "hours"},
+	}
+	for in, want := range cases {
+		if got := SplitIdentifier(in); !reflect.DeepEqual(got, want) {
+			t.Errorf("SplitIdentifier(%q) = %v, want %v", in, got, want)
+		}
+	}
+}
+
+func TestConceptTermsStemming(t *testing.T) {
+	same := [][]string{
+		{"expiry", "expires", "expired", "expiration"},
+		{"hook", "hooks"},
+		{"create", "created", "creating"},
+		{"entry", "entries"},
+		{"address", "addresses"},
+		{"run", "running"},
+	}
+	for _, group := range same {
+		want := ConceptTerms(group[0])
+		for _, w := range group[1:] {
+			if got := ConceptTerms(w); !reflect.DeepEqual(got, want) {
+				t.Errorf("ConceptTerms(%q) = %v, want %v (same as %q)", w, got, want, group[0])
+			}
+		}
+	}
+
+	if got := ConceptTerms("the string"); !reflect.DeepEqual(got, []string{"string"}) {
+		t.Errorf("stopwords/short stems: got %v", got)
+	}
+	if got := ConceptTerms("创建钩子"); !reflect.DeepEqual(got, []string{"创建", "建钩", "钩子"}) {
+		t.Errorf("han bigrams: got %v", got)
+	}
+}
+
+func TestConceptSearchRanksByConcept(t *testing.T) {
+	root := t.TempDir()
+	src := `package core
+
+// CreateHook 创建待办钩子，可设置过期时间
+func (m *MemoryLayer) CreateHook(ctx context.Context, description, priority, tag, taskID string, expiresHours int) (string, error) {
+	return "", nil
+}
+
+// ListHooks 列出钩子
+func (m *MemoryLayer) ListHooks(ctx context.Context, status string) ([]Hook, error) {
+	return nil, nil
+}
+
+// SearchMemos 搜索备忘录
+func (m *MemoryLayer) SearchMemos(ctx context.Context, keywords string, limit int) ([]Memo, error) {
+	return nil, nil
+}
+`
+	if err := os.MkdirAll(filepath.Join(root, "internal", "core"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "internal", "core", "memory.go"), []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(root, ".mcp-data"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := sql.Open("sqlite", getDBPath(root))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, stmt := range []string{
+		`CREATE TABLE files (file_id INTEGER PRIMARY KEY, file_path TEXT UNIQUE)`,
+		`CREATE TABLE symbols (symbol_id INTEGER PRIMARY KEY, file_id INTEGER, name TEXT, qualified_name TEXT,
+			canonical_id TEXT, symbol_type TEXT, line_start INTEGER, line_end INTEGER, signature TEXT)`,
+		`INSERT INTO files VALUES (1, 'internal/core/memory.go'), (2, 'internal/tools/render.go')`,
+		`INSERT INTO symbols VALUES
+			(1, 1, 'CreateHook', 'MemoryLayer.CreateHook', 'func:internal/core/memory.go::CreateHook', 'function', 4, 6,
+				'func (m *MemoryLayer) CreateHook(ctx context.Context, description, priority, tag, taskID string, expiresHours int) (string, error) {'),
+			(2, 1, 'ListHooks', 'MemoryLayer.ListHooks', 'func:internal/core/memory.go::ListHooks', 'function', 9, 11,
+				'func (m *MemoryLayer) ListHooks(ctx context.Context, status string) ([]Hook, error) {'),
+			(3, 1, 'SearchMemos', 'MemoryLayer.SearchMemos', 'func:internal/core/memory.go::SearchMemos', 'function', 14, 16,
+				'func (m *MemoryLayer) SearchMemos(ctx context.Context, keywords string, limit int) ([]Memo, error) {'),
+			(4, 2, 'renderTable', 'renderTable', 'func:internal/tools/render.go::renderTable', 'function', 1, 5,
+				'func renderTable(rows [][]string) string {')`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("setup %q: %v", stmt, err)
+		}
+	}
+	db.Close()
+
+	ai := &ASTIndexer{}
+	hits, err := ai.ConceptSearch(root, "persist hook expiry", "", "any", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hits) < 2 || hits[0].Node.Name != "CreateHook" || hits[1].Node.Name != "ListHooks" {
+		t.Fatalf("unexpected ranking: %+v", hits)
+	}
+	if hits[0].Doc != "CreateHook 创建待办钩子，可设置过期时间" {
+		t.Fatalf("doc comment not indexed: %q", hits[0].Doc)
+	}
+	for _, h := range hits {
+		if h.Node.Name == "renderTable" {
+			t.Fatalf("unrelated symbol matched: %+v", h)
+		}
+	}
+
+	// 中文描述通过文档注释命中
+	hits, err = ai.ConceptSearch(root, "钩子 过期", "", "any", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hits) == 0 || hits[0].Node.Name != "CreateHook" {
+		t.Fatalf("chinese query ranking: %+v", hits)
+	}
+
+	// scope 过滤
+	hits, err = ai.ConceptSearch(root, "hook", "internal/tools", "any", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hits) != 0 {
+		t.Fatalf("scope filter ignored: %+v", hits)
+	}
+
+	// scope 按路径段匹配
+	if hits, _ = ai.ConceptSearch(root, "hook", "internal/cor", "any", 10); len(hits) != 0 {
+		t.Fatalf("scope must match whole path segments: %+v", hits)
+	}
+	if hits, _ = ai.ConceptSearch(root, "hook", "./internal/core/", "any", 10); len(hits) == 0 {
+		t.Fatalf("scope should be cleaned before matching")
+	}
+}
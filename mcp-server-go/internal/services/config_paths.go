@@ -0,0 +1,38 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// ProjectConfigDirName 项目级配置目录 (与 .mcp-data 并列，适合纳入版本控制)
+const ProjectConfigDirName = ".mcp-config"
+
+// GlobalConfigDir 全局配置目录：$MPM_HOME，未设置时为 ~/.mpm；无法确定时返回空串
+func GlobalConfigDir() string {
+	if dir := os.Getenv("MPM_HOME"); dir != "" {
+		return dir
+	}
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		return ""
+	}
+	return filepath.Join(home, ".mpm")
+}
+
+// ProjectConfigDir 项目级配置目录
+func ProjectConfigDir(projectRoot string) string {
+	return filepath.Join(projectRoot, ProjectConfigDirName)
+}
+
+// configLayerPaths 按优先级从低到高返回全局与项目级的同名配置文件路径
+func configLayerPaths(projectRoot, name string) []string {
+	var paths []string
+	if dir := GlobalConfigDir(); dir != "" {
+		paths = append(paths, filepath.Join(dir, name))
+	}
+	if projectRoot != "" {
+		paths = append(paths, filepath.Join(ProjectConfigDir(projectRoot), name))
+	}
+	return paths
+}
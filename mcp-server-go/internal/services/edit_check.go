@@ -0,0 +1,227 @@
+package services
+
+import (
+	"bufio"
+	"errors"
+	"mcp-server-go/internal/i18n"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// EditedFile 一次拟议修改涉及的文件
+type EditedFile struct {
+	Path    string     `json:"path"`
+	Added   []DiffLine `json:"added,omitempty"` // 新增行 (仅 diff 输入时有)
+	Deleted bool       `json:"deleted,omitempty"`
+}
+
+// DiffLine diff 中的新增行
+type DiffLine struct {
+	Line int    `json:"line"`
+	Text string `json:"text"`
+}
+
+// EditConstraint 可执行的约束规则 (由 constraint_rules 表转换而来)
+type EditConstraint struct {
+	Name         string
+	Description  string
+	FilePatterns []string // 适用范围，空表示全部文件
+	Definition   ConstraintDefinition
+}
+
+// ConstraintDefinition 约束规则的 rule_definition JSON
+type ConstraintDefinition struct {
+	ForbidPaths    []string `json:"forbid_paths,omitempty"`    // 命中即违规 (gitignore 语法)
+	ForbidPatterns []string `json:"forbid_patterns,omitempty"` // 新增行命中正则即违规
+}
+
+// EditViolation 违规项
+type EditViolation struct {
+	Source string `json:"source"` // guardrail | constraint
+	Rule   string `json:"rule"`
+	Path   string `json:"path"`
+	Line   int    `json:"line,omitempty"`
+	Detail string `json:"detail,omitempty"`
+}
+
+var hunkHeader = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(\d+)(?:,\d+)? @@`)
+
+// ParseUnifiedDiff 从 unified diff (git diff 输出) 中提取文件与新增行
+func ParseUnifiedDiff(diff string) []EditedFile {
+	var files []EditedFile
+	index := map[string]int{}
+	cur := -1
+	line := 0
+	oldPath := ""
+
+	use := func(path string, deleted bool) {
+		path = cleanDiffPath(path)
+		if path == "" {
+			cur = -1
+			return
+		}
+		if i, ok := index[path]; ok {
+			cur = i
+		} else {
+			files = append(files, EditedFile{Path: path})
+			cur = len(files) - 1
+			index[path] = cur
+		}
+		if deleted {
+			files[cur].Deleted = true
+		}
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(diff))
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		text := scanner.Text()
+		switch {
+		case strings.HasPrefix(text, "diff --git "):
+			// diff --git a/x b/x：先按 b/ 路径登记，后续 +++ 会覆盖
+			fields := strings.Fields(text)
+			if len(fields) >= 4 {
+				use(fields[3], false)
+			}
+			oldPath = ""
+		case strings.HasPrefix(text, "--- "):
+			oldPath = strings.TrimSpace(strings.TrimPrefix(text, "--- "))
+		case strings.HasPrefix(text, "+++ "):
+			newPath := strings.TrimSpace(strings.TrimPrefix(text, "+++ "))
+			if newPath == "/dev/null" {
+				use(oldPath, true)
+			} else {
+				use(newPath, false)
+			}
+		case strings.HasPrefix(text, "@@"):
+			if m := hunkHeader.FindStringSubmatch(text); m != nil {
+				line, _ = strconv.Atoi(m[1])
+			}
+		case cur >= 0 && line > 0 && strings.HasPrefix(text, "+"):
+			files[cur].Added = append(files[cur].Added, DiffLine{Line: line, Text: text[1:]})
+			line++
+		case cur >= 0 && line > 0 && strings.HasPrefix(text, " "):
+			line++
+		}
+	}
+	return files
+}
+
+func cleanDiffPath(p string) string {
+	if i := strings.IndexByte(p, '\t'); i >= 0 {
+		p = p[:i]
+	}
+	p = strings.Trim(p, `"`)
+	if p == "" || p == "/dev/null" {
+		return ""
+	}
+	if strings.HasPrefix(p, "a/") || strings.HasPrefix(p, "b/") {
+		p = p[2:]
+	}
+	return filepath.ToSlash(filepath.Clean(p))
+}
+
+// RelativeEditPath 把用户给出的路径规整为相对项目根的 / 分隔路径 (接受绝对路径与 \ 分隔)；越出项目时返回错误
+func RelativeEditPath(projectRoot, p string) (string, error) {
+	p = strings.ReplaceAll(strings.TrimSpace(p), `\`, "/")
+	if p == "" {
+		return "", errors.New(i18n.T("路径为空"))
+	}
+	if filepath.IsAbs(p) {
+		rel, err := filepath.Rel(projectRoot, p)
+		if err != nil {
+			return "", err
+		}
+		p = rel
+	}
+	p = filepath.ToSlash(filepath.Clean(p))
+	if p == ".." || strings.HasPrefix(p, "../") {
+		return "", errors.New(i18n.T("%s 不在项目目录内", p))
+	}
+	return p, nil
+}
+
+// CheckGuardrails 按禁令检查文件；返回违规项与无法自动检查的禁令
+func CheckGuardrails(rules *IntentRules, guardrails []string, files []EditedFile) ([]EditViolation, []string) {
+	var violations []EditViolation
+	var unchecked []string
+	for _, g := range guardrails {
+		check, ok := rules.GuardrailCheckFor(g)
+		if !ok {
+			unchecked = append(unchecked, g)
+			continue
+		}
+		for _, f := range files {
+			if check.Violates(f.Path) {
+				violations = append(violations, EditViolation{
+					Source: "guardrail",
+					Rule:   GuardrailCode(g),
+					Path:   f.Path,
+					Detail: g,
+				})
+			}
+		}
+	}
+	return violations, unchecked
+}
+
+// CheckConstraints 按约束规则检查文件；返回违规项与无效的规则说明
+func CheckConstraints(constraints []EditConstraint, files []EditedFile) ([]EditViolation, []string) {
+	var violations []EditViolation
+	var invalid []string
+	for _, c := range constraints {
+		var patterns []*regexp.Regexp
+		for _, p := range c.Definition.ForbidPatterns {
+			re, err := regexp.Compile(p)
+			if err != nil {
+				invalid = append(invalid, i18n.T("%s: 正则 %q 无效 (%v)", c.Name, p, err))
+				continue
+			}
+			patterns = append(patterns, re)
+		}
+
+		for _, f := range files {
+			if !matchesAnyGlob(c.FilePatterns, f.Path, true) {
+				continue
+			}
+			if matchesAnyGlob(c.Definition.ForbidPaths, f.Path, false) {
+				violations = append(violations, EditViolation{
+					Source: "constraint", Rule: c.Name, Path: f.Path, Detail: c.Description,
+				})
+			}
+			for _, dl := range f.Added {
+				for _, re := range patterns {
+					if re.MatchString(dl.Text) {
+						violations = append(violations, EditViolation{
+							Source: "constraint", Rule: c.Name, Path: f.Path, Line: dl.Line,
+							Detail: i18n.T("新增行命中 %s: %s", re.String(), strings.TrimSpace(dl.Text)),
+						})
+						break
+					}
+				}
+			}
+		}
+	}
+	sort.SliceStable(violations, func(i, j int) bool {
+		if violations[i].Path != violations[j].Path {
+			return violations[i].Path < violations[j].Path
+		}
+		return violations[i].Line < violations[j].Line
+	})
+	return violations, invalid
+}
+
+func matchesAnyGlob(patterns []string, rel string, emptyMatches bool) bool {
+	if len(patterns) == 0 {
+		return emptyMatches
+	}
+	for _, p := range patterns {
+		if MatchPathGlob(p, rel) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+)
+
+const sampleDiff = `diff --git a/internal/auth/login.go b/internal/auth/login.go
+index 1111111..2222222 100644
+--- a/internal/auth/login.go
++++ b/internal/auth/login.go
+@@ -10,3 +10,4 @@ func Login() {
+ 	ctx := context.Background()
+-	c := legacy.NewClient()
++	c := legacy.NewClient(ctx)
++	defer c.Close()
+ 	return c.Do()
+diff --git a/docs/old.md b/docs/old.md
+deleted file mode 100644
+--- a/docs/old.md
++++ /dev/null
+@@ -1,1 +0,0 @@
+-# old
+`
+
+func TestParseUnifiedDiff(t *testing.T) {
+	files := ParseUnifiedDiff(sampleDiff)
+	if len(files) != 2 {
+		t.Fatalf("files = %+v", files)
+	}
+	want := []DiffLine{{Line: 11, Text: "\tc := legacy.NewClient(ctx)"}, {Line: 12, Text: "\tdefer c.Close()"}}
+	if files[0].Path != "internal/auth/login.go" || !reflect.DeepEqual(files[0].Added, want) {
+		t.Errorf("file[0] = %+v", files[0])
+	}
+	if files[1].Path != "docs/old.md" || !files[1].Deleted || len(files[1].Added) != 0 {
+		t.Errorf("file[1] = %+v", files[1])
+	}
+}
+
+func TestCheckGuardrails(t *testing.T) {
+	rules := DefaultIntentRules()
+	files := []EditedFile{{Path: "main.go"}, {Path: "docs/design.md"}, {Path: "web/app.tsx"}}
+	cases := []struct {
+		guardrail     string
+		wantPaths     []string
+		wantUnchecked bool
+	}{
+		{"READ_ONLY: 严禁修改任何文件", []string{"main.go", "docs/design.md", "web/app.tsx"}, false},
+		{"NO_CODE_EDIT: 禁止修改代码", []string{"main.go", "web/app.tsx"}, false},
+		{"MD_ONLY: 仅允许修改 Markdown", []string{"main.go", "web/app.tsx"}, false},
+		{"VERIFY_FIRST: 修改前必须先定位根因", nil, true},
+	}
+	for _, c := range cases {
+		t.Run(GuardrailCode(c.guardrail), func(t *testing.T) {
+			violations, unchecked := CheckGuardrails(rules, []string{c.guardrail}, files)
+			var paths []string
+			for _, v := range violations {
+				paths = append(paths, v.Path)
+			}
+			if !reflect.DeepEqual(paths, c.wantPaths) {
+				t.Errorf("violations = %v, want %v", paths, c.wantPaths)
+			}
+			if (len(unchecked) > 0) != c.wantUnchecked {
+				t.Errorf("unchecked = %v", unchecked)
+			}
+		})
+	}
+}
+
+func TestCheckConstraints(t *testing.T) {
+	files := ParseUnifiedDiff(sampleDiff)
+	files = append(files, EditedFile{Path: "vendor/lib/x.go"})
+	constraints := []EditConstraint{
+		{Name: "no-legacy", FilePatterns: []string{"*.go"}, Definition: ConstraintDefinition{ForbidPatterns: []string{`legacy\.NewClient`}}},
+		{Name: "no-vendor", Definition: ConstraintDefinition{ForbidPaths: []string{"vendor/"}}},
+		{Name: "md-only-legacy", FilePatterns: []string{"*.md"}, Definition: ConstraintDefinition{ForbidPatterns: []string{"legacy"}}},
+		{Name: "broken", Definition: ConstraintDefinition{ForbidPatterns: []string{"("}}},
+	}
+	violations, invalid := CheckConstraints(constraints, files)
+	if len(invalid) != 1 {
+		t.Errorf("invalid = %v", invalid)
+	}
+	got := map[string]int{}
+	for _, v := range violations {
+		got[v.Rule+"@"+v.Path] = v.Line
+	}
+	want := map[string]int{"no-legacy@internal/auth/login.go": 11, "no-vendor@vendor/lib/x.go": 0}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("violations = %v, want %v", got, want)
+	}
+}
+
+func TestRelativeEditPath(t *testing.T) {
+	root := t.TempDir()
+	if got, err := RelativeEditPath(root, root+"/pkg/a.go"); err != nil || got != "pkg/a.go" {
+		t.Errorf("abs path = %q, %v", got, err)
+	}
+	if got, err := RelativeEditPath(root, `pkg\sub\a.go`); err != nil || got != "pkg/sub/a.go" {
+		t.Errorf("backslash path = %q, %v", got, err)
+	}
+	for _, p := range []string{"../other/a.go", "..", `pkg\..\..\a.go`} {
+		if _, err := RelativeEditPath(root, p); err == nil {
+			t.Errorf("path %q outside project should fail", p)
+		}
+	}
+}
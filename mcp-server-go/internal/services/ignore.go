@@ -0,0 +1,381 @@
+package services
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// ============================================================================
+// 忽略规则 (gitignore 语义)
+// 技术栈检测、索引参数、原生搜索兜底与 Timeline 共用同一套匹配器
+// ============================================================================
+
+// MPMIgnoreFile 项目级忽略文件，语法与 .gitignore 相同，同目录下优先于 .gitignore
+const MPMIgnoreFile = ".mpmignore"
+
+// alwaysIgnoredDirs 无论规则如何都跳过的目录 (VCS 元数据与 MPM 缓存)
+var alwaysIgnoredDirs = map[string]bool{
+	".git": true, ".svn": true, ".hg": true, ".mcp-data": true,
+}
+
+// DefaultIgnoreDirs 内置的第三方/产物目录，作为优先级最低的一层规则，
+// 可在 .gitignore 或 .mpmignore 中用 "!name/" 重新纳入；mpm.yaml 的 ignore 段可替换或追加
+var DefaultIgnoreDirs = []string{
+	"node_modules", "__pycache__", ".pytest_cache", ".venv", "venv", "site-packages",
+	".idea", ".vscode", "dist", "build", "target", "vendor", "coverage",
+	".next", ".nuxt", "out", "release", "releases", "archive", "backup", "old",
+}
+
+// ignoreRule 单条编译后的规则
+type ignoreRule struct {
+	base    string // 规则所在目录 (相对项目根，"" 表示根)
+	re      *regexp.Regexp
+	negate  bool
+	dirOnly bool
+	tail    string // 规则的最后一段，用于判断否定规则能否重新纳入某个目录名
+}
+
+// IgnoreMatcher 按 gitignore 语义判断项目内路径是否被忽略
+// 规则优先级 (低 -> 高): 内置默认 < .git/info/exclude < 各级目录的 .gitignore < 同目录 .mpmignore，
+// 深层目录的规则覆盖浅层，同一层内后出现的规则覆盖先出现的
+type IgnoreMatcher struct {
+	root string
+
+	mu       sync.Mutex
+	global   []ignoreRule
+	dirRules map[string][]ignoreRule // 目录 -> 该目录下 ignore 文件的规则 (懒加载)
+	dirCache map[string]bool         // 目录 -> 是否被忽略
+}
+
+// NewIgnoreMatcher 为项目根创建匹配器，ignore 文件在首次用到时读取
+func NewIgnoreMatcher(root string) *IgnoreMatcher {
+	if abs, err := filepath.Abs(root); err == nil {
+		root = abs
+	}
+	m := &IgnoreMatcher{
+		root:     root,
+		dirRules: make(map[string][]ignoreRule),
+		dirCache: make(map[string]bool),
+	}
+	for _, d := range IgnoreDirsFor(root) {
+		if r, ok := compileIgnoreRule(d+"/", ""); ok {
+			m.global = append(m.global, r)
+		}
+	}
+	m.global = append(m.global, readIgnoreFile(filepath.Join(root, ".git", "info", "exclude"), "")...)
+	return m
+}
+
+// Match 判断相对项目根的路径是否被忽略 (任一父目录被忽略时，其下内容不可再被否定规则纳入)
+func (m *IgnoreMatcher) Match(rel string, isDir bool) bool {
+	rel = cleanIgnorePath(rel)
+	if rel == "" {
+		return false
+	}
+	parent := path.Dir(rel)
+	if parent != "." && m.dirIgnored(parent) {
+		return true
+	}
+	if isDir {
+		return m.dirIgnored(rel)
+	}
+	return m.matchRules(rel, false)
+}
+
+// MatchPath 与 Match 相同，但接受绝对路径或相对路径；项目外的路径视为不忽略
+func (m *IgnoreMatcher) MatchPath(p string, isDir bool) bool {
+	if filepath.IsAbs(p) {
+		rel, err := filepath.Rel(m.root, p)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return false
+		}
+		p = rel
+	}
+	return m.Match(p, isDir)
+}
+
+func (m *IgnoreMatcher) dirIgnored(rel string) bool {
+	m.mu.Lock()
+	v, ok := m.dirCache[rel]
+	m.mu.Unlock()
+	if ok {
+		return v
+	}
+
+	parent := path.Dir(rel)
+	ignored := alwaysIgnoredDirs[path.Base(rel)] ||
+		(parent != "." && m.dirIgnored(parent)) ||
+		m.matchRules(rel, true)
+
+	m.mu.Lock()
+	m.dirCache[rel] = ignored
+	m.mu.Unlock()
+	return ignored
+}
+
+// matchRules 按优先级从高到低查找第一条命中的规则
+func (m *IgnoreMatcher) matchRules(rel string, isDir bool) bool {
+	// 收集从最深的父目录到根目录的规则层
+	var layers [][]ignoreRule
+	for dir := path.Dir(rel); ; dir = path.Dir(dir) {
+		if dir == "." {
+			layers = append(layers, m.rulesFor(""))
+			break
+		}
+		layers = append(layers, m.rulesFor(dir))
+	}
+	layers = append(layers, m.global)
+
+	for _, rules := range layers {
+		for i := len(rules) - 1; i >= 0; i-- {
+			if rules[i].matches(rel, isDir) {
+				return !rules[i].negate
+			}
+		}
+	}
+	return false
+}
+
+// rulesFor 读取并缓存某目录下的 .gitignore 与 .mpmignore
+func (m *IgnoreMatcher) rulesFor(dir string) []ignoreRule {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if rules, ok := m.dirRules[dir]; ok {
+		return rules
+	}
+	abs := filepath.Join(m.root, filepath.FromSlash(dir))
+	rules := readIgnoreFile(filepath.Join(abs, ".gitignore"), dir)
+	rules = append(rules, readIgnoreFile(filepath.Join(abs, MPMIgnoreFile), dir)...)
+	m.dirRules[dir] = rules
+	return rules
+}
+
+// BlanketIgnoreDirs 从目录名列表中去掉可能被否定规则 (如 "!build/") 重新纳入的名称，
+// 剩下的名称在任何位置都被忽略，可以交给 rg -g 或索引器按名称整体排除；去掉的名称由调用方按路径处理。
+// 只看项目根、.git/info/exclude 与已遍历过的目录中的 ignore 文件
+func (m *IgnoreMatcher) BlanketIgnoreDirs(names []string) []string {
+	m.rulesFor("")
+	m.mu.Lock()
+	negations := negationRules(m.global)
+	for _, rules := range m.dirRules {
+		negations = append(negations, negationRules(rules)...)
+	}
+	m.mu.Unlock()
+
+	var out []string
+	for _, name := range names {
+		reincluded := false
+		for _, r := range negations {
+			if r.reincludes(name) {
+				reincluded = true
+				break
+			}
+		}
+		if !reincluded {
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+func negationRules(rules []ignoreRule) []ignoreRule {
+	var out []ignoreRule
+	for _, r := range rules {
+		if r.negate {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// reincludes 否定规则的最后一段能否匹配该目录名 (无法判断时按能匹配处理)
+func (r ignoreRule) reincludes(name string) bool {
+	tail := r.tail
+	if runtime.GOOS == "windows" {
+		tail, name = strings.ToLower(tail), strings.ToLower(name)
+	}
+	ok, err := path.Match(tail, name)
+	return ok || err != nil
+}
+
+func (r ignoreRule) matches(rel string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+	if r.base != "" {
+		if !strings.HasPrefix(rel, r.base+"/") {
+			return false
+		}
+		rel = rel[len(r.base)+1:]
+	}
+	return r.re.MatchString(rel)
+}
+
+// readIgnoreFile 读取 ignore 文件，文件不存在时返回 nil
+func readIgnoreFile(file, base string) []ignoreRule {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var rules []ignoreRule
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if r, ok := compileIgnoreRule(scanner.Text(), base); ok {
+			rules = append(rules, r)
+		}
+	}
+	return rules
+}
+
+// compileIgnoreRule 将一行 gitignore 规则编译为正则
+// 支持: 注释/转义、行尾空格、! 否定、结尾 / (仅目录)、开头或中间 / (锚定)、**、*、?、[...]
+func compileIgnoreRule(line, base string) (ignoreRule, bool) {
+	line = strings.TrimSuffix(line, "\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignoreRule{}, false
+	}
+	line = trimIgnoreTrailingSpaces(line)
+
+	rule := ignoreRule{base: base}
+	if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return ignoreRule{}, false
+	}
+
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+	rule.tail = path.Base(line)
+
+	var sb strings.Builder
+	if runtime.GOOS == "windows" {
+		sb.WriteString("(?i)")
+	}
+	sb.WriteString("^")
+	if !anchored {
+		sb.WriteString("(?:.*/)?")
+	}
+	sb.WriteString(globToRegexp(line))
+	sb.WriteString("$")
+
+	re, err := regexp.Compile(sb.String())
+	if err != nil {
+		return ignoreRule{}, false
+	}
+	rule.re = re
+	return rule, true
+}
+
+// trimIgnoreTrailingSpaces 去掉未转义的行尾空格
+func trimIgnoreTrailingSpaces(line string) string {
+	end := len(line)
+	for end > 0 && line[end-1] == ' ' {
+		if end >= 2 && line[end-2] == '\\' {
+			break
+		}
+		end--
+	}
+	return line[:end]
+}
+
+// globToRegexp 将 gitignore 通配符转换为正则片段
+func globToRegexp(glob string) string {
+	var sb strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch c {
+		case '*':
+			if i+1 < len(glob) && glob[i+1] == '*' {
+				atSegStart := i == 0 || glob[i-1] == '/'
+				j := i + 2
+				switch {
+				case atSegStart && j < len(glob) && glob[j] == '/':
+					// "**/" 匹配零或多级目录
+					sb.WriteString("(?:.*/)?")
+					i = j
+					continue
+				case atSegStart && j == len(glob):
+					// 结尾的 "/**" 匹配目录下的一切
+					sb.WriteString(".*")
+					i = j - 1
+					continue
+				}
+				// 其他位置的连续 * 等价于单个 *
+				for i+1 < len(glob) && glob[i+1] == '*' {
+					i++
+				}
+			}
+			sb.WriteString("[^/]*")
+		case '?':
+			sb.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				sb.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if end == 0 {
+				// "[]...]" 中的首个 ] 是字面量
+				next := strings.IndexByte(glob[i+2:], ']')
+				if next < 0 {
+					sb.WriteString(`\[`)
+					continue
+				}
+				class = glob[i+1 : i+2+next]
+				end = next + 1
+			}
+			sb.WriteString("[")
+			if strings.HasPrefix(class, "!") || strings.HasPrefix(class, "^") {
+				sb.WriteString("^/")
+				class = class[1:]
+			}
+			sb.WriteString(strings.ReplaceAll(strings.ReplaceAll(class, `\`, `\\`), "[", `\[`))
+			sb.WriteString("]")
+			i += end + 1
+		case '\\':
+			if i+1 < len(glob) {
+				i++
+				sb.WriteString(regexp.QuoteMeta(string(glob[i])))
+			}
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return sb.String()
+}
+
+// MatchPathGlob 用 gitignore 语法匹配单个相对文件路径；与遍历时一致，上级目录命中也算命中
+func MatchPathGlob(pattern, rel string) bool {
+	rule, ok := compileIgnoreRule(pattern, "")
+	if !ok || rule.negate {
+		return false
+	}
+	rel = cleanIgnorePath(rel)
+	for i := 0; i < len(rel); i++ {
+		if rel[i] == '/' && rule.matches(rel[:i], true) {
+			return true
+		}
+	}
+	return rule.matches(rel, false)
+}
+
+func cleanIgnorePath(rel string) string {
+	rel = filepath.ToSlash(rel)
+	rel = strings.Trim(path.Clean("/"+rel), "/")
+	return rel
+}
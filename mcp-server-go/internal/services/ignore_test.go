@@ -0,0 +1,200 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeTree(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for rel, content := range files {
+		p := filepath.Join(root, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestCompileIgnoreRule(t *testing.T) {
+	cases := []struct {
+		pattern string
+		path    string
+		isDir   bool
+		want    bool
+	}{
+		{"*.log", "a.log", false, true},
+		{"*.log", "deep/dir/a.log", false, true},
+		{"*.log", "a.log.txt", false, false},
+		{"/root.txt", "root.txt", false, true},
+		{"/root.txt", "sub/root.txt", false, false},
+		{"doc/frotz", "doc/frotz", false, true},
+		{"doc/frotz", "a/doc/frotz", false, false},
+		{"tmp/", "tmp", true, true},
+		{"tmp/", "tmp", false, false},
+		{"**/foo", "foo", false, true},
+		{"**/foo", "a/b/foo", false, true},
+		{"**/foo/bar", "x/foo/bar", false, true},
+		{"abc/**", "abc/x/y", false, true},
+		{"abc/**", "abc", true, false},
+		{"a/**/b", "a/b", false, true},
+		{"a/**/b", "a/x/y/b", false, true},
+		{"a/**/b", "ab", false, false},
+		{"file?.go", "file1.go", false, true},
+		{"file?.go", "file10.go", false, false},
+		{"[abc].txt", "b.txt", false, true},
+		{"[!abc].txt", "b.txt", false, false},
+		{"[!abc].txt", "d.txt", false, true},
+		{`\#hash`, "#hash", false, true},
+		{`\!bang`, "!bang", false, true},
+		{"trailing   ", "trailing", false, true},
+		{`space\ `, "space ", false, true},
+		{"*", "a/b", false, true},
+		{"a*b", "a/b", false, false},
+	}
+	for _, c := range cases {
+		rule, ok := compileIgnoreRule(c.pattern, "")
+		if !ok {
+			t.Fatalf("compileIgnoreRule(%q) failed", c.pattern)
+		}
+		if got := rule.matches(c.path, c.isDir); got != c.want {
+			t.Errorf("pattern %q on %q (dir=%v) = %v, want %v", c.pattern, c.path, c.isDir, got, c.want)
+		}
+	}
+
+	for _, skip := range []string{"", "# comment", "   ", "!", "/"} {
+		if _, ok := compileIgnoreRule(skip, ""); ok {
+			t.Errorf("compileIgnoreRule(%q) should be skipped", skip)
+		}
+	}
+}
+
+func TestIgnoreMatcherLayers(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		".gitignore":         "*.log\n!keep.log\n/generated/\nlogs/\n",
+		".git/info/exclude":  "secret.txt\n",
+		".mpmignore":         "docs/drafts/\n!vendor/\n",
+		"pkg/.gitignore":     "local.go\n!debug.log\n",
+		"pkg/sub/.mpmignore": "*.go\n!main.go\n",
+		"pkg/local.go":       "",
+		"pkg/debug.log":      "",
+		"pkg/sub/a.go":       "",
+		"pkg/sub/main.go":    "",
+		"generated/x.go":     "",
+		"vendor/lib/lib.go":  "",
+	})
+	m := NewIgnoreMatcher(root)
+
+	cases := []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{"app.log", false, true},
+		{"keep.log", false, false},      // 否定规则
+		{"pkg/debug.log", false, false}, // 子目录 .gitignore 重新纳入
+		{"pkg/other.log", false, true},
+		{"pkg/local.go", false, true},
+		{"local.go", false, false}, // 子目录规则不影响上层
+		{"pkg/sub/a.go", false, true},
+		{"pkg/sub/main.go", false, false},
+		{"generated", true, true},
+		{"generated/x.go", false, true}, // 父目录被忽略
+		{"src/generated", true, false},  // 锚定到根
+		{"a/b/logs", true, true},
+		{"a/b/logs", false, false}, // 仅匹配目录
+		{"secret.txt", false, true},
+		{"docs/drafts/1.md", false, true},
+		{"node_modules/x.js", false, true},  // 内置默认
+		{"vendor/lib/lib.go", false, false}, // .mpmignore 覆盖内置默认
+		{".git/config", false, true},
+		{".mcp-data/symbols.db", false, true},
+		{"main.go", false, false},
+	}
+	for _, c := range cases {
+		if got := m.Match(c.path, c.isDir); got != c.want {
+			t.Errorf("Match(%q, dir=%v) = %v, want %v", c.path, c.isDir, got, c.want)
+		}
+	}
+
+	if !m.MatchPath(filepath.Join(root, "app.log"), false) {
+		t.Errorf("MatchPath with absolute path should be ignored")
+	}
+	if m.MatchPath(filepath.Join(filepath.Dir(root), "app.log"), false) {
+		t.Errorf("MatchPath outside root should not be ignored")
+	}
+}
+
+func TestDetectTechStackAndConfig_UsesIgnoreMatcher(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		".gitignore":         "/third_party/\n",
+		".mpmignore":         "scratch/\n",
+		"main.go":            "package main\n",
+		"third_party/a.py":   "",
+		"src/scratch/b.rs":   "",
+		"src/third_party/ok": "",
+	})
+
+	exts, ignores := detectTechStackAndConfig(root)
+	extSet := toSet(exts)
+	if !extSet["go"] || extSet["py"] || extSet["rs"] {
+		t.Fatalf("ignored dirs should not influence detection, got %q", exts)
+	}
+
+	ignoreSet := toSet(ignores)
+	if !ignoreSet["./third_party"] || !ignoreSet["./src/scratch"] {
+		t.Fatalf("expected ignored dirs as relative paths, got %q", ignores)
+	}
+	if ignoreSet["./src/third_party"] || strings.Contains(ignores, "./node_modules") {
+		t.Fatalf("unexpected ignore entries: %q", ignores)
+	}
+}
+
+func TestReincludedDefaultDirsReachRgAndIndexer(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		".gitignore":        "!/build/\n",
+		"main.go":           "package main\n",
+		"build/a.go":        "package build\n",
+		"lib/build/b.go":    "package build\n",
+		"node_modules/x.js": "",
+	})
+
+	// 根目录 build 被重新纳入：不能按名称整体排除，仍被忽略的 lib/build 按路径传递
+	_, ignores := detectTechStackAndConfig(root)
+	ignoreSet := toSet(ignores)
+	if ignoreSet["build"] || ignoreSet["./build"] || !ignoreSet["./lib/build"] || !ignoreSet["node_modules"] {
+		t.Fatalf("unexpected --ignore-dirs: %q", ignores)
+	}
+
+	args := strings.Join(rgArgs(SearchOptions{Query: "package", RootPath: root}, NewIgnoreMatcher(root)), " ")
+	if strings.Contains(args, "!build") || !strings.Contains(args, "!node_modules") {
+		t.Fatalf("unexpected rg args: %s", args)
+	}
+}
+
+func TestNativeSearchHonorsIgnoreFiles(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		".gitignore":        "*.gen.go\n",
+		".mpmignore":        "fixtures/\n",
+		"a.go":              "needle\n",
+		"b.gen.go":          "needle\n",
+		"fixtures/c.go":     "needle\n",
+		"node_modules/d.js": "needle\n",
+	})
+
+	got, err := (&RipgrepEngine{}).nativeSearch(t.Context(), SearchOptions{Query: "needle", RootPath: root})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || !strings.HasSuffix(got[0].FilePath, "/a.go") {
+		t.Fatalf("nativeSearch = %+v, want only a.go", got)
+	}
+}
@@ -0,0 +1,540 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mcp-server-go/internal/i18n"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ============================================================================
+// 意图规则 (manager_analyze 的意图识别、禁令、警告与提示)
+// 内置默认 < 全局 (~/.mpm/intent_rules.yaml) < 项目 (.mcp-config/intent_rules.yaml)，
+// 同名意图/警告按字段覆盖，新名称追加，disabled: true 移除
+// ============================================================================
+
+// IntentRulesFile 意图规则文件名
+const IntentRulesFile = "intent_rules.yaml"
+
+const intentRulesVersion = 1
+
+// 警告生效条件
+const (
+	AlertWhenAny      = "any"
+	AlertWhenWrite    = "write"     // 非只读任务
+	AlertWhenReadOnly = "read_only" // 只读任务
+)
+
+var intentNamePattern = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+
+// IntentRule 单个意图
+type IntentRule struct {
+	Name     string   `yaml:"name" json:"name"`
+	Keywords []string `yaml:"keywords,omitempty" json:"keywords,omitempty"` // 任务描述包含任一关键词即命中 (忽略大小写)
+	Priority *int     `yaml:"priority,omitempty" json:"priority,omitempty"` // 匹配顺序，大的优先，相同按定义顺序
+	Hint     string   `yaml:"hint,omitempty" json:"hint,omitempty"`
+	Critical []string `yaml:"critical,omitempty" json:"critical,omitempty"`
+	Advisory []string `yaml:"advisory,omitempty" json:"advisory,omitempty"`
+	ReadOnly *bool    `yaml:"read_only,omitempty" json:"read_only,omitempty"` // 该意图强制追加只读禁令
+	Disabled bool     `yaml:"disabled,omitempty" json:"disabled,omitempty"`
+}
+
+// AlertRule 基于任务描述关键词的警告
+type AlertRule struct {
+	ID       string   `yaml:"id" json:"id"`
+	Keywords []string `yaml:"keywords,omitempty" json:"keywords,omitempty"`
+	When     string   `yaml:"when,omitempty" json:"when,omitempty"` // any | write | read_only，默认 any
+	Message  string   `yaml:"message,omitempty" json:"message,omitempty"`
+	Disabled bool     `yaml:"disabled,omitempty" json:"disabled,omitempty"`
+}
+
+// GuardrailCheck 禁令的可执行检查：路径命中 deny，或 allow 非空且未命中 allow 即违规
+// 模式采用 gitignore 语法 (不含 / 时匹配任意目录下的文件名)
+type GuardrailCheck struct {
+	Deny     []string `yaml:"deny,omitempty" json:"deny,omitempty"`
+	Allow    []string `yaml:"allow,omitempty" json:"allow,omitempty"`
+	Disabled bool     `yaml:"disabled,omitempty" json:"disabled,omitempty"`
+}
+
+// IntentRules 生效的意图规则集
+type IntentRules struct {
+	Version           int          `yaml:"version" json:"version"`
+	ReadOnlyIntent    string       `yaml:"read_only_intent,omitempty" json:"read_only_intent,omitempty"`       // 未命中关键词的只读任务归入此意图
+	ReadOnlyGuardrail string       `yaml:"read_only_guardrail,omitempty" json:"read_only_guardrail,omitempty"` // 只读任务的禁令
+	DefaultHint       string       `yaml:"default_hint,omitempty" json:"default_hint,omitempty"`
+	Advisory          []string     `yaml:"advisory,omitempty" json:"advisory,omitempty"` // 所有意图共用的建议
+	Intents           []IntentRule `yaml:"intents,omitempty" json:"intents,omitempty"`
+	Alerts            []AlertRule  `yaml:"alerts,omitempty" json:"alerts,omitempty"`
+
+	// GuardrailChecks 禁令代码 (冒号前的部分，如 READ_ONLY) -> 路径检查，供 check_edit 使用
+	GuardrailChecks map[string]GuardrailCheck `yaml:"guardrail_checks,omitempty" json:"guardrail_checks,omitempty"`
+
+	Sources []string `yaml:"-" json:"sources,omitempty"` // 参与合并的规则文件
+}
+
+// DefaultIntentRules 内置规则（解析失败属于编程错误，直接 panic）
+func DefaultIntentRules() *IntentRules {
+	rules, err := ParseIntentRules([]byte(defaultIntentRulesYAML))
+	if err != nil {
+		panic(i18n.T("内置意图规则无效: %v", err))
+	}
+	if err := rules.Validate(); err != nil {
+		panic(i18n.T("内置意图规则无效: %v", err))
+	}
+	rules.Sources = []string{"builtin"}
+	return rules
+}
+
+// ParseIntentRules 解析单个规则文件（拒绝未知字段，便于发现拼写错误）
+func ParseIntentRules(data []byte) (*IntentRules, error) {
+	var rules IntentRules
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&rules); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if rules.Version != 0 && rules.Version != intentRulesVersion {
+		return nil, errors.New(i18n.T("不支持的 version: %d (当前为 %d)", rules.Version, intentRulesVersion))
+	}
+	rules.Version = intentRulesVersion
+	for i := range rules.Intents {
+		rules.Intents[i].Name = strings.ToUpper(strings.TrimSpace(rules.Intents[i].Name))
+	}
+	return &rules, nil
+}
+
+// LoadIntentRules 加载并合并内置、全局、项目三层规则
+// 某一层无法解析或校验失败时跳过该层并返回警告，不影响其余层
+func LoadIntentRules(projectRoot string) (*IntentRules, []string) {
+	rules := DefaultIntentRules()
+	var warnings []string
+
+	for _, path := range configLayerPaths(projectRoot, IntentRulesFile) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				warnings = append(warnings, fmt.Sprintf("%s: %v", path, err))
+			}
+			continue
+		}
+		layer, err := ParseIntentRules(data)
+		if err == nil {
+			err = layer.validateLayer()
+		}
+		if err != nil {
+			warnings = append(warnings, i18n.T("%s: %v (已忽略此文件)", path, err))
+			continue
+		}
+		merged := rules.merge(layer)
+		if err := merged.Validate(); err != nil {
+			warnings = append(warnings, i18n.T("%s: 合并后无效: %v (已忽略此文件)", path, err))
+			continue
+		}
+		merged.Sources = append(append([]string{}, rules.Sources...), path)
+		rules = merged
+	}
+	return rules, warnings
+}
+
+// validateLayer 单层文件的校验：允许只覆盖部分字段
+func (r *IntentRules) validateLayer() error {
+	var errs []string
+	seen := make(map[string]bool)
+	for i, in := range r.Intents {
+		switch {
+		case in.Name == "":
+			errs = append(errs, i18n.T("intents[%d]: 缺少 name", i))
+		case !intentNamePattern.MatchString(in.Name):
+			errs = append(errs, i18n.T("intents[%d]: name %q 只能包含大写字母、数字和下划线", i, in.Name))
+		case seen[in.Name]:
+			errs = append(errs, i18n.T("intents[%d]: 重复的意图 %s", i, in.Name))
+		}
+		seen[in.Name] = true
+		for _, k := range in.Keywords {
+			if strings.TrimSpace(k) == "" {
+				errs = append(errs, i18n.T("intent %s: 关键词不能为空", in.Name))
+				break
+			}
+		}
+	}
+	seenAlert := make(map[string]bool)
+	for i, a := range r.Alerts {
+		switch {
+		case a.ID == "":
+			errs = append(errs, i18n.T("alerts[%d]: 缺少 id", i))
+		case seenAlert[a.ID]:
+			errs = append(errs, i18n.T("alerts[%d]: 重复的警告 %s", i, a.ID))
+		}
+		seenAlert[a.ID] = true
+		if a.When != "" && a.When != AlertWhenAny && a.When != AlertWhenWrite && a.When != AlertWhenReadOnly {
+			errs = append(errs, i18n.T("alert %s: when 只能是 any/write/read_only", a.ID))
+		}
+	}
+	for code, c := range r.GuardrailChecks {
+		if !intentNamePattern.MatchString(code) {
+			errs = append(errs, i18n.T("guardrail_checks: 代码 %q 只能包含大写字母、数字和下划线", code))
+		}
+		if !c.Disabled && len(c.Deny)+len(c.Allow) == 0 {
+			errs = append(errs, i18n.T("guardrail_checks.%s: deny 与 allow 至少填写一个", code))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// Validate 校验合并后的完整规则集
+func (r *IntentRules) Validate() error {
+	if err := r.validateLayer(); err != nil {
+		return err
+	}
+	var errs []string
+	for _, a := range r.Alerts {
+		if len(a.Keywords) == 0 {
+			errs = append(errs, i18n.T("alert %s: 缺少 keywords", a.ID))
+		}
+		if strings.TrimSpace(a.Message) == "" {
+			errs = append(errs, i18n.T("alert %s: 缺少 message", a.ID))
+		}
+	}
+	if r.ReadOnlyIntent != "" && r.Intent(r.ReadOnlyIntent) == nil {
+		errs = append(errs, i18n.T("read_only_intent %s 未定义", r.ReadOnlyIntent))
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// merge 返回 base 被 layer 覆盖后的新规则集（不修改 base）
+func (r *IntentRules) merge(layer *IntentRules) *IntentRules {
+	out := &IntentRules{
+		Version:           intentRulesVersion,
+		ReadOnlyIntent:    pick(layer.ReadOnlyIntent, r.ReadOnlyIntent),
+		ReadOnlyGuardrail: pick(layer.ReadOnlyGuardrail, r.ReadOnlyGuardrail),
+		DefaultHint:       pick(layer.DefaultHint, r.DefaultHint),
+		Advisory:          r.Advisory,
+	}
+	out.ReadOnlyIntent = strings.ToUpper(out.ReadOnlyIntent)
+	if layer.Advisory != nil {
+		out.Advisory = layer.Advisory
+	}
+
+	intents := append([]IntentRule{}, r.Intents...)
+	for _, in := range layer.Intents {
+		idx := -1
+		for i := range intents {
+			if intents[i].Name == in.Name {
+				idx = i
+				break
+			}
+		}
+		switch {
+		case in.Disabled && idx >= 0:
+			intents = append(intents[:idx], intents[idx+1:]...)
+		case in.Disabled:
+		case idx < 0:
+			intents = append(intents, in)
+		default:
+			intents[idx] = overrideIntent(intents[idx], in)
+		}
+	}
+	out.Intents = intents
+
+	alerts := append([]AlertRule{}, r.Alerts...)
+	for _, a := range layer.Alerts {
+		idx := -1
+		for i := range alerts {
+			if alerts[i].ID == a.ID {
+				idx = i
+				break
+			}
+		}
+		switch {
+		case a.Disabled && idx >= 0:
+			alerts = append(alerts[:idx], alerts[idx+1:]...)
+		case a.Disabled:
+		case idx < 0:
+			alerts = append(alerts, a)
+		default:
+			cur := alerts[idx]
+			if a.Keywords != nil {
+				cur.Keywords = a.Keywords
+			}
+			cur.When = pick(a.When, cur.When)
+			cur.Message = pick(a.Message, cur.Message)
+			alerts[idx] = cur
+		}
+	}
+	out.Alerts = alerts
+
+	out.GuardrailChecks = make(map[string]GuardrailCheck, len(r.GuardrailChecks))
+	for code, c := range r.GuardrailChecks {
+		out.GuardrailChecks[code] = c
+	}
+	for code, c := range layer.GuardrailChecks {
+		if c.Disabled {
+			delete(out.GuardrailChecks, code)
+		} else {
+			out.GuardrailChecks[code] = c
+		}
+	}
+	return out
+}
+
+func overrideIntent(cur, in IntentRule) IntentRule {
+	if in.Keywords != nil {
+		cur.Keywords = in.Keywords
+	}
+	if in.Priority != nil {
+		cur.Priority = in.Priority
+	}
+	cur.Hint = pick(in.Hint, cur.Hint)
+	if in.Critical != nil {
+		cur.Critical = in.Critical
+	}
+	if in.Advisory != nil {
+		cur.Advisory = in.Advisory
+	}
+	if in.ReadOnly != nil {
+		cur.ReadOnly = in.ReadOnly
+	}
+	return cur
+}
+
+func pick(override, base string) string {
+	if strings.TrimSpace(override) != "" {
+		return override
+	}
+	return base
+}
+
+// Intent 按名称查找意图（忽略大小写）
+func (r *IntentRules) Intent(name string) *IntentRule {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	for i := range r.Intents {
+		if r.Intents[i].Name == name {
+			return &r.Intents[i]
+		}
+	}
+	return nil
+}
+
+// IntentNames 所有意图名称（定义顺序）
+func (r *IntentRules) IntentNames() []string {
+	names := make([]string, 0, len(r.Intents))
+	for _, in := range r.Intents {
+		names = append(names, in.Name)
+	}
+	return names
+}
+
+// DetermineIntent 显式意图有效时直接采用，否则按关键词匹配；只读任务兜底为 read_only_intent
+func (r *IntentRules) DetermineIntent(desc, explicitIntent string, readOnly bool) string {
+	if explicitIntent != "" {
+		if in := r.Intent(explicitIntent); in != nil {
+			return in.Name
+		}
+	}
+
+	ordered := make([]IntentRule, len(r.Intents))
+	copy(ordered, r.Intents)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return intentPriority(ordered[i]) > intentPriority(ordered[j])
+	})
+
+	descLower := strings.ToLower(desc)
+	for _, in := range ordered {
+		if containsAnyKeyword(descLower, in.Keywords) {
+			return in.Name
+		}
+	}
+
+	if readOnly {
+		return r.ReadOnlyIntent
+	}
+	return ""
+}
+
+func intentPriority(in IntentRule) int {
+	if in.Priority == nil {
+		return 0
+	}
+	return *in.Priority
+}
+
+// Guardrails 生成禁令 (critical) 与建议 (advisory)，去重并保持顺序
+func (r *IntentRules) Guardrails(intent string, readOnly bool) (critical, advisory []string) {
+	critical = []string{}
+	advisory = append([]string{}, r.Advisory...)
+
+	in := r.Intent(intent)
+	if readOnly || (in != nil && in.ReadOnly != nil && *in.ReadOnly) {
+		if r.ReadOnlyGuardrail != "" {
+			critical = append(critical, r.ReadOnlyGuardrail)
+		}
+	}
+	if in != nil {
+		critical = append(critical, in.Critical...)
+		advisory = append(advisory, in.Advisory...)
+	}
+	return dedupeStrings(critical), dedupeStrings(advisory)
+}
+
+// MatchAlerts 按任务描述关键词生成警告
+func (r *IntentRules) MatchAlerts(desc string, readOnly bool) []string {
+	var alerts []string
+	descLower := strings.ToLower(desc)
+	for _, a := range r.Alerts {
+		switch a.When {
+		case AlertWhenWrite:
+			if readOnly {
+				continue
+			}
+		case AlertWhenReadOnly:
+			if !readOnly {
+				continue
+			}
+		}
+		if containsAnyKeyword(descLower, a.Keywords) {
+			alerts = append(alerts, a.Message)
+		}
+	}
+	return alerts
+}
+
+// GuardrailCode 禁令代码：冒号前的大写标识 ("MD_ONLY: 仅允许..." -> "MD_ONLY")
+func GuardrailCode(guardrail string) string {
+	code := guardrail
+	if i := strings.IndexAny(code, ":："); i >= 0 {
+		code = code[:i]
+	}
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// GuardrailCheckFor 查找禁令对应的路径检查
+func (r *IntentRules) GuardrailCheckFor(guardrail string) (GuardrailCheck, bool) {
+	c, ok := r.GuardrailChecks[GuardrailCode(guardrail)]
+	return c, ok && !c.Disabled
+}
+
+// Violates 判断相对路径是否违反该检查
+func (c GuardrailCheck) Violates(rel string) bool {
+	for _, p := range c.Deny {
+		if MatchPathGlob(p, rel) {
+			return true
+		}
+	}
+	if len(c.Allow) == 0 {
+		return false
+	}
+	for _, p := range c.Allow {
+		if MatchPathGlob(p, rel) {
+			return false
+		}
+	}
+	return true
+}
+
+// Hint 意图提示，未定义时返回 default_hint
+func (r *IntentRules) Hint(intent string) string {
+	if in := r.Intent(intent); in != nil && in.Hint != "" {
+		return in.Hint
+	}
+	return r.DefaultHint
+}
+
+func containsAnyKeyword(textLower string, keywords []string) bool {
+	for _, k := range keywords {
+		if k = strings.ToLower(strings.TrimSpace(k)); k != "" && strings.Contains(textLower, k) {
+			return true
+		}
+	}
+	return false
+}
+
+func dedupeStrings(items []string) []string {
+	seen := make(map[string]bool, len(items))
+	out := items[:0]
+	for _, s := range items {
+		if !seen[s] {
+			seen[s] = true
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// defaultIntentRulesYAML 内置规则，也是自定义规则文件的参考模板
+const defaultIntentRulesYAML = `version: 1
+read_only_intent: RESEARCH
+read_only_guardrail: "READ_ONLY: 严禁修改任何文件"
+default_hint: "📋 自行决定最佳方案"
+advisory:
+  - "最小变更，不做大爆炸重构"
+
+intents:
+  - name: DEBUG
+    keywords: [debug, fix, 修复, 报错]
+    hint: "🔧 定位根因 → 验证修复。可构建/复用项目专用debug环境，可搜索"
+    critical:
+      - "VERIFY_FIRST: 修改前必须先定位根因"
+      - "NO_BLIND_REWRITE: 禁止盲目重写整个文件"
+  - name: REFACTOR
+    keywords: [refactor, 重构]
+    hint: "♻️ 小步快跑，每步可验证。重构前先跑通测试。分析代码语义"
+    advisory:
+      - "INCREMENTAL: 小步快跑，每步可验证"
+      - "VERIFY_EACH_STEP: 每次修改后运行测试确认未破坏功能"
+  - name: RESEARCH
+    keywords: [analy, 分析, 调研, research]
+    hint: "🔍 可退一步全局思考，可复盘，可用顺序思考工具"
+    read_only: true
+  - name: DESIGN
+    keywords: [design, 设计, 架构]
+    hint: "📐 先讨论方案，有必要再输出设计文档。不动代码"
+    critical:
+      - "NO_CODE_EDIT: 严禁编辑业务代码"
+      - "MD_ONLY: 仅允许创建 .md 文档"
+  - name: DEVELOP
+    hint: "🚀 明确修改点 → 最小变更。优先找成熟库，可搜索"
+  - name: PERFORMANCE
+    hint: "⚡ 先执行性能分析定位瓶颈 → 针对性优化 → 基准测试验证提升"
+    critical:
+      - "PROFILE_FIRST: 修改前必须先执行性能分析定位瓶颈"
+      - "MEASURE_AFTER: 优化后必须用基准测试验证性能提升"
+  - name: REFLECT
+    hint: "🪞 系统性回顾历史决策。可用 system_recall 检索记忆，open_timeline 查看演进，基于事实得出结论"
+    read_only: true
+    critical:
+      - "EVIDENCE_BASED: 所有结论必须基于 memo/system_recall 的历史证据"
+
+guardrail_checks:
+  READ_ONLY:
+    deny: ["**"]
+  NO_CODE_EDIT:
+    deny: ["*.go", "*.py", "*.js", "*.jsx", "*.ts", "*.tsx", "*.rs", "*.java", "*.kt", "*.c", "*.h", "*.cpp", "*.hpp", "*.cs", "*.rb", "*.php", "*.swift", "*.vue", "*.svelte"]
+  MD_ONLY:
+    allow: ["*.md"]
+
+alerts:
+  - id: modification
+    keywords: [修改, update, change]
+    when: write
+    message: "Modification detected. Call code_impact(symbol_name=...) first."
+  - id: migration
+    keywords: [migrate, 迁移, 升级]
+    message: "🔒 **约束建议**: 技术栈变更。建议添加约束规则,禁止使用旧技术栈的API或模式。"
+  - id: new_feature
+    keywords: [开发, 新增, 添加, implement, create, feature, module]
+    when: write
+    message: "[技术调研提醒]: 开发新组件前，请先执行技术调研。使用 search_web 搜索现有库/方案，避免重复造轮子。"
+`
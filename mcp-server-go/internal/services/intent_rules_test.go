@@ -0,0 +1,219 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDefaultIntentRulesDetermineIntent(t *testing.T) {
+	rules := DefaultIntentRules()
+	cases := []struct {
+		name     string
+		desc     string
+		explicit string
+		readOnly bool
+		want     string
+	}{
+		{"explicit wins", "修复登录报错", "refactor", false, "REFACTOR"},
+		{"unknown explicit falls back to keywords", "修复登录报错", "SECURITY", false, "DEBUG"},
+		{"english keyword", "Fix the crash on startup", "", false, "DEBUG"},
+		{"debug before refactor", "重构并修复缓存", "", false, "DEBUG"},
+		{"refactor", "重构 session 模块", "", false, "REFACTOR"},
+		{"research", "分析调用链", "", false, "RESEARCH"},
+		{"design", "设计新的插件架构", "", false, "DESIGN"},
+		{"read only fallback", "看看这个项目", "", true, "RESEARCH"},
+		{"no match", "看看这个项目", "", false, ""},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := rules.DetermineIntent(c.desc, c.explicit, c.readOnly); got != c.want {
+				t.Errorf("DetermineIntent(%q, %q, %v) = %q, want %q", c.desc, c.explicit, c.readOnly, got, c.want)
+			}
+		})
+	}
+}
+
+func TestDefaultIntentRulesGuardrails(t *testing.T) {
+	rules := DefaultIntentRules()
+	cases := []struct {
+		intent       string
+		readOnly     bool
+		wantCritical []string
+		wantAdvisory int
+	}{
+		{"DEBUG", false, []string{"VERIFY_FIRST: 修改前必须先定位根因", "NO_BLIND_REWRITE: 禁止盲目重写整个文件"}, 1},
+		{"DEBUG", true, []string{"READ_ONLY: 严禁修改任何文件", "VERIFY_FIRST: 修改前必须先定位根因", "NO_BLIND_REWRITE: 禁止盲目重写整个文件"}, 1},
+		{"RESEARCH", false, []string{"READ_ONLY: 严禁修改任何文件"}, 1},
+		{"RESEARCH", true, []string{"READ_ONLY: 严禁修改任何文件"}, 1},
+		{"REFLECT", true, []string{"READ_ONLY: 严禁修改任何文件", "EVIDENCE_BASED: 所有结论必须基于 memo/system_recall 的历史证据"}, 1},
+		{"REFACTOR", false, []string{}, 3},
+		{"", false, []string{}, 1},
+	}
+	for _, c := range cases {
+		critical, advisory := rules.Guardrails(c.intent, c.readOnly)
+		if !reflect.DeepEqual(critical, c.wantCritical) {
+			t.Errorf("Guardrails(%q, %v) critical = %q, want %q", c.intent, c.readOnly, critical, c.wantCritical)
+		}
+		if len(advisory) != c.wantAdvisory {
+			t.Errorf("Guardrails(%q, %v) advisory = %q, want %d items", c.intent, c.readOnly, advisory, c.wantAdvisory)
+		}
+	}
+}
+
+func TestDefaultIntentRulesAlerts(t *testing.T) {
+	rules := DefaultIntentRules()
+	cases := []struct {
+		desc     string
+		readOnly bool
+		want     []string // 警告消息的前缀
+	}{
+		{"修改登录逻辑", false, []string{"Modification detected"}},
+		{"修改登录逻辑", true, nil},
+		{"把数据库迁移到 PostgreSQL", true, []string{"🔒"}},
+		{"Implement a new export feature", false, []string{"[技术调研提醒]"}},
+		{"Update module loader", false, []string{"Modification detected", "[技术调研提醒]"}},
+		{"阅读代码", false, nil},
+	}
+	for _, c := range cases {
+		got := rules.MatchAlerts(c.desc, c.readOnly)
+		if len(got) != len(c.want) {
+			t.Errorf("MatchAlerts(%q, %v) = %q, want %d alerts", c.desc, c.readOnly, got, len(c.want))
+			continue
+		}
+		for i := range got {
+			if !strings.HasPrefix(got[i], c.want[i]) {
+				t.Errorf("MatchAlerts(%q)[%d] = %q, want prefix %q", c.desc, i, got[i], c.want[i])
+			}
+		}
+	}
+}
+
+func TestIntentRulesHint(t *testing.T) {
+	rules := DefaultIntentRules()
+	cases := map[string]string{
+		"DEBUG":   "🔧",
+		"develop": "🚀",
+		"UNKNOWN": "📋",
+		"":        "📋",
+	}
+	for intent, prefix := range cases {
+		if got := rules.Hint(intent); !strings.HasPrefix(got, prefix) {
+			t.Errorf("Hint(%q) = %q, want prefix %q", intent, got, prefix)
+		}
+	}
+}
+
+func TestIntentRulesValidation(t *testing.T) {
+	cases := []struct {
+		name    string
+		yaml    string
+		wantErr string // 为空表示应当通过
+	}{
+		{"empty file", "", ""},
+		{"comment only", "# nothing yet\n", ""},
+		{"partial override", "intents:\n  - name: debug\n    hint: 先复现\n", ""},
+		{"unknown field", "intents:\n  - name: DEBUG\n    keyword: [x]\n", "keyword"},
+		{"bad version", "version: 2\n", "version"},
+		{"missing name", "intents:\n  - keywords: [x]\n", "缺少 name"},
+		{"bad name", "intents:\n  - name: sec-ops\n", "只能包含"},
+		{"duplicate intent", "intents:\n  - name: A\n  - name: a\n", "重复的意图"},
+		{"blank keyword", "intents:\n  - name: A\n    keywords: [\" \"]\n", "关键词不能为空"},
+		{"missing alert id", "alerts:\n  - message: hi\n", "缺少 id"},
+		{"bad when", "alerts:\n  - id: x\n    when: always\n", "when"},
+		{"guardrail check", "guardrail_checks:\n  NO_SQL:\n    deny: [\"*.sql\"]\n", ""},
+		{"bad guardrail code", "guardrail_checks:\n  no-sql:\n    deny: [x]\n", "只能包含"},
+		{"empty guardrail check", "guardrail_checks:\n  NO_SQL: {}\n", "至少填写"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rules, err := ParseIntentRules([]byte(c.yaml))
+			if err == nil {
+				err = rules.validateLayer()
+			}
+			switch {
+			case c.wantErr == "" && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case c.wantErr != "" && (err == nil || !strings.Contains(err.Error(), c.wantErr)):
+				t.Fatalf("error = %v, want containing %q", err, c.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadIntentRulesLayers(t *testing.T) {
+	global := t.TempDir()
+	t.Setenv("MPM_HOME", global)
+	root := t.TempDir()
+	_ = os.MkdirAll(ProjectConfigDir(root), 0755)
+
+	writeRules := func(path, content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeRules(filepath.Join(global, IntentRulesFile), `
+intents:
+  - name: SECURITY
+    keywords: [漏洞, cve, xss]
+    priority: 10
+    hint: "🛡️ 先确认攻击面"
+    critical: ["NO_SECRET_LOG: 禁止在日志中输出凭据"]
+`)
+	writeRules(filepath.Join(ProjectConfigDir(root), IntentRulesFile), `
+intents:
+  - name: MIGRATION
+    keywords: [迁移]
+    critical: ["DUAL_WRITE: 迁移期间保持双写"]
+  - name: DESIGN
+    disabled: true
+  - name: DEBUG
+    keywords: [排查]
+alerts:
+  - id: migration
+    disabled: true
+  - id: schema
+    keywords: [schema]
+    message: "改 schema 前先写迁移脚本"
+`)
+
+	rules, warnings := LoadIntentRules(root)
+	if len(warnings) != 0 {
+		t.Fatalf("warnings = %v", warnings)
+	}
+	if len(rules.Sources) != 3 {
+		t.Errorf("Sources = %v", rules.Sources)
+	}
+
+	cases := []struct{ desc, want string }{
+		{"修复 XSS 漏洞", "SECURITY"}, // priority 高于 DEBUG
+		{"排查内存泄漏", "DEBUG"},
+		{"修复登录", ""}, // DEBUG 关键词已被项目层替换
+		{"数据库迁移", "MIGRATION"},
+		{"设计插件架构", ""}, // DESIGN 已禁用
+	}
+	for _, c := range cases {
+		if got := rules.DetermineIntent(c.desc, "", false); got != c.want {
+			t.Errorf("DetermineIntent(%q) = %q, want %q", c.desc, got, c.want)
+		}
+	}
+	if critical, _ := rules.Guardrails("MIGRATION", false); len(critical) != 1 || !strings.HasPrefix(critical[0], "DUAL_WRITE") {
+		t.Errorf("MIGRATION critical = %v", critical)
+	}
+	if alerts := rules.MatchAlerts("迁移 schema", true); len(alerts) != 1 || !strings.Contains(alerts[0], "迁移脚本") {
+		t.Errorf("alerts = %v", alerts)
+	}
+
+	// 项目层无效：跳过该层并给出警告，全局层仍生效
+	writeRules(filepath.Join(ProjectConfigDir(root), IntentRulesFile), "read_only_intent: NOPE\n")
+	rules, warnings = LoadIntentRules(root)
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "NOPE") {
+		t.Fatalf("warnings = %v", warnings)
+	}
+	if rules.Intent("SECURITY") == nil || rules.ReadOnlyIntent != "RESEARCH" {
+		t.Errorf("global layer should still apply: %+v", rules.Sources)
+	}
+}
@@ -0,0 +1,801 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ============================================================================
+// 函数级复杂度指标 (词法分析，不依赖 AST 引擎，跨语言通用)
+// ============================================================================
+
+// SymbolMetrics 单个符号的复杂度指标
+type SymbolMetrics struct {
+	Cyclomatic int `json:"cyclomatic"`  // 圈复杂度 (1 + 决策点)
+	Cognitive  int `json:"cognitive"`   // 认知复杂度 (嵌套加权)
+	LineCount  int `json:"line_count"`  // 物理行数
+	ParamCount int `json:"param_count"` // 参数个数
+	MaxNesting int `json:"max_nesting"` // 最大控制流嵌套深度
+}
+
+// Score 综合复杂度分数 (0-100)，与 map/analyze 的 High>=50 / Med>=20 阈值对齐
+func (m SymbolMetrics) Score() float64 {
+	score := float64(m.Cyclomatic)*2 + float64(m.Cognitive) + float64(m.LineCount)/25
+	if m.MaxNesting > 2 {
+		score += float64(m.MaxNesting-2) * 5
+	}
+	if m.ParamCount > 5 {
+		score += float64(m.ParamCount - 5)
+	}
+	if score > 100 {
+		score = 100
+	}
+	return score
+}
+
+// Reasons 复杂度成因说明
+func (m SymbolMetrics) Reasons() []string {
+	var reasons []string
+	if m.Cyclomatic > 10 {
+		reasons = append(reasons, fmt.Sprintf("High Cyclomatic (CC: %d)", m.Cyclomatic))
+	}
+	if m.Cognitive > 15 {
+		reasons = append(reasons, fmt.Sprintf("High Cognitive (%d)", m.Cognitive))
+	}
+	if m.MaxNesting > 3 {
+		reasons = append(reasons, fmt.Sprintf("Deep Nesting (depth %d)", m.MaxNesting))
+	}
+	if m.LineCount > 100 {
+		reasons = append(reasons, fmt.Sprintf("Long Body (%d lines)", m.LineCount))
+	}
+	if m.ParamCount > 5 {
+		reasons = append(reasons, fmt.Sprintf("Many Params (%d)", m.ParamCount))
+	}
+	return reasons
+}
+
+// metricsLevel 复杂度分级 (与 Rust analyze 的 complexity_level 取值一致)
+func metricsLevel(score float64) string {
+	switch {
+	case score < 20:
+		return "Simple"
+	case score < 50:
+		return "Medium"
+	case score < 80:
+		return "High"
+	default:
+		return "Extreme"
+	}
+}
+
+// escalateRisk 函数本身复杂度高时，修改风险上调一级
+// 调用方数量决定的风险等级只反映影响面，难以改对的函数同样危险
+func escalateRisk(riskLevel string, score float64) string {
+	if score < 50 {
+		return riskLevel
+	}
+	switch riskLevel {
+	case "low":
+		return "medium"
+	case "medium":
+		return "high"
+	}
+	return riskLevel
+}
+
+// metricsLanguage 根据扩展名归类语言族
+func metricsLanguage(path string) string {
+	switch strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".") {
+	case "py", "pyi":
+		return "python"
+	case "go":
+		return "go"
+	case "rs":
+		return "rust"
+	case "js", "jsx", "ts", "tsx", "mjs", "cjs", "vue", "svelte":
+		return "javascript"
+	case "java":
+		return "java"
+	default:
+		return "c"
+	}
+}
+
+// ComputeMetrics 计算一段函数源码的复杂度指标
+// lines 为函数定义的完整源码行（第一行为定义头），name 用于定位参数列表
+func ComputeMetrics(lang string, name string, lines []string) SymbolMetrics {
+	m := SymbolMetrics{LineCount: len(lines)}
+	if len(lines) == 0 {
+		return m
+	}
+
+	code := stripCommentsAndStrings(strings.Join(lines, "\n"), lang)
+	m.ParamCount = countParams(code, name, lang)
+
+	if lang == "python" {
+		analyzeIndentBlocks(strings.Split(code, "\n"), &m)
+	} else {
+		analyzeBraceBlocks(tokenize(code), lang, &m)
+	}
+	return m
+}
+
+// stripCommentsAndStrings 去除注释与字符串内容，保留换行以维持行结构
+func stripCommentsAndStrings(src string, lang string) string {
+	var out strings.Builder
+	out.Grow(len(src))
+	n := len(src)
+
+	for i := 0; i < n; i++ {
+		c := src[i]
+
+		// 行注释
+		if (lang == "python" && c == '#') || (lang != "python" && c == '/' && i+1 < n && src[i+1] == '/') {
+			for i < n && src[i] != '\n' {
+				i++
+			}
+			if i < n {
+				out.WriteByte('\n')
+			}
+			continue
+		}
+
+		// 块注释
+		if lang != "python" && c == '/' && i+1 < n && src[i+1] == '*' {
+			i += 2
+			for i < n && !(src[i] == '*' && i+1 < n && src[i+1] == '/') {
+				if src[i] == '\n' {
+					out.WriteByte('\n')
+				}
+				i++
+			}
+			i++
+			out.WriteByte(' ')
+			continue
+		}
+
+		// Python 三引号字符串
+		if lang == "python" && (c == '"' || c == '\'') && i+2 < n && src[i+1] == c && src[i+2] == c {
+			i += 3
+			for i < n && !(src[i] == c && i+2 < n && src[i+1] == c && src[i+2] == c) {
+				if src[i] == '\n' {
+					out.WriteByte('\n')
+				}
+				i++
+			}
+			i += 2
+			out.WriteString(`""`)
+			continue
+		}
+
+		// Rust 生命周期 ('a) 不是字符字面量
+		if c == '\'' && lang == "rust" && !(i+2 < n && (src[i+1] == '\\' || src[i+2] == '\'')) {
+			out.WriteByte(c)
+			continue
+		}
+
+		if c == '"' || c == '\'' || (c == '`' && (lang == "go" || lang == "javascript")) {
+			quote := c
+			i++
+			for i < n && src[i] != quote {
+				if src[i] == '\\' && quote != '`' {
+					i++
+				} else if src[i] == '\n' {
+					if quote != '`' {
+						break // 未闭合的单行字符串
+					}
+					out.WriteByte('\n')
+				}
+				i++
+			}
+			out.WriteByte('"')
+			out.WriteByte('"')
+			if i < n && src[i] == '\n' {
+				out.WriteByte('\n')
+			}
+			continue
+		}
+
+		out.WriteByte(c)
+	}
+	return out.String()
+}
+
+// tokenize 将代码切分为标识符与关心的运算符
+func tokenize(code string) []string {
+	var tokens []string
+	n := len(code)
+	for i := 0; i < n; i++ {
+		c := code[i]
+		switch {
+		case isIdentStart(c):
+			j := i + 1
+			for j < n && isIdentPart(code[j]) {
+				j++
+			}
+			tokens = append(tokens, code[i:j])
+			i = j - 1
+		case c == '&' && i+1 < n && code[i+1] == '&',
+			c == '|' && i+1 < n && code[i+1] == '|',
+			c == '=' && i+1 < n && code[i+1] == '>',
+			c == '?' && i+1 < n && (code[i+1] == '.' || code[i+1] == '?'):
+			tokens = append(tokens, code[i:i+2])
+			i++
+		case strings.IndexByte("{}();?:,<>", c) >= 0:
+			tokens = append(tokens, string(c))
+		}
+	}
+	return tokens
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c >= 0x80
+}
+
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || (c >= '0' && c <= '9')
+}
+
+// analyzeBraceBlocks 花括号语言族 (C/Go/Java/JS/Rust) 的复杂度分析
+func analyzeBraceBlocks(tokens []string, lang string, m *SymbolMetrics) {
+	m.Cyclomatic = 1
+	nesting := 0
+	parenDepth := 0
+	pendingControl := false
+	var braces []bool // true = 控制流块，出栈时嵌套 -1
+	lastBoolOp := ""
+	// Go/Rust 的 if/for 头部可含分号，且必带花括号，分号不取消待定控制块
+	semicolonEndsControl := lang != "go" && lang != "rust"
+
+	enterControl := func() {
+		m.Cognitive += 1 + nesting
+		pendingControl = true
+		lastBoolOp = ""
+	}
+
+	for i, tok := range tokens {
+		prev, next := "", ""
+		if i > 0 {
+			prev = tokens[i-1]
+		}
+		if i+1 < len(tokens) {
+			next = tokens[i+1]
+		}
+
+		switch tok {
+		case "if":
+			m.Cyclomatic++
+			if prev == "else" {
+				// else if：只计 1 次，不叠加嵌套
+				m.Cognitive++
+				pendingControl = true
+				lastBoolOp = ""
+			} else {
+				enterControl()
+			}
+		case "else":
+			if next != "if" {
+				m.Cognitive++
+			}
+			pendingControl = true
+		case "for", "while":
+			m.Cyclomatic++
+			enterControl()
+		case "catch":
+			m.Cyclomatic++
+			enterControl()
+		case "switch", "select":
+			enterControl()
+		case "match", "loop":
+			if lang == "rust" {
+				enterControl()
+			}
+		case "do":
+			pendingControl = true
+		case "case":
+			m.Cyclomatic++
+		case "goto":
+			m.Cognitive++
+		case "&&", "||":
+			m.Cyclomatic++
+			if tok != lastBoolOp {
+				m.Cognitive++
+			}
+			lastBoolOp = tok
+		case "?":
+			// 三元表达式 (排除 TS 可选参数 x?: T / x?)
+			if lang == "go" || lang == "rust" || next == ":" || next == ")" || next == "," {
+				continue
+			}
+			m.Cyclomatic++
+			m.Cognitive += 1 + nesting
+		case "=>":
+			// Rust match 分支 (通配 _ 不计)
+			if lang == "rust" && prev != "_" {
+				m.Cyclomatic++
+			}
+		case "(":
+			parenDepth++
+		case ")":
+			if parenDepth > 0 {
+				parenDepth--
+			}
+		case ";":
+			lastBoolOp = ""
+			if semicolonEndsControl && parenDepth == 0 {
+				pendingControl = false
+			}
+		case "{":
+			lastBoolOp = ""
+			if pendingControl {
+				pendingControl = false
+				nesting++
+				if nesting > m.MaxNesting {
+					m.MaxNesting = nesting
+				}
+				braces = append(braces, true)
+			} else {
+				braces = append(braces, false)
+			}
+		case "}":
+			lastBoolOp = ""
+			if len(braces) > 0 {
+				if braces[len(braces)-1] {
+					nesting--
+				}
+				braces = braces[:len(braces)-1]
+			}
+		}
+	}
+}
+
+// pyFrame Python 缩进块
+type pyFrame struct {
+	indent  int
+	control bool
+}
+
+// analyzeIndentBlocks Python 基于缩进的复杂度分析
+func analyzeIndentBlocks(lines []string, m *SymbolMetrics) {
+	m.Cyclomatic = 1
+	var stack []pyFrame
+	parenDepth := 0
+	continued := false
+
+	for idx, raw := range lines {
+		trimmed := strings.TrimSpace(raw)
+		if trimmed == "" {
+			continue
+		}
+		tokens := tokenize(trimmed)
+		isContinuation := parenDepth > 0 || continued
+		for _, t := range tokens {
+			switch t {
+			case "(":
+				parenDepth++
+			case ")":
+				if parenDepth > 0 {
+					parenDepth--
+				}
+			}
+		}
+		continued = strings.HasSuffix(trimmed, "\\")
+
+		// 定义头只统计参数，不计入控制流
+		if idx == 0 {
+			continue
+		}
+
+		start := 0
+		if !isContinuation {
+			indent := indentWidth(raw)
+			for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+				stack = stack[:len(stack)-1]
+			}
+			nesting := controlDepth(stack)
+
+			first := ""
+			if len(tokens) > 0 {
+				first = tokens[0]
+				if first == "async" && len(tokens) > 1 {
+					first = tokens[1]
+					start = 1
+				}
+			}
+
+			opensBlock := strings.HasSuffix(trimmed, ":")
+			push := func(control bool) {
+				if opensBlock {
+					stack = append(stack, pyFrame{indent: indent, control: control})
+				}
+			}
+
+			switch first {
+			case "if", "for", "while", "except":
+				m.Cyclomatic++
+				m.Cognitive += 1 + nesting
+				push(true)
+				start++
+			case "elif":
+				m.Cyclomatic++
+				m.Cognitive++
+				push(true)
+				start++
+			case "else":
+				m.Cognitive++
+				push(true)
+				start++
+			case "match":
+				m.Cognitive += 1 + nesting
+				push(true)
+				start++
+			case "case":
+				m.Cyclomatic++
+				push(false)
+				start++
+			case "def", "class":
+				// 嵌套函数/类增加嵌套层级
+				push(true)
+				start = len(tokens)
+			default:
+				push(false)
+			}
+
+			if d := controlDepth(stack); d > m.MaxNesting {
+				m.MaxNesting = d
+			}
+		}
+
+		// 行内运算符：布尔序列、三元表达式与推导式
+		nesting := controlDepth(stack)
+		lastBoolOp := ""
+		for _, t := range tokens[min(start, len(tokens)):] {
+			switch t {
+			case "and", "or":
+				m.Cyclomatic++
+				if t != lastBoolOp {
+					m.Cognitive++
+				}
+				lastBoolOp = t
+			case "if":
+				m.Cyclomatic++
+				m.Cognitive += 1 + nesting
+			case "for":
+				m.Cyclomatic++
+			}
+		}
+	}
+}
+
+func indentWidth(line string) int {
+	width := 0
+	for _, c := range line {
+		switch c {
+		case ' ':
+			width++
+		case '\t':
+			width += 4
+		default:
+			return width
+		}
+	}
+	return width
+}
+
+func controlDepth(stack []pyFrame) int {
+	depth := 0
+	for _, f := range stack {
+		if f.control {
+			depth++
+		}
+	}
+	return depth
+}
+
+// countParams 定位符号名之后的参数列表并统计参数个数
+func countParams(code string, name string, lang string) int {
+	pos := 0
+	if name != "" {
+		if idx := indexIdent(code, name); idx >= 0 {
+			pos = idx + len(name)
+		}
+	}
+
+	// 跳过泛型参数 <...>，避免把 Fn(T) 之类的约束当成参数列表
+	rest := code[pos:]
+	trimmed := strings.TrimLeft(rest, " \t")
+	if strings.HasPrefix(trimmed, "<") {
+		depth := 0
+		for i := len(rest) - len(trimmed); i < len(rest); i++ {
+			if rest[i] == '<' {
+				depth++
+			} else if rest[i] == '>' {
+				depth--
+				if depth == 0 {
+					pos += i + 1
+					break
+				}
+			}
+		}
+	}
+
+	open := strings.IndexByte(code[pos:], '(')
+	if open < 0 {
+		return 0
+	}
+	open += pos
+
+	var params []string
+	depth := 0
+	last := open + 1
+	for i := open; i < len(code); i++ {
+		switch code[i] {
+		case '(', '[', '{', '<':
+			depth++
+		case ')', ']', '}', '>':
+			if code[i] == '>' && i > 0 && code[i-1] == '-' {
+				continue // 箭头 ->
+			}
+			depth--
+			if depth == 0 {
+				params = append(params, code[last:i])
+				return countParamList(params, lang)
+			}
+		case ',':
+			if depth == 1 {
+				params = append(params, code[last:i])
+				last = i + 1
+			}
+		}
+	}
+	return 0
+}
+
+func countParamList(params []string, lang string) int {
+	count := 0
+	for _, p := range params {
+		p = strings.TrimSpace(p)
+		switch {
+		case p == "", p == "void", p == "*", p == "/":
+			continue
+		case lang == "python" && (p == "self" || p == "cls" || strings.HasPrefix(p, "self:") || strings.HasPrefix(p, "cls:")):
+			continue
+		case lang == "rust" && strings.HasSuffix(strings.Join(strings.Fields(p), ""), "self"):
+			continue
+		case lang == "javascript" && strings.HasPrefix(p, "this:"):
+			continue
+		}
+		count++
+	}
+	return count
+}
+
+// indexIdent 查找完整标识符出现的位置
+func indexIdent(code, ident string) int {
+	from := 0
+	for {
+		idx := strings.Index(code[from:], ident)
+		if idx < 0 {
+			return -1
+		}
+		idx += from
+		end := idx + len(ident)
+		if (idx == 0 || !isIdentPart(code[idx-1])) && (end >= len(code) || !isIdentPart(code[end])) {
+			return idx
+		}
+		from = idx + 1
+	}
+}
+
+// ============================================================================
+// 指标持久化 (symbols.db 中的 symbol_metrics 表)
+// ============================================================================
+
+const symbolMetricsSchema = `CREATE TABLE IF NOT EXISTS symbol_metrics (
+	symbol_id INTEGER PRIMARY KEY,
+	cyclomatic INTEGER NOT NULL DEFAULT 1,
+	cognitive INTEGER NOT NULL DEFAULT 0,
+	line_count INTEGER NOT NULL DEFAULT 0,
+	param_count INTEGER NOT NULL DEFAULT 0,
+	max_nesting INTEGER NOT NULL DEFAULT 0
+)`
+
+type pendingMetricSymbol struct {
+	id        int64
+	name      string
+	kind      string
+	lineStart int
+	lineEnd   int
+}
+
+// refreshSymbolMetrics 为索引中尚无指标的符号计算复杂度
+// Rust 引擎对变更文件会删除并重建符号（symbol_id 自增不复用），因此只需补算缺失项并清理孤儿记录
+func refreshSymbolMetrics(projectRoot string) error {
+	dbPath := getDBPath(projectRoot)
+	if !fileExists(dbPath) {
+		return nil
+	}
+
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if _, err := db.Exec(symbolMetricsSchema); err != nil {
+		return err
+	}
+	if _, err := db.Exec("DELETE FROM symbol_metrics WHERE symbol_id NOT IN (SELECT symbol_id FROM symbols)"); err != nil {
+		return err
+	}
+
+	rows, err := db.Query(`
+		SELECT s.symbol_id, s.name, s.symbol_type, s.line_start, s.line_end, f.file_path
+		FROM symbols s
+		JOIN files f ON s.file_id = f.file_id
+		LEFT JOIN symbol_metrics m ON m.symbol_id = s.symbol_id
+		WHERE m.symbol_id IS NULL AND s.symbol_type IN ('function', 'method', 'class')
+		ORDER BY f.file_path, s.line_start`)
+	if err != nil {
+		return err
+	}
+
+	byFile := make(map[string][]pendingMetricSymbol)
+	var order []string
+	for rows.Next() {
+		var s pendingMetricSymbol
+		var lineStart, lineEnd sql.NullInt64
+		var path string
+		if err := rows.Scan(&s.id, &s.name, &s.kind, &lineStart, &lineEnd, &path); err != nil {
+			continue
+		}
+		s.lineStart, s.lineEnd = int(lineStart.Int64), int(lineEnd.Int64)
+		if _, ok := byFile[path]; !ok {
+			order = append(order, path)
+		}
+		byFile[path] = append(byFile[path], s)
+	}
+	rows.Close()
+
+	if len(order) == 0 {
+		return nil
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	stmt, err := tx.Prepare(`INSERT OR REPLACE INTO symbol_metrics
+		(symbol_id, cyclomatic, cognitive, line_count, param_count, max_nesting)
+		VALUES (?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+
+	for _, path := range order {
+		data, err := os.ReadFile(filepath.Join(projectRoot, path))
+		if err != nil {
+			continue
+		}
+		lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+		for id, m := range computeFileMetrics(path, lines, byFile[path]) {
+			if _, err := stmt.Exec(id, m.Cyclomatic, m.Cognitive, m.LineCount, m.ParamCount, m.MaxNesting); err != nil {
+				tx.Rollback()
+				return err
+			}
+		}
+	}
+
+	return tx.Commit()
+}
+
+// computeFileMetrics 计算单个文件内待处理符号的指标
+// 类的指标由其内部函数聚合：圈/认知复杂度求和，嵌套取最大值
+func computeFileMetrics(path string, lines []string, symbols []pendingMetricSymbol) map[int64]SymbolMetrics {
+	lang := metricsLanguage(path)
+	result := make(map[int64]SymbolMetrics)
+
+	var funcs []pendingMetricSymbol
+	for _, s := range symbols {
+		if s.kind == "class" || s.lineStart < 1 || s.lineEnd < s.lineStart || s.lineStart > len(lines) {
+			continue
+		}
+		end := s.lineEnd
+		if end > len(lines) {
+			end = len(lines)
+		}
+		result[s.id] = ComputeMetrics(lang, s.name, lines[s.lineStart-1:end])
+		funcs = append(funcs, s)
+	}
+
+	for _, s := range symbols {
+		if s.kind != "class" {
+			continue
+		}
+		agg := SymbolMetrics{Cyclomatic: 1, LineCount: s.lineEnd - s.lineStart + 1}
+		for _, f := range funcs {
+			if f.lineStart >= s.lineStart && f.lineEnd <= s.lineEnd {
+				fm := result[f.id]
+				agg.Cyclomatic += fm.Cyclomatic - 1
+				agg.Cognitive += fm.Cognitive
+				agg.MaxNesting = max(agg.MaxNesting, fm.MaxNesting)
+			}
+		}
+		result[s.id] = agg
+	}
+	return result
+}
+
+// loadMetricsByName 读取指定符号名的全部指标（同名符号可能有多处定义）
+func loadMetricsByName(db *sql.DB, name string) []SymbolMetrics {
+	rows, err := db.Query(`
+		SELECT m.cyclomatic, m.cognitive, m.line_count, m.param_count, m.max_nesting
+		FROM symbols s JOIN symbol_metrics m ON m.symbol_id = s.symbol_id
+		WHERE s.name = ?`, name)
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+
+	var result []SymbolMetrics
+	for rows.Next() {
+		var m SymbolMetrics
+		if err := rows.Scan(&m.Cyclomatic, &m.Cognitive, &m.LineCount, &m.ParamCount, &m.MaxNesting); err == nil {
+			result = append(result, m)
+		}
+	}
+	return result
+}
+
+// GetSymbolMetrics 按 canonical_id 读取符号指标（同 ID 多处定义时取最复杂者）
+func (ai *ASTIndexer) GetSymbolMetrics(projectRoot string, canonicalID string) (*SymbolMetrics, error) {
+	dbPath := getDBPath(projectRoot)
+	if !fileExists(dbPath) {
+		return nil, nil
+	}
+
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	if !hasTable(db, "symbol_metrics") {
+		return nil, nil
+	}
+
+	rows, err := db.Query(`
+		SELECT m.cyclomatic, m.cognitive, m.line_count, m.param_count, m.max_nesting
+		FROM symbols s JOIN symbol_metrics m ON m.symbol_id = s.symbol_id
+		WHERE s.canonical_id = ?`, canonicalID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var best *SymbolMetrics
+	for rows.Next() {
+		var m SymbolMetrics
+		if err := rows.Scan(&m.Cyclomatic, &m.Cognitive, &m.LineCount, &m.ParamCount, &m.MaxNesting); err != nil {
+			continue
+		}
+		if best == nil || m.Score() > best.Score() {
+			mc := m
+			best = &mc
+		}
+	}
+	return best, nil
+}
+
+func hasTable(db *sql.DB, table string) bool {
+	var n int
+	if err := db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", table).Scan(&n); err != nil {
+		return false
+	}
+	return n > 0
+}
@@ -0,0 +1,577 @@
+package services
+
+import (
+	"crypto/sha1"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mcp-server-go/internal/i18n"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ============================================================================
+// 指标快照与趋势 (.mcp-data/metrics_history.db)
+// symbols.db 每次索引都会被覆盖，历史单独存放
+// ============================================================================
+
+const (
+	// HotspotScore 热点阈值，与 project_map 的 HIGH 标记一致
+	HotspotScore = 50.0
+	// maxMetricSnapshots 最多保留的快照数
+	maxMetricSnapshots = 100
+	// minScoreDelta 小于该值的分数变化视为噪声
+	minScoreDelta = 0.5
+)
+
+// MetricSnapshot 一次指标变化的快照；指标未变的后续索引只累加 Runs 并更新最近一次运行的时间与版本
+type MetricSnapshot struct {
+	ID              int64   `json:"id"`
+	CreatedAt       string  `json:"created_at"`
+	GitRevision     string  `json:"git_revision,omitempty"`
+	LastRunAt       string  `json:"last_run_at"`
+	LastGitRevision string  `json:"last_git_revision,omitempty"`
+	Runs            int     `json:"runs"`
+	TotalFiles      int     `json:"total_files"`
+	TotalSymbols    int     `json:"total_symbols"`
+	AvgScore        float64 `json:"avg_score"`
+	Hotspots        int     `json:"hotspots"`
+}
+
+// SnapshotSymbol 快照中的单个符号
+type SnapshotSymbol struct {
+	CanonicalID string        `json:"canonical_id"`
+	Name        string        `json:"name"`
+	FilePath    string        `json:"file_path"`
+	SymbolType  string        `json:"symbol_type"`
+	Score       float64       `json:"score"`
+	Metrics     SymbolMetrics `json:"metrics"`
+}
+
+// SnapshotDir 快照中的目录汇总
+type SnapshotDir struct {
+	Dir        string  `json:"dir"`
+	Files      int     `json:"files"`
+	Symbols    int     `json:"symbols"`
+	TotalScore float64 `json:"total_score"`
+	MaxScore   float64 `json:"max_score"`
+	Hotspots   int     `json:"hotspots"`
+}
+
+// AvgScore 目录平均复杂度
+func (d SnapshotDir) AvgScore() float64 {
+	if d.Symbols == 0 {
+		return 0
+	}
+	return d.TotalScore / float64(d.Symbols)
+}
+
+// SymbolChange 符号复杂度变化
+type SymbolChange struct {
+	Symbol SnapshotSymbol `json:"symbol"`
+	Before float64        `json:"before"`
+	After  float64        `json:"after"`
+	Delta  float64        `json:"delta"`
+}
+
+// DirChange 目录复杂度变化
+type DirChange struct {
+	Dir          string  `json:"dir"`
+	SymbolsDelta int     `json:"symbols_delta"`
+	AvgBefore    float64 `json:"avg_before"`
+	AvgAfter     float64 `json:"avg_after"`
+	HotspotDelta int     `json:"hotspot_delta"`
+}
+
+// MetricDelta 两个快照之间的差异
+type MetricDelta struct {
+	From        MetricSnapshot   `json:"from"`
+	To          MetricSnapshot   `json:"to"`
+	Scope       string           `json:"scope,omitempty"`
+	Added       []SnapshotSymbol `json:"added"`
+	Removed     []SnapshotSymbol `json:"removed"`
+	Rises       []SymbolChange   `json:"rises"`
+	Falls       []SymbolChange   `json:"falls"`
+	NewHotspots []SnapshotSymbol `json:"new_hotspots"`
+	Dirs        []DirChange      `json:"dirs"`
+}
+
+const metricsHistorySchema = `
+CREATE TABLE IF NOT EXISTS metric_snapshots (
+	snapshot_id INTEGER PRIMARY KEY AUTOINCREMENT,
+	created_at TEXT NOT NULL,
+	git_revision TEXT,
+	fingerprint TEXT NOT NULL,
+	last_run_at TEXT NOT NULL,
+	last_git_revision TEXT,
+	runs INTEGER NOT NULL DEFAULT 1,
+	total_files INTEGER NOT NULL DEFAULT 0,
+	total_symbols INTEGER NOT NULL DEFAULT 0,
+	avg_score REAL NOT NULL DEFAULT 0,
+	hotspots INTEGER NOT NULL DEFAULT 0
+);
+CREATE TABLE IF NOT EXISTS snapshot_symbols (
+	snapshot_id INTEGER NOT NULL,
+	canonical_id TEXT NOT NULL,
+	name TEXT NOT NULL,
+	file_path TEXT NOT NULL,
+	symbol_type TEXT,
+	score REAL NOT NULL,
+	cyclomatic INTEGER, cognitive INTEGER, line_count INTEGER, param_count INTEGER, max_nesting INTEGER,
+	PRIMARY KEY (snapshot_id, canonical_id)
+);`
+
+func getHistoryDBPath(projectRoot string) string {
+	return filepath.Join(projectRoot, ".mcp-data", "metrics_history.db")
+}
+
+func openHistoryDB(projectRoot string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", getHistoryDBPath(projectRoot))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(metricsHistorySchema); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// gitRevision 当前 HEAD 短哈希（非 git 仓库返回空）
+func gitRevision(projectRoot string) string {
+	cmd := exec.Command("git", "rev-parse", "--short", "HEAD")
+	cmd.Dir = projectRoot
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// collectCurrentMetrics 从 symbols.db 读取当前全部符号指标
+func collectCurrentMetrics(projectRoot string) ([]SnapshotSymbol, int, error) {
+	dbPath := getDBPath(projectRoot)
+	if !fileExists(dbPath) {
+		return nil, 0, nil
+	}
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer db.Close()
+
+	if !hasTable(db, "symbol_metrics") {
+		return nil, 0, nil
+	}
+
+	var totalFiles int
+	_ = db.QueryRow("SELECT COUNT(*) FROM files").Scan(&totalFiles)
+
+	rows, err := db.Query(`
+		SELECT s.canonical_id, s.name, f.file_path, s.symbol_type,
+		       m.cyclomatic, m.cognitive, m.line_count, m.param_count, m.max_nesting
+		FROM symbols s
+		JOIN files f ON s.file_id = f.file_id
+		JOIN symbol_metrics m ON m.symbol_id = s.symbol_id`)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	// 同一 canonical_id 可能多处定义 (重载/条件编译)，取最复杂者
+	byID := make(map[string]SnapshotSymbol)
+	for rows.Next() {
+		var s SnapshotSymbol
+		var canonicalID sql.NullString
+		if err := rows.Scan(&canonicalID, &s.Name, &s.FilePath, &s.SymbolType,
+			&s.Metrics.Cyclomatic, &s.Metrics.Cognitive, &s.Metrics.LineCount,
+			&s.Metrics.ParamCount, &s.Metrics.MaxNesting); err != nil {
+			continue
+		}
+		s.FilePath = filepath.ToSlash(s.FilePath)
+		s.CanonicalID = canonicalID.String
+		if s.CanonicalID == "" {
+			s.CanonicalID = s.FilePath + "::" + s.Name
+		}
+		s.Score = s.Metrics.Score()
+		if old, ok := byID[s.CanonicalID]; !ok || s.Score > old.Score {
+			byID[s.CanonicalID] = s
+		}
+	}
+
+	symbols := make([]SnapshotSymbol, 0, len(byID))
+	for _, s := range byID {
+		symbols = append(symbols, s)
+	}
+	sort.Slice(symbols, func(i, j int) bool { return symbols[i].CanonicalID < symbols[j].CanonicalID })
+	return symbols, totalFiles, nil
+}
+
+// aggregateDirs 按目录汇总符号指标
+func aggregateDirs(symbols []SnapshotSymbol) map[string]*SnapshotDir {
+	dirs := make(map[string]*SnapshotDir)
+	files := make(map[string]map[string]bool)
+	for _, s := range symbols {
+		dir := path.Dir(s.FilePath)
+		d := dirs[dir]
+		if d == nil {
+			d = &SnapshotDir{Dir: dir}
+			dirs[dir] = d
+			files[dir] = make(map[string]bool)
+		}
+		if !files[dir][s.FilePath] {
+			files[dir][s.FilePath] = true
+			d.Files++
+		}
+		d.Symbols++
+		d.TotalScore += s.Score
+		if s.Score > d.MaxScore {
+			d.MaxScore = s.Score
+		}
+		if s.Score >= HotspotScore {
+			d.Hotspots++
+		}
+	}
+	return dirs
+}
+
+func metricsFingerprint(symbols []SnapshotSymbol) string {
+	h := sha1.New()
+	for _, s := range symbols {
+		fmt.Fprintf(h, "%s|%s|%v\n", s.CanonicalID, s.FilePath, s.Metrics)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// RecordMetricSnapshot 记录一次索引运行的指标
+// 指标与上一次快照完全相同时不另存符号，只在最新快照上记下本次运行的时间、Git 版本与次数 (recorded=false)：
+// 这样每次运行都留有记录，而最近 maxMetricSnapshots 个快照仍都代表真实变化，"对比上一快照" 不会落到空差异上。
+// 目录汇总不落库，对比时由 snapshot_symbols 按 scope 现算
+func RecordMetricSnapshot(projectRoot string) (snapshot *MetricSnapshot, recorded bool, err error) {
+	symbols, totalFiles, err := collectCurrentMetrics(projectRoot)
+	if err != nil || len(symbols) == 0 {
+		return nil, false, err
+	}
+
+	db, err := openHistoryDB(projectRoot)
+	if err != nil {
+		return nil, false, err
+	}
+	defer db.Close()
+
+	now, revision := time.Now().Format(time.RFC3339), gitRevision(projectRoot)
+	fingerprint := metricsFingerprint(symbols)
+	var lastID int64
+	var lastFingerprint string
+	_ = db.QueryRow("SELECT snapshot_id, fingerprint FROM metric_snapshots ORDER BY snapshot_id DESC LIMIT 1").Scan(&lastID, &lastFingerprint)
+	if lastFingerprint == fingerprint {
+		if _, err := db.Exec(`UPDATE metric_snapshots SET last_run_at = ?, last_git_revision = ?, runs = runs + 1
+			WHERE snapshot_id = ?`, now, revision, lastID); err != nil {
+			return nil, false, err
+		}
+		latest, err := getSnapshot(db, lastID)
+		return latest, false, err
+	}
+
+	snap := MetricSnapshot{
+		CreatedAt:       now,
+		GitRevision:     revision,
+		LastRunAt:       now,
+		LastGitRevision: revision,
+		Runs:            1,
+		TotalFiles:      totalFiles,
+		TotalSymbols:    len(symbols),
+	}
+	var total float64
+	for _, s := range symbols {
+		total += s.Score
+		if s.Score >= HotspotScore {
+			snap.Hotspots++
+		}
+	}
+	snap.AvgScore = total / float64(len(symbols))
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, false, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`INSERT INTO metric_snapshots
+		(created_at, git_revision, fingerprint, last_run_at, last_git_revision, runs, total_files, total_symbols, avg_score, hotspots)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		snap.CreatedAt, snap.GitRevision, fingerprint, snap.LastRunAt, snap.LastGitRevision, snap.Runs,
+		snap.TotalFiles, snap.TotalSymbols, snap.AvgScore, snap.Hotspots)
+	if err != nil {
+		return nil, false, err
+	}
+	snap.ID, _ = res.LastInsertId()
+
+	symStmt, err := tx.Prepare(`INSERT INTO snapshot_symbols
+		(snapshot_id, canonical_id, name, file_path, symbol_type, score,
+		 cyclomatic, cognitive, line_count, param_count, max_nesting)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return nil, false, err
+	}
+	defer symStmt.Close()
+	for _, s := range symbols {
+		m := s.Metrics
+		if _, err := symStmt.Exec(snap.ID, s.CanonicalID, s.Name, s.FilePath, s.SymbolType, s.Score,
+			m.Cyclomatic, m.Cognitive, m.LineCount, m.ParamCount, m.MaxNesting); err != nil {
+			return nil, false, err
+		}
+	}
+
+	// 只保留最近 N 个快照
+	cutoff := `SELECT snapshot_id FROM metric_snapshots ORDER BY snapshot_id DESC LIMIT -1 OFFSET ?`
+	for _, table := range []string{"snapshot_symbols", "metric_snapshots"} {
+		if _, err := tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE snapshot_id IN (%s)", table, cutoff), maxMetricSnapshots); err != nil {
+			return nil, false, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, false, err
+	}
+	return &snap, true, nil
+}
+
+// getSnapshot 读取快照元信息；id<=0 表示最新
+func getSnapshot(db *sql.DB, id int64) (*MetricSnapshot, error) {
+	query := `SELECT snapshot_id, created_at, COALESCE(git_revision, ''), last_run_at, COALESCE(last_git_revision, ''), runs,
+		total_files, total_symbols, avg_score, hotspots
+		FROM metric_snapshots `
+	var row *sql.Row
+	if id > 0 {
+		row = db.QueryRow(query+"WHERE snapshot_id = ?", id)
+	} else {
+		row = db.QueryRow(query + "ORDER BY snapshot_id DESC LIMIT 1")
+	}
+
+	var s MetricSnapshot
+	if err := row.Scan(&s.ID, &s.CreatedAt, &s.GitRevision, &s.LastRunAt, &s.LastGitRevision, &s.Runs, &s.TotalFiles, &s.TotalSymbols, &s.AvgScore, &s.Hotspots); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &s, nil
+}
+
+// ListMetricSnapshots 列出最近的快照（新到旧）
+func ListMetricSnapshots(projectRoot string, limit int) ([]MetricSnapshot, error) {
+	if !fileExists(getHistoryDBPath(projectRoot)) {
+		return nil, nil
+	}
+	db, err := openHistoryDB(projectRoot)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	if limit <= 0 {
+		limit = 20
+	}
+	rows, err := db.Query(`SELECT snapshot_id, created_at, COALESCE(git_revision, ''), last_run_at, COALESCE(last_git_revision, ''), runs,
+		total_files, total_symbols, avg_score, hotspots
+		FROM metric_snapshots ORDER BY snapshot_id DESC LIMIT ?`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []MetricSnapshot
+	for rows.Next() {
+		var s MetricSnapshot
+		if err := rows.Scan(&s.ID, &s.CreatedAt, &s.GitRevision, &s.LastRunAt, &s.LastGitRevision, &s.Runs, &s.TotalFiles, &s.TotalSymbols, &s.AvgScore, &s.Hotspots); err == nil {
+			result = append(result, s)
+		}
+	}
+	return result, nil
+}
+
+func loadSnapshotSymbols(db *sql.DB, id int64, scope string) (map[string]SnapshotSymbol, error) {
+	rows, err := db.Query(`SELECT canonical_id, name, file_path, COALESCE(symbol_type, ''), score,
+		cyclomatic, cognitive, line_count, param_count, max_nesting
+		FROM snapshot_symbols WHERE snapshot_id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[string]SnapshotSymbol)
+	for rows.Next() {
+		var s SnapshotSymbol
+		if err := rows.Scan(&s.CanonicalID, &s.Name, &s.FilePath, &s.SymbolType, &s.Score,
+			&s.Metrics.Cyclomatic, &s.Metrics.Cognitive, &s.Metrics.LineCount,
+			&s.Metrics.ParamCount, &s.Metrics.MaxNesting); err != nil {
+			continue
+		}
+		if inScope(s.FilePath, scope) {
+			result[s.CanonicalID] = s
+		}
+	}
+	return result, nil
+}
+
+// inScope 按路径段判断文件是否位于 scope 下 ("internal/tool" 不匹配 "internal/tools/...")
+func inScope(filePath, scope string) bool {
+	scope = path.Clean(strings.Trim(filepath.ToSlash(scope), "/"))
+	if scope == "." {
+		return true
+	}
+	return filePath == scope || strings.HasPrefix(filePath, scope+"/")
+}
+
+// DiffMetricSnapshots 对比两个快照
+// toID<=0 表示最新快照；fromID==0 表示 to 的前一个快照
+func DiffMetricSnapshots(projectRoot string, fromID, toID int64, scope string) (*MetricDelta, error) {
+	if !fileExists(getHistoryDBPath(projectRoot)) {
+		return nil, errors.New(i18n.T("尚无指标快照，请先执行一次索引 (initialize_project / project_map)"))
+	}
+	db, err := openHistoryDB(projectRoot)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	to, err := getSnapshot(db, toID)
+	if err != nil {
+		return nil, err
+	}
+	if to == nil {
+		return nil, errors.New(i18n.T("快照 %d 不存在", toID))
+	}
+
+	var from *MetricSnapshot
+	if fromID > 0 {
+		from, err = getSnapshot(db, fromID)
+	} else {
+		var prevID int64
+		err = db.QueryRow("SELECT snapshot_id FROM metric_snapshots WHERE snapshot_id < ? ORDER BY snapshot_id DESC LIMIT 1", to.ID).Scan(&prevID)
+		if err == sql.ErrNoRows {
+			return nil, errors.New(i18n.T("快照 #%d 之前没有更早的快照，至少需要两次索引才能对比", to.ID))
+		}
+		if err == nil {
+			from, err = getSnapshot(db, prevID)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if from == nil {
+		return nil, errors.New(i18n.T("快照 %d 不存在", fromID))
+	}
+
+	before, err := loadSnapshotSymbols(db, from.ID, scope)
+	if err != nil {
+		return nil, err
+	}
+	after, err := loadSnapshotSymbols(db, to.ID, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	delta := compareSnapshotSymbols(before, after)
+	delta.From, delta.To, delta.Scope = *from, *to, scope
+	return delta, nil
+}
+
+// compareSnapshotSymbols 计算符号级与目录级差异
+func compareSnapshotSymbols(before, after map[string]SnapshotSymbol) *MetricDelta {
+	delta := &MetricDelta{}
+
+	for id, a := range after {
+		b, existed := before[id]
+		if !existed {
+			delta.Added = append(delta.Added, a)
+			if a.Score >= HotspotScore {
+				delta.NewHotspots = append(delta.NewHotspots, a)
+			}
+			continue
+		}
+		diff := a.Score - b.Score
+		change := SymbolChange{Symbol: a, Before: b.Score, After: a.Score, Delta: diff}
+		if diff >= minScoreDelta {
+			delta.Rises = append(delta.Rises, change)
+		} else if diff <= -minScoreDelta {
+			delta.Falls = append(delta.Falls, change)
+		}
+		if a.Score >= HotspotScore && b.Score < HotspotScore {
+			delta.NewHotspots = append(delta.NewHotspots, a)
+		}
+	}
+	for id, b := range before {
+		if _, ok := after[id]; !ok {
+			delta.Removed = append(delta.Removed, b)
+		}
+	}
+
+	byScore := func(list []SnapshotSymbol) {
+		sort.Slice(list, func(i, j int) bool {
+			if list[i].Score != list[j].Score {
+				return list[i].Score > list[j].Score
+			}
+			return list[i].CanonicalID < list[j].CanonicalID
+		})
+	}
+	byScore(delta.Added)
+	byScore(delta.Removed)
+	byScore(delta.NewHotspots)
+	sort.Slice(delta.Rises, func(i, j int) bool { return delta.Rises[i].Delta > delta.Rises[j].Delta })
+	sort.Slice(delta.Falls, func(i, j int) bool { return delta.Falls[i].Delta < delta.Falls[j].Delta })
+
+	// 目录级：按作用域内的符号重新聚合，保证与 scope 过滤一致
+	toList := func(m map[string]SnapshotSymbol) []SnapshotSymbol {
+		list := make([]SnapshotSymbol, 0, len(m))
+		for _, s := range m {
+			list = append(list, s)
+		}
+		return list
+	}
+	dirsBefore := aggregateDirs(toList(before))
+	dirsAfter := aggregateDirs(toList(after))
+	seen := make(map[string]bool)
+	for dir := range dirsBefore {
+		seen[dir] = true
+	}
+	for dir := range dirsAfter {
+		seen[dir] = true
+	}
+	for dir := range seen {
+		b, a := SnapshotDir{Dir: dir}, SnapshotDir{Dir: dir}
+		if d := dirsBefore[dir]; d != nil {
+			b = *d
+		}
+		if d := dirsAfter[dir]; d != nil {
+			a = *d
+		}
+		change := DirChange{
+			Dir:          dir,
+			SymbolsDelta: a.Symbols - b.Symbols,
+			AvgBefore:    b.AvgScore(),
+			AvgAfter:     a.AvgScore(),
+			HotspotDelta: a.Hotspots - b.Hotspots,
+		}
+		avgDiff := change.AvgAfter - change.AvgBefore
+		if change.SymbolsDelta != 0 || change.HotspotDelta != 0 || avgDiff >= minScoreDelta || avgDiff <= -minScoreDelta {
+			delta.Dirs = append(delta.Dirs, change)
+		}
+	}
+	sort.Slice(delta.Dirs, func(i, j int) bool {
+		di := delta.Dirs[i].AvgAfter - delta.Dirs[i].AvgBefore
+		dj := delta.Dirs[j].AvgAfter - delta.Dirs[j].AvgBefore
+		if di != dj {
+			return di > dj
+		}
+		return delta.Dirs[i].Dir < delta.Dirs[j].Dir
+	})
+
+	return delta
+}
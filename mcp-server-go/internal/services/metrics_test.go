@@ -0,0 +1,304 @@
+package services
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestComputeMetrics(t *testing.T) {
+	cases := []struct {
+		name   string
+		lang   string
+		symbol string
+		src    string
+		want   SymbolMetrics
+	}{
+		{
+			name:   "go straight line",
+			lang:   "go",
+			symbol: "Add",
+			src: `func Add(a, b int) int {
+	return a + b
+}`,
+			want: SymbolMetrics{Cyclomatic: 1, Cognitive: 0, LineCount: 3, ParamCount: 2, MaxNesting: 0},
+		},
+		{
+			name:   "go nested branches",
+			lang:   "go",
+			symbol: "Walk",
+			src: `func (w *Walker) Walk(items []string) int {
+	n := 0
+	for _, it := range items {
+		if it == "" || it == "-" {
+			continue
+		} else if strings.HasPrefix(it, "#") {
+			n++
+		} else {
+			n += 2
+		}
+	}
+	return n
+}`,
+			// for(+1) if(+2 nesting) ||(+1) else-if(+1) else(+1)
+			want: SymbolMetrics{Cyclomatic: 5, Cognitive: 6, LineCount: 13, ParamCount: 1, MaxNesting: 2},
+		},
+		{
+			name:   "go switch and strings ignored",
+			lang:   "go",
+			symbol: "Kind",
+			src: `func Kind(s string) string {
+	// if this comment counted it would be wrong
+	switch s {
+	case "a":
+		return "if && for"
+	case "b":
+		return ` + "`while`" + `
+	default:
+		return ""
+	}
+}`,
+			want: SymbolMetrics{Cyclomatic: 3, Cognitive: 1, LineCount: 11, ParamCount: 1, MaxNesting: 1},
+		},
+		{
+			name:   "python nested",
+			lang:   "python",
+			symbol: "scan",
+			src: `def scan(self, items, limit=10):
+    """if for while"""
+    total = 0
+    for item in items:
+        if item and item.ok:
+            total += 1
+        elif item is None:
+            continue
+    return total`,
+			// for(+1) if(+2) and(+1) elif(+1)
+			want: SymbolMetrics{Cyclomatic: 5, Cognitive: 5, LineCount: 9, ParamCount: 2, MaxNesting: 2},
+		},
+		{
+			name:   "javascript ternary and catch",
+			lang:   "javascript",
+			symbol: "load",
+			src: `async function load(url, opts) {
+  try {
+    const r = await fetch(url, opts?.init ?? {});
+    return r.ok ? r.json() : null;
+  } catch (e) {
+    return null;
+  }
+}`,
+			// ?:(+1) catch(+1)
+			want: SymbolMetrics{Cyclomatic: 3, Cognitive: 2, LineCount: 8, ParamCount: 2, MaxNesting: 1},
+		},
+		{
+			name:   "rust match arms",
+			lang:   "rust",
+			symbol: "label",
+			src: `fn label<'a, F: Fn(i32) -> bool>(&self, v: i32, f: F) -> &'a str {
+    match v {
+        0 => "zero",
+        1 => "one",
+        _ => "many",
+    }
+}`,
+			want: SymbolMetrics{Cyclomatic: 3, Cognitive: 1, LineCount: 7, ParamCount: 2, MaxNesting: 1},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := ComputeMetrics(tc.lang, tc.symbol, strings.Split(tc.src, "\n"))
+			if got != tc.want {
+				t.Fatalf("ComputeMetrics() = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSymbolMetricsScore(t *testing.T) {
+	simple := SymbolMetrics{Cyclomatic: 1, LineCount: 3}
+	if s := simple.Score(); s >= 20 {
+		t.Fatalf("trivial function scored %.1f, want < 20", s)
+	}
+
+	tangled := SymbolMetrics{Cyclomatic: 18, Cognitive: 30, LineCount: 180, MaxNesting: 5, ParamCount: 7}
+	if s := tangled.Score(); s < 50 {
+		t.Fatalf("tangled function scored %.1f, want >= 50", s)
+	}
+	if escalateRisk("low", tangled.Score()) != "medium" || escalateRisk("low", simple.Score()) != "low" {
+		t.Fatalf("escalateRisk should only bump risk for complex functions")
+	}
+}
+
+func TestRefreshSymbolMetrics(t *testing.T) {
+	root := t.TempDir()
+	src := `package demo
+
+func Simple() int { return 1 }
+
+func Branchy(a int) int {
+	if a > 0 {
+		return 1
+	}
+	return 0
+}
+`
+	if err := os.WriteFile(filepath.Join(root, "demo.go"), []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	dbPath := getDBPath(root)
+	if err := os.MkdirAll(filepath.Dir(dbPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stmts := []string{
+		`CREATE TABLE files (file_id INTEGER PRIMARY KEY, file_path TEXT UNIQUE)`,
+		`CREATE TABLE symbols (symbol_id INTEGER PRIMARY KEY AUTOINCREMENT, file_id INTEGER, name TEXT,
+			canonical_id TEXT, symbol_type TEXT, line_start INTEGER, line_end INTEGER)`,
+		`INSERT INTO files (file_id, file_path) VALUES (1, 'demo.go')`,
+		`INSERT INTO symbols (file_id, name, canonical_id, symbol_type, line_start, line_end) VALUES
+			(1, 'Simple', 'func:demo.go::Simple', 'function', 3, 3),
+			(1, 'Branchy', 'func:demo.go::Branchy', 'function', 5, 10)`,
+	}
+	for _, stmt := range stmts {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("setup %q: %v", stmt, err)
+		}
+	}
+	db.Close()
+
+	if err := refreshSymbolMetrics(root); err != nil {
+		t.Fatalf("refreshSymbolMetrics() error = %v", err)
+	}
+
+	ai := &ASTIndexer{}
+	m, err := ai.GetSymbolMetrics(root, "func:demo.go::Branchy")
+	if err != nil || m == nil {
+		t.Fatalf("GetSymbolMetrics() = %v, %v", m, err)
+	}
+	if m.Cyclomatic != 2 || m.ParamCount != 1 || m.LineCount != 6 {
+		t.Fatalf("Branchy metrics = %+v", *m)
+	}
+
+	// 符号被删除后，孤儿指标应被清理
+	db, _ = sql.Open("sqlite", dbPath)
+	defer db.Close()
+	if _, err := db.Exec("DELETE FROM symbols WHERE name = 'Simple'"); err != nil {
+		t.Fatal(err)
+	}
+	if err := refreshSymbolMetrics(root); err != nil {
+		t.Fatal(err)
+	}
+	var n int
+	if err := db.QueryRow("SELECT COUNT(*) FROM symbol_metrics").Scan(&n); err != nil || n != 1 {
+		t.Fatalf("symbol_metrics rows = %d (err %v), want 1", n, err)
+	}
+}
+
+func TestCompareSnapshotSymbols(t *testing.T) {
+	sym := func(id, file string, score float64) SnapshotSymbol {
+		return SnapshotSymbol{CanonicalID: id, Name: id, FilePath: file, Score: score}
+	}
+	before := map[string]SnapshotSymbol{
+		"a": sym("a", "pkg/a.go", 10),
+		"b": sym("b", "pkg/b.go", 40),
+		"c": sym("c", "pkg/c.go", 30),
+		"d": sym("d", "lib/d.go", 5),
+	}
+	after := map[string]SnapshotSymbol{
+		"a": sym("a", "pkg/a.go", 10.2), // 噪声，忽略
+		"b": sym("b", "pkg/b.go", 55),   // 上升并成为热点
+		"c": sym("c", "pkg/c.go", 12),   // 下降
+		"e": sym("e", "lib/e.go", 60),   // 新增热点
+	}
+
+	d := compareSnapshotSymbols(before, after)
+	if len(d.Added) != 1 || d.Added[0].CanonicalID != "e" {
+		t.Fatalf("Added = %+v", d.Added)
+	}
+	if len(d.Removed) != 1 || d.Removed[0].CanonicalID != "d" {
+		t.Fatalf("Removed = %+v", d.Removed)
+	}
+	if len(d.Rises) != 1 || d.Rises[0].Symbol.CanonicalID != "b" || d.Rises[0].Delta != 15 {
+		t.Fatalf("Rises = %+v", d.Rises)
+	}
+	if len(d.Falls) != 1 || d.Falls[0].Symbol.CanonicalID != "c" {
+		t.Fatalf("Falls = %+v", d.Falls)
+	}
+	if len(d.NewHotspots) != 2 || d.NewHotspots[0].CanonicalID != "e" || d.NewHotspots[1].CanonicalID != "b" {
+		t.Fatalf("NewHotspots = %+v", d.NewHotspots)
+	}
+	if len(d.Dirs) != 2 || d.Dirs[0].Dir != "lib" || d.Dirs[0].HotspotDelta != 1 {
+		t.Fatalf("Dirs = %+v", d.Dirs)
+	}
+}
+
+func TestRecordMetricSnapshotDedup(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "a.go"), []byte("package a\n\nfunc A(x int) int {\n\tif x > 0 {\n\t\treturn x\n\t}\n\treturn 0\n}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	dbPath := getDBPath(root)
+	_ = os.MkdirAll(filepath.Dir(dbPath), 0755)
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	for _, stmt := range []string{
+		`CREATE TABLE files (file_id INTEGER PRIMARY KEY, file_path TEXT UNIQUE)`,
+		`CREATE TABLE symbols (symbol_id INTEGER PRIMARY KEY AUTOINCREMENT, file_id INTEGER, name TEXT,
+			canonical_id TEXT, symbol_type TEXT, line_start INTEGER, line_end INTEGER)`,
+		`INSERT INTO files (file_id, file_path) VALUES (1, 'a.go')`,
+		`INSERT INTO symbols (file_id, name, canonical_id, symbol_type, line_start, line_end)
+			VALUES (1, 'A', 'func:a.go::A', 'function', 3, 8)`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := refreshSymbolMetrics(root); err != nil {
+		t.Fatal(err)
+	}
+	first, recorded, err := RecordMetricSnapshot(root)
+	if err != nil || !recorded || first == nil {
+		t.Fatalf("first snapshot = %v, %v, %v", first, recorded, err)
+	}
+	// 指标未变：不另存快照，只在上一快照上记下这次运行
+	again, recorded, err := RecordMetricSnapshot(root)
+	if err != nil || recorded || again == nil || again.ID != first.ID {
+		t.Fatalf("unchanged metrics should not create a new snapshot: %v, %v, %v", again, recorded, err)
+	}
+	if first.Runs != 1 || again.Runs != 2 || again.LastRunAt == "" || again.CreatedAt != first.CreatedAt {
+		t.Fatalf("the run should be recorded on the latest snapshot: %+v", again)
+	}
+	if list, _ := ListMetricSnapshots(root, 10); len(list) != 1 {
+		t.Fatalf("expected a single snapshot, got %d", len(list))
+	}
+
+	// 函数变长后应产生新快照，并可与上一快照对比
+	if _, err := db.Exec("UPDATE symbol_metrics SET line_count = 300"); err != nil {
+		t.Fatal(err)
+	}
+	if _, recorded, _ := RecordMetricSnapshot(root); !recorded {
+		t.Fatalf("changed metrics should create a new snapshot")
+	}
+	delta, err := DiffMetricSnapshots(root, 0, 0, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if delta.From.ID != first.ID || len(delta.Rises) != 1 {
+		t.Fatalf("delta = %+v", delta)
+	}
+	if len(delta.Dirs) != 1 || delta.Dirs[0].Dir != "." {
+		t.Fatalf("directory summary should be derived from the symbols: %+v", delta.Dirs)
+	}
+}
@@ -0,0 +1,183 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"mcp-server-go/internal/i18n"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// PackageInfo 一个目录 (包) 的符号与依赖汇总
+type PackageInfo struct {
+	Dir       string       // 项目相对目录，根目录为 "."
+	Files     []string     // 目录下被索引的文件 (项目相对路径)
+	Symbols   []Node       // 目录下的全部符号，按文件、行号排序
+	DependsOn []PackageDep // 本目录调用的其他目录
+	UsedBy    []PackageDep // 调用本目录的其他目录
+}
+
+// PackageDep 目录间依赖 (按调用次数统计)
+type PackageDep struct {
+	Dir   string
+	Calls int
+}
+
+// CollectPackages 从 symbols.db 按目录汇总符号与跨目录调用关系，并补充文档注释。
+// scope 非空时只返回该目录及其子目录。
+func (ai *ASTIndexer) CollectPackages(projectRoot, scope string) ([]PackageInfo, error) {
+	dbPath := getDBPath(projectRoot)
+	if !fileExists(dbPath) {
+		return nil, errors.New(i18n.T("索引不存在，请先执行 initialize_project"))
+	}
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	rows, err := db.Query(`
+		SELECT s.canonical_id, s.name, COALESCE(s.qualified_name, ''), s.symbol_type, f.file_path,
+		       s.line_start, s.line_end, COALESCE(s.signature, '')
+		FROM symbols s JOIN files f ON s.file_id = f.file_id
+		ORDER BY f.file_path, s.line_start`)
+	if err != nil {
+		return nil, err
+	}
+	scope = strings.Trim(filepath.ToSlash(strings.TrimSpace(scope)), "/")
+	inScope := func(dir string) bool {
+		return scope == "" || scope == "." || dir == scope || strings.HasPrefix(dir, scope+"/")
+	}
+
+	byDir := make(map[string]*PackageInfo)
+	fileSeen := make(map[string]bool)
+	for rows.Next() {
+		var n Node
+		if err := rows.Scan(&n.ID, &n.Name, &n.QualifiedName, &n.NodeType, &n.FilePath,
+			&n.LineStart, &n.LineEnd, &n.Signature); err != nil {
+			continue
+		}
+		n.FilePath = filepath.ToSlash(n.FilePath)
+		dir := path.Dir(n.FilePath)
+		if !inScope(dir) {
+			continue
+		}
+		pkg := byDir[dir]
+		if pkg == nil {
+			pkg = &PackageInfo{Dir: dir}
+			byDir[dir] = pkg
+		}
+		if !fileSeen[n.FilePath] {
+			fileSeen[n.FilePath] = true
+			pkg.Files = append(pkg.Files, n.FilePath)
+		}
+		pkg.Symbols = append(pkg.Symbols, n)
+	}
+	rows.Close()
+
+	if hasTable(db, "calls") && hasColumn(db, "calls", "callee_id") {
+		depRows, err := db.Query(`
+			SELECT fc.file_path, fd.file_path, COUNT(*)
+			FROM calls c
+			JOIN symbols sc ON c.caller_id = sc.symbol_id
+			JOIN files fc ON sc.file_id = fc.file_id
+			JOIN symbols sd ON sd.canonical_id = c.callee_id
+			JOIN files fd ON sd.file_id = fd.file_id
+			WHERE c.callee_id IS NOT NULL AND c.callee_id != ''
+			GROUP BY fc.file_path, fd.file_path`)
+		if err == nil {
+			out := make(map[string]map[string]int)
+			in := make(map[string]map[string]int)
+			for depRows.Next() {
+				var from, to string
+				var calls int
+				if err := depRows.Scan(&from, &to, &calls); err != nil {
+					continue
+				}
+				fromDir, toDir := path.Dir(filepath.ToSlash(from)), path.Dir(filepath.ToSlash(to))
+				if fromDir == toDir {
+					continue
+				}
+				if out[fromDir] == nil {
+					out[fromDir] = make(map[string]int)
+				}
+				if in[toDir] == nil {
+					in[toDir] = make(map[string]int)
+				}
+				out[fromDir][toDir] += calls
+				in[toDir][fromDir] += calls
+			}
+			depRows.Close()
+			for dir, pkg := range byDir {
+				pkg.DependsOn = sortedPackageDeps(out[dir])
+				pkg.UsedBy = sortedPackageDeps(in[dir])
+			}
+		}
+	}
+
+	var nodes []*Node
+	for _, pkg := range byDir {
+		for i := range pkg.Symbols {
+			nodes = append(nodes, &pkg.Symbols[i])
+		}
+	}
+	ai.AttachDocs(projectRoot, nodes...)
+
+	result := make([]PackageInfo, 0, len(byDir))
+	for _, pkg := range byDir {
+		result = append(result, *pkg)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Dir < result[j].Dir })
+	return result, nil
+}
+
+func sortedPackageDeps(m map[string]int) []PackageDep {
+	deps := make([]PackageDep, 0, len(m))
+	for dir, calls := range m {
+		deps = append(deps, PackageDep{Dir: dir, Calls: calls})
+	}
+	sort.Slice(deps, func(i, j int) bool {
+		if deps[i].Calls != deps[j].Calls {
+			return deps[i].Calls > deps[j].Calls
+		}
+		return deps[i].Dir < deps[j].Dir
+	})
+	return deps
+}
+
+// IsExportedSymbol 按语言约定判断符号是否对包外可见；无法判断的语言一律视为导出
+func IsExportedSymbol(n Node) bool {
+	sig := strings.TrimSpace(n.Signature)
+	switch strings.ToLower(filepath.Ext(n.FilePath)) {
+	case ".go":
+		// 方法还要求接收者类型导出 (QualifiedName 形如 Type.Method)
+		if i := strings.Index(n.QualifiedName, "."); i > 0 && !startsUpper(n.QualifiedName[:i]) {
+			return false
+		}
+		return startsUpper(n.Name)
+	case ".py":
+		return !strings.HasPrefix(n.Name, "_")
+	case ".rs":
+		return strings.HasPrefix(sig, "pub")
+	case ".java", ".cs":
+		return strings.Contains(" "+sig, " public ")
+	case ".kt":
+		return !strings.Contains(" "+sig, " private ") && !strings.Contains(" "+sig, " internal ")
+	case ".js", ".jsx", ".ts", ".tsx", ".mjs":
+		// 类成员的签名不带 export，只排除私有成员
+		if strings.HasPrefix(n.Name, "#") || strings.Contains(" "+sig, " private ") {
+			return false
+		}
+		return strings.HasPrefix(sig, "export") || strings.Contains(n.QualifiedName, ".")
+	}
+	return true
+}
+
+func startsUpper(s string) bool {
+	r, _ := utf8.DecodeRuneInString(s)
+	return unicode.IsUpper(r)
+}
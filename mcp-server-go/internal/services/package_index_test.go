@@ -0,0 +1,93 @@
+package services
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCollectPackages(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"core/store.go":  "package core\n\n// Store 持久化存储\ntype Store struct{}\n\n// Save 保存记录\nfunc (s *Store) Save() {}\n\nfunc helper() {}\n",
+		"api/handler.go": "package api\n\n// Handle 处理请求\nfunc Handle() {\n\tnew(core.Store).Save()\n}\n",
+	}
+	for rel, content := range files {
+		p := filepath.Join(root, rel)
+		_ = os.MkdirAll(filepath.Dir(p), 0755)
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_ = os.MkdirAll(filepath.Join(root, ".mcp-data"), 0755)
+	db, err := sql.Open("sqlite", getDBPath(root))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, stmt := range []string{
+		`CREATE TABLE files (file_id INTEGER PRIMARY KEY, file_path TEXT UNIQUE)`,
+		`CREATE TABLE symbols (symbol_id INTEGER PRIMARY KEY, file_id INTEGER, name TEXT, qualified_name TEXT,
+			canonical_id TEXT, symbol_type TEXT, line_start INTEGER, line_end INTEGER, signature TEXT)`,
+		`CREATE TABLE calls (call_id INTEGER PRIMARY KEY, caller_id INTEGER, callee_name TEXT, call_line INTEGER, callee_id TEXT)`,
+		`INSERT INTO files VALUES (1, 'core/store.go'), (2, 'api/handler.go')`,
+		`INSERT INTO symbols VALUES
+			(1, 1, 'Store', 'Store', 'struct:core/store.go::Store', 'struct', 4, 4, 'type Store struct{}'),
+			(2, 1, 'Save', 'Store.Save', 'func:core/store.go::Store.Save', 'method', 7, 7, 'func (s *Store) Save() {}'),
+			(3, 1, 'helper', 'helper', 'func:core/store.go::helper', 'function', 9, 9, 'func helper() {}'),
+			(4, 2, 'Handle', 'Handle', 'func:api/handler.go::Handle', 'function', 4, 6, 'func Handle() {')`,
+		`INSERT INTO calls VALUES (1, 4, 'Save', 5, 'func:core/store.go::Store.Save'), (2, 2, 'helper', 7, 'func:core/store.go::helper')`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("setup %q: %v", stmt, err)
+		}
+	}
+	db.Close()
+
+	ai := &ASTIndexer{}
+	pkgs, err := ai.CollectPackages(root, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pkgs) != 2 || pkgs[0].Dir != "api" || pkgs[1].Dir != "core" {
+		t.Fatalf("unexpected packages: %+v", pkgs)
+	}
+	api, core := pkgs[0], pkgs[1]
+	if len(api.DependsOn) != 1 || api.DependsOn[0].Dir != "core" || api.DependsOn[0].Calls != 1 {
+		t.Fatalf("api should depend on core: %+v", api.DependsOn)
+	}
+	if len(core.UsedBy) != 1 || core.UsedBy[0].Dir != "api" || len(core.DependsOn) != 0 {
+		t.Fatalf("core should only be used by api (same-dir calls ignored): %+v %+v", core.UsedBy, core.DependsOn)
+	}
+	if core.Symbols[1].Doc != "Save 保存记录" {
+		t.Fatalf("doc comment not attached: %q", core.Symbols[1].Doc)
+	}
+
+	scoped, err := ai.CollectPackages(root, "core")
+	if err != nil || len(scoped) != 1 || scoped[0].Dir != "core" {
+		t.Fatalf("scope should limit packages: %+v %v", scoped, err)
+	}
+}
+
+func TestIsExportedSymbol(t *testing.T) {
+	cases := []struct {
+		node Node
+		want bool
+	}{
+		{Node{Name: "Save", QualifiedName: "Store.Save", FilePath: "a.go"}, true},
+		{Node{Name: "Save", QualifiedName: "store.Save", FilePath: "a.go"}, false},
+		{Node{Name: "helper", FilePath: "a.go"}, false},
+		{Node{Name: "_private", FilePath: "a.py"}, false},
+		{Node{Name: "load", FilePath: "a.py"}, true},
+		{Node{Name: "parse", Signature: "pub fn parse() {", FilePath: "a.rs"}, true},
+		{Node{Name: "inner", Signature: "fn inner() {", FilePath: "a.rs"}, false},
+		{Node{Name: "run", Signature: "public void run() {", FilePath: "A.java"}, true},
+		{Node{Name: "render", Signature: "export function render() {", FilePath: "a.ts"}, true},
+		{Node{Name: "local", Signature: "function local() {", FilePath: "a.ts"}, false},
+	}
+	for _, c := range cases {
+		if got := IsExportedSymbol(c.node); got != c.want {
+			t.Errorf("IsExportedSymbol(%+v) = %v, want %v", c.node, got, c.want)
+		}
+	}
+}
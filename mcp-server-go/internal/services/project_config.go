@@ -0,0 +1,271 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mcp-server-go/internal/i18n"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ============================================================================
+// 项目配置 (阈值、忽略目录与各类上限)
+// 内置默认 < 全局 (~/.mpm/mpm.yaml) < 项目 (.mcp-config/mpm.yaml)，
+// 每层只需写要覆盖的字段；文件变化后下一次读取自动重载
+// ============================================================================
+
+// ProjectConfigFile 项目配置文件名
+const ProjectConfigFile = "mpm.yaml"
+
+const projectConfigVersion = 1
+
+// ProjectConfig 生效的项目配置
+type ProjectConfig struct {
+	Version int           `yaml:"version" json:"version"`
+	Map     MapConfig     `yaml:"map" json:"map"`
+	Recall  RecallConfig  `yaml:"recall" json:"recall"`
+	Analyze AnalyzeConfig `yaml:"analyze" json:"analyze"`
+	Search  SearchConfig  `yaml:"search" json:"search"`
+	Ignore  IgnoreConfig  `yaml:"ignore" json:"ignore"`
+	DevLog  DevLogConfig  `yaml:"dev_log" json:"dev_log"`
+	Wiki    WikiConfig    `yaml:"wiki" json:"wiki"`
+	Locale  string        `yaml:"locale" json:"locale"` // 界面语言 (zh/en 或 locales/ 下的扩展语言)，环境变量 MPM_LANG 优先
+
+	Sources []string `yaml:"-" json:"sources,omitempty"` // 参与合并的配置文件
+}
+
+// MapConfig project_map 分页
+type MapConfig struct {
+	PageSize    int `yaml:"page_size" json:"page_size"`         // 每页条目预算
+	MaxPageSize int `yaml:"max_page_size" json:"max_page_size"` // 单页上限 (page_size 参数也不能超过)
+}
+
+// RecallConfig system_recall
+type RecallConfig struct {
+	Limit int `yaml:"limit" json:"limit"` // 未指定 limit 时的返回条数
+}
+
+// AnalyzeConfig manager_analyze
+type AnalyzeConfig struct {
+	AnchorLimit       int `yaml:"anchor_limit" json:"anchor_limit"`               // 参与符号预搜索的 symbols 上限
+	DirectiveMaxChars int `yaml:"directive_max_chars" json:"directive_max_chars"` // 简报中任务描述的截断长度 (字节)
+	BriefingTTLHours  int `yaml:"briefing_ttl_hours" json:"briefing_ttl_hours"`   // 简报默认保留时长
+}
+
+// SearchConfig code_grep
+type SearchConfig struct {
+	MaxResults int `yaml:"max_results" json:"max_results"` // 未指定 max_results 时的匹配上限
+}
+
+// IgnoreConfig 内置忽略目录 (优先级最低，仍可被 .gitignore / .mpmignore 的 "!name/" 重新纳入)
+type IgnoreConfig struct {
+	Dirs      []string `yaml:"dirs" json:"dirs"`                                 // 替换内置列表
+	ExtraDirs []string `yaml:"extra_dirs,omitempty" json:"extra_dirs,omitempty"` // 追加到列表末尾
+}
+
+// DevLogConfig dev-log.md
+type DevLogConfig struct {
+	Window int `yaml:"window" json:"window"` // 同步到 dev-log.md 的最近 memo 条数
+}
+
+// WikiConfig wiki_writer
+type WikiConfig struct {
+	OutputDir string `yaml:"output_dir" json:"output_dir"` // generate 模式默认输出目录
+}
+
+// DefaultProjectConfig 内置默认值 (与各工具原先的硬编码一致)
+func DefaultProjectConfig() *ProjectConfig {
+	return &ProjectConfig{
+		Version: projectConfigVersion,
+		Map:     MapConfig{PageSize: 200, MaxPageSize: 2000},
+		Recall:  RecallConfig{Limit: 20},
+		Analyze: AnalyzeConfig{AnchorLimit: 10, DirectiveMaxChars: 300, BriefingTTLHours: 72},
+		Search:  SearchConfig{MaxResults: 100},
+		Ignore:  IgnoreConfig{Dirs: append([]string{}, DefaultIgnoreDirs...)},
+		DevLog:  DevLogConfig{Window: 100},
+		Wiki:    WikiConfig{OutputDir: "docs/wiki"},
+		Locale:  i18n.LocaleZH,
+		Sources: []string{"builtin"},
+	}
+}
+
+// IgnoreDirs 生效的内置忽略目录
+func (c *ProjectConfig) IgnoreDirs() []string {
+	dirs := make([]string, 0, len(c.Ignore.Dirs)+len(c.Ignore.ExtraDirs))
+	seen := make(map[string]bool)
+	for _, d := range append(append([]string{}, c.Ignore.Dirs...), c.Ignore.ExtraDirs...) {
+		d = strings.Trim(strings.TrimSpace(d), "/")
+		if d != "" && !seen[d] {
+			seen[d] = true
+			dirs = append(dirs, d)
+		}
+	}
+	return dirs
+}
+
+// applyProjectConfigLayer 在 base 的副本上叠加一层配置文件 (拒绝未知字段，便于发现拼写错误)
+func applyProjectConfigLayer(base *ProjectConfig, data []byte) (*ProjectConfig, error) {
+	out := *base
+	out.Ignore.ExtraDirs = nil // extra_dirs 只对所在层生效，已在 IgnoreDirs 中并入 Dirs
+	out.Ignore.Dirs = base.IgnoreDirs()
+	out.Sources = append([]string{}, base.Sources...)
+	out.Version = 0
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&out); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if out.Version != 0 && out.Version != projectConfigVersion {
+		return nil, errors.New(i18n.T("不支持的 version: %d (当前为 %d)", out.Version, projectConfigVersion))
+	}
+	out.Version = projectConfigVersion
+	return &out, nil
+}
+
+// Validate 校验取值范围
+func (c *ProjectConfig) Validate() error {
+	var errs []string
+	check := func(key string, v, min, max int) {
+		if v < min || v > max {
+			errs = append(errs, i18n.T("%s 必须在 %d-%d 之间 (当前 %d)", key, min, max, v))
+		}
+	}
+	check("map.page_size", c.Map.PageSize, 1, 100000)
+	check("map.max_page_size", c.Map.MaxPageSize, 1, 100000)
+	if c.Map.PageSize > c.Map.MaxPageSize {
+		errs = append(errs, i18n.T("map.page_size (%d) 不能大于 map.max_page_size (%d)", c.Map.PageSize, c.Map.MaxPageSize))
+	}
+	check("recall.limit", c.Recall.Limit, 1, 1000)
+	check("analyze.anchor_limit", c.Analyze.AnchorLimit, 1, 100)
+	check("analyze.directive_max_chars", c.Analyze.DirectiveMaxChars, 20, 100000)
+	check("analyze.briefing_ttl_hours", c.Analyze.BriefingTTLHours, 1, 24*30)
+	check("search.max_results", c.Search.MaxResults, 1, 10000)
+	check("dev_log.window", c.DevLog.Window, 1, 100000)
+	for _, d := range append(append([]string{}, c.Ignore.Dirs...), c.Ignore.ExtraDirs...) {
+		if strings.TrimSpace(d) == "" || strings.ContainsAny(d, "*?[") {
+			errs = append(errs, i18n.T("ignore: 目录名 %q 无效 (不支持空值与通配符，复杂规则请写入 .mpmignore)", d))
+		}
+	}
+	if out := strings.TrimSpace(c.Wiki.OutputDir); out == "" || strings.HasPrefix(out, "/") || strings.Contains(out, "..") {
+		errs = append(errs, i18n.T("wiki.output_dir %q 必须是项目内的相对路径", c.Wiki.OutputDir))
+	}
+	if i18n.NormalizeLocale(c.Locale) == "" {
+		errs = append(errs, i18n.T("locale %q 无效 (需为语言代码，如 zh、en)", c.Locale))
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// LoadProjectConfig 加载并合并内置、全局、项目三层配置
+// 某一层无法解析或校验失败时跳过该层并返回警告，不影响其余层
+func LoadProjectConfig(projectRoot string) (*ProjectConfig, []string) {
+	cfg := DefaultProjectConfig()
+	var warnings []string
+
+	for _, path := range configLayerPaths(projectRoot, ProjectConfigFile) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				warnings = append(warnings, fmt.Sprintf("%s: %v", path, err))
+			}
+			continue
+		}
+		merged, err := applyProjectConfigLayer(cfg, data)
+		if err == nil {
+			err = merged.Validate()
+		}
+		if err != nil {
+			warnings = append(warnings, i18n.T("%s: %v (已忽略此文件)", path, err))
+			continue
+		}
+		merged.Ignore.Dirs = merged.IgnoreDirs()
+		merged.Ignore.ExtraDirs = nil
+		merged.Sources = append(merged.Sources, path)
+		cfg = merged
+	}
+	return cfg, warnings
+}
+
+// configFileStamp 配置文件的存在性与修改时间，任一变化即重新加载
+type configFileStamp struct {
+	path    string
+	exists  bool
+	modTime time.Time
+	size    int64
+}
+
+type projectConfigCacheEntry struct {
+	stamps   []configFileStamp
+	cfg      *ProjectConfig
+	warnings []string
+}
+
+var (
+	projectConfigMu    sync.Mutex
+	projectConfigCache = make(map[string]*projectConfigCacheEntry)
+)
+
+func stampConfigFiles(projectRoot string) []configFileStamp {
+	paths := configLayerPaths(projectRoot, ProjectConfigFile)
+	stamps := make([]configFileStamp, 0, len(paths))
+	for _, p := range paths {
+		s := configFileStamp{path: p}
+		if info, err := os.Stat(p); err == nil {
+			s.exists, s.modTime, s.size = true, info.ModTime(), info.Size()
+		}
+		stamps = append(stamps, s)
+	}
+	return stamps
+}
+
+func sameConfigStamps(a, b []configFileStamp) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].path != b[i].path || a[i].exists != b[i].exists || a[i].size != b[i].size || !a[i].modTime.Equal(b[i].modTime) {
+			return false
+		}
+	}
+	return true
+}
+
+// ProjectConfigFor 返回项目当前生效的配置；配置文件有变化 (mtime/大小/增删) 时自动重载。
+// 返回的配置为只读共享实例，调用方不要修改。
+func ProjectConfigFor(projectRoot string) (*ProjectConfig, []string) {
+	stamps := stampConfigFiles(projectRoot)
+	projectConfigMu.Lock()
+	defer projectConfigMu.Unlock()
+	if e, ok := projectConfigCache[projectRoot]; ok && sameConfigStamps(e.stamps, stamps) {
+		return e.cfg, e.warnings
+	}
+	cfg, warnings := LoadProjectConfig(projectRoot)
+	projectConfigCache[projectRoot] = &projectConfigCacheEntry{stamps: stamps, cfg: cfg, warnings: warnings}
+	return cfg, warnings
+}
+
+// ReloadProjectConfig 丢弃缓存并重新加载
+func ReloadProjectConfig(projectRoot string) (*ProjectConfig, []string) {
+	projectConfigMu.Lock()
+	delete(projectConfigCache, projectRoot)
+	projectConfigMu.Unlock()
+	return ProjectConfigFor(projectRoot)
+}
+
+// IgnoreDirsFor 项目生效的内置忽略目录 (未配置时即 DefaultIgnoreDirs)
+func IgnoreDirsFor(projectRoot string) []string {
+	if projectRoot == "" {
+		return DefaultIgnoreDirs
+	}
+	cfg, _ := ProjectConfigFor(projectRoot)
+	return cfg.IgnoreDirs()
+}
@@ -0,0 +1,91 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeConfigLayer(t *testing.T, dir, content string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ProjectConfigFile), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadProjectConfigLayers(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("MPM_HOME", home)
+	root := t.TempDir()
+
+	cfg, warnings := LoadProjectConfig(root)
+	if len(warnings) != 0 || cfg.Recall.Limit != 20 || cfg.Map.MaxPageSize != 2000 || len(cfg.IgnoreDirs()) != len(DefaultIgnoreDirs) {
+		t.Fatalf("defaults expected without files: %+v %v", cfg, warnings)
+	}
+
+	writeConfigLayer(t, home, "recall:\n  limit: 50\nanalyze:\n  anchor_limit: 5\nignore:\n  extra_dirs: [generated]\n")
+	writeConfigLayer(t, ProjectConfigDir(root), "version: 1\nrecall:\n  limit: 30\nignore:\n  extra_dirs: [fixtures/]\n")
+	cfg, warnings = LoadProjectConfig(root)
+	if len(warnings) != 0 {
+		t.Fatal(warnings)
+	}
+	if cfg.Recall.Limit != 30 || cfg.Analyze.AnchorLimit != 5 || cfg.Analyze.DirectiveMaxChars != 300 {
+		t.Fatalf("project should override global field by field: %+v", cfg)
+	}
+	dirs := strings.Join(cfg.IgnoreDirs(), ",")
+	if !strings.Contains(dirs, "node_modules") || !strings.HasSuffix(dirs, "generated,fixtures") {
+		t.Fatalf("extra_dirs should accumulate across layers: %s", dirs)
+	}
+	if len(cfg.Sources) != 3 {
+		t.Fatalf("sources should list builtin, global and project: %v", cfg.Sources)
+	}
+
+	// 未知字段与越界取值：整层忽略
+	writeConfigLayer(t, ProjectConfigDir(root), "recal:\n  limit: 30\n")
+	cfg, warnings = LoadProjectConfig(root)
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "recal") || cfg.Recall.Limit != 50 {
+		t.Fatalf("unknown field should skip the layer: %v %+v", warnings, cfg.Recall)
+	}
+	writeConfigLayer(t, ProjectConfigDir(root), "map:\n  page_size: 5000\nwiki:\n  output_dir: ../docs\n")
+	_, warnings = LoadProjectConfig(root)
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "map.page_size (5000)") || !strings.Contains(warnings[0], "wiki.output_dir") {
+		t.Fatalf("out of range values should be reported: %v", warnings)
+	}
+
+	// ignore.dirs 替换内置列表
+	writeConfigLayer(t, ProjectConfigDir(root), "ignore:\n  dirs: [vendor]\n")
+	cfg, _ = LoadProjectConfig(root)
+	if got := strings.Join(cfg.IgnoreDirs(), ","); got != "vendor" {
+		t.Fatalf("dirs should replace the builtin list: %s", got)
+	}
+}
+
+func TestProjectConfigForReloadsOnChange(t *testing.T) {
+	t.Setenv("MPM_HOME", t.TempDir())
+	root := t.TempDir()
+
+	if cfg, _ := ProjectConfigFor(root); cfg.Search.MaxResults != 100 {
+		t.Fatalf("default expected: %+v", cfg.Search)
+	}
+	writeConfigLayer(t, ProjectConfigDir(root), "search:\n  max_results: 7\n")
+	if cfg, _ := ProjectConfigFor(root); cfg.Search.MaxResults != 7 {
+		t.Fatalf("new file should be picked up: %+v", cfg.Search)
+	}
+	writeConfigLayer(t, ProjectConfigDir(root), "search:\n  max_results: 250\n")
+	if cfg, _ := ProjectConfigFor(root); cfg.Search.MaxResults != 250 {
+		t.Fatalf("changed file should be reloaded: %+v", cfg.Search)
+	}
+	writeConfigLayer(t, ProjectConfigDir(root), "ignore:\n  extra_dirs: [snapshots]\n")
+	m := NewIgnoreMatcher(root)
+	if !m.Match("snapshots/a.txt", false) || !m.Match("node_modules/x.js", false) {
+		t.Fatalf("ignore matcher should use configured dirs")
+	}
+	_ = os.Remove(filepath.Join(ProjectConfigDir(root), ProjectConfigFile))
+	if cfg, _ := ProjectConfigFor(root); cfg.Search.MaxResults != 100 || len(cfg.Sources) != 1 {
+		t.Fatalf("removed file should fall back to defaults: %+v", cfg)
+	}
+}
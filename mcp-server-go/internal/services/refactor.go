@@ -0,0 +1,480 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"mcp-server-go/internal/i18n"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ============================================================================
+// 重命名 / 移动预览 (只读，生成 unified diff，不落盘)
+// ============================================================================
+
+const (
+	// MatchCertain AST 确认的引用 (定义 / calls 表中的调用点)
+	MatchCertain = "certain"
+	// MatchPossible 仅文本匹配的引用 (注释、字符串、动态调用、同名符号等)
+	MatchPossible = "possible"
+)
+
+// RefactorRequest 重构预览请求
+type RefactorRequest struct {
+	Symbol      string // 目标符号名
+	File        string // 定义所在文件 (同名符号消歧，可选)
+	NewName     string // 新名称 (重命名)
+	Destination string // 目标文件 (移动)
+	CertainOnly bool   // 补丁只包含 certain 引用
+}
+
+// RefactorMatch 一处引用
+type RefactorMatch struct {
+	FilePath   string `json:"file_path"`
+	LineNumber int    `json:"line_number"`
+	Kind       string `json:"kind"` // definition / call / text
+	Confidence string `json:"confidence"`
+	Content    string `json:"content"`
+}
+
+// RefactorPreview 重构预览结果
+type RefactorPreview struct {
+	Definition Node            `json:"definition"`
+	Matches    []RefactorMatch `json:"matches"`
+	Patch      string          `json:"patch"`
+	Files      []string        `json:"files"` // 补丁涉及的文件
+	Notes      []string        `json:"notes,omitempty"`
+}
+
+// FindDefinitions 按名称查找符号定义 (直接读取 symbols.db)
+func (ai *ASTIndexer) FindDefinitions(projectRoot string, name string) ([]Node, error) {
+	dbPath := getDBPath(projectRoot)
+	if !fileExists(dbPath) {
+		return nil, errors.New(i18n.T("索引不存在，请先执行 initialize_project"))
+	}
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	rows, err := db.Query(`
+		SELECT s.canonical_id, s.name, COALESCE(s.qualified_name, ''), s.symbol_type, f.file_path,
+		       s.line_start, s.line_end, COALESCE(s.signature, '')
+		FROM symbols s JOIN files f ON s.file_id = f.file_id
+		WHERE s.name = ?
+		ORDER BY f.file_path, s.line_start`, name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var nodes []Node
+	for rows.Next() {
+		var n Node
+		if err := rows.Scan(&n.ID, &n.Name, &n.QualifiedName, &n.NodeType, &n.FilePath,
+			&n.LineStart, &n.LineEnd, &n.Signature); err != nil {
+			continue
+		}
+		n.FilePath = filepath.ToSlash(n.FilePath)
+		nodes = append(nodes, n)
+	}
+	return nodes, nil
+}
+
+// findCallLines 查询 calls 表中链接到该定义的调用点 (file -> 行号集合)
+func findCallLines(projectRoot string, canonicalID string) (map[string]map[int]bool, error) {
+	db, err := sql.Open("sqlite", getDBPath(projectRoot))
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	result := make(map[string]map[int]bool)
+	if !hasColumn(db, "calls", "callee_id") {
+		return result, nil
+	}
+
+	rows, err := db.Query(`
+		SELECT f.file_path, c.call_line
+		FROM calls c
+		JOIN symbols s ON c.caller_id = s.symbol_id
+		JOIN files f ON s.file_id = f.file_id
+		WHERE c.callee_id = ?`, canonicalID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var path string
+		var line int
+		if err := rows.Scan(&path, &line); err != nil {
+			continue
+		}
+		path = filepath.ToSlash(path)
+		if result[path] == nil {
+			result[path] = make(map[int]bool)
+		}
+		result[path][line] = true
+	}
+	return result, nil
+}
+
+// PreviewRefactor 生成重命名/移动预览
+func (ai *ASTIndexer) PreviewRefactor(ctx context.Context, projectRoot string, req RefactorRequest) (*RefactorPreview, error) {
+	if req.NewName == "" && req.Destination == "" {
+		return nil, errors.New(i18n.T("new_name 与 destination 至少填写一个"))
+	}
+	if req.NewName != "" && !regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`).MatchString(req.NewName) {
+		return nil, errors.New(i18n.T("new_name 不是合法标识符: %s", req.NewName))
+	}
+
+	def, err := ai.resolveDefinition(projectRoot, req.Symbol, req.File)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &RefactorPreview{Definition: *def}
+
+	// 1. 收集引用：定义 + 调用点 (certain)，文本匹配 (possible)
+	certain := map[string]map[int]string{def.FilePath: {def.LineStart: "definition"}}
+	callLines, err := findCallLines(projectRoot, def.ID)
+	if err != nil {
+		return nil, err
+	}
+	for path, lines := range callLines {
+		if certain[path] == nil {
+			certain[path] = make(map[int]string)
+		}
+		for line := range lines {
+			if _, ok := certain[path][line]; !ok {
+				certain[path][line] = "call"
+			}
+		}
+	}
+
+	wordRe := regexp.MustCompile(`\b` + regexp.QuoteMeta(req.Symbol) + `\b`)
+	textMatches, err := NewRipgrepEngine().Search(ctx, SearchOptions{
+		Query:         req.Symbol,
+		RootPath:      projectRoot,
+		ProjectRoot:   projectRoot,
+		CaseSensitive: true,
+		WordMatch:     true,
+	})
+	if err != nil {
+		return nil, errors.New(i18n.T("文本引用搜索失败: %v", err))
+	}
+	possible := make(map[string]map[int]bool)
+	for _, m := range textMatches {
+		rel := relProjectPath(projectRoot, m.FilePath)
+		if !wordRe.MatchString(m.Content) {
+			continue // 兜底的原生搜索不支持全词匹配
+		}
+		if _, ok := certain[rel][m.LineNumber]; ok {
+			continue
+		}
+		if possible[rel] == nil {
+			possible[rel] = make(map[int]bool)
+		}
+		possible[rel][m.LineNumber] = true
+	}
+
+	// 2. 逐文件生成编辑
+	fileCache := make(map[string][]string)
+	readLines := func(rel string) []string {
+		if lines, ok := fileCache[rel]; ok {
+			return lines
+		}
+		lines := readFileLines(filepath.Join(projectRoot, rel))
+		fileCache[rel] = lines
+		return lines
+	}
+
+	edits := make(map[string][]lineEdit)
+
+	addMatch := func(rel string, line int, kind, confidence string) {
+		lines := readLines(rel)
+		content := ""
+		if line >= 1 && line <= len(lines) {
+			content = strings.TrimSpace(lines[line-1])
+		}
+		preview.Matches = append(preview.Matches, RefactorMatch{
+			FilePath: rel, LineNumber: line, Kind: kind, Confidence: confidence, Content: content,
+		})
+		if req.NewName == "" || (confidence == MatchPossible && req.CertainOnly) {
+			return
+		}
+		if line < 1 || line > len(lines) || !wordRe.MatchString(lines[line-1]) {
+			return
+		}
+		edits[rel] = append(edits[rel], lineEdit{
+			start: line - 1, end: line,
+			repl: []string{wordRe.ReplaceAllString(lines[line-1], req.NewName)},
+		})
+	}
+
+	for _, rel := range sortedKeys(certain) {
+		for _, line := range sortedInts(certain[rel]) {
+			addMatch(rel, line, certain[rel][line], MatchCertain)
+		}
+	}
+	for _, rel := range sortedKeys(possible) {
+		for _, line := range sortedInts(possible[rel]) {
+			addMatch(rel, line, "text", MatchPossible)
+		}
+	}
+
+	// 3. 移动：从源文件剪切定义块 (含紧邻的注释)，追加到目标文件
+	var destAppend []string
+	var dest string
+	destIsNew := false
+	if req.Destination != "" {
+		var err error
+		dest, err = RelativeEditPath(projectRoot, req.Destination)
+		if err != nil || dest == "." {
+			return nil, errors.New(i18n.T("destination 必须是项目内的文件路径: %s", req.Destination))
+		}
+		if dest == def.FilePath {
+			return nil, errors.New(i18n.T("目标文件与定义所在文件相同: %s", dest))
+		}
+
+		lines := readLines(def.FilePath)
+		start, end := definitionBlock(lines, def.LineStart, def.LineEnd)
+		if start >= end {
+			return nil, errors.New(i18n.T("无法读取定义源码: %s L%d-%d", def.FilePath, def.LineStart, def.LineEnd))
+		}
+		block := append([]string(nil), lines[start:end]...)
+
+		// 块内的重命名在移动后的副本上完成，源文件中对应行整体删除
+		var kept []lineEdit
+		for _, e := range edits[def.FilePath] {
+			if e.start >= start && e.start < end {
+				block[e.start-start] = e.repl[0]
+				continue
+			}
+			kept = append(kept, e)
+		}
+		removeEnd := end
+		if removeEnd < len(lines) && strings.TrimSpace(lines[removeEnd]) == "" {
+			removeEnd++ // 一并删除块后的空行
+		}
+		edits[def.FilePath] = append(kept, lineEdit{start: start, end: removeEnd})
+
+		destPath := filepath.Join(projectRoot, dest)
+		destLines := readLines(dest)
+		destIsNew = !fileExists(destPath)
+		if !destIsNew && len(destLines) > 0 && strings.TrimSpace(destLines[len(destLines)-1]) != "" {
+			destAppend = append(destAppend, "")
+		}
+		destAppend = append(destAppend, block...)
+		edits[dest] = append(edits[dest], lineEdit{start: len(destLines), end: len(destLines), repl: destAppend})
+
+		preview.Notes = append(preview.Notes,
+			i18n.T("移动 `%s` (%d 行) 到 `%s`", def.Name, len(block), dest),
+			i18n.T("调用点未改写：跨包/跨模块时请手动补充 import 或包名限定"))
+		if destIsNew {
+			preview.Notes = append(preview.Notes, i18n.T("目标文件不存在，补丁将新建该文件 (需自行补充 package/import 声明)"))
+		}
+	}
+
+	// 4. 生成补丁
+	var patch strings.Builder
+	for _, rel := range sortedKeys(edits) {
+		fileEdits := edits[rel]
+		if len(fileEdits) == 0 {
+			continue
+		}
+		newFile := destIsNew && rel == dest
+		patch.WriteString(UnifiedDiff(rel, readLines(rel), fileEdits, newFile))
+		preview.Files = append(preview.Files, rel)
+	}
+	preview.Patch = patch.String()
+
+	return preview, nil
+}
+
+// resolveDefinition 定位唯一定义，同名多处时要求通过 file 消歧
+func (ai *ASTIndexer) resolveDefinition(projectRoot, name, file string) (*Node, error) {
+	defs, err := ai.FindDefinitions(projectRoot, name)
+	if err != nil {
+		return nil, err
+	}
+	if file != "" {
+		want := filepath.ToSlash(filepath.Clean(file))
+		var filtered []Node
+		for _, d := range defs {
+			if d.FilePath == want || strings.HasSuffix(d.FilePath, "/"+want) {
+				filtered = append(filtered, d)
+			}
+		}
+		defs = filtered
+	}
+
+	switch len(defs) {
+	case 0:
+		return nil, errors.New(i18n.T("未找到符号定义: %s", name))
+	case 1:
+		return &defs[0], nil
+	}
+
+	var locs []string
+	for _, d := range defs {
+		locs = append(locs, fmt.Sprintf("%s:%d", d.FilePath, d.LineStart))
+	}
+	return nil, errors.New(i18n.T("符号 `%s` 有 %d 处定义，请用 file 参数指定: %s", name, len(defs), strings.Join(locs, ", ")))
+}
+
+// definitionBlock 计算定义块范围 (0-based 半开区间)，向上包含紧邻的注释/装饰器行
+func definitionBlock(lines []string, lineStart, lineEnd int) (int, int) {
+	if lineStart < 1 || lineStart > len(lines) {
+		return 0, 0
+	}
+	end := lineEnd
+	if end > len(lines) || end < lineStart {
+		end = len(lines)
+	}
+	start := lineStart - 1
+	for start > 0 {
+		prev := strings.TrimSpace(lines[start-1])
+		if strings.HasPrefix(prev, "//") || strings.HasPrefix(prev, "#") || strings.HasPrefix(prev, "@") ||
+			strings.HasPrefix(prev, "/*") || strings.HasPrefix(prev, "*") {
+			start--
+			continue
+		}
+		break
+	}
+	return start, end
+}
+
+func relProjectPath(projectRoot, path string) string {
+	if filepath.IsAbs(path) {
+		if rel, err := filepath.Rel(projectRoot, path); err == nil {
+			path = rel
+		}
+	}
+	return filepath.ToSlash(path)
+}
+
+// readFileLines 按行读取文件 (不含末尾换行产生的空行)，不存在时返回 nil
+func readFileLines(path string) []string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	content := strings.ReplaceAll(string(data), "\r\n", "\n")
+	content = strings.TrimSuffix(content, "\n")
+	if content == "" {
+		return nil
+	}
+	return strings.Split(content, "\n")
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedInts[V any](m map[int]V) []int {
+	keys := make([]int, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	return keys
+}
+
+// ============================================================================
+// Unified diff 生成
+// ============================================================================
+
+// lineEdit 将旧文件 [start, end) 行替换为 repl (0-based)
+type lineEdit struct {
+	start int
+	end   int
+	repl  []string
+}
+
+const diffContext = 3
+
+// UnifiedDiff 根据行编辑生成 unified diff；newFile 表示文件当前不存在
+func UnifiedDiff(path string, old []string, edits []lineEdit, newFile bool) string {
+	if len(edits) == 0 {
+		return ""
+	}
+	sort.SliceStable(edits, func(i, j int) bool { return edits[i].start < edits[j].start })
+
+	var sb strings.Builder
+	if newFile {
+		sb.WriteString("--- /dev/null\n")
+	} else {
+		sb.WriteString(fmt.Sprintf("--- a/%s\n", path))
+	}
+	sb.WriteString(fmt.Sprintf("+++ b/%s\n", path))
+
+	// 将相距较近的编辑合并为一个 hunk
+	var groups [][]lineEdit
+	for _, e := range edits {
+		n := len(groups)
+		if n > 0 {
+			last := groups[n-1][len(groups[n-1])-1]
+			if e.start-last.end <= 2*diffContext {
+				groups[n-1] = append(groups[n-1], e)
+				continue
+			}
+		}
+		groups = append(groups, []lineEdit{e})
+	}
+
+	offset := 0 // 新文件相对旧文件的行号偏移
+	for _, g := range groups {
+		hunkStart := max(g[0].start-diffContext, 0)
+		hunkEnd := min(g[len(g)-1].end+diffContext, len(old))
+
+		var body strings.Builder
+		oldCount, newCount := 0, 0
+		cursor := hunkStart
+		for _, e := range g {
+			for ; cursor < e.start; cursor++ {
+				body.WriteString(" " + old[cursor] + "\n")
+				oldCount++
+				newCount++
+			}
+			for i := e.start; i < e.end; i++ {
+				body.WriteString("-" + old[i] + "\n")
+				oldCount++
+			}
+			for _, r := range e.repl {
+				body.WriteString("+" + r + "\n")
+				newCount++
+			}
+			cursor = e.end
+		}
+		for ; cursor < hunkEnd; cursor++ {
+			body.WriteString(" " + old[cursor] + "\n")
+			oldCount++
+			newCount++
+		}
+
+		oldStart, newStart := hunkStart+1, hunkStart+1+offset
+		if oldCount == 0 {
+			oldStart--
+		}
+		if newCount == 0 {
+			newStart--
+		}
+		sb.WriteString(fmt.Sprintf("@@ -%d,%d +%d,%d @@\n", oldStart, oldCount, newStart, newCount))
+		sb.WriteString(body.String())
+		offset += newCount - oldCount
+	}
+	return sb.String()
+}
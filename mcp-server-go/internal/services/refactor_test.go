@@ -0,0 +1,172 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setupRefactorProject 构造一个带 symbols.db 的迷你项目
+func setupRefactorProject(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	files := map[string]string{
+		"lib/calc.go": `package lib
+
+// Sum adds numbers.
+func Sum(a, b int) int {
+	return a + b
+}
+`,
+		"main.go": `package main
+
+func run() int {
+	// Sum is used here too
+	return Sum(1, 2)
+}
+`,
+	}
+	for rel, content := range files {
+		path := filepath.Join(root, rel)
+		_ = os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_ = os.MkdirAll(filepath.Join(root, ".mcp-data"), 0755)
+	db, err := sql.Open("sqlite", getDBPath(root))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	for _, stmt := range []string{
+		`CREATE TABLE files (file_id INTEGER PRIMARY KEY, file_path TEXT UNIQUE)`,
+		`CREATE TABLE symbols (symbol_id INTEGER PRIMARY KEY, file_id INTEGER, name TEXT, qualified_name TEXT,
+			canonical_id TEXT, symbol_type TEXT, line_start INTEGER, line_end INTEGER, signature TEXT)`,
+		`CREATE TABLE calls (call_id INTEGER PRIMARY KEY, caller_id INTEGER, callee_name TEXT, call_line INTEGER, callee_id TEXT)`,
+		`INSERT INTO files VALUES (1, 'lib/calc.go'), (2, 'main.go')`,
+		`INSERT INTO symbols VALUES
+			(1, 1, 'Sum', 'Sum', 'func:lib/calc.go::Sum', 'function', 4, 6, 'func Sum(a, b int) int {'),
+			(2, 2, 'run', 'run', 'func:main.go::run', 'function', 3, 6, 'func run() int {')`,
+		`INSERT INTO calls VALUES (1, 2, 'Sum', 5, 'func:lib/calc.go::Sum')`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("setup %q: %v", stmt, err)
+		}
+	}
+	return root
+}
+
+func gitApplyCheck(t *testing.T, root, patch string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	cmd := exec.Command("git", "apply", "--check", "-")
+	cmd.Dir = root
+	cmd.Stdin = strings.NewReader(patch)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("patch does not apply: %v\n%s\n%s", err, out, patch)
+	}
+}
+
+func TestPreviewRefactorRename(t *testing.T) {
+	root := setupRefactorProject(t)
+	ai := &ASTIndexer{}
+
+	p, err := ai.PreviewRefactor(context.Background(), root, RefactorRequest{Symbol: "Sum", NewName: "Add"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := map[string]string{}
+	for _, m := range p.Matches {
+		got[fmt.Sprintf("%s:%d", m.FilePath, m.LineNumber)] = m.Confidence
+	}
+	want := map[string]string{
+		"lib/calc.go:4": MatchCertain,  // 定义
+		"main.go:5":     MatchCertain,  // 调用点
+		"lib/calc.go:3": MatchPossible, // 注释
+		"main.go:4":     MatchPossible, // 注释
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("match %s = %q, want %q (all: %v)", k, got[k], v, got)
+		}
+	}
+
+	if !strings.Contains(p.Patch, "+func Add(a, b int) int {") || !strings.Contains(p.Patch, "+\treturn Add(1, 2)") {
+		t.Fatalf("unexpected patch:\n%s", p.Patch)
+	}
+	gitApplyCheck(t, root, p.Patch)
+
+	// certain_only 时注释不改写
+	p, err = ai.PreviewRefactor(context.Background(), root, RefactorRequest{Symbol: "Sum", NewName: "Add", CertainOnly: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(p.Patch, "// Add") {
+		t.Fatalf("certain_only patch rewrote a comment:\n%s", p.Patch)
+	}
+}
+
+func TestPreviewRefactorMove(t *testing.T) {
+	root := setupRefactorProject(t)
+	ai := &ASTIndexer{}
+
+	p, err := ai.PreviewRefactor(context.Background(), root, RefactorRequest{Symbol: "Sum", Destination: "lib/math.go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(p.Patch, "--- /dev/null\n+++ b/lib/math.go") || !strings.Contains(p.Patch, "-// Sum adds numbers.") {
+		t.Fatalf("unexpected move patch:\n%s", p.Patch)
+	}
+	gitApplyCheck(t, root, p.Patch)
+
+	// 绝对路径与 \ 分隔的路径规整为项目内相对路径
+	for _, dest := range []string{filepath.Join(root, "lib", "math.go"), `lib\math.go`} {
+		p, err := ai.PreviewRefactor(context.Background(), root, RefactorRequest{Symbol: "Sum", Destination: dest})
+		if err != nil || !strings.Contains(p.Patch, "+++ b/lib/math.go") {
+			t.Fatalf("destination %q: %v\n%v", dest, err, p)
+		}
+	}
+	for _, dest := range []string{"..", "../math.go", `lib\..\..\math.go`, ".", filepath.Join(filepath.Dir(root), "math.go")} {
+		if _, err := ai.PreviewRefactor(context.Background(), root, RefactorRequest{Symbol: "Sum", Destination: dest}); err == nil {
+			t.Errorf("destination %q outside the project should be rejected", dest)
+		}
+	}
+}
+
+func TestUnifiedDiffHunks(t *testing.T) {
+	old := []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k", "l"}
+	diff := UnifiedDiff("x.txt", old, []lineEdit{
+		{start: 1, end: 2, repl: []string{"B"}},
+		{start: 10, end: 11},
+	}, false)
+
+	want := `--- a/x.txt
++++ b/x.txt
+@@ -1,5 +1,5 @@
+ a
+-b
++B
+ c
+ d
+ e
+@@ -8,5 +8,4 @@
+ h
+ i
+ j
+-k
+ l
+`
+	if diff != want {
+		t.Fatalf("UnifiedDiff() =\n%s\nwant\n%s", diff, want)
+	}
+}
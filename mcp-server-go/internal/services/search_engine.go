@@ -5,10 +5,15 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"mcp-server-go/internal/i18n"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
 	"time"
 )
@@ -25,10 +30,23 @@ func NewRipgrepEngine() *RipgrepEngine {
 	return &RipgrepEngine{BinPath: "rg"}
 }
 
+// commandNotFoundMarkers 表示 rg 不存在的启动错误片段 (中文 Windows 为 "无法将…识别为…")
+var commandNotFoundMarkers = []string{"executable file not found", "无法将"}
+
+func isCommandNotFound(err error) bool {
+	for _, marker := range commandNotFoundMarkers {
+		if strings.Contains(err.Error(), marker) {
+			return true
+		}
+	}
+	return false
+}
+
 // SearchOptions 搜索选项
 type SearchOptions struct {
 	Query          string   // 搜索关键词
-	RootPath       string   // 搜索根目录
+	RootPath       string   // 搜索根目录 (可以是项目内的子目录或文件)
+	ProjectRoot    string   // 项目根：.gitignore / .mpmignore 与 mpm.yaml 忽略目录按它解析，为空时取 RootPath
 	IsRegex        bool     // 是否正则
 	CaseSensitive  bool     // 是否区分大小写
 	WordMatch      bool     // 是否全词匹配
@@ -36,7 +54,9 @@ type SearchOptions struct {
 	IncludePattern []string // 包含的文件 glob (e.g. "*.go")
 	IgnorePattern  []string // 忽略的文件 glob
 	ContextLines   int      // 上下文行数
-	MaxCount       int      // 最大结果数
+	MaxCount       int      // 最大结果数 (rg 为每个文件的上限)
+	MaxResults     int      // 总结果上限：达到后停止读取并结束 rg (0=不限)
+	Multiline      bool     // 跨行匹配 (正则中 . 可匹配换行)
 }
 
 // TextMatch 代表一个文本匹配项
@@ -87,6 +107,56 @@ func (e *RipgrepEngine) Search(ctx context.Context, opts SearchOptions) ([]TextM
 		return nil, fmt.Errorf("root path is required")
 	}
 
+	matcher := NewIgnoreMatcher(opts.ignoreRoot())
+	args := rgArgs(opts, matcher)
+
+	// 设置超时；达到 MaxResults 后通过 cancel 提前结束 rg
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// 不设 Dir，直接对 RootPath 搜索 (假设 RootPath 是绝对路径)
+	cmd := exec.CommandContext(ctx, e.BinPath, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		// 如果是命令找不到，执行 Native Fallback
+		if isCommandNotFound(err) {
+			return e.nativeSearch(ctx, opts)
+		}
+		return nil, fmt.Errorf("ripgrep failed: %v", err)
+	}
+
+	// 边读边过滤：.gitignore / .mpmignore 的完整语义由 IgnoreMatcher 补齐
+	matches, limited := e.parseStream(stdout, opts.ContextLines, ignoreFilter(matcher), opts.MaxResults)
+	if limited {
+		cancel()
+	}
+	_, _ = io.Copy(io.Discard, stdout)
+	err = cmd.Wait()
+	if limited {
+		return matches, nil
+	}
+	if err != nil {
+		// rg 返回 1 表示没找到，不是错误
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+			return []TextMatch{}, nil
+		}
+		return nil, fmt.Errorf("ripgrep failed: %v, stderr: %s", err, stderr.String())
+	}
+	return matches, nil
+}
+
+// rgArgs 构建 rg 命令行参数
+func rgArgs(opts SearchOptions, matcher *IgnoreMatcher) []string {
 	args := []string{"--json"} // 强制 JSON 输出
 
 	if !opts.CaseSensitive {
@@ -101,22 +171,21 @@ func (e *RipgrepEngine) Search(ctx context.Context, opts SearchOptions) ([]TextM
 	if opts.ContextLines > 0 {
 		args = append(args, fmt.Sprintf("-C%d", opts.ContextLines))
 	}
-	if opts.MaxCount > 0 {
-		args = append(args, fmt.Sprintf("-m%d", opts.MaxCount))
+	if perFile := opts.perFileLimit(); perFile > 0 {
+		args = append(args, fmt.Sprintf("-m%d", perFile))
 	}
-
-	// 排除常见干扰项
-	// 默认排除 .git, node_modules 等 (rg 默认会处理 .gitignore)
-	// 这里添加额外的强制排除
-	defaultIgnores := []string{
-		".git", ".svn", ".hg", "node_modules", "dist", "build", "target", "vendor",
-		".idea", ".vscode", "__pycache__", ".venv", "venv",
-		".mcp-data", // 🆕 排除 MPM 缓存目录，避免搜索到临时文件
-		"*.lock", "*.log", "*.map", "*.min.js", "*.min.css",
+	if opts.Multiline {
+		args = append(args, "-U", "--multiline-dotall")
 	}
+
+	// 排除常见干扰项：内置目录与 IgnoreMatcher 共用同一份列表，被重新纳入的目录名不排除；
+	// .gitignore / .mpmignore 的完整语义在结果返回后统一过滤
+	defaultIgnores := append(sortedKeys(alwaysIgnoredDirs), matcher.BlanketIgnoreDirs(IgnoreDirsFor(opts.ignoreRoot()))...)
+	defaultIgnores = append(defaultIgnores, "*.lock", "*.log", "*.map", "*.min.js", "*.min.css")
 	for _, ignore := range defaultIgnores {
 		args = append(args, "-g", "!"+ignore)
 	}
+	args = append(args, "--no-require-git")
 
 	// 用户自定义忽略
 	for _, ignore := range opts.IgnorePattern {
@@ -135,113 +204,144 @@ func (e *RipgrepEngine) Search(ctx context.Context, opts SearchOptions) ([]TextM
 		args = append(args, "-g", "*."+ext)
 	}
 
-	// 目标
-	args = append(args, opts.Query)
+	// 目标 (-e 防止以 - 开头的查询被当作参数)
+	args = append(args, "-e", opts.Query)
 	args = append(args, opts.RootPath)
+	return args
+}
 
-	cmd := exec.CommandContext(ctx, e.BinPath, args...)
-
-	// 不设 Dir，直接对 RootPath 搜索。但如果 RootPath 是相对路径，可能需要
-	// cmd.Dir = opts.RootPath // 暂不设置，假设 RootPath 是绝对路径
-
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
+// perFileLimit rg -m 的取值：每个文件的匹配数不会超过总上限
+func (opts SearchOptions) perFileLimit() int {
+	if opts.MaxResults > 0 && (opts.MaxCount <= 0 || opts.MaxCount > opts.MaxResults) {
+		return opts.MaxResults
+	}
+	return opts.MaxCount
+}
 
-	// 设置超时
-	if _, ok := ctx.Deadline(); !ok {
-		var cancel context.CancelFunc
-		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
-		defer cancel()
-		cmd = exec.CommandContext(ctx, e.BinPath, args...)
-		cmd.Stdout = &stdout
-		cmd.Stderr = &stderr
+// ignoreRoot 构建 IgnoreMatcher 的根目录：限定 scope 搜索时仍需加载项目根的 ignore 文件，
+// 锚定规则 (如 "/build") 也要相对项目根解析
+func (opts SearchOptions) ignoreRoot() string {
+	if opts.ProjectRoot != "" {
+		return opts.ProjectRoot
 	}
+	return opts.RootPath
+}
 
-	err := cmd.Run()
-	if err != nil {
-		// rg 返回 1 表示没找到，不是错误
-		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
-			return []TextMatch{}, nil
-		}
-		// 如果是命令找不到，执行 Native Fallback
-		if strings.Contains(err.Error(), "executable file not found") || strings.Contains(err.Error(), "无法将") {
-			return e.nativeSearch(ctx, opts)
+// ignoreFilter 返回判断 rg 结果是否保留的函数，补齐 rg 不认识的 .mpmignore 等规则
+func ignoreFilter(matcher *IgnoreMatcher) func(TextMatch) bool {
+	return func(m TextMatch) bool {
+		p := filepath.FromSlash(m.FilePath)
+		if abs, err := filepath.Abs(p); err == nil {
+			p = abs // rg 输出的路径以 RootPath 为前缀，相对路径需按 cwd 展开
 		}
-		return nil, fmt.Errorf("ripgrep failed: %v, stderr: %s", err, stderr.String())
+		return !matcher.MatchPath(p, false)
 	}
+}
 
-	return e.parseOutput(stdout.Bytes())
+// filterIgnoredMatches 用 IgnoreMatcher 过滤 rg 结果
+func filterIgnoredMatches(root string, matches []TextMatch) []TextMatch {
+	keep := ignoreFilter(NewIgnoreMatcher(root))
+	filtered := matches[:0]
+	for _, m := range matches {
+		if keep(m) {
+			filtered = append(filtered, m)
+		}
+	}
+	return filtered
 }
 
-// nativeSearch 使用 Go 原生 遍历进行简单搜索 (兜底方案)
+// nativeSearch 使用 Go 原生 遍历进行搜索 (兜底方案)
+// 与 rg 参数语义保持一致：正则/大小写/全词/多行/glob/上下文
 func (e *RipgrepEngine) nativeSearch(ctx context.Context, opts SearchOptions) ([]TextMatch, error) {
+	re, err := compileSearchPattern(opts)
+	if err != nil {
+		return nil, err
+	}
+
 	var results []TextMatch
 	root := opts.RootPath
-	query := opts.Query
-	if !opts.CaseSensitive {
-		query = strings.ToLower(query)
+	if abs, err := filepath.Abs(root); err == nil {
+		root = abs // 以绝对路径遍历，IgnoreMatcher 才能换算出相对项目根的路径
 	}
+	matcher := NewIgnoreMatcher(opts.ignoreRoot())
 
-	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+	err = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return nil // 跳过错误
 		}
+		if path == root {
+			return nil
+		}
 		if info.IsDir() {
-			// 简单忽略常见目录
-			name := info.Name()
-			if name == ".git" || name == "node_modules" || name == "vendor" || name == "target" || name == "build" || name == ".mcp-data" {
+			if matcher.MatchPath(path, true) {
 				return filepath.SkipDir
 			}
 			return nil
 		}
 
-		// 检查扩展名
-		if len(opts.Extensions) > 0 {
-			ext := filepath.Ext(path)
-			matched := false
-			for _, e := range opts.Extensions {
-				if strings.EqualFold(ext, "."+strings.TrimPrefix(e, ".")) {
-					matched = true
-					break
-				}
-			}
-			if !matched {
-				return nil
-			}
+		if matcher.MatchPath(path, false) || !nativeFileSelected(root, path, opts) {
+			return nil
 		}
 
-		// 读取文件内容进行简单搜索
+		// 读取文件内容进行搜索
 		data, err := os.ReadFile(path)
-		if err != nil {
-			return nil
+		if err != nil || bytes.IndexByte(data, 0) >= 0 {
+			return nil // 跳过二进制文件
 		}
 
-		content := string(data)
+		content := strings.ReplaceAll(string(data), "\r\n", "\n")
 		lines := strings.Split(content, "\n")
-		for i, line := range lines {
-			displayLine := line
-			if !opts.CaseSensitive {
-				line = strings.ToLower(line)
-			}
 
-			if strings.Contains(line, query) {
-				results = append(results, TextMatch{
+		var fileMatches []TextMatch
+		if opts.Multiline {
+			lineStarts := []int{0}
+			for i, c := range content {
+				if c == '\n' {
+					lineStarts = append(lineStarts, i+1)
+				}
+			}
+			for _, loc := range re.FindAllStringIndex(content, -1) {
+				first := sort.SearchInts(lineStarts, loc[0]+1) - 1
+				last := first
+				if loc[1] > loc[0] {
+					last = sort.SearchInts(lineStarts, loc[1]) - 1
+				}
+				fileMatches = append(fileMatches, TextMatch{
 					FilePath:   filepath.ToSlash(path),
-					LineNumber: i + 1,
-					Content:    strings.TrimSpace(displayLine),
+					LineNumber: first + 1,
+					Content:    strings.Join(lines[first:last+1], "\n"),
 				})
-				if opts.MaxCount > 0 && len(results) >= opts.MaxCount {
-					return fmt.Errorf("limit reached")
+			}
+		} else {
+			for i, line := range lines {
+				if re.MatchString(line) {
+					fileMatches = append(fileMatches, TextMatch{
+						FilePath:   filepath.ToSlash(path),
+						LineNumber: i + 1,
+						Content:    line,
+					})
 				}
 			}
+		}
 
-			// 检查 Context 超时
-			select {
-			case <-ctx.Done():
-				return ctx.Err()
-			default:
+		for _, m := range fileMatches {
+			if opts.ContextLines > 0 {
+				from := max(m.LineNumber-1-opts.ContextLines, 0)
+				to := min(m.LineNumber-1+strings.Count(m.Content, "\n")+1+opts.ContextLines, len(lines))
+				m.ContextBefore = strings.Join(lines[from:m.LineNumber-1], "\n")
+				m.ContextAfter = strings.Join(lines[m.LineNumber+strings.Count(m.Content, "\n"):to], "\n")
 			}
+			results = append(results, m)
+			if limit := opts.nativeLimit(); limit > 0 && len(results) >= limit {
+				return fmt.Errorf("limit reached")
+			}
+		}
+
+		// 检查 Context 超时
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
 		}
 
 		return nil
@@ -254,14 +354,112 @@ func (e *RipgrepEngine) nativeSearch(ctx context.Context, opts SearchOptions) ([
 	return results, nil
 }
 
+// nativeLimit 原生搜索的总结果上限 (MaxCount 与 MaxResults 取较小者)
+func (opts SearchOptions) nativeLimit() int {
+	if opts.MaxResults > 0 && (opts.MaxCount <= 0 || opts.MaxCount > opts.MaxResults) {
+		return opts.MaxResults
+	}
+	return opts.MaxCount
+}
+
+// compileSearchPattern 将搜索选项转换为 Go 正则
+func compileSearchPattern(opts SearchOptions) (*regexp.Regexp, error) {
+	pattern := opts.Query
+	if !opts.IsRegex {
+		pattern = regexp.QuoteMeta(pattern)
+	}
+	if opts.WordMatch {
+		pattern = `\b(?:` + pattern + `)\b`
+	}
+	flags := ""
+	if !opts.CaseSensitive {
+		flags += "i"
+	}
+	if opts.Multiline {
+		flags += "s"
+	}
+	if flags != "" {
+		pattern = "(?" + flags + ")" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, errors.New(i18n.T("无效的正则表达式: %v", err))
+	}
+	return re, nil
+}
+
+// nativeFileSelected 按扩展名与 include/ignore glob 过滤文件
+func nativeFileSelected(root, path string, opts SearchOptions) bool {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		rel = path
+	}
+	rel = filepath.ToSlash(rel)
+	base := filepath.Base(path)
+
+	globMatch := func(pattern string) bool {
+		pattern = filepath.ToSlash(pattern)
+		if ok, _ := filepath.Match(pattern, base); ok {
+			return true
+		}
+		ok, _ := filepath.Match(pattern, rel)
+		return ok
+	}
+
+	if len(opts.Extensions) > 0 {
+		ext := filepath.Ext(path)
+		matched := false
+		for _, e := range opts.Extensions {
+			if strings.EqualFold(ext, "."+strings.TrimPrefix(e, ".")) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	if len(opts.IncludePattern) > 0 {
+		matched := false
+		for _, p := range opts.IncludePattern {
+			if globMatch(p) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	for _, p := range opts.IgnorePattern {
+		if globMatch(p) {
+			return false
+		}
+	}
+	return true
+}
+
 // parseOutput 解析 JSON 输出
-func (e *RipgrepEngine) parseOutput(output []byte) ([]TextMatch, error) {
-	scanner := bufio.NewScanner(bytes.NewReader(output))
+func (e *RipgrepEngine) parseOutput(output []byte, contextLines int) ([]TextMatch, error) {
+	matches, _ := e.parseStream(bytes.NewReader(output), contextLines, nil, 0)
+	return matches, nil
+}
+
+// parseStream 逐行解析 rg --json 输出。
+// rg --json 的上下文行以独立的 context 消息出现 (Context -> Match -> Context)，
+// 紧跟在匹配之后的归入上一个匹配的 ContextAfter，其余的暂存为下一个匹配的 ContextBefore。
+// keep 为 nil 时保留全部匹配；limit > 0 时收满 limit 条后在下一个匹配或文件边界处停止读取，
+// 第二个返回值表示是否因此提前结束
+func (e *RipgrepEngine) parseStream(r io.Reader, contextLines int, keep func(TextMatch) bool, limit int) ([]TextMatch, bool) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
 	var results []TextMatch
 
-	// 暂存 context，rg json 的 context 是分开的消息
-	// 目前简化处理，只提取 match 类型的行
-	// TODO: 完整支持 context (rg 输出顺序是 Context -> Match -> Context)
+	var pendingBefore []string
+	lastIdx := -1 // 当前文件中上一个匹配在 results 中的下标
+	lastEnd := 0  // 上一个匹配的最后一行行号
 
 	for scanner.Scan() {
 		line := scanner.Bytes()
@@ -270,7 +468,35 @@ func (e *RipgrepEngine) parseOutput(output []byte) ([]TextMatch, error) {
 			continue // 忽略解析错误行
 		}
 
-		if msg.Type == "match" {
+		full := limit > 0 && len(results) >= limit
+		switch msg.Type {
+		case "begin", "end":
+			if full {
+				return results, true
+			}
+			pendingBefore = nil
+			lastIdx = -1
+
+		case "context":
+			var ctxData RgMatchData
+			if err := json.Unmarshal(msg.Data, &ctxData); err != nil {
+				continue
+			}
+			text := strings.TrimRight(ctxData.Lines.Text, "\r\n")
+			if lastIdx >= 0 && ctxData.LineNumber > lastEnd && ctxData.LineNumber <= lastEnd+contextLines {
+				m := &results[lastIdx]
+				if m.ContextAfter != "" {
+					m.ContextAfter += "\n"
+				}
+				m.ContextAfter += text
+			} else {
+				pendingBefore = append(pendingBefore, text)
+			}
+
+		case "match":
+			if full {
+				return results, true
+			}
 			var matchData RgMatchData
 			if err := json.Unmarshal(msg.Data, &matchData); err != nil {
 				continue
@@ -285,18 +511,26 @@ func (e *RipgrepEngine) parseOutput(output []byte) ([]TextMatch, error) {
 			// 修正 windows 路径分割符
 			cleanPath := strings.ReplaceAll(matchData.Path.Text, "\\", "/")
 
-			// 简单的内容去空白 (display friendly)
-			// 实际上 rg --json 返回的是包含换行符的完整行
+			// rg --json 返回的是包含换行符的完整行 (多行匹配时为多行)
 			content := strings.TrimRight(matchData.Lines.Text, "\r\n")
 
-			results = append(results, TextMatch{
-				FilePath:   cleanPath,
-				LineNumber: matchData.LineNumber,
-				Content:    content,
-				Submatches: subs,
-			})
+			m := TextMatch{
+				FilePath:      cleanPath,
+				LineNumber:    matchData.LineNumber,
+				Content:       content,
+				ContextBefore: strings.Join(pendingBefore, "\n"),
+				Submatches:    subs,
+			}
+			pendingBefore = nil
+			if keep != nil && !keep(m) {
+				lastIdx = -1
+				continue
+			}
+			results = append(results, m)
+			lastIdx = len(results) - 1
+			lastEnd = matchData.LineNumber + strings.Count(content, "\n")
 		}
 	}
 
-	return results, nil
+	return results, false
 }
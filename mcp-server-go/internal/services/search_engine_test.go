@@ -0,0 +1,180 @@
+package services
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseOutputContext(t *testing.T) {
+	output := strings.Join([]string{
+		`{"type":"begin","data":{"path":{"text":"a.go"}}}`,
+		`{"type":"context","data":{"path":{"text":"a.go"},"lines":{"text":"line1\n"},"line_number":1}}`,
+		`{"type":"match","data":{"path":{"text":"a.go"},"lines":{"text":"hit2\n"},"line_number":2,"submatches":[{"match":{"text":"hit"},"start":0,"end":3}]}}`,
+		`{"type":"context","data":{"path":{"text":"a.go"},"lines":{"text":"line3\n"},"line_number":3}}`,
+		`{"type":"context","data":{"path":{"text":"a.go"},"lines":{"text":"line5\n"},"line_number":5}}`,
+		`{"type":"match","data":{"path":{"text":"a.go"},"lines":{"text":"hit6\n"},"line_number":6,"submatches":[]}}`,
+		`{"type":"end","data":{}}`,
+	}, "\n")
+
+	got, err := (&RipgrepEngine{}).parseOutput([]byte(output), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d matches, want 2", len(got))
+	}
+	if got[0].ContextBefore != "line1" || got[0].ContextAfter != "line3" {
+		t.Fatalf("first match context = %q / %q", got[0].ContextBefore, got[0].ContextAfter)
+	}
+	if got[1].ContextBefore != "line5" || got[1].ContextAfter != "" {
+		t.Fatalf("second match context = %q / %q", got[1].ContextBefore, got[1].ContextAfter)
+	}
+	if len(got[0].Submatches) != 2 || got[0].Submatches[1] != 3 {
+		t.Fatalf("submatches = %v", got[0].Submatches)
+	}
+}
+
+// endlessReader 模拟仍在输出的 rg：被读取即说明没有在达到上限后停止
+type endlessReader struct{ t *testing.T }
+
+func (r endlessReader) Read(p []byte) (int, error) {
+	r.t.Fatal("output was read past the result limit")
+	return 0, io.EOF
+}
+
+func TestParseStreamStopsAtLimit(t *testing.T) {
+	head := strings.Join([]string{
+		`{"type":"begin","data":{"path":{"text":"skip.go"}}}`,
+		`{"type":"match","data":{"path":{"text":"skip.go"},"lines":{"text":"hit\n"},"line_number":1}}`,
+		`{"type":"end","data":{}}`,
+		`{"type":"begin","data":{"path":{"text":"a.go"}}}`,
+		`{"type":"match","data":{"path":{"text":"a.go"},"lines":{"text":"hit1\n"},"line_number":1}}`,
+		`{"type":"match","data":{"path":{"text":"a.go"},"lines":{"text":"hit2\n"},"line_number":2}}`,
+		`{"type":"context","data":{"path":{"text":"a.go"},"lines":{"text":"after\n"},"line_number":3}}`,
+		`{"type":"match","data":{"path":{"text":"a.go"},"lines":{"text":"hit4\n"},"line_number":4}}`,
+		"",
+	}, "\n")
+	keep := func(m TextMatch) bool { return m.FilePath != "skip.go" }
+
+	got, limited := (&RipgrepEngine{}).parseStream(io.MultiReader(strings.NewReader(head), endlessReader{t}), 1, keep, 2)
+	if !limited || len(got) != 2 {
+		t.Fatalf("expected to stop after 2 kept matches, got %d (limited=%v)", len(got), limited)
+	}
+	if got[0].FilePath != "a.go" || got[1].ContextAfter != "after" {
+		t.Fatalf("unexpected matches: %+v", got)
+	}
+}
+
+func TestNativeSearchOptions(t *testing.T) {
+	root := t.TempDir()
+	write := func(rel, content string) {
+		path := filepath.Join(root, rel)
+		_ = os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("main.go", "package main\n\nfunc Load() {\n\tloadAll()\n}\n")
+	write("main_test.go", "package main\n\n// Load test\n")
+	write("notes.md", "Load the thing\n")
+
+	e := &RipgrepEngine{}
+	search := func(opts SearchOptions) []TextMatch {
+		t.Helper()
+		opts.RootPath = root
+		got, err := e.nativeSearch(context.Background(), opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return got
+	}
+
+	if got := search(SearchOptions{Query: "load"}); len(got) != 4 {
+		t.Fatalf("case-insensitive search = %d matches, want 4", len(got))
+	}
+	if got := search(SearchOptions{Query: "Load", CaseSensitive: true, WordMatch: true, Extensions: []string{"go"}}); len(got) != 2 {
+		t.Fatalf("word/extension search = %d matches, want 2", len(got))
+	}
+	if got := search(SearchOptions{Query: "Load", CaseSensitive: true, Extensions: []string{"go"}, IgnorePattern: []string{"*_test.go"}}); len(got) != 1 {
+		t.Fatalf("ignore glob search = %d matches, want 1", len(got))
+	}
+	if got := search(SearchOptions{Query: `func \w+`, IsRegex: true, CaseSensitive: true, ContextLines: 1}); len(got) != 1 ||
+		got[0].ContextBefore != "" || got[0].ContextAfter != "\tloadAll()" {
+		t.Fatalf("regex context search = %+v", got)
+	}
+
+	got := search(SearchOptions{Query: `Load\(\) \{.*?loadAll`, IsRegex: true, CaseSensitive: true, Multiline: true})
+	if len(got) != 1 || got[0].LineNumber != 3 || !strings.Contains(got[0].Content, "\n\tloadAll()") {
+		t.Fatalf("multiline search = %+v", got)
+	}
+}
+
+func TestEnclosingSymbol(t *testing.T) {
+	nodes := []Node{
+		{Name: "Outer", LineStart: 1, LineEnd: 20},
+		{Name: "inner", LineStart: 5, LineEnd: 8},
+	}
+	if n := EnclosingSymbol(nodes, 6); n == nil || n.Name != "inner" {
+		t.Fatalf("EnclosingSymbol(6) = %v", n)
+	}
+	if n := EnclosingSymbol(nodes, 12); n == nil || n.Name != "Outer" {
+		t.Fatalf("EnclosingSymbol(12) = %v", n)
+	}
+	if n := EnclosingSymbol(nodes, 30); n != nil {
+		t.Fatalf("EnclosingSymbol(30) = %v, want nil", n)
+	}
+}
+
+func TestScopedSearchUsesProjectIgnores(t *testing.T) {
+	root := t.TempDir()
+	write := func(rel, content string) {
+		path := filepath.Join(root, rel)
+		_ = os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(".gitignore", "/generated\ndocs/gen/\n")
+	write("generated/a.txt", "needle\n")
+	write("sub/generated/b.txt", "needle\n")
+	write("docs/gen/c.txt", "needle\n")
+	write("docs/d.txt", "needle\n")
+
+	e := &RipgrepEngine{}
+	files := func(scope string) []string {
+		t.Helper()
+		got, err := e.nativeSearch(context.Background(), SearchOptions{Query: "needle", RootPath: filepath.Join(root, scope), ProjectRoot: root})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var out []string
+		for _, m := range got {
+			rel, _ := filepath.Rel(root, filepath.FromSlash(m.FilePath))
+			out = append(out, filepath.ToSlash(rel))
+		}
+		return out
+	}
+
+	if got := files("docs"); len(got) != 1 || got[0] != "docs/d.txt" {
+		t.Fatalf("root .gitignore must apply to scoped searches: %v", got)
+	}
+	if got := files("sub"); len(got) != 1 || got[0] != "sub/generated/b.txt" {
+		t.Fatalf("anchored rules resolve against the project root: %v", got)
+	}
+	if got := files("generated"); len(got) != 0 {
+		t.Fatalf("ignored scope should yield nothing: %v", got)
+	}
+
+	// rg 结果的过滤同样以项目根为准
+	matches := []TextMatch{
+		{FilePath: filepath.ToSlash(filepath.Join(root, "docs", "gen", "c.txt"))},
+		{FilePath: filepath.ToSlash(filepath.Join(root, "docs", "d.txt"))},
+	}
+	if got := filterIgnoredMatches(root, matches); len(got) != 1 || !strings.HasSuffix(got[0].FilePath, "docs/d.txt") {
+		t.Fatalf("filterIgnoredMatches = %+v", got)
+	}
+}
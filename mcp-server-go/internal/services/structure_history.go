@@ -0,0 +1,666 @@
+package services
+
+import (
+	"crypto/sha1"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mcp-server-go/internal/i18n"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ============================================================================
+// 结构快照 (.mcp-data/metrics_history.db)
+// 每次 initialize_project 记录一份符号集合，用于回答"上次之后改了什么"
+// ============================================================================
+
+// maxStructureSnapshots 最多保留的结构快照数
+const maxStructureSnapshots = 50
+
+// StructureSnapshot 一次初始化时的符号集合
+type StructureSnapshot struct {
+	ID           int64  `json:"id"`
+	CreatedAt    string `json:"created_at"`
+	GitRevision  string `json:"git_revision,omitempty"`
+	TotalFiles   int    `json:"total_files"`
+	TotalSymbols int    `json:"total_symbols"`
+}
+
+// StructureSymbol 快照中的单个符号
+type StructureSymbol struct {
+	CanonicalID   string `json:"canonical_id"`
+	Name          string `json:"name"`
+	QualifiedName string `json:"qualified_name"`
+	FilePath      string `json:"file_path"`
+	SymbolType    string `json:"symbol_type"`
+	Signature     string `json:"signature,omitempty"`
+	LineStart     int    `json:"line_start"`
+}
+
+// Public 按语言惯例粗略判断是否为公开符号
+func (s StructureSymbol) Public() bool {
+	return IsPublicSymbol(s.FilePath, s.Name, s.Signature)
+}
+
+// SymbolMove 跨文件移动的符号
+type SymbolMove struct {
+	Symbol   StructureSymbol `json:"symbol"`
+	FromFile string          `json:"from_file"`
+}
+
+// SignatureChange 签名变化的符号
+type SignatureChange struct {
+	Symbol StructureSymbol `json:"symbol"`
+	Before string          `json:"before"`
+	After  string          `json:"after"`
+}
+
+// ModuleChange 目录级变化
+type ModuleChange struct {
+	Dir          string `json:"dir"`
+	Status       string `json:"status"` // added / removed / changed
+	FilesAdded   int    `json:"files_added"`
+	FilesRemoved int    `json:"files_removed"`
+	Added        int    `json:"added"`
+	Removed      int    `json:"removed"`
+	Moved        int    `json:"moved"`
+	Changed      int    `json:"changed"`
+}
+
+// StructureDiff 快照与当前索引之间的结构差异
+type StructureDiff struct {
+	From             StructureSnapshot `json:"from"`
+	Scope            string            `json:"scope,omitempty"`
+	PublicOnly       bool              `json:"public_only"`
+	AddedFiles       []string          `json:"added_files"`
+	RemovedFiles     []string          `json:"removed_files"`
+	Added            []StructureSymbol `json:"added"`
+	Removed          []StructureSymbol `json:"removed"`
+	Moved            []SymbolMove      `json:"moved"`
+	SignatureChanged []SignatureChange `json:"signature_changed"`
+	Modules          []ModuleChange    `json:"modules"`
+}
+
+// Empty 是否没有任何变化
+func (d *StructureDiff) Empty() bool {
+	return len(d.AddedFiles)+len(d.RemovedFiles)+len(d.Added)+len(d.Removed)+len(d.Moved)+len(d.SignatureChanged) == 0
+}
+
+const structureHistorySchema = `
+CREATE TABLE IF NOT EXISTS structure_snapshots (
+	snapshot_id INTEGER PRIMARY KEY AUTOINCREMENT,
+	created_at TEXT NOT NULL,
+	git_revision TEXT,
+	fingerprint TEXT NOT NULL,
+	total_files INTEGER NOT NULL DEFAULT 0,
+	total_symbols INTEGER NOT NULL DEFAULT 0
+);
+CREATE TABLE IF NOT EXISTS structure_files (
+	snapshot_id INTEGER NOT NULL,
+	file_path TEXT NOT NULL,
+	PRIMARY KEY (snapshot_id, file_path)
+);
+CREATE TABLE IF NOT EXISTS structure_symbols (
+	snapshot_id INTEGER NOT NULL,
+	canonical_id TEXT NOT NULL,
+	name TEXT NOT NULL,
+	qualified_name TEXT,
+	file_path TEXT NOT NULL,
+	symbol_type TEXT,
+	signature TEXT,
+	line_start INTEGER,
+	PRIMARY KEY (snapshot_id, canonical_id)
+);`
+
+func openStructureDB(projectRoot string) (*sql.DB, error) {
+	db, err := openHistoryDB(projectRoot)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(structureHistorySchema); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// collectCurrentStructure 从 symbols.db 读取当前文件列表与符号集合
+func collectCurrentStructure(projectRoot string) ([]string, []StructureSymbol, error) {
+	dbPath := getDBPath(projectRoot)
+	if !fileExists(dbPath) {
+		return nil, nil, errors.New(i18n.T("索引不存在，请先执行 initialize_project"))
+	}
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer db.Close()
+
+	rows, err := db.Query("SELECT file_path FROM files")
+	if err != nil {
+		return nil, nil, err
+	}
+	var files []string
+	for rows.Next() {
+		var f string
+		if err := rows.Scan(&f); err == nil {
+			files = append(files, filepath.ToSlash(f))
+		}
+	}
+	rows.Close()
+	sort.Strings(files)
+
+	rows, err = db.Query(`
+		SELECT COALESCE(s.canonical_id, ''), s.name, COALESCE(s.qualified_name, ''), f.file_path,
+		       COALESCE(s.symbol_type, ''), COALESCE(s.signature, ''), s.line_start
+		FROM symbols s
+		JOIN files f ON s.file_id = f.file_id
+		ORDER BY f.file_path, s.line_start`)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	// 同一 canonical_id 多处定义时保留第一处
+	seen := make(map[string]bool)
+	var symbols []StructureSymbol
+	for rows.Next() {
+		var s StructureSymbol
+		if err := rows.Scan(&s.CanonicalID, &s.Name, &s.QualifiedName, &s.FilePath,
+			&s.SymbolType, &s.Signature, &s.LineStart); err != nil {
+			continue
+		}
+		s.FilePath = filepath.ToSlash(s.FilePath)
+		if s.CanonicalID == "" {
+			s.CanonicalID = s.FilePath + "::" + s.Name
+		}
+		if s.QualifiedName == "" {
+			s.QualifiedName = s.Name
+		}
+		if seen[s.CanonicalID] {
+			continue
+		}
+		seen[s.CanonicalID] = true
+		symbols = append(symbols, s)
+	}
+	return files, symbols, nil
+}
+
+func structureFingerprint(files []string, symbols []StructureSymbol) string {
+	h := sha1.New()
+	for _, f := range files {
+		fmt.Fprintf(h, "F|%s\n", f)
+	}
+	for _, s := range symbols {
+		fmt.Fprintf(h, "S|%s|%s|%s\n", s.CanonicalID, s.QualifiedName, normalizeSignature(s.Signature))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// RecordStructureSnapshot 记录当前索引的结构快照
+// 与上一次快照完全相同时不重复记录，返回 recorded=false
+func RecordStructureSnapshot(projectRoot string) (snapshot *StructureSnapshot, recorded bool, err error) {
+	files, symbols, err := collectCurrentStructure(projectRoot)
+	if err != nil || len(files) == 0 {
+		return nil, false, err
+	}
+
+	db, err := openStructureDB(projectRoot)
+	if err != nil {
+		return nil, false, err
+	}
+	defer db.Close()
+
+	fingerprint := structureFingerprint(files, symbols)
+	var lastFingerprint string
+	_ = db.QueryRow("SELECT fingerprint FROM structure_snapshots ORDER BY snapshot_id DESC LIMIT 1").Scan(&lastFingerprint)
+	if lastFingerprint == fingerprint {
+		latest, err := getStructureSnapshot(db, 0)
+		return latest, false, err
+	}
+
+	snap := StructureSnapshot{
+		CreatedAt:    time.Now().Format(time.RFC3339),
+		GitRevision:  gitRevision(projectRoot),
+		TotalFiles:   len(files),
+		TotalSymbols: len(symbols),
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, false, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`INSERT INTO structure_snapshots
+		(created_at, git_revision, fingerprint, total_files, total_symbols) VALUES (?, ?, ?, ?, ?)`,
+		snap.CreatedAt, snap.GitRevision, fingerprint, snap.TotalFiles, snap.TotalSymbols)
+	if err != nil {
+		return nil, false, err
+	}
+	snap.ID, _ = res.LastInsertId()
+
+	fileStmt, err := tx.Prepare("INSERT INTO structure_files (snapshot_id, file_path) VALUES (?, ?)")
+	if err != nil {
+		return nil, false, err
+	}
+	defer fileStmt.Close()
+	for _, f := range files {
+		if _, err := fileStmt.Exec(snap.ID, f); err != nil {
+			return nil, false, err
+		}
+	}
+
+	symStmt, err := tx.Prepare(`INSERT INTO structure_symbols
+		(snapshot_id, canonical_id, name, qualified_name, file_path, symbol_type, signature, line_start)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return nil, false, err
+	}
+	defer symStmt.Close()
+	for _, s := range symbols {
+		if _, err := symStmt.Exec(snap.ID, s.CanonicalID, s.Name, s.QualifiedName, s.FilePath,
+			s.SymbolType, s.Signature, s.LineStart); err != nil {
+			return nil, false, err
+		}
+	}
+
+	// 只保留最近 N 个快照
+	cutoff := `SELECT snapshot_id FROM structure_snapshots ORDER BY snapshot_id DESC LIMIT -1 OFFSET ?`
+	for _, table := range []string{"structure_symbols", "structure_files", "structure_snapshots"} {
+		if _, err := tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE snapshot_id IN (%s)", table, cutoff), maxStructureSnapshots); err != nil {
+			return nil, false, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, false, err
+	}
+	return &snap, true, nil
+}
+
+// getStructureSnapshot 读取快照元信息；id<=0 表示最新
+func getStructureSnapshot(db *sql.DB, id int64) (*StructureSnapshot, error) {
+	query := `SELECT snapshot_id, created_at, COALESCE(git_revision, ''), total_files, total_symbols
+		FROM structure_snapshots `
+	var row *sql.Row
+	if id > 0 {
+		row = db.QueryRow(query+"WHERE snapshot_id = ?", id)
+	} else {
+		row = db.QueryRow(query + "ORDER BY snapshot_id DESC LIMIT 1")
+	}
+
+	var s StructureSnapshot
+	if err := row.Scan(&s.ID, &s.CreatedAt, &s.GitRevision, &s.TotalFiles, &s.TotalSymbols); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &s, nil
+}
+
+// ListStructureSnapshots 列出最近的结构快照（新到旧）
+func ListStructureSnapshots(projectRoot string, limit int) ([]StructureSnapshot, error) {
+	if !fileExists(getHistoryDBPath(projectRoot)) {
+		return nil, nil
+	}
+	db, err := openStructureDB(projectRoot)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	if limit <= 0 {
+		limit = maxStructureSnapshots
+	}
+	rows, err := db.Query(`SELECT snapshot_id, created_at, COALESCE(git_revision, ''), total_files, total_symbols
+		FROM structure_snapshots ORDER BY snapshot_id DESC LIMIT ?`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []StructureSnapshot
+	for rows.Next() {
+		var s StructureSnapshot
+		if err := rows.Scan(&s.ID, &s.CreatedAt, &s.GitRevision, &s.TotalFiles, &s.TotalSymbols); err == nil {
+			result = append(result, s)
+		}
+	}
+	return result, nil
+}
+
+// sinceLayouts since 参数接受的日期格式 (本地时区)
+var sinceLayouts = []string{time.RFC3339, "2006-01-02T15:04", "2006-01-02 15:04", "2006-01-02"}
+
+// FindStructureSnapshot 按 since 选择基准快照
+// since: 空=最新快照；数字或 #数字=快照 ID；日期=该时刻之前的最后一个快照
+// (该时刻之前没有快照时退回最早的快照，并在 note 中说明)
+func FindStructureSnapshot(projectRoot, since string) (snap *StructureSnapshot, note string, err error) {
+	since = strings.TrimSpace(since)
+	if !fileExists(getHistoryDBPath(projectRoot)) {
+		return nil, "", nil
+	}
+	db, err := openStructureDB(projectRoot)
+	if err != nil {
+		return nil, "", err
+	}
+	defer db.Close()
+
+	if since == "" {
+		snap, err = getStructureSnapshot(db, 0)
+		return snap, "", err
+	}
+	if id, err := strconv.ParseInt(strings.TrimPrefix(since, "#"), 10, 64); err == nil {
+		snap, err = getStructureSnapshot(db, id)
+		if err == nil && snap == nil {
+			return nil, "", errors.New(i18n.T("结构快照 #%d 不存在", id))
+		}
+		return snap, "", err
+	}
+
+	var at time.Time
+	for _, layout := range sinceLayouts {
+		if t, perr := time.ParseInLocation(layout, since, time.Local); perr == nil {
+			at = t
+			break
+		}
+	}
+	if at.IsZero() {
+		return nil, "", errors.New(i18n.T("since 无效: %s (支持快照 ID、2006-01-02、2006-01-02 15:04 或 RFC3339)", since))
+	}
+
+	snapshots, err := ListStructureSnapshots(projectRoot, maxStructureSnapshots)
+	if err != nil || len(snapshots) == 0 {
+		return nil, "", err
+	}
+	// snapshots 新到旧，取第一个不晚于 at 的
+	for i := range snapshots {
+		created, perr := time.Parse(time.RFC3339, snapshots[i].CreatedAt)
+		if perr == nil && !created.After(at) {
+			return &snapshots[i], "", nil
+		}
+	}
+	oldest := snapshots[len(snapshots)-1]
+	return &oldest, i18n.T("%s 之前没有结构快照，已使用最早的快照 #%d (%s)", since, oldest.ID, oldest.CreatedAt), nil
+}
+
+func loadStructureSnapshot(db *sql.DB, id int64) ([]string, []StructureSymbol, error) {
+	rows, err := db.Query("SELECT file_path FROM structure_files WHERE snapshot_id = ? ORDER BY file_path", id)
+	if err != nil {
+		return nil, nil, err
+	}
+	var files []string
+	for rows.Next() {
+		var f string
+		if err := rows.Scan(&f); err == nil {
+			files = append(files, f)
+		}
+	}
+	rows.Close()
+
+	rows, err = db.Query(`SELECT canonical_id, name, COALESCE(qualified_name, ''), file_path,
+		COALESCE(symbol_type, ''), COALESCE(signature, ''), COALESCE(line_start, 0)
+		FROM structure_symbols WHERE snapshot_id = ?`, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+	var symbols []StructureSymbol
+	for rows.Next() {
+		var s StructureSymbol
+		if err := rows.Scan(&s.CanonicalID, &s.Name, &s.QualifiedName, &s.FilePath,
+			&s.SymbolType, &s.Signature, &s.LineStart); err == nil {
+			symbols = append(symbols, s)
+		}
+	}
+	return files, symbols, nil
+}
+
+// DiffStructure 对比结构快照与当前索引
+func DiffStructure(projectRoot string, fromID int64, scope string, publicOnly bool) (*StructureDiff, error) {
+	if !fileExists(getHistoryDBPath(projectRoot)) {
+		return nil, errors.New(i18n.T("尚无结构快照，initialize_project 时会自动记录"))
+	}
+	db, err := openStructureDB(projectRoot)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	from, err := getStructureSnapshot(db, fromID)
+	if err != nil {
+		return nil, err
+	}
+	if from == nil {
+		return nil, errors.New(i18n.T("尚无结构快照，initialize_project 时会自动记录"))
+	}
+	beforeFiles, beforeSymbols, err := loadStructureSnapshot(db, from.ID)
+	if err != nil {
+		return nil, err
+	}
+	afterFiles, afterSymbols, err := collectCurrentStructure(projectRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := compareStructure(
+		filterStructureFiles(beforeFiles, scope), filterStructureSymbols(beforeSymbols, scope, publicOnly),
+		filterStructureFiles(afterFiles, scope), filterStructureSymbols(afterSymbols, scope, publicOnly))
+	diff.From, diff.Scope, diff.PublicOnly = *from, scope, publicOnly
+	return diff, nil
+}
+
+func filterStructureFiles(files []string, scope string) []string {
+	var out []string
+	for _, f := range files {
+		if inScope(f, scope) {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+func filterStructureSymbols(symbols []StructureSymbol, scope string, publicOnly bool) []StructureSymbol {
+	var out []StructureSymbol
+	for _, s := range symbols {
+		if inScope(s.FilePath, scope) && (!publicOnly || s.Public()) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// compareStructure 计算文件、符号与目录级差异
+// 移动：删除与新增中 qualified_name + 类型相同且一一对应的符号
+// 签名变化：canonical_id 相同而规范化后的签名不同
+func compareStructure(beforeFiles []string, before []StructureSymbol, afterFiles []string, after []StructureSymbol) *StructureDiff {
+	diff := &StructureDiff{}
+
+	beforeFileSet, afterFileSet := toStringSet(beforeFiles), toStringSet(afterFiles)
+	for _, f := range afterFiles {
+		if !beforeFileSet[f] {
+			diff.AddedFiles = append(diff.AddedFiles, f)
+		}
+	}
+	for _, f := range beforeFiles {
+		if !afterFileSet[f] {
+			diff.RemovedFiles = append(diff.RemovedFiles, f)
+		}
+	}
+
+	beforeByID := make(map[string]StructureSymbol, len(before))
+	for _, s := range before {
+		beforeByID[s.CanonicalID] = s
+	}
+	afterByID := make(map[string]StructureSymbol, len(after))
+	for _, s := range after {
+		afterByID[s.CanonicalID] = s
+	}
+
+	var added, removed []StructureSymbol
+	for _, a := range after {
+		b, ok := beforeByID[a.CanonicalID]
+		if !ok {
+			added = append(added, a)
+			continue
+		}
+		if bs, as := normalizeSignature(b.Signature), normalizeSignature(a.Signature); bs != "" && as != "" && bs != as {
+			diff.SignatureChanged = append(diff.SignatureChanged, SignatureChange{Symbol: a, Before: b.Signature, After: a.Signature})
+		}
+	}
+	for _, b := range before {
+		if _, ok := afterByID[b.CanonicalID]; !ok {
+			removed = append(removed, b)
+		}
+	}
+
+	moveKey := func(s StructureSymbol) string { return s.SymbolType + "|" + s.QualifiedName }
+	addedByKey := make(map[string][]StructureSymbol)
+	for _, s := range added {
+		addedByKey[moveKey(s)] = append(addedByKey[moveKey(s)], s)
+	}
+	removedByKey := make(map[string][]StructureSymbol)
+	for _, s := range removed {
+		removedByKey[moveKey(s)] = append(removedByKey[moveKey(s)], s)
+	}
+	moved := make(map[string]bool)
+	for key, rs := range removedByKey {
+		as := addedByKey[key]
+		if len(rs) != 1 || len(as) != 1 || rs[0].FilePath == as[0].FilePath {
+			continue
+		}
+		diff.Moved = append(diff.Moved, SymbolMove{Symbol: as[0], FromFile: rs[0].FilePath})
+		if bs, ns := normalizeSignature(rs[0].Signature), normalizeSignature(as[0].Signature); bs != "" && ns != "" && bs != ns {
+			diff.SignatureChanged = append(diff.SignatureChanged, SignatureChange{Symbol: as[0], Before: rs[0].Signature, After: as[0].Signature})
+		}
+		moved[as[0].CanonicalID], moved[rs[0].CanonicalID] = true, true
+	}
+	for _, s := range added {
+		if !moved[s.CanonicalID] {
+			diff.Added = append(diff.Added, s)
+		}
+	}
+	for _, s := range removed {
+		if !moved[s.CanonicalID] {
+			diff.Removed = append(diff.Removed, s)
+		}
+	}
+
+	bySymbolPath := func(list []StructureSymbol) {
+		sort.Slice(list, func(i, j int) bool {
+			if list[i].FilePath != list[j].FilePath {
+				return list[i].FilePath < list[j].FilePath
+			}
+			return list[i].LineStart < list[j].LineStart
+		})
+	}
+	bySymbolPath(diff.Added)
+	bySymbolPath(diff.Removed)
+	sort.Slice(diff.Moved, func(i, j int) bool { return diff.Moved[i].Symbol.CanonicalID < diff.Moved[j].Symbol.CanonicalID })
+	sort.Slice(diff.SignatureChanged, func(i, j int) bool {
+		return diff.SignatureChanged[i].Symbol.CanonicalID < diff.SignatureChanged[j].Symbol.CanonicalID
+	})
+
+	diff.Modules = moduleChanges(diff, beforeFiles, afterFiles)
+	return diff
+}
+
+// moduleChanges 按目录汇总变化，整目录新增/消失单独标记
+func moduleChanges(diff *StructureDiff, beforeFiles, afterFiles []string) []ModuleChange {
+	dirsOf := func(files []string) map[string]bool {
+		set := make(map[string]bool)
+		for _, f := range files {
+			set[path.Dir(f)] = true
+		}
+		return set
+	}
+	beforeDirs, afterDirs := dirsOf(beforeFiles), dirsOf(afterFiles)
+
+	modules := make(map[string]*ModuleChange)
+	get := func(file string) *ModuleChange {
+		dir := path.Dir(file)
+		m := modules[dir]
+		if m == nil {
+			m = &ModuleChange{Dir: dir, Status: "changed"}
+			switch {
+			case afterDirs[dir] && !beforeDirs[dir]:
+				m.Status = "added"
+			case beforeDirs[dir] && !afterDirs[dir]:
+				m.Status = "removed"
+			}
+			modules[dir] = m
+		}
+		return m
+	}
+	for _, f := range diff.AddedFiles {
+		get(f).FilesAdded++
+	}
+	for _, f := range diff.RemovedFiles {
+		get(f).FilesRemoved++
+	}
+	for _, s := range diff.Added {
+		get(s.FilePath).Added++
+	}
+	for _, s := range diff.Removed {
+		get(s.FilePath).Removed++
+	}
+	for _, mv := range diff.Moved {
+		get(mv.Symbol.FilePath).Moved++
+	}
+	for _, c := range diff.SignatureChanged {
+		get(c.Symbol.FilePath).Changed++
+	}
+
+	result := make([]ModuleChange, 0, len(modules))
+	for _, m := range modules {
+		result = append(result, *m)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Dir < result[j].Dir })
+	return result
+}
+
+func toStringSet(list []string) map[string]bool {
+	set := make(map[string]bool, len(list))
+	for _, s := range list {
+		set[s] = true
+	}
+	return set
+}
+
+// normalizeSignature 压缩空白并去掉函数体起始符，避免格式化造成误报
+func normalizeSignature(sig string) string {
+	sig = strings.Join(strings.Fields(sig), " ")
+	sig = strings.TrimSpace(strings.TrimSuffix(sig, "{"))
+	return strings.TrimSpace(strings.TrimSuffix(sig, ":"))
+}
+
+// IsPublicSymbol 按语言惯例判断符号是否对外可见 (启发式，仅依据名称与签名首行)
+func IsPublicSymbol(filePath, name, signature string) bool {
+	if name == "" {
+		return false
+	}
+	sig := strings.TrimSpace(signature)
+	switch metricsLanguage(filePath) {
+	case "go":
+		return unicode.IsUpper([]rune(name)[0])
+	case "python":
+		return !strings.HasPrefix(name, "_") || (strings.HasPrefix(name, "__") && strings.HasSuffix(name, "__"))
+	case "rust":
+		return sig == "" || strings.HasPrefix(sig, "pub")
+	case "java":
+		return sig == "" || strings.Contains(" "+sig, " public ")
+	case "c":
+		return !strings.HasPrefix(sig, "static ")
+	default:
+		return !strings.HasPrefix(name, "_") && !strings.HasPrefix(name, "#")
+	}
+}
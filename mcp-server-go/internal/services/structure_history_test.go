@@ -0,0 +1,156 @@
+package services
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCompareStructure(t *testing.T) {
+	sym := func(file, name, sig string) StructureSymbol {
+		return StructureSymbol{
+			CanonicalID:   "func:" + file + "::" + name,
+			Name:          name,
+			QualifiedName: name,
+			FilePath:      file,
+			SymbolType:    "function",
+			Signature:     sig,
+		}
+	}
+	beforeFiles := []string{"core/a.go", "core/b.go", "old/x.go"}
+	before := []StructureSymbol{
+		sym("core/a.go", "Keep", "func Keep(a int) {"),
+		sym("core/a.go", "Resize", "func Resize(w int) {"),
+		sym("core/b.go", "Move", "func Move() {"),
+		sym("old/x.go", "Gone", "func Gone() {"),
+	}
+	afterFiles := []string{"core/a.go", "core/b.go", "util/m.go"}
+	after := []StructureSymbol{
+		sym("core/a.go", "Keep", "func Keep(a  int)  {"), // 仅空白变化
+		sym("core/a.go", "Resize", "func Resize(w, h int) {"),
+		sym("util/m.go", "Move", "func Move() {"),
+		sym("util/m.go", "Fresh", "func Fresh() {"),
+	}
+
+	d := compareStructure(beforeFiles, before, afterFiles, after)
+	if len(d.AddedFiles) != 1 || d.AddedFiles[0] != "util/m.go" || len(d.RemovedFiles) != 1 || d.RemovedFiles[0] != "old/x.go" {
+		t.Fatalf("files: +%v -%v", d.AddedFiles, d.RemovedFiles)
+	}
+	if len(d.Moved) != 1 || d.Moved[0].Symbol.Name != "Move" || d.Moved[0].FromFile != "core/b.go" {
+		t.Fatalf("Moved = %+v", d.Moved)
+	}
+	if len(d.Added) != 1 || d.Added[0].Name != "Fresh" || len(d.Removed) != 1 || d.Removed[0].Name != "Gone" {
+		t.Fatalf("Added = %+v, Removed = %+v", d.Added, d.Removed)
+	}
+	if len(d.SignatureChanged) != 1 || d.SignatureChanged[0].Symbol.Name != "Resize" {
+		t.Fatalf("SignatureChanged = %+v", d.SignatureChanged)
+	}
+
+	status := make(map[string]string)
+	for _, m := range d.Modules {
+		status[m.Dir] = m.Status
+	}
+	if status["util"] != "added" || status["old"] != "removed" || status["core"] != "changed" {
+		t.Fatalf("Modules = %+v", d.Modules)
+	}
+}
+
+func TestIsPublicSymbol(t *testing.T) {
+	cases := []struct {
+		file, name, sig string
+		want            bool
+	}{
+		{"a.go", "Exported", "func Exported() {", true},
+		{"a.go", "internal", "func internal() {", false},
+		{"a.py", "_private", "def _private():", false},
+		{"a.py", "__init__", "def __init__(self):", true},
+		{"a.rs", "run", "pub fn run() {", true},
+		{"a.rs", "helper", "fn helper() {", false},
+		{"A.java", "get", "public int get() {", true},
+		{"A.java", "calc", "private int calc() {", false},
+		{"a.c", "local", "static int local(void) {", false},
+		{"a.ts", "render", "export function render() {", true},
+	}
+	for _, c := range cases {
+		if got := IsPublicSymbol(c.file, c.name, c.sig); got != c.want {
+			t.Errorf("IsPublicSymbol(%q, %q) = %v, want %v", c.file, c.name, got, c.want)
+		}
+	}
+}
+
+func TestStructureSnapshotRoundTrip(t *testing.T) {
+	root := t.TempDir()
+	dbPath := getDBPath(root)
+	_ = os.MkdirAll(filepath.Dir(dbPath), 0755)
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	for _, stmt := range []string{
+		`CREATE TABLE files (file_id INTEGER PRIMARY KEY, file_path TEXT UNIQUE)`,
+		`CREATE TABLE symbols (symbol_id INTEGER PRIMARY KEY AUTOINCREMENT, file_id INTEGER, name TEXT, qualified_name TEXT,
+			canonical_id TEXT, symbol_type TEXT, line_start INTEGER, line_end INTEGER, signature TEXT)`,
+		`INSERT INTO files VALUES (1, 'lib/calc.go')`,
+		`INSERT INTO symbols (file_id, name, qualified_name, canonical_id, symbol_type, line_start, line_end, signature)
+			VALUES (1, 'Sum', 'Sum', 'func:lib/calc.go::Sum', 'function', 3, 5, 'func Sum(a, b int) int {'),
+			       (1, 'helper', 'helper', 'func:lib/calc.go::helper', 'function', 7, 9, 'func helper() {')`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("setup %q: %v", stmt, err)
+		}
+	}
+
+	first, recorded, err := RecordStructureSnapshot(root)
+	if err != nil || !recorded || first.TotalSymbols != 2 {
+		t.Fatalf("first snapshot = %+v, %v, %v", first, recorded, err)
+	}
+	if _, recorded, _ := RecordStructureSnapshot(root); recorded {
+		t.Fatal("unchanged structure should not create a new snapshot")
+	}
+
+	// 修改签名、删除私有函数、新增文件
+	for _, stmt := range []string{
+		`UPDATE symbols SET signature = 'func Sum(nums ...int) int {' WHERE name = 'Sum'`,
+		`DELETE FROM symbols WHERE name = 'helper'`,
+		`INSERT INTO files VALUES (2, 'lib/avg.go')`,
+		`INSERT INTO symbols (file_id, name, qualified_name, canonical_id, symbol_type, line_start, line_end, signature)
+			VALUES (2, 'Avg', 'Avg', 'func:lib/avg.go::Avg', 'function', 3, 5, 'func Avg(nums ...int) int {')`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	d, err := DiffStructure(root, first.ID, "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d.SignatureChanged) != 1 || len(d.Removed) != 1 || len(d.Added) != 1 || len(d.AddedFiles) != 1 {
+		t.Fatalf("diff = %+v", d)
+	}
+	if d, _ := DiffStructure(root, first.ID, "", true); len(d.Removed) != 0 {
+		t.Fatalf("public_only should hide private helper: %+v", d.Removed)
+	}
+	if d, _ := DiffStructure(root, first.ID, "cmd", false); !d.Empty() {
+		t.Fatalf("scope filter ignored: %+v", d)
+	}
+
+	// 按日期选择：未来时刻取最新快照，过去时刻退回最早快照并给出说明
+	snap, note, err := FindStructureSnapshot(root, time.Now().Add(time.Hour).Format("2006-01-02 15:04"))
+	if err != nil || snap == nil || snap.ID != first.ID || note != "" {
+		t.Fatalf("future date: %+v %q %v", snap, note, err)
+	}
+	snap, note, err = FindStructureSnapshot(root, "2000-01-01")
+	if err != nil || snap == nil || snap.ID != first.ID || note == "" {
+		t.Fatalf("past date: %+v %q %v", snap, note, err)
+	}
+	if _, _, err := FindStructureSnapshot(root, "yesterday"); err == nil {
+		t.Fatal("invalid since should be rejected")
+	}
+	if _, _, err := FindStructureSnapshot(root, "#99"); err == nil {
+		t.Fatal("missing snapshot id should be rejected")
+	}
+}
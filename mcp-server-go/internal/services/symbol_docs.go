@@ -0,0 +1,272 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// ============================================================================
+// 文档注释提取
+// 基于源码行号读取紧邻定义的注释块 (Go/Rust/Java/JS/TS/C 的前置注释，Python 的 docstring)
+// ============================================================================
+
+// maxDocLines 单个符号最多保留的注释行数，避免整段 license 头被当作文档
+const maxDocLines = 30
+
+// ExtractDocComment 提取符号的文档注释，lineStart/lineEnd 为 1-based 行号
+func ExtractDocComment(lang string, lines []string, lineStart, lineEnd int) string {
+	if lineStart < 1 || lineStart > len(lines) {
+		return ""
+	}
+	if lang == "python" {
+		if doc := pythonDocstring(lines, lineStart, lineEnd); doc != "" {
+			return doc
+		}
+	}
+	return leadingComment(lang, lines, lineStart)
+}
+
+// leadingComment 向上收集定义前连续的注释行，跳过装饰器/注解/属性
+func leadingComment(lang string, lines []string, lineStart int) string {
+	var collected []string
+	inBlock := false
+
+	for i := lineStart - 2; i >= 0 && len(collected) < maxDocLines; i-- {
+		line := strings.TrimSpace(lines[i])
+
+		if inBlock {
+			if strings.HasPrefix(line, "/*") {
+				collected = append(collected, cleanBlockLine(line))
+				inBlock = false
+				continue
+			}
+			collected = append(collected, cleanBlockLine(line))
+			continue
+		}
+
+		switch {
+		case line == "":
+			i = -1 // 空行终止注释块
+		case strings.HasSuffix(line, "*/"):
+			if strings.HasPrefix(line, "/*") {
+				collected = append(collected, cleanBlockLine(line))
+			} else {
+				collected = append(collected, cleanBlockLine(line))
+				inBlock = true
+			}
+		case strings.HasPrefix(line, "//!"):
+			i = -1 // Rust 内部文档描述的是所在模块，不属于下方定义
+		case lang == "go" && (strings.HasPrefix(line, "//go:") || strings.HasPrefix(line, "//nolint")):
+			continue // 编译指令不是文档
+		case strings.HasPrefix(line, "//"):
+			collected = append(collected, strings.TrimSpace(strings.TrimLeft(line, "/")))
+		case lang == "python" && strings.HasPrefix(line, "#"):
+			collected = append(collected, strings.TrimSpace(strings.TrimLeft(line, "#")))
+		case len(collected) == 0 && isAnnotationLine(lang, line):
+			continue // 注解在注释与定义之间
+		default:
+			i = -1
+		}
+	}
+
+	// 逆序收集，翻转回源码顺序
+	for l, r := 0, len(collected)-1; l < r; l, r = l+1, r-1 {
+		collected[l], collected[r] = collected[r], collected[l]
+	}
+	return joinDocLines(collected)
+}
+
+// isAnnotationLine 判断是否为装饰器/注解/属性行
+func isAnnotationLine(lang string, line string) bool {
+	switch lang {
+	case "rust":
+		return strings.HasPrefix(line, "#[")
+	case "python", "java", "javascript":
+		return strings.HasPrefix(line, "@")
+	}
+	return false
+}
+
+// cleanBlockLine 去掉块注释的 /** * */ 标记
+func cleanBlockLine(line string) string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimSuffix(line, "*/")
+	line = strings.TrimPrefix(line, "/**")
+	line = strings.TrimPrefix(line, "/*")
+	line = strings.TrimPrefix(line, "*")
+	return strings.TrimSpace(line)
+}
+
+// pythonDocstring 读取 def/class 之后的三引号文档字符串
+func pythonDocstring(lines []string, lineStart, lineEnd int) string {
+	if lineEnd < lineStart || lineEnd > len(lines) {
+		lineEnd = len(lines)
+	}
+
+	// 定义头可能跨多行，找到以 : 结尾的那一行
+	body := lineStart
+	for body <= lineEnd && !strings.HasSuffix(strings.TrimSpace(stripPythonComment(lines[body-1])), ":") {
+		body++
+	}
+	if body >= lineEnd {
+		return ""
+	}
+
+	first := strings.TrimSpace(lines[body])
+	first = strings.TrimLeft(first, "rRuUbB")
+	var quote string
+	switch {
+	case strings.HasPrefix(first, `"""`):
+		quote = `"""`
+	case strings.HasPrefix(first, `'''`):
+		quote = `'''`
+	default:
+		return ""
+	}
+
+	rest := first[len(quote):]
+	if idx := strings.Index(rest, quote); idx >= 0 {
+		return strings.TrimSpace(rest[:idx])
+	}
+
+	collected := []string{strings.TrimSpace(rest)}
+	for i := body + 1; i < lineEnd && len(collected) < maxDocLines; i++ {
+		line := strings.TrimSpace(lines[i])
+		if idx := strings.Index(line, quote); idx >= 0 {
+			collected = append(collected, strings.TrimSpace(line[:idx]))
+			break
+		}
+		collected = append(collected, line)
+	}
+	return joinDocLines(collected)
+}
+
+func stripPythonComment(line string) string {
+	if idx := strings.Index(line, "#"); idx >= 0 {
+		return line[:idx]
+	}
+	return line
+}
+
+// joinDocLines 去掉首尾空行后拼接
+func joinDocLines(lines []string) string {
+	for len(lines) > 0 && lines[0] == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// AttachDocs 为节点补充文档注释 (按 文件+名称+起始行 匹配 concept_docs)
+// 文档读取失败时静默跳过，不影响调用方
+func (ai *ASTIndexer) AttachDocs(projectRoot string, nodes ...*Node) {
+	if len(nodes) == 0 {
+		return
+	}
+	if err := refreshConceptIndex(projectRoot); err != nil {
+		return
+	}
+	db, err := sql.Open("sqlite", getDBPath(projectRoot))
+	if err != nil {
+		return
+	}
+	defer db.Close()
+	if !hasTable(db, "concept_docs") {
+		return
+	}
+
+	fileSet := make(map[string]bool)
+	for _, n := range nodes {
+		if n != nil && n.FilePath != "" {
+			fileSet[filepath.ToSlash(n.FilePath)] = true
+		}
+	}
+	files := sortedKeys(fileSet)
+	if len(files) == 0 {
+		return
+	}
+
+	docs := make(map[string]string)
+	// 分批查询，避免超过 SQLite 参数上限
+	for start := 0; start < len(files); start += 500 {
+		batch := files[start:min(start+500, len(files))]
+		args := make([]interface{}, len(batch))
+		for i, f := range batch {
+			args[i] = f
+		}
+		rows, err := db.Query(fmt.Sprintf(`
+			SELECT f.file_path, s.name, s.line_start, c.doc
+			FROM concept_docs c
+			JOIN symbols s ON s.symbol_id = c.symbol_id
+			JOIN files f ON s.file_id = f.file_id
+			WHERE c.doc != '' AND f.file_path IN (%s)`, strings.TrimSuffix(strings.Repeat("?,", len(batch)), ",")), args...)
+		if err != nil {
+			return
+		}
+		for rows.Next() {
+			var path, name, doc string
+			var line int
+			if err := rows.Scan(&path, &name, &line, &doc); err == nil {
+				docs[docKey(path, name, line)] = doc
+			}
+		}
+		rows.Close()
+	}
+
+	for _, n := range nodes {
+		if n != nil && n.Doc == "" {
+			n.Doc = docs[docKey(n.FilePath, n.Name, n.LineStart)]
+		}
+	}
+}
+
+// docByCanonicalID 读取单个符号的文档注释
+func (ai *ASTIndexer) docByCanonicalID(projectRoot, canonicalID string) string {
+	if err := refreshConceptIndex(projectRoot); err != nil {
+		return ""
+	}
+	db, err := sql.Open("sqlite", getDBPath(projectRoot))
+	if err != nil {
+		return ""
+	}
+	defer db.Close()
+	if !hasTable(db, "concept_docs") {
+		return ""
+	}
+
+	var doc string
+	_ = db.QueryRow(`
+		SELECT COALESCE(c.doc, '') FROM concept_docs c
+		JOIN symbols s ON s.symbol_id = c.symbol_id
+		WHERE s.canonical_id = ? AND c.doc != ''
+		LIMIT 1`, canonicalID).Scan(&doc)
+	return doc
+}
+
+func docKey(path, name string, line int) string {
+	return fmt.Sprintf("%s::%s@%d", filepath.ToSlash(path), name, line)
+}
+
+// DocSummary 取文档注释首行作为摘要，去掉 Go 风格开头重复的符号名，超长截断
+func DocSummary(name, doc string, limit int) string {
+	var first string
+	for _, line := range strings.Split(doc, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			first = line
+			break
+		}
+	}
+	if rest, ok := strings.CutPrefix(first, name+" "); ok && strings.TrimSpace(rest) != "" {
+		first = strings.TrimSpace(rest)
+	}
+
+	runes := []rune(first)
+	if limit > 0 && len(runes) > limit {
+		return string(runes[:limit]) + "..."
+	}
+	return first
+}
@@ -0,0 +1,113 @@
+package services
+
+import "testing"
+
+func TestExtractDocComment(t *testing.T) {
+	goSrc := []string{
+		"package x",
+		"",
+		"// Load reads the config.",
+		"// It never fails.",
+		"func Load() {}",
+	}
+	if got := ExtractDocComment("go", goSrc, 5, 5); got != "Load reads the config.\nIt never fails." {
+		t.Errorf("go doc = %q", got)
+	}
+
+	javaSrc := []string{
+		"/**",
+		" * Saves the user.",
+		" */",
+		"@Override",
+		"public void save() {}",
+	}
+	if got := ExtractDocComment("java", javaSrc, 5, 5); got != "Saves the user." {
+		t.Errorf("java doc = %q", got)
+	}
+
+	pySrc := []string{
+		"@cache",
+		"def load(path,",
+		"         strict=False):",
+		`    """Load a file.`,
+		"",
+		`    Returns text."""`,
+		"    return open(path).read()",
+	}
+	if got := ExtractDocComment("python", pySrc, 2, 7); got != "Load a file.\n\nReturns text." {
+		t.Errorf("python doc = %q", got)
+	}
+
+	detached := []string{"// unrelated", "", "func F() {}"}
+	if got := ExtractDocComment("go", detached, 3, 3); got != "" {
+		t.Errorf("comment separated by blank line should not attach, got %q", got)
+	}
+}
+
+func TestExtractDocCommentLanguages(t *testing.T) {
+	rustSrc := []string{
+		"//! crate docs",
+		"",
+		"/// Parses the header.",
+		"/// Returns None on EOF.",
+		"#[inline]",
+		"pub fn parse() {}",
+	}
+	if got := ExtractDocComment("rust", rustSrc, 6, 6); got != "Parses the header.\nReturns None on EOF." {
+		t.Errorf("rust doc = %q", got)
+	}
+	if got := ExtractDocComment("rust", []string{"//! module", "fn f() {}"}, 2, 2); got != "" {
+		t.Errorf("inner rust doc should not attach, got %q", got)
+	}
+
+	tsSrc := []string{
+		"/** Fetches the user by id. */",
+		"export async function fetchUser(id: string) {}",
+	}
+	if got := ExtractDocComment("javascript", tsSrc, 2, 2); got != "Fetches the user by id." {
+		t.Errorf("ts doc = %q", got)
+	}
+
+	goDirective := []string{
+		"// Hot is inlined.",
+		"//go:noinline",
+		"func Hot() {}",
+	}
+	if got := ExtractDocComment("go", goDirective, 3, 3); got != "Hot is inlined." {
+		t.Errorf("go directive doc = %q", got)
+	}
+
+	pyClass := []string{
+		"class Store:",
+		"    '''Persists items.'''",
+		"    pass",
+	}
+	if got := ExtractDocComment("python", pyClass, 1, 3); got != "Persists items." {
+		t.Errorf("python class doc = %q", got)
+	}
+}
+
+func TestAttachDocsAndSummary(t *testing.T) {
+	root := setupRefactorProject(t)
+	ai := &ASTIndexer{}
+
+	sum := &Node{Name: "Sum", FilePath: "lib/calc.go", LineStart: 4}
+	run := &Node{Name: "run", FilePath: "main.go", LineStart: 3}
+	ai.AttachDocs(root, sum, run)
+	if sum.Doc != "Sum adds numbers." {
+		t.Fatalf("Sum doc = %q", sum.Doc)
+	}
+	if run.Doc != "" {
+		t.Fatalf("run should have no doc, got %q", run.Doc)
+	}
+	if got := ai.docByCanonicalID(root, "func:lib/calc.go::Sum"); got != "Sum adds numbers." {
+		t.Fatalf("docByCanonicalID = %q", got)
+	}
+
+	if got := DocSummary("Sum", sum.Doc, 0); got != "adds numbers." {
+		t.Errorf("DocSummary strips name: %q", got)
+	}
+	if got := DocSummary("X", "\n第一行很长很长\n第二行", 3); got != "第一行..." {
+		t.Errorf("DocSummary truncation: %q", got)
+	}
+}
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// techStackMarkers 清单文件 -> 技术栈名称 (按展示顺序)
+var techStackMarkers = []struct {
+	File  string
+	Stack string
+}{
+	{"go.mod", "Go"},
+	{"Cargo.toml", "Rust"},
+	{"package.json", "Node.js"},
+	{"tsconfig.json", "TypeScript"},
+	{"pyproject.toml", "Python"},
+	{"requirements.txt", "Python"},
+	{"setup.py", "Python"},
+	{"pom.xml", "Java"},
+	{"build.gradle", "Java"},
+	{"build.gradle.kts", "Kotlin"},
+	{"CMakeLists.txt", "C/C++"},
+	{"composer.json", "PHP"},
+	{"Gemfile", "Ruby"},
+	{"pubspec.yaml", "Dart"},
+}
+
+// DetectTechStack 根据项目根目录及其一级子目录中的清单文件粗略判断技术栈。
+// 只看清单文件，不遍历源码，开销可以忽略，适合每次请求调用。
+func DetectTechStack(projectRoot string) []string {
+	if projectRoot == "" {
+		return nil
+	}
+	dirs := []string{projectRoot}
+	if entries, err := os.ReadDir(projectRoot); err == nil {
+		ignoreDirs := IgnoreDirsFor(projectRoot)
+		ignored := make(map[string]bool, len(ignoreDirs))
+		for _, d := range ignoreDirs {
+			ignored[d] = true
+		}
+		for _, e := range entries {
+			if e.IsDir() && !strings.HasPrefix(e.Name(), ".") && !ignored[e.Name()] {
+				dirs = append(dirs, filepath.Join(projectRoot, e.Name()))
+			}
+		}
+	}
+
+	seen := make(map[string]bool)
+	var stack []string
+	for _, m := range techStackMarkers {
+		if seen[m.Stack] {
+			continue
+		}
+		for _, dir := range dirs {
+			if fileExists(filepath.Join(dir, m.File)) {
+				seen[m.Stack] = true
+				stack = append(stack, m.Stack)
+				break
+			}
+		}
+	}
+	return stack
+}
@@ -0,0 +1,260 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mcp-server-go/internal/core"
+	"mcp-server-go/internal/i18n"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	// maxBriefingTTLHours 分析简报最长保留时长；默认值见 mpm.yaml 的 analyze.briefing_ttl_hours，关联任务链后不再过期
+	maxBriefingTTLHours = 24 * 30
+	defaultBriefingList = 20
+)
+
+// BriefingListArgs 分析简报列表参数
+type BriefingListArgs struct {
+	TaskID         string `json:"task_id" jsonschema:"description=查看单个简报详情 (manager_analyze 返回的 task_id)"`
+	IncludeExpired bool   `json:"include_expired" jsonschema:"description=是否显示已过期的简报，默认 false"`
+	Limit          int    `json:"limit" jsonschema:"description=最多显示条数，默认 20"`
+}
+
+// saveAnalysisBriefing 持久化第一步分析结果，返回过期时间
+func saveAnalysisBriefing(ctx context.Context, sm *SessionManager, taskID string, state *AnalysisState, ttlHours int) (time.Time, error) {
+	if ttlHours <= 0 {
+		ttlHours = sm.config().Analyze.BriefingTTLHours
+	}
+	if ttlHours > maxBriefingTTLHours {
+		ttlHours = maxBriefingTTLHours
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return time.Time{}, err
+	}
+	// 顺带清理过期简报，避免无限增长
+	_, _ = sm.Memory.PurgeExpiredBriefings(ctx)
+
+	expires := time.Now().Add(time.Duration(ttlHours) * time.Hour)
+	b := &core.AnalysisBriefing{
+		ID:        taskID,
+		Intent:    state.Intent,
+		Directive: state.UserDirective,
+		State:     string(data),
+		ExpiresAt: expires,
+	}
+	return expires, sm.Memory.SaveBriefing(ctx, b)
+}
+
+// loadAnalysisBriefing 读取未过期的分析简报
+func loadAnalysisBriefing(ctx context.Context, sm *SessionManager, taskID string) (*core.AnalysisBriefing, *AnalysisState, error) {
+	b, err := sm.Memory.GetBriefing(ctx, taskID)
+	if err != nil {
+		return nil, nil, errors.New(i18n.T("读取分析简报失败: %v", err))
+	}
+	if b == nil {
+		return nil, nil, errors.New(i18n.T("未找到分析简报 %s，请先调用 manager_analyze(step=1)", taskID))
+	}
+	if b.Expired(time.Now()) {
+		return nil, nil, errors.New(i18n.T("分析简报 %s 已于 %s 过期，请重新调用 manager_analyze(step=1)",
+			taskID, b.ExpiresAt.Local().Format("2006-01-02 15:04")))
+	}
+	var state AnalysisState
+	if err := json.Unmarshal([]byte(b.State), &state); err != nil {
+		return nil, nil, errors.New(i18n.T("分析简报 %s 已损坏: %v", taskID, err))
+	}
+	return b, &state, nil
+}
+
+// touchAnalysisBriefing 再次使用时顺延过期时间（不缩短）
+func touchAnalysisBriefing(ctx context.Context, sm *SessionManager, b *core.AnalysisBriefing) {
+	ttl := time.Duration(sm.config().Analyze.BriefingTTLHours) * time.Hour
+	if next := time.Now().Add(ttl); next.After(b.ExpiresAt) {
+		b.ExpiresAt = next
+	}
+	_ = sm.Memory.SaveBriefing(ctx, b)
+}
+
+// linkBriefingToChain 校验简报存在并与任务链互相关联
+func linkBriefingToChain(ctx context.Context, sm *SessionManager, analysisID string, chain *TaskChainV2) error {
+	if sm.Memory == nil {
+		return errors.New(i18n.T("记忆层尚未初始化，无法关联分析简报"))
+	}
+	if _, _, err := loadAnalysisBriefing(ctx, sm, analysisID); err != nil {
+		return err
+	}
+	if err := sm.Memory.LinkBriefing(ctx, analysisID, chain.TaskID); err != nil {
+		return err
+	}
+	chain.AnalysisID = analysisID
+	return nil
+}
+
+func wrapListBriefings(sm *SessionManager) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var args BriefingListArgs
+		request.BindArguments(&args)
+
+		if sm.Memory == nil {
+			return mcp.NewToolResultError(i18n.T("记忆层尚未初始化")), nil
+		}
+
+		if args.TaskID != "" {
+			b, err := sm.Memory.GetBriefing(ctx, args.TaskID)
+			if err != nil {
+				return mcp.NewToolResultError(i18n.T("查询简报失败: %v", err)), nil
+			}
+			if b == nil {
+				return mcp.NewToolResultError(i18n.T("未找到分析简报 %s", args.TaskID)), nil
+			}
+			return mcp.NewToolResultStructured(newBriefingView(b, true), renderBriefingDetail(sm, b)), nil
+		}
+
+		limit := args.Limit
+		if limit <= 0 {
+			limit = defaultBriefingList
+		}
+		// 多取一些，过滤过期后仍能填满
+		list, err := sm.Memory.ListBriefings(ctx, limit*2)
+		if err != nil {
+			return mcp.NewToolResultError(i18n.T("查询简报失败: %v", err)), nil
+		}
+		shown, hidden := visibleBriefings(list, args.IncludeExpired, limit)
+		views := make([]briefingView, 0, len(shown))
+		for i := range shown {
+			views = append(views, newBriefingView(&shown[i], false))
+		}
+		return mcp.NewToolResultStructured(map[string]any{"briefings": views, "hidden_expired": hidden},
+			renderBriefingList(sm, list, args.IncludeExpired, limit)), nil
+	}
+}
+
+// visibleBriefings 按是否包含过期与条数上限筛选简报，返回筛选结果与被隐藏的过期简报数
+func visibleBriefings(list []core.AnalysisBriefing, includeExpired bool, limit int) ([]core.AnalysisBriefing, int) {
+	now := time.Now()
+	var shown []core.AnalysisBriefing
+	hidden := 0
+	for _, b := range list {
+		if b.Expired(now) && !includeExpired {
+			hidden++
+			continue
+		}
+		if len(shown) >= limit {
+			break
+		}
+		shown = append(shown, b)
+	}
+	return shown, hidden
+}
+
+// briefingView 分析简报的结构化视图 (output_format=json)
+type briefingView struct {
+	TaskID    string         `json:"task_id"`
+	Intent    string         `json:"intent"`
+	Directive string         `json:"directive"`
+	ChainID   string         `json:"chain_id,omitempty"`
+	CreatedAt string         `json:"created_at"`
+	UpdatedAt string         `json:"updated_at"`
+	ExpiresAt string         `json:"expires_at"`
+	Expired   bool           `json:"expired"`
+	State     *AnalysisState `json:"state,omitempty"` // 仅详情包含
+}
+
+func newBriefingView(b *core.AnalysisBriefing, withState bool) briefingView {
+	v := briefingView{
+		TaskID:    b.ID,
+		Intent:    b.Intent,
+		Directive: b.Directive,
+		ChainID:   b.ChainID,
+		CreatedAt: b.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: b.UpdatedAt.Format(time.RFC3339),
+		ExpiresAt: b.ExpiresAt.Format(time.RFC3339),
+		Expired:   b.Expired(time.Now()),
+	}
+	if withState {
+		var state AnalysisState
+		if err := json.Unmarshal([]byte(b.State), &state); err == nil {
+			v.State = &state
+		}
+	}
+	return v
+}
+
+func renderBriefingList(sm *SessionManager, list []core.AnalysisBriefing, includeExpired bool, limit int) string {
+	now := time.Now()
+	var sb strings.Builder
+	sb.WriteString(i18n.T("### 🧠 分析简报\n\n"))
+
+	shown, hidden := visibleBriefings(list, includeExpired, limit)
+	for _, b := range shown {
+		expired := b.Expired(now)
+		status := fmt.Sprintf("(Exp: %s)", b.ExpiresAt.Local().Format("01-02 15:04"))
+		switch {
+		case b.ChainID != "":
+			status = "🔗 " + b.ChainID + chainStatusSuffix(sm, b.ChainID)
+		case expired:
+			status = "(EXPIRED)"
+		}
+		sb.WriteString(fmt.Sprintf("- **%s** [%s] %s %s\n", b.ID, b.Intent, truncateLine(b.Directive, 60), status))
+	}
+
+	if len(shown) == 0 {
+		sb.WriteString(i18n.T("暂无分析简报。调用 manager_analyze(step=1) 生成。\n"))
+	}
+	if hidden > 0 {
+		sb.WriteString(i18n.T("\n_已隐藏 %d 个过期简报 (include_expired=true 查看)_\n", hidden))
+	}
+	return sb.String()
+}
+
+func renderBriefingDetail(sm *SessionManager, b *core.AnalysisBriefing) string {
+	var sb strings.Builder
+	sb.WriteString(i18n.T("### 🧠 分析简报 %s\n\n", b.ID))
+	sb.WriteString(i18n.T("**意图**: %s\n", b.Intent))
+	sb.WriteString(i18n.T("**指令**: %s\n", b.Directive))
+	sb.WriteString(i18n.T("**创建**: %s | **更新**: %s\n",
+		b.CreatedAt.Local().Format("2006-01-02 15:04"), b.UpdatedAt.Local().Format("2006-01-02 15:04")))
+	switch {
+	case b.ChainID != "":
+		sb.WriteString(i18n.T("**任务链**: %s%s\n", b.ChainID, chainStatusSuffix(sm, b.ChainID)))
+	case b.Expired(time.Now()):
+		sb.WriteString(i18n.T("**状态**: 已过期 (%s)\n", b.ExpiresAt.Local().Format("2006-01-02 15:04")))
+	default:
+		sb.WriteString(i18n.T("**过期**: %s\n", b.ExpiresAt.Local().Format("2006-01-02 15:04")))
+	}
+
+	var state AnalysisState
+	if err := json.Unmarshal([]byte(b.State), &state); err == nil {
+		sb.WriteString(i18n.T("\n**锚点** (%d):\n", len(state.ContextAnchors)))
+		for _, a := range state.ContextAnchors {
+			sb.WriteString(fmt.Sprintf("- `%s` @ %s:%d\n", a.Symbol, a.File, a.Line))
+		}
+		if len(state.Guardrails.Critical) > 0 {
+			sb.WriteString(i18n.T("\n**约束**:\n"))
+			for _, c := range state.Guardrails.Critical {
+				sb.WriteString("- " + c + "\n")
+			}
+		}
+	}
+	if !b.Expired(time.Now()) {
+		sb.WriteString(i18n.T("\n→ `manager_analyze(step=2, task_id=\"%s\")` 重新生成战术策略\n", b.ID))
+	}
+	return sb.String()
+}
+
+// chainStatusSuffix 任务链当前状态（仅内存中存在时显示）
+func chainStatusSuffix(sm *SessionManager, chainID string) string {
+	chain, ok := sm.TaskChainsV2[chainID]
+	if !ok {
+		return ""
+	}
+	done, total := chainProgress(chain)
+	return fmt.Sprintf(" (%s %d/%d)", chain.Status, done, total)
+}
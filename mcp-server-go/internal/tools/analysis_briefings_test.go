@@ -0,0 +1,70 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"mcp-server-go/internal/core"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAnalysisBriefingSurvivesRestart(t *testing.T) {
+	root := t.TempDir()
+	mem, err := core.NewMemoryLayer(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	sm := &SessionManager{Memory: mem, ProjectRoot: root}
+
+	args := AnalyzeArgs{TaskDescription: "重构登录流程", Intent: "REFACTOR"}
+	res, err := handleAnalyzeStep1(ctx, sm, nil, args, "analyze_test")
+	if err != nil || res.IsError {
+		t.Fatalf("step1: %v %s", err, getTextResult(t, res))
+	}
+
+	// 模拟重启：新的 SessionManager 共享同一个项目数据库
+	restarted := &SessionManager{Memory: mem, ProjectRoot: root}
+	for i := 0; i < 2; i++ {
+		res, _ := handleAnalyzeStep2(ctx, restarted, AnalyzeArgs{}, "analyze_test")
+		if res.IsError {
+			t.Fatalf("step2 run %d failed: %s", i+1, getTextResult(t, res))
+		}
+		var briefing MissionBriefing
+		if err := json.Unmarshal([]byte(getTextResult(t, res)), &briefing); err != nil {
+			t.Fatal(err)
+		}
+		if briefing.MissionControl.Intent != "REFACTOR" || briefing.StrategicHandoff == "" || !strings.Contains(briefing.NextStep, "analysis_id") {
+			t.Fatalf("briefing = %+v", briefing)
+		}
+	}
+
+	// 关联任务链后，过期时间不再生效
+	if res, _ := initTaskChainV2(restarted, "chain_login", "重构登录", []map[string]interface{}{{"name": "拆分"}}, "analyze_test"); res.IsError {
+		t.Fatalf("link failed: %s", getTextResult(t, res))
+	}
+	b, _ := mem.GetBriefing(ctx, "analyze_test")
+	b.ExpiresAt = time.Now().Add(-time.Hour)
+	_ = mem.SaveBriefing(ctx, b)
+	if res, _ := handleAnalyzeStep2(ctx, restarted, AnalyzeArgs{}, "analyze_test"); res.IsError || !strings.Contains(getTextResult(t, res), "chain_login") {
+		t.Fatalf("linked briefing should not expire: %s", getTextResult(t, res))
+	}
+	if restarted.TaskChainsV2["chain_login"].AnalysisID != "analyze_test" {
+		t.Error("chain should record analysis_id")
+	}
+
+	// 未关联且过期的简报：step2 报错，列表默认隐藏
+	_ = mem.SaveBriefing(ctx, &core.AnalysisBriefing{ID: "analyze_old", Intent: "DEBUG", State: `{}`, ExpiresAt: time.Now().Add(-time.Hour)})
+	if res, _ := handleAnalyzeStep2(ctx, restarted, AnalyzeArgs{}, "analyze_old"); !res.IsError {
+		t.Fatal("expired briefing should be rejected")
+	}
+	if res, _ := initTaskChainV2(restarted, "chain_old", "", []map[string]interface{}{{"name": "x"}}, "analyze_old"); !res.IsError {
+		t.Fatal("linking an expired briefing should fail")
+	}
+	list, _ := mem.ListBriefings(ctx, 10)
+	out := renderBriefingList(restarted, list, false, 10)
+	if strings.Contains(out, "analyze_old") || !strings.Contains(out, "已隐藏 1") || !strings.Contains(out, "🔗 chain_login") {
+		t.Fatalf("list:\n%s", out)
+	}
+}
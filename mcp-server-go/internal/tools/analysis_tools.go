@@ -2,10 +2,12 @@ package tools
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"mcp-server-go/internal/i18n"
 	"mcp-server-go/internal/services"
-	"os"
-	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/mark3labs/mcp-go/mcp"
@@ -23,12 +25,34 @@ type ProjectMapArgs struct {
 	Scope     string `json:"scope" jsonschema:"description=限定范围 (目录或文件路径，留空=整个项目)"`
 	Level     string `json:"level" jsonschema:"default=symbols,enum=structure,enum=symbols,description=视图层级"`
 	CorePaths string `json:"core_paths" jsonschema:"description=核心目录列表 (JSON 数组字符串)"`
+	DeltaFrom string `json:"delta_from" jsonschema:"description=附加复杂度趋势：previous=对比上一快照，或填写快照 ID"`
+	Format    string `json:"format" jsonschema:"default=markdown,enum=markdown,enum=json,enum=mermaid,description=输出格式"`
+	Cursor    string `json:"cursor" jsonschema:"description=分页游标 (取自上一页返回的 next_cursor，留空=第一页)"`
+	PageSize  int    `json:"page_size" jsonschema:"description=每页条目预算 (文件计 1 条，symbols 层每个符号再计 1 条，默认 200，上限 2000，可在 mpm.yaml 的 map 段调整)"`
+}
+
+// MetricsDeltaArgs 指标趋势参数
+type MetricsDeltaArgs struct {
+	Mode  string `json:"mode" jsonschema:"default=diff,enum=diff,enum=list,description=diff=对比两个快照 list=列出快照"`
+	From  int64  `json:"from" jsonschema:"description=起始快照 ID (默认: to 的上一个快照)"`
+	To    int64  `json:"to" jsonschema:"description=目标快照 ID (默认: 最新快照)"`
+	Scope string `json:"scope" jsonschema:"description=限定目录或文件 (留空=整个项目)"`
+	Limit int    `json:"limit" jsonschema:"default=10,description=每类变化最多显示条数 (list 模式为快照条数)"`
+}
+
+// StructureDiffArgs 结构变化参数
+type StructureDiffArgs struct {
+	Mode       string `json:"mode" jsonschema:"default=diff,enum=diff,enum=list,description=diff=对比快照与当前代码 list=列出结构快照"`
+	Since      string `json:"since" jsonschema:"description=基准：快照 ID 或日期 (2006-01-02 / 2006-01-02 15:04)，留空=本次会话初始化前的快照"`
+	Scope      string `json:"scope" jsonschema:"description=限定目录或文件 (留空=整个项目)"`
+	PublicOnly bool   `json:"public_only" jsonschema:"description=只看公开符号 (按语言惯例判断：Go 首字母大写、Rust pub、Python 非下划线等)"`
+	Limit      int    `json:"limit" jsonschema:"default=10,description=每类变化最多显示条数 (list 模式为快照条数)"`
 }
 
 // RegisterAnalysisTools 注册分析类工具
 func RegisterAnalysisTools(s *server.MCPServer, sm *SessionManager, ai *services.ASTIndexer) {
-	s.AddTool(mcp.NewTool("code_impact",
-		mcp.WithDescription(`code_impact - 代码修改影响分析
+	addTool(s, mcp.NewTool("code_impact",
+		mcp.WithDescription(i18n.Desc("code_impact", `code_impact - 代码修改影响分析
 
 用途：
   分析修改函数或类时的影响范围，识别需要同步修改的位置
@@ -54,12 +78,12 @@ func RegisterAnalysisTools(s *server.MCPServer, sm *SessionManager, ai *services
     -> 分析谁在调用 Login 函数
 
 触发词：
-  "mpm 影响", "mpm 依赖", "mpm impact"`),
+  "mpm 影响", "mpm 依赖", "mpm impact"`)),
 		mcp.WithInputSchema[ImpactArgs](),
 	), wrapImpact(sm, ai))
 
-	s.AddTool(mcp.NewTool("project_map",
-		mcp.WithDescription(`project_map - 你的项目导航仪 (当不知道代码在哪时)
+	addTool(s, mcp.NewTool("project_map",
+		mcp.WithDescription(i18n.Desc("project_map", `project_map - 你的项目导航仪 (当不知道代码在哪时)
 
 用途：
   【宏观视角】当你迷路了，或者不知道该改哪个文件时，用我。我会给你一张带导航的地图。
@@ -72,24 +96,114 @@ func RegisterAnalysisTools(s *server.MCPServer, sm *SessionManager, ai *services
   scope (可选)
     如果不填，默认看整个项目（可能会很长）。建议填入你感兴趣的目录。
 
+  delta_from (可选)
+    附加复杂度趋势："previous" 对比上一快照，或填写快照 ID（见 metrics_delta）
+
+  format (默认: markdown)
+    - markdown: 给人读的地图 + 复杂度热力图
+    - json: 结构化地图 (schema=project_map/v1)，目录 -> 文件 -> 符号，带复杂度分级与指标
+    - mermaid: flowchart 图，目录为 subgraph，按复杂度着色
+
+  cursor / page_size (可选)
+    大项目按文件分页返回。结果里有 next_cursor 时，带上它（其余参数不变）继续取下一页。
+
 返回：
-  一张 ASCII 格式的项目地图 + 复杂度热力图。
+  项目地图 + 复杂度标注；还有下一页时附带 next_cursor。
 
 触发词：
-  "mpm 地图", "mpm 结构", "mpm map"`),
+  "mpm 地图", "mpm 结构", "mpm map"`)),
 		mcp.WithInputSchema[ProjectMapArgs](),
 	), wrapProjectMap(sm, ai))
+
+	addTool(s, mcp.NewTool("metrics_delta",
+		mcp.WithDescription(i18n.Desc("metrics_delta", `metrics_delta - 复杂度趋势对比
+
+用途：
+  每次索引都会在指标变化时记录一份快照（时间 + Git 版本 + 每个符号/目录的复杂度）。
+  用我对比两份快照，判断模块是否在变复杂、重构是否真的有效。
+
+参数：
+  mode (默认: diff)
+    - diff: 对比两个快照
+    - list: 列出最近的快照
+  from / to (可选)
+    快照 ID。默认 to=最新，from=to 的上一个
+  scope (可选)
+    限定目录或文件
+
+返回：
+  新增/删除的符号、复杂度上升/下降、新出现的热点 (≥50)、目录级变化。
+
+触发词：
+  "mpm 趋势", "mpm 复杂度变化", "mpm delta"`)),
+		mcp.WithInputSchema[MetricsDeltaArgs](),
+	), wrapMetricsDelta(sm, ai))
+
+	addTool(s, mcp.NewTool("structure_diff",
+		mcp.WithDescription(i18n.Desc("structure_diff", `structure_diff - 结构变化 (上次会话之后改了什么)
+
+用途：
+  隔了几天回到项目，不知道哪些模块/文件/公开符号变了？用我。
+  每次 initialize_project 都会记录一份符号快照，我拿它和当前代码对比。
+
+参数：
+  mode (默认: diff)
+    - diff: 对比基准快照与当前代码
+    - list: 列出已记录的结构快照
+  since (可选)
+    基准快照 ID，或日期 ("2026-10-01" / "2026-10-01 18:00")，取该时刻之前的最后一个快照。
+    留空 = 本次会话初始化前的快照
+  scope (可选)
+    限定目录或文件
+  public_only (默认: false)
+    只看公开符号
+
+返回：
+  新增/删除的文件、新增/删除/跨文件移动/签名变化的符号，以及按目录的汇总。
+
+触发词：
+  "mpm 变化", "mpm 改了什么", "mpm changes"`)),
+		mcp.WithInputSchema[StructureDiffArgs](),
+	), wrapStructureDiff(sm, ai))
+
+	addTool(s, mcp.NewTool("refactor_preview",
+		mcp.WithDescription(i18n.Desc("refactor_preview", `refactor_preview - 重命名/移动预览 (只读，生成补丁)
+
+用途：
+  重命名被广泛调用的函数，或把它挪到另一个文件前，先看完整补丁。
+  不会修改任何文件。
+
+参数：
+  symbol_name (必填)
+    要重构的符号名
+  new_name (可选)
+    新名称
+  destination (可选)
+    目标文件相对路径（移动定义；可与 new_name 同时使用）
+  file (可选)
+    同名符号有多处定义时，指定定义所在文件
+  certain_only (默认: false)
+    补丁只改写 AST 确认的引用
+
+返回：
+  - 引用列表，标注 certain (AST: 定义 + 调用点) / possible (仅文本匹配)
+  - unified diff 补丁（可直接 git apply）
+
+触发词：
+  "mpm 重命名", "mpm 移动", "mpm rename"`)),
+		mcp.WithInputSchema[RefactorPreviewArgs](),
+	), wrapRefactorPreview(sm, ai))
 }
 
 func wrapImpact(sm *SessionManager, ai *services.ASTIndexer) server.ToolHandlerFunc {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		var args ImpactArgs
 		if err := request.BindArguments(&args); err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("参数格式错误: %v", err)), nil
+			return mcp.NewToolResultError(i18n.T("参数格式错误: %v", err)), nil
 		}
 
 		if sm.ProjectRoot == "" {
-			return mcp.NewToolResultError("项目尚未初始化，请先执行 initialize_project。"), nil
+			return mcp.NewToolResultError(i18n.T("项目尚未初始化，请先执行 initialize_project。")), nil
 		}
 
 		// 默认方向
@@ -100,89 +214,149 @@ func wrapImpact(sm *SessionManager, ai *services.ASTIndexer) server.ToolHandlerF
 		// 1. AST 静态分析 (硬调用)
 		astResult, err := ai.Analyze(sm.ProjectRoot, args.SymbolName, args.Direction)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("AST 分析失败: %v", err)), nil
+			return mcp.NewToolResultError(i18n.T("AST 分析失败: %v", err)), nil
 		}
 
 		if astResult == nil || astResult.Status != "success" {
-			errorMessage := fmt.Sprintf("⚠️ `%s` 不是代码函数/类定义。\n\n", args.SymbolName)
-			errorMessage += "> 如果要搜索**字符串**，用 **Grep** 工具\n"
-			errorMessage += "> 如果要查找**函数定义**，用 **code_search** 工具"
-			return mcp.NewToolResultText(errorMessage), nil
+			errorMessage := i18n.T("⚠️ `%s` 不是代码函数/类定义。\n\n", args.SymbolName)
+			errorMessage += i18n.T("> 如果要搜索**字符串**，用 **Grep** 工具\n")
+			errorMessage += i18n.T("> 如果要查找**函数定义**，用 **code_search** 工具")
+			data := map[string]any{"symbol": args.SymbolName, "found": false}
+			return mcp.NewToolResultStructured(data, errorMessage), nil
 		}
 
 		// 2. 精简输出 (面向 LLM 决策)
 		var sb strings.Builder
-		sb.WriteString(fmt.Sprintf("## `%s` 影响分析\n\n", args.SymbolName))
-		sb.WriteString(fmt.Sprintf("**风险**: %s | **复杂度**: %.0f | **影响节点**: %d\n\n",
-			astResult.RiskLevel, astResult.ComplexityScore, astResult.AffectedNodes))
+		sb.WriteString(i18n.T("## `%s` 影响分析\n\n", args.SymbolName))
+		if doc := services.DocSummary(args.SymbolName, astResult.Doc, 200); doc != "" {
+			sb.WriteString(fmt.Sprintf("> 📖 %s\n\n", doc))
+		}
+		sb.WriteString(i18n.T("**风险**: %s | **复杂度**: %.0f | **耦合度**: %.0f | **影响节点**: %d\n\n",
+			astResult.RiskLevel, astResult.ComplexityScore, astResult.CouplingScore, astResult.AffectedNodes))
+		if m := astResult.Metrics; m != nil {
+			sb.WriteString(i18n.T("**函数指标**: 圈复杂度 %d | 认知复杂度 %d | %d 行 | %d 参数 | 嵌套 %d 层\n\n",
+				m.Cyclomatic, m.Cognitive, m.LineCount, m.ParamCount, m.MaxNesting))
+			if reasons := m.Reasons(); len(reasons) > 0 {
+				sb.WriteString(fmt.Sprintf("> ⚠️ %s\n\n", strings.Join(reasons, ", ")))
+			}
+		}
 
 		// 直接调用者列表
 		if len(astResult.DirectCallers) > 0 {
-			sb.WriteString("### 直接调用者（修改前必须检查）\n")
+			sb.WriteString(i18n.T("### 直接调用者（修改前必须检查）\n"))
 			limit := 10
 			if len(astResult.DirectCallers) < limit {
 				limit = len(astResult.DirectCallers)
 			}
 			for i := 0; i < limit; i++ {
 				c := astResult.DirectCallers[i]
-				sb.WriteString(fmt.Sprintf("- `%s` @ %s:%d\n", c.Node.Name, c.Node.FilePath, c.Node.LineStart))
+				sb.WriteString(fmt.Sprintf("- `%s` @ %s:%d%s\n", c.Node.Name, c.Node.FilePath, c.Node.LineStart, docSuffix(c.Node, 80)))
 			}
 			if len(astResult.DirectCallers) > limit {
-				sb.WriteString(fmt.Sprintf("- ... 还有 %d 个\n", len(astResult.DirectCallers)-limit))
+				sb.WriteString(i18n.T("- ... 还有 %d 个\n", len(astResult.DirectCallers)-limit))
 			}
 		} else {
-			sb.WriteString("✅ 无直接调用者，可安全修改\n")
+			sb.WriteString(i18n.T("✅ 无直接调用者，可安全修改\n"))
 		}
 
 		// 间接调用总数
 		if len(astResult.IndirectCallers) > 0 {
-			sb.WriteString(fmt.Sprintf("\n_间接影响: %d 个函数_\n", len(astResult.IndirectCallers)))
+			sb.WriteString(i18n.T("\n_间接影响: %d 个函数_\n", len(astResult.IndirectCallers)))
 		}
 
 		// JSON：直接调用者 + 间接调用者（按距离，前20个）
+		data := newImpactData(args.SymbolName, args.Direction, astResult)
+		brief, err := json.Marshal(data.impactBrief)
+		if err != nil {
+			return mcp.NewToolResultError(i18n.T("JSON 序列化失败: %v", err)), nil
+		}
 		sb.WriteString("\n```json\n")
-		sb.WriteString(fmt.Sprintf(`{"risk":"%s","direct_count":%d,"indirect_count":%d,"callers":[`,
-			astResult.RiskLevel, len(astResult.DirectCallers), len(astResult.IndirectCallers)))
+		sb.Write(brief)
+		sb.WriteString("\n```\n")
 
-		// 直接调用者
-		for i, c := range astResult.DirectCallers {
-			if i >= 10 {
-				break
-			}
-			if i > 0 {
-				sb.WriteString(",")
-			}
-			sb.WriteString(fmt.Sprintf(`"%s"`, c.Node.Name))
-		}
+		return mcp.NewToolResultStructured(data, sb.String()), nil
+	}
+}
 
-		// 间接调用者（前20个，BFS已按距离排序）
-		indirectLimit := 20
-		if len(astResult.IndirectCallers) < indirectLimit {
-			indirectLimit = len(astResult.IndirectCallers)
-		}
-		for i := 0; i < indirectLimit; i++ {
-			c := astResult.IndirectCallers[i]
-			if i > 0 || len(astResult.DirectCallers) > 0 {
-				sb.WriteString(",")
-			}
-			sb.WriteString(fmt.Sprintf(`"%s"`, c.Node.Name))
-		}
+// impactBrief Markdown 末尾的紧凑 JSON：直接调用者前 10 个 + 间接调用者前 20 个 (按距离)
+type impactBrief struct {
+	Risk          string   `json:"risk"`
+	Complexity    float64  `json:"complexity"`
+	DirectCount   int      `json:"direct_count"`
+	IndirectCount int      `json:"indirect_count"`
+	Callers       []string `json:"callers"`
+}
+
+// impactCaller 调用者位置
+type impactCaller struct {
+	Name     string `json:"name"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	CallType string `json:"call_type,omitempty"`
+}
 
-		sb.WriteString("]}\n```\n")
+// impactData code_impact 的结构化结果 (output_format=json)
+type impactData struct {
+	Symbol    string `json:"symbol"`
+	Direction string `json:"direction"`
+	impactBrief
+	Coupling        float64                 `json:"coupling"`
+	AffectedNodes   int                     `json:"affected_nodes"`
+	Metrics         *services.SymbolMetrics `json:"metrics,omitempty"`
+	Doc             string                  `json:"doc,omitempty"`
+	DirectCallers   []impactCaller          `json:"direct_callers"`
+	IndirectCallers []impactCaller          `json:"indirect_callers"`
+	Checklist       []string                `json:"modification_checklist,omitempty"`
+}
 
-		return mcp.NewToolResultText(sb.String()), nil
+func newImpactData(symbol, direction string, r *services.ImpactResult) *impactData {
+	const directLimit, indirectLimit = 10, 20
+	data := &impactData{
+		Symbol:    symbol,
+		Direction: direction,
+		impactBrief: impactBrief{
+			Risk:          r.RiskLevel,
+			Complexity:    round1(r.ComplexityScore),
+			DirectCount:   len(r.DirectCallers),
+			IndirectCount: len(r.IndirectCallers),
+			Callers:       []string{},
+		},
+		Coupling:        round1(r.CouplingScore),
+		AffectedNodes:   r.AffectedNodes,
+		Metrics:         r.Metrics,
+		Doc:             r.Doc,
+		DirectCallers:   []impactCaller{},
+		IndirectCallers: []impactCaller{},
+		Checklist:       r.ModificationChecklist,
+	}
+	toCaller := func(c services.CallerInfo) impactCaller {
+		return impactCaller{Name: c.Node.Name, File: c.Node.FilePath, Line: c.Node.LineStart, CallType: c.CallType}
+	}
+	for i, c := range r.DirectCallers {
+		data.DirectCallers = append(data.DirectCallers, toCaller(c))
+		if i < directLimit {
+			data.Callers = append(data.Callers, c.Node.Name)
+		}
 	}
+	for i, c := range r.IndirectCallers {
+		if i >= indirectLimit {
+			break
+		}
+		data.IndirectCallers = append(data.IndirectCallers, toCaller(c))
+		data.Callers = append(data.Callers, c.Node.Name)
+	}
+	return data
 }
 
 func wrapProjectMap(sm *SessionManager, ai *services.ASTIndexer) server.ToolHandlerFunc {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		var args ProjectMapArgs
 		if err := request.BindArguments(&args); err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("参数错误: %v", err)), nil
+			return mcp.NewToolResultError(i18n.T("参数错误: %v", err)), nil
 		}
 
 		if sm.ProjectRoot == "" {
-			return mcp.NewToolResultError("项目未初始化，请先执行 initialize_project"), nil
+			return mcp.NewToolResultError(i18n.T("项目未初始化，请先执行 initialize_project")), nil
 		}
 
 		// 🆕 【关键】先刷新索引，确保数据最新
@@ -193,11 +367,22 @@ func wrapProjectMap(sm *SessionManager, ai *services.ASTIndexer) server.ToolHand
 			level = "symbols"
 		}
 
+		format := args.Format
+		if format == "" {
+			format = "markdown"
+			if wantsJSON(request) {
+				format = "json"
+			}
+		}
+		if format != "markdown" && format != "json" && format != "mermaid" {
+			return mcp.NewToolResultError(i18n.T("不支持的 format: %s (可选 markdown/json/mermaid)", format)), nil
+		}
+
 		// 调用 AST 服务生成数据
 		// 注意：如果 scope 为空，底层会自动处理为整个项目
 		result, err := ai.MapProjectWithScope(sm.ProjectRoot, level, args.Scope)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("生成地图失败: %v", err)), nil
+			return mcp.NewToolResultError(i18n.T("生成地图失败: %v", err)), nil
 		}
 
 		// 🆕 收集所有符号名并分析复杂度
@@ -217,8 +402,12 @@ func wrapProjectMap(sm *SessionManager, ai *services.ASTIndexer) server.ToolHand
 			if err == nil && complexityReport != nil {
 				// 构建复杂度映射
 				result.ComplexityMap = make(map[string]float64)
+				result.MetricsMap = make(map[string]services.SymbolMetrics)
 				for _, risk := range complexityReport.HighRiskSymbols {
 					result.ComplexityMap[risk.SymbolName] = risk.Score
+					if risk.Metrics != nil {
+						result.MetricsMap[risk.SymbolName] = *risk.Metrics
+					}
 				}
 			}
 		}
@@ -226,31 +415,180 @@ func wrapProjectMap(sm *SessionManager, ai *services.ASTIndexer) server.ToolHand
 		// 使用 MapRenderer 渲染结果
 		mr := NewMapRenderer(result, sm.ProjectRoot)
 
+		cfg := sm.config()
+		pageSize := args.PageSize
+		if pageSize <= 0 {
+			pageSize = cfg.Map.PageSize
+		}
+		if pageSize > cfg.Map.MaxPageSize {
+			pageSize = cfg.Map.MaxPageSize
+		}
+		page, err := paginateMap(result, level, args.Scope, args.Cursor, pageSize)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		// 复杂度趋势仅附加在第一页；各 format 的结构化内容都是 JSON 地图
+		var delta *services.MetricDelta
+		var deltaErr error
+		if args.DeltaFrom != "" && page.Offset == 0 {
+			delta, deltaErr = loadMapDelta(sm.ProjectRoot, args.DeltaFrom, args.Scope)
+		}
+		out := mr.JSONMap(level, args.Scope, page, delta, deltaErr)
+
 		var content string
-		switch level {
-		case "structure":
+		switch format {
+		case "json":
+			data, err := json.MarshalIndent(out, "", "  ")
+			if err != nil {
+				return mcp.NewToolResultError(i18n.T("序列化地图失败: %v", err)), nil
+			}
+			return mcp.NewToolResultStructured(out, string(data)), nil
+		case "mermaid":
+			return mcp.NewToolResultStructured(out, mr.RenderMermaid(level, page)), nil
+		}
+
+		switch {
+		case level == "structure":
+			// 结构视图自带自适应折叠，体量可控，不分页
 			content = mr.RenderOverview()
-		default: // symbols
+		case page.Offset == 0 && !page.HasMore():
 			content = mr.RenderStandard()
+		default:
+			content = mr.RenderPage(page)
+		}
+
+		// 附加复杂度趋势 (仅第一页)
+		if args.DeltaFrom != "" && page.Offset == 0 {
+			content += "\n" + renderMapDelta(delta, deltaErr)
+		}
+
+		return mcp.NewToolResultStructured(out, content), nil
+	}
+}
+
+// renderMapDelta 为 project_map 生成趋势附录，失败时返回提示而不是中断地图输出
+func renderMapDelta(delta *services.MetricDelta, err error) string {
+	if err != nil {
+		return fmt.Sprintf("> ⚠️ %v\n", err)
+	}
+	return renderMetricDelta(delta, 5)
+}
+
+// loadMapDelta 解析 delta_from 并对比快照
+func loadMapDelta(projectRoot, deltaFrom, scope string) (*services.MetricDelta, error) {
+	var fromID int64
+	if deltaFrom != "previous" {
+		id, err := strconv.ParseInt(deltaFrom, 10, 64)
+		if err != nil {
+			return nil, errors.New(i18n.T("delta_from 无效: `%s` (应为 previous 或快照 ID)", deltaFrom))
+		}
+		fromID = id
+	}
+
+	delta, err := services.DiffMetricSnapshots(projectRoot, fromID, 0, scope)
+	if err != nil {
+		return nil, errors.New(i18n.T("无法生成趋势: %v", err))
+	}
+	return delta, nil
+}
+
+func wrapMetricsDelta(sm *SessionManager, ai *services.ASTIndexer) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var args MetricsDeltaArgs
+		if err := request.BindArguments(&args); err != nil {
+			return mcp.NewToolResultError(i18n.T("参数错误: %v", err)), nil
+		}
+
+		if sm.ProjectRoot == "" {
+			return mcp.NewToolResultError(i18n.T("项目未初始化，请先执行 initialize_project")), nil
+		}
+
+		// 先刷新索引，确保最新状态已记录为快照
+		_, _ = ai.Index(sm.ProjectRoot)
+
+		limit := args.Limit
+		if limit <= 0 {
+			limit = 10
+		}
+
+		if args.Mode == "list" {
+			snapshots, err := services.ListMetricSnapshots(sm.ProjectRoot, limit)
+			if err != nil {
+				return mcp.NewToolResultError(i18n.T("读取快照失败: %v", err)), nil
+			}
+			return mcp.NewToolResultStructured(&snapshotListData{Snapshots: snapshots}, renderSnapshotList(snapshots)), nil
+		}
+
+		delta, err := services.DiffMetricSnapshots(sm.ProjectRoot, args.From, args.To, args.Scope)
+		if err != nil {
+			return mcp.NewToolResultError(i18n.T("趋势对比失败: %v", err)), nil
+		}
+		return mcp.NewToolResultStructured(delta, renderMetricDelta(delta, limit)), nil
+	}
+}
+
+func wrapStructureDiff(sm *SessionManager, ai *services.ASTIndexer) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var args StructureDiffArgs
+		if err := request.BindArguments(&args); err != nil {
+			return mcp.NewToolResultError(i18n.T("参数错误: %v", err)), nil
+		}
+
+		if sm.ProjectRoot == "" {
+			return mcp.NewToolResultError(i18n.T("项目未初始化，请先执行 initialize_project")), nil
+		}
+
+		limit := args.Limit
+		if limit <= 0 {
+			limit = 10
 		}
 
-		// 🆕 主动接管大输出：如果 > 2000 字符，保存到文件
-		if len(content) > 2000 {
-			mcpDataDir := filepath.Join(sm.ProjectRoot, ".mcp-data")
-			_ = os.MkdirAll(mcpDataDir, 0755)
+		if args.Mode == "list" {
+			snapshots, err := services.ListStructureSnapshots(sm.ProjectRoot, limit)
+			if err != nil {
+				return mcp.NewToolResultError(i18n.T("读取快照失败: %v", err)), nil
+			}
+			return mcp.NewToolResultStructured(&snapshotListData{Snapshots: snapshots}, renderStructureSnapshotList(snapshots)), nil
+		}
 
-			// 按模式固定命名，每次直接覆盖（不保留历史版本）
-			filename := fmt.Sprintf("project_map_%s.md", level)
-			outputPath := filepath.Join(mcpDataDir, filename)
+		// 先刷新索引，与最新代码对比
+		_, _ = ai.Index(sm.ProjectRoot)
 
-			if err := os.WriteFile(outputPath, []byte(content), 0644); err == nil {
-				return mcp.NewToolResultText(fmt.Sprintf(
-					"⚠️ Map 内容较长 (%d chars)，已自动保存到项目文件：\n👉 `%s`\n\n请使用 view_file 查看。",
-					len(content), outputPath)), nil
+		fromID := sm.StructureBaselineID
+		var note string
+		if args.Since != "" || fromID == 0 {
+			snap, n, err := services.FindStructureSnapshot(sm.ProjectRoot, args.Since)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
 			}
-			// 如果保存失败，降级回直接返回
+			if snap == nil {
+				return mcp.NewToolResultError(i18n.T("尚无结构快照，initialize_project 时会自动记录")), nil
+			}
+			fromID, note = snap.ID, n
+		}
+
+		diff, err := services.DiffStructure(sm.ProjectRoot, fromID, args.Scope, args.PublicOnly)
+		if err != nil {
+			return mcp.NewToolResultError(i18n.T("结构对比失败: %v", err)), nil
 		}
 
-		return mcp.NewToolResultText(content), nil
+		var sb strings.Builder
+		if note != "" {
+			sb.WriteString(fmt.Sprintf("> ⚠️ %s\n\n", note))
+		}
+		sb.WriteString(renderStructureDiff(diff, limit))
+		return mcp.NewToolResultStructured(&structureDiffData{StructureDiff: diff, Note: note}, sb.String()), nil
 	}
 }
+
+// snapshotListData metrics_delta / structure_diff 列出快照时的结构化结果
+type snapshotListData struct {
+	Snapshots any `json:"snapshots"`
+}
+
+// structureDiffData structure_diff 的结构化结果；note 为按日期回退到最近快照等提示
+type structureDiffData struct {
+	*services.StructureDiff
+	Note string `json:"note,omitempty"`
+}
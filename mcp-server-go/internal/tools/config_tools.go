@@ -0,0 +1,230 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"mcp-server-go/internal/i18n"
+	"mcp-server-go/internal/services"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"gopkg.in/yaml.v3"
+)
+
+// legacyProjectConfigFile 旧版 .mcp-data 下的项目配置，已并入 mpm.yaml
+const legacyProjectConfigFile = "project_config.json"
+
+// projectConfigTemplate config(mode="init") 写入的模板，取值即内置默认
+const projectConfigTemplate = `# MPM 项目配置 (内置默认 < ~/.mpm/mpm.yaml < .mcp-config/mpm.yaml)
+# 只需保留要修改的字段；修改后下一次工具调用自动生效
+version: 1
+
+map:
+  page_size: 200        # project_map 每页条目预算
+  max_page_size: 2000   # 单页上限 (page_size 参数也不能超过)
+
+recall:
+  limit: 20             # system_recall 默认返回条数
+
+analyze:
+  anchor_limit: 10          # manager_analyze 参与符号预搜索的 symbols 上限
+  directive_max_chars: 300  # 简报中任务描述的截断长度
+  briefing_ttl_hours: 72    # 分析简报默认保留时长
+
+search:
+  max_results: 100      # code_grep 默认匹配上限
+
+ignore:
+  # dirs: [...]         # 替换内置忽略目录列表
+  extra_dirs: []        # 追加忽略目录 (复杂规则请写 .mpmignore)
+
+dev_log:
+  window: 100           # 同步到 dev-log.md 的最近 memo 条数
+
+wiki:
+  output_dir: docs/wiki # wiki_writer generate 默认输出目录
+
+locale: zh              # 界面语言 (zh/en，或 locales/<lang>.yaml 扩展)；环境变量 MPM_LANG 优先
+`
+
+// ConfigArgs 项目配置参数
+type ConfigArgs struct {
+	Mode string `json:"mode,omitempty" jsonschema:"default=show,enum=show,enum=reload,enum=init,description=show 查看生效配置；reload 强制重新加载；init 生成 .mcp-config/mpm.yaml 模板"`
+}
+
+func registerConfigTool(s *server.MCPServer, sm *SessionManager) {
+	addTool(s, mcp.NewTool("config",
+		mcp.WithDescription(i18n.Desc("config", `config - 项目配置 (阈值、忽略目录与各类上限)
+
+用途：
+  查看或重新加载 mpm.yaml。配置按 内置默认 < 全局 ~/.mpm/mpm.yaml < 项目 .mcp-config/mpm.yaml 合并，
+  每层只需写要覆盖的字段。
+
+参数：
+  mode (默认: show)
+    - show: 展示生效配置、来源文件、相对默认值的改动与校验警告
+    - reload: 丢弃缓存重新加载 (通常不需要，文件变化后会自动重载)
+    - init: 在 .mcp-config/ 下生成带注释的 mpm.yaml 模板 (已存在时不覆盖)
+
+说明：
+  - 配置项：map.page_size / map.max_page_size、recall.limit、analyze.anchor_limit /
+    directive_max_chars / briefing_ttl_hours、search.max_results、ignore.dirs / extra_dirs、
+    dev_log.window、wiki.output_dir、locale。
+  - 未知字段、超出范围的取值会让该层文件整体被忽略，并在 show 与 initialize_project 中提示。
+  - 旧版 .mcp-data/project_config.json 已并入本配置，初始化时自动清理。
+  - locale 切换后错误与结果文本随即生效，工具描述需重启服务后更新。
+
+示例：
+  config()
+    -> 查看生效配置
+  config(mode="init")
+    -> 生成项目配置模板
+
+触发词：
+  "mpm 配置", "mpm config"`)),
+		mcp.WithInputSchema[ConfigArgs](),
+	), wrapConfig(sm))
+}
+
+func wrapConfig(sm *SessionManager) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var args ConfigArgs
+		if err := request.BindArguments(&args); err != nil {
+			return mcp.NewToolResultError(i18n.T("参数错误: %v", err)), nil
+		}
+
+		switch args.Mode {
+		case "", "show":
+			cfg, warnings := services.ProjectConfigFor(sm.ProjectRoot)
+			return mcp.NewToolResultStructured(newConfigData(cfg, warnings), renderProjectConfig(sm, cfg, warnings, "")), nil
+		case "reload":
+			warnings := sm.ReloadConfig()
+			cfg := sm.config()
+			return mcp.NewToolResultStructured(newConfigData(cfg, warnings), renderProjectConfig(sm, cfg, warnings, i18n.T("🔄 已重新加载配置"))), nil
+		case "init":
+			if sm.ProjectRoot == "" {
+				return mcp.NewToolResultError(i18n.T("项目尚未初始化，请先执行 initialize_project")), nil
+			}
+			path := filepath.Join(services.ProjectConfigDir(sm.ProjectRoot), services.ProjectConfigFile)
+			if _, err := os.Stat(path); err == nil {
+				return mcp.NewToolResultError(i18n.T("%s 已存在，请直接编辑", path)), nil
+			}
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				return mcp.NewToolResultError(i18n.T("创建配置目录失败: %v", err)), nil
+			}
+			if err := os.WriteFile(path, []byte(projectConfigTemplate), 0644); err != nil {
+				return mcp.NewToolResultError(i18n.T("写入配置失败: %v", err)), nil
+			}
+			return mcp.NewToolResultStructured(map[string]interface{}{"path": path},
+				i18n.T("✅ 已生成配置模板: %s\n\n按需修改后，下一次工具调用自动生效。", path)), nil
+		default:
+			return mcp.NewToolResultError(i18n.T("未知 mode: %s (可选 show/reload/init)", args.Mode)), nil
+		}
+	}
+}
+
+// configData config 的结构化结果 (output_format=json)
+type configData struct {
+	Config   *services.ProjectConfig `json:"config"`
+	Sources  []string                `json:"sources"`
+	Changed  []string                `json:"changed"`
+	Warnings []string                `json:"warnings"`
+}
+
+func newConfigData(cfg *services.ProjectConfig, warnings []string) *configData {
+	data := &configData{Config: cfg, Sources: cfg.Sources, Changed: []string{}, Warnings: []string{}}
+	def, cur := flattenConfig(services.DefaultProjectConfig()), flattenConfig(cfg)
+	for k, v := range cur {
+		if k != "version" && !reflect.DeepEqual(def[k], v) {
+			data.Changed = append(data.Changed, k)
+		}
+	}
+	sort.Strings(data.Changed)
+	data.Warnings = append(data.Warnings, warnings...)
+	return data
+}
+
+// renderProjectConfig 生效配置 + 来源 + 相对默认值的改动 + 警告
+func renderProjectConfig(sm *SessionManager, cfg *services.ProjectConfig, warnings []string, header string) string {
+	var sb strings.Builder
+	if header != "" {
+		sb.WriteString(header + "\n\n")
+	}
+	sb.WriteString(i18n.T("### ⚙️ 项目配置\n\n"))
+	if sm.ProjectRoot != "" {
+		sb.WriteString(i18n.T("- 项目: `%s`\n", sm.ProjectRoot))
+	} else {
+		sb.WriteString(i18n.T("- 项目: 未初始化 (仅内置默认与全局配置)\n"))
+	}
+	if !sm.InitializedAt.IsZero() {
+		sb.WriteString(i18n.T("- 本次初始化: %s\n", sm.InitializedAt.Format("2006-01-02 15:04:05")))
+	}
+	sb.WriteString(i18n.T("- 生效来源: %s\n", strings.Join(cfg.Sources, " → ")))
+
+	if changed := changedConfigKeys(services.DefaultProjectConfig(), cfg); len(changed) > 0 {
+		sb.WriteString(i18n.T("\n**相对默认值的改动**：\n"))
+		for _, c := range changed {
+			sb.WriteString("- " + c + "\n")
+		}
+	}
+	if len(warnings) > 0 {
+		sb.WriteString(i18n.T("\n⚠️ %d 个配置文件被忽略：\n", len(warnings)))
+		for _, w := range warnings {
+			sb.WriteString("- " + w + "\n")
+		}
+	}
+
+	data, err := yaml.Marshal(cfg)
+	if err == nil {
+		sb.WriteString("\n```yaml\n")
+		sb.Write(data)
+		sb.WriteString("```\n")
+	}
+	if sm.ProjectRoot != "" {
+		path := filepath.Join(services.ProjectConfigDir(sm.ProjectRoot), services.ProjectConfigFile)
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			sb.WriteString(i18n.T("\n> 💡 项目尚无 mpm.yaml，可用 `config(mode=\"init\")` 生成模板。\n"))
+		}
+	}
+	return sb.String()
+}
+
+// changedConfigKeys 以 "key: 默认 → 当前" 列出与默认值不同的配置项
+func changedConfigKeys(def, cfg *services.ProjectConfig) []string {
+	a, b := flattenConfig(def), flattenConfig(cfg)
+	var out []string
+	for k, v := range b {
+		if k == "version" {
+			continue
+		}
+		if !reflect.DeepEqual(a[k], v) {
+			out = append(out, fmt.Sprintf("`%s`: %v → %v", k, a[k], v))
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func flattenConfig(cfg *services.ProjectConfig) map[string]interface{} {
+	data, _ := json.Marshal(cfg)
+	var raw map[string]interface{}
+	_ = json.Unmarshal(data, &raw)
+	delete(raw, "sources")
+	flat := make(map[string]interface{})
+	for section, v := range raw {
+		if m, ok := v.(map[string]interface{}); ok {
+			for k, vv := range m {
+				flat[section+"."+k] = vv
+			}
+			continue
+		}
+		flat[section] = v
+	}
+	return flat
+}
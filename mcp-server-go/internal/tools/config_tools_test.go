@@ -0,0 +1,65 @@
+package tools
+
+import (
+	"context"
+	"mcp-server-go/internal/services"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func callConfig(t *testing.T, sm *SessionManager, mode string) (string, bool) {
+	t.Helper()
+	res, err := wrapConfig(sm)(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{
+		Name:      "config",
+		Arguments: map[string]any{"mode": mode},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return getTextResult(t, res), res.IsError
+}
+
+func TestConfigTool(t *testing.T) {
+	t.Setenv("MPM_HOME", t.TempDir())
+	root := t.TempDir()
+	sm := &SessionManager{ProjectRoot: root}
+
+	text, isErr := callConfig(t, sm, "show")
+	if isErr || !strings.Contains(text, "生效来源: builtin") || !strings.Contains(text, "page_size: 200") || !strings.Contains(text, `config(mode="init")`) {
+		t.Fatalf("show should render defaults: %s", text)
+	}
+
+	if text, isErr = callConfig(t, sm, "init"); isErr {
+		t.Fatalf("init failed: %s", text)
+	}
+	path := filepath.Join(root, ".mcp-config", "mpm.yaml")
+	if cfg, warnings := services.LoadProjectConfig(root); len(warnings) != 0 || len(cfg.Sources) != 2 {
+		t.Fatalf("generated template must be valid: %v %v", warnings, cfg.Sources)
+	}
+	if _, isErr = callConfig(t, sm, "init"); !isErr {
+		t.Fatalf("init should not overwrite an existing file")
+	}
+
+	if err := os.WriteFile(path, []byte("recall:\n  limit: 5\ndev_log:\n  window: 0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	text, _ = callConfig(t, sm, "show")
+	if !strings.Contains(text, "1 个配置文件被忽略") || !strings.Contains(text, "dev_log.window") {
+		t.Fatalf("invalid file should be reported: %s", text)
+	}
+
+	if err := os.WriteFile(path, []byte("recall:\n  limit: 5\nanalyze:\n  anchor_limit: 3\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	text, _ = callConfig(t, sm, "reload")
+	if !strings.Contains(text, "已重新加载") || !strings.Contains(text, "`recall.limit`: 20 → 5") || !strings.Contains(text, "`analyze.anchor_limit`: 10 → 3") {
+		t.Fatalf("reload should show changed keys: %s", text)
+	}
+	if sm.config().Recall.Limit != 5 {
+		t.Fatalf("session config should follow the file: %+v", sm.config().Recall)
+	}
+}
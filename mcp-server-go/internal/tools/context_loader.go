@@ -0,0 +1,390 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"mcp-server-go/internal/core"
+	"mcp-server-go/internal/i18n"
+	"mcp-server-go/internal/services"
+	"path"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// ============================================================================
+// manager_analyze 相关上下文加载
+// 按与任务的相关度给铁律/备忘打分：锚点文件与符号 > 调用方 (code_impact 同源) > 任务关键词重合，
+// 去重后在 token 预算内贪心挑选
+// ============================================================================
+
+const (
+	contextTokenBudget   = 800 // 铁律 + 备忘合计的 token 预算
+	contextFactPool      = 200 // 参与排序的候选铁律数
+	contextMemoPool      = 300 // 参与排序的候选备忘数
+	contextMinFacts      = 3   // 相关铁律不足时用最新铁律补足
+	contextCallerLimit   = 10  // 每个锚点最多取的调用方数
+	contextItemRuneLimit = 200 // 单条上下文的最大字符数
+	contextDupThreshold  = 0.8 // 词项 Jaccard 相似度超过此值视为重复
+)
+
+// 相关度权重
+const (
+	weightAnchorFile   = 6.0
+	weightAnchorSymbol = 4.0
+	weightCallerFile   = 3.0
+	weightCallerSymbol = 2.0
+	weightKeywords     = 4.0 // 乘以关键词重合率
+)
+
+// contextItem 一条候选上下文（铁律或备忘）
+type contextItem struct {
+	Kind    string // fact | memo
+	ID      int64
+	Text    string
+	Score   float64
+	Reasons []string
+
+	path   string
+	terms  map[string]bool
+	tokens int
+}
+
+// relevanceSignals 用于打分的任务信号
+type relevanceSignals struct {
+	anchorFiles   map[string]bool
+	anchorSymbols []string // 小写，保持锚点顺序
+	callerFiles   map[string]bool
+	callerSymbols []string
+	taskTerms     map[string]bool
+}
+
+// ContextLoadStats 上下文加载统计，写入 telemetry
+type ContextLoadStats struct {
+	Candidates int `json:"candidates"`
+	Duplicates int `json:"duplicates"`
+	Selected   int `json:"selected"`
+	Tokens     int `json:"tokens"`
+	Budget     int `json:"budget"`
+	Callers    int `json:"callers"`
+}
+
+// loadRelevantContext 按相关度加载铁律与备忘
+func loadRelevantContext(ctx context.Context, sm *SessionManager, ai *services.ASTIndexer, desc string, anchors []CodeAnchor) (facts []string, memos []string, stats ContextLoadStats) {
+	stats.Budget = contextTokenBudget
+	if sm.Memory == nil {
+		return nil, nil, stats
+	}
+
+	var callers []services.Node
+	if ai != nil && len(anchors) > 0 {
+		var names []string
+		for _, a := range anchors {
+			names = append(names, anchorName(a.Symbol))
+		}
+		callers, _ = ai.DirectCallers(sm.ProjectRoot, names, contextCallerLimit)
+	}
+	stats.Callers = len(callers)
+	signals := newRelevanceSignals(desc, anchors, callers)
+
+	knownFacts, _ := sm.Memory.QueryFacts(ctx, "", contextFactPool)
+	recentMemos, _ := sm.Memory.QueryMemos(ctx, "", "", contextMemoPool)
+
+	var candidates []*contextItem
+	for _, f := range knownFacts {
+		candidates = append(candidates, newFactItem(f))
+	}
+	for _, m := range recentMemos {
+		candidates = append(candidates, newMemoItem(m))
+	}
+	stats.Candidates = len(candidates)
+
+	for _, item := range candidates {
+		scoreContextItem(item, signals)
+	}
+	selected, dups := selectContextItems(candidates, contextTokenBudget, contextMinFacts)
+	stats.Duplicates = dups
+
+	for _, item := range selected {
+		stats.Tokens += item.tokens
+		switch item.Kind {
+		case "fact":
+			facts = append(facts, item.Text)
+		case "memo":
+			memo := item.Text
+			if len(item.Reasons) > 0 {
+				memo += " ← " + strings.Join(item.Reasons, ", ")
+			}
+			memos = append(memos, memo)
+		}
+	}
+	stats.Selected = len(selected)
+	return facts, memos, stats
+}
+
+// anchorName 取限定名的最后一段 (pkg.Type.Method -> Method)
+func anchorName(symbol string) string {
+	if i := strings.LastIndexAny(symbol, ".:"); i >= 0 && i+1 < len(symbol) {
+		return symbol[i+1:]
+	}
+	return symbol
+}
+
+func newRelevanceSignals(desc string, anchors []CodeAnchor, callers []services.Node) relevanceSignals {
+	s := relevanceSignals{
+		anchorFiles: make(map[string]bool),
+		callerFiles: make(map[string]bool),
+		taskTerms:   make(map[string]bool),
+	}
+	seen := make(map[string]bool)
+	for _, a := range anchors {
+		if a.File != "" {
+			s.anchorFiles[normalizeContextPath(a.File)] = true
+		}
+		if name := strings.ToLower(anchorName(a.Symbol)); !seen[name] {
+			seen[name] = true
+			s.anchorSymbols = append(s.anchorSymbols, name)
+		}
+	}
+	for _, c := range callers {
+		file := normalizeContextPath(c.FilePath)
+		if !s.anchorFiles[file] {
+			s.callerFiles[file] = true
+		}
+		if name := strings.ToLower(c.Name); !seen[name] {
+			seen[name] = true
+			s.callerSymbols = append(s.callerSymbols, name)
+		}
+	}
+	for _, t := range services.ConceptTerms(desc) {
+		s.taskTerms[t] = true
+	}
+	return s
+}
+
+func newFactItem(f core.KnownFact) *contextItem {
+	text := truncateLine(fmt.Sprintf("[%s] %s", f.Type, strings.TrimSpace(f.Summarize)), contextItemRuneLimit)
+	return &contextItem{Kind: "fact", ID: f.ID, Text: text, terms: termSet(f.Summarize), tokens: estimateTokens(text)}
+}
+
+func newMemoItem(m core.Memo) *contextItem {
+	p := ""
+	if m.Path != "" && m.Path != "-" {
+		p = m.Path
+	}
+	text := fmt.Sprintf("[#%d %s] %s", m.ID, m.Category, m.Content)
+	if p != "" {
+		text = fmt.Sprintf("[#%d %s] %s: %s", m.ID, m.Category, p, m.Content)
+	}
+	text = truncateLine(text, contextItemRuneLimit)
+	return &contextItem{
+		Kind:   "memo",
+		ID:     m.ID,
+		Text:   text,
+		path:   normalizeContextPath(p),
+		terms:  termSet(m.Entity + " " + m.Act + " " + m.Content),
+		tokens: estimateTokens(text),
+	}
+}
+
+// scoreContextItem 计算相关度得分并记录命中原因
+func scoreContextItem(item *contextItem, s relevanceSignals) {
+	lower := strings.ToLower(item.Text)
+
+	if item.path != "" {
+		for f := range s.anchorFiles {
+			if pathsOverlap(item.path, f) {
+				item.add(weightAnchorFile, i18n.T("锚点文件 %s", path.Base(f)))
+				break
+			}
+		}
+		for f := range s.callerFiles {
+			if pathsOverlap(item.path, f) {
+				item.add(weightCallerFile, i18n.T("调用方文件 %s", path.Base(f)))
+				break
+			}
+		}
+	} else {
+		// 铁律没有 path 字段，按文本中提及的文件名匹配
+		for f := range s.anchorFiles {
+			if base := strings.ToLower(path.Base(f)); strings.Contains(lower, base) {
+				item.add(weightAnchorFile/2, i18n.T("提及 %s", path.Base(f)))
+				break
+			}
+		}
+	}
+
+	for _, name := range s.anchorSymbols {
+		if mentionsIdentifier(lower, name) {
+			item.add(weightAnchorSymbol, i18n.T("符号 %s", name))
+			break
+		}
+	}
+	for _, name := range s.callerSymbols {
+		if mentionsIdentifier(lower, name) {
+			item.add(weightCallerSymbol, i18n.T("调用方 %s", name))
+			break
+		}
+	}
+
+	if len(s.taskTerms) > 0 && len(item.terms) > 0 {
+		overlap := 0
+		for t := range item.terms {
+			if s.taskTerms[t] {
+				overlap++
+			}
+		}
+		if overlap > 0 {
+			item.add(weightKeywords*float64(overlap)/float64(len(s.taskTerms)), i18n.T("关键词 %d", overlap))
+		}
+	}
+}
+
+func (item *contextItem) add(score float64, reason string) {
+	item.Score += score
+	item.Reasons = append(item.Reasons, reason)
+}
+
+// selectContextItems 按得分排序、去重，并在 token 预算内贪心选择
+// 相关铁律不足 minFacts 时，用最新铁律补足（候选已按 ID 倒序排列）
+func selectContextItems(candidates []*contextItem, budget, minFacts int) ([]*contextItem, int) {
+	ranked := make([]*contextItem, 0, len(candidates))
+	var fallbackFacts []*contextItem
+	for _, item := range candidates {
+		if item.Score > 0 {
+			ranked = append(ranked, item)
+		} else if item.Kind == "fact" {
+			fallbackFacts = append(fallbackFacts, item)
+		}
+	}
+	// 得分相同时铁律优先，其次较新的
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].Score != ranked[j].Score {
+			return ranked[i].Score > ranked[j].Score
+		}
+		if ranked[i].Kind != ranked[j].Kind {
+			return ranked[i].Kind == "fact"
+		}
+		return ranked[i].ID > ranked[j].ID
+	})
+
+	var selected []*contextItem
+	used, dups, factCount := 0, 0, 0
+	take := func(item *contextItem) bool {
+		for _, s := range selected {
+			if isDuplicateContext(item, s) {
+				dups++
+				return false
+			}
+		}
+		if used+item.tokens > budget {
+			return false
+		}
+		selected = append(selected, item)
+		used += item.tokens
+		if item.Kind == "fact" {
+			factCount++
+		}
+		return true
+	}
+
+	for _, item := range ranked {
+		take(item)
+	}
+	for _, item := range fallbackFacts {
+		if factCount >= minFacts {
+			break
+		}
+		if take(item) {
+			item.Reasons = append(item.Reasons, i18n.T("最新"))
+		}
+	}
+	return selected, dups
+}
+
+// isDuplicateContext 规范化文本相同或词项高度重合
+func isDuplicateContext(a, b *contextItem) bool {
+	if normalizeContextText(a.Text) == normalizeContextText(b.Text) {
+		return true
+	}
+	if len(a.terms) == 0 || len(b.terms) == 0 {
+		return false
+	}
+	inter := 0
+	for t := range a.terms {
+		if b.terms[t] {
+			inter++
+		}
+	}
+	union := len(a.terms) + len(b.terms) - inter
+	return float64(inter)/float64(union) >= contextDupThreshold
+}
+
+// normalizeContextText 去掉 [类型]/[#ID] 前缀、标点与空白，仅比较正文
+func normalizeContextText(s string) string {
+	if strings.HasPrefix(s, "[") {
+		if i := strings.Index(s, "]"); i >= 0 {
+			s = s[i+1:]
+		}
+	}
+	var sb strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+func normalizeContextPath(p string) string {
+	return strings.TrimPrefix(strings.ToLower(strings.ReplaceAll(strings.TrimSpace(p), "\\", "/")), "./")
+}
+
+// pathsOverlap 备忘路径可能是相对路径片段，任一方以另一方结尾即视为同一文件
+func pathsOverlap(a, b string) bool {
+	return a == b || strings.HasSuffix(a, "/"+b) || strings.HasSuffix(b, "/"+a)
+}
+
+// mentionsIdentifier 文本中以完整标识符形式出现 name（忽略大小写，name 已小写）
+func mentionsIdentifier(lower, name string) bool {
+	if len(name) < 3 {
+		return false
+	}
+	for start := 0; ; {
+		i := strings.Index(lower[start:], name)
+		if i < 0 {
+			return false
+		}
+		i += start
+		end := i + len(name)
+		if (i == 0 || !isIdentRune(rune(lower[i-1]))) && (end == len(lower) || !isIdentRune(rune(lower[end]))) {
+			return true
+		}
+		start = i + 1
+	}
+}
+
+func isIdentRune(r rune) bool {
+	return r == '_' || (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9')
+}
+
+func termSet(text string) map[string]bool {
+	set := make(map[string]bool)
+	for _, t := range services.ConceptTerms(text) {
+		set[t] = true
+	}
+	return set
+}
+
+// estimateTokens 粗略估算 token 数：ASCII 约 4 字符/token，CJK 等约 1 字/token
+func estimateTokens(s string) int {
+	ascii, other := 0, 0
+	for _, r := range s {
+		if r < 128 {
+			ascii++
+		} else {
+			other++
+		}
+	}
+	return (ascii+3)/4 + other
+}
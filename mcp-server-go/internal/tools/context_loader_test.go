@@ -0,0 +1,106 @@
+package tools
+
+import (
+	"context"
+	"mcp-server-go/internal/core"
+	"mcp-server-go/internal/services"
+	"strings"
+	"testing"
+)
+
+func TestLoadRelevantContextRanking(t *testing.T) {
+	root := t.TempDir()
+	mem, err := core.NewMemoryLayer(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	for _, f := range [][2]string{
+		{"铁律", "ParseConfig 必须在 LoadPlugins 之前调用"},
+		{"规范", "日志统一使用 zap"},
+		{"避坑", "日志统一使用 zap "}, // 与上一条重复
+	} {
+		if _, err := mem.SaveFact(ctx, f[0], f[1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := mem.AddMemos(ctx, []core.Memo{
+		{Category: "修改", Entity: "config", Act: "fix", Path: "internal/config/parse.go", Content: "修复空文件 panic"},
+		{Category: "修改", Entity: "ui", Act: "style", Path: "web/app.css", Content: "调整按钮颜色"},
+		{Category: "修改", Entity: "server", Act: "refactor", Path: "cmd/server.go", Content: "启动流程改为先读配置"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	sm := &SessionManager{Memory: mem, ProjectRoot: root}
+	anchors := []CodeAnchor{{Symbol: "config.ParseConfig", File: "internal/config/parse.go", Line: 10, Type: "function"}}
+	callers := []services.Node{{Name: "startServer", FilePath: "cmd/server.go"}}
+
+	signals := newRelevanceSignals("修复 ParseConfig 读取配置时的 panic", anchors, callers)
+	facts, _ := mem.QueryFacts(ctx, "", 10)
+	memos, _ := mem.QueryMemos(ctx, "", "", 10)
+	var items []*contextItem
+	for _, f := range facts {
+		items = append(items, newFactItem(f))
+	}
+	for _, m := range memos {
+		items = append(items, newMemoItem(m))
+	}
+	for _, item := range items {
+		scoreContextItem(item, signals)
+	}
+	selected, dups := selectContextItems(items, contextTokenBudget, contextMinFacts)
+
+	var texts []string
+	for _, item := range selected {
+		texts = append(texts, item.Text)
+	}
+	joined := strings.Join(texts, "\n")
+	if !strings.HasPrefix(texts[0], "[#1 修改] internal/config/parse.go") {
+		t.Errorf("anchor-file memo should rank first:\n%s", joined)
+	}
+	if !strings.Contains(joined, "cmd/server.go") || !strings.Contains(joined, "ParseConfig 必须") {
+		t.Errorf("caller memo and symbol fact expected:\n%s", joined)
+	}
+	if strings.Contains(joined, "app.css") {
+		t.Errorf("unrelated memo should be dropped:\n%s", joined)
+	}
+	if dups != 1 || strings.Count(joined, "zap") != 1 {
+		t.Errorf("duplicate fact should be filled once, dups=%d:\n%s", dups, joined)
+	}
+
+	// 无调用图 (ai=nil) 时仍按锚点与关键词工作
+	factTexts, memoTexts, stats := loadRelevantContext(ctx, sm, nil, "修复 ParseConfig", anchors)
+	if len(factTexts) == 0 || len(memoTexts) == 0 || stats.Tokens > stats.Budget || stats.Candidates != 6 {
+		t.Fatalf("facts=%v memos=%v stats=%+v", factTexts, memoTexts, stats)
+	}
+}
+
+func TestSelectContextItemsBudget(t *testing.T) {
+	var items []*contextItem
+	for i, text := range []string{"alpha beta gamma", "delta epsilon zeta", "eta theta iota"} {
+		items = append(items, &contextItem{Kind: "memo", ID: int64(i + 1), Text: text, Score: float64(3 - i), terms: termSet(text), tokens: 10})
+	}
+	selected, _ := selectContextItems(items, 25, 0)
+	if len(selected) != 2 || selected[0].ID != 1 || selected[1].ID != 2 {
+		t.Fatalf("selected = %+v", selected)
+	}
+}
+
+func TestMentionsIdentifier(t *testing.T) {
+	cases := []struct {
+		text, name string
+		want       bool
+	}{
+		{"call parseconfig() first", "parseconfig", true},
+		{"use myparseconfig instead", "parseconfig", false},
+		{"parseconfig_v2 is new", "parseconfig", false},
+		{"修改 parseconfig 的返回值", "parseconfig", true},
+		{"id only", "id", false},
+	}
+	for _, c := range cases {
+		if got := mentionsIdentifier(c.text, c.name); got != c.want {
+			t.Errorf("mentionsIdentifier(%q, %q) = %v, want %v", c.text, c.name, got, c.want)
+		}
+	}
+}
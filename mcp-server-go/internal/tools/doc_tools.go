@@ -3,9 +3,8 @@ package tools
 import (
 	"context"
 	"fmt"
+	"mcp-server-go/internal/i18n"
 	"mcp-server-go/internal/services"
-	"os"
-	"path/filepath"
 	"strings"
 
 	"github.com/mark3labs/mcp-go/mcp"
@@ -14,31 +13,66 @@ import (
 
 // WikiWriterArgs Wiki 写作参数（简化版）
 type WikiWriterArgs struct {
-	OutputFile string `json:"output_file,omitempty" jsonschema:"default=wiki_outline.md,description=输出文件名"`
-	Style      string `json:"style,omitempty" jsonschema:"description=书写风格（technical/tutorial/reference/blog 或自定义要求）"`
+	Mode       string `json:"mode,omitempty" jsonschema:"default=outline,enum=outline,enum=generate,enum=styles,description=outline 返回大纲指引；generate 按目录写入 Wiki 页面；styles 列出并校验书写风格"`
+	OutputFile string `json:"output_file,omitempty" jsonschema:"default=wiki_outline.md,description=输出文件名 (outline)"`
+	Style      string `json:"style,omitempty" jsonschema:"description=书写风格（内置 technical/tutorial/reference/blog、自定义风格名或自定义要求）"`
+	OutputDir  string `json:"output_dir,omitempty" jsonschema:"default=docs/wiki,description=Wiki 输出目录，相对项目根 (generate)"`
+	Scope      string `json:"scope,omitempty" jsonschema:"description=只生成该目录及其子目录 (generate)"`
 }
 
 // RegisterDocTools 注册文档工具
 func RegisterDocTools(s *server.MCPServer, sm *SessionManager, ai *services.ASTIndexer) {
-	s.AddTool(mcp.NewTool("wiki_writer",
-		mcp.WithDescription(`wiki_writer - Wiki 大纲生成工具
+	addTool(s, mcp.NewTool("wiki_writer",
+		mcp.WithDescription(i18n.Desc("wiki_writer", `wiki_writer - Wiki 大纲生成工具
 
 用途：
-  为项目生成 Wiki 文档大纲和章节规划，支持自定义书写风格。
+  为项目生成 Wiki 文档大纲和章节规划，支持自定义书写风格；
+  或直接根据 symbols.db 按目录写出 Wiki 骨架页面。
 
 参数：
-  output_file (可选)
+  mode (默认: outline)
+    - outline: 返回项目地图与大纲写作指引，不写文件。
+    - generate: 每个包/目录生成一页 (默认写入 docs/wiki/)，并生成 README.md 索引页。
+    - styles: 列出全部书写风格 (内置/全局/项目) 并校验继承关系。
+
+  output_file (可选, outline)
     输出文件名，默认 wiki_outline.md
 
+  output_dir (可选, generate)
+    输出目录，默认 docs/wiki
+
+  scope (可选, generate)
+    只生成该目录及其子目录，如 "internal/core"
+
   style (可选)
     书写风格：
     - technical: 技术文档风格（简洁专业）
     - tutorial: 教程指南风格（循序渐进）
     - reference: 参考资料风格（详细完整）
     - blog: 博客风格（轻松活泼）
+    - 自定义风格名 (见下方"自定义风格")
     - 或直接输入自定义要求
 
-工作流程：
+自定义风格：
+  放在 .mcp-config/wiki_styles/*.md (项目) 或 ~/.mpm/wiki_styles/*.md (全局)，
+  项目 > 全局 > 内置，同名覆盖。文件可带 YAML frontmatter：
+    ---
+    name: team            # 缺省为文件名
+    description: 团队统一文档风格
+    extends: technical    # 可选，继承父风格
+    ---
+  继承按 "## " 章节合并：同名章节覆盖父风格，新章节追加在末尾。
+  extends 写自身名称时继承下一层的同名风格 (如项目 technical.md 只改内置 technical 的个别章节)。
+
+generate 页面内容：
+  - 概览：文件列表与符号统计
+  - 导出符号：签名与文档注释 (按语言约定判断是否导出)
+  - 依赖关系：基于调用关系的跨包依赖与被依赖
+  - 设计决策：路径落在该目录下的 memo 记录
+  生成内容位于 <!-- mpm:begin xxx --> / <!-- mpm:end xxx --> 区块内，重新运行只替换这些区块，
+  区块外的手写内容保持不变；对应目录已消失的页面只提示不删除。
+
+工作流程 (outline)：
   1. 获取项目地图作为参考资料
   2. LLM 自主探索代码生成大纲
   3. 询问用户选择书写风格
@@ -48,9 +82,13 @@ func RegisterDocTools(s *server.MCPServer, sm *SessionManager, ai *services.ASTI
   wiki_writer()
   wiki_writer(output_file="MPM_Wiki.md", style="technical")
   wiki_writer(style="面向新手，多用 emoji，代码要详细注释")
+  wiki_writer(mode="generate")
+  wiki_writer(mode="generate", scope="internal/core")
+  wiki_writer(mode="styles")
+  wiki_writer(style="team")
 
 触发词：
-  "mpm wiki", "mpm 文档"`),
+  "mpm wiki", "mpm 文档"`)),
 		mcp.WithInputSchema[WikiWriterArgs](),
 	), wrapWikiWriter(sm, ai))
 }
@@ -59,7 +97,20 @@ func wrapWikiWriter(sm *SessionManager, ai *services.ASTIndexer) server.ToolHand
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		var args WikiWriterArgs
 		if err := request.BindArguments(&args); err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("参数错误: %v", err)), nil
+			return mcp.NewToolResultError(i18n.T("参数错误: %v", err)), nil
+		}
+
+		if args.Mode == "generate" {
+			report, err := generateWiki(ctx, sm, ai, args.OutputDir, args.Scope)
+			if err != nil {
+				return mcp.NewToolResultError(i18n.T("生成 Wiki 失败: %v", err)), nil
+			}
+			return mcp.NewToolResultStructured(report, renderWikiReport(report)), nil
+		}
+		if args.Mode == "styles" {
+			styles, warnings := loadWikiStyles(sm.ProjectRoot)
+			problems := validateWikiStyles(styles, warnings)
+			return mcp.NewToolResultStructured(&wikiStylesData{Styles: styles, Problems: problems}, renderWikiStyles(styles, problems)), nil
 		}
 
 		// 设置默认输出文件
@@ -71,25 +122,24 @@ func wrapWikiWriter(sm *SessionManager, ai *services.ASTIndexer) server.ToolHand
 		// 1. 调用 project_map 生成地图作为参考资料
 		mapResult, err := ai.MapProjectWithScope(sm.ProjectRoot, "symbols", "")
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("生成项目地图失败: %v", err)), nil
+			return mcp.NewToolResultError(i18n.T("生成项目地图失败: %v", err)), nil
 		}
 
-		// 2. 读取生成的 map 文件内容（完整内容）
-		mapFile := filepath.Join(sm.ProjectRoot, ".mcp-data", "project_map_symbols.md")
-		mapContent, _ := os.ReadFile(mapFile)
-		mapContentStr := string(mapContent)
+		// 2. 渲染地图内容
+		mapContentStr := NewMapRenderer(mapResult, sm.ProjectRoot).RenderStandard()
 
 		// 3. 返回生成指引
+		data := &wikiOutlineData{OutputFile: outputFile, Style: args.Style}
 		var sb strings.Builder
 		sb.WriteString("══════════════════════════════════════════════════════════════\n")
-		sb.WriteString("                    【Wiki 大纲生成】\n")
+		sb.WriteString(i18n.T("                    【Wiki 大纲生成】\n"))
 		sb.WriteString("══════════════════════════════════════════════════════════════\n\n")
 
-		sb.WriteString("## 📋 参考资料（项目地图）\n\n")
+		sb.WriteString(i18n.T("## 📋 参考资料（项目地图）\n\n"))
 		if len(mapContentStr) > 10000 {
-			// 内容太长，显示摘要和文件路径
-			sb.WriteString(fmt.Sprintf("> 📄 完整地图：`.mcp-data/project_map_symbols.md` (%d 字符)\n\n", len(mapContentStr)))
-			sb.WriteString("**摘要**：\n\n")
+			// 内容太长，显示摘要并引导分页查看
+			sb.WriteString(i18n.T("> 📄 完整地图较长 (%d 字符)，请用 `project_map(level=\"symbols\")` 按 cursor 分页查看\n\n", len(mapContentStr)))
+			sb.WriteString(i18n.T("**摘要**：\n\n"))
 			sb.WriteString(formatMapResult(mapResult))
 			sb.WriteString("\n\n")
 		} else {
@@ -99,152 +149,89 @@ func wrapWikiWriter(sm *SessionManager, ai *services.ASTIndexer) server.ToolHand
 		}
 		sb.WriteString("---\n\n")
 
-		sb.WriteString("## ✍️ 你的任务\n\n")
-		sb.WriteString("基于上述项目地图，生成一套完整的 Wiki 大纲和章节规划文档。\n\n")
-		sb.WriteString("**要求**：\n")
-		sb.WriteString("- 用最流行的方式组织章节\n")
-		sb.WriteString("- 可以自主使用 code_search 查找符号\n")
-		sb.WriteString("- 可以使用 Read 阅读具体实现\n")
-		sb.WriteString("- 输出完整的大纲文档\n\n")
-
-		sb.WriteString("## 🎨 书写风格选择\n\n")
-		sb.WriteString("**预置模板**（输入数字或名称）：\n")
-		sb.WriteString("- `1` 或 `technical` → 技术文档风格（简洁专业）\n")
-		sb.WriteString("- `2` 或 `tutorial` → 教程指南风格（循序渐进）\n")
-		sb.WriteString("- `3` 或 `reference` → 参考资料风格（详细完整）\n")
-		sb.WriteString("- `4` 或 `blog` → 博客风格（轻松活泼）\n\n")
-		sb.WriteString("**自定义要求**：\n")
-		sb.WriteString("- 直接输入你的风格要求\n")
-		sb.WriteString("- 例如：\"面向新手，多用 emoji，代码要详细注释\"\n\n")
+		sb.WriteString(i18n.T("## ✍️ 你的任务\n\n"))
+		sb.WriteString(i18n.T("基于上述项目地图，生成一套完整的 Wiki 大纲和章节规划文档。\n\n"))
+		sb.WriteString(i18n.T("**要求**：\n"))
+		sb.WriteString(i18n.T("- 用最流行的方式组织章节\n"))
+		sb.WriteString(i18n.T("- 可以自主使用 code_search 查找符号\n"))
+		sb.WriteString(i18n.T("- 可以使用 Read 阅读具体实现\n"))
+		sb.WriteString(i18n.T("- 输出完整的大纲文档\n\n"))
+
+		sb.WriteString(i18n.T("## 🎨 书写风格选择\n\n"))
+		styles, _ := loadWikiStyles(sm.ProjectRoot)
+		sb.WriteString(i18n.T("**预置模板**（输入数字或名称）：\n"))
+		for i, name := range builtinWikiStyleOrder {
+			sb.WriteString(i18n.T("- `%d` 或 `%s` → %s\n", i+1, name, builtinWikiStyleDescription(name)))
+		}
+		sb.WriteString("\n")
+		var custom []*WikiStyle
+		for _, style := range styles {
+			if style.Source != templateSourceBuiltin {
+				custom = append(custom, style)
+			}
+		}
+		if len(custom) > 0 {
+			sb.WriteString(i18n.T("**团队风格**（输入名称）：\n"))
+			for _, style := range custom {
+				sb.WriteString(fmt.Sprintf("- `%s` [%s]", style.Name, style.Source))
+				if style.Description != "" {
+					sb.WriteString(" → " + style.Description)
+				}
+				sb.WriteString("\n")
+			}
+			sb.WriteString("\n")
+		}
+		sb.WriteString(i18n.T("**自定义要求**：\n"))
+		sb.WriteString(i18n.T("- 直接输入你的风格要求\n"))
+		sb.WriteString(i18n.T("- 例如：\"面向新手，多用 emoji，代码要详细注释\"\n\n"))
 
 		if args.Style != "" {
-			sb.WriteString(fmt.Sprintf("---\n\n**当前选择**：%s\n\n", args.Style))
-			sb.WriteString("**生成的书写指南**：\n\n")
-			sb.WriteString(generateStyleGuide(args.Style))
+			sb.WriteString(i18n.T("---\n\n**当前选择**：%s\n\n", args.Style))
+			sb.WriteString(i18n.T("**生成的书写指南**：\n\n"))
+			guide, err := generateStyleGuide(sm.ProjectRoot, args.Style)
+			if err != nil {
+				return mcp.NewToolResultError(i18n.T("书写风格 %s 无效: %v", args.Style, err)), nil
+			}
+			sb.WriteString(guide)
+			data.StyleGuide = guide
 		}
 
-		sb.WriteString(fmt.Sprintf("\n---\n\n💾 **保存到**：`%s`\n", outputFile))
+		sb.WriteString(i18n.T("\n---\n\n💾 **保存到**：`%s`\n", outputFile))
 
-		return mcp.NewToolResultText(sb.String()), nil
+		data.Styles = styles
+		return mcp.NewToolResultStructured(data, sb.String()), nil
 	}
 }
 
+// wikiStylesData wiki_writer(mode="styles") 的结构化结果
+type wikiStylesData struct {
+	Styles   []*WikiStyle `json:"styles"`
+	Problems []string     `json:"problems,omitempty"`
+}
+
+// wikiOutlineData wiki_writer 大纲模式的结构化结果
+type wikiOutlineData struct {
+	OutputFile string       `json:"output_file"`
+	Style      string       `json:"style,omitempty"`
+	StyleGuide string       `json:"style_guide,omitempty"`
+	Styles     []*WikiStyle `json:"styles"`
+}
+
 // formatMapResult 格式化地图结果
 func formatMapResult(mapResult *services.MapResult) string {
 	if mapResult == nil {
-		return "项目地图获取失败"
+		return i18n.T("项目地图获取失败")
 	}
 
 	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("**📊 统计**: %d 文件 | %d 符号\n\n",
+	sb.WriteString(i18n.T("**📊 统计**: %d 文件 | %d 符号\n\n",
 		mapResult.Statistics.TotalFiles, mapResult.Statistics.TotalSymbols))
-	sb.WriteString("**📁 目录结构**:\n\n")
+	sb.WriteString(i18n.T("**📁 目录结构**:\n\n"))
 
 	// 按目录组织显示
 	for dir, nodes := range mapResult.Structure {
-		sb.WriteString(fmt.Sprintf("- `%s/` (%d 符号)\n", dir, len(nodes)))
+		sb.WriteString(i18n.T("- `%s/` (%d 符号)\n", dir, len(nodes)))
 	}
 
 	return sb.String()
 }
-
-// generateStyleGuide 生成书写指南
-func generateStyleGuide(style string) string {
-	// 预置模板
-	templates := map[string]string{
-		"technical": `
-# Wiki 书写指南 - 技术文档风格
-
-## 语言风格
-- 简洁准确，避免冗余
-- 使用专业术语
-- 保持客观中立
-
-## 写作手法
-- 章节结构清晰，层次分明
-- 代码示例带注释
-- 重点内容加粗强调
-
-## 格式要求
-- Markdown 格式
-- 代码块指定语言
-- 标题层级规范
-`,
-		"tutorial": `
-# Wiki 书写指南 - 教程风格
-
-## 语言风格
-- 循序渐进，从简单到复杂
-- 语言通俗，适合新手
-- 多用示例和类比
-
-## 写作手法
-- 每个概念配示例
-- 使用图示辅助说明
-- 分步骤详细讲解
-
-## 格式要求
-- 代码块完整可运行
-- 多用 Mermaid 流程图
-- 步骤编号清晰
-`,
-		"reference": `
-# Wiki 书写指南 - 参考资料风格
-
-## 语言风格
-- 详细完整，全面覆盖
-- 准确描述每个细节
-- 保持结构一致
-
-## 写作手法
-- 按功能模块组织
-- 每个函数/接口独立说明
-- 提供参数详解
-
-## 格式要求
-- 表格展示参数列表
-- 代码签名完整
-- 交叉引用相关内容
-`,
-		"blog": `
-# Wiki 书写指南 - 博客风格
-
-## 语言风格
-- 轻松活泼，富有感染力
-- 使用 emoji 增强可读性
-- 讲故事的方式
-
-## 写作手法
-- 多用实例和生活类比
-- 使用引用突出金句
-- 图文并茂
-
-## 格式要求
-- 适当使用 emoji
-- 引用块强调重点
-- 图片配说明
-`,
-	}
-
-	// 检查是否是预置模板
-	if tpl, ok := templates[strings.ToLower(style)]; ok {
-		return tpl
-	}
-
-	// 自定义要求，与默认模板融合
-	return fmt.Sprintf(`
-# Wiki 书写指南（个性化定制）
-
-## 用户要求
-%s
-
----
-
-## 基础规范（与默认模板融合）
-- 简洁准确，避免冗余
-- 代码示例带注释
-- 章节结构清晰
-
-**注**：以上要求已与默认写作规范融合，确保文档质量与个性化需求的平衡。
-`, style)
-}
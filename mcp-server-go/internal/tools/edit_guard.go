@@ -0,0 +1,486 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mcp-server-go/internal/core"
+	"mcp-server-go/internal/i18n"
+	"mcp-server-go/internal/services"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// maxEditViolationsShown 单次输出的违规条数上限
+const maxEditViolationsShown = 30
+
+// CheckEditArgs check_edit 参数
+type CheckEditArgs struct {
+	Paths      []string `json:"paths" jsonschema:"description=拟修改的文件路径列表 (相对项目根或绝对路径)"`
+	Diff       string   `json:"diff" jsonschema:"description=拟应用的 unified diff (git diff 输出)，可代替 paths"`
+	TaskID     string   `json:"task_id" jsonschema:"description=manager_analyze 的 task_id，或关联了简报的任务链 ID"`
+	RecordMemo bool     `json:"record_memo" jsonschema:"description=是否把检查结果记为 memo，默认 false"`
+}
+
+// ConstraintArgs manager_constraints 参数
+type ConstraintArgs struct {
+	Mode           string   `json:"mode" jsonschema:"required,enum=add,enum=list,enum=enable,enum=disable,description=操作模式"`
+	Name           string   `json:"name" jsonschema:"description=规则名 (add/enable/disable 必填，add 时同名覆盖)"`
+	Description    string   `json:"description" jsonschema:"description=规则说明，违规时展示"`
+	Category       string   `json:"category" jsonschema:"description=分类，如 架构/迁移/安全"`
+	FilePatterns   []string `json:"file_patterns" jsonschema:"description=适用文件 (gitignore 语法)，为空表示全部"`
+	ForbidPaths    []string `json:"forbid_paths" jsonschema:"description=禁止修改的路径 (gitignore 语法)"`
+	ForbidPatterns []string `json:"forbid_patterns" jsonschema:"description=新增代码中禁止出现的正则"`
+	Priority       int      `json:"priority" jsonschema:"description=优先级，越大越靠前"`
+}
+
+func registerEditGuardTools(s *server.MCPServer, sm *SessionManager) {
+	addTool(s, mcp.NewTool("check_edit",
+		mcp.WithDescription(i18n.Desc("check_edit", `check_edit - 修改前的禁令合规检查
+
+用途：
+  在动手修改前，检查拟修改的文件/diff 是否违反 manager_analyze 简报中的禁令
+  (READ_ONLY、NO_CODE_EDIT、MD_ONLY 等) 以及当前启用的约束规则 (manager_constraints)。
+
+参数：
+  paths (可选)
+    拟修改的文件路径列表。
+
+  diff (可选)
+    拟应用的 unified diff；提供时还会检查新增行是否命中约束规则的禁用正则。
+
+  task_id (可选)
+    manager_analyze 的 task_id，或关联了简报的任务链 ID；不填则只检查约束规则。
+
+  record_memo (默认 false)
+    将检查结果记为一条 memo (分类"检查")。
+
+说明：
+  禁令与路径检查的对应关系由 intent_rules.yaml 的 guardrail_checks 定义；
+  没有对应检查的禁令会列为"需人工确认"。
+
+返回：
+  ✅ 通过，或 ❌ 违规清单 (来源、规则、文件、行号)
+
+触发词：
+  "mpm 合规", "mpm check", "mpm 检查修改"`)),
+		mcp.WithInputSchema[CheckEditArgs](),
+	), wrapCheckEdit(sm))
+
+	addTool(s, mcp.NewTool("manager_constraints",
+		mcp.WithDescription(i18n.Desc("manager_constraints", `manager_constraints - 管理项目约束规则
+
+用途：
+  维护 check_edit 使用的约束规则，例如"迁移期间禁止调用旧 API"、"禁止修改 vendor/"。
+  规则保存在项目数据库中，跨会话生效。
+
+参数：
+  mode (必填)
+    - add: 新增规则 (同名覆盖)，需 name，以及 forbid_paths / forbid_patterns 至少一项
+    - list: 列出全部规则
+    - enable / disable: 启用或停用规则，需 name
+
+  file_patterns / forbid_paths (可选)
+    gitignore 语法，如 "*.go"、"vendor/"、"internal/legacy/**"。
+
+  forbid_patterns (可选)
+    正则，diff 新增行命中即违规，如 "legacy\\.Client"。
+
+返回：
+  操作结果或规则列表
+
+触发词：
+  "mpm 约束", "mpm constraints"`)),
+		mcp.WithInputSchema[ConstraintArgs](),
+	), wrapConstraints(sm))
+}
+
+func wrapCheckEdit(sm *SessionManager) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var args CheckEditArgs
+		if err := request.BindArguments(&args); err != nil {
+			return mcp.NewToolResultError(i18n.T("参数格式错误: %v", err)), nil
+		}
+		if sm.ProjectRoot == "" || sm.Memory == nil {
+			return mcp.NewToolResultError(i18n.T("⚠️ 项目未初始化，请先调用 initialize_project。")), nil
+		}
+		report, res, err := checkEdit(ctx, sm, args)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return mcp.NewToolResultStructured(res, report), nil
+	}
+}
+
+// editCheckResult 一次合规检查的结果 (同时作为 check_edit 的结构化结果)
+type editCheckResult struct {
+	BriefingID   string                   `json:"briefing_id,omitempty"`
+	Intent       string                   `json:"intent,omitempty"`
+	Files        []services.EditedFile    `json:"files"`
+	Violations   []services.EditViolation `json:"violations"`
+	Unchecked    []string                 `json:"unchecked,omitempty"`
+	Notes        []string                 `json:"notes,omitempty"`
+	MemoRecorded bool                     `json:"memo_recorded,omitempty"`
+}
+
+func checkEdit(ctx context.Context, sm *SessionManager, args CheckEditArgs) (string, *editCheckResult, error) {
+	files, err := collectEditedFiles(sm.ProjectRoot, args.Paths, args.Diff)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(files) == 0 {
+		return "", nil, errors.New(i18n.T("请提供 paths 或 diff (diff 中未识别到文件)"))
+	}
+
+	res := &editCheckResult{Files: files, Violations: []services.EditViolation{}}
+	if args.TaskID != "" {
+		b, state, err := resolveCheckBriefing(ctx, sm, args.TaskID)
+		if err != nil {
+			return "", nil, err
+		}
+		res.BriefingID, res.Intent = b.ID, b.Intent
+		if b.Expired(time.Now()) {
+			res.Notes = append(res.Notes, i18n.T("简报 %s 已过期，仍按其禁令检查", b.ID))
+		}
+		v, unchecked := services.CheckGuardrails(sm.intentRules(), state.Guardrails.Critical, files)
+		res.Violations = append(res.Violations, v...)
+		res.Unchecked = unchecked
+	}
+
+	rules, err := sm.Memory.ListConstraintRules(ctx, true)
+	if err != nil {
+		return "", nil, errors.New(i18n.T("读取约束规则失败: %v", err))
+	}
+	constraints, notes := toEditConstraints(rules)
+	res.Notes = append(res.Notes, notes...)
+	v, invalid := services.CheckConstraints(constraints, files)
+	res.Violations = append(res.Violations, v...)
+	res.Notes = append(res.Notes, invalid...)
+	if args.Diff == "" && hasForbidPatterns(constraints) {
+		res.Notes = append(res.Notes, i18n.T("仅提供了 paths，未检查约束规则中的代码正则 (提供 diff 可检查新增行)"))
+	}
+
+	report := renderEditCheck(res)
+	if args.RecordMemo {
+		if err := recordEditCheck(ctx, sm, res); err != nil {
+			report += i18n.T("\n⚠️ 记录 memo 失败: %v\n", err)
+		} else {
+			res.MemoRecorded = true
+			report += i18n.T("\n📝 检查结果已记为 memo\n")
+		}
+	}
+	return report, res, nil
+}
+
+// collectEditedFiles 合并 paths 与 diff 中的文件，统一为相对项目根的路径
+func collectEditedFiles(root string, paths []string, diff string) ([]services.EditedFile, error) {
+	var files []services.EditedFile
+	seen := map[string]bool{}
+	if strings.TrimSpace(diff) != "" {
+		for _, f := range services.ParseUnifiedDiff(diff) {
+			rel, err := services.RelativeEditPath(root, f.Path)
+			if err != nil {
+				return nil, err
+			}
+			f.Path = rel
+			if !seen[rel] {
+				seen[rel] = true
+				files = append(files, f)
+			}
+		}
+	}
+	for _, p := range paths {
+		rel, err := services.RelativeEditPath(root, p)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[rel] {
+			seen[rel] = true
+			files = append(files, services.EditedFile{Path: rel})
+		}
+	}
+	return files, nil
+}
+
+// resolveCheckBriefing task_id 可以是简报 ID，也可以是关联了简报的任务链 ID
+func resolveCheckBriefing(ctx context.Context, sm *SessionManager, taskID string) (*core.AnalysisBriefing, *AnalysisState, error) {
+	b, err := sm.Memory.GetBriefing(ctx, taskID)
+	if err != nil {
+		return nil, nil, errors.New(i18n.T("读取分析简报失败: %v", err))
+	}
+	if b == nil {
+		if chain, ok := sm.TaskChainsV2[taskID]; ok && chain.AnalysisID != "" {
+			b, _ = sm.Memory.GetBriefing(ctx, chain.AnalysisID)
+		}
+	}
+	if b == nil {
+		list, _ := sm.Memory.ListBriefings(ctx, 200)
+		for i := range list {
+			if list[i].ChainID == taskID {
+				b = &list[i]
+				break
+			}
+		}
+	}
+	if b == nil {
+		return nil, nil, errors.New(i18n.T("未找到 %s 对应的分析简报 (可用 manager_list_briefings 查看)", taskID))
+	}
+	var state AnalysisState
+	if err := json.Unmarshal([]byte(b.State), &state); err != nil {
+		return nil, nil, errors.New(i18n.T("分析简报 %s 已损坏: %v", b.ID, err))
+	}
+	return b, &state, nil
+}
+
+// toEditConstraints 把数据库中的约束规则转换为可执行的检查
+func toEditConstraints(rules []core.ConstraintRule) ([]services.EditConstraint, []string) {
+	var out []services.EditConstraint
+	var notes []string
+	for _, r := range rules {
+		c := services.EditConstraint{Name: r.RuleName, Description: r.Description}
+		if r.FilePatterns != "" {
+			if err := json.Unmarshal([]byte(r.FilePatterns), &c.FilePatterns); err != nil {
+				notes = append(notes, i18n.T("约束规则 %s 的 file_patterns 无效，已跳过", r.RuleName))
+				continue
+			}
+		}
+		if r.RuleDefinition != "" {
+			if err := json.Unmarshal([]byte(r.RuleDefinition), &c.Definition); err != nil {
+				notes = append(notes, i18n.T("约束规则 %s 的 rule_definition 无效，已跳过", r.RuleName))
+				continue
+			}
+		}
+		out = append(out, c)
+	}
+	return out, notes
+}
+
+func hasForbidPatterns(constraints []services.EditConstraint) bool {
+	for _, c := range constraints {
+		if len(c.Definition.ForbidPatterns) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func renderEditCheck(res *editCheckResult) string {
+	var sb strings.Builder
+	sb.WriteString(i18n.T("### 🛡️ 修改合规检查\n\n"))
+	if res.BriefingID != "" {
+		sb.WriteString(i18n.T("**简报**: %s (意图 %s)\n", res.BriefingID, fallback(res.Intent, i18n.T("未识别"))))
+	} else {
+		sb.WriteString(i18n.T("**简报**: 未指定，仅检查约束规则\n"))
+	}
+	sb.WriteString(i18n.T("**文件**: %d 个\n\n", len(res.Files)))
+
+	if len(res.Violations) == 0 {
+		sb.WriteString(i18n.T("✅ 未发现违规\n"))
+	} else {
+		sb.WriteString(i18n.T("❌ 发现 %d 处违规\n\n", len(res.Violations)))
+		for i, v := range res.Violations {
+			if i >= maxEditViolationsShown {
+				sb.WriteString(i18n.T("- ... 另有 %d 处未显示\n", len(res.Violations)-i))
+				break
+			}
+			loc := v.Path
+			if v.Line > 0 {
+				loc = fmt.Sprintf("%s:%d", v.Path, v.Line)
+			}
+			source := i18n.T("禁令")
+			if v.Source == "constraint" {
+				source = i18n.T("约束")
+			}
+			line := fmt.Sprintf("- [%s] **%s** `%s`", source, v.Rule, loc)
+			if v.Detail != "" {
+				line += " — " + truncateLine(v.Detail, 120)
+			}
+			sb.WriteString(line + "\n")
+		}
+	}
+
+	if len(res.Unchecked) > 0 {
+		sb.WriteString(i18n.T("\n**需人工确认** (无法自动检查的禁令):\n"))
+		for _, g := range res.Unchecked {
+			sb.WriteString("- " + g + "\n")
+		}
+	}
+	if len(res.Notes) > 0 {
+		sb.WriteString(i18n.T("\n**说明**:\n"))
+		for _, n := range res.Notes {
+			sb.WriteString("- " + n + "\n")
+		}
+	}
+	if len(res.Violations) > 0 {
+		sb.WriteString(i18n.T("\n> 请调整修改范围，或先与用户确认是否放宽约束。\n"))
+	}
+	return sb.String()
+}
+
+func recordEditCheck(ctx context.Context, sm *SessionManager, res *editCheckResult) error {
+	paths := make([]string, 0, len(res.Files))
+	for _, f := range res.Files {
+		paths = append(paths, f.Path)
+	}
+	act, content := i18n.T("通过"), i18n.T("%d 个文件未发现违规", len(res.Files))
+	if len(res.Violations) > 0 {
+		act = i18n.T("违规")
+		var parts []string
+		for i, v := range res.Violations {
+			if i >= 5 {
+				parts = append(parts, i18n.T("等 %d 处", len(res.Violations)))
+				break
+			}
+			parts = append(parts, fmt.Sprintf("%s@%s", v.Rule, v.Path))
+		}
+		content = i18n.T("违规: %s", strings.Join(parts, ", "))
+	}
+	if res.BriefingID != "" {
+		content += i18n.T(" (简报 %s)", res.BriefingID)
+	}
+	_, err := sm.Memory.AddMemos(ctx, []core.Memo{{
+		Category: i18n.T("检查"),
+		Entity:   "check_edit",
+		Act:      act,
+		Path:     truncateLine(strings.Join(paths, ", "), 200),
+		Content:  content,
+	}})
+	return err
+}
+
+func wrapConstraints(sm *SessionManager) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var args ConstraintArgs
+		if err := request.BindArguments(&args); err != nil {
+			return mcp.NewToolResultError(i18n.T("参数格式错误: %v", err)), nil
+		}
+		if sm.Memory == nil {
+			return mcp.NewToolResultError(i18n.T("记忆层尚未初始化")), nil
+		}
+
+		switch args.Mode {
+		case "add":
+			return addConstraintRule(ctx, sm, args)
+		case "list":
+			rules, err := sm.Memory.ListConstraintRules(ctx, false)
+			if err != nil {
+				return mcp.NewToolResultError(i18n.T("查询约束规则失败: %v", err)), nil
+			}
+			return mcp.NewToolResultStructured(map[string]any{"rules": constraintViews(rules)}, renderConstraintRules(rules)), nil
+		case "enable", "disable":
+			if args.Name == "" {
+				return mcp.NewToolResultError(i18n.T("请提供 name")), nil
+			}
+			if err := sm.Memory.SetConstraintRuleActive(ctx, args.Name, args.Mode == "enable"); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			msg := i18n.T("✅ 约束规则 %s 已启用", args.Name)
+			if args.Mode == "disable" {
+				msg = i18n.T("✅ 约束规则 %s 已停用", args.Name)
+			}
+			return mcp.NewToolResultStructured(map[string]any{"name": args.Name, "active": args.Mode == "enable"}, msg), nil
+		default:
+			return mcp.NewToolResultError(i18n.T("未知模式: %s (可选 add/list/enable/disable)", args.Mode)), nil
+		}
+	}
+}
+
+func addConstraintRule(ctx context.Context, sm *SessionManager, args ConstraintArgs) (*mcp.CallToolResult, error) {
+	if strings.TrimSpace(args.Name) == "" {
+		return mcp.NewToolResultError(i18n.T("请提供 name")), nil
+	}
+	def := services.ConstraintDefinition{ForbidPaths: args.ForbidPaths, ForbidPatterns: args.ForbidPatterns}
+	if len(def.ForbidPaths)+len(def.ForbidPatterns) == 0 {
+		return mcp.NewToolResultError(i18n.T("forbid_paths 与 forbid_patterns 至少填写一项")), nil
+	}
+	// 先用检查器跑一遍，提前暴露无效正则
+	if _, invalid := services.CheckConstraints([]services.EditConstraint{{Name: args.Name, Definition: def}}, nil); len(invalid) > 0 {
+		return mcp.NewToolResultError(strings.Join(invalid, "\n")), nil
+	}
+
+	defJSON, _ := json.Marshal(def)
+	patternsJSON := ""
+	if len(args.FilePatterns) > 0 {
+		data, _ := json.Marshal(args.FilePatterns)
+		patternsJSON = string(data)
+	}
+	id, err := sm.Memory.SaveConstraintRule(ctx, core.ConstraintRule{
+		RuleName:       strings.TrimSpace(args.Name),
+		Category:       args.Category,
+		RuleDefinition: string(defJSON),
+		Description:    args.Description,
+		Priority:       args.Priority,
+		FilePatterns:   patternsJSON,
+		IsActive:       true,
+	})
+	if err != nil {
+		return mcp.NewToolResultError(i18n.T("保存约束规则失败: %v", err)), nil
+	}
+	return mcp.NewToolResultStructured(map[string]any{"id": id, "name": strings.TrimSpace(args.Name), "active": true},
+		i18n.T("✅ 约束规则 %s 已保存 (ID: %d)，check_edit 将自动应用", args.Name, id)), nil
+}
+
+// constraintView 约束规则的结构化视图 (output_format=json)
+type constraintView struct {
+	ID           int64                         `json:"id"`
+	Name         string                        `json:"name"`
+	Category     string                        `json:"category,omitempty"`
+	Description  string                        `json:"description,omitempty"`
+	Priority     int                           `json:"priority"`
+	FilePatterns []string                      `json:"file_patterns,omitempty"`
+	Definition   services.ConstraintDefinition `json:"definition"`
+	Active       bool                          `json:"active"`
+}
+
+func constraintViews(rules []core.ConstraintRule) []constraintView {
+	views := make([]constraintView, 0, len(rules))
+	for _, r := range rules {
+		v := constraintView{
+			ID:          r.ID,
+			Name:        r.RuleName,
+			Category:    r.Category,
+			Description: r.Description,
+			Priority:    r.Priority,
+			Active:      r.IsActive,
+		}
+		_ = json.Unmarshal([]byte(r.RuleDefinition), &v.Definition)
+		if r.FilePatterns != "" {
+			_ = json.Unmarshal([]byte(r.FilePatterns), &v.FilePatterns)
+		}
+		views = append(views, v)
+	}
+	return views
+}
+
+func renderConstraintRules(rules []core.ConstraintRule) string {
+	if len(rules) == 0 {
+		return i18n.T("暂无约束规则。使用 manager_constraints(mode=\"add\") 添加。")
+	}
+	var sb strings.Builder
+	sb.WriteString(i18n.T("### 📏 约束规则 (%d)\n\n", len(rules)))
+	for _, r := range rules {
+		status := "🟢"
+		if !r.IsActive {
+			status = "⚪"
+		}
+		sb.WriteString(fmt.Sprintf("- %s **%s**", status, r.RuleName))
+		if r.Category != "" {
+			sb.WriteString(fmt.Sprintf(" [%s]", r.Category))
+		}
+		if r.Description != "" {
+			sb.WriteString(" — " + r.Description)
+		}
+		sb.WriteString("\n")
+		if r.FilePatterns != "" {
+			sb.WriteString(i18n.T("  - 适用: %s\n", r.FilePatterns))
+		}
+		if r.RuleDefinition != "" {
+			sb.WriteString(i18n.T("  - 定义: %s\n", r.RuleDefinition))
+		}
+	}
+	return sb.String()
+}
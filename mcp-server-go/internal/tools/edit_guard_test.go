@@ -0,0 +1,72 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"mcp-server-go/internal/core"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckEditAgainstBriefingAndConstraints(t *testing.T) {
+	root := t.TempDir()
+	mem, err := core.NewMemoryLayer(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	sm := &SessionManager{Memory: mem, ProjectRoot: root, TaskChainsV2: make(map[string]*TaskChainV2)}
+
+	state, _ := json.Marshal(AnalysisState{Intent: "DESIGN", Guardrails: Guardrails{Critical: []string{
+		"MD_ONLY: 仅允许修改 Markdown",
+		"ASK_FIRST: 动手前先确认方案",
+	}}})
+	_ = mem.SaveBriefing(ctx, &core.AnalysisBriefing{ID: "analyze_design", Intent: "DESIGN", State: string(state), ExpiresAt: time.Now().Add(time.Hour)})
+
+	// 设计任务改代码：违规；未知禁令列为人工确认
+	report, _, err := checkEdit(ctx, sm, CheckEditArgs{Paths: []string{"internal/app.go", "docs/plan.md"}, TaskID: "analyze_design"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"❌ 发现 1 处违规", "MD_ONLY", "internal/app.go", "ASK_FIRST"} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report missing %q:\n%s", want, report)
+		}
+	}
+
+	// 通过任务链 ID 找到简报；只改文档则通过，并记录 memo
+	sm.TaskChainsV2["chain_design"] = &TaskChainV2{TaskID: "chain_design", AnalysisID: "analyze_design"}
+	report, _, err = checkEdit(ctx, sm, CheckEditArgs{Paths: []string{"docs/plan.md"}, TaskID: "chain_design", RecordMemo: true})
+	if err != nil || !strings.Contains(report, "✅ 未发现违规") {
+		t.Fatalf("report = %s, err = %v", report, err)
+	}
+	if memos, _ := mem.QueryMemos(ctx, "check_edit", "检查", 5); len(memos) != 1 || memos[0].Act != "通过" {
+		t.Errorf("memos = %+v", memos)
+	}
+
+	// 约束规则：新增行命中禁用正则
+	if res, _ := addConstraintRule(ctx, sm, ConstraintArgs{Name: "no-legacy", Description: "迁移期间禁止新增旧客户端调用", FilePatterns: []string{"*.go"}, ForbidPatterns: []string{`legacy\.`}}); res.IsError {
+		t.Fatalf("add rule: %s", getTextResult(t, res))
+	}
+	if res, _ := addConstraintRule(ctx, sm, ConstraintArgs{Name: "bad", ForbidPatterns: []string{"("}}); !res.IsError {
+		t.Error("invalid regex should be rejected")
+	}
+	diff := "--- a/svc.go\n+++ b/svc.go\n@@ -1,1 +1,2 @@\n package svc\n+var c = legacy.New()\n"
+	report, _, _ = checkEdit(ctx, sm, CheckEditArgs{Diff: diff})
+	if !strings.Contains(report, "no-legacy") || !strings.Contains(report, "svc.go:2") {
+		t.Errorf("constraint violation missing:\n%s", report)
+	}
+
+	_ = mem.SetConstraintRuleActive(ctx, "no-legacy", false)
+	if report, _, _ = checkEdit(ctx, sm, CheckEditArgs{Diff: diff}); !strings.Contains(report, "✅") {
+		t.Errorf("disabled rule should not apply:\n%s", report)
+	}
+
+	if _, _, err := checkEdit(ctx, sm, CheckEditArgs{Paths: []string{"a.go"}, TaskID: "missing"}); err == nil {
+		t.Error("unknown task_id should fail")
+	}
+	if _, _, err := checkEdit(ctx, sm, CheckEditArgs{}); err == nil {
+		t.Error("empty input should fail")
+	}
+}
@@ -3,7 +3,10 @@ package tools
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
+	"mcp-server-go/internal/i18n"
 	"os"
 	"path/filepath"
 	"strings"
@@ -16,11 +19,13 @@ import (
 type PromptEnhanceArgs struct {
 	TaskDescription string `json:"task_description" jsonschema:"description=用户的原始任务描述"`
 	Mode            string `json:"mode" jsonschema:"default=inject,enum=inject,enum=explain,description=操作模式"`
+	Protocol        string `json:"protocol" jsonschema:"description=协议模板名称 (默认按意图选择，否则 tactical)"`
+	Intent          string `json:"intent" jsonschema:"description=任务意图 (如 DEBUG/REFACTOR)，用于选择协议；为空时从任务描述推断"`
 }
 
 // PersonaArgs 人格管理参数
 type PersonaArgs struct {
-	Mode           string   `json:"mode" jsonschema:"default=list,enum=list,enum=activate,enum=create,enum=update,enum=delete,description=操作模式"`
+	Mode           string   `json:"mode" jsonschema:"default=list,enum=list,enum=activate,enum=deactivate,enum=create,enum=update,enum=delete,enum=export,enum=import,description=操作模式"`
 	Name           string   `json:"name" jsonschema:"description=人格名称 (activate/update/delete 必填)"`
 	NewName        string   `json:"new_name" jsonschema:"description=新名称 (update 可选)"`
 	DisplayName    string   `json:"display_name" jsonschema:"description=显示名称"`
@@ -31,12 +36,18 @@ type PersonaArgs struct {
 	StyleSignature []string `json:"style_signature" jsonschema:"description=标志性表达"`
 	StyleTaboo     []string `json:"style_taboo" jsonschema:"description=禁用表达"`
 	Triggers       []string `json:"triggers" jsonschema:"description=触发词"`
+	Extends        string   `json:"extends" jsonschema:"description=继承的父人格 (create/update)"`
+	StyleMerge     string   `json:"style_merge" jsonschema:"enum=replace,enum=append,description=风格列表与父人格的合并方式 (默认 replace)"`
+	Names          []string `json:"names" jsonschema:"description=要导出的人格 (export，默认全部)"`
+	Path           string   `json:"path" jsonschema:"description=人格包文件路径，相对项目根 (export/import)"`
+	Content        string   `json:"content" jsonschema:"description=人格包 JSON 内容 (import，与 path 二选一)"`
+	Overwrite      bool     `json:"overwrite" jsonschema:"description=导入时覆盖同名人格 (import)；导出时覆盖已存在的文件 (export)"`
 }
 
 // RegisterEnhanceTools 注册增强工具
 func RegisterEnhanceTools(s *server.MCPServer, sm *SessionManager) {
-	s.AddTool(mcp.NewTool("prompt_enhance",
-		mcp.WithDescription(`prompt_enhance - 意图增强与战术规划
+	addTool(s, mcp.NewTool("prompt_enhance",
+		mcp.WithDescription(i18n.Desc("prompt_enhance", `prompt_enhance - 意图增强与战术规划
 
 用途：
   为复杂的小任务快速注入战术执行协议。它会强制 LLM 执行：建立边界 -> 历史探测 -> 意图解析 -> 现状映射 -> 输出清单，并立即开始执行。
@@ -47,22 +58,37 @@ func RegisterEnhanceTools(s *server.MCPServer, sm *SessionManager) {
   
   mode (默认: inject)
     - inject: 注入增强协议并开始任务。
-    - explain: 解释增强协议的具体步骤。
+    - explain: 展示选中的协议、来源与完整渲染结果，并列出可用协议 (不激活)。
+
+  protocol (可选)
+    协议模板名称。未指定时按 intent 选择声明了该意图的模板，都没有时使用 tactical。
+
+  intent (可选)
+    任务意图 (DEBUG/REFACTOR/DESIGN...)。为空时根据 task_description 推断。
 
 说明：
   - 注入后，LLM 会在输出 [ ] 格式的任务清单后，不经确认立即开始顺序执行。
+  - 协议模板放在 .mcp-config/protocols/ (项目) 或 ~/.mpm/protocols/ (全局)，*.md 或 *.tmpl；
+    同名时项目覆盖全局，放 tactical.md 即可覆盖内置协议。
+  - 模板使用 Go text/template 语法，frontmatter 可声明 name/description/intents。可用变量:
+    {{.ProjectName}} {{.ProjectRoot}} {{.TechStack}} {{.Task}} {{.Intent}} {{.Date}}
+    {{.Hooks}} (ID/Summary/Priority/Description/TaskID) {{.Facts}}
+    {{.TaskChain}} (ID/Description/Done/Total/Current/Next)；函数 join/upper/lower。
+  - 提供 task_description 时会附带最相关的技能推荐 (同 skill_search)。
 
 示例：
   prompt_enhance(task_description="重构登录模块的错误处理逻辑")
     -> 激活针对该重构任务的战术协议
 
+  - task_description 命中人格触发词 (triggers) 时会自动激活该人格。
+
 触发词：
-  "mpm 增强", "mpm pe", "mpm enhance"`),
+  "mpm 增强", "mpm pe", "mpm enhance"`)),
 		mcp.WithInputSchema[PromptEnhanceArgs](),
-	), wrapPromptEnhance())
+	), wrapPromptEnhance(sm))
 
-	s.AddTool(mcp.NewTool("persona",
-		mcp.WithDescription(`persona - AI 人格管理工具
+	addTool(s, mcp.NewTool("persona",
+		mcp.WithDescription(i18n.Desc("persona", `persona - AI 人格管理工具
 
 用途：
   切换或列出可用的 AI 人格（角色）。通过改变语气、回复风格和思维协议，提升交互体验或特定场景的处理效率。
@@ -73,7 +99,10 @@ func RegisterEnhanceTools(s *server.MCPServer, sm *SessionManager) {
     - activate: 激活指定的人格。
     - create: 新增人格（写入 .mcp-config/personas.json）。
     - update: 更新人格（支持重命名）。
-    - delete: 删除人格。
+    - delete: 删除人格 (仍被其他人格继承时拒绝)。
+    - deactivate: 取消当前人格，恢复默认风格。
+    - export: 导出人格包 (names 为空则全部；自动带上父人格)。提供 path (项目内相对路径) 时写入文件，否则直接返回 JSON；文件已存在时需 overwrite=true。
+    - import: 导入人格包 (path 或 content)。同名人格默认跳过，overwrite=true 时覆盖。
   
   name (activate/update/delete 模式必填)
     目标人格名称或别名。
@@ -88,11 +117,15 @@ func RegisterEnhanceTools(s *server.MCPServer, sm *SessionManager) {
   create/update 可选字段:
     - new_name, display_name, hard_directive, aliases
     - style_must, style_signature, style_taboo, triggers
+    - extends: 继承父人格。未填写的指令/头像/风格列表沿用父人格。
+    - style_merge: replace (默认，风格列表整体替换) / append (追加在父人格列表之后)
 
 说明：
   - 激活人格后，LLM 将严格遵守该角色的语言特征和指令。
   - 常驻角色包括诸葛（孔明）、懂王（特朗普）、哆啦（哆啦 A 梦）等。
   - 建议在对话中展示简要结果（如已激活人格名称），避免输出冗长内部提示文本。
+  - 激活的人格会在下次 initialize_project 时自动恢复。
+  - manager_analyze / prompt_enhance 的任务描述包含人格触发词 (triggers) 时自动激活该人格。
 
 示例：
   persona(mode="activate", name="zhuge")
@@ -101,8 +134,14 @@ func RegisterEnhanceTools(s *server.MCPServer, sm *SessionManager) {
   persona(mode="create", name="my_architect", display_name="架构师", hard_directive="回答要简洁严谨")
     -> 新增自定义人格
 
+  persona(mode="create", name="strict_zhuge", extends="zhuge", style_taboo=["夸张修辞"])
+    -> 继承孔明，只替换禁用表达
+
+  persona(mode="export", names=["strict_zhuge"], path="personas/pack.json")
+    -> 导出人格包 (含父人格 zhuge)
+
 触发词：
-  "mpm 人格", "mpm persona", "激活人格", "切换人格", "切换到.*人格", "列出人格", "创建人格", "删除人格"`),
+  "mpm 人格", "mpm persona", "激活人格", "切换人格", "切换到.*人格", "列出人格", "创建人格", "删除人格", "导出人格", "导入人格"`)),
 		mcp.WithInputSchema[PersonaArgs](),
 	), wrapPersona(sm))
 }
@@ -147,29 +186,103 @@ const tacticalProtocol = `
 ═══════════════════════════════════════════════════════════════
 `
 
-func wrapPromptEnhance() server.ToolHandlerFunc {
+func wrapPromptEnhance(sm *SessionManager) server.ToolHandlerFunc {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		var args PromptEnhanceArgs
 		request.BindArguments(&args)
 
+		intent := strings.ToUpper(strings.TrimSpace(args.Intent))
+		if intent == "" && strings.TrimSpace(args.TaskDescription) != "" {
+			intent = sm.intentRules().DetermineIntent(args.TaskDescription, "", false)
+		}
+		templates, warnings := loadProtocolTemplates(sm.ProjectRoot)
+		protocol, reason, err := selectProtocol(templates, args.Protocol, intent)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		rendered, err := renderProtocol(protocol, buildProtocolVars(ctx, sm, args.TaskDescription, intent))
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		data := &promptEnhanceData{
+			Protocol: protocol.Name,
+			Source:   protocol.Source,
+			Reason:   reason,
+			Intent:   intent,
+			Rendered: rendered,
+			Warnings: warnings,
+		}
 		if args.Mode == "explain" {
-			return mcp.NewToolResultText("**意图增强协议** (Prompt Enhancement Protocol)\n\n强制 LLM 执行精确的任务规划流程：信息预清理 → 意图解析 → 现状映射 → 逻辑串联 → 任务规划 → 立即执行"), nil
+			data.Templates = templates
+			var sb strings.Builder
+			sb.WriteString(i18n.T("**意图增强协议** (Prompt Enhancement Protocol)\n\n"))
+			sb.WriteString(i18n.T("📜 **协议**: %s (%s)\n", protocol.Name, protocolLocation(protocol)))
+			sb.WriteString(i18n.T("**选择依据**: %s", reason))
+			if intent != "" {
+				sb.WriteString(i18n.T("；意图 %s", intent))
+			}
+			sb.WriteString(i18n.T("\n\n**可用协议**:\n"))
+			for _, tpl := range templates {
+				line := fmt.Sprintf("- `%s` [%s]", tpl.Name, tpl.Source)
+				if tpl.Description != "" {
+					line += " " + tpl.Description
+				}
+				if len(tpl.Intents) > 0 {
+					line += i18n.T(" (意图: %s)", strings.Join(tpl.Intents, ", "))
+				}
+				sb.WriteString(line + "\n")
+			}
+			if len(warnings) > 0 {
+				sb.WriteString(i18n.T("\n⚠️ 以下协议文件有误，已忽略:\n- %s\n", strings.Join(warnings, "\n- ")))
+			}
+			sb.WriteString(i18n.T("\n---\n**渲染结果**:\n"))
+			sb.WriteString(rendered)
+			return mcp.NewToolResultStructured(data, sb.String()), nil
 		}
 
 		var sb strings.Builder
-		sb.WriteString("⚡ **【意图增强协议已激活】**\n\n")
-		sb.WriteString(tacticalProtocol)
-		sb.WriteString("\n\n请立即按照上述协议处理以下任务：\n")
+		if protocol.Source == templateSourceBuiltin {
+			sb.WriteString(i18n.T("⚡ **【意图增强协议已激活】**\n\n"))
+		} else {
+			sb.WriteString(i18n.T("⚡ **【意图增强协议已激活: %s】**\n\n", protocol.Name))
+		}
+		sb.WriteString(rendered)
+		if len(warnings) > 0 {
+			sb.WriteString(i18n.T("\n⚠️ 以下协议文件有误，已忽略:\n- %s\n", strings.Join(warnings, "\n- ")))
+		}
+		sb.WriteString(i18n.T("\n\n请立即按照上述协议处理以下任务：\n"))
 		if args.TaskDescription != "" {
 			sb.WriteString(fmt.Sprintf("> %s\n\n", args.TaskDescription))
 		}
-		sb.WriteString("🔹 第一步：输出 `[ ]` 格式的任务清单\n")
-		sb.WriteString("🔹 第二步：立即开始执行，不要等待确认")
+		data.Skills = suggestSkills(sm, args.TaskDescription, "", defaultSkillSuggestions)
+		if hint := renderSkillSuggestions(data.Skills); hint != "" {
+			sb.WriteString(hint + "\n")
+		}
+		data.Persona = autoActivatePersona(ctx, sm, args.TaskDescription)
+		if activation := renderPersonaActivation(data.Persona); activation != "" {
+			sb.WriteString(activation + "\n")
+		}
+		sb.WriteString(i18n.T("🔹 第一步：输出 `[ ]` 格式的任务清单\n"))
+		sb.WriteString(i18n.T("🔹 第二步：立即开始执行，不要等待确认"))
 
-		return mcp.NewToolResultText(sb.String()), nil
+		return mcp.NewToolResultStructured(data, sb.String()), nil
 	}
 }
 
+// promptEnhanceData prompt_enhance 的结构化结果
+type promptEnhanceData struct {
+	Protocol  string              `json:"protocol"`
+	Source    string              `json:"source"`
+	Reason    string              `json:"reason,omitempty"`
+	Intent    string              `json:"intent,omitempty"`
+	Rendered  string              `json:"rendered"`
+	Warnings  []string            `json:"warnings,omitempty"`
+	Templates []*ProtocolTemplate `json:"templates,omitempty"` // explain 模式列出可用协议
+	Skills    []SkillSuggestion   `json:"suggested_skills,omitempty"`
+	Persona   *PersonaActivation  `json:"persona,omitempty"` // 任务描述命中触发词时自动激活的人格
+}
+
 // PersonaData 人格数据
 type PersonaData struct {
 	Name           string   `json:"name"`
@@ -181,6 +294,8 @@ type PersonaData struct {
 	StyleTaboo     []string `json:"style_taboo"`
 	Aliases        []string `json:"aliases"`
 	Triggers       []string `json:"triggers"`
+	Extends        string   `json:"extends,omitempty"`
+	StyleMerge     string   `json:"style_merge,omitempty"`
 }
 
 type PersonaLibrary struct {
@@ -215,7 +330,7 @@ func findPersonaIndex(library *PersonaLibrary, key string) int {
 func personaOneLineIntro(p PersonaData) string {
 	intro := strings.TrimSpace(p.HardDirective)
 	if intro == "" {
-		return "通用问题求解与任务执行"
+		return i18n.T("通用问题求解与任务执行")
 	}
 
 	separators := []string{"。", "!", "！", "?", "？", ";", "；"}
@@ -244,7 +359,7 @@ func personaDisplayName(p PersonaData) string {
 func savePersonaLibrary(sm *SessionManager, library *PersonaLibrary) error {
 	path := resolveWritablePersonaPath(sm)
 	if path == "" {
-		return fmt.Errorf("项目未初始化，无法持久化人格库")
+		return errors.New(i18n.T("项目未初始化，无法持久化人格库"))
 	}
 
 	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
@@ -378,26 +493,47 @@ func wrapPersona(sm *SessionManager) server.ToolHandlerFunc {
 		// 加载人格库 (支持自定义 + 内建回退)
 		library, err := loadPersonaLibrary(sm)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("加载人格库失败: %v", err)), nil
+			return mcp.NewToolResultError(i18n.T("加载人格库失败: %v", err)), nil
 		}
 
 		if args.Mode == "list" {
 			var sb strings.Builder
-			sb.WriteString("[RELAY_REQUIRED] 请将以下内容直接输出到对话框，不要省略。\n")
-			sb.WriteString(fmt.Sprintf("可用人格列表（共 %d 个）\n", len(library.Personas)))
+			sb.WriteString(i18n.T("[RELAY_REQUIRED] 请将以下内容直接输出到对话框，不要省略。\n"))
+			sb.WriteString(i18n.T("可用人格列表（共 %d 个）\n", len(library.Personas)))
+			var active string
+			if sm.Memory != nil {
+				active, _ = sm.Memory.GetState(ctx, activePersonaStateKey)
+			}
+			items := make([]personaListItem, 0, len(library.Personas))
 			for i, p := range library.Personas {
-				sb.WriteString(fmt.Sprintf("%d. %s (%s): %s\n", i+1, personaDisplayName(p), p.Name, personaOneLineIntro(p)))
+				mark := ""
+				isActive := active != "" && normalizePersonaKey(active) == normalizePersonaKey(p.Name)
+				if isActive {
+					mark = i18n.T(" ★当前")
+				}
+				intro := p
+				if resolved, err := resolvePersona(library, p.Name); err == nil {
+					intro = resolved
+				}
+				items = append(items, personaListItem{PersonaData: p, Active: isActive, Intro: personaOneLineIntro(intro)})
+				sb.WriteString(fmt.Sprintf("%d. %s (%s)%s: %s\n", i+1, personaDisplayName(p), p.Name, mark, personaOneLineIntro(intro)))
+				if strings.TrimSpace(p.Extends) != "" {
+					sb.WriteString(i18n.T("  继承: %s\n", p.Extends))
+				}
 				if len(p.Aliases) > 0 {
-					sb.WriteString(fmt.Sprintf("  别名: %s\n", strings.Join(p.Aliases, ", ")))
+					sb.WriteString(i18n.T("  别名: %s\n", strings.Join(p.Aliases, ", ")))
+				}
+				if len(p.Triggers) > 0 {
+					sb.WriteString(i18n.T("  触发词: %s\n", strings.Join(p.Triggers, ", ")))
 				}
 			}
-			sb.WriteString("\n可直接说：激活人格 <名称>\n")
-			return mcp.NewToolResultText(sb.String()), nil
+			sb.WriteString(i18n.T("\n可直接说：激活人格 <名称>\n"))
+			return mcp.NewToolResultStructured(map[string]any{"personas": items}, sb.String()), nil
 		}
 
 		if args.Mode == "activate" {
 			if args.Name == "" {
-				return mcp.NewToolResultError("activate 模式需要提供 name 参数"), nil
+				return mcp.NewToolResultError(i18n.T("activate 模式需要提供 name 参数")), nil
 			}
 
 			idx := findPersonaIndex(library, args.Name)
@@ -406,42 +542,45 @@ func wrapPersona(sm *SessionManager) server.ToolHandlerFunc {
 				for _, p := range library.Personas {
 					available = append(available, p.Name)
 				}
-				return mcp.NewToolResultText(fmt.Sprintf("未找到人格 '%s'。可用人格: %s", args.Name, strings.Join(available, ", "))), nil
+				return mcp.NewToolResultStructured(map[string]any{"name": args.Name, "found": false, "available": available},
+					i18n.T("未找到人格 '%s'。可用人格: %s", args.Name, strings.Join(available, ", "))), nil
 			}
-			target := &library.Personas[idx]
-
-			// 写入系统状态
-			if sm.Memory != nil {
-				_ = sm.Memory.SaveState(ctx, "active_persona", target.Name, "persona")
+			// 按继承链合并后激活，并写入系统状态
+			target, err := resolvePersona(library, library.Personas[idx].Name)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			activation, err := activatePersona(ctx, sm, library, target.Name)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
 			}
 
-			visible := fmt.Sprintf("[RELAY_REQUIRED] 请将以下内容直接输出到对话框，不要省略。\n🎭 人格已激活：%s (%s)\n> %s\n\n[POST_ACTIVATE_BEHAVIOR] 请立刻以该人格语气再回复用户一句“已到位”的开场白（1 句话）。",
-				personaDisplayName(*target), target.Name, target.HardDirective)
+			visible := i18n.T("[RELAY_REQUIRED] 请将以下内容直接输出到对话框，不要省略。\n🎭 人格已激活：%s (%s)\n> %s\n\n[POST_ACTIVATE_BEHAVIOR] 请立刻以该人格语气再回复用户一句“已到位”的开场白（1 句话）。",
+				activation.DisplayName, target.Name, target.HardDirective)
 
-			llmDirective := buildPersonaDNA(target)
 			return &mcp.CallToolResult{
 				Content: []mcp.Content{mcp.NewTextContent(visible)},
 				StructuredContent: map[string]any{
 					"type":             "persona_activation",
-					"persona_name":     target.Name,
-					"persona_display":  personaDisplayName(*target),
-					"llm_instruction":  llmDirective,
+					"persona_name":     activation.Name,
+					"persona_display":  activation.DisplayName,
+					"llm_instruction":  activation.Instruction,
 					"post_activate_reply_required": true,
-					"post_activate_reply_prompt":   "请立刻以当前人格语气向用户说一句到位开场白（仅一句）。",
-					"activation_notice": "从现在开始，请在后续回复中遵循该人格的语言与风格设定；仅改变表达风格，不得污染代码、日志与命令输出。",
+					"post_activate_reply_prompt":   i18n.T("请立刻以当前人格语气向用户说一句到位开场白（仅一句）。"),
+					"activation_notice": i18n.T("从现在开始，请在后续回复中遵循该人格的语言与风格设定；仅改变表达风格，不得污染代码、日志与命令输出。"),
 				},
 			}, nil
 		}
 
 		if args.Mode == "create" {
 			if sm.ProjectRoot == "" {
-				return mcp.NewToolResultError("create 模式需要先 initialize_project"), nil
+				return mcp.NewToolResultError(i18n.T("create 模式需要先 initialize_project")), nil
 			}
 			if strings.TrimSpace(args.Name) == "" {
-				return mcp.NewToolResultError("create 模式需要提供 name"), nil
+				return mcp.NewToolResultError(i18n.T("create 模式需要提供 name")), nil
 			}
 			if findPersonaIndex(library, args.Name) >= 0 {
-				return mcp.NewToolResultError(fmt.Sprintf("人格 '%s' 已存在", args.Name)), nil
+				return mcp.NewToolResultError(i18n.T("人格 '%s' 已存在", args.Name)), nil
 			}
 
 			displayName := strings.TrimSpace(args.DisplayName)
@@ -450,8 +589,8 @@ func wrapPersona(sm *SessionManager) server.ToolHandlerFunc {
 			}
 
 			hardDirective := strings.TrimSpace(args.HardDirective)
-			if hardDirective == "" {
-				hardDirective = "回答保持专业、准确、简洁。"
+			if hardDirective == "" && strings.TrimSpace(args.Extends) == "" {
+				hardDirective = i18n.T("回答保持专业、准确、简洁。")
 			}
 
 			library.Personas = append(library.Personas, PersonaData{
@@ -464,32 +603,38 @@ func wrapPersona(sm *SessionManager) server.ToolHandlerFunc {
 				StyleSignature: args.StyleSignature,
 				StyleTaboo:     args.StyleTaboo,
 				Triggers:       args.Triggers,
+				Extends:        strings.TrimSpace(args.Extends),
+				StyleMerge:     strings.TrimSpace(args.StyleMerge),
 			})
+			if err := validatePersonaLibrary(library); err != nil {
+				return mcp.NewToolResultError(i18n.T("人格定义无效: %v", err)), nil
+			}
 
 			if err := savePersonaLibrary(sm, library); err != nil {
-				return mcp.NewToolResultError(fmt.Sprintf("保存人格库失败: %v", err)), nil
+				return mcp.NewToolResultError(i18n.T("保存人格库失败: %v", err)), nil
 			}
 
-			return mcp.NewToolResultText(fmt.Sprintf("✅ 已创建人格: %s", args.Name)), nil
+			return mcp.NewToolResultStructured(map[string]any{"created": library.Personas[len(library.Personas)-1]},
+				i18n.T("✅ 已创建人格: %s", args.Name)), nil
 		}
 
 		if args.Mode == "update" {
 			if sm.ProjectRoot == "" {
-				return mcp.NewToolResultError("update 模式需要先 initialize_project"), nil
+				return mcp.NewToolResultError(i18n.T("update 模式需要先 initialize_project")), nil
 			}
 			if strings.TrimSpace(args.Name) == "" {
-				return mcp.NewToolResultError("update 模式需要提供 name"), nil
+				return mcp.NewToolResultError(i18n.T("update 模式需要提供 name")), nil
 			}
 
 			idx := findPersonaIndex(library, args.Name)
 			if idx < 0 {
-				return mcp.NewToolResultError(fmt.Sprintf("未找到人格: %s", args.Name)), nil
+				return mcp.NewToolResultError(i18n.T("未找到人格: %s", args.Name)), nil
 			}
 			p := &library.Personas[idx]
 
 			if strings.TrimSpace(args.NewName) != "" {
 				if exists := findPersonaIndex(library, args.NewName); exists >= 0 && exists != idx {
-					return mcp.NewToolResultError(fmt.Sprintf("新名称冲突: %s", args.NewName)), nil
+					return mcp.NewToolResultError(i18n.T("新名称冲突: %s", args.NewName)), nil
 				}
 				p.Name = strings.TrimSpace(args.NewName)
 			}
@@ -517,41 +662,142 @@ func wrapPersona(sm *SessionManager) server.ToolHandlerFunc {
 			if len(args.Triggers) > 0 {
 				p.Triggers = args.Triggers
 			}
+			if strings.TrimSpace(args.Extends) != "" {
+				p.Extends = strings.TrimSpace(args.Extends)
+			}
+			if strings.TrimSpace(args.StyleMerge) != "" {
+				p.StyleMerge = strings.TrimSpace(args.StyleMerge)
+			}
+			if err := validatePersonaLibrary(library); err != nil {
+				return mcp.NewToolResultError(i18n.T("人格定义无效: %v", err)), nil
+			}
 
 			if err := savePersonaLibrary(sm, library); err != nil {
-				return mcp.NewToolResultError(fmt.Sprintf("保存人格库失败: %v", err)), nil
+				return mcp.NewToolResultError(i18n.T("保存人格库失败: %v", err)), nil
 			}
 
-			return mcp.NewToolResultText(fmt.Sprintf("✅ 已更新人格: %s", p.Name)), nil
+			return mcp.NewToolResultStructured(map[string]any{"updated": *p}, i18n.T("✅ 已更新人格: %s", p.Name)), nil
 		}
 
 		if args.Mode == "delete" {
 			if sm.ProjectRoot == "" {
-				return mcp.NewToolResultError("delete 模式需要先 initialize_project"), nil
+				return mcp.NewToolResultError(i18n.T("delete 模式需要先 initialize_project")), nil
 			}
 			if strings.TrimSpace(args.Name) == "" {
-				return mcp.NewToolResultError("delete 模式需要提供 name"), nil
+				return mcp.NewToolResultError(i18n.T("delete 模式需要提供 name")), nil
 			}
 
 			idx := findPersonaIndex(library, args.Name)
 			if idx < 0 {
-				return mcp.NewToolResultError(fmt.Sprintf("未找到人格: %s", args.Name)), nil
+				return mcp.NewToolResultError(i18n.T("未找到人格: %s", args.Name)), nil
 			}
 
+			if dependents := personaDependents(library, idx); len(dependents) > 0 {
+				return mcp.NewToolResultError(i18n.T("人格 %s 仍被继承: %s，请先删除或修改这些人格", library.Personas[idx].Name, strings.Join(dependents, ", "))), nil
+			}
 			removed := library.Personas[idx].Name
 			library.Personas = append(library.Personas[:idx], library.Personas[idx+1:]...)
 
 			if err := savePersonaLibrary(sm, library); err != nil {
-				return mcp.NewToolResultError(fmt.Sprintf("保存人格库失败: %v", err)), nil
+				return mcp.NewToolResultError(i18n.T("保存人格库失败: %v", err)), nil
 			}
 
-			return mcp.NewToolResultText(fmt.Sprintf("✅ 已删除人格: %s", removed)), nil
+			return mcp.NewToolResultStructured(map[string]any{"deleted": removed}, i18n.T("✅ 已删除人格: %s", removed)), nil
 		}
 
-		return mcp.NewToolResultError(fmt.Sprintf("未知模式: %s", args.Mode)), nil
+		if args.Mode == "deactivate" {
+			if sm.Memory == nil {
+				return mcp.NewToolResultError(i18n.T("deactivate 模式需要先 initialize_project")), nil
+			}
+			_ = sm.Memory.SaveState(ctx, activePersonaStateKey, "", "persona")
+			return mcp.NewToolResultStructured(map[string]any{"active": nil}, i18n.T("✅ 已取消人格，恢复默认风格。")), nil
+		}
+
+		if args.Mode == "export" {
+			pack, err := exportPersonaPack(library, args.Names)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if strings.TrimSpace(args.Path) == "" {
+				data, err := json.MarshalIndent(pack, "", "  ")
+				if err != nil {
+					return mcp.NewToolResultError(i18n.T("JSON 序列化失败: %v", err)), nil
+				}
+				return mcp.NewToolResultStructured(pack, string(data)), nil
+			}
+			path, err := resolvePersonaPackPath(sm, args.Path)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if err := writePersonaPack(path, pack, args.Overwrite); err != nil {
+				if errors.Is(err, fs.ErrExist) {
+					return mcp.NewToolResultError(i18n.T("文件 %s 已存在，如需覆盖请设置 overwrite=true", args.Path)), nil
+				}
+				return mcp.NewToolResultError(i18n.T("写入人格包失败: %v", err)), nil
+			}
+			return mcp.NewToolResultStructured(map[string]any{"path": path, "personas": len(pack.Personas)},
+				i18n.T("✅ 已导出 %d 个人格到 %s", len(pack.Personas), path)), nil
+		}
+
+		if args.Mode == "import" {
+			if sm.ProjectRoot == "" {
+				return mcp.NewToolResultError(i18n.T("import 模式需要先 initialize_project")), nil
+			}
+			var data []byte
+			switch {
+			case strings.TrimSpace(args.Content) != "":
+				data = []byte(args.Content)
+			case strings.TrimSpace(args.Path) != "":
+				path, err := resolvePersonaPackPath(sm, args.Path)
+				if err != nil {
+					return mcp.NewToolResultError(err.Error()), nil
+				}
+				data, err = os.ReadFile(path)
+				if err != nil {
+					return mcp.NewToolResultError(i18n.T("读取人格包失败: %v", err)), nil
+				}
+			default:
+				return mcp.NewToolResultError(i18n.T("import 模式需要提供 path 或 content")), nil
+			}
+			pack, err := parsePersonaPack(data)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			added, replaced, skipped, err := importPersonaPack(library, pack, args.Overwrite)
+			if err != nil {
+				return mcp.NewToolResultError(i18n.T("导入失败: %v", err)), nil
+			}
+			if len(added)+len(replaced) > 0 {
+				if err := savePersonaLibrary(sm, library); err != nil {
+					return mcp.NewToolResultError(i18n.T("保存人格库失败: %v", err)), nil
+				}
+			}
+
+			var sb strings.Builder
+			sb.WriteString(i18n.T("✅ 人格包导入完成 (新增 %d，覆盖 %d，跳过 %d)\n", len(added), len(replaced), len(skipped)))
+			if len(added) > 0 {
+				sb.WriteString(i18n.T("- 新增: %s\n", strings.Join(added, ", ")))
+			}
+			if len(replaced) > 0 {
+				sb.WriteString(i18n.T("- 覆盖: %s\n", strings.Join(replaced, ", ")))
+			}
+			if len(skipped) > 0 {
+				sb.WriteString(i18n.T("- 跳过 (已存在，需 overwrite=true): %s\n", strings.Join(skipped, ", ")))
+			}
+			return mcp.NewToolResultStructured(map[string]any{"added": added, "replaced": replaced, "skipped": skipped}, sb.String()), nil
+		}
+
+		return mcp.NewToolResultError(i18n.T("未知模式: %s", args.Mode)), nil
 	}
 }
 
+// personaListItem persona(mode="list") 的结构化条目
+type personaListItem struct {
+	PersonaData
+	Active bool   `json:"active"`
+	Intro  string `json:"intro"`
+}
+
 func loadPersonaLibrary(sm *SessionManager) (*PersonaLibrary, error) {
 	// 1) 先加载全局人格库（历史行为，默认源）
 	var globalLib *PersonaLibrary
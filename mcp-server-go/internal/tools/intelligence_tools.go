@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"mcp-server-go/internal/i18n"
 	"mcp-server-go/internal/services"
 	"strings"
 	"time"
@@ -15,12 +16,13 @@ import (
 // AnalyzeArgs 任务分析参数
 type AnalyzeArgs struct {
 	TaskDescription string   `json:"task_description" jsonschema:"required,description=用户的原始指令/任务详情"`
-	Intent          string   `json:"intent" jsonschema:"description=LLM 自行判断的意向 (DEBUG/DEVELOP/REFACTOR/DESIGN/RESEARCH)"`
+	Intent          string   `json:"intent" jsonschema:"description=LLM 自行判断的意向 (DEBUG/DEVELOP/REFACTOR/DESIGN/RESEARCH 或 intent_rules.yaml 自定义意图)"`
 	Symbols         []string `json:"symbols" jsonschema:"description=提取的代码符号"`
 	ReadOnly        bool     `json:"read_only" jsonschema:"description=是否为只读分析模式"`
 	Scope           string   `json:"scope" jsonschema:"description=任务范围描述"`
 	Step            int      `json:"step" jsonschema:"description=执行步骤 (1=分析, 2=生成策略)，默认为1"`
 	TaskID          string   `json:"task_id" jsonschema:"description=步骤2时必填，步骤1返回的 task_id"`
+	TTLHours        int      `json:"ttl_hours" jsonschema:"description=步骤1：简报保留时长（小时），默认 72；关联任务链后不再过期"`
 }
 
 // FactArgs 事实存档参数
@@ -31,13 +33,17 @@ type FactArgs struct {
 
 // MissionBriefing 情报包结构
 type MissionBriefing struct {
+	TaskID           string                 `json:"task_id"`
 	MissionControl   MissionControl         `json:"mission_control"`
 	ContextAnchors   []CodeAnchor           `json:"context_anchors"`
 	VerifiedFacts    []string               `json:"verified_facts"`
+	RelatedMemos     []string               `json:"related_memos,omitempty"`
 	Telemetry        map[string]interface{} `json:"telemetry"`
 	Guardrails       Guardrails             `json:"guardrails"`
 	Alerts           []string               `json:"alerts"`
+	SuggestedSkills  []SkillSuggestion      `json:"suggested_skills,omitempty"`
 	StrategicHandoff string                 `json:"strategic_handoff"`
+	NextStep         string                 `json:"next_step,omitempty"`
 }
 
 type MissionControl struct {
@@ -47,23 +53,28 @@ type MissionControl struct {
 
 // RegisterIntelligenceTools 注册智能分析工具
 func RegisterIntelligenceTools(s *server.MCPServer, sm *SessionManager, ai *services.ASTIndexer) {
-	s.AddTool(mcp.NewTool("manager_analyze",
-		mcp.WithDescription(`manager_analyze - 任务情报聚合与战术简报（两步自迭代）
+	addTool(s, mcp.NewTool("manager_analyze",
+		mcp.WithDescription(i18n.Desc("manager_analyze", `manager_analyze - 任务情报聚合与战术简报（两步自迭代）
 
 用途：
   【必选】复杂任务启动入口。采用两步自迭代模式：
 
   步骤1（step=1）：真实分析
     - AST 搜索代码定位
-    - 加载历史经验
+    - 按相关度加载铁律与备忘（锚点文件/符号、调用方、任务关键词，去重并限制 token 预算）
     - 复杂度评估
     - 生成约束规则
+    - 按触发词/描述/分类推荐相关技能 (suggested_skills)
     返回：分析结果 + task_id
 
   步骤2（step=2）：动态策略
     - 基于步骤1的真实分析结果
     - 动态生成战术建议
     - 返回：完整的 Mission Briefing（含 strategic_handoff）
+    - 可重复调用；简报存于项目数据库，重启后仍可用
+
+  简报默认保留 72 小时（ttl_hours 可调），用 manager_list_briefings 查看；
+  task_chain(mode="step", analysis_id=...) 关联后不再过期。
 
   ⚠️ 注意：此工具不具备自然语言理解能力。
   你必须先运用逻辑能力，从用户指令中解析出「意图」和「关键符号」，填入参数。
@@ -78,6 +89,7 @@ func RegisterIntelligenceTools(s *server.MCPServer, sm *SessionManager, ai *serv
     - DEVELOP: 新功能开发
     - REFACTOR: 代码重构
     - RESEARCH: 技术调研
+    - 以及 intent_rules.yaml 中自定义的意图 (如 SECURITY、MIGRATION)
 
   symbols (必填)
     基于你的分析，提取指令中涉及的核心函数名、类名或文件名。
@@ -89,17 +101,20 @@ func RegisterIntelligenceTools(s *server.MCPServer, sm *SessionManager, ai *serv
   task_id (步骤2时必填)
     步骤1返回的 task_id，用于获取上一步的分析结果。
 
+  ttl_hours (可选，步骤1)
+    简报保留时长（小时），默认 72，最长 720。
+
 返回：
   步骤1：分析结果 + task_id
   步骤2：完整的 Mission Briefing JSON
 
 触发词：
-  "mpm 分析", "mpm 任务", "mpm mg", "mpm analyze"`),
+  "mpm 分析", "mpm 任务", "mpm mg", "mpm analyze"`)),
 		mcp.WithInputSchema[AnalyzeArgs](),
 	), wrapAnalyze(sm, ai))
 
-	s.AddTool(mcp.NewTool("known_facts",
-		mcp.WithDescription(`known_facts - 原子级经验事实存档
+	addTool(s, mcp.NewTool("known_facts",
+		mcp.WithDescription(i18n.Desc("known_facts", `known_facts - 原子级经验事实存档
 
 用途：
   将经过验证的代码规则、铁律或重要的避坑经验存入记忆层。这些事实会被 manager_analyze 自动加载，以防止在未来的任务中犯同样的错误。
@@ -116,20 +131,47 @@ func RegisterIntelligenceTools(s *server.MCPServer, sm *SessionManager, ai *serv
     -> 保存一条重要的经验法则
 
 触发词：
-  "mpm 铁律", "mpm 避坑", "mpm fact"`),
+  "mpm 铁律", "mpm 避坑", "mpm fact"`)),
 		mcp.WithInputSchema[FactArgs](),
 	), wrapSaveFact(sm))
+
+	addTool(s, mcp.NewTool("manager_list_briefings",
+		mcp.WithDescription(i18n.Desc("manager_list_briefings", `manager_list_briefings - 查看 manager_analyze 分析简报
+
+用途：
+  列出保存在项目数据库中的分析简报（意图、指令、过期时间、关联的任务链），
+  或查看单个简报的锚点与约束。重启/换窗口后可据此继续 step=2。
+
+参数：
+  task_id (可选)
+    manager_analyze 返回的 task_id，填写则显示该简报详情。
+
+  include_expired (默认 false)
+    是否显示已过期的简报。
+
+  limit (默认 20)
+    最多显示条数。
+
+返回：
+  简报列表或单个简报详情
+
+触发词：
+  "mpm 简报", "mpm briefings"`)),
+		mcp.WithInputSchema[BriefingListArgs](),
+	), wrapListBriefings(sm))
+
+	registerEditGuardTools(s, sm)
 }
 
 func wrapAnalyze(sm *SessionManager, ai *services.ASTIndexer) server.ToolHandlerFunc {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		var args AnalyzeArgs
 		if err := request.BindArguments(&args); err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("参数格式错误: %v", err)), nil
+			return mcp.NewToolResultError(i18n.T("参数格式错误: %v", err)), nil
 		}
 
-		if sm.ProjectRoot == "" {
-			return mcp.NewToolResultError("⚠️ 项目未初始化，无法执行任务分析。请先调用 initialize_project。"), nil
+		if sm.ProjectRoot == "" || sm.Memory == nil {
+			return mcp.NewToolResultError(i18n.T("⚠️ 项目未初始化，无法执行任务分析。请先调用 initialize_project。")), nil
 		}
 
 		// 默认 step = 1
@@ -147,7 +189,7 @@ func wrapAnalyze(sm *SessionManager, ai *services.ASTIndexer) server.ToolHandler
 			// Step 2: 使用用户传入的 taskID
 			taskID = args.TaskID
 			if taskID == "" {
-				return mcp.NewToolResultError("⚠️ Step 2 需要提供 task_id 参数（来自 Step 1 的返回值）"), nil
+				return mcp.NewToolResultError(i18n.T("⚠️ Step 2 需要提供 task_id 参数（来自 Step 1 的返回值）")), nil
 			}
 		}
 
@@ -156,7 +198,7 @@ func wrapAnalyze(sm *SessionManager, ai *services.ASTIndexer) server.ToolHandler
 			return handleAnalyzeStep1(ctx, sm, ai, args, taskID)
 		} else {
 			// ===== 步骤2：动态策略 =====
-			return handleAnalyzeStep2(sm, ai, args, taskID)
+			return handleAnalyzeStep2(ctx, sm, args, taskID)
 		}
 	}
 }
@@ -164,11 +206,13 @@ func wrapAnalyze(sm *SessionManager, ai *services.ASTIndexer) server.ToolHandler
 // handleAnalyzeStep1 执行第一步：真实分析，保存状态
 func handleAnalyzeStep1(ctx context.Context, sm *SessionManager, ai *services.ASTIndexer, args AnalyzeArgs, taskID string) (*mcp.CallToolResult, error) {
 	// 1. 意图识别
-	intent := determineIntent(args.TaskDescription, args.Intent, args.ReadOnly)
+	rules := sm.intentRules()
+	intent := rules.DetermineIntent(args.TaskDescription, args.Intent, args.ReadOnly)
 
 	// 2. 符号预搜索 (Code Anchors)
 	var anchors []CodeAnchor
-	limit := 10
+	cfg := sm.config()
+	limit := cfg.Analyze.AnchorLimit
 	if len(args.Symbols) < limit {
 		limit = len(args.Symbols)
 	}
@@ -194,48 +238,74 @@ func handleAnalyzeStep1(ctx context.Context, sm *SessionManager, ai *services.AS
 		}
 	}
 
-	// 3. 记忆加载（仅 Facts）
-	var facts []string
-	if sm.Memory != nil {
-		knownFacts, _ := sm.Memory.QueryFacts(ctx, "", 10)
-		for _, f := range knownFacts {
-			facts = append(facts, f.Summarize)
-		}
-	}
+	// 3. 记忆加载：按与锚点/调用方/任务关键词的相关度挑选铁律与备忘
+	facts, memos, contextStats := loadRelevantContext(ctx, sm, ai, args.TaskDescription, anchors)
 
 	// 4. 构建禁令 (Guardrails)
-	guardrails := buildGuardrails(intent, args.ReadOnly)
+	critical, advisory := rules.Guardrails(intent, args.ReadOnly)
+	guardrails := Guardrails{Critical: critical, Advisory: advisory}
 
 	// 5. 复杂度分析与遥测
 	telemetry := make(map[string]interface{})
+	telemetry["context"] = contextStats
 	var complexityAlerts []string
 
 	if len(args.Symbols) > 0 {
 		compReport, err := ai.AnalyzeComplexity(sm.ProjectRoot, args.Symbols)
 		if err == nil && compReport != nil {
 			maxScore := 0.0
+			maxCoupling := 0.0
+			var worstSymbol string
+			var worstMetrics *services.SymbolMetrics
+			var maxFanIn, maxFanOut int
 			for _, risk := range compReport.HighRiskSymbols {
-				if risk.Score > maxScore {
+				if risk.Score > maxScore || worstSymbol == "" {
 					maxScore = risk.Score
+					worstSymbol = risk.SymbolName
+					worstMetrics = risk.Metrics
+				}
+				if risk.Coupling > maxCoupling {
+					maxCoupling = risk.Coupling
 				}
+				maxFanIn = max(maxFanIn, risk.FanIn)
+				maxFanOut = max(maxFanOut, risk.FanOut)
 				if risk.Score >= 50 {
 					complexityAlerts = append(complexityAlerts, fmt.Sprintf("⚠️ [Complexity] %s: %.1f - %s", risk.SymbolName, risk.Score, risk.Reason))
 				}
 			}
 
-			telemetry["complexity"] = map[string]interface{}{
-				"score": maxScore,
-				"level": getComplexityLevel(maxScore),
+			complexity := map[string]interface{}{
+				"score":  maxScore,
+				"level":  getComplexityLevel(maxScore),
+				"symbol": worstSymbol,
+			}
+			if worstMetrics != nil {
+				complexity["cyclomatic"] = worstMetrics.Cyclomatic
+				complexity["cognitive"] = worstMetrics.Cognitive
+				complexity["line_count"] = worstMetrics.LineCount
+				complexity["param_count"] = worstMetrics.ParamCount
+				complexity["max_nesting"] = worstMetrics.MaxNesting
+			}
+			telemetry["complexity"] = complexity
+
+			// 耦合度独立于复杂度：影响面大不代表函数难改
+			telemetry["coupling"] = map[string]interface{}{
+				"score":   maxCoupling,
+				"fan_in":  maxFanIn,
+				"fan_out": maxFanOut,
 			}
 		}
 	}
 
 	// 6. 生成综合警告
-	alerts := generateAlerts(args.TaskDescription, intent, args.ReadOnly)
+	alerts := rules.MatchAlerts(args.TaskDescription, args.ReadOnly)
 	alerts = append(alerts, complexityAlerts...)
 
-	// 7. 保存状态到 Session
-	directiveLimit := 300
+	// 7. 推荐技能
+	skills := suggestSkills(sm, args.TaskDescription, intent, defaultSkillSuggestions)
+
+	// 8. 持久化到项目数据库（带过期时间）
+	directiveLimit := cfg.Analyze.DirectiveMaxChars
 	directive := args.TaskDescription
 	if len(directive) > directiveLimit {
 		directive = directive[:directiveLimit] + "..."
@@ -246,17 +316,19 @@ func handleAnalyzeStep1(ctx context.Context, sm *SessionManager, ai *services.AS
 		UserDirective:  directive,
 		ContextAnchors: anchors,
 		VerifiedFacts:  facts,
+		RelatedMemos:   memos,
 		Telemetry:      telemetry,
 		Guardrails:     guardrails,
 		Alerts:         alerts,
+		Skills:         skills,
 	}
 
-	if sm.AnalysisState == nil {
-		sm.AnalysisState = make(map[string]*AnalysisState)
+	expiresAt, err := saveAnalysisBriefing(ctx, sm, taskID, state, args.TTLHours)
+	if err != nil {
+		return mcp.NewToolResultError(i18n.T("保存分析简报失败: %v", err)), nil
 	}
-	sm.AnalysisState[taskID] = state
 
-	// 8. 返回第一步结果（不包含 strategic_handoff）
+	// 9. 返回第一步结果（不包含 strategic_handoff）
 	step1Result := map[string]interface{}{
 		"step":    1,
 		"task_id": taskID,
@@ -264,78 +336,93 @@ func handleAnalyzeStep1(ctx context.Context, sm *SessionManager, ai *services.AS
 			"intent":         intent,
 			"user_directive": directive,
 		},
-		"context_anchors": anchors,
-		"verified_facts":  facts,
-		"telemetry":       telemetry,
-		"guardrails":      guardrails,
-		"alerts":          alerts,
-		"next_step":       "调用 manager_analyze(step=2, task_id=\"" + taskID + "\") 生成战术策略",
+		"context_anchors":  anchors,
+		"verified_facts":   facts,
+		"related_memos":    memos,
+		"telemetry":        telemetry,
+		"guardrails":       guardrails,
+		"alerts":           alerts,
+		"suggested_skills": skills,
+		"expires_at":       expiresAt.Format("2006-01-02 15:04"),
+		"next_step":        i18n.T("调用 manager_analyze(step=2, task_id=\"%s\") 生成战术策略", taskID),
+	}
+	// 任务描述命中人格触发词时自动切换人格
+	if activation := autoActivatePersona(ctx, sm, args.TaskDescription); activation != nil {
+		step1Result["persona_activation"] = activation
 	}
 
 	jsonData, err := json.MarshalIndent(step1Result, "", "  ")
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("JSON 序列化失败: %v", err)), nil
+		return mcp.NewToolResultError(i18n.T("JSON 序列化失败: %v", err)), nil
 	}
 
-	return mcp.NewToolResultText(string(jsonData)), nil
+	return mcp.NewToolResultStructured(step1Result, string(jsonData)), nil
 }
 
-// handleAnalyzeStep2 执行第二步：基于第一步结果动态生成 strategic_handoff
-func handleAnalyzeStep2(sm *SessionManager, ai *services.ASTIndexer, args AnalyzeArgs, taskID string) (*mcp.CallToolResult, error) {
-	// 1. 从 Session 读取第一步的状态
-	state, exists := sm.AnalysisState[taskID]
-	if !exists {
-		return mcp.NewToolResultError("⚠️ 未找到第一步的分析结果，请先调用 manager_analyze(step=1)"), nil
+// handleAnalyzeStep2 执行第二步：基于第一步结果动态生成 strategic_handoff（可重复调用）
+func handleAnalyzeStep2(ctx context.Context, sm *SessionManager, args AnalyzeArgs, taskID string) (*mcp.CallToolResult, error) {
+	// 1. 从项目数据库读取第一步的状态（重启后仍可用）
+	record, state, err := loadAnalysisBriefing(ctx, sm, taskID)
+	if err != nil {
+		return mcp.NewToolResultError("⚠️ " + err.Error()), nil
 	}
 
 	// 2. 基于第一步结果动态生成 strategic_handoff
-	strategicHandoff := generateDynamicStrategicHandoff(state)
+	strategicHandoff := generateDynamicStrategicHandoff(state, sm.intentRules())
 
 	// 3. 组装完整的 Mission Briefing
 	briefing := MissionBriefing{
+		TaskID: taskID,
 		MissionControl: MissionControl{
 			Intent:        state.Intent,
 			UserDirective: state.UserDirective,
 		},
 		ContextAnchors:   state.ContextAnchors,
 		VerifiedFacts:    state.VerifiedFacts,
+		RelatedMemos:     state.RelatedMemos,
 		Telemetry:        state.Telemetry,
 		Guardrails:       state.Guardrails,
 		Alerts:           state.Alerts,
+		SuggestedSkills:  state.Skills,
 		StrategicHandoff: strategicHandoff,
 	}
+	if record.ChainID == "" {
+		briefing.NextStep = i18n.T(`执行时用 task_chain(mode="step", task_id="...", analysis_id="%s", plan=[...]) 关联本简报`, taskID)
+	} else {
+		briefing.NextStep = i18n.T("本简报由任务链 %s 执行中", record.ChainID)
+	}
 
-	// 4. 清理临时状态
-	delete(sm.AnalysisState, taskID)
+	// 4. 保留简报以便再次生成策略，并顺延过期时间
+	touchAnalysisBriefing(ctx, sm, record)
 
 	// 5. 返回第二步结果
 	jsonData, err := json.MarshalIndent(briefing, "", "  ")
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("JSON 序列化失败: %v", err)), nil
+		return mcp.NewToolResultError(i18n.T("JSON 序列化失败: %v", err)), nil
 	}
 
-	return mcp.NewToolResultText(string(jsonData)), nil
+	return mcp.NewToolResultStructured(briefing, string(jsonData)), nil
 }
 
 // generateDynamicStrategicHandoff 基于第一步分析结果动态生成 strategic_handoff
-func generateDynamicStrategicHandoff(state *AnalysisState) string {
+func generateDynamicStrategicHandoff(state *AnalysisState, rules *services.IntentRules) string {
 	var parts []string
 
 	// 1. 任务意图
-	intentHint := getIntentHint(state.Intent)
-	parts = append(parts, fmt.Sprintf("[任务意图]: %s", state.Intent))
+	intentHint := rules.Hint(state.Intent)
+	parts = append(parts, i18n.T("[任务意图]: %s", state.Intent))
 	parts = append(parts, intentHint)
 
 	// 2. 基于真实分析结果的建议
 	parts = append(parts, "")
-	parts = append(parts, "[情报评估与建议]")
+	parts = append(parts, i18n.T("[情报评估与建议]"))
 
 	// 2.1 代码定位情况
 	if len(state.ContextAnchors) == 0 {
-		parts = append(parts, "!!! CRITICAL: 未定位到任何代码符号 !!!")
-		parts = append(parts, "建议：使用 project_map 查看项目结构，或检查 symbols 参数是否正确")
+		parts = append(parts, i18n.T("!!! CRITICAL: 未定位到任何代码符号 !!!"))
+		parts = append(parts, i18n.T("建议：使用 project_map 查看项目结构，或检查 symbols 参数是否正确"))
 	} else {
-		parts = append(parts, fmt.Sprintf("已定位到 %d 个代码符号", len(state.ContextAnchors)))
+		parts = append(parts, i18n.T("已定位到 %d 个代码符号", len(state.ContextAnchors)))
 	}
 
 	// 2.2 复杂度评估
@@ -343,12 +430,12 @@ func generateDynamicStrategicHandoff(state *AnalysisState) string {
 		if level, ok := comp["level"].(string); ok {
 			switch level {
 			case "High":
-				parts = append(parts, "!!! 任务复杂度极高 !!!")
-				parts = append(parts, "建议：使用 code_impact 先分析影响范围，避免遗漏依赖关系")
+				parts = append(parts, i18n.T("!!! 任务复杂度极高 !!!"))
+				parts = append(parts, i18n.T("建议：使用 code_impact 先分析影响范围，避免遗漏依赖关系"))
 			case "Medium":
-				parts = append(parts, "任务复杂度中等，建议谨慎处理")
+				parts = append(parts, i18n.T("任务复杂度中等，建议谨慎处理"))
 			case "Low":
-				parts = append(parts, "任务复杂度较低，可直接开始")
+				parts = append(parts, i18n.T("任务复杂度较低，可直接开始"))
 			}
 		}
 	}
@@ -365,130 +452,41 @@ func generateDynamicStrategicHandoff(state *AnalysisState) string {
 
 	// 3. Vibe Coding 规范
 	parts = append(parts, "")
-	parts = append(parts, "[Vibe Coding 规范]")
-	parts = append(parts, "✅ 建议：AI友好命名，函数名即文档，最简代码，如无必要勿增实体")
-	parts = append(parts, "❌ 禁止：并行调试系统，遗留代码不清理，走捷径保留原路，擅自写文档")
+	parts = append(parts, i18n.T("[Vibe Coding 规范]"))
+	parts = append(parts, i18n.T("✅ 建议：AI友好命名，函数名即文档，最简代码，如无必要勿增实体"))
+	parts = append(parts, i18n.T("❌ 禁止：并行调试系统，遗留代码不清理，走捷径保留原路，擅自写文档"))
 
 	// 4. Tool Strategy
 	parts = append(parts, "")
-	parts = append(parts, "[Tool Strategy - 基于情报分析]")
+	parts = append(parts, i18n.T("[Tool Strategy - 基于情报分析]"))
 
 	// 根据实际情况给出工具建议
 	if len(state.ContextAnchors) == 0 {
-		parts = append(parts, "• 优先使用 project_map 了解项目结构")
-		parts = append(parts, "• 使用 code_search 精确定位代码符号")
+		parts = append(parts, i18n.T("• 优先使用 project_map 了解项目结构"))
+		parts = append(parts, i18n.T("• 使用 code_search 精确定位代码符号"))
 	} else {
-		parts = append(parts, "• 已定位代码，可直接使用 code_impact 分析影响范围")
-		parts = append(parts, "• 修改代码后务必使用 memo 记录")
+		parts = append(parts, i18n.T("• 已定位代码，可直接使用 code_impact 分析影响范围"))
+		parts = append(parts, i18n.T("• 修改代码后务必使用 memo 记录"))
+	}
+	if len(state.Skills) > 0 {
+		names := make([]string, 0, len(state.Skills))
+		for _, sk := range state.Skills {
+			names = append(names, sk.Name)
+		}
+		parts = append(parts, i18n.T("• 相关技能：%s，动手前可用 skill_load 加载", strings.Join(names, ", ")))
 	}
 
 	// 5. 你的判断
 	parts = append(parts, "")
-	parts = append(parts, "[你的判断]")
-	parts = append(parts, "以上情报基于实际代码分析生成。请根据情报充分性判断是否需要补充调研。")
-	parts = append(parts, "你拥有完全自主权。")
+	parts = append(parts, i18n.T("[你的判断]"))
+	parts = append(parts, i18n.T("以上情报基于实际代码分析生成。请根据情报充分性判断是否需要补充调研。"))
+	parts = append(parts, i18n.T("你拥有完全自主权。"))
 
 	return strings.Join(parts, "\n")
 }
 
 // 辅助逻辑
 
-func determineIntent(desc, explicitIntent string, readOnly bool) string {
-	validIntents := map[string]bool{
-		"DEBUG": true, "DEVELOP": true, "REFACTOR": true,
-		"DESIGN": true, "RESEARCH": true, "PERFORMANCE": true, "REFLECT": true,
-	}
-
-	if explicitIntent != "" {
-		upper := strings.ToUpper(explicitIntent)
-		if validIntents[upper] {
-			return upper
-		}
-	}
-
-	descLower := strings.ToLower(desc)
-	if strings.Contains(descLower, "debug") || strings.Contains(descLower, "fix") || strings.Contains(descLower, "修复") || strings.Contains(descLower, "报错") {
-		return "DEBUG"
-	}
-	if strings.Contains(descLower, "refactor") || strings.Contains(descLower, "重构") {
-		return "REFACTOR"
-	}
-	if strings.Contains(descLower, "analy") || strings.Contains(descLower, "分析") || strings.Contains(descLower, "调研") || strings.Contains(descLower, "research") {
-		return "RESEARCH"
-	}
-	if strings.Contains(descLower, "design") || strings.Contains(descLower, "设计") || strings.Contains(descLower, "架构") {
-		return "DESIGN"
-	}
-
-	if readOnly {
-		return "RESEARCH"
-	}
-
-	return ""
-}
-
-func buildGuardrails(intent string, readOnly bool) Guardrails {
-	g := Guardrails{
-		Critical: []string{},
-		Advisory: []string{"最小变更，不做大爆炸重构"},
-	}
-
-	if readOnly {
-		g.Critical = append(g.Critical, "READ_ONLY: 严禁修改任何文件")
-	}
-
-	switch intent {
-	case "DESIGN":
-		g.Critical = append(g.Critical, "NO_CODE_EDIT: 严禁编辑业务代码", "MD_ONLY: 仅允许创建 .md 文档")
-	case "RESEARCH":
-		if !readOnly {
-			g.Critical = append(g.Critical, "READ_ONLY: 严禁修改任何文件")
-		}
-	case "DEBUG":
-		g.Critical = append(g.Critical, "VERIFY_FIRST: 修改前必须先定位根因", "NO_BLIND_REWRITE: 禁止盲目重写整个文件")
-	case "PERFORMANCE":
-		g.Critical = append(g.Critical, "PROFILE_FIRST: 修改前必须先执行性能分析定位瓶颈", "MEASURE_AFTER: 优化后必须用基准测试验证性能提升")
-	case "REFACTOR":
-		g.Advisory = append(g.Advisory, "INCREMENTAL: 小步快跑，每步可验证", "VERIFY_EACH_STEP: 每次修改后运行测试确认未破坏功能")
-	case "REFLECT":
-		g.Critical = append(g.Critical, "READ_ONLY: 严禁修改任何文件", "EVIDENCE_BASED: 所有结论必须基于 memo/system_recall 的历史证据")
-	}
-
-	return g
-}
-
-func generateAlerts(desc, intent string, readOnly bool) []string {
-	var alerts []string
-
-	if !readOnly && (strings.Contains(desc, "修改") || strings.Contains(desc, "update") || strings.Contains(desc, "change")) {
-		alerts = append(alerts, "Modification detected. Call code_impact(symbol_name=...) first.")
-	}
-
-	if strings.Contains(desc, "migrate") || strings.Contains(desc, "迁移") || strings.Contains(desc, "升级") {
-		alerts = append(alerts, "🔒 **约束建议**: 技术栈变更。建议添加约束规则,禁止使用旧技术栈的API或模式。")
-	}
-
-	// 新功能开发调研提醒
-	newFeatureKeywords := []string{"开发", "新增", "添加", "implement", "create", "feature", "module"}
-	isNewFeature := false
-	matchCount := 0
-	descLower := strings.ToLower(desc)
-	for _, k := range newFeatureKeywords {
-		if strings.Contains(descLower, k) {
-			matchCount++
-		}
-	}
-	if matchCount >= 1 && !readOnly {
-		isNewFeature = true
-	}
-
-	if isNewFeature {
-		alerts = append(alerts, "[技术调研提醒]: 开发新组件前，请先执行技术调研。使用 search_web 搜索现有库/方案，避免重复造轮子。")
-	}
-
-	return alerts
-}
-
 func getComplexityLevel(score float64) string {
 	if score >= 70 {
 		return "High"
@@ -499,43 +497,23 @@ func getComplexityLevel(score float64) string {
 	return "Low"
 }
 
-func getIntentHint(intent string) string {
-	switch intent {
-	case "DEBUG":
-		return "🔧 定位根因 → 验证修复。可构建/复用项目专用debug环境，可搜索"
-	case "DEVELOP":
-		return "🚀 明确修改点 → 最小变更。优先找成熟库，可搜索"
-	case "REFACTOR":
-		return "♻️ 小步快跑，每步可验证。重构前先跑通测试。分析代码语义"
-	case "DESIGN":
-		return "📐 先讨论方案，有必要再输出设计文档。不动代码"
-	case "RESEARCH":
-		return "🔍 可退一步全局思考，可复盘，可用顺序思考工具"
-	case "PERFORMANCE":
-		return "⚡ 先执行性能分析定位瓶颈 → 针对性优化 → 基准测试验证提升"
-	case "REFLECT":
-		return "🪞 系统性回顾历史决策。可用 system_recall 检索记忆，open_timeline 查看演进，基于事实得出结论"
-	default:
-		return "📋 自行决定最佳方案"
-	}
-}
-
 func wrapSaveFact(sm *SessionManager) server.ToolHandlerFunc {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		if sm.Memory == nil {
-			return mcp.NewToolResultError("记忆层尚未初始化，请先执行 initialize_project。"), nil
+			return mcp.NewToolResultError(i18n.T("记忆层尚未初始化，请先执行 initialize_project。")), nil
 		}
 
 		var args FactArgs
 		if err := request.BindArguments(&args); err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("参数格式错误: %v", err)), nil
+			return mcp.NewToolResultError(i18n.T("参数格式错误: %v", err)), nil
 		}
 
 		id, err := sm.Memory.SaveFact(ctx, args.Type, args.Summarize)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("保存事实失败: %v", err)), nil
+			return mcp.NewToolResultError(i18n.T("保存事实失败: %v", err)), nil
 		}
 
-		return mcp.NewToolResultText(fmt.Sprintf("✅ 事实已存入数据库 (ID: %d): [%s] %s", id, args.Type, args.Summarize)), nil
+		return mcp.NewToolResultStructured(map[string]interface{}{"id": id, "type": args.Type, "summary": args.Summarize},
+			i18n.T("✅ 事实已存入数据库 (ID: %d): [%s] %s", id, args.Type, args.Summarize)), nil
 	}
 }
@@ -0,0 +1,454 @@
+package tools
+
+import (
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mcp-server-go/internal/i18n"
+	"mcp-server-go/internal/services"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ============================================================================
+// project_map 机器可读格式与分页
+// 按文件路径排序后切页，文件不会被拆到两页；cursor 绑定地图指纹，索引变化后失效
+// ============================================================================
+
+const (
+	// MapSchemaVersion JSON 输出的结构版本
+	MapSchemaVersion = "project_map/v1"
+
+	defaultMapPageSize = 200 // 每页条目预算 (文件计 1 条，symbols 层每个符号再计 1 条)；单页上限见 mpm.yaml 的 map.max_page_size
+)
+
+// mapPage 一页地图的切片信息
+type mapPage struct {
+	Files      []string // 本页文件 (按路径排序)
+	Offset     int      // 本页首个文件在全部文件中的下标
+	TotalFiles int
+	Cursor     string // 本页的 cursor (首页为空)
+	NextCursor string // 下一页 cursor，已是最后一页时为空
+}
+
+// HasMore 是否还有下一页
+func (p *mapPage) HasMore() bool {
+	return p.NextCursor != ""
+}
+
+// paginateMap 按条目预算切出 cursor 指向的一页
+func paginateMap(result *services.MapResult, level, scope, cursor string, pageSize int) (*mapPage, error) {
+	if pageSize <= 0 {
+		pageSize = defaultMapPageSize
+	}
+
+	files := make([]string, 0, len(result.Structure))
+	for path := range result.Structure {
+		files = append(files, path)
+	}
+	sort.Strings(files)
+
+	fp := mapFingerprint(result, level, scope, files)
+	offset, err := decodeMapCursor(cursor, fp)
+	if err != nil {
+		return nil, err
+	}
+	if offset > len(files) {
+		return nil, errors.New(i18n.T("cursor 越界，请去掉 cursor 重新获取第一页"))
+	}
+
+	page := &mapPage{Offset: offset, TotalFiles: len(files), Cursor: cursor}
+	used := 0
+	for i := offset; i < len(files); i++ {
+		cost := 1
+		if level != "structure" {
+			cost += len(result.Structure[files[i]])
+		}
+		// 单个文件超出预算时仍独占一页，保证分页能前进
+		if used > 0 && used+cost > pageSize {
+			page.NextCursor = encodeMapCursor(i, fp)
+			break
+		}
+		page.Files = append(page.Files, files[i])
+		used += cost
+	}
+	return page, nil
+}
+
+// mapFingerprint 地图指纹：层级 + 范围 + 文件列表 + 每个文件的符号与行号范围，
+// 文件列表不变但重新索引后符号增减或移动时 cursor 同样失效
+func mapFingerprint(result *services.MapResult, level, scope string, files []string) string {
+	h := sha1.New()
+	h.Write([]byte(level + "\x00" + scope))
+	for _, f := range files {
+		nodes := result.Structure[f]
+		symbols := make([]string, 0, len(nodes))
+		for _, n := range nodes {
+			symbols = append(symbols, fmt.Sprintf("%s:%d-%d", n.Name, n.LineStart, n.LineEnd))
+		}
+		sort.Strings(symbols) // 与索引返回的符号顺序无关
+		fmt.Fprintf(h, "\x00%s\x00%d\x00%s", f, len(nodes), strings.Join(symbols, "\x00"))
+	}
+	return hex.EncodeToString(h.Sum(nil))[:12]
+}
+
+func encodeMapCursor(offset int, fp string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d|%s", offset, fp)))
+}
+
+// decodeMapCursor 解析 cursor，空 cursor 表示第一页
+func decodeMapCursor(cursor, fp string) (int, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, errors.New(i18n.T("cursor 无效，请使用上一页返回的 next_cursor"))
+	}
+	offsetStr, cursorFP, ok := strings.Cut(string(raw), "|")
+	offset, err := strconv.Atoi(offsetStr)
+	if !ok || err != nil || offset < 0 {
+		return 0, errors.New(i18n.T("cursor 无效，请使用上一页返回的 next_cursor"))
+	}
+	if cursorFP != fp {
+		return 0, errors.New(i18n.T("项目地图已变化 (索引更新或 level/scope 不同)，cursor 已失效，请去掉 cursor 重新获取第一页"))
+	}
+	return offset, nil
+}
+
+// pageRenderer 构造只包含本页文件的渲染器，统计信息保持全局
+func (mr *MapRenderer) pageRenderer(page *mapPage) *MapRenderer {
+	structure := make(map[string][]services.Node, len(page.Files))
+	for _, f := range page.Files {
+		structure[f] = mr.Result.Structure[f]
+	}
+	return &MapRenderer{
+		Result: &services.MapResult{
+			Statistics:    mr.Result.Statistics,
+			Structure:     structure,
+			Elapsed:       mr.Result.Elapsed,
+			ComplexityMap: mr.Result.ComplexityMap,
+			MetricsMap:    mr.Result.MetricsMap,
+		},
+		Root: mr.Root,
+	}
+}
+
+// complexityLevel 复杂度分级，阈值与 Markdown 的 HIGH/MED/LOW 一致
+func complexityLevel(score float64) string {
+	if score >= 50 {
+		return "high"
+	}
+	if score >= 20 {
+		return "medium"
+	}
+	return "low"
+}
+
+// mapDirOf 文件所在目录，根目录记为 "(root)"
+func mapDirOf(path string) string {
+	dir := strings.ReplaceAll(filepath.Dir(path), "\\", "/")
+	if dir == "." {
+		return "(root)"
+	}
+	return dir
+}
+
+// fileComplexity 文件内有复杂度数据的符号的总分与个数
+func (mr *MapRenderer) fileComplexity(nodes []services.Node) (float64, int) {
+	total, count := 0.0, 0
+	for _, n := range nodes {
+		if s, ok := mr.Result.ComplexityMap[n.Name]; ok {
+			total += s
+			count++
+		}
+	}
+	return total, count
+}
+
+// ----------------------------------------------------------------------------
+// Markdown 分页
+// ----------------------------------------------------------------------------
+
+// RenderPage 渲染一页完整符号视图 (分页时不再折叠，靠 cursor 翻页)
+func (mr *MapRenderer) RenderPage(page *mapPage) string {
+	var sb strings.Builder
+	stats := mr.Result.Statistics
+	end := page.Offset + len(page.Files)
+
+	sb.WriteString(i18n.T("### 🗺️ 项目地图 (Symbols) · 文件 %d-%d / %d\n\n", page.Offset+1, end, page.TotalFiles))
+	sb.WriteString(i18n.T("**📊 范围统计**: %d files | %d symbols\n", stats.TotalFiles, stats.TotalSymbols))
+	if page.Offset == 0 {
+		if summary := mr.complexitySummary(); summary != nil {
+			sb.WriteString(i18n.T("**🔥 复杂度**: High: %d | Med: %d | Low: %d | Avg: %.1f\n",
+				summary.High, summary.Medium, summary.Low, summary.Avg))
+		}
+	}
+
+	mr.pageRenderer(page).renderWithMode(&sb, "Full", true)
+
+	if page.HasMore() {
+		sb.WriteString(i18n.T("\n---\n➡️ 还有 %d 个文件，继续查看：`cursor=\"%s\"` (其余参数保持不变)\n",
+			page.TotalFiles-end, page.NextCursor))
+	}
+	return sb.String()
+}
+
+// ----------------------------------------------------------------------------
+// JSON
+// ----------------------------------------------------------------------------
+
+type mapComplexitySummary struct {
+	High   int     `json:"high"`
+	Medium int     `json:"medium"`
+	Low    int     `json:"low"`
+	Avg    float64 `json:"avg"`
+}
+
+type mapPageInfo struct {
+	Cursor     string `json:"cursor,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	Offset     int    `json:"offset"`
+	Files      int    `json:"files"`
+	TotalFiles int    `json:"total_files"`
+}
+
+type mapJSONSymbol struct {
+	Name       string                  `json:"name"`
+	Type       string                  `json:"type"`
+	LineStart  int                     `json:"line_start"`
+	LineEnd    int                     `json:"line_end"`
+	Signature  string                  `json:"signature,omitempty"`
+	Doc        string                  `json:"doc,omitempty"`
+	Complexity *float64                `json:"complexity,omitempty"`
+	Level      string                  `json:"level,omitempty"`
+	Metrics    *services.SymbolMetrics `json:"metrics,omitempty"`
+}
+
+type mapJSONFile struct {
+	Path          string          `json:"path"`
+	SymbolCount   int             `json:"symbol_count"`
+	AvgComplexity float64         `json:"avg_complexity"`
+	Symbols       []mapJSONSymbol `json:"symbols,omitempty"`
+}
+
+type mapJSONDir struct {
+	Path          string        `json:"path"`
+	AvgComplexity float64       `json:"avg_complexity"`
+	Files         []mapJSONFile `json:"files"`
+}
+
+type mapJSON struct {
+	Schema     string                `json:"schema"`
+	Level      string                `json:"level"`
+	Scope      string                `json:"scope,omitempty"`
+	Statistics services.Stats        `json:"statistics"`
+	Complexity *mapComplexitySummary `json:"complexity,omitempty"`
+	Page       mapPageInfo           `json:"page"`
+	Dirs       []mapJSONDir          `json:"dirs"`
+	Delta      *services.MetricDelta `json:"delta,omitempty"`
+	DeltaError string                `json:"delta_error,omitempty"`
+}
+
+// complexitySummary 全局复杂度分布，无复杂度数据时返回 nil
+func (mr *MapRenderer) complexitySummary() *mapComplexitySummary {
+	if len(mr.Result.ComplexityMap) == 0 {
+		return nil
+	}
+	s := &mapComplexitySummary{}
+	total := 0.0
+	for _, score := range mr.Result.ComplexityMap {
+		switch complexityLevel(score) {
+		case "high":
+			s.High++
+		case "medium":
+			s.Medium++
+		default:
+			s.Low++
+		}
+		total += score
+	}
+	s.Avg = round1(total / float64(len(mr.Result.ComplexityMap)))
+	return s
+}
+
+// buildJSON 组装一页 JSON 地图：目录按路径排序，文件按路径排序，符号按行号排序
+func (mr *MapRenderer) buildJSON(level, scope string, page *mapPage) *mapJSON {
+	out := &mapJSON{
+		Schema:     MapSchemaVersion,
+		Level:      level,
+		Scope:      scope,
+		Statistics: mr.Result.Statistics,
+		Complexity: mr.complexitySummary(),
+		Page: mapPageInfo{
+			Cursor:     page.Cursor,
+			NextCursor: page.NextCursor,
+			Offset:     page.Offset,
+			Files:      len(page.Files),
+			TotalFiles: page.TotalFiles,
+		},
+		Dirs: []mapJSONDir{},
+	}
+
+	dirIndex := make(map[string]int)
+	dirTotals := make(map[string][2]float64) // 总分, 个数
+	for _, path := range page.Files {
+		nodes := mr.Result.Structure[path]
+		total, count := mr.fileComplexity(nodes)
+
+		file := mapJSONFile{Path: path, SymbolCount: len(nodes)}
+		if count > 0 {
+			file.AvgComplexity = round1(total / float64(count))
+		}
+		if level != "structure" {
+			sorted := append([]services.Node(nil), nodes...)
+			sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].LineStart < sorted[j].LineStart })
+			for _, n := range sorted {
+				sym := mapJSONSymbol{
+					Name:      n.Name,
+					Type:      n.NodeType,
+					LineStart: n.LineStart,
+					LineEnd:   n.LineEnd,
+					Signature: n.Signature,
+					Doc:       n.Doc,
+				}
+				if score, ok := mr.Result.ComplexityMap[n.Name]; ok {
+					score = round1(score)
+					sym.Complexity = &score
+					sym.Level = complexityLevel(score)
+					if m, ok := mr.Result.MetricsMap[n.Name]; ok {
+						sym.Metrics = &m
+					}
+				}
+				file.Symbols = append(file.Symbols, sym)
+			}
+		}
+
+		dir := mapDirOf(path)
+		idx, ok := dirIndex[dir]
+		if !ok {
+			idx = len(out.Dirs)
+			dirIndex[dir] = idx
+			out.Dirs = append(out.Dirs, mapJSONDir{Path: dir})
+		}
+		out.Dirs[idx].Files = append(out.Dirs[idx].Files, file)
+		t := dirTotals[dir]
+		dirTotals[dir] = [2]float64{t[0] + total, t[1] + float64(count)}
+	}
+
+	for i := range out.Dirs {
+		if t := dirTotals[out.Dirs[i].Path]; t[1] > 0 {
+			out.Dirs[i].AvgComplexity = round1(t[0] / t[1])
+		}
+	}
+	sort.SliceStable(out.Dirs, func(i, j int) bool { return out.Dirs[i].Path < out.Dirs[j].Path })
+	return out
+}
+
+// JSONMap 一页结构化地图，delta 仅附加在第一页
+func (mr *MapRenderer) JSONMap(level, scope string, page *mapPage, delta *services.MetricDelta, deltaErr error) *mapJSON {
+	out := mr.buildJSON(level, scope, page)
+	if page.Offset == 0 {
+		out.Delta = delta
+		if deltaErr != nil {
+			out.DeltaError = deltaErr.Error()
+		}
+	}
+	return out
+}
+
+// RenderJSON 渲染一页 JSON 地图
+func (mr *MapRenderer) RenderJSON(level, scope string, page *mapPage, delta *services.MetricDelta, deltaErr error) (string, error) {
+	data, err := json.MarshalIndent(mr.JSONMap(level, scope, page, delta, deltaErr), "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// ----------------------------------------------------------------------------
+// Mermaid
+// ----------------------------------------------------------------------------
+
+// RenderMermaid 渲染一页 Mermaid flowchart：目录为 subgraph，文件为节点，
+// symbols 层的符号挂在所属文件下，按复杂度着色
+func (mr *MapRenderer) RenderMermaid(level string, page *mapPage) string {
+	var sb strings.Builder
+	sb.WriteString("flowchart LR\n")
+	sb.WriteString(fmt.Sprintf("%%%% %s level=%s files=%d-%d/%d\n",
+		MapSchemaVersion, level, page.Offset+1, page.Offset+len(page.Files), page.TotalFiles))
+	if page.HasMore() {
+		sb.WriteString(fmt.Sprintf("%%%% next_cursor: %s\n", page.NextCursor))
+	}
+	sb.WriteString("  classDef high fill:#f8d7da,stroke:#c0392b\n")
+	sb.WriteString("  classDef medium fill:#fff3cd,stroke:#d4a017\n")
+	sb.WriteString("  classDef low fill:#e8f5e9,stroke:#4caf50\n")
+
+	var dirs []string
+	byDir := make(map[string][]string)
+	for _, path := range page.Files {
+		dir := mapDirOf(path)
+		if _, ok := byDir[dir]; !ok {
+			dirs = append(dirs, dir)
+		}
+		byDir[dir] = append(byDir[dir], path)
+	}
+	sort.Strings(dirs)
+
+	var edges []string
+	classes := make(map[string][]string)
+	for di, dir := range dirs {
+		sb.WriteString(fmt.Sprintf("  subgraph d%d[\"%s/\"]\n", di, mermaidLabel(dir)))
+		for fi, path := range byDir[dir] {
+			nodes := mr.Result.Structure[path]
+			fileID := fmt.Sprintf("d%df%d", di, fi)
+			label := fmt.Sprintf("%s (%d)", filepath.Base(path), len(nodes))
+			if total, count := mr.fileComplexity(nodes); count > 0 {
+				avg := total / float64(count)
+				label += fmt.Sprintf(" Avg:%.1f", avg)
+				classes[complexityLevel(avg)] = append(classes[complexityLevel(avg)], fileID)
+			}
+			sb.WriteString(fmt.Sprintf("    %s[\"%s\"]\n", fileID, mermaidLabel(label)))
+
+			if level == "structure" {
+				continue
+			}
+			sorted := append([]services.Node(nil), nodes...)
+			sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].LineStart < sorted[j].LineStart })
+			for si, n := range sorted {
+				symID := fmt.Sprintf("%ss%d", fileID, si)
+				symLabel := fmt.Sprintf("%s L%d", n.Name, n.LineStart)
+				if score, ok := mr.Result.ComplexityMap[n.Name]; ok {
+					symLabel += " " + mr.getLevelTag(score)
+					classes[complexityLevel(score)] = append(classes[complexityLevel(score)], symID)
+				}
+				sb.WriteString(fmt.Sprintf("    %s(\"%s\")\n", symID, mermaidLabel(symLabel)))
+				edges = append(edges, fmt.Sprintf("  %s --> %s\n", fileID, symID))
+			}
+		}
+		sb.WriteString("  end\n")
+	}
+	for _, e := range edges {
+		sb.WriteString(e)
+	}
+	for _, cls := range []string{"high", "medium", "low"} {
+		if ids := classes[cls]; len(ids) > 0 {
+			sb.WriteString(fmt.Sprintf("  class %s %s\n", strings.Join(ids, ","), cls))
+		}
+	}
+	return sb.String()
+}
+
+// mermaidLabel 转义 Mermaid 标签中的特殊字符
+func mermaidLabel(s string) string {
+	return strings.NewReplacer(`"`, "#quot;", "<", "#lt;", ">", "#gt;", "\n", " ").Replace(s)
+}
+
+func round1(v float64) float64 {
+	return float64(int64(v*10+0.5)) / 10
+}
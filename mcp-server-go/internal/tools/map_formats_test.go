@@ -0,0 +1,176 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"mcp-server-go/internal/services"
+	"strings"
+	"testing"
+)
+
+func sampleMapResult(files int) *services.MapResult {
+	result := &services.MapResult{
+		Structure:     make(map[string][]services.Node),
+		ComplexityMap: map[string]float64{"Parse": 62.5, "Render": 25},
+		MetricsMap:    map[string]services.SymbolMetrics{"Parse": {Cyclomatic: 18, Cognitive: 20, LineCount: 90, MaxNesting: 4}},
+	}
+	for i := 0; i < files; i++ {
+		path := fmt.Sprintf("pkg/file%02d.go", i)
+		result.Structure[path] = []services.Node{
+			{Name: fmt.Sprintf("Helper%d", i), NodeType: "function", FilePath: path, LineStart: 10, LineEnd: 12},
+		}
+	}
+	result.Structure["core/parser.go"] = []services.Node{
+		{Name: "Render", NodeType: "function", FilePath: "core/parser.go", LineStart: 40, LineEnd: 60},
+		{Name: "Parse", NodeType: "function", FilePath: "core/parser.go", LineStart: 3, LineEnd: 30, Doc: "Parse 解析输入"},
+	}
+	result.Statistics = services.Stats{TotalFiles: files + 1, TotalSymbols: files + 2}
+	return result
+}
+
+func TestPaginateMapWalksAllFiles(t *testing.T) {
+	result := sampleMapResult(9)
+
+	var seen []string
+	cursor := ""
+	for pages := 0; ; pages++ {
+		if pages > 10 {
+			t.Fatal("pagination does not terminate")
+		}
+		page, err := paginateMap(result, "symbols", "", cursor, 5)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if page.Offset != len(seen) {
+			t.Fatalf("page offset = %d, want %d", page.Offset, len(seen))
+		}
+		seen = append(seen, page.Files...)
+		if !page.HasMore() {
+			break
+		}
+		cursor = page.NextCursor
+	}
+
+	if len(seen) != 10 || seen[0] != "core/parser.go" {
+		t.Fatalf("pages covered %v", seen)
+	}
+	for i := 1; i < len(seen); i++ {
+		if seen[i] <= seen[i-1] {
+			t.Fatalf("files out of order or repeated: %v", seen)
+		}
+	}
+}
+
+func TestPaginateMapRejectsStaleCursor(t *testing.T) {
+	result := sampleMapResult(9)
+	page, err := paginateMap(result, "symbols", "", "", 5)
+	if err != nil || !page.HasMore() {
+		t.Fatalf("expected multi-page map, got %+v, %v", page, err)
+	}
+
+	if _, err := paginateMap(result, "structure", "", page.NextCursor, 5); err == nil {
+		t.Fatal("cursor from another level should be rejected")
+	}
+	reindexed := sampleMapResult(9)
+	reindexed.Structure["core/parser.go"][1].LineEnd = 35
+	if _, err := paginateMap(reindexed, "symbols", "", page.NextCursor, 5); err == nil {
+		t.Fatal("cursor should expire after a re-index moves symbols")
+	}
+	reindexed.Structure["pkg/file03.go"] = append(reindexed.Structure["pkg/file03.go"], services.Node{Name: "Extra", FilePath: "pkg/file03.go"})
+	if _, err := paginateMap(reindexed, "symbols", "", page.NextCursor, 5); err == nil {
+		t.Fatal("cursor should expire after a re-index adds symbols")
+	}
+	result.Structure["pkg/new.go"] = nil
+	if _, err := paginateMap(result, "symbols", "", page.NextCursor, 5); err == nil {
+		t.Fatal("cursor should expire after the file list changes")
+	}
+	if _, err := paginateMap(result, "symbols", "", "not-a-cursor", 5); err == nil {
+		t.Fatal("garbage cursor should be rejected")
+	}
+}
+
+func TestRenderJSONMap(t *testing.T) {
+	result := sampleMapResult(2)
+	mr := NewMapRenderer(result, "")
+	page, err := paginateMap(result, "symbols", "", "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := mr.RenderJSON("symbols", "", page, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got mapJSON
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("invalid json: %v\n%s", err, out)
+	}
+	if got.Schema != MapSchemaVersion || got.Page.TotalFiles != 3 || got.Page.NextCursor != "" {
+		t.Fatalf("unexpected header: %+v", got)
+	}
+	if got.Complexity == nil || got.Complexity.High != 1 || got.Complexity.Medium != 1 {
+		t.Fatalf("complexity summary = %+v", got.Complexity)
+	}
+	if len(got.Dirs) != 2 || got.Dirs[0].Path != "core" {
+		t.Fatalf("dirs = %+v", got.Dirs)
+	}
+
+	parser := got.Dirs[0].Files[0]
+	if parser.AvgComplexity != 43.8 || len(parser.Symbols) != 2 {
+		t.Fatalf("parser file = %+v", parser)
+	}
+	parse := parser.Symbols[0] // 按行号排序
+	if parse.Name != "Parse" || parse.Level != "high" || parse.Metrics == nil || parse.Metrics.Cyclomatic != 18 || parse.Doc != "Parse 解析输入" {
+		t.Fatalf("parse symbol = %+v", parse)
+	}
+	if helper := got.Dirs[1].Files[0].Symbols[0]; helper.Complexity != nil || helper.Level != "" {
+		t.Fatalf("symbols without metrics should omit complexity: %+v", helper)
+	}
+
+	// structure 层只给符号数
+	page, _ = paginateMap(result, "structure", "", "", 0)
+	out, _ = mr.RenderJSON("structure", "", page, nil, nil)
+	if strings.Contains(out, `"symbols"`) || !strings.Contains(out, `"symbol_count": 2`) {
+		t.Fatalf("structure json should list counts only:\n%s", out)
+	}
+}
+
+func TestRenderMermaidMap(t *testing.T) {
+	result := sampleMapResult(9)
+	mr := NewMapRenderer(result, "")
+	page, err := paginateMap(result, "symbols", "", "", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := mr.RenderMermaid("symbols", page)
+
+	for _, want := range []string{
+		"flowchart LR",
+		"%% next_cursor: " + page.NextCursor,
+		`subgraph d0["core/"]`,
+		`d0f0["parser.go (2) Avg:43.8"]`,
+		`d0f0s0("Parse L3 [HIGH:62.5]")`,
+		"d0f0 --> d0f0s0",
+		"class d0f0s0 high",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("mermaid output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestRenderPageMarkdown(t *testing.T) {
+	result := sampleMapResult(9)
+	mr := NewMapRenderer(result, "")
+	page, err := paginateMap(result, "symbols", "", "", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := mr.RenderPage(page)
+	if !strings.Contains(out, "文件 1-") || !strings.Contains(out, page.NextCursor) || !strings.Contains(out, "`Parse` L3 [HIGH:62.5]") {
+		t.Fatalf("unexpected page:\n%s", out)
+	}
+	if strings.Contains(out, "file08.go") {
+		t.Fatalf("page leaked files from later pages:\n%s", out)
+	}
+}
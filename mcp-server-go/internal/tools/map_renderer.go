@@ -2,6 +2,7 @@ package tools
 
 import (
 	"fmt"
+	"mcp-server-go/internal/i18n"
 	"mcp-server-go/internal/services"
 	"path/filepath"
 	"sort"
@@ -36,8 +37,8 @@ func (mr *MapRenderer) RenderOverview() string {
 	var sb strings.Builder
 	stats := mr.Result.Statistics
 
-	sb.WriteString(fmt.Sprintf("### 🗺️ 项目地图 (Structure)\n\n"))
-	sb.WriteString(fmt.Sprintf("**📊 统计**: %d 文件 | %d 符号\n\n", stats.TotalFiles, stats.TotalSymbols))
+	sb.WriteString(i18n.T("### 🗺️ 项目地图 (Structure)\n\n"))
+	sb.WriteString(i18n.T("**📊 统计**: %d 文件 | %d 符号\n\n", stats.TotalFiles, stats.TotalSymbols))
 
 	// 1. 复杂度统计摘要
 	if mr.Result.ComplexityMap != nil && len(mr.Result.ComplexityMap) > 0 {
@@ -48,7 +49,7 @@ func (mr *MapRenderer) RenderOverview() string {
 	root := mr.buildDirTree()
 
 	// 3. 自适应展开渲染
-	sb.WriteString("**📁 目录结构** (按复杂度排序):\n")
+	sb.WriteString(i18n.T("**📁 目录结构** (按复杂度排序):\n"))
 	mr.renderAdaptive(&sb, root)
 
 	return sb.String()
@@ -77,7 +78,7 @@ func (mr *MapRenderer) renderComplexitySummary(sb *strings.Builder) {
 
 	avgScore := totalScore / float64(len(mr.Result.ComplexityMap))
 
-	sb.WriteString(fmt.Sprintf("**🔥 复杂度**: High: %d | Med: %d | Low: %d | Avg: %.1f\n\n",
+	sb.WriteString(i18n.T("**🔥 复杂度**: High: %d | Med: %d | Low: %d | Avg: %.1f\n\n",
 		highCount, medCount, lowCount, avgScore))
 
 	// Top 5
@@ -85,7 +86,7 @@ func (mr *MapRenderer) renderComplexitySummary(sb *strings.Builder) {
 		return topSymbols[i].Score > topSymbols[j].Score
 	})
 	if len(topSymbols) > 0 {
-		sb.WriteString("**🎯 Top复杂符号**:\n")
+		sb.WriteString(i18n.T("**🎯 Top复杂符号**:\n"))
 		limit := 5
 		if len(topSymbols) < limit {
 			limit = len(topSymbols)
@@ -93,7 +94,11 @@ func (mr *MapRenderer) renderComplexitySummary(sb *strings.Builder) {
 		for i := 0; i < limit; i++ {
 			s := topSymbols[i]
 			level := mr.getLevelTag(s.Score)
-			sb.WriteString(fmt.Sprintf("  %d. `%s` %s\n", i+1, s.Name, level))
+			detail := ""
+			if m, ok := mr.Result.MetricsMap[s.Name]; ok {
+				detail = i18n.T(" (CC:%d, Cog:%d, Nest:%d, %d行)", m.Cyclomatic, m.Cognitive, m.MaxNesting, m.LineCount)
+			}
+			sb.WriteString(fmt.Sprintf("  %d. `%s` %s%s\n", i+1, s.Name, level, detail))
 		}
 		sb.WriteString("\n")
 	}
@@ -227,7 +232,7 @@ func (mr *MapRenderer) renderAdaptive(sb *strings.Builder, root *DirNode) {
 	// 3. 渲染
 	for i, node := range level1 {
 		if i >= s.ShowLimit {
-			sb.WriteString(fmt.Sprintf("- ... (还有 %d 个低复杂度目录)\n", n-i))
+			sb.WriteString(i18n.T("- ... (还有 %d 个低复杂度目录)\n", n-i))
 			break
 		}
 
@@ -304,11 +309,11 @@ func (mr *MapRenderer) getLevelTag(score float64) string {
 // 策略：智能折叠，Top 10 详细展开
 func (mr *MapRenderer) RenderStandard() string {
 	var sb strings.Builder
-	sb.WriteString("### 🗺️ 项目地图 (Symbols)\n\n")
+	sb.WriteString(i18n.T("### 🗺️ 项目地图 (Symbols)\n\n"))
 
 	// 统计摘要
 	stats := mr.Result.Statistics
-	sb.WriteString(fmt.Sprintf("**📊 范围统计**: %d files | %d symbols\n", stats.TotalFiles, stats.TotalSymbols))
+	sb.WriteString(i18n.T("**📊 范围统计**: %d files | %d symbols\n", stats.TotalFiles, stats.TotalSymbols))
 
 	if mr.Result.ComplexityMap != nil {
 		var high, med, low int
@@ -326,7 +331,7 @@ func (mr *MapRenderer) RenderStandard() string {
 			count++
 		}
 		if count > 0 {
-			sb.WriteString(fmt.Sprintf("**🔥 复杂度**: High: %d | Med: %d | Low: %d | Avg: %.1f\n\n", high, med, low, total/float64(count)))
+			sb.WriteString(i18n.T("**🔥 复杂度**: High: %d | Med: %d | Low: %d | Avg: %.1f\n\n", high, med, low, total/float64(count)))
 		}
 	} else {
 		sb.WriteString("\n")
@@ -413,7 +418,7 @@ func (mr *MapRenderer) renderWithMode(sb *strings.Builder, mode string, truncate
 		for i, f := range files {
 			// 超出摘要限制：折叠
 			if i >= summaryLimit {
-				sb.WriteString(fmt.Sprintf("  - ... (还有 %d 个低复杂度文件)\n", len(files)-i))
+				sb.WriteString(i18n.T("  - ... (还有 %d 个低复杂度文件)\n", len(files)-i))
 				break
 			}
 
@@ -479,8 +484,18 @@ func (mr *MapRenderer) renderNode(sb *strings.Builder, node services.Node, inden
 			} else if score > 0 {
 				complexityMarker = fmt.Sprintf(" [LOW:%.1f]", score)
 			}
+			// 中高复杂度附带成因指标，便于判断是分支多、嵌套深还是过长
+			if m, ok := mr.Result.MetricsMap[node.Name]; ok && score >= 20 {
+				complexityMarker += fmt.Sprintf(" CC:%d Cog:%d Nest:%d %dL", m.Cyclomatic, m.Cognitive, m.MaxNesting, m.LineCount)
+			}
 		}
 	}
 
-	sb.WriteString(fmt.Sprintf("%s%s `%s` L%d%s\n", indent, icon, desc, node.LineStart, complexityMarker))
+	// 文档摘要：Standard 模式截断更短，保持地图紧凑
+	docLimit := 100
+	if truncate {
+		docLimit = 50
+	}
+
+	sb.WriteString(fmt.Sprintf("%s%s `%s` L%d%s%s\n", indent, icon, desc, node.LineStart, complexityMarker, docSuffix(node, docLimit)))
 }
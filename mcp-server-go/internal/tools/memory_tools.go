@@ -2,8 +2,8 @@ package tools
 
 import (
 	"context"
-	"fmt"
 	"mcp-server-go/internal/core"
+	"mcp-server-go/internal/i18n"
 	"strings"
 
 	"github.com/mark3labs/mcp-go/mcp"
@@ -24,13 +24,13 @@ type MemoItem struct {
 // MemoArgs 备忘录参数
 type MemoArgs struct {
 	Items []MemoItem `json:"items" jsonschema:"required,description=录入事项列表"`
-	Lang  string     `json:"lang" jsonschema:"enum=zh,enum=en,default=zh,description=当前用户对话的语言 (zh=中文, en=英文)"`
+	Lang  string     `json:"lang" jsonschema:"enum=zh,enum=en,description=当前用户对话的语言 (zh=中文, en=英文)；默认跟随界面语言"`
 }
 
 // RegisterMemoryTools 注册备忘与检索工具
 func RegisterMemoryTools(s *server.MCPServer, sm *SessionManager) {
-	s.AddTool(mcp.NewTool("memo",
-		mcp.WithDescription(`memo - 项目的"黑匣子" (如果不记，等于没做)
+	addTool(s, mcp.NewTool("memo",
+		mcp.WithDescription(i18n.Desc("memo", `memo - 项目的"黑匣子" (如果不记，等于没做)
 
 用途：
   【修改后必选】任何代码/文档修改后，严禁不留记录直接结束。
@@ -47,8 +47,8 @@ func RegisterMemoryTools(s *server.MCPServer, sm *SessionManager) {
     - path: 文件路径
     - content: 详细说明，解释"为什么这么改"而非只说"改了什么"
   
-  lang (可选，默认 zh): 
-    记录语言，建议始终使用中文
+  lang (可选，默认跟随界面语言): 
+    记录语言，影响自动补全的实体/行为名
 
 完整调用示例（JSON格式）：
   {
@@ -65,7 +65,7 @@ func RegisterMemoryTools(s *server.MCPServer, sm *SessionManager) {
   }
 
 触发词：
-  "mpm memo", "mpm 记录", "mpm 存档"`),
+  "mpm memo", "mpm 记录", "mpm 存档"`)),
 		mcp.WithInputSchema[MemoArgs](),
 	), wrapMemo(sm))
 
@@ -75,28 +75,26 @@ func RegisterMemoryTools(s *server.MCPServer, sm *SessionManager) {
 func wrapMemo(sm *SessionManager) server.ToolHandlerFunc {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		if sm.Memory == nil {
-			return mcp.NewToolResultError("记忆层尚未初始化，请先执行 initialize_project 任务。"), nil
+			return mcp.NewToolResultError(i18n.T("记忆层尚未初始化，请先执行 initialize_project 任务。")), nil
 		}
 		var args MemoArgs
 		if err := request.BindArguments(&args); err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("参数格式错误： %v", err)), nil
+			return mcp.NewToolResultError(i18n.T("参数格式错误: %v", err)), nil
 		}
 
-		// 根据语种判定本地化术语
-		txtSystem := "System"
-		txtInfo := "Info"
-		txtManual := "Manual Entry"
-
-		if args.Lang == "zh" {
-			txtSystem = "系统"
-			txtInfo = "信息"
-			txtManual = "手动录入"
+		// 根据语种判定本地化术语，未指定时跟随界面语言
+		lang := args.Lang
+		if lang == "" {
+			lang = i18n.Locale()
 		}
+		txtSystem := i18n.TL(lang, "系统")
+		txtInfo := i18n.TL(lang, "信息")
+		txtManual := i18n.TL(lang, "手动录入")
 
 		var memos []core.Memo
 		for _, item := range args.Items {
 			memo := core.Memo{
-				Category: fallback(item.Category, "开发"),
+				Category: fallback(item.Category, i18n.TL(lang, "开发")),
 				Path:     fallback(item.Path, "-"),
 				Content:  item.Content,
 			}
@@ -133,10 +131,11 @@ func wrapMemo(sm *SessionManager) server.ToolHandlerFunc {
 
 		ids, err := sm.Memory.AddMemos(ctx, memos)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("保存备忘录失败： %v", err)), nil
+			return mcp.NewToolResultError(i18n.T("保存备忘录失败： %v", err)), nil
 		}
 
-		return mcp.NewToolResultText(fmt.Sprintf("已成功录入 %d 条记录 (IDs: %v)。", len(ids), ids)), nil
+		return mcp.NewToolResultStructured(map[string]interface{}{"ids": ids},
+			i18n.T("已成功录入 %d 条记录 (IDs: %v)。", len(ids), ids)), nil
 	}
 }
 
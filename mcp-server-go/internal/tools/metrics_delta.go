@@ -0,0 +1,148 @@
+package tools
+
+import (
+	"fmt"
+	"mcp-server-go/internal/i18n"
+	"mcp-server-go/internal/services"
+	"strings"
+)
+
+// renderSnapshotList 渲染快照列表
+func renderSnapshotList(snapshots []services.MetricSnapshot) string {
+	var sb strings.Builder
+	sb.WriteString(i18n.T("### 📸 指标快照\n\n"))
+	if len(snapshots) == 0 {
+		sb.WriteString(i18n.T("暂无快照。每次索引 (initialize_project / project_map / code_impact) 会在指标变化时自动记录。\n"))
+		return sb.String()
+	}
+	sb.WriteString(i18n.T("| ID | 时间 | Git | 文件 | 符号 | 平均复杂度 | 热点 | 最近索引 |\n"))
+	sb.WriteString("|---|---|---|---|---|---|---|---|\n")
+	for _, s := range snapshots {
+		rev := s.GitRevision
+		if rev == "" {
+			rev = "-"
+		}
+		lastRun := "-"
+		if s.Runs > 1 {
+			lastRun = i18n.T("%s @ `%s` (共 %d 次)", s.LastRunAt, s.LastGitRevision, s.Runs)
+		}
+		sb.WriteString(fmt.Sprintf("| #%d | %s | `%s` | %d | %d | %.1f | %d | %s |\n",
+			s.ID, s.CreatedAt, rev, s.TotalFiles, s.TotalSymbols, s.AvgScore, s.Hotspots, lastRun))
+	}
+	return sb.String()
+}
+
+// renderMetricDelta 渲染两个快照间的差异，每类最多 limit 条
+func renderMetricDelta(d *services.MetricDelta, limit int) string {
+	var sb strings.Builder
+
+	snapLabel := func(s services.MetricSnapshot) string {
+		if s.GitRevision != "" {
+			return fmt.Sprintf("#%d (%s @ `%s`)", s.ID, s.CreatedAt, s.GitRevision)
+		}
+		return fmt.Sprintf("#%d (%s)", s.ID, s.CreatedAt)
+	}
+
+	sb.WriteString(i18n.T("### 📈 复杂度趋势\n\n"))
+	sb.WriteString(i18n.T("**对比**: %s → %s\n", snapLabel(d.From), snapLabel(d.To)))
+	if d.Scope != "" {
+		sb.WriteString(i18n.T("**范围**: `%s`\n", d.Scope))
+	}
+	sb.WriteString(i18n.T("**概览**: +%d 新增 | -%d 删除 | ↑%d 变复杂 | ↓%d 变简单 | 🔥%d 新热点\n\n",
+		len(d.Added), len(d.Removed), len(d.Rises), len(d.Falls), len(d.NewHotspots)))
+
+	if len(d.Added)+len(d.Removed)+len(d.Rises)+len(d.Falls) == 0 {
+		sb.WriteString(i18n.T("✅ 两个快照之间无复杂度变化\n"))
+		return sb.String()
+	}
+
+	more := func(total int) {
+		if total > limit {
+			sb.WriteString(i18n.T("- ... 还有 %d 个\n", total-limit))
+		}
+	}
+	symLine := func(s services.SnapshotSymbol) string {
+		return fmt.Sprintf("`%s` @ %s [%.1f] CC:%d Nest:%d %dL",
+			s.Name, s.FilePath, s.Score, s.Metrics.Cyclomatic, s.Metrics.MaxNesting, s.Metrics.LineCount)
+	}
+
+	if len(d.NewHotspots) > 0 {
+		sb.WriteString(i18n.T("#### 🔥 新热点 (复杂度 ≥ 50)\n"))
+		for i, s := range d.NewHotspots {
+			if i >= limit {
+				break
+			}
+			sb.WriteString("- " + symLine(s) + "\n")
+		}
+		more(len(d.NewHotspots))
+		sb.WriteString("\n")
+	}
+
+	if len(d.Rises) > 0 {
+		sb.WriteString(i18n.T("#### ↑ 复杂度上升\n"))
+		for i, c := range d.Rises {
+			if i >= limit {
+				break
+			}
+			sb.WriteString(fmt.Sprintf("- `%s` @ %s: %.1f → %.1f (+%.1f)\n",
+				c.Symbol.Name, c.Symbol.FilePath, c.Before, c.After, c.Delta))
+		}
+		more(len(d.Rises))
+		sb.WriteString("\n")
+	}
+
+	if len(d.Falls) > 0 {
+		sb.WriteString(i18n.T("#### ↓ 复杂度下降\n"))
+		for i, c := range d.Falls {
+			if i >= limit {
+				break
+			}
+			sb.WriteString(fmt.Sprintf("- `%s` @ %s: %.1f → %.1f (%.1f)\n",
+				c.Symbol.Name, c.Symbol.FilePath, c.Before, c.After, c.Delta))
+		}
+		more(len(d.Falls))
+		sb.WriteString("\n")
+	}
+
+	if len(d.Added) > 0 {
+		sb.WriteString(i18n.T("#### ➕ 新增符号\n"))
+		for i, s := range d.Added {
+			if i >= limit {
+				break
+			}
+			sb.WriteString("- " + symLine(s) + "\n")
+		}
+		more(len(d.Added))
+		sb.WriteString("\n")
+	}
+
+	if len(d.Removed) > 0 {
+		sb.WriteString(i18n.T("#### ➖ 删除符号\n"))
+		for i, s := range d.Removed {
+			if i >= limit {
+				break
+			}
+			sb.WriteString(fmt.Sprintf("- `%s` @ %s [%.1f]\n", s.Name, s.FilePath, s.Score))
+		}
+		more(len(d.Removed))
+		sb.WriteString("\n")
+	}
+
+	if len(d.Dirs) > 0 {
+		sb.WriteString(i18n.T("#### 📁 目录变化\n"))
+		for i, c := range d.Dirs {
+			if i >= limit {
+				break
+			}
+			hot := ""
+			if c.HotspotDelta != 0 {
+				hot = i18n.T(", 热点 %+d", c.HotspotDelta)
+			}
+			sb.WriteString(i18n.T("- **%s/**: Avg %.1f → %.1f, 符号 %+d%s\n",
+				c.Dir, c.AvgBefore, c.AvgAfter, c.SymbolsDelta, hot))
+		}
+		more(len(d.Dirs))
+	}
+
+	return sb.String()
+}
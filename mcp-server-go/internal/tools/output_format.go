@@ -0,0 +1,164 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mcp-server-go/internal/i18n"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	// ToolOutputSchema output_format=json 时结果的 schema 标识，字段有不兼容变化时升级版本号
+	ToolOutputSchema = "mpm.tool/v1"
+
+	outputFormatArg      = "output_format"
+	outputFormatMarkdown = "markdown"
+	outputFormatJSON     = "json"
+)
+
+// ToolOutput output_format=json 时所有工具共用的结果外壳。
+// 成功时 data 为工具的结构化结果 (每个工具都提供)；handler 未附加结构化结果时退回 text (即 Markdown 原文)。
+// 失败时 ok=false，error.message 为错误信息。
+type ToolOutput struct {
+	Schema string           `json:"schema"`
+	Tool   string           `json:"tool"`
+	OK     bool             `json:"ok"`
+	Data   any              `json:"data,omitempty"`
+	Text   string           `json:"text,omitempty"`
+	Error  *ToolOutputError `json:"error,omitempty"`
+}
+
+// ToolOutputError 失败结果
+type ToolOutputError struct {
+	Message string `json:"message"`
+}
+
+// addTool 注册工具，统一附加 output_format 参数与 JSON 外壳
+func addTool(s *server.MCPServer, tool mcp.Tool, handler server.ToolHandlerFunc) {
+	s.AddTool(withOutputFormatArg(tool), withOutputFormat(tool.Name, handler))
+}
+
+// outputFormatProperty output_format 参数的 JSON Schema
+func outputFormatProperty() map[string]any {
+	return map[string]any{
+		"type":        "string",
+		"enum":        []string{outputFormatMarkdown, outputFormatJSON},
+		"default":     outputFormatMarkdown,
+		"description": i18n.T("输出格式：markdown=给人读的文本；json=结构化结果 (schema=%s，成功与失败结构一致)", ToolOutputSchema),
+	}
+}
+
+// withOutputFormatArg 在工具的输入 schema 中加入 output_format
+func withOutputFormatArg(tool mcp.Tool) mcp.Tool {
+	if len(tool.RawInputSchema) == 0 {
+		if tool.InputSchema.Type == "" {
+			tool.InputSchema.Type = "object"
+		}
+		props := make(map[string]any, len(tool.InputSchema.Properties)+1)
+		for k, v := range tool.InputSchema.Properties {
+			props[k] = v
+		}
+		props[outputFormatArg] = outputFormatProperty()
+		tool.InputSchema.Properties = props
+		return tool
+	}
+
+	var schema map[string]any
+	if err := json.Unmarshal(tool.RawInputSchema, &schema); err != nil {
+		return tool
+	}
+	props, _ := schema["properties"].(map[string]any)
+	if props == nil {
+		props = make(map[string]any)
+	}
+	props[outputFormatArg] = outputFormatProperty()
+	schema["properties"] = props
+	if data, err := json.Marshal(schema); err == nil {
+		tool.RawInputSchema = data
+	}
+	return tool
+}
+
+// wantsJSON 请求是否要求 output_format=json (供需要调整内部格式的工具使用)
+func wantsJSON(request mcp.CallToolRequest) bool {
+	return strings.EqualFold(request.GetString(outputFormatArg, ""), outputFormatJSON)
+}
+
+// withOutputFormat 按 output_format 包装工具结果：
+// markdown 保持原样 (去掉结构化附件，避免重复输出)；json 统一为 ToolOutput。
+func withOutputFormat(name string, handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		format := strings.ToLower(request.GetString(outputFormatArg, outputFormatMarkdown))
+		switch format {
+		case "", outputFormatMarkdown:
+			res, err := handler(ctx, request)
+			if res != nil {
+				res.StructuredContent = nil
+			}
+			return res, err
+		case outputFormatJSON:
+		default:
+			return mcp.NewToolResultError(i18n.T("不支持的 output_format: %s (可选 markdown/json)", format)), nil
+		}
+
+		res, err := handler(ctx, request)
+		return toolOutputResult(buildToolOutput(name, res, err)), nil
+	}
+}
+
+// buildToolOutput 把工具返回值转换为统一外壳；handler 返回的 Go error 与 IsError 结果同样视为失败
+func buildToolOutput(name string, res *mcp.CallToolResult, err error) *ToolOutput {
+	out := &ToolOutput{Schema: ToolOutputSchema, Tool: name}
+	if err != nil {
+		out.Error = &ToolOutputError{Message: err.Error()}
+		return out
+	}
+	if res == nil {
+		out.OK = true
+		return out
+	}
+
+	text := resultText(res)
+	if res.IsError {
+		out.Error = &ToolOutputError{Message: text}
+		return out
+	}
+	out.OK = true
+	if res.StructuredContent != nil {
+		out.Data = res.StructuredContent
+	} else {
+		out.Text = text
+	}
+	return out
+}
+
+// resultText 拼接结果中的全部文本内容
+func resultText(res *mcp.CallToolResult) string {
+	var parts []string
+	for _, c := range res.Content {
+		if tc, ok := c.(mcp.TextContent); ok {
+			parts = append(parts, tc.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// toolOutputResult 序列化外壳：文本内容为 JSON 本身，同时作为 structuredContent 返回
+func toolOutputResult(out *ToolOutput) *mcp.CallToolResult {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(out); err != nil {
+		out = &ToolOutput{Schema: ToolOutputSchema, Tool: out.Tool, Error: &ToolOutputError{Message: i18n.T("JSON 序列化失败: %v", err)}}
+		buf.Reset()
+		_ = enc.Encode(out)
+	}
+	res := mcp.NewToolResultStructured(out, strings.TrimRight(buf.String(), "\n"))
+	res.IsError = !out.OK
+	return res
+}
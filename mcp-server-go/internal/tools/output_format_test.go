@@ -0,0 +1,344 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"mcp-server-go/internal/core"
+	"mcp-server-go/internal/services"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestEveryToolAcceptsOutputFormat(t *testing.T) {
+	s := server.NewMCPServer("test", "0.0.0")
+	sm := &SessionManager{}
+	ai := services.NewASTIndexer()
+	RegisterSystemTools(s, sm, ai)
+	RegisterMemoryTools(s, sm)
+	RegisterSearchTools(s, sm, ai)
+	RegisterIntelligenceTools(s, sm, ai)
+	RegisterAnalysisTools(s, sm, ai)
+	RegisterSkillTools(s, sm)
+	RegisterTaskTools(s, sm)
+	RegisterEnhanceTools(s, sm)
+	RegisterDocTools(s, sm, ai)
+
+	tools := s.ListTools()
+	if len(tools) < 29 {
+		t.Fatalf("expected all tools to be registered, got %d", len(tools))
+	}
+	for name, st := range tools {
+		props := st.Tool.InputSchema.Properties
+		if len(st.Tool.RawInputSchema) > 0 {
+			var schema struct {
+				Properties map[string]any `json:"properties"`
+			}
+			if err := json.Unmarshal(st.Tool.RawInputSchema, &schema); err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			props = schema.Properties
+			if len(props) < 2 && name != "skill_list" && name != "open_timeline" {
+				t.Errorf("%s: original properties were lost: %v", name, props)
+			}
+		}
+		if _, ok := props[outputFormatArg]; !ok {
+			t.Errorf("%s: missing output_format in input schema", name)
+		}
+	}
+}
+
+func callWithFormat(t *testing.T, name string, h server.ToolHandlerFunc, args map[string]any) (*mcp.CallToolResult, *ToolOutput) {
+	t.Helper()
+	res, err := withOutputFormat(name, h)(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{Name: name, Arguments: args}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if args[outputFormatArg] != outputFormatJSON {
+		return res, nil
+	}
+	var out ToolOutput
+	if err := json.Unmarshal([]byte(getTextResult(t, res)), &out); err != nil {
+		t.Fatalf("json output is not valid JSON: %v\n%s", err, getTextResult(t, res))
+	}
+	if out.Schema != ToolOutputSchema || out.Tool != name || res.IsError == out.OK {
+		t.Fatalf("inconsistent envelope: %+v (IsError=%v)", out, res.IsError)
+	}
+	return res, &out
+}
+
+func TestOutputFormatEnvelope(t *testing.T) {
+	t.Setenv("MPM_HOME", t.TempDir())
+	sm := &SessionManager{ProjectRoot: t.TempDir()}
+
+	// 结构化结果放在 data
+	_, out := callWithFormat(t, "config", wrapConfig(sm), map[string]any{outputFormatArg: "json"})
+	data, _ := out.Data.(map[string]any)
+	if !out.OK || data == nil || data["config"] == nil || out.Text != "" {
+		t.Fatalf("config should return structured data: %+v", out)
+	}
+
+	// 工具错误统一为 ok=false + error.message
+	_, out = callWithFormat(t, "code_impact", wrapImpact(&SessionManager{}, nil), map[string]any{outputFormatArg: "json", "symbol_name": "x"})
+	if out.OK || out.Error == nil || !strings.Contains(out.Error.Message, "initialize_project") || out.Data != nil {
+		t.Fatalf("errors should use the error shape: %+v", out)
+	}
+
+	// handler 返回的 Go error 同样归为失败
+	failing := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return nil, fmt.Errorf("boom")
+	}
+	if _, out = callWithFormat(t, "x", failing, map[string]any{outputFormatArg: "json"}); out.Error == nil || out.Error.Message != "boom" {
+		t.Fatalf("go errors should be wrapped: %+v", out)
+	}
+
+	// 没有结构化结果的工具给出 text
+	textOnly := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("## 标题 <b>"), nil
+	}
+	res, out := callWithFormat(t, "y", textOnly, map[string]any{outputFormatArg: "json"})
+	if !out.OK || out.Text != "## 标题 <b>" || out.Data != nil || !strings.Contains(getTextResult(t, res), "<b>") {
+		t.Fatalf("text-only tools should fill text: %+v", out)
+	}
+
+	// markdown 保持原样，不附带结构化内容
+	res, _ = callWithFormat(t, "config", wrapConfig(sm), map[string]any{})
+	if res.StructuredContent != nil || !strings.Contains(getTextResult(t, res), "### ⚙️ 项目配置") {
+		t.Fatalf("markdown output should be unchanged")
+	}
+
+	res, _ = callWithFormat(t, "config", wrapConfig(sm), map[string]any{outputFormatArg: "xml"})
+	if !res.IsError {
+		t.Fatalf("unknown output_format should be rejected")
+	}
+}
+
+func TestImpactDataJSON(t *testing.T) {
+	r := &services.ImpactResult{RiskLevel: "high", ComplexityScore: 12.345, CouplingScore: 3}
+	for i := 0; i < 12; i++ {
+		r.DirectCallers = append(r.DirectCallers, services.CallerInfo{Node: services.Node{Name: fmt.Sprintf("d%d", i), FilePath: "a.go", LineStart: i + 1}})
+	}
+	for i := 0; i < 25; i++ {
+		r.IndirectCallers = append(r.IndirectCallers, services.CallerInfo{Node: services.Node{Name: fmt.Sprintf(`i"%d`, i)}})
+	}
+
+	data := newImpactData("Login", "backward", r)
+	if len(data.Callers) != 30 || len(data.DirectCallers) != 12 || len(data.IndirectCallers) != 20 {
+		t.Fatalf("unexpected limits: %d callers, %d direct, %d indirect", len(data.Callers), len(data.DirectCallers), len(data.IndirectCallers))
+	}
+
+	brief, err := json.Marshal(data.impactBrief)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var back impactBrief
+	if err := json.Unmarshal(brief, &back); err != nil {
+		t.Fatalf("brief must stay valid JSON even with quotes in names: %v\n%s", err, brief)
+	}
+	if back.Complexity != 12.3 || back.DirectCount != 12 || back.IndirectCount != 25 || back.Callers[10] != `i"0` {
+		t.Fatalf("unexpected brief: %+v", back)
+	}
+}
+
+func TestEveryToolReturnsData(t *testing.T) {
+	t.Setenv("MPM_HOME", t.TempDir())
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "main.go"), []byte("package main\n\n// Login 登录\nfunc Login() {}\n\nfunc main() { Login() }\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mem, err := core.NewMemoryLayer(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sm := &SessionManager{Memory: mem, ProjectRoot: root, TaskChains: make(map[string]*TaskChain), TaskChainsV2: make(map[string]*TaskChainV2)}
+	s := server.NewMCPServer("test", "0.0.0")
+	ai := services.NewASTIndexer()
+	RegisterSystemTools(s, sm, ai)
+	RegisterMemoryTools(s, sm)
+	RegisterSearchTools(s, sm, ai)
+	RegisterIntelligenceTools(s, sm, ai)
+	RegisterAnalysisTools(s, sm, ai)
+	RegisterSkillTools(s, sm)
+	RegisterTaskTools(s, sm)
+	RegisterEnhanceTools(s, sm)
+	RegisterDocTools(s, sm, ai)
+
+	// 会启动外部进程或重建会话的工具不在这里调用
+	skipped := map[string]bool{"open_timeline": true, "skill_run": true, "initialize_project": true}
+	needsIndexer := map[string]bool{"code_impact": true, "project_map": true, "refactor_preview": true}
+	calls := []struct {
+		tool string
+		args map[string]any
+	}{
+		{"config", nil},
+		{"memo", map[string]any{"items": []any{map[string]any{"category": "开发", "entity": "main.go", "act": "新增", "path": "main.go", "content": "Login"}}}},
+		{"known_facts", map[string]any{"type": "铁律", "summarize": "Login 必须幂等"}},
+		{"system_recall", map[string]any{"keywords": "Login"}},
+		{"manager_create_hook", map[string]any{"description": "补测试"}},
+		{"manager_list_hooks", nil},
+		{"manager_release_hook", map[string]any{"hook_id": "#001"}},
+		{"manager_analyze", map[string]any{"task_description": "修复 Login 的 bug", "intent": "DEBUG"}},
+		{"manager_list_briefings", nil},
+		{"task_chain", map[string]any{"mode": "step", "task_id": "t1", "description": "demo", "plan": []any{map[string]any{"name": "一"}}}},
+		{"task_chain", map[string]any{"mode": "insert", "task_id": "t1", "after": 1, "insert_plan": []any{map[string]any{"name": "二"}}}},
+		{"task_chain", map[string]any{"mode": "complete", "task_id": "t1", "step_number": 1, "summary": "完成"}},
+		{"task_chain", map[string]any{"mode": "delete", "task_id": "t1", "step_number": 1.1}},
+		{"task_chain", map[string]any{"mode": "continue"}},
+		{"task_chain", map[string]any{"mode": "finish", "task_id": "t1"}},
+		{"manager_constraints", map[string]any{"mode": "list"}},
+		{"check_edit", map[string]any{"paths": []any{"main.go"}}},
+		{"code_search", map[string]any{"query": "Login"}},
+		{"code_grep", map[string]any{"pattern": "Login"}},
+		{"code_impact", map[string]any{"symbol_name": "Login"}},
+		{"project_map", nil},
+		{"metrics_delta", map[string]any{"mode": "list"}},
+		{"structure_diff", map[string]any{"mode": "list"}},
+		{"refactor_preview", map[string]any{"symbol_name": "Login", "new_name": "SignIn"}},
+		{"prompt_enhance", map[string]any{"task_description": "修复 Login 的 bug"}},
+		{"persona", map[string]any{"mode": "list"}},
+		{"skill_list", nil},
+		{"skill_search", map[string]any{"query": "Login"}},
+		{"skill_load", map[string]any{"name": "missing"}},
+		{"skill_manage", map[string]any{"mode": "versions"}},
+		{"wiki_writer", map[string]any{"mode": "styles"}},
+	}
+
+	tools := s.ListTools()
+	covered := make(map[string]bool)
+	for _, c := range calls {
+		st := tools[c.tool]
+		if st == nil {
+			t.Fatalf("%s is not registered", c.tool)
+		}
+		covered[c.tool] = true
+		args := map[string]any{outputFormatArg: "json"}
+		for k, v := range c.args {
+			args[k] = v
+		}
+		res, err := st.Handler(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{Name: c.tool, Arguments: args}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var out ToolOutput
+		if err := json.Unmarshal([]byte(getTextResult(t, res)), &out); err != nil {
+			t.Fatalf("%s: %v", c.tool, err)
+		}
+		if !out.OK && needsIndexer[c.tool] {
+			t.Logf("%s skipped: %s", c.tool, out.Error.Message) // 沙箱中可能没有 ast_indexer
+			continue
+		}
+		if !out.OK {
+			t.Errorf("%s failed: %s", c.tool, out.Error.Message)
+			continue
+		}
+		if out.Data == nil || out.Text != "" {
+			t.Errorf("%s returned no structured data: %+v", c.tool, out)
+		}
+	}
+	for name := range tools {
+		if !covered[name] && !skipped[name] {
+			t.Errorf("%s is not covered by this test", name)
+		}
+	}
+}
+
+// TestToolResultsAreStructured 成功结果必须带结构化数据，不能落到 buildToolOutput 的 text 兜底：
+// mcp.NewToolResultText 只允许出现在 task_chain 的分派函数中 (wrapTaskChain 统一附加任务链状态)，
+// 直接构造的 mcp.CallToolResult 必须设置 StructuredContent
+func TestToolResultsAreStructured(t *testing.T) {
+	fset := token.NewFileSet()
+	funcs := make(map[string]*ast.FuncDecl)
+	files, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range files {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, decl := range f.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil {
+				funcs[fn.Name.Name] = fn
+			}
+		}
+	}
+
+	// 包内被引用过的函数；从未被引用的函数不会出现在任何工具的调用路径上
+	referenced := make(map[string]bool)
+	for _, fn := range funcs {
+		ast.Inspect(fn.Body, func(n ast.Node) bool {
+			if ident, ok := n.(*ast.Ident); ok && funcs[ident.Name] != nil && ident.Name != fn.Name.Name {
+				referenced[ident.Name] = true
+			}
+			return true
+		})
+	}
+
+	// dispatchTaskChain 及其 return 语句直接返回的包内函数 (递归)
+	wrapped := make(map[string]bool)
+	var walk func(name string)
+	walk = func(name string) {
+		fn := funcs[name]
+		if fn == nil || wrapped[name] {
+			return
+		}
+		wrapped[name] = true
+		ast.Inspect(fn.Body, func(n ast.Node) bool {
+			if ret, ok := n.(*ast.ReturnStmt); ok && len(ret.Results) > 0 {
+				if call, ok := ret.Results[0].(*ast.CallExpr); ok {
+					if ident, ok := call.Fun.(*ast.Ident); ok {
+						walk(ident.Name)
+					}
+				}
+			}
+			return true
+		})
+	}
+	walk("dispatchTaskChain")
+	if len(wrapped) < 5 {
+		t.Fatalf("task_chain dispatch not found: %v", wrapped)
+	}
+
+	for name, fn := range funcs {
+		if !referenced[name] {
+			continue
+		}
+		ast.Inspect(fn, func(n ast.Node) bool {
+			switch x := n.(type) {
+			case *ast.CallExpr:
+				if sel, ok := x.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == "NewToolResultText" && !wrapped[name] {
+					t.Errorf("%s: %s returns a text-only result; use mcp.NewToolResultStructured", fset.Position(x.Pos()), name)
+				}
+			case *ast.CompositeLit:
+				sel, ok := x.Type.(*ast.SelectorExpr)
+				if !ok || sel.Sel.Name != "CallToolResult" {
+					return true
+				}
+				structured := false
+				for _, elt := range x.Elts {
+					if kv, ok := elt.(*ast.KeyValueExpr); ok {
+						if key, ok := kv.Key.(*ast.Ident); ok && key.Name == "StructuredContent" {
+							structured = true
+						}
+					}
+				}
+				if !structured {
+					t.Errorf("%s: %s builds a CallToolResult without StructuredContent", fset.Position(x.Pos()), name)
+				}
+			}
+			return true
+		})
+	}
+}
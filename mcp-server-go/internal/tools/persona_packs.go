@@ -0,0 +1,360 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"mcp-server-go/internal/i18n"
+	"mcp-server-go/internal/services"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// activePersonaStateKey 当前激活的人格 (system_state)，initialize_project 时重新注入
+	activePersonaStateKey = "active_persona"
+	// personaPackVersion 人格包格式版本，导入时拒绝更高版本
+	personaPackVersion = 1
+	// maxPersonaInheritDepth 继承链最大深度
+	maxPersonaInheritDepth = 8
+
+	personaStyleReplace = "replace"
+	personaStyleAppend  = "append"
+)
+
+// PersonaPack 人格包：导入/导出的文件格式 (兼容 personas.json)
+type PersonaPack struct {
+	Version    int           `json:"version"`
+	ExportedAt string        `json:"exported_at,omitempty"`
+	Personas   []PersonaData `json:"personas"`
+}
+
+// PersonaActivation 自动激活的结果
+type PersonaActivation struct {
+	Name        string `json:"persona_name"`
+	DisplayName string `json:"persona_display"`
+	Trigger     string `json:"trigger,omitempty"`
+	Instruction string `json:"llm_instruction"`
+}
+
+// resolvePersona 沿 extends 链合并出最终人格：
+// 子人格的非空字段覆盖父人格；风格列表默认整体替换，style_merge=append 时追加在父列表之后。
+// 名称、显示名、别名、触发词属于人格身份，不继承。
+func resolvePersona(library *PersonaLibrary, key string) (PersonaData, error) {
+	idx := findPersonaIndex(library, key)
+	if idx < 0 {
+		return PersonaData{}, errors.New(i18n.T("未找到人格: %s", key))
+	}
+	chain, err := personaInheritChain(library, idx)
+	if err != nil {
+		return PersonaData{}, err
+	}
+
+	resolved := library.Personas[chain[len(chain)-1]]
+	for i := len(chain) - 2; i >= 0; i-- {
+		child := library.Personas[chain[i]]
+		if strings.TrimSpace(child.Avatar) != "" {
+			resolved.Avatar = child.Avatar
+		}
+		if strings.TrimSpace(child.HardDirective) != "" {
+			resolved.HardDirective = child.HardDirective
+		}
+		appendStyle := normalizePersonaKey(child.StyleMerge) == personaStyleAppend
+		resolved.StyleMust = mergePersonaStyle(resolved.StyleMust, child.StyleMust, appendStyle)
+		resolved.StyleSignature = mergePersonaStyle(resolved.StyleSignature, child.StyleSignature, appendStyle)
+		resolved.StyleTaboo = mergePersonaStyle(resolved.StyleTaboo, child.StyleTaboo, appendStyle)
+		resolved.Name = child.Name
+		resolved.DisplayName = child.DisplayName
+		resolved.Aliases = child.Aliases
+		resolved.Triggers = child.Triggers
+		resolved.Extends = child.Extends
+		resolved.StyleMerge = child.StyleMerge
+	}
+	return resolved, nil
+}
+
+func mergePersonaStyle(base, child []string, appendStyle bool) []string {
+	if len(child) == 0 {
+		return base
+	}
+	if !appendStyle {
+		return child
+	}
+	merged := append([]string{}, base...)
+	for _, s := range child {
+		dup := false
+		for _, b := range base {
+			if b == s {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			merged = append(merged, s)
+		}
+	}
+	return merged
+}
+
+// personaInheritChain 返回 [自身, 父, 祖父...] 的下标；父人格缺失或出现循环时报错
+func personaInheritChain(library *PersonaLibrary, idx int) ([]int, error) {
+	chain := []int{idx}
+	visited := map[int]bool{idx: true}
+	for cur := idx; strings.TrimSpace(library.Personas[cur].Extends) != ""; {
+		parent := library.Personas[cur].Extends
+		next := findPersonaIndex(library, parent)
+		if next < 0 {
+			return nil, errors.New(i18n.T("人格 %s 继承的 %s 不存在", library.Personas[cur].Name, parent))
+		}
+		if visited[next] {
+			return nil, errors.New(i18n.T("人格 %s 的继承链存在循环 (%s)", library.Personas[idx].Name, parent))
+		}
+		if len(chain) >= maxPersonaInheritDepth {
+			return nil, errors.New(i18n.T("人格 %s 的继承链超过 %d 层", library.Personas[idx].Name, maxPersonaInheritDepth))
+		}
+		visited[next] = true
+		chain = append(chain, next)
+		cur = next
+	}
+	return chain, nil
+}
+
+// validatePersonaLibrary 检查每个人格的继承链与 style_merge 取值
+func validatePersonaLibrary(library *PersonaLibrary) error {
+	for i, p := range library.Personas {
+		if strings.TrimSpace(p.Name) == "" {
+			return errors.New(i18n.T("第 %d 个人格缺少 name", i+1))
+		}
+		switch normalizePersonaKey(p.StyleMerge) {
+		case "", personaStyleReplace, personaStyleAppend:
+		default:
+			return errors.New(i18n.T("人格 %s 的 style_merge 无效: %s (可选 replace/append)", p.Name, p.StyleMerge))
+		}
+		if _, err := personaInheritChain(library, i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// personaDependents 直接继承指定人格的子人格名称
+func personaDependents(library *PersonaLibrary, idx int) []string {
+	var names []string
+	for i, p := range library.Personas {
+		if i != idx && strings.TrimSpace(p.Extends) != "" && findPersonaIndex(library, p.Extends) == idx {
+			names = append(names, p.Name)
+		}
+	}
+	return names
+}
+
+// exportPersonaPack 导出指定人格 (为空则全部)，自动带上其继承的父人格，保证人格包自包含
+func exportPersonaPack(library *PersonaLibrary, names []string) (*PersonaPack, error) {
+	include := make(map[int]bool)
+	if len(names) == 0 {
+		for i := range library.Personas {
+			include[i] = true
+		}
+	}
+	for _, name := range names {
+		idx := findPersonaIndex(library, name)
+		if idx < 0 {
+			return nil, errors.New(i18n.T("未找到人格: %s", name))
+		}
+		chain, err := personaInheritChain(library, idx)
+		if err != nil {
+			return nil, err
+		}
+		for _, i := range chain {
+			include[i] = true
+		}
+	}
+
+	pack := &PersonaPack{Version: personaPackVersion, ExportedAt: time.Now().Format(time.RFC3339)}
+	for i, p := range library.Personas {
+		if include[i] {
+			pack.Personas = append(pack.Personas, p)
+		}
+	}
+	return pack, nil
+}
+
+// parsePersonaPack 解析人格包 (也接受不带 version 的 personas.json)
+func parsePersonaPack(data []byte) (*PersonaPack, error) {
+	var pack PersonaPack
+	if err := json.Unmarshal(data, &pack); err != nil {
+		return nil, errors.New(i18n.T("人格包不是有效的 JSON: %v", err))
+	}
+	if pack.Version > personaPackVersion {
+		return nil, errors.New(i18n.T("人格包版本 %d 高于当前支持的版本 %d", pack.Version, personaPackVersion))
+	}
+	if len(pack.Personas) == 0 {
+		return nil, errors.New(i18n.T("人格包中没有人格"))
+	}
+	return &pack, nil
+}
+
+// importPersonaPack 把人格包并入人格库；同名人格默认跳过，overwrite 时替换。
+// 合并后整体校验继承链，失败则不修改 library。
+func importPersonaPack(library *PersonaLibrary, pack *PersonaPack, overwrite bool) (added, replaced, skipped []string, err error) {
+	merged := &PersonaLibrary{Personas: append([]PersonaData{}, library.Personas...)}
+	seen := make(map[string]bool)
+	for _, p := range pack.Personas {
+		p.Name = strings.TrimSpace(p.Name)
+		if p.Name == "" {
+			return nil, nil, nil, errors.New(i18n.T("人格包中存在缺少 name 的人格"))
+		}
+		key := normalizePersonaKey(p.Name)
+		if seen[key] {
+			return nil, nil, nil, errors.New(i18n.T("人格包中人格 %s 重复", p.Name))
+		}
+		seen[key] = true
+
+		idx := findPersonaIndex(merged, p.Name)
+		switch {
+		case idx < 0:
+			merged.Personas = append(merged.Personas, p)
+			added = append(added, p.Name)
+		case overwrite:
+			merged.Personas[idx] = p
+			replaced = append(replaced, p.Name)
+		default:
+			skipped = append(skipped, p.Name)
+		}
+	}
+	if err := validatePersonaLibrary(merged); err != nil {
+		return nil, nil, nil, err
+	}
+	library.Personas = merged.Personas
+	return added, replaced, skipped, nil
+}
+
+// resolvePersonaPackPath 人格包路径必须是项目内的相对路径 (拒绝绝对路径与 ../)
+func resolvePersonaPackPath(sm *SessionManager, path string) (string, error) {
+	path = strings.TrimSpace(path)
+	if sm.ProjectRoot == "" {
+		return "", errors.New(i18n.T("人格包路径需要先 initialize_project"))
+	}
+	if filepath.IsAbs(path) {
+		return "", errors.New(i18n.T("人格包路径须为相对项目根的路径: %s", path))
+	}
+	rel, err := services.RelativeEditPath(sm.ProjectRoot, path)
+	if err != nil {
+		return "", errors.New(i18n.T("人格包路径不在项目目录内: %s", path))
+	}
+	return filepath.Join(sm.ProjectRoot, filepath.FromSlash(rel)), nil
+}
+
+// matchPersonaTrigger 任务描述中出现人格触发词时返回该人格下标 (多个命中取最长的触发词)
+func matchPersonaTrigger(library *PersonaLibrary, text string) (int, string) {
+	lower := strings.ToLower(text)
+	best, bestTrigger := -1, ""
+	for i, p := range library.Personas {
+		for _, trig := range p.Triggers {
+			t := strings.ToLower(strings.TrimSpace(trig))
+			if t == "" || !strings.Contains(lower, t) {
+				continue
+			}
+			if len([]rune(t)) > len([]rune(bestTrigger)) {
+				best, bestTrigger = i, strings.TrimSpace(trig)
+			}
+		}
+	}
+	return best, bestTrigger
+}
+
+// activatePersona 记录激活状态并生成人格指令
+func activatePersona(ctx context.Context, sm *SessionManager, library *PersonaLibrary, key string) (*PersonaActivation, error) {
+	p, err := resolvePersona(library, key)
+	if err != nil {
+		return nil, err
+	}
+	if sm.Memory != nil {
+		_ = sm.Memory.SaveState(ctx, activePersonaStateKey, p.Name, "persona")
+	}
+	return &PersonaActivation{
+		Name:        p.Name,
+		DisplayName: personaDisplayName(p),
+		Instruction: buildPersonaDNA(&p),
+	}, nil
+}
+
+// autoActivatePersona 任务描述命中人格触发词时自动激活；已是当前人格或未命中时返回 nil
+func autoActivatePersona(ctx context.Context, sm *SessionManager, task string) *PersonaActivation {
+	if sm.Memory == nil || strings.TrimSpace(task) == "" {
+		return nil
+	}
+	library, err := loadPersonaLibrary(sm)
+	if err != nil {
+		return nil
+	}
+	idx, trigger := matchPersonaTrigger(library, task)
+	if idx < 0 {
+		return nil
+	}
+	name := library.Personas[idx].Name
+	if active, _ := sm.Memory.GetState(ctx, activePersonaStateKey); normalizePersonaKey(active) == normalizePersonaKey(name) {
+		return nil
+	}
+	activation, err := activatePersona(ctx, sm, library, name)
+	if err != nil {
+		return nil
+	}
+	activation.Trigger = trigger
+	return activation
+}
+
+// renderPersonaActivation 自动激活提示 (Markdown)
+func renderPersonaActivation(a *PersonaActivation) string {
+	if a == nil {
+		return ""
+	}
+	return i18n.T("🎭 **人格已自动激活**: %s (%s)，触发词「%s」\n%s\n", a.DisplayName, a.Name, a.Trigger, a.Instruction)
+}
+
+// restoreActivePersona initialize_project 时重新注入上次激活的人格；人格已被删除则清除记录
+func restoreActivePersona(ctx context.Context, sm *SessionManager) string {
+	if sm.Memory == nil {
+		return ""
+	}
+	name, _ := sm.Memory.GetState(ctx, activePersonaStateKey)
+	if strings.TrimSpace(name) == "" {
+		return ""
+	}
+	library, err := loadPersonaLibrary(sm)
+	if err != nil {
+		return ""
+	}
+	p, err := resolvePersona(library, name)
+	if err != nil {
+		_ = sm.Memory.SaveState(ctx, activePersonaStateKey, "", "persona")
+		return i18n.T("\n\n⚠️ 上次激活的人格 %s 无法加载 (%v)，已恢复默认风格。", name, err)
+	}
+	return i18n.T("\n\n### 🎭 人格恢复\n已恢复上次激活的人格: %s (%s)\n%s", personaDisplayName(p), p.Name, buildPersonaDNA(&p))
+}
+
+// writePersonaPack 导出到文件 (不存在的目录会自动创建)；文件已存在且未指定 overwrite 时返回 fs.ErrExist
+func writePersonaPack(path string, pack *PersonaPack, overwrite bool) error {
+	data, err := json.MarshalIndent(pack, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	flag := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if !overwrite {
+		flag |= os.O_EXCL
+	}
+	f, err := os.OpenFile(path, flag, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,155 @@
+package tools
+
+import (
+	"context"
+	"mcp-server-go/internal/core"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestResolvePersonaInheritance(t *testing.T) {
+	library := &PersonaLibrary{Personas: []PersonaData{
+		{Name: "base", HardDirective: "严谨", Avatar: "🧭", StyleMust: []string{"先结论"}, StyleTaboo: []string{"废话"}, Triggers: []string{"基础"}},
+		{Name: "strict", Extends: "base", StyleTaboo: []string{"猜测"}},
+		{Name: "loud", Extends: "strict", StyleMerge: "append", HardDirective: "大声", StyleMust: []string{"感叹号", "先结论"}},
+	}}
+
+	strict, err := resolvePersona(library, "strict")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strict.HardDirective != "严谨" || strict.Avatar != "🧭" || len(strict.Triggers) != 0 {
+		t.Fatalf("strict should inherit directive/avatar but not triggers: %+v", strict)
+	}
+	if strings.Join(strict.StyleTaboo, ",") != "猜测" || strings.Join(strict.StyleMust, ",") != "先结论" {
+		t.Fatalf("style lists should be replaced or inherited: %+v", strict)
+	}
+
+	loud, err := resolvePersona(library, "loud")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loud.Name != "loud" || loud.HardDirective != "大声" || strings.Join(loud.StyleMust, ",") != "先结论,感叹号" {
+		t.Fatalf("append should extend parent list without duplicates: %+v", loud)
+	}
+
+	library.Personas[0].Extends = "loud"
+	if err := validatePersonaLibrary(library); err == nil || !strings.Contains(err.Error(), "循环") {
+		t.Fatalf("expected cycle error, got %v", err)
+	}
+	library.Personas[0].Extends = "missing"
+	if _, err := resolvePersona(library, "strict"); err == nil || !strings.Contains(err.Error(), "不存在") {
+		t.Fatalf("expected missing parent error, got %v", err)
+	}
+}
+
+func TestPersonaExportImportAndDelete(t *testing.T) {
+	root := t.TempDir()
+	sm := &SessionManager{ProjectRoot: root}
+
+	callPersonaTool(t, sm, map[string]any{"mode": "create", "name": "strict_zhuge", "extends": "zhuge", "style_taboo": []string{"夸张修辞"}})
+	if text := callPersonaTool(t, sm, map[string]any{"mode": "delete", "name": "zhuge"}); !strings.Contains(text, "仍被继承") {
+		t.Fatalf("deleting a parent persona should be refused: %s", text)
+	}
+	if text := callPersonaTool(t, sm, map[string]any{"mode": "create", "name": "bad", "extends": "nobody"}); !strings.Contains(text, "不存在") {
+		t.Fatalf("unknown parent should be rejected: %s", text)
+	}
+
+	// 导出时自动带上父人格
+	text := callPersonaTool(t, sm, map[string]any{"mode": "export", "names": []string{"strict_zhuge"}, "path": "packs/zhuge.json"})
+	if !strings.Contains(text, "已导出 2 个人格") {
+		t.Fatalf("unexpected export output: %s", text)
+	}
+	data, err := os.ReadFile(filepath.Join(root, "packs", "zhuge.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 已存在的文件需 overwrite；路径限定在项目内
+	if text := callPersonaTool(t, sm, map[string]any{"mode": "export", "path": "packs/zhuge.json"}); !strings.Contains(text, "已存在") {
+		t.Fatalf("existing pack should not be overwritten silently: %s", text)
+	}
+	if text := callPersonaTool(t, sm, map[string]any{"mode": "export", "path": "packs/zhuge.json", "overwrite": true}); !strings.Contains(text, "已导出") {
+		t.Fatalf("overwrite=true should replace the pack: %s", text)
+	}
+	for _, path := range []string{"../escape.json", "packs/../../escape.json", filepath.Join(t.TempDir(), "abs.json")} {
+		if text := callPersonaTool(t, sm, map[string]any{"mode": "export", "path": path}); !strings.Contains(text, "人格包路径") {
+			t.Errorf("export path %q should be rejected: %s", path, text)
+		}
+		if text := callPersonaTool(t, sm, map[string]any{"mode": "import", "path": path}); !strings.Contains(text, "人格包路径") {
+			t.Errorf("import path %q should be rejected: %s", path, text)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(root), "escape.json")); err == nil {
+		t.Fatal("pack escaped the project root")
+	}
+
+	other := &SessionManager{ProjectRoot: t.TempDir()}
+	text = callPersonaTool(t, other, map[string]any{"mode": "import", "content": string(data)})
+	if !strings.Contains(text, "新增 1") || !strings.Contains(text, "跳过 1") {
+		t.Fatalf("import should add the child and skip the existing parent: %s", text)
+	}
+	library, err := loadPersonaLibrary(other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resolved, err := resolvePersona(library, "strict_zhuge")
+	if err != nil || !strings.Contains(resolved.HardDirective, "主公") {
+		t.Fatalf("imported persona should resolve against its parent: %+v %v", resolved, err)
+	}
+
+	if text := callPersonaTool(t, other, map[string]any{"mode": "import", "content": `{"version": 9, "personas": [{"name": "x"}]}`}); !strings.Contains(text, "版本") {
+		t.Fatalf("newer pack version should be rejected: %s", text)
+	}
+}
+
+func TestPersonaTriggerActivationAndRestore(t *testing.T) {
+	root := t.TempDir()
+	mem, err := core.NewMemoryLayer(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	sm := &SessionManager{Memory: mem, ProjectRoot: root}
+
+	callPersonaTool(t, sm, map[string]any{"mode": "create", "name": "reviewer", "hard_directive": "逐行挑错", "triggers": []string{"代码审查", "审查"}})
+
+	res, err := wrapPromptEnhance(sm)(ctx, mcp.CallToolRequest{Params: mcp.CallToolParams{
+		Name:      "prompt_enhance",
+		Arguments: map[string]any{"task_description": "帮我做一次代码审查"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text := getTextResult(t, res); !strings.Contains(text, "人格已自动激活") || !strings.Contains(text, "代码审查") {
+		t.Fatalf("trigger should auto-activate persona with the longest trigger: %s", text)
+	}
+	if active, _ := mem.GetState(ctx, activePersonaStateKey); active != "reviewer" {
+		t.Fatalf("active persona not recorded: %q", active)
+	}
+	if a := autoActivatePersona(ctx, sm, "继续审查"); a != nil {
+		t.Fatalf("already active persona should not be re-injected: %+v", a)
+	}
+
+	restored := restoreActivePersona(ctx, sm)
+	if !strings.Contains(restored, "人格恢复") || !strings.Contains(restored, "逐行挑错") {
+		t.Fatalf("restore should re-inject persona DNA: %s", restored)
+	}
+
+	callPersonaTool(t, sm, map[string]any{"mode": "deactivate"})
+	if restoreActivePersona(ctx, sm) != "" {
+		t.Fatalf("deactivated persona should not be restored")
+	}
+
+	_ = mem.SaveState(ctx, activePersonaStateKey, "ghost", "persona")
+	if msg := restoreActivePersona(ctx, sm); !strings.Contains(msg, "ghost") {
+		t.Fatalf("missing persona should be reported: %s", msg)
+	}
+	if active, _ := mem.GetState(ctx, activePersonaStateKey); active != "" {
+		t.Fatalf("missing persona should be cleared, got %q", active)
+	}
+}
@@ -0,0 +1,291 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mcp-server-go/internal/i18n"
+	"mcp-server-go/internal/services"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const (
+	// protocolDirName 全局 (~/.mpm/protocols) 与项目 (.mcp-config/protocols) 的协议模板目录
+	protocolDirName = "protocols"
+	// defaultProtocolName 未指定名称且没有意图命中时使用的协议；放同名文件即可覆盖内置版本
+	defaultProtocolName = "tactical"
+
+	protocolHookLimit = 5
+)
+
+// protocolContextBlock 内置协议末尾的项目上下文段 (变量为空时整段省略)
+const protocolContextBlock = `
+{{- if or .TechStack .TaskChain .Hooks .Facts}}
+## 📎 项目上下文 ({{.ProjectName}})
+{{- if .TechStack}}
+- 技术栈: {{join .TechStack ", "}}
+{{- end}}
+{{- with .TaskChain}}
+- 进行中的任务链: {{.ID}}{{if .Description}} {{.Description}}{{end}} ({{.Done}}/{{.Total}}，当前: {{.Current}})
+{{- end}}
+{{- if .Hooks}}
+- 待办钩子:
+{{- range .Hooks}}
+  - [{{.Priority}}] {{.Summary}}
+{{- end}}
+{{- end}}
+{{- if .Facts}}
+- 相关铁律:
+{{- range .Facts}}
+  - {{.}}
+{{- end}}
+{{- end}}
+{{end}}`
+
+// ProtocolTemplate 意图增强协议模板
+type ProtocolTemplate struct {
+	Name        string   `yaml:"name" json:"name"`
+	Description string   `yaml:"description" json:"description,omitempty"`
+	Intents     []string `yaml:"intents" json:"intents,omitempty"` // 按意图自动选用 (如 DEBUG, REFACTOR)
+	Body        string   `yaml:"-" json:"-"`
+	Source      string   `yaml:"-" json:"source"` // builtin / global / project
+	Path        string   `yaml:"-" json:"path,omitempty"`
+}
+
+// ProtocolVars 模板可用变量
+type ProtocolVars struct {
+	ProjectName string
+	ProjectRoot string
+	TechStack   []string
+	Task        string
+	Intent      string
+	Date        string
+	Hooks       []ProtocolHook
+	Facts       []string
+	TaskChain   *ProtocolChain
+}
+
+// ProtocolHook 待办钩子摘要
+type ProtocolHook struct {
+	ID          string
+	Summary     string
+	Priority    string
+	Description string
+	TaskID      string
+}
+
+// ProtocolChain 进行中的任务链摘要
+type ProtocolChain struct {
+	ID          string
+	Description string
+	Done        int
+	Total       int
+	Current     string
+	Next        string
+}
+
+// protocolFuncs 模板函数
+var protocolFuncs = template.FuncMap{
+	"join":  strings.Join,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+func builtinProtocol() *ProtocolTemplate {
+	return &ProtocolTemplate{
+		Name:        defaultProtocolName,
+		Description: i18n.T("战术执行协议：建立边界 -> 历史探测 -> 意图解析 -> 现状映射 -> 输出清单 -> 立即执行"),
+		Body:        tacticalProtocol + protocolContextBlock,
+		Source:      templateSourceBuiltin,
+	}
+}
+
+// loadProtocolTemplates 按 内置 < 全局 < 项目 加载协议模板，同名者后加载的覆盖先加载的。
+// 解析失败的文件跳过并返回警告。
+func loadProtocolTemplates(projectRoot string) ([]*ProtocolTemplate, []string) {
+	byName := map[string]*ProtocolTemplate{defaultProtocolName: builtinProtocol()}
+	warnings := loadTemplateFiles(projectRoot, protocolDirName, []string{".md", ".tmpl"}, func(source, path string) error {
+		tpl, err := readProtocolTemplate(path)
+		if err != nil {
+			return err
+		}
+		tpl.Source = source
+		byName[strings.ToLower(tpl.Name)] = tpl
+		return nil
+	})
+
+	list := make([]*ProtocolTemplate, 0, len(byName))
+	for _, tpl := range byName {
+		list = append(list, tpl)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list, warnings
+}
+
+// readProtocolTemplate 读取协议文件：可选 YAML frontmatter (name/description/intents) + 模板正文
+func readProtocolTemplate(path string) (*ProtocolTemplate, error) {
+	tpl := &ProtocolTemplate{}
+	content, err := readTemplateFile(path, tpl)
+	if err != nil {
+		return nil, err
+	}
+	if tpl.Name == "" {
+		tpl.Name = templateFileName(path)
+	}
+	if !templateNameRe.MatchString(tpl.Name) {
+		return nil, errors.New(i18n.T("协议名无效: %q (仅限字母、数字、- 和 _)", tpl.Name))
+	}
+	if strings.TrimSpace(content) == "" {
+		return nil, errors.New(i18n.T("协议正文为空"))
+	}
+	if _, err := template.New(tpl.Name).Funcs(protocolFuncs).Parse(content); err != nil {
+		return nil, errors.New(i18n.T("模板语法错误: %v", err))
+	}
+	tpl.Body = content
+	tpl.Path = path
+	return tpl, nil
+}
+
+// selectProtocol 指定名称优先；否则按意图匹配 (项目级优先于全局)；都没有时使用默认协议。
+// 返回选中的模板与选择依据。
+func selectProtocol(templates []*ProtocolTemplate, name, intent string) (*ProtocolTemplate, string, error) {
+	if name = strings.TrimSpace(name); name != "" {
+		for _, tpl := range templates {
+			if strings.EqualFold(tpl.Name, name) {
+				return tpl, i18n.T("指定名称"), nil
+			}
+		}
+		var names []string
+		for _, tpl := range templates {
+			names = append(names, tpl.Name)
+		}
+		return nil, "", errors.New(i18n.T("未找到协议 %s (可用: %s)", name, strings.Join(names, ", ")))
+	}
+
+	if intent = strings.TrimSpace(intent); intent != "" {
+		var best *ProtocolTemplate
+		for _, tpl := range templates {
+			for _, in := range tpl.Intents {
+				if strings.EqualFold(strings.TrimSpace(in), intent) {
+					if best == nil || protocolSourceRank(tpl.Source) > protocolSourceRank(best.Source) {
+						best = tpl
+					}
+					break
+				}
+			}
+		}
+		if best != nil {
+			return best, i18n.T("意图 %s", strings.ToUpper(intent)), nil
+		}
+	}
+
+	for _, tpl := range templates {
+		if tpl.Name == defaultProtocolName {
+			return tpl, i18n.T("默认"), nil
+		}
+	}
+	return builtinProtocol(), i18n.T("默认"), nil
+}
+
+func protocolSourceRank(source string) int {
+	switch source {
+	case templateSourceProject:
+		return 2
+	case templateSourceGlobal:
+		return 1
+	}
+	return 0
+}
+
+// buildProtocolVars 收集模板变量；记忆层未初始化时相关字段为空
+func buildProtocolVars(ctx context.Context, sm *SessionManager, task, intent string) ProtocolVars {
+	vars := ProtocolVars{
+		Task:   strings.TrimSpace(task),
+		Intent: intent,
+		Date:   time.Now().Format("2006-01-02"),
+	}
+	if sm.ProjectRoot == "" {
+		return vars
+	}
+	vars.ProjectRoot = sm.ProjectRoot
+	vars.ProjectName = filepath.Base(sm.ProjectRoot)
+	vars.TechStack = services.DetectTechStack(sm.ProjectRoot)
+	vars.TaskChain = activeProtocolChain(sm)
+
+	if sm.Memory == nil {
+		return vars
+	}
+	if hooks, err := sm.Memory.ListHooks(ctx, "open"); err == nil {
+		for i, h := range hooks {
+			if i >= protocolHookLimit {
+				break
+			}
+			summary := h.Summary
+			if summary == "" {
+				summary = truncateLine(h.Description, 80)
+			}
+			vars.Hooks = append(vars.Hooks, ProtocolHook{
+				ID:          h.HookID,
+				Summary:     summary,
+				Priority:    h.Priority,
+				Description: h.Description,
+				TaskID:      h.RelatedTaskID,
+			})
+		}
+	}
+	if vars.Task != "" {
+		vars.Facts, _, _ = loadRelevantContext(ctx, sm, nil, vars.Task, nil)
+	}
+	return vars
+}
+
+// activeProtocolChain 进行中的任务链 (多个时取最近有变更的一条)
+func activeProtocolChain(sm *SessionManager) *ProtocolChain {
+	var latest *TaskChainV2
+	for _, chain := range sm.TaskChainsV2 {
+		if chain.Status == "finished" {
+			continue
+		}
+		if latest == nil || chain.UpdatedAt.After(latest.UpdatedAt) ||
+			(chain.UpdatedAt.Equal(latest.UpdatedAt) && chain.TaskID > latest.TaskID) {
+			latest = chain
+		}
+	}
+	if latest == nil {
+		return nil
+	}
+	done, total := chainProgress(latest)
+	return &ProtocolChain{
+		ID:          latest.TaskID,
+		Description: truncateLine(latest.Description, 60),
+		Done:        done,
+		Total:       total,
+		Current:     currentStepLabel(latest),
+		Next:        chainNextAction(latest),
+	}
+}
+
+// renderProtocol 渲染协议模板；引用不存在的变量视为错误
+func renderProtocol(tpl *ProtocolTemplate, vars ProtocolVars) (string, error) {
+	t, err := template.New(tpl.Name).Funcs(protocolFuncs).Option("missingkey=error").Parse(tpl.Body)
+	if err != nil {
+		return "", errors.New(i18n.T("协议 %s 模板语法错误: %v", tpl.Name, err))
+	}
+	var sb strings.Builder
+	if err := t.Execute(&sb, vars); err != nil {
+		return "", errors.New(i18n.T("协议 %s 渲染失败: %v", tpl.Name, err))
+	}
+	return sb.String(), nil
+}
+
+// protocolLocation 协议来源描述
+func protocolLocation(tpl *ProtocolTemplate) string {
+	if tpl.Path == "" {
+		return tpl.Source
+	}
+	return fmt.Sprintf("%s: %s", tpl.Source, tpl.Path)
+}
@@ -0,0 +1,115 @@
+package tools
+
+import (
+	"context"
+	"mcp-server-go/internal/core"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func callPromptEnhance(t *testing.T, sm *SessionManager, args map[string]any) (string, bool) {
+	t.Helper()
+	res, err := wrapPromptEnhance(sm)(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{
+		Name:      "prompt_enhance",
+		Arguments: args,
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return getTextResult(t, res), res.IsError
+}
+
+func writeProtocol(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPromptEnhanceProtocols(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("MPM_HOME", home)
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "go.mod"), []byte("module demo\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mem, err := core.NewMemoryLayer(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if _, err := mem.SaveFact(ctx, "铁律", "支付回调必须幂等"); err != nil {
+		t.Fatal(err)
+	}
+	sm := &SessionManager{Memory: mem, ProjectRoot: root, TaskChainsV2: map[string]*TaskChainV2{
+		"chain_1": {TaskID: "chain_1", Description: "支付重构", Status: "running", UpdatedAt: time.Now(), Steps: []Step{
+			{Number: 1, Name: "梳理回调", Status: StepStatusComplete},
+			{Number: 2, Name: "拆分服务", Status: StepStatusInProgress},
+		}},
+		// TaskID 更大但更早变更：不应被选中
+		"zz_stale": {TaskID: "zz_stale", Status: "running", UpdatedAt: time.Now().Add(-time.Hour), Steps: []Step{
+			{Number: 1, Name: "旧任务", Status: StepStatusInProgress},
+		}},
+	}}
+
+	// 内置协议附带项目上下文
+	text, isErr := callPromptEnhance(t, sm, map[string]any{"task_description": "修复支付回调重复入账"})
+	if isErr || !strings.Contains(text, "意图增强协议已激活") || !strings.Contains(text, "技术栈: Go") ||
+		!strings.Contains(text, "chain_1") || !strings.Contains(text, "支付回调必须幂等") {
+		t.Fatalf("builtin protocol should render project context: %s", text)
+	}
+
+	globalDir := filepath.Join(home, "protocols")
+	projectDir := filepath.Join(root, ".mcp-config", "protocols")
+	writeProtocol(t, globalDir, "debug.md", "---\nintents: [DEBUG]\n---\nGLOBAL {{.Task}}\n")
+	writeProtocol(t, projectDir, "bugfix.md", "---\ndescription: 项目排障协议\nintents: [debug]\n---\n# {{.ProjectName}} 排障\n任务: {{.Task}}\n栈: {{join .TechStack \"/\"}}\n{{with .TaskChain}}链: {{.ID}} {{.Done}}/{{.Total}}{{end}}\n")
+	writeProtocol(t, projectDir, "broken.md", "{{if .Task}}unterminated")
+	writeProtocol(t, projectDir, "typo.tmpl", "{{.Unknown}}")
+
+	// 按意图选择：项目级优先于全局
+	text, isErr = callPromptEnhance(t, sm, map[string]any{"task_description": "排查登录报错", "intent": "DEBUG"})
+	if isErr || !strings.Contains(text, "已激活: bugfix") || !strings.Contains(text, filepath.Base(root)+" 排障") ||
+		!strings.Contains(text, "栈: Go") || !strings.Contains(text, "链: chain_1 1/2") {
+		t.Fatalf("project protocol should be selected by intent: %s", text)
+	}
+	if !strings.Contains(text, "broken.md") {
+		t.Fatalf("broken template should be reported: %s", text)
+	}
+
+	// 按名称选择
+	text, _ = callPromptEnhance(t, sm, map[string]any{"task_description": "x", "protocol": "debug"})
+	if !strings.Contains(text, "GLOBAL x") {
+		t.Fatalf("protocol should be selectable by name: %s", text)
+	}
+	if text, isErr = callPromptEnhance(t, sm, map[string]any{"protocol": "nope"}); !isErr || !strings.Contains(text, "bugfix") {
+		t.Fatalf("unknown protocol should list available ones: %s", text)
+	}
+	if text, isErr = callPromptEnhance(t, sm, map[string]any{"protocol": "typo"}); !isErr || !strings.Contains(text, "渲染失败") {
+		t.Fatalf("unknown variable should fail rendering: %s", text)
+	}
+
+	// explain 展示完整渲染结果与来源
+	text, _ = callPromptEnhance(t, sm, map[string]any{"mode": "explain", "task_description": "排查登录报错", "intent": "DEBUG"})
+	if !strings.Contains(text, "bugfix (project:") || !strings.Contains(text, "意图 DEBUG") ||
+		!strings.Contains(text, "任务: 排查登录报错") || !strings.Contains(text, "`tactical` [builtin]") {
+		t.Fatalf("explain should show selection and rendered protocol: %s", text)
+	}
+	if strings.Contains(text, "已激活") {
+		t.Fatalf("explain should not activate the protocol: %s", text)
+	}
+
+	// 同名文件覆盖内置协议
+	writeProtocol(t, projectDir, "tactical.md", "自定义默认协议 {{.Date}}")
+	text, _ = callPromptEnhance(t, sm, map[string]any{"task_description": "整理文档"})
+	if !strings.Contains(text, "自定义默认协议") || strings.Contains(text, "战术") {
+		t.Fatalf("project tactical.md should override builtin: %s", text)
+	}
+}
@@ -0,0 +1,125 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"mcp-server-go/internal/i18n"
+	"mcp-server-go/internal/services"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// RefactorPreviewArgs 重构预览参数
+type RefactorPreviewArgs struct {
+	SymbolName  string `json:"symbol_name" jsonschema:"required,description=要重命名或移动的符号名"`
+	NewName     string `json:"new_name" jsonschema:"description=新名称 (重命名)"`
+	Destination string `json:"destination" jsonschema:"description=目标文件相对路径 (移动)"`
+	File        string `json:"file" jsonschema:"description=定义所在文件 (同名符号有多处定义时用于消歧)"`
+	CertainOnly bool   `json:"certain_only" jsonschema:"description=补丁只包含 AST 确认的引用，文本匹配仅列出不改写"`
+}
+
+func wrapRefactorPreview(sm *SessionManager, ai *services.ASTIndexer) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var args RefactorPreviewArgs
+		if err := request.BindArguments(&args); err != nil {
+			return mcp.NewToolResultError(i18n.T("参数格式错误: %v", err)), nil
+		}
+
+		if sm.ProjectRoot == "" {
+			return mcp.NewToolResultError(i18n.T("项目尚未初始化，请先执行 initialize_project。")), nil
+		}
+
+		// 先刷新索引，保证行号与磁盘一致
+		_, _ = ai.Index(sm.ProjectRoot)
+
+		preview, err := ai.PreviewRefactor(ctx, sm.ProjectRoot, services.RefactorRequest{
+			Symbol:      args.SymbolName,
+			File:        args.File,
+			NewName:     args.NewName,
+			Destination: args.Destination,
+			CertainOnly: args.CertainOnly,
+		})
+		if err != nil {
+			return mcp.NewToolResultError(i18n.T("重构预览失败: %v", err)), nil
+		}
+
+		return mcp.NewToolResultStructured(preview, renderRefactorPreview(args, preview)), nil
+	}
+}
+
+func renderRefactorPreview(args RefactorPreviewArgs, p *services.RefactorPreview) string {
+	var sb strings.Builder
+
+	var action string
+	switch {
+	case args.NewName != "" && args.Destination != "":
+		action = i18n.T("重命名为 `%s` 并移动到 `%s`", args.NewName, args.Destination)
+	case args.NewName != "":
+		action = i18n.T("重命名为 `%s`", args.NewName)
+	default:
+		action = i18n.T("移动到 `%s`", args.Destination)
+	}
+
+	def := p.Definition
+	sb.WriteString(i18n.T("## 🔧 重构预览: `%s` %s\n\n", def.Name, action))
+	sb.WriteString(i18n.T("**定义**: %s `%s` @ `%s` L%d-%d\n", def.NodeType, def.Name, def.FilePath, def.LineStart, def.LineEnd))
+
+	var certain, possible []services.RefactorMatch
+	for _, m := range p.Matches {
+		if m.Confidence == services.MatchCertain {
+			certain = append(certain, m)
+		} else {
+			possible = append(possible, m)
+		}
+	}
+	sb.WriteString(i18n.T("**引用**: ✅ %d certain (AST) | ❓ %d possible (文本)\n\n", len(certain), len(possible)))
+
+	writeMatches := func(title string, matches []services.RefactorMatch, limit int) {
+		if len(matches) == 0 {
+			return
+		}
+		sb.WriteString(title + "\n")
+		for i, m := range matches {
+			if i >= limit {
+				sb.WriteString(i18n.T("- ... 还有 %d 处\n", len(matches)-limit))
+				break
+			}
+			sb.WriteString(fmt.Sprintf("- [%s] `%s:%d` %s\n", m.Kind, m.FilePath, m.LineNumber, truncateLine(m.Content, 100)))
+		}
+		sb.WriteString("\n")
+	}
+	writeMatches(i18n.T("### ✅ Certain (AST 确认)"), certain, 30)
+	writeMatches(i18n.T("### ❓ Possible (仅文本匹配，请人工确认)"), possible, 30)
+
+	for _, note := range p.Notes {
+		sb.WriteString(fmt.Sprintf("> %s\n", note))
+	}
+	if len(p.Notes) > 0 {
+		sb.WriteString("\n")
+	}
+
+	if p.Patch == "" {
+		sb.WriteString(i18n.T("_无需修改任何文件_\n"))
+		return sb.String()
+	}
+
+	sb.WriteString(i18n.T("### 📝 补丁 (%d 个文件，未应用)\n", len(p.Files)))
+	if args.NewName != "" && !args.CertainOnly && len(possible) > 0 {
+		sb.WriteString(i18n.T("_补丁包含 possible 引用；如需只改 AST 确认的位置，设置 certain_only=true_\n"))
+	}
+	sb.WriteString("\n```diff\n")
+	sb.WriteString(p.Patch)
+	sb.WriteString("```\n")
+	return sb.String()
+}
+
+// truncateLine 截断单行展示内容
+func truncateLine(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+	return string(runes[:limit]) + "..."
+}
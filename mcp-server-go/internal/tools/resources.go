@@ -339,9 +339,16 @@ def generate():
         conn = sqlite3.connect(DB_PATH)
         conn.row_factory = sqlite3.Row
         cur = conn.cursor()
+        # 被 .gitignore / .mpmignore 忽略的文件由 MPM 计算后通过环境变量传入
+        try:
+            excluded = set(json.loads(os.environ.get("MPM_TIMELINE_EXCLUDE") or "[]"))
+        except Exception:
+            excluded = set()
         cur.execute("SELECT * FROM memos ORDER BY id ASC")
         for row in cur.fetchall():
             d = dict(row)
+            if d.get('path') in excluded:
+                continue
             d['timestamp'] = normalize_ts(d.get('timestamp') or d.get('created_at'))
             data.append(d)
 
@@ -2,10 +2,14 @@ package tools
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"mcp-server-go/internal/i18n"
 	"mcp-server-go/internal/services"
 	"path/filepath"
+	"sort"
 	"strings"
+	"unicode"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
@@ -16,13 +20,29 @@ type SearchArgs struct {
 	Query      string `json:"query" jsonschema:"required,description=搜索关键词"`
 	Scope      string `json:"scope" jsonschema:"description=限定范围"`
 	SearchType string `json:"search_type" jsonschema:"default=any,enum=any,enum=function,enum=class,description=符号类型过滤"`
+	Mode       string `json:"mode" jsonschema:"default=auto,enum=auto,enum=symbol,enum=concept,description=auto=按名称定位，多词描述自动走概念搜索; symbol=仅按名称; concept=按概念 (名称/签名/注释/路径) BM25 排序"`
+}
+
+// GrepArgs 文本搜索参数
+type GrepArgs struct {
+	Pattern       string `json:"pattern" jsonschema:"required,description=搜索内容 (默认按字面量匹配)"`
+	Scope         string `json:"scope" jsonschema:"description=限定目录或文件 (留空=整个项目)"`
+	Regex         bool   `json:"regex" jsonschema:"description=按正则表达式匹配"`
+	CaseSensitive bool   `json:"case_sensitive" jsonschema:"description=区分大小写 (默认不区分)"`
+	WordMatch     bool   `json:"word_match" jsonschema:"description=全词匹配"`
+	Multiline     bool   `json:"multiline" jsonschema:"description=跨行匹配 (正则中 . 可匹配换行)"`
+	Extensions    string `json:"extensions" jsonschema:"description=扩展名过滤，逗号分隔 (如 go,py)"`
+	Include       string `json:"include" jsonschema:"description=包含的文件 glob，逗号分隔 (如 *_test.go)"`
+	Exclude       string `json:"exclude" jsonschema:"description=排除的文件 glob，逗号分隔"`
+	ContextLines  int    `json:"context_lines" jsonschema:"description=每处匹配附带的上下文行数 (0-10)"`
+	MaxResults    int    `json:"max_results" jsonschema:"default=100,description=最多返回的匹配数"`
 }
 
 // RegisterSearchTools 注册搜索工具
 func RegisterSearchTools(s *server.MCPServer, sm *SessionManager, ai *services.ASTIndexer) {
 
-	s.AddTool(mcp.NewTool("code_search",
-		mcp.WithDescription(`code_search - 代码符号定位 (比 grep 更懂代码)
+	addTool(s, mcp.NewTool("code_search",
+		mcp.WithDescription(i18n.Desc("code_search", `code_search - 代码符号定位 (比 grep 更懂代码)
 
 用途：
   【精确定位】当你只知道名字（函数名/类名），但不知道它在哪个文件时，别用 grep，用我。
@@ -30,7 +50,14 @@ func RegisterSearchTools(s *server.MCPServer, sm *SessionManager, ai *services.A
 
 参数策略：
   query (必填)
-    不要写自然语言！直接写代码符号名（如 "SessionManager" 或 "HandleRequest"）。
+    首选代码符号名（如 "SessionManager" 或 "HandleRequest"）。
+    只知道功能不知道名字？写几个关键词（如 "persist hook expiry" / "钩子 过期"），
+    会走概念搜索：拆分驼峰/下划线，按名称、签名、文档注释、路径做 BM25 排序。
+  
+  mode (可选)
+    - "auto" (默认)：单个标识符按名称定位；多词描述先走概念搜索
+    - "symbol"：只按名称定位，找不到时退回文本搜索
+    - "concept"：只做概念搜索
   
   scope (可选)
     知道大概在哪个目录？填进来（如 "internal/core"），能大幅提高准确率。
@@ -44,25 +71,238 @@ func RegisterSearchTools(s *server.MCPServer, sm *SessionManager, ai *services.A
   告诉代码符号定义所在的精确文件路径和行号。
 
 触发词：
-  "mpm 搜索", "mpm 定位", "mpm 符号", "mpm find"`),
+  "mpm 搜索", "mpm 定位", "mpm 符号", "mpm find"`)),
 		mcp.WithInputSchema[SearchArgs](),
 	), wrapSearch(sm, ai))
+
+	addTool(s, mcp.NewTool("code_grep",
+		mcp.WithDescription(i18n.Desc("code_grep", `code_grep - 全功能文本搜索 (带所属符号)
+
+用途：
+  【文本检索】搜字符串、错误信息、配置键、正则模式时用我。
+  每处匹配都会标注所在的函数/类，结果按文件分组。
+  找符号定义请用 code_search。
+
+参数：
+  pattern (必填)
+    搜索内容，默认按字面量匹配
+  regex / case_sensitive / word_match / multiline (可选)
+    正则、区分大小写、全词匹配、跨行匹配
+  extensions / include / exclude (可选)
+    扩展名与 glob 过滤，逗号分隔
+  context_lines (可选)
+    上下文行数
+  scope (可选)
+    限定目录或文件
+
+返回：
+  按文件分组的匹配行 + 所属符号。
+
+触发词：
+  "mpm grep", "mpm 文本搜索"`)),
+		mcp.WithInputSchema[GrepArgs](),
+	), wrapGrep(sm, ai))
+}
+
+// resolveSearchRoot 把 scope 规整为项目内的搜索根；越出项目根时报错
+func resolveSearchRoot(projectRoot, scope string) (string, error) {
+	if strings.TrimSpace(scope) == "" {
+		return projectRoot, nil
+	}
+	rel, err := services.RelativeEditPath(projectRoot, scope)
+	if err != nil {
+		return "", errors.New(i18n.T("scope %s 不在项目目录内", scope))
+	}
+	return filepath.Join(projectRoot, filepath.FromSlash(rel)), nil
+}
+
+func wrapGrep(sm *SessionManager, ai *services.ASTIndexer) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if sm.ProjectRoot == "" {
+			return mcp.NewToolResultError(i18n.T("项目尚未初始化，请先执行 initialize_project。")), nil
+		}
+
+		var args GrepArgs
+		if err := request.BindArguments(&args); err != nil {
+			return mcp.NewToolResultError(i18n.T("参数格式错误: %v", err)), nil
+		}
+		if args.Pattern == "" {
+			return mcp.NewToolResultError(i18n.T("pattern 不能为空")), nil
+		}
+
+		maxResults := args.MaxResults
+		if maxResults <= 0 {
+			maxResults = sm.config().Search.MaxResults
+		}
+		contextLines := args.ContextLines
+		if contextLines < 0 {
+			contextLines = 0
+		} else if contextLines > 10 {
+			contextLines = 10
+		}
+
+		searchRoot, err := resolveSearchRoot(sm.ProjectRoot, args.Scope)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		// 多取一条用于判断是否截断，达到上限后停止读取 rg 输出
+		matches, err := services.NewRipgrepEngine().Search(ctx, services.SearchOptions{
+			Query:          args.Pattern,
+			RootPath:       searchRoot,
+			ProjectRoot:    sm.ProjectRoot,
+			IsRegex:        args.Regex,
+			CaseSensitive:  args.CaseSensitive,
+			WordMatch:      args.WordMatch,
+			Multiline:      args.Multiline,
+			Extensions:     splitList(args.Extensions),
+			IncludePattern: splitList(args.Include),
+			IgnorePattern:  splitList(args.Exclude),
+			ContextLines:   contextLines,
+			MaxResults:     maxResults + 1,
+		})
+		if err != nil {
+			return mcp.NewToolResultError(i18n.T("搜索失败: %v", err)), nil
+		}
+
+		if len(matches) == 0 {
+			data := &grepData{Pattern: args.Pattern, Files: []grepFile{}}
+			return mcp.NewToolResultStructured(data, i18n.T("⚠️ 未找到「%s」", args.Pattern)), nil
+		}
+
+		truncated := len(matches) > maxResults
+		if truncated {
+			matches = matches[:maxResults]
+		}
+		total := len(matches)
+
+		// 按文件分组 (路径统一为项目相对路径)
+		grouped := make(map[string][]services.TextMatch)
+		var files []string
+		for _, m := range matches {
+			rel := m.FilePath
+			if r, err := filepath.Rel(sm.ProjectRoot, filepath.FromSlash(m.FilePath)); err == nil && filepath.IsAbs(m.FilePath) {
+				rel = filepath.ToSlash(r)
+			}
+			if _, ok := grouped[rel]; !ok {
+				files = append(files, rel)
+			}
+			grouped[rel] = append(grouped[rel], m)
+		}
+		sort.Strings(files)
+
+		// 所属符号：按文件批量查询一次
+		symbolsByFile, _ := ai.SymbolsInFiles(sm.ProjectRoot, files)
+
+		data := &grepData{Pattern: args.Pattern, Total: total, Truncated: truncated, Files: []grepFile{}}
+		var sb strings.Builder
+		if truncated {
+			sb.WriteString(i18n.T("### 🔎 「%s」文本搜索: 超过 %d 处匹配，%d 个文件\n\n", args.Pattern, total, len(files)))
+			sb.WriteString(i18n.T("_仅显示前 %d 处，可缩小 scope 或提高 max_results_\n\n", maxResults))
+		} else {
+			sb.WriteString(i18n.T("### 🔎 「%s」文本搜索: %d 处匹配，%d 个文件\n\n", args.Pattern, total, len(files)))
+		}
+
+		for _, file := range files {
+			fileMatches := grouped[file]
+			gf := grepFile{Path: file, Matches: make([]grepMatch, 0, len(fileMatches))}
+			sb.WriteString(fmt.Sprintf("📄 **%s** (%d)\n", file, len(fileMatches)))
+			for _, m := range fileMatches {
+				owner := "(global)"
+				gm := grepMatch{Line: m.LineNumber, Content: m.Content, ContextBefore: m.ContextBefore, ContextAfter: m.ContextAfter}
+				if n := services.EnclosingSymbol(symbolsByFile[file], m.LineNumber); n != nil {
+					owner = fmt.Sprintf("in `%s` (%s)", n.Name, n.NodeType)
+					gm.Symbol, gm.SymbolType = n.Name, n.NodeType
+				}
+				gf.Matches = append(gf.Matches, gm)
+
+				if contextLines == 0 && !strings.Contains(m.Content, "\n") {
+					sb.WriteString(fmt.Sprintf("  L%d: `%s` %s\n", m.LineNumber, truncateLine(strings.TrimSpace(m.Content), 160), owner))
+					continue
+				}
+
+				sb.WriteString(fmt.Sprintf("  L%d %s\n  ```\n", m.LineNumber, owner))
+				writeNumbered := func(text string, first int, marker string) {
+					if text == "" {
+						return
+					}
+					for i, line := range strings.Split(text, "\n") {
+						sb.WriteString(fmt.Sprintf("  %4d%s %s\n", first+i, marker, truncateLine(line, 160)))
+					}
+				}
+				before := m.ContextBefore
+				writeNumbered(before, m.LineNumber-strings.Count(before, "\n")-1, " ")
+				writeNumbered(m.Content, m.LineNumber, ">")
+				writeNumbered(m.ContextAfter, m.LineNumber+strings.Count(m.Content, "\n")+1, " ")
+				sb.WriteString("  ```\n")
+			}
+			sb.WriteString("\n")
+			data.Files = append(data.Files, gf)
+		}
+
+		return mcp.NewToolResultStructured(data, sb.String()), nil
+	}
+}
+
+// grepData code_grep 的结构化结果 (output_format=json)
+type grepData struct {
+	Pattern   string     `json:"pattern"`
+	Total     int        `json:"total"` // truncated 时为已返回的数量，实际匹配更多
+	Truncated bool       `json:"truncated"`
+	Files     []grepFile `json:"files"`
+}
+
+type grepFile struct {
+	Path    string      `json:"path"`
+	Matches []grepMatch `json:"matches"`
+}
+
+type grepMatch struct {
+	Line          int    `json:"line"`
+	Content       string `json:"content"`
+	ContextBefore string `json:"context_before,omitempty"`
+	ContextAfter  string `json:"context_after,omitempty"`
+	Symbol        string `json:"symbol,omitempty"`
+	SymbolType    string `json:"symbol_type,omitempty"`
+}
+
+// splitList 拆分逗号分隔的参数列表
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
 }
 
 func wrapSearch(sm *SessionManager, ai *services.ASTIndexer) server.ToolHandlerFunc {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		if sm.ProjectRoot == "" {
-			return mcp.NewToolResultError("项目尚未初始化，请先执行 initialize_project。"), nil
+			return mcp.NewToolResultError(i18n.T("项目尚未初始化，请先执行 initialize_project。")), nil
 		}
 
 		var args SearchArgs
 		if err := request.BindArguments(&args); err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("参数格式错误: %v", err)), nil
+			return mcp.NewToolResultError(i18n.T("参数格式错误: %v", err)), nil
 		}
 
 		// 🆕 【关键】先刷新索引，确保数据最新
 		_, _ = ai.Index(sm.ProjectRoot)
 
+		// 0. 概念搜索：显式指定，或 auto 模式下查询是多词描述
+		if args.Mode == "concept" || ((args.Mode == "" || args.Mode == "auto") && isConceptQuery(args.Query)) {
+			hits, err := ai.ConceptSearch(sm.ProjectRoot, args.Query, args.Scope, args.SearchType, 10)
+			if err != nil {
+				return mcp.NewToolResultError(i18n.T("概念搜索失败: %v", err)), nil
+			}
+			if len(hits) > 0 || args.Mode == "concept" {
+				data := &codeSearchData{Query: args.Query, Mode: "concept", Concepts: hits}
+				return mcp.NewToolResultStructured(data, renderConceptHits(args.Query, hits)), nil
+			}
+		}
+
 		// 1. AST Search (Core Strategy)
 		astResult, err := ai.SearchSymbolWithScope(sm.ProjectRoot, args.Query, args.Scope)
 		if err != nil {
@@ -114,7 +354,8 @@ func wrapSearch(sm *SessionManager, ai *services.ASTIndexer) server.ToolHandlerF
 		}
 
 		var sb strings.Builder
-		sb.WriteString(fmt.Sprintf("### 关于「%s」的搜索结果\n\n", args.Query))
+		sb.WriteString(i18n.T("### 关于「%s」的搜索结果\n\n", args.Query))
+		data := &codeSearchData{Query: args.Query, Mode: "symbol"}
 
 		// 2. Decide if Grep is needed
 		// Fallback trigger: No Exact Match found in AST (after filtering)
@@ -122,24 +363,29 @@ func wrapSearch(sm *SessionManager, ai *services.ASTIndexer) server.ToolHandlerF
 
 		// 如果 AST 找到了精确匹配，直接展示，不进行 grep (避免噪音)
 		if astResult != nil && astResult.FoundSymbol != nil {
-			sb.WriteString(fmt.Sprintf("✅ **精确定义** (%s):\n", astResult.MatchType))
+			sb.WriteString(i18n.T("✅ **精确定义** (%s):\n", astResult.MatchType))
 			node := astResult.FoundSymbol
+			data.Definition, data.MatchType = node, astResult.MatchType
 			sb.WriteString(fmt.Sprintf("- **%s** `%s` @ `%s` L%d-%d\n",
 				node.NodeType, node.Name, node.FilePath, node.LineStart, node.LineEnd))
 
 			if node.Signature != "" {
 				sb.WriteString(fmt.Sprintf("  Config: `%s`\n", node.Signature))
 			}
+			if doc := services.DocSummary(node.Name, node.Doc, 160); doc != "" {
+				sb.WriteString(fmt.Sprintf("  Doc: %s\n", doc))
+			}
 			sb.WriteString("\n")
 		} else if astResult != nil && len(astResult.Candidates) > 0 {
 			// 展示 AST 候选
-			sb.WriteString("🔍 **相似符号** (AST):\n")
+			sb.WriteString(i18n.T("🔍 **相似符号** (AST):\n"))
 			for i, c := range astResult.Candidates {
 				if i >= 5 {
 					break
 				}
-				sb.WriteString(fmt.Sprintf("- [%s] `%s` @ `%s` (score: %.2f)\n",
-					c.Node.NodeType, c.Node.Name, c.Node.FilePath, c.Score))
+				data.Candidates = append(data.Candidates, c)
+				sb.WriteString(fmt.Sprintf("- [%s] `%s` @ `%s` (score: %.2f)%s\n",
+					c.Node.NodeType, c.Node.Name, c.Node.FilePath, c.Score, docSuffix(c.Node, 80)))
 			}
 			sb.WriteString("\n")
 		}
@@ -148,23 +394,27 @@ func wrapSearch(sm *SessionManager, ai *services.ASTIndexer) server.ToolHandlerF
 		if useGrep {
 			rg := services.NewRipgrepEngine()
 
-			// 智能检测是否包含路径分隔符，如果有，只搜那个文件或目录
-			searchRoot := sm.ProjectRoot
-			if args.Scope != "" {
-				searchRoot = filepath.Join(sm.ProjectRoot, args.Scope)
+			// scope 限定为项目内的文件或目录
+			searchRoot, err := resolveSearchRoot(sm.ProjectRoot, args.Scope)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
 			}
 
 			matches, err := rg.Search(ctx, services.SearchOptions{
 				Query:         args.Query,
 				RootPath:      searchRoot,
+				ProjectRoot:   sm.ProjectRoot,
 				CaseSensitive: false, // 默认不区分大小写
 				WordMatch:     false,
 				MaxCount:      20, // 限制数量以防爆炸
+				MaxResults:    200,
 				ContextLines:  0,
 			})
 
+			data.Mode = "text"
 			if err == nil && len(matches) > 0 {
-				sb.WriteString(fmt.Sprintf("🕵️ **文本搜索结果** (Ripgrep found %d matches):\n", len(matches)))
+				data.Matches = matches
+				sb.WriteString(i18n.T("🕵️ **文本搜索结果** (Ripgrep found %d matches):\n", len(matches)))
 
 				// Group by File
 				grouped := make(map[string][]services.TextMatch)
@@ -176,7 +426,7 @@ func wrapSearch(sm *SessionManager, ai *services.ASTIndexer) server.ToolHandlerF
 				filesProcessed := 0
 				for path, fileMatches := range grouped {
 					if filesProcessed >= 10 {
-						sb.WriteString(fmt.Sprintf("... (剩余 %d 个文件的匹配已省略)\n", len(grouped)-filesProcessed))
+						sb.WriteString(i18n.T("... (剩余 %d 个文件的匹配已省略)\n", len(grouped)-filesProcessed))
 						break
 					}
 
@@ -184,7 +434,7 @@ func wrapSearch(sm *SessionManager, ai *services.ASTIndexer) server.ToolHandlerF
 
 					for i, m := range fileMatches {
 						if i >= 3 {
-							sb.WriteString(fmt.Sprintf("  ... (本文件还有 %d 处匹配)\n", len(fileMatches)-i))
+							sb.WriteString(i18n.T("  ... (本文件还有 %d 处匹配)\n", len(fileMatches)-i))
 							break
 						}
 
@@ -214,11 +464,64 @@ func wrapSearch(sm *SessionManager, ai *services.ASTIndexer) server.ToolHandlerF
 				sb.WriteString("\n")
 			} else {
 				if len(matches) == 0 && (astResult == nil || (astResult.FoundSymbol == nil && len(astResult.Candidates) == 0)) {
-					sb.WriteString(fmt.Sprintf("⚠️ **未找到「%s」** → 换词重试（同义词/缩写/驼峰变体），或用 `project_map` 先看结构\n", args.Query))
+					sb.WriteString(i18n.T("⚠️ **未找到「%s」** → 换词重试（同义词/缩写/驼峰变体），或用 `project_map` 先看结构\n", args.Query))
 				}
 			}
 		}
 
-		return mcp.NewToolResultText(sb.String()), nil
+		return mcp.NewToolResultStructured(data, sb.String()), nil
+	}
+}
+
+// codeSearchData code_search 的结构化结果 (output_format=json)
+type codeSearchData struct {
+	Query      string                    `json:"query"`
+	Mode       string                    `json:"mode"` // concept / symbol / text
+	Concepts   []services.ConceptHit     `json:"concepts,omitempty"`
+	Definition *services.Node            `json:"definition,omitempty"`
+	MatchType  string                    `json:"match_type,omitempty"`
+	Candidates []services.CandidateMatch `json:"candidates,omitempty"`
+	Matches    []services.TextMatch      `json:"matches,omitempty"`
+}
+
+// isConceptQuery 判断查询是否为自然语言描述 (多个词或中文)，而不是单个标识符
+func isConceptQuery(query string) bool {
+	query = strings.TrimSpace(query)
+	if len(strings.Fields(query)) > 1 {
+		return true
+	}
+	for _, r := range query {
+		if unicode.Is(unicode.Han, r) {
+			return true
+		}
+	}
+	return false
+}
+
+func renderConceptHits(query string, hits []services.ConceptHit) string {
+	var sb strings.Builder
+	sb.WriteString(i18n.T("### 🧠 「%s」概念搜索结果\n\n", query))
+	if len(hits) == 0 {
+		sb.WriteString(i18n.T("⚠️ **未找到与「%s」相关的符号** → 换个说法，或直接用符号名搜索\n", query))
+		return sb.String()
+	}
+
+	for i, h := range hits {
+		n := h.Node
+		sb.WriteString(i18n.T("%d. [%s] `%s` @ `%s` L%d-%d (score: %.2f, 命中: %s)\n",
+			i+1, n.NodeType, n.Name, n.FilePath, n.LineStart, n.LineEnd, h.Score, strings.Join(h.Matched, ", ")))
+		if doc := services.DocSummary(n.Name, h.Doc, 120); doc != "" {
+			sb.WriteString(fmt.Sprintf("   > %s\n", doc))
+		}
+	}
+	sb.WriteString(i18n.T("\n_排序基于名称/签名/文档注释/路径的 BM25 相关度，确认目标后可用 code_impact 查看影响范围_\n"))
+	return sb.String()
+}
+
+// docSuffix 生成行尾的文档摘要 (" — ...")，无文档时为空
+func docSuffix(n services.Node, limit int) string {
+	if doc := services.DocSummary(n.Name, n.Doc, limit); doc != "" {
+		return " — " + doc
 	}
+	return ""
 }
@@ -0,0 +1,54 @@
+package tools
+
+import (
+	"context"
+	"mcp-server-go/internal/services"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestGrepScopeAndLimit(t *testing.T) {
+	t.Setenv("MPM_HOME", t.TempDir())
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "pkg"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "pkg", "a.txt"), []byte(strings.Repeat("needle\n", 5)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sm := &SessionManager{ProjectRoot: root}
+	grep := wrapGrep(sm, services.NewASTIndexer())
+	call := func(args map[string]any) *mcp.CallToolResult {
+		t.Helper()
+		res, err := grep(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: args}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+
+	// scope 不能越出项目根
+	for _, scope := range []string{"../", "pkg/../..", filepath.Dir(root)} {
+		if res := call(map[string]any{"pattern": "needle", "scope": scope}); !res.IsError || !strings.Contains(getTextResult(t, res), "不在项目目录内") {
+			t.Errorf("scope %q should be rejected: %s", scope, getTextResult(t, res))
+		}
+	}
+
+	res := call(map[string]any{"pattern": "needle", "scope": "pkg", "max_results": 2})
+	data, _ := res.StructuredContent.(*grepData)
+	if res.IsError || data == nil || !data.Truncated || data.Total != 2 || len(data.Files) != 1 || data.Files[0].Path != "pkg/a.txt" {
+		t.Fatalf("expected 2 truncated matches in pkg/a.txt: %s", getTextResult(t, res))
+	}
+	if !strings.Contains(getTextResult(t, res), "超过 2 处匹配") {
+		t.Errorf("truncated header missing:\n%s", getTextResult(t, res))
+	}
+
+	res = call(map[string]any{"pattern": "needle", "max_results": 5})
+	if data, _ := res.StructuredContent.(*grepData); data == nil || data.Truncated || data.Total != 5 {
+		t.Fatalf("exactly max_results matches should not be truncated: %s", getTextResult(t, res))
+	}
+}
@@ -0,0 +1,254 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"mcp-server-go/internal/core"
+	"mcp-server-go/internal/i18n"
+	"mcp-server-go/internal/services"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// lastInitStateKey 上次 initialize_project 的时间 (system_state)
+	lastInitStateKey = "session:last_init_at"
+
+	briefingMemoLimit = 5
+	briefingFactLimit = 3
+	briefingHookLimit = 5
+	briefingFileLimit = 10
+)
+
+// mpmGeneratedFiles 初始化/记录时由 MPM 自身写入的文件，不计入变更文件
+var mpmGeneratedFiles = map[string]bool{
+	"visualize_history.py":  true,
+	"_MPM_PROJECT_RULES.md": true,
+	"dev-log.md":            true,
+}
+
+// markSessionStart 记录本次初始化时间，返回上一次初始化时间 (首次为零值)
+func markSessionStart(ctx context.Context, sm *SessionManager) time.Time {
+	if sm.Memory == nil {
+		return time.Time{}
+	}
+	prev, _ := sm.Memory.GetState(ctx, lastInitStateKey)
+	_ = sm.Memory.SaveState(ctx, lastInitStateKey, time.Now().Format(time.RFC3339), "session")
+	t, _ := time.Parse(time.RFC3339, prev)
+	return t
+}
+
+// sessionBriefing 会话交接简报的素材：文本简报与 task_chain continue 的结构化结果共用
+type sessionBriefing struct {
+	hooks      []core.Hook // open 状态，过期的在前
+	memos      []core.Memo
+	facts      []core.KnownFact
+	files      []services.ChangedFile // 上次会话后修改的文件 (最多 briefingFileLimit 个)
+	filesTotal int
+}
+
+// collectSessionBriefing 读取待办钩子、最近备忘与铁律、上次会话后修改的文件
+func collectSessionBriefing(ctx context.Context, sm *SessionManager) *sessionBriefing {
+	b := &sessionBriefing{}
+	if sm.Memory != nil {
+		if hooks, err := sm.Memory.ListHooks(ctx, "open"); err == nil {
+			// 过期的排在前面，其余保持创建时间倒序
+			now := time.Now()
+			sort.SliceStable(hooks, func(i, j int) bool {
+				ei := hooks[i].ExpiresAt.Valid && now.After(hooks[i].ExpiresAt.Time)
+				ej := hooks[j].ExpiresAt.Valid && now.After(hooks[j].ExpiresAt.Time)
+				return ei && !ej
+			})
+			b.hooks = hooks
+		}
+		b.memos, _ = sm.Memory.QueryMemos(ctx, "", "", briefingMemoLimit)
+		b.facts, _ = sm.Memory.QueryFacts(ctx, "", briefingFactLimit)
+	}
+	if !sm.PrevSessionAt.IsZero() && sm.ProjectRoot != "" {
+		files, total, err := services.ChangedFilesSince(sm.ProjectRoot, sm.PrevSessionAt, briefingFileLimit, mpmGeneratedFiles)
+		if err == nil {
+			b.files, b.filesTotal = files, total
+		}
+	}
+	return b
+}
+
+// sessionBriefingData 会话交接简报的结构化结果 (output_format=json)
+type sessionBriefingData struct {
+	PrevSessionAt     string                 `json:"prev_session_at,omitempty"`
+	Hooks             []hookView             `json:"hooks"`
+	Memos             []recallMemo           `json:"memos"`
+	Facts             []recallFact           `json:"facts"`
+	ChangedFiles      []services.ChangedFile `json:"changed_files"`
+	ChangedFilesTotal int                    `json:"changed_files_total"`
+}
+
+func (b *sessionBriefing) data(sm *SessionManager) *sessionBriefingData {
+	recall := newRecallData("", "", b.facts, b.memos)
+	data := &sessionBriefingData{
+		Hooks:             hookViews(b.hooks),
+		Memos:             recall.Memos,
+		Facts:             recall.Facts,
+		ChangedFiles:      b.files,
+		ChangedFilesTotal: b.filesTotal,
+	}
+	if data.ChangedFiles == nil {
+		data.ChangedFiles = []services.ChangedFile{}
+	}
+	if !sm.PrevSessionAt.IsZero() {
+		data.PrevSessionAt = sm.PrevSessionAt.Format(time.RFC3339)
+	}
+	return data
+}
+
+// buildSessionBriefing 会话交接简报：进行中的任务链、待办钩子、最近备忘与铁律、上次会话后修改的文件
+func buildSessionBriefing(ctx context.Context, sm *SessionManager) string {
+	b := collectSessionBriefing(ctx, sm)
+
+	var sb strings.Builder
+	sb.WriteString(i18n.T("### 📋 会话交接\n\n"))
+	if sm.PrevSessionAt.IsZero() {
+		sb.WriteString(i18n.T("**上次会话**: 无记录（首次初始化）\n"))
+	} else {
+		sb.WriteString(i18n.T("**上次会话**: %s (%s)\n", sm.PrevSessionAt.Format("2006-01-02 15:04"), humanizeSince(sm.PrevSessionAt)))
+	}
+
+	empty := true
+	for _, section := range []string{
+		briefingChains(sm),
+		briefingHooks(b.hooks),
+		briefingMemos(b.memos),
+		briefingFacts(sm, b.facts),
+		briefingChangedFiles(b.files, b.filesTotal),
+	} {
+		if section != "" {
+			sb.WriteString("\n" + section)
+			empty = false
+		}
+	}
+
+	if empty {
+		sb.WriteString(i18n.T("\n✅ 没有进行中的任务链、待办或历史记录，可以直接开始新任务。\n"))
+	}
+	return sb.String()
+}
+
+func briefingChains(sm *SessionManager) string {
+	var running []*TaskChainV2
+	for _, chain := range sm.TaskChainsV2 {
+		if chain.Status != "finished" {
+			running = append(running, chain)
+		}
+	}
+	if len(running) == 0 {
+		return ""
+	}
+	sort.Slice(running, func(i, j int) bool { return running[i].TaskID < running[j].TaskID })
+
+	var sb strings.Builder
+	sb.WriteString(i18n.T("#### 🔗 进行中的任务链 (%d)\n", len(running)))
+	for _, chain := range running {
+		done, total := chainProgress(chain)
+		desc := ""
+		if chain.Description != "" {
+			desc = " " + truncateLine(chain.Description, 60)
+		}
+		sb.WriteString(i18n.T("- **%s**%s — %d/%d 完成，当前: %s\n", chain.TaskID, desc, done, total, currentStepLabel(chain)))
+		sb.WriteString(fmt.Sprintf("  → `%s`\n", chainNextAction(chain)))
+	}
+	return sb.String()
+}
+
+func briefingHooks(hooks []core.Hook) string {
+	if len(hooks) == 0 {
+		return ""
+	}
+
+	now := time.Now()
+	expired := 0
+	for _, h := range hooks {
+		if h.ExpiresAt.Valid && now.After(h.ExpiresAt.Time) {
+			expired++
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(i18n.T("#### 🪝 待办钩子 (open %d，已过期 %d)\n", len(hooks), expired))
+	for i, h := range hooks {
+		if i >= briefingHookLimit {
+			sb.WriteString(i18n.T("- ... 还有 %d 个，用 `manager_list_hooks` 查看\n", len(hooks)-i))
+			break
+		}
+		displayID := h.Summary
+		if displayID == "" {
+			displayID = h.HookID
+		}
+		mark := ""
+		if h.ExpiresAt.Valid && now.After(h.ExpiresAt.Time) {
+			mark = "⏰ "
+		}
+		task := ""
+		if h.RelatedTaskID != "" {
+			task = fmt.Sprintf(" [Task: %s]", h.RelatedTaskID)
+		}
+		sb.WriteString(fmt.Sprintf("- %s**%s** [%s]%s %s\n", mark, displayID, h.Priority, task, truncateLine(h.Description, 80)))
+	}
+	return sb.String()
+}
+
+func briefingMemos(memos []core.Memo) string {
+	if len(memos) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString(i18n.T("#### 📝 最近备忘 (%d)\n", len(memos)))
+	for _, m := range memos {
+		sb.WriteString(fmt.Sprintf(formatMemo, m.ID, m.Timestamp.Format("2006-01-02 15:04"), m.Category, m.Act, truncateLine(m.Content, 100)))
+	}
+	return sb.String()
+}
+
+func briefingFacts(sm *SessionManager, facts []core.KnownFact) string {
+	if len(facts) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString(i18n.T("#### 📌 最近铁律 (%d)\n", len(facts)))
+	for _, f := range facts {
+		line := fmt.Sprintf(formatFact, f.Type, truncateLine(f.Summarize, 100), f.ID, f.CreatedAt.Format("2006-01-02"))
+		if !sm.PrevSessionAt.IsZero() && f.CreatedAt.After(sm.PrevSessionAt) {
+			line = "- 🆕 " + strings.TrimPrefix(line, "- ")
+		}
+		sb.WriteString(line)
+	}
+	return sb.String()
+}
+
+func briefingChangedFiles(files []services.ChangedFile, total int) string {
+	if total == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString(i18n.T("#### 📄 上次会话后修改的文件 (%d)\n", total))
+	for _, f := range files {
+		sb.WriteString(fmt.Sprintf("- %s _(%s)_\n", f.Path, f.ModTime.Format("01-02 15:04")))
+	}
+	if total > len(files) {
+		sb.WriteString(i18n.T("- ... 还有 %d 个\n", total-len(files)))
+	}
+	return sb.String()
+}
+
+// humanizeSince 粗略描述距今时长
+func humanizeSince(t time.Time) string {
+	d := time.Since(t)
+	switch {
+	case d < time.Hour:
+		return i18n.T("%d 分钟前", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return i18n.T("%d 小时前", int(d.Hours()))
+	default:
+		return i18n.T("%d 天前", int(d.Hours()/24))
+	}
+}
@@ -0,0 +1,121 @@
+package tools
+
+import (
+	"context"
+	"mcp-server-go/internal/core"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTaskChainPersistAndBriefing(t *testing.T) {
+	root := t.TempDir()
+	mem, err := core.NewMemoryLayer(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	sm := &SessionManager{Memory: mem, ProjectRoot: root, TaskChainsV2: make(map[string]*TaskChainV2)}
+	if _, err := initTaskChainV2(sm, "refactor_auth", "拆分登录模块", []map[string]interface{}{
+		{"name": "分析依赖", "input": "auth/", "output": "依赖清单"},
+		{"name": "拆分文件", "input": "依赖清单", "output": "新模块"},
+	}, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := completeStepV2(sm, "refactor_auth", 1, "依赖已梳理"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := initTaskChainV2(sm, "done_task", "", []map[string]interface{}{{"name": "唯一步骤"}}, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := finishChain(sm, "done_task"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mem.CreateHook(ctx, "补充登录单测", "high", "", "refactor_auth", 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mem.SaveFact(ctx, "铁律", "登录接口必须幂等"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mem.AddMemos(ctx, []core.Memo{{Category: "重构", Entity: "auth", Act: "拆分", Path: "auth/login.go", Content: "拆出 token 校验"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 模拟新会话：内存状态清空，只剩记忆层
+	fresh := &SessionManager{Memory: mem, ProjectRoot: root}
+	if n := restoreTaskChainsV2(ctx, fresh); n != 1 {
+		t.Fatalf("restored %d chains, want 1 (finished chains are skipped)", n)
+	}
+	chain := fresh.TaskChainsV2["refactor_auth"]
+	if chain == nil {
+		t.Fatal("refactor_auth not restored")
+	}
+	if done, total := chainProgress(chain); done != 1 || total != 2 {
+		t.Fatalf("progress = %d/%d", done, total)
+	}
+
+	briefing := buildSessionBriefing(ctx, fresh)
+	for _, want := range []string{"refactor_auth", "1/2", "Step 2.0 拆分文件", `mode="start"`, "补充登录单测", "无记录"} {
+		if !strings.Contains(briefing, want) {
+			t.Errorf("briefing missing %q:\n%s", want, briefing)
+		}
+	}
+	if strings.Contains(briefing, "done_task") {
+		t.Errorf("finished chain should not appear:\n%s", briefing)
+	}
+
+	res, err := continueExecution(ctx, fresh)
+	if err != nil || res.IsError {
+		t.Fatalf("continue failed: %v %+v", err, res)
+	}
+	// continue 的结构化结果带上与文本简报相同的钩子、备忘、铁律与变更文件
+	data := newTaskChainData(ctx, fresh, TaskChainArgs{Mode: "continue"})
+	if len(data.Chains) != 1 || data.Briefing == nil {
+		t.Fatalf("continue data = %+v", data)
+	}
+	if b := data.Briefing; len(b.Hooks) != 1 || b.Hooks[0].Description != "补充登录单测" ||
+		len(b.Memos) != 1 || b.Memos[0].Content != "拆出 token 校验" ||
+		len(b.Facts) != 1 || b.Facts[0].Summary != "登录接口必须幂等" || b.ChangedFiles == nil {
+		t.Fatalf("continue briefing data = %+v", b)
+	}
+	if res, _ := continueExecution(ctx, &SessionManager{}); !res.IsError {
+		t.Fatal("continue without init should error")
+	}
+}
+
+func TestMarkSessionStartAndChangedFiles(t *testing.T) {
+	root := t.TempDir()
+	mem, err := core.NewMemoryLayer(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	sm := &SessionManager{Memory: mem, ProjectRoot: root}
+
+	if prev := markSessionStart(ctx, sm); !prev.IsZero() {
+		t.Fatalf("first session prev = %v", prev)
+	}
+	sm.PrevSessionAt = markSessionStart(ctx, sm)
+	if sm.PrevSessionAt.IsZero() {
+		t.Fatal("second session should see the previous init time")
+	}
+
+	// 回拨基准，让下面的写入落在“上次会话之后”
+	sm.PrevSessionAt = time.Now().Add(-time.Minute)
+	_ = os.WriteFile(filepath.Join(root, "main.go"), []byte("package main\n"), 0644)
+	_ = os.WriteFile(filepath.Join(root, "dev-log.md"), []byte("# log\n"), 0644)
+
+	briefing := buildSessionBriefing(ctx, sm)
+	if !strings.Contains(briefing, "main.go") {
+		t.Errorf("changed file missing:\n%s", briefing)
+	}
+	if strings.Contains(briefing, "dev-log.md") || strings.Contains(briefing, ".mcp-data") {
+		t.Errorf("generated files should be skipped:\n%s", briefing)
+	}
+	if data := collectSessionBriefing(ctx, sm).data(sm); data.ChangedFilesTotal != 1 || data.ChangedFiles[0].Path != "main.go" || data.PrevSessionAt == "" {
+		t.Errorf("changed files data = %+v", data)
+	}
+}
@@ -0,0 +1,703 @@
+package tools
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mcp-server-go/internal/i18n"
+	"mcp-server-go/internal/services"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	skillScopeProject = "project"
+	skillScopeGlobal  = "global"
+
+	// skillInstallManifest 安装记录，放在技能目录内
+	skillInstallManifest = ".mpm-install.json"
+	// maxSkillPackageBytes 解压后的总大小上限，防止压缩炸弹
+	maxSkillPackageBytes = 64 << 20
+)
+
+var (
+	skillNamePattern    = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+	skillVersionPattern = regexp.MustCompile(`^v?(\d+)(?:\.(\d+))?(?:\.(\d+))?(?:-([0-9A-Za-z.-]+))?$`)
+	skillFrontmatterRe  = regexp.MustCompile(`(?s)^---\s*\n(.*?)\n---\s*(?:\n|$)`)
+	// skillResourceDirs 技能包约定的资源目录
+	skillResourceDirs = map[string]bool{"references": true, "scripts": true, "assets": true}
+)
+
+// SkillManageArgs 技能包管理参数
+type SkillManageArgs struct {
+	Mode   string `json:"mode" jsonschema:"required,enum=install,enum=uninstall,enum=versions,enum=validate,description=操作模式"`
+	Source string `json:"source" jsonschema:"description=技能包路径：目录、.zip、.tar、.tar.gz/.tgz (install/validate)"`
+	Name   string `json:"name" jsonschema:"description=技能名 (uninstall)"`
+	Scope  string `json:"scope" jsonschema:"enum=project,enum=global,description=安装范围：project (项目 skills/，默认) 或 global (~/.mpm/skills)"`
+	Force  bool   `json:"force" jsonschema:"description=允许降级或重装相同版本"`
+}
+
+// SkillInstallRecord 安装记录
+type SkillInstallRecord struct {
+	Name        string `json:"name"`
+	Version     string `json:"version"`
+	Source      string `json:"source"`
+	InstalledAt string `json:"installed_at"`
+}
+
+// skillValidation 技能包校验结果
+type skillValidation struct {
+	Meta     SkillMetadata `json:"metadata"`
+	Valid    bool          `json:"valid"`
+	Errors   []string      `json:"errors,omitempty"`
+	Warnings []string      `json:"warnings,omitempty"`
+}
+
+// skillInstallResult install 模式的结构化结果
+type skillInstallResult struct {
+	SkillInstallRecord
+	Scope    string   `json:"scope"`
+	Path     string   `json:"path"`
+	Previous string   `json:"previous,omitempty"` // 被替换的旧版本
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// skillScopeRecords versions 模式下一个范围内的已安装技能
+type skillScopeRecords struct {
+	Scope  string               `json:"scope"`
+	Dir    string               `json:"dir"`
+	Skills []SkillInstallRecord `json:"skills"`
+}
+
+func (v *skillValidation) ok() bool { return len(v.Errors) == 0 }
+
+func wrapSkillManage(sm *SessionManager) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var args SkillManageArgs
+		if err := request.BindArguments(&args); err != nil {
+			return mcp.NewToolResultError(i18n.T("参数错误: %v", err)), nil
+		}
+
+		var (
+			out  string
+			data any
+			err  error
+		)
+		switch args.Mode {
+		case "install":
+			out, data, err = installSkill(sm, args.Source, args.Scope, args.Force)
+		case "uninstall":
+			out, data, err = uninstallSkill(sm, args.Name, args.Scope)
+		case "versions":
+			out, data, err = listInstalledSkills(sm, args.Scope)
+		case "validate":
+			out, data, err = validateSkillSource(args.Source)
+		default:
+			err = errors.New(i18n.T("未知模式: %s (可选 install/uninstall/versions/validate)", args.Mode))
+		}
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return mcp.NewToolResultStructured(data, out), nil
+	}
+}
+
+// skillScopeDir 返回安装范围对应的技能目录
+func skillScopeDir(sm *SessionManager, scope string) (string, error) {
+	switch scope {
+	case "", skillScopeProject:
+		if sm.ProjectRoot == "" {
+			return "", errors.New(i18n.T("项目尚未初始化，无法安装到 project 范围"))
+		}
+		return filepath.Join(sm.ProjectRoot, "skills"), nil
+	case skillScopeGlobal:
+		dir := services.GlobalConfigDir()
+		if dir == "" {
+			return "", errors.New(i18n.T("无法确定全局目录，请设置 MPM_HOME"))
+		}
+		return filepath.Join(dir, "skills"), nil
+	default:
+		return "", errors.New(i18n.T("未知范围: %s (可选 project/global)", scope))
+	}
+}
+
+func installSkill(sm *SessionManager, source, scope string, force bool) (string, *skillInstallResult, error) {
+	if strings.TrimSpace(source) == "" {
+		return "", nil, errors.New(i18n.T("请提供 source"))
+	}
+	if scope == "" {
+		scope = skillScopeProject
+	}
+	scopeDir, err := skillScopeDir(sm, scope)
+	if err != nil {
+		return "", nil, err
+	}
+	if err := os.MkdirAll(scopeDir, 0755); err != nil {
+		return "", nil, err
+	}
+
+	// 解压/复制到范围目录下的临时目录，校验通过后再整体替换
+	staging, err := os.MkdirTemp(scopeDir, ".install-")
+	if err != nil {
+		return "", nil, err
+	}
+	defer os.RemoveAll(staging)
+
+	pkgDir, err := unpackSkillSource(source, staging)
+	if err != nil {
+		return "", nil, err
+	}
+	v := validateSkillPackage(pkgDir)
+	if !v.ok() {
+		return "", nil, errors.New(i18n.T("技能包校验失败:\n- %s", strings.Join(v.Errors, "\n- ")))
+	}
+	meta := v.Meta
+	version := meta.Version
+	if version == "" {
+		version = "0.0.0"
+	}
+
+	target := filepath.Join(scopeDir, meta.Name)
+	prev := ""
+	if _, err := os.Stat(target); err == nil {
+		prev = installedSkillVersion(target)
+		if cmp := compareSkillVersion(version, prev); cmp <= 0 && !force {
+			if cmp == 0 {
+				return "", nil, errors.New(i18n.T("%s@%s 已安装，使用 force=true 重新安装", meta.Name, prev))
+			}
+			return "", nil, errors.New(i18n.T("拒绝降级 %s: 已安装 %s，待安装 %s (使用 force=true 强制)", meta.Name, prev, version))
+		}
+	}
+
+	absSource, _ := filepath.Abs(source)
+	record := SkillInstallRecord{
+		Name:        meta.Name,
+		Version:     version,
+		Source:      absSource,
+		InstalledAt: time.Now().Format("2006-01-02 15:04:05"),
+	}
+	data, _ := json.MarshalIndent(record, "", "  ")
+	if err := os.WriteFile(filepath.Join(pkgDir, skillInstallManifest), data, 0644); err != nil {
+		return "", nil, err
+	}
+
+	// 旧版本先挪开，替换失败时还原
+	backup := ""
+	if prev != "" {
+		backup = filepath.Join(staging, "previous")
+		if err := os.Rename(target, backup); err != nil {
+			return "", nil, errors.New(i18n.T("移除旧版本失败: %v", err))
+		}
+	}
+	if err := os.Rename(pkgDir, target); err != nil {
+		if backup != "" {
+			_ = os.Rename(backup, target)
+		}
+		return "", nil, errors.New(i18n.T("安装失败: %v", err))
+	}
+	sm.invalidateSkills()
+
+	var sb strings.Builder
+	if prev != "" {
+		sb.WriteString(i18n.T("✅ 已将 %s 从 %s 更新到 %s (%s)\n", meta.Name, prev, version, scope))
+	} else {
+		sb.WriteString(i18n.T("✅ 已安装 %s@%s (%s)\n", meta.Name, version, scope))
+	}
+	sb.WriteString(fmt.Sprintf("📂 %s\n", target))
+	for _, w := range v.Warnings {
+		sb.WriteString("⚠️ " + w + "\n")
+	}
+	sb.WriteString(i18n.T("\n> 使用 `skill_load(name=\"%s\")` 加载。", meta.Name))
+	return sb.String(), &skillInstallResult{SkillInstallRecord: record, Scope: scope, Path: target, Previous: prev, Warnings: v.Warnings}, nil
+}
+
+func uninstallSkill(sm *SessionManager, name, scope string) (string, map[string]any, error) {
+	if strings.TrimSpace(name) == "" {
+		return "", nil, errors.New(i18n.T("请提供 name"))
+	}
+	if scope == "" {
+		scope = skillScopeProject
+	}
+	scopeDir, err := skillScopeDir(sm, scope)
+	if err != nil {
+		return "", nil, err
+	}
+	dir := findSkillDir(scopeDir, name)
+	if dir == "" {
+		return "", nil, errors.New(i18n.T("%s 范围内未安装技能 %s", scope, name))
+	}
+	version := installedSkillVersion(dir)
+	if err := os.RemoveAll(dir); err != nil {
+		return "", nil, errors.New(i18n.T("卸载失败: %v", err))
+	}
+	sm.invalidateSkills()
+	data := map[string]any{"name": name, "version": version, "scope": scope}
+	return i18n.T("🗑️ 已卸载 %s@%s (%s)", name, version, scope), data, nil
+}
+
+// findSkillDir 在范围目录中按目录名或 frontmatter name 查找技能
+func findSkillDir(scopeDir, name string) string {
+	entries, err := os.ReadDir(scopeDir)
+	if err != nil {
+		return ""
+	}
+	for _, e := range entries {
+		if !e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		dir := filepath.Join(scopeDir, e.Name())
+		if e.Name() == name {
+			return dir
+		}
+		if file := skillDocPath(dir); file != "" {
+			if content, err := os.ReadFile(file); err == nil && parseFrontmatter(string(content)).Name == name {
+				return dir
+			}
+		}
+	}
+	return ""
+}
+
+func listInstalledSkills(sm *SessionManager, scope string) (string, []skillScopeRecords, error) {
+	scopes := []string{skillScopeProject, skillScopeGlobal}
+	if scope != "" {
+		scopes = []string{scope}
+	}
+
+	var (
+		sb   strings.Builder
+		data []skillScopeRecords
+	)
+	sb.WriteString(i18n.T("#### 📦 已安装技能\n"))
+	for _, sc := range scopes {
+		dir, err := skillScopeDir(sm, sc)
+		if err != nil {
+			if scope != "" {
+				return "", nil, err
+			}
+			continue
+		}
+		records := readInstalledSkills(dir)
+		data = append(data, skillScopeRecords{Scope: sc, Dir: dir, Skills: records})
+		sb.WriteString(i18n.T("\n**%s** (%s): %d 个\n", sc, dir, len(records)))
+		for _, r := range records {
+			origin := i18n.T("手动放置")
+			if r.InstalledAt != "" {
+				origin = i18n.T("%s 安装自 %s", r.InstalledAt, r.Source)
+			}
+			sb.WriteString(fmt.Sprintf("- %s@%s — %s\n", r.Name, fallback(r.Version, i18n.T("未标注")), origin))
+		}
+	}
+	return sb.String(), data, nil
+}
+
+// readInstalledSkills 读取范围目录中的技能及安装记录 (手动放置的技能没有安装时间)
+func readInstalledSkills(scopeDir string) []SkillInstallRecord {
+	entries, err := os.ReadDir(scopeDir)
+	if err != nil {
+		return nil
+	}
+	var records []SkillInstallRecord
+	for _, e := range entries {
+		if !e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		dir := filepath.Join(scopeDir, e.Name())
+		if rec, ok := readInstallRecord(dir); ok {
+			records = append(records, rec)
+			continue
+		}
+		file := skillDocPath(dir)
+		if file == "" {
+			continue
+		}
+		rec := SkillInstallRecord{Name: e.Name()}
+		if content, err := os.ReadFile(file); err == nil {
+			meta := parseFrontmatter(string(content))
+			if meta.Name != "" {
+				rec.Name = meta.Name
+			}
+			rec.Version = meta.Version
+		}
+		records = append(records, rec)
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].Name < records[j].Name })
+	return records
+}
+
+func readInstallRecord(dir string) (SkillInstallRecord, bool) {
+	var rec SkillInstallRecord
+	data, err := os.ReadFile(filepath.Join(dir, skillInstallManifest))
+	if err != nil || json.Unmarshal(data, &rec) != nil {
+		return rec, false
+	}
+	return rec, true
+}
+
+// installedSkillVersion 已安装版本：优先安装记录，其次 frontmatter
+func installedSkillVersion(dir string) string {
+	if rec, ok := readInstallRecord(dir); ok && rec.Version != "" {
+		return rec.Version
+	}
+	if file := skillDocPath(dir); file != "" {
+		if content, err := os.ReadFile(file); err == nil {
+			if v := parseFrontmatter(string(content)).Version; v != "" {
+				return v
+			}
+		}
+	}
+	return "0.0.0"
+}
+
+func validateSkillSource(source string) (string, *skillValidation, error) {
+	if strings.TrimSpace(source) == "" {
+		return "", nil, errors.New(i18n.T("请提供 source"))
+	}
+	staging, err := os.MkdirTemp("", "mpm-skill-")
+	if err != nil {
+		return "", nil, err
+	}
+	defer os.RemoveAll(staging)
+
+	pkgDir, err := unpackSkillSource(source, staging)
+	if err != nil {
+		return "", nil, err
+	}
+	v := validateSkillPackage(pkgDir)
+
+	var sb strings.Builder
+	if v.ok() {
+		sb.WriteString(i18n.T("✅ 技能包有效: %s@%s\n", v.Meta.Name, fallback(v.Meta.Version, "0.0.0")))
+	} else {
+		sb.WriteString(i18n.T("❌ 技能包无效 (%d 个错误)\n", len(v.Errors)))
+		for _, e := range v.Errors {
+			sb.WriteString("- " + e + "\n")
+		}
+	}
+	for _, w := range v.Warnings {
+		sb.WriteString("⚠️ " + w + "\n")
+	}
+	return sb.String(), v, nil
+}
+
+// skillDocPath 技能目录下的 SKILL.md (兼容小写)，不存在时返回空串
+func skillDocPath(dir string) string {
+	for _, name := range []string{"SKILL.md", "skill.md"} {
+		p := filepath.Join(dir, name)
+		if info, err := os.Stat(p); err == nil && !info.IsDir() {
+			return p
+		}
+	}
+	return ""
+}
+
+// validateSkillPackage 校验 frontmatter 与资源目录布局
+func validateSkillPackage(dir string) *skillValidation {
+	v := &skillValidation{}
+	file := skillDocPath(dir)
+	if file == "" {
+		v.Errors = append(v.Errors, i18n.T("缺少 SKILL.md"))
+		return v
+	}
+	content, err := os.ReadFile(file)
+	if err != nil {
+		v.Errors = append(v.Errors, i18n.T("读取 SKILL.md 失败: %v", err))
+		return v
+	}
+	if len(content) >= 3 && content[0] == 0xEF && content[1] == 0xBB && content[2] == 0xBF {
+		content = content[3:]
+	}
+
+	match := skillFrontmatterRe.FindSubmatch(content)
+	if match == nil {
+		v.Errors = append(v.Errors, i18n.T("SKILL.md 缺少 frontmatter (--- 包裹的 YAML 头)"))
+	} else if err := yaml.Unmarshal(match[1], &v.Meta); err != nil {
+		v.Errors = append(v.Errors, i18n.T("frontmatter 解析失败: %v", err))
+	} else {
+		m := v.Meta
+		switch {
+		case m.Name == "":
+			v.Errors = append(v.Errors, i18n.T("frontmatter 缺少 name"))
+		case !skillNamePattern.MatchString(m.Name):
+			v.Errors = append(v.Errors, i18n.T("name %q 只能包含字母、数字、点、下划线和连字符", m.Name))
+		}
+		if strings.TrimSpace(m.Description) == "" {
+			v.Errors = append(v.Errors, i18n.T("frontmatter 缺少 description"))
+		}
+		if m.Version == "" {
+			v.Warnings = append(v.Warnings, i18n.T("未声明 version，按 0.0.0 记录"))
+		} else if !skillVersionPattern.MatchString(m.Version) {
+			v.Errors = append(v.Errors, i18n.T("version %q 不是有效的语义化版本 (如 1.2.0)", m.Version))
+		}
+		for _, t := range m.Trigger {
+			if strings.TrimSpace(t) == "" {
+				v.Errors = append(v.Errors, i18n.T("trigger 中有空项"))
+				break
+			}
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		v.Errors = append(v.Errors, err.Error())
+		return v
+	}
+	for _, e := range entries {
+		name := e.Name()
+		switch {
+		case skillResourceDirs[name] && !e.IsDir():
+			v.Errors = append(v.Errors, i18n.T("%s 应为目录", name))
+		case skillResourceDirs[name]:
+			if files, _ := os.ReadDir(filepath.Join(dir, name)); len(files) == 0 {
+				v.Warnings = append(v.Warnings, i18n.T("%s/ 为空", name))
+			}
+		case e.IsDir() && !strings.HasPrefix(name, "."):
+			v.Warnings = append(v.Warnings, i18n.T("非约定目录 %s/ (约定: references/ scripts/ assets/)，skill_load 不会列出", name))
+		}
+	}
+	_ = filepath.WalkDir(dir, func(p string, d os.DirEntry, err error) error {
+		if err == nil && d.Type()&os.ModeSymlink != 0 {
+			rel, _ := filepath.Rel(dir, p)
+			v.Errors = append(v.Errors, i18n.T("不支持符号链接: %s", filepath.ToSlash(rel)))
+		}
+		return nil
+	})
+	v.Valid = v.ok()
+	return v
+}
+
+// unpackSkillSource 把目录/压缩包展开到 staging，返回技能包根目录
+// 压缩包只有一个顶层目录时，自动进入该目录
+func unpackSkillSource(source, staging string) (string, error) {
+	info, err := os.Stat(source)
+	if err != nil {
+		return "", errors.New(i18n.T("无法读取技能包: %v", err))
+	}
+	dest := filepath.Join(staging, "pkg")
+	lower := strings.ToLower(source)
+	switch {
+	case info.IsDir():
+		err = copySkillDir(source, dest)
+	case strings.HasSuffix(lower, ".zip"):
+		err = extractSkillZip(source, dest)
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"), strings.HasSuffix(lower, ".tar"):
+		err = extractSkillTar(source, dest, !strings.HasSuffix(lower, ".tar"))
+	default:
+		return "", errors.New(i18n.T("不支持的技能包格式: %s (支持目录、.zip、.tar、.tar.gz、.tgz)", filepath.Base(source)))
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if skillDocPath(dest) == "" {
+		entries, _ := os.ReadDir(dest)
+		var dirs []os.DirEntry
+		for _, e := range entries {
+			if !strings.HasPrefix(e.Name(), ".") && !strings.HasPrefix(e.Name(), "__MACOSX") {
+				dirs = append(dirs, e)
+			}
+		}
+		if len(dirs) == 1 && dirs[0].IsDir() {
+			return filepath.Join(dest, dirs[0].Name()), nil
+		}
+	}
+	return dest, nil
+}
+
+// safeJoin 拼接压缩包内路径，拒绝绝对路径与 .. 逃逸
+func safeJoin(dest, name string) (string, error) {
+	name = filepath.FromSlash(name)
+	if filepath.IsAbs(name) || strings.HasPrefix(name, `\`) {
+		return "", errors.New(i18n.T("压缩包包含绝对路径: %s", name))
+	}
+	p := filepath.Join(dest, name)
+	if p != dest && !strings.HasPrefix(p, dest+string(filepath.Separator)) {
+		return "", errors.New(i18n.T("压缩包路径越界: %s", name))
+	}
+	return p, nil
+}
+
+// sizeGuard 累计写入字节，超过上限报错
+type sizeGuard struct{ total int64 }
+
+func (g *sizeGuard) copy(dst io.Writer, src io.Reader) error {
+	n, err := io.Copy(dst, io.LimitReader(src, maxSkillPackageBytes-g.total+1))
+	g.total += n
+	if g.total > maxSkillPackageBytes {
+		return errors.New(i18n.T("技能包解压后超过 %d MB", maxSkillPackageBytes>>20))
+	}
+	return err
+}
+
+func writeSkillFile(path string, mode os.FileMode, r io.Reader, guard *sizeGuard) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode.Perm()|0600)
+	if err != nil {
+		return err
+	}
+	if err := guard.copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func extractSkillZip(src, dest string) error {
+	zr, err := zip.OpenReader(src)
+	if err != nil {
+		return errors.New(i18n.T("打开 zip 失败: %v", err))
+	}
+	defer zr.Close()
+
+	guard := &sizeGuard{}
+	for _, f := range zr.File {
+		p, err := safeJoin(dest, f.Name)
+		if err != nil {
+			return err
+		}
+		mode := f.Mode()
+		switch {
+		case mode.IsDir():
+			if err := os.MkdirAll(p, 0755); err != nil {
+				return err
+			}
+		case mode&os.ModeSymlink != 0:
+			return errors.New(i18n.T("不支持符号链接: %s", f.Name))
+		default:
+			rc, err := f.Open()
+			if err != nil {
+				return err
+			}
+			err = writeSkillFile(p, mode, rc, guard)
+			rc.Close()
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func extractSkillTar(src, dest string, gzipped bool) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if gzipped {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return errors.New(i18n.T("解压 gzip 失败: %v", err))
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	guard := &sizeGuard{}
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.New(i18n.T("读取 tar 失败: %v", err))
+		}
+		p, err := safeJoin(dest, hdr.Name)
+		if err != nil {
+			return err
+		}
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(p, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := writeSkillFile(p, os.FileMode(hdr.Mode), tr, guard); err != nil {
+				return err
+			}
+		case tar.TypeSymlink, tar.TypeLink:
+			return errors.New(i18n.T("不支持符号链接: %s", hdr.Name))
+		}
+		// 其余类型 (pax 头等) 忽略
+	}
+}
+
+func copySkillDir(src, dest string) error {
+	guard := &sizeGuard{}
+	return filepath.WalkDir(src, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(src, p)
+		if d.IsDir() && (d.Name() == ".git" || d.Name() == "node_modules") && rel != "." {
+			return filepath.SkipDir
+		}
+		target := filepath.Join(dest, rel)
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(target, 0755)
+		case d.Type()&os.ModeSymlink != 0:
+			return errors.New(i18n.T("不支持符号链接: %s", filepath.ToSlash(rel)))
+		default:
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			in, err := os.Open(p)
+			if err != nil {
+				return err
+			}
+			defer in.Close()
+			return writeSkillFile(target, info.Mode(), in, guard)
+		}
+	})
+}
+
+// compareSkillVersion 比较语义化版本：a<b 返回 -1，相等 0，a>b 返回 1
+// 无法解析的版本按字符串比较；预发布版本低于同号正式版
+func compareSkillVersion(a, b string) int {
+	ma := skillVersionPattern.FindStringSubmatch(strings.TrimSpace(a))
+	mb := skillVersionPattern.FindStringSubmatch(strings.TrimSpace(b))
+	if ma == nil || mb == nil {
+		return strings.Compare(a, b)
+	}
+	for i := 1; i <= 3; i++ {
+		x, _ := strconv.Atoi(fallback(ma[i], "0"))
+		y, _ := strconv.Atoi(fallback(mb[i], "0"))
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch pa, pb := ma[4], mb[4]; {
+	case pa == pb:
+		return 0
+	case pa == "":
+		return 1
+	case pb == "":
+		return -1
+	default:
+		return strings.Compare(pa, pb)
+	}
+}
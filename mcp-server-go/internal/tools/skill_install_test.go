@@ -0,0 +1,193 @@
+package tools
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeSkillPackage(t *testing.T, dir, version string) {
+	t.Helper()
+	_ = os.MkdirAll(filepath.Join(dir, "scripts"), 0755)
+	doc := "---\nname: sql-tuning\ndescription: 慢查询分析\nversion: " + version + "\ntrigger: [慢查询]\n---\n# SQL\n"
+	if err := os.WriteFile(filepath.Join(dir, "SKILL.md"), []byte(doc), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_ = os.WriteFile(filepath.Join(dir, "scripts", "explain.sh"), []byte("#!/bin/sh\n"), 0755)
+}
+
+// archiveSkill 把目录打包成 zip 或 tar.gz，包内多一层 sql-tuning/ 目录
+func archiveSkill(t *testing.T, src, dest string) {
+	t.Helper()
+	f, err := os.Create(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var add func(name string, data []byte)
+	var closeAll func()
+	if strings.HasSuffix(dest, ".zip") {
+		zw := zip.NewWriter(f)
+		add = func(name string, data []byte) {
+			w, _ := zw.Create(name)
+			_, _ = w.Write(data)
+		}
+		closeAll = func() { zw.Close() }
+	} else {
+		gz := gzip.NewWriter(f)
+		tw := tar.NewWriter(gz)
+		add = func(name string, data []byte) {
+			_ = tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(data)), Typeflag: tar.TypeReg})
+			_, _ = tw.Write(data)
+		}
+		closeAll = func() { tw.Close(); gz.Close() }
+	}
+	_ = filepath.WalkDir(src, func(p string, d os.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			rel, _ := filepath.Rel(src, p)
+			data, _ := os.ReadFile(p)
+			add("sql-tuning/"+filepath.ToSlash(rel), data)
+		}
+		return nil
+	})
+	closeAll()
+}
+
+func TestSkillInstallLifecycle(t *testing.T) {
+	t.Setenv("MPM_HOME", t.TempDir())
+	root := t.TempDir()
+	sm := &SessionManager{ProjectRoot: root}
+
+	src := t.TempDir()
+	writeSkillPackage(t, src, "1.0.0")
+	if out, _, err := installSkill(sm, src, "", false); err != nil || !strings.Contains(out, "sql-tuning@1.0.0") {
+		t.Fatalf("install dir: %v %s", err, out)
+	}
+	installed := filepath.Join(root, "skills", "sql-tuning")
+	if _, ok := readInstallRecord(installed); !ok {
+		t.Fatal("install record missing")
+	}
+	if _, _, err := installSkill(sm, src, "", false); err == nil || !strings.Contains(err.Error(), "force") {
+		t.Fatalf("same version should need force: %v", err)
+	}
+
+	// zip 升级
+	writeSkillPackage(t, src, "1.2.0")
+	zipPath := filepath.Join(t.TempDir(), "sql.zip")
+	archiveSkill(t, src, zipPath)
+	if out, _, err := installSkill(sm, zipPath, "project", false); err != nil || !strings.Contains(out, "从 1.0.0 更新到 1.2.0") {
+		t.Fatalf("upgrade: %v %s", err, out)
+	}
+
+	// tar.gz 降级：默认拒绝，force 允许
+	writeSkillPackage(t, src, "1.1.0")
+	tgzPath := filepath.Join(t.TempDir(), "sql.tgz")
+	archiveSkill(t, src, tgzPath)
+	if _, _, err := installSkill(sm, tgzPath, "project", false); err == nil || !strings.Contains(err.Error(), "拒绝降级") {
+		t.Fatalf("downgrade should be refused: %v", err)
+	}
+	if _, _, err := installSkill(sm, tgzPath, "project", true); err != nil {
+		t.Fatalf("forced downgrade: %v", err)
+	}
+	if v := installedSkillVersion(installed); v != "1.1.0" {
+		t.Fatalf("version = %s", v)
+	}
+	if _, err := os.Stat(filepath.Join(installed, "scripts", "explain.sh")); err != nil {
+		t.Error("resources should be installed")
+	}
+
+	// 全局范围 + 手动放置的技能
+	if _, _, err := installSkill(sm, src, "global", false); err != nil {
+		t.Fatal(err)
+	}
+	manual := filepath.Join(root, "skills", "notes")
+	_ = os.MkdirAll(manual, 0755)
+	_ = os.WriteFile(filepath.Join(manual, "SKILL.md"), []byte("---\nname: notes\ndescription: x\n---\n"), 0644)
+	out, _, _ := listInstalledSkills(sm, "")
+	for _, want := range []string{"**project**", "sql-tuning@1.1.0", "notes@未标注 — 手动放置", "**global**"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("versions missing %q:\n%s", want, out)
+		}
+	}
+
+	if _, _, err := uninstallSkill(sm, "sql-tuning", "project"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(installed); !os.IsNotExist(err) {
+		t.Error("skill dir should be removed")
+	}
+	if _, _, err := uninstallSkill(sm, "sql-tuning", "project"); err == nil {
+		t.Error("second uninstall should fail")
+	}
+}
+
+func TestValidateSkillPackage(t *testing.T) {
+	cases := []struct {
+		name    string
+		doc     string
+		extra   func(dir string)
+		wantErr string // 为空表示应当有效
+	}{
+		{"valid", "---\nname: ok\ndescription: d\nversion: 2.0.0-beta.1\n---\n", nil, ""},
+		{"no frontmatter", "# Title\n", nil, "frontmatter"},
+		{"missing description", "---\nname: ok\n---\n", nil, "description"},
+		{"bad name", "---\nname: bad name\ndescription: d\n---\n", nil, "name"},
+		{"bad version", "---\nname: ok\ndescription: d\nversion: latest\n---\n", nil, "version"},
+		{"trigger not list", "---\nname: ok\ndescription: d\ntrigger:\n  a: b\n---\n", nil, "解析失败"},
+		{"scripts is file", "---\nname: ok\ndescription: d\n---\n", func(dir string) {
+			_ = os.WriteFile(filepath.Join(dir, "scripts"), nil, 0644)
+		}, "scripts 应为目录"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			dir := t.TempDir()
+			_ = os.WriteFile(filepath.Join(dir, "SKILL.md"), []byte(c.doc), 0644)
+			if c.extra != nil {
+				c.extra(dir)
+			}
+			v := validateSkillPackage(dir)
+			got := strings.Join(v.Errors, "; ")
+			if c.wantErr == "" && got != "" || c.wantErr != "" && !strings.Contains(got, c.wantErr) {
+				t.Errorf("errors = %q, want %q", got, c.wantErr)
+			}
+		})
+	}
+}
+
+func TestExtractSkillZipRejectsTraversal(t *testing.T) {
+	zipPath := filepath.Join(t.TempDir(), "evil.zip")
+	f, _ := os.Create(zipPath)
+	zw := zip.NewWriter(f)
+	w, _ := zw.Create("../escape.txt")
+	_, _ = w.Write([]byte("x"))
+	zw.Close()
+	f.Close()
+
+	if err := extractSkillZip(zipPath, filepath.Join(t.TempDir(), "out")); err == nil || !strings.Contains(err.Error(), "越界") {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestCompareSkillVersion(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want int
+	}{
+		{"1.0.0", "1.0.0", 0},
+		{"v1.2", "1.2.0", 0},
+		{"1.10.0", "1.9.3", 1},
+		{"0.9.0", "1.0.0", -1},
+		{"1.0.0-beta", "1.0.0", -1},
+		{"1.0.0-beta.2", "1.0.0-beta.1", 1},
+	}
+	for _, c := range cases {
+		if got := compareSkillVersion(c.a, c.b); got != c.want {
+			t.Errorf("compareSkillVersion(%q, %q) = %d, want %d", c.a, c.b, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package tools
+
+import (
+	"fmt"
+	"math"
+	"mcp-server-go/internal/i18n"
+	"mcp-server-go/internal/services"
+	"sort"
+	"strings"
+)
+
+const (
+	// 评分权重：触发词是作者明确声明的信号，权重最高
+	skillTriggerWeight  = 4.0
+	skillNameWeight     = 2.0
+	skillCategoryWeight = 1.5
+	// 描述词重合按 IDF 累加，封顶避免长描述占便宜
+	skillDescriptionCap = 4.0
+	// 低于该分数的匹配视为噪声
+	skillMinScore = 1.5
+
+	defaultSkillSuggestions = 3
+	defaultSkillSearchLimit = 5
+)
+
+// SkillSuggestion 推荐技能 (写入分析简报)
+type SkillSuggestion struct {
+	Name   string  `json:"name"`
+	Score  float64 `json:"score"`
+	Reason string  `json:"reason"`
+}
+
+// SkillMatch 技能匹配结果
+type SkillMatch struct {
+	Entry   *SkillEntry `json:"skill"`
+	Score   float64     `json:"score"`
+	Reasons []string    `json:"reasons"`
+}
+
+// matchSkills 按触发词、名称/描述词项与分类给技能打分，返回得分降序的匹配
+// intent 可选，仅参与分类匹配 (如 DESIGN 对应 design 分类)
+func matchSkills(entries []SkillEntry, task, intent string, limit int) []SkillMatch {
+	taskLower := strings.ToLower(task)
+	taskTerms := termSet(task)
+	if len(taskTerms) == 0 && strings.TrimSpace(taskLower) == "" {
+		return nil
+	}
+	categoryTerms := termSet(task + " " + intent)
+
+	// 描述词项的文档频率：多数技能都有的词 (use/create/文件) 区分度低
+	docTerms := make([]map[string]bool, len(entries))
+	df := map[string]int{}
+	for i := range entries {
+		meta := entries[i].Metadata
+		docTerms[i] = termSet(meta.Name + " " + meta.Description)
+		for t := range docTerms[i] {
+			df[t]++
+		}
+	}
+	idf := func(t string) float64 {
+		return math.Log(1 + float64(len(entries))/float64(df[t]))
+	}
+	// 超过半数技能都有的词不计分 (技能太少时统计无意义，不过滤)
+	commonTerm := func(t string) bool {
+		return len(entries) >= 4 && df[t]*2 > len(entries)
+	}
+
+	var matches []SkillMatch
+	for i := range entries {
+		entry := &entries[i]
+		meta := entry.Metadata
+		var score float64
+		var reasons []string
+
+		for _, trig := range meta.Trigger {
+			if skillTriggerMatches(trig, taskLower, taskTerms) {
+				score += skillTriggerWeight
+				reasons = append(reasons, i18n.T("触发词 %s", strings.TrimSpace(trig)))
+			}
+		}
+
+		nameTerms := termSet(meta.Name)
+		var nameHits []string
+		for t := range nameTerms {
+			if taskTerms[t] {
+				nameHits = append(nameHits, t)
+			}
+		}
+		if len(nameHits) > 0 {
+			score += skillNameWeight * float64(len(nameHits))
+			sort.Strings(nameHits)
+			reasons = append(reasons, i18n.T("名称 %s", strings.Join(nameHits, "/")))
+		}
+
+		var descScore float64
+		var descHits []string
+		for t := range docTerms[i] {
+			if taskTerms[t] && !nameTerms[t] && !commonTerm(t) {
+				descScore += idf(t)
+				descHits = append(descHits, t)
+			}
+		}
+		if descScore > 0 {
+			score += math.Min(descScore, skillDescriptionCap)
+			sort.Strings(descHits)
+			if len(descHits) > 4 {
+				descHits = descHits[:4]
+			}
+			reasons = append(reasons, i18n.T("描述 %s", strings.Join(descHits, "/")))
+		}
+
+		if cat := strings.ToLower(strings.TrimSpace(meta.Category)); cat != "" && !strings.HasPrefix(cat, "global") {
+			for t := range termSet(cat) {
+				if categoryTerms[t] {
+					score += skillCategoryWeight
+					reasons = append(reasons, i18n.T("分类 %s", meta.Category))
+					break
+				}
+			}
+		}
+
+		if score >= skillMinScore {
+			matches = append(matches, SkillMatch{Entry: entry, Score: math.Round(score*10) / 10, Reasons: reasons})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].Entry.Metadata.Name < matches[j].Entry.Metadata.Name
+	})
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+// skillTriggerMatches 触发词原文出现在任务中，或其词项全部出现 (容忍词形变化)
+func skillTriggerMatches(trigger, taskLower string, taskTerms map[string]bool) bool {
+	trigger = strings.ToLower(strings.TrimSpace(trigger))
+	if trigger == "" {
+		return false
+	}
+	if strings.Contains(taskLower, trigger) {
+		return true
+	}
+	terms := services.ConceptTerms(trigger)
+	if len(terms) == 0 {
+		return false
+	}
+	for _, t := range terms {
+		if !taskTerms[t] {
+			return false
+		}
+	}
+	return true
+}
+
+// suggestSkills 为任务推荐技能；技能库不可用时返回 nil
+func suggestSkills(sm *SessionManager, task, intent string, limit int) []SkillSuggestion {
+	if strings.TrimSpace(task) == "" || sm.ProjectRoot == "" {
+		return nil
+	}
+	reg, err := sm.skillRegistry()
+	if err != nil {
+		return nil
+	}
+	var out []SkillSuggestion
+	for _, m := range matchSkills(reg.List(), task, intent, limit) {
+		out = append(out, SkillSuggestion{
+			Name:   m.Entry.Metadata.Name,
+			Score:  m.Score,
+			Reason: strings.Join(m.Reasons, "; "),
+		})
+	}
+	return out
+}
+
+// renderSkillSuggestions 推荐技能的 Markdown 片段，无推荐时返回空串
+func renderSkillSuggestions(suggestions []SkillSuggestion) string {
+	if len(suggestions) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString(i18n.T("📚 **推荐技能**:\n"))
+	for _, s := range suggestions {
+		sb.WriteString(fmt.Sprintf("- `%s` (%.1f) — %s\n", s.Name, s.Score, s.Reason))
+	}
+	sb.WriteString(i18n.T("> 需要时用 `skill_load(name=\"...\")` 加载专家指导。\n"))
+	return sb.String()
+}
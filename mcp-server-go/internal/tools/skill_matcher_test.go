@@ -0,0 +1,90 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func testSkillEntries() []SkillEntry {
+	mk := func(name, category, desc string, triggers ...string) SkillEntry {
+		return SkillEntry{Metadata: SkillMetadata{Name: name, Category: category, Description: desc, Trigger: triggers}}
+	}
+	return []SkillEntry{
+		mk("webapp-testing", "testing", "Use this skill to test web applications with Playwright and capture screenshots."),
+		mk("architecture", "design", "架构设计与技术方案专家。用于系统设计、技术选型与重构规划。", "架构", "ADR"),
+		mk("pdf", "global", "Use this skill to create, merge and fill PDF documents."),
+		mk("docx", "global", "Use this skill to create and edit Word documents with tracked changes."),
+		mk("go-game-dev", "game", "Build 2D games in Go with Ebitengine.", "ebiten"),
+	}
+}
+
+func TestMatchSkills(t *testing.T) {
+	entries := testSkillEntries()
+	cases := []struct {
+		name   string
+		task   string
+		intent string
+		want   string // 排名第一的技能，空表示无匹配
+	}{
+		{"trigger chinese", "为插件系统写一份 ADR", "", "architecture"},
+		{"trigger beats description", "用 ebiten 写个小游戏", "", "go-game-dev"},
+		{"description idf", "Write Playwright tests for the login page", "", "webapp-testing"},
+		{"name term", "把报表导出成 pdf", "", "pdf"},
+		{"category via intent", "规划缓存层", "DESIGN", "architecture"},
+		{"common words only", "use this to create something", "", ""},
+		{"empty", "", "", ""},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			matches := matchSkills(entries, c.task, c.intent, 3)
+			got := ""
+			if len(matches) > 0 {
+				got = matches[0].Entry.Metadata.Name
+			}
+			if got != c.want {
+				t.Errorf("matchSkills(%q) top = %q, want %q (%+v)", c.task, got, c.want, matches)
+			}
+			for i := 1; i < len(matches); i++ {
+				if matches[i].Score > matches[i-1].Score {
+					t.Errorf("matches not sorted: %+v", matches)
+				}
+			}
+		})
+	}
+}
+
+func TestSkillSearchAndSuggestions(t *testing.T) {
+	t.Setenv("MPM_HOME", t.TempDir())
+	root := t.TempDir()
+	dir := filepath.Join(root, "skills", "sql-tuning")
+	_ = os.MkdirAll(dir, 0755)
+	_ = os.WriteFile(filepath.Join(dir, "SKILL.md"), []byte("---\nname: sql-tuning\ndescription: 慢查询分析与索引优化\ncategory: database\ntrigger: [慢查询, explain]\n---\n# SQL\n"), 0644)
+
+	sm := &SessionManager{ProjectRoot: root}
+
+	suggestions := suggestSkills(sm, "排查订单列表的慢查询", "DEBUG", defaultSkillSuggestions)
+	if len(suggestions) == 0 || suggestions[0].Name != "sql-tuning" || !strings.Contains(suggestions[0].Reason, "触发词 慢查询") {
+		t.Fatalf("suggestions = %+v", suggestions)
+	}
+	if out := renderSkillSuggestions(suggestions); !strings.Contains(out, "`sql-tuning`") {
+		t.Errorf("render = %s", out)
+	}
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]interface{}{"query": "用 EXPLAIN 看执行计划"}
+	res, _ := wrapSkillSearch(sm)(context.Background(), req)
+	if text := getTextResult(t, res); !strings.Contains(text, "**sql-tuning**") {
+		t.Errorf("skill_search = %s", text)
+	}
+
+	req.Params.Arguments = map[string]interface{}{"query": "画一张海报"}
+	res, _ = wrapSkillSearch(sm)(context.Background(), req)
+	if text := getTextResult(t, res); !strings.Contains(text, "未找到") {
+		t.Errorf("unrelated query = %s", text)
+	}
+}
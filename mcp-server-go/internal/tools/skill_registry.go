@@ -0,0 +1,350 @@
+package tools
+
+import (
+	"errors"
+	"fmt"
+	"mcp-server-go/internal/i18n"
+	"mcp-server-go/internal/services"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// skillScopeInstall 随 MPM 分发的技能 (可执行文件旁的 skills/)
+	skillScopeInstall = "install"
+	// skillRegistryCheckInterval 两次变更检查的最小间隔，避免每次调用都遍历目录
+	skillRegistryCheckInterval = time.Second
+)
+
+// skillScopeOrder 优先级从低到高；同名技能取优先级最高的范围
+var skillScopeOrder = []string{skillScopeInstall, skillScopeGlobal, skillScopeProject}
+
+type skillRoot struct {
+	Scope string
+	Dir   string
+}
+
+// SkillConflict 同名技能或目录别名冲突
+type SkillConflict struct {
+	Name     string   `json:"name"`             // 冲突的技能名或别名
+	Alias    bool     `json:"alias"`            // true 表示目录名别名冲突
+	Winner   string   `json:"winner,omitempty"` // 生效者 "scope: 路径"
+	Shadowed []string `json:"shadowed"`         // 被遮蔽者 "scope: 路径"
+}
+
+// skillStamp SKILL.md 与资源目录的修改时间，任一变化即重新解析
+type skillStamp struct {
+	modTime  time.Time
+	size     int64
+	resource string
+}
+
+type skillCacheItem struct {
+	stamp skillStamp
+	entry SkillEntry
+}
+
+// SkillRegistry 会话持有的技能注册表：按 mtime 增量重载，读写加锁
+type SkillRegistry struct {
+	mu          sync.RWMutex
+	projectRoot string
+	roots       []skillRoot
+	items       map[string]skillCacheItem // SKILL.md 路径 -> 解析结果
+	entries     []SkillEntry              // 生效的技能，按名称排序
+	index       map[string]int            // 名称/目录别名 -> entries 下标
+	conflicts   []SkillConflict
+	checkedAt   time.Time
+	loaded      bool
+}
+
+// newSkillRegistry 按 安装目录 < 全局 < 项目 的顺序登记扫描源
+func newSkillRegistry(projectRoot string) *SkillRegistry {
+	var roots []skillRoot
+	if exePath, err := os.Executable(); err == nil {
+		// 可执行文件在 bin/ 下，skills 在 mcp-server-go/ 下
+		roots = append(roots, skillRoot{skillScopeInstall, filepath.Join(filepath.Dir(exePath), "..", "skills")})
+	}
+	if dir := services.GlobalConfigDir(); dir != "" {
+		roots = append(roots, skillRoot{skillScopeGlobal, filepath.Join(dir, "skills")})
+	}
+	if projectRoot != "" {
+		roots = append(roots, skillRoot{skillScopeProject, filepath.Join(projectRoot, "skills")})
+	}
+	return &SkillRegistry{
+		projectRoot: projectRoot,
+		roots:       roots,
+		items:       make(map[string]skillCacheItem),
+	}
+}
+
+// skillRegistry 当前会话的技能注册表；切换项目后重建
+func (sm *SessionManager) skillRegistry() (*SkillRegistry, error) {
+	if sm.ProjectRoot == "" {
+		return nil, errors.New(i18n.T("项目尚未初始化"))
+	}
+	sm.skillsMu.Lock()
+	defer sm.skillsMu.Unlock()
+	if sm.Skills == nil || sm.Skills.projectRoot != sm.ProjectRoot {
+		sm.Skills = newSkillRegistry(sm.ProjectRoot)
+	}
+	return sm.Skills, nil
+}
+
+// invalidateSkills 技能目录被本进程修改后，让下一次访问立即检查变更
+func (sm *SessionManager) invalidateSkills() {
+	sm.skillsMu.Lock()
+	reg := sm.Skills
+	sm.skillsMu.Unlock()
+	if reg != nil {
+		reg.mu.Lock()
+		reg.checkedAt = time.Time{}
+		reg.mu.Unlock()
+	}
+}
+
+// Reload 丢弃缓存，重新解析全部技能
+func (r *SkillRegistry) Reload() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.items = make(map[string]skillCacheItem)
+	r.refreshLocked()
+}
+
+// List 生效的技能 (副本)
+func (r *SkillRegistry) List() []SkillEntry {
+	r.ensureFresh()
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]SkillEntry, len(r.entries))
+	copy(out, r.entries)
+	return out
+}
+
+// Get 按技能名或目录名查找
+func (r *SkillRegistry) Get(name string) (SkillEntry, bool) {
+	r.ensureFresh()
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	i, ok := r.index[name]
+	if !ok {
+		return SkillEntry{}, false
+	}
+	return r.entries[i], true
+}
+
+// Keys 全部可用于查找的名称与别名
+func (r *SkillRegistry) Keys() []string {
+	r.ensureFresh()
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	keys := make([]string, 0, len(r.index))
+	for k := range r.index {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Conflicts 最近一次加载发现的冲突
+func (r *SkillRegistry) Conflicts() []SkillConflict {
+	r.ensureFresh()
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]SkillConflict, len(r.conflicts))
+	copy(out, r.conflicts)
+	return out
+}
+
+func (r *SkillRegistry) ensureFresh() {
+	r.mu.RLock()
+	fresh := r.loaded && time.Since(r.checkedAt) < skillRegistryCheckInterval
+	r.mu.RUnlock()
+	if fresh {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.loaded && time.Since(r.checkedAt) < skillRegistryCheckInterval {
+		return
+	}
+	r.refreshLocked()
+}
+
+// refreshLocked 遍历扫描源，只重新解析 mtime 变化的 SKILL.md；有变化时重建索引
+func (r *SkillRegistry) refreshLocked() {
+	var found []SkillEntry
+	seen := make(map[string]bool)
+	changed := !r.loaded
+
+	for _, root := range r.roots {
+		dirs, err := os.ReadDir(root.Dir)
+		if err != nil {
+			continue
+		}
+		for _, d := range dirs {
+			if !d.IsDir() || strings.HasPrefix(d.Name(), ".") {
+				continue
+			}
+			skillDir := filepath.Join(root.Dir, d.Name())
+			doc := skillDocPath(skillDir)
+			if doc == "" {
+				continue
+			}
+			stamp, ok := stampSkill(skillDir, doc)
+			if !ok {
+				continue
+			}
+			seen[doc] = true
+			item, cached := r.items[doc]
+			if !cached || item.stamp != stamp {
+				entry, err := loadSkillEntry(root, skillDir, doc)
+				if err != nil {
+					continue
+				}
+				item = skillCacheItem{stamp: stamp, entry: entry}
+				r.items[doc] = item
+				changed = true
+			}
+			found = append(found, item.entry)
+		}
+	}
+	for doc := range r.items {
+		if !seen[doc] {
+			delete(r.items, doc)
+			changed = true
+		}
+	}
+
+	r.checkedAt = time.Now()
+	r.loaded = true
+	if changed {
+		r.entries, r.index, r.conflicts = buildSkillIndex(found)
+	}
+}
+
+func stampSkill(skillDir, doc string) (skillStamp, bool) {
+	info, err := os.Stat(doc)
+	if err != nil {
+		return skillStamp{}, false
+	}
+	stamp := skillStamp{modTime: info.ModTime(), size: info.Size()}
+	// 资源目录的 mtime 随文件增删变化
+	var parts []string
+	for _, sub := range []string{"references", "scripts", "assets"} {
+		if di, err := os.Stat(filepath.Join(skillDir, sub)); err == nil {
+			parts = append(parts, fmt.Sprintf("%s=%d", sub, di.ModTime().UnixNano()))
+		}
+	}
+	stamp.resource = strings.Join(parts, ";")
+	return stamp, true
+}
+
+func loadSkillEntry(root skillRoot, skillDir, doc string) (SkillEntry, error) {
+	content, err := os.ReadFile(doc)
+	if err != nil {
+		return SkillEntry{}, err
+	}
+	// 移除 BOM
+	if len(content) >= 3 && content[0] == 0xEF && content[1] == 0xBB && content[2] == 0xBF {
+		content = content[3:]
+	}
+	meta := parseFrontmatter(string(content))
+	if meta.Name == "" {
+		meta.Name = filepath.Base(skillDir)
+	}
+	// 如果是 Global 路径，标记 Category
+	if strings.Contains(root.Dir, "mcp-expert-server") && meta.Category == "global" {
+		meta.Category = "global (shared)"
+	}
+	return SkillEntry{
+		Metadata:  meta,
+		FilePath:  doc,
+		Resources: scanResources(skillDir),
+		Scope:     root.Scope,
+	}, nil
+}
+
+// buildSkillIndex 同名技能取优先级最高的范围 (同一范围内取目录名靠前者)，其余记为冲突；
+// 目录名与技能名不同时作为别名，别名不得覆盖任何技能名或其他别名
+func buildSkillIndex(found []SkillEntry) ([]SkillEntry, map[string]int, []SkillConflict) {
+	rank := make(map[string]int, len(skillScopeOrder))
+	for i, s := range skillScopeOrder {
+		rank[s] = i
+	}
+	groups := make(map[string][]SkillEntry)
+	var names []string
+	for _, e := range found {
+		if _, ok := groups[e.Metadata.Name]; !ok {
+			names = append(names, e.Metadata.Name)
+		}
+		groups[e.Metadata.Name] = append(groups[e.Metadata.Name], e)
+	}
+	sort.Strings(names)
+
+	var entries []SkillEntry
+	var conflicts []SkillConflict
+	for _, name := range names {
+		group := groups[name]
+		// 稳定排序：范围优先级降序，保留同范围内的目录顺序
+		sort.SliceStable(group, func(i, j int) bool { return rank[group[i].Scope] > rank[group[j].Scope] })
+		entries = append(entries, group[0])
+		if len(group) > 1 {
+			c := SkillConflict{Name: name, Winner: skillLocation(group[0])}
+			for _, e := range group[1:] {
+				c.Shadowed = append(c.Shadowed, skillLocation(e))
+			}
+			conflicts = append(conflicts, c)
+		}
+	}
+
+	index := make(map[string]int, len(entries)*2)
+	for i, e := range entries {
+		index[e.Metadata.Name] = i
+	}
+	aliasOwners := make(map[string][]int)
+	for i, e := range entries {
+		if dir := e.DirName(); dir != e.Metadata.Name {
+			aliasOwners[dir] = append(aliasOwners[dir], i)
+		}
+	}
+	aliases := make([]string, 0, len(aliasOwners))
+	for a := range aliasOwners {
+		aliases = append(aliases, a)
+	}
+	sort.Strings(aliases)
+	for _, alias := range aliases {
+		owners := aliasOwners[alias]
+		if owner, taken := index[alias]; taken {
+			c := SkillConflict{Name: alias, Alias: true, Winner: skillLocation(entries[owner])}
+			for _, i := range owners {
+				c.Shadowed = append(c.Shadowed, skillLocation(entries[i]))
+			}
+			conflicts = append(conflicts, c)
+			continue
+		}
+		if len(owners) > 1 {
+			// 多个技能共用同一目录名：别名有歧义，全部不登记
+			c := SkillConflict{Name: alias, Alias: true}
+			for _, i := range owners {
+				c.Shadowed = append(c.Shadowed, skillLocation(entries[i]))
+			}
+			conflicts = append(conflicts, c)
+			continue
+		}
+		index[alias] = owners[0]
+	}
+	return entries, index, conflicts
+}
+
+func skillLocation(e SkillEntry) string {
+	return fmt.Sprintf("%s: %s", e.Scope, filepath.Dir(e.FilePath))
+}
+
+// DirName 技能所在目录名
+func (e SkillEntry) DirName() string {
+	return filepath.Base(filepath.Dir(e.FilePath))
+}
@@ -0,0 +1,141 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func writeSkillDoc(t *testing.T, dir, name, desc string) string {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	doc := filepath.Join(dir, "SKILL.md")
+	if err := os.WriteFile(doc, []byte("---\nname: "+name+"\ndescription: "+desc+"\n---\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+// bumpMtime 把修改时间推后，避免文件系统时间精度导致检测不到变化
+func bumpMtime(t *testing.T, path string) {
+	t.Helper()
+	future := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, future, future); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSkillRegistryScopesAndConflicts(t *testing.T) {
+	global := t.TempDir()
+	t.Setenv("MPM_HOME", global)
+	root := t.TempDir()
+
+	writeSkillDoc(t, filepath.Join(global, "skills", "review"), "review", "全局版")
+	writeSkillDoc(t, filepath.Join(global, "skills", "lint"), "lint", "只在全局")
+	writeSkillDoc(t, filepath.Join(root, "skills", "review"), "review", "项目版")
+	// 目录名 lint 与全局技能名冲突：别名不能覆盖技能名
+	writeSkillDoc(t, filepath.Join(root, "skills", "lint"), "style-check", "目录名与技能名不同")
+	// 两个目录使用同一 name
+	writeSkillDoc(t, filepath.Join(root, "skills", "a-sql"), "sql", "A")
+	writeSkillDoc(t, filepath.Join(root, "skills", "b-sql"), "sql", "B")
+
+	sm := &SessionManager{ProjectRoot: root}
+	reg, err := sm.skillRegistry()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct{ key, wantDesc, wantScope string }{
+		{"review", "项目版", skillScopeProject},
+		{"lint", "只在全局", skillScopeGlobal},
+		{"style-check", "目录名与技能名不同", skillScopeProject},
+		{"sql", "A", skillScopeProject},
+		{"a-sql", "A", skillScopeProject},
+	}
+	for _, c := range cases {
+		e, ok := reg.Get(c.key)
+		if !ok || e.Metadata.Description != c.wantDesc || e.Scope != c.wantScope {
+			t.Errorf("Get(%q) = %+v, %v; want %s/%s", c.key, e.Metadata, ok, c.wantDesc, c.wantScope)
+		}
+	}
+	if _, ok := reg.Get("b-sql"); ok {
+		t.Error("shadowed skill's dir alias should not resolve")
+	}
+
+	var kinds []string
+	for _, c := range reg.Conflicts() {
+		kinds = append(kinds, c.Name)
+		if c.Name == "review" && (!strings.HasPrefix(c.Winner, "project") || !strings.HasPrefix(c.Shadowed[0], "global")) {
+			t.Errorf("review conflict = %+v", c)
+		}
+	}
+	if got := strings.Join(kinds, ","); got != "review,sql,lint" {
+		t.Errorf("conflicts = %s", got)
+	}
+	if sm.skillRegistry(); sm.Skills != reg {
+		t.Error("registry should be reused within a session")
+	}
+}
+
+func TestSkillRegistryReloadsChangedFiles(t *testing.T) {
+	t.Setenv("MPM_HOME", t.TempDir())
+	root := t.TempDir()
+	doc := writeSkillDoc(t, filepath.Join(root, "skills", "demo"), "demo", "v1")
+
+	sm := &SessionManager{ProjectRoot: root}
+	reg, _ := sm.skillRegistry()
+	if e, _ := reg.Get("demo"); e.Metadata.Description != "v1" {
+		t.Fatalf("initial = %+v", e.Metadata)
+	}
+
+	_ = os.WriteFile(doc, []byte("---\nname: demo\ndescription: v2\n---\n"), 0644)
+	bumpMtime(t, doc)
+	writeSkillDoc(t, filepath.Join(root, "skills", "extra"), "extra", "new")
+	_ = os.MkdirAll(filepath.Join(root, "skills", "demo", "scripts"), 0755)
+	_ = os.WriteFile(filepath.Join(root, "skills", "demo", "scripts", "run.sh"), nil, 0755)
+
+	// 检查间隔内不重新扫描；失效后立即生效
+	if e, _ := reg.Get("demo"); e.Metadata.Description != "v1" {
+		t.Errorf("should be throttled, got %q", e.Metadata.Description)
+	}
+	sm.invalidateSkills()
+	e, _ := reg.Get("demo")
+	if e.Metadata.Description != "v2" || len(e.Resources["scripts"]) != 1 {
+		t.Errorf("reloaded = %+v %v", e.Metadata, e.Resources)
+	}
+	if _, ok := reg.Get("extra"); !ok {
+		t.Error("new skill should be picked up")
+	}
+
+	_ = os.RemoveAll(filepath.Join(root, "skills", "extra"))
+	sm.invalidateSkills()
+	if _, ok := reg.Get("extra"); ok {
+		t.Error("removed skill should disappear")
+	}
+
+	// 并发读与失效
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				reg.List()
+				reg.Get("demo")
+				sm.invalidateSkills()
+			}
+		}()
+	}
+	wg.Wait()
+
+	// 切换项目后重建注册表
+	sm.ProjectRoot = t.TempDir()
+	if next, _ := sm.skillRegistry(); next == reg {
+		t.Error("registry should be rebuilt for a new project")
+	}
+}
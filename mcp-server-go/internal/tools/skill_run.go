@@ -0,0 +1,310 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"mcp-server-go/internal/i18n"
+	"mcp-server-go/internal/services"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	defaultSkillRunTimeout = 60
+	maxSkillRunTimeout     = 600
+	// maxSkillRunOutput stdout/stderr 各自保留的字节数
+	maxSkillRunOutput = 64 << 10
+)
+
+// skillRunEnvAllowlist 从宿主进程继承的环境变量，其余一律不传 (避免泄露凭据)
+var skillRunEnvAllowlist = []string{
+	"PATH", "HOME", "USER", "LOGNAME", "SHELL", "LANG", "LC_ALL", "LC_CTYPE", "TERM", "TZ",
+	"TMPDIR", "TEMP", "TMP",
+	// Windows 下进程启动所需
+	"SYSTEMROOT", "SYSTEMDRIVE", "WINDIR", "COMSPEC", "PATHEXT", "USERPROFILE", "APPDATA", "LOCALAPPDATA",
+}
+
+// skillRunEnvDenied 调用方也不能设置的变量 (可劫持解释器加载)
+var skillRunEnvDenied = map[string]bool{
+	"LD_PRELOAD": true, "LD_LIBRARY_PATH": true, "LD_AUDIT": true,
+	"DYLD_INSERT_LIBRARIES": true, "DYLD_LIBRARY_PATH": true,
+	"PYTHONSTARTUP": true, "NODE_OPTIONS": true, "BASH_ENV": true, "ENV": true,
+}
+
+// skillScriptInterpreters 按扩展名选择解释器 (依次尝试)
+var skillScriptInterpreters = map[string][][]string{
+	".py":  {{"python3"}, {"python"}},
+	".sh":  {{"bash"}, {"sh"}},
+	".js":  {{"node"}},
+	".mjs": {{"node"}},
+	".cjs": {{"node"}},
+	".rb":  {{"ruby"}},
+	".ps1": {{"pwsh", "-NoProfile", "-File"}, {"powershell", "-NoProfile", "-ExecutionPolicy", "Bypass", "-File"}},
+}
+
+// SkillRunArgs 技能脚本执行参数
+type SkillRunArgs struct {
+	Skill          string            `json:"skill" jsonschema:"required,description=技能名称"`
+	Script         string            `json:"script" jsonschema:"required,description=scripts/ 下的脚本文件名 (如 with_server.py)"`
+	Args           []string          `json:"args" jsonschema:"description=传给脚本的参数"`
+	Cwd            string            `json:"cwd" jsonschema:"description=工作目录，相对项目根，默认项目根；不能越出项目"`
+	TimeoutSeconds int               `json:"timeout_seconds" jsonschema:"description=超时秒数，默认 60，最大 600"`
+	Env            map[string]string `json:"env" jsonschema:"description=额外环境变量 (宿主环境只继承白名单中的变量)"`
+}
+
+// SkillRunResult 脚本执行结果
+type SkillRunResult struct {
+	Skill           string   `json:"skill"`
+	Script          string   `json:"script"`
+	Command         []string `json:"command"`
+	Cwd             string   `json:"cwd"`
+	ExitCode        int      `json:"exit_code"`
+	TimedOut        bool     `json:"timed_out"`
+	DurationMs      int64    `json:"duration_ms"`
+	Stdout          string   `json:"stdout"`
+	Stderr          string   `json:"stderr"`
+	StdoutTruncated bool     `json:"stdout_truncated,omitempty"`
+	StderrTruncated bool     `json:"stderr_truncated,omitempty"`
+}
+
+func wrapSkillRun(sm *SessionManager) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var args SkillRunArgs
+		if err := request.BindArguments(&args); err != nil {
+			return mcp.NewToolResultError(i18n.T("参数错误: %v", err)), nil
+		}
+		res, err := runSkillScript(ctx, sm, args)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		data, err := json.MarshalIndent(res, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(i18n.T("JSON 序列化失败: %v", err)), nil
+		}
+		return mcp.NewToolResultStructured(res, string(data)), nil
+	}
+}
+
+// runSkillScript 执行技能 scripts/ 下声明的脚本；非零退出码与超时作为结果返回，而非错误
+func runSkillScript(ctx context.Context, sm *SessionManager, args SkillRunArgs) (*SkillRunResult, error) {
+	if sm.ProjectRoot == "" {
+		return nil, errors.New(i18n.T("项目尚未初始化"))
+	}
+	reg, err := sm.skillRegistry()
+	if err != nil {
+		return nil, errors.New(i18n.T("扫描技能库失败: %v", err))
+	}
+	entry, ok := reg.Get(args.Skill)
+	if !ok {
+		return nil, errors.New(i18n.T("未找到技能 %s", args.Skill))
+	}
+
+	script := strings.TrimPrefix(filepath.ToSlash(args.Script), "scripts/")
+	declared := false
+	for _, f := range entry.Resources["scripts"] {
+		if f == script {
+			declared = true
+			break
+		}
+	}
+	if !declared {
+		available := strings.Join(entry.Resources["scripts"], ", ")
+		return nil, errors.New(i18n.T("技能 %s 的 scripts/ 中没有 %s (可用: %s)", args.Skill, args.Script, fallback(available, i18n.T("无"))))
+	}
+	scriptPath := filepath.Join(filepath.Dir(entry.FilePath), "scripts", script)
+
+	cwd, err := resolveSkillRunCwd(sm.ProjectRoot, args.Cwd)
+	if err != nil {
+		return nil, err
+	}
+	command, err := skillScriptCommand(scriptPath)
+	if err != nil {
+		return nil, err
+	}
+	env, err := skillRunEnv(args.Env, sm.ProjectRoot, filepath.Dir(entry.FilePath))
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := args.TimeoutSeconds
+	if timeout <= 0 {
+		timeout = defaultSkillRunTimeout
+	}
+	if timeout > maxSkillRunTimeout {
+		timeout = maxSkillRunTimeout
+	}
+	runCtx, cancel := context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
+	defer cancel()
+
+	command = append(command, args.Args...)
+	cmd := exec.CommandContext(runCtx, command[0], command[1:]...)
+	cmd.Dir = cwd
+	cmd.Env = env
+	// 子进程持有管道时，超时后最多再等 2 秒
+	cmd.WaitDelay = 2 * time.Second
+	stdout := &cappedBuffer{limit: maxSkillRunOutput}
+	stderr := &cappedBuffer{limit: maxSkillRunOutput}
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+
+	// 脚本及其子进程同属一个进程组 (Windows 为 Job Object)，超时时整组结束
+	start := time.Now()
+	release, runErr := startProcessGroup(cmd)
+	if runErr == nil {
+		runErr = cmd.Wait()
+		release()
+	}
+	rel, _ := filepath.Rel(sm.ProjectRoot, cwd)
+	res := &SkillRunResult{
+		Skill:           entry.Metadata.Name,
+		Script:          "scripts/" + script,
+		Command:         command,
+		Cwd:             filepath.ToSlash(rel),
+		DurationMs:      time.Since(start).Milliseconds(),
+		Stdout:          stdout.String(),
+		Stderr:          stderr.String(),
+		StdoutTruncated: stdout.truncated,
+		StderrTruncated: stderr.truncated,
+	}
+
+	var exitErr *exec.ExitError
+	switch {
+	case runCtx.Err() == context.DeadlineExceeded:
+		res.TimedOut = true
+		res.ExitCode = -1
+	case runErr == nil:
+	case errors.As(runErr, &exitErr):
+		res.ExitCode = exitErr.ExitCode()
+	default:
+		return nil, errors.New(i18n.T("启动脚本失败: %v", runErr))
+	}
+	return res, nil
+}
+
+// resolveSkillRunCwd 工作目录必须位于项目内 (含符号链接解析后)
+func resolveSkillRunCwd(root, cwd string) (string, error) {
+	dir := root
+	if strings.TrimSpace(cwd) != "" {
+		rel, err := services.RelativeEditPath(root, cwd)
+		if err != nil {
+			return "", errors.New(i18n.T("工作目录无效: %v", err))
+		}
+		dir = filepath.Join(root, filepath.FromSlash(rel))
+	}
+	info, err := os.Stat(dir)
+	if err != nil || !info.IsDir() {
+		return "", errors.New(i18n.T("工作目录不存在: %s", cwd))
+	}
+	realRoot, err1 := filepath.EvalSymlinks(root)
+	realDir, err2 := filepath.EvalSymlinks(dir)
+	if err1 != nil || err2 != nil {
+		return "", errors.New(i18n.T("无法解析工作目录: %s", cwd))
+	}
+	if realDir != realRoot && !strings.HasPrefix(realDir, realRoot+string(filepath.Separator)) {
+		return "", errors.New(i18n.T("工作目录 %s 不在项目目录内", cwd))
+	}
+	return dir, nil
+}
+
+// skillScriptCommand 按扩展名选择解释器；无已知扩展名时要求脚本本身可执行
+func skillScriptCommand(scriptPath string) ([]string, error) {
+	ext := strings.ToLower(filepath.Ext(scriptPath))
+	if candidates, ok := skillScriptInterpreters[ext]; ok {
+		for _, c := range candidates {
+			if bin, err := exec.LookPath(c[0]); err == nil {
+				cmd := append([]string{bin}, c[1:]...)
+				return append(cmd, scriptPath), nil
+			}
+		}
+		return nil, errors.New(i18n.T("未找到 %s 脚本的解释器 (%s)", ext, candidates[0][0]))
+	}
+	info, err := os.Stat(scriptPath)
+	if err != nil {
+		return nil, err
+	}
+	if runtime.GOOS == "windows" {
+		if ext == ".bat" || ext == ".cmd" || ext == ".exe" {
+			return []string{scriptPath}, nil
+		}
+	} else if info.Mode()&0111 != 0 {
+		return []string{scriptPath}, nil
+	}
+	return nil, errors.New(i18n.T("%s 不是可执行脚本 (支持 .py .sh .js .rb .ps1 或带可执行权限的文件)", filepath.Base(scriptPath)))
+}
+
+// skillRunEnv 白名单继承宿主环境，再叠加调用方变量与 MPM_* 上下文
+func skillRunEnv(extra map[string]string, projectRoot, skillDir string) ([]string, error) {
+	vars := map[string]string{}
+	for _, key := range skillRunEnvAllowlist {
+		if v, ok := lookupEnvFold(key); ok {
+			vars[key] = v
+		}
+	}
+	for k, v := range extra {
+		if k == "" || strings.ContainsAny(k, "=\x00") {
+			return nil, errors.New(i18n.T("环境变量名无效: %q", k))
+		}
+		if skillRunEnvDenied[strings.ToUpper(k)] || strings.HasPrefix(strings.ToUpper(k), "DYLD_") {
+			return nil, errors.New(i18n.T("不允许设置环境变量 %s", k))
+		}
+		vars[k] = v
+	}
+	vars["MPM_PROJECT_ROOT"] = projectRoot
+	vars["MPM_SKILL_DIR"] = skillDir
+
+	keys := make([]string, 0, len(vars))
+	for k := range vars {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	env := make([]string, 0, len(keys))
+	for _, k := range keys {
+		env = append(env, k+"="+vars[k])
+	}
+	return env, nil
+}
+
+// lookupEnvFold Windows 环境变量名不区分大小写
+func lookupEnvFold(key string) (string, bool) {
+	if v, ok := os.LookupEnv(key); ok {
+		return v, true
+	}
+	if runtime.GOOS != "windows" {
+		return "", false
+	}
+	for _, kv := range os.Environ() {
+		if i := strings.IndexByte(kv, '='); i > 0 && strings.EqualFold(kv[:i], key) {
+			return kv[i+1:], true
+		}
+	}
+	return "", false
+}
+
+// cappedBuffer 只保留前 limit 字节，超出部分丢弃并标记
+type cappedBuffer struct {
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.buf.Len(); room < len(p) {
+		if room > 0 {
+			b.buf.Write(p[:room])
+		}
+		b.truncated = true
+		return len(p), nil
+	}
+	return b.buf.Write(p)
+}
+
+func (b *cappedBuffer) String() string { return b.buf.String() }
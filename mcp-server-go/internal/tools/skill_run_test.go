@@ -0,0 +1,99 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunSkillScript(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("依赖 sh")
+	}
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh 不可用")
+	}
+	t.Setenv("MPM_HOME", t.TempDir())
+	t.Setenv("MPM_TEST_SECRET", "leak")
+	root := t.TempDir()
+	scripts := filepath.Join(root, "skills", "demo", "scripts")
+	_ = os.MkdirAll(scripts, 0755)
+	_ = os.MkdirAll(filepath.Join(root, "web"), 0755)
+	_ = os.WriteFile(filepath.Join(root, "skills", "demo", "SKILL.md"), []byte("---\nname: demo\ndescription: d\n---\n"), 0644)
+	_ = os.WriteFile(filepath.Join(scripts, "echo.sh"), []byte(`echo "args=$*"
+echo "cwd=$(basename "$PWD")"
+echo "secret=${MPM_TEST_SECRET:-none} greet=$GREETING skill=$(basename "$MPM_SKILL_DIR")"
+echo oops >&2
+exit 3
+`), 0644)
+	_ = os.WriteFile(filepath.Join(scripts, "slow.sh"), []byte("sleep 5\n"), 0644)
+	_ = os.WriteFile(filepath.Join(scripts, "spawn.sh"), []byte("(sleep 2; touch late.txt) &\nsleep 5\n"), 0644)
+	_ = os.WriteFile(filepath.Join(scripts, "notes.txt"), []byte("x"), 0644)
+
+	sm := &SessionManager{ProjectRoot: root}
+	ctx := context.Background()
+
+	res, err := runSkillScript(ctx, sm, SkillRunArgs{
+		Skill: "demo", Script: "scripts/echo.sh", Args: []string{"a", "b c"}, Cwd: "web",
+		Env: map[string]string{"GREETING": "hi"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"args=a b c", "cwd=web", "secret=none greet=hi skill=demo"} {
+		if !strings.Contains(res.Stdout, want) {
+			t.Errorf("stdout missing %q: %q", want, res.Stdout)
+		}
+	}
+	if res.ExitCode != 3 || strings.TrimSpace(res.Stderr) != "oops" || res.TimedOut {
+		t.Errorf("result = %+v", res)
+	}
+
+	res, err = runSkillScript(ctx, sm, SkillRunArgs{Skill: "demo", Script: "slow.sh", TimeoutSeconds: 1})
+	if err != nil || !res.TimedOut || res.ExitCode != -1 {
+		t.Fatalf("timeout: %+v %v", res, err)
+	}
+
+	// 超时结束整个进程组：脚本派生的子进程不能继续运行
+	res, err = runSkillScript(ctx, sm, SkillRunArgs{Skill: "demo", Script: "spawn.sh", TimeoutSeconds: 1})
+	if err != nil || !res.TimedOut || res.DurationMs >= 2000 {
+		t.Fatalf("timeout with children: %+v %v", res, err)
+	}
+	time.Sleep(2 * time.Second)
+	if _, err := os.Stat(filepath.Join(root, "late.txt")); err == nil {
+		t.Fatal("child process survived the timeout")
+	}
+
+	errCases := []struct {
+		name string
+		args SkillRunArgs
+		want string
+	}{
+		{"unknown skill", SkillRunArgs{Skill: "nope", Script: "echo.sh"}, "未找到技能"},
+		{"undeclared script", SkillRunArgs{Skill: "demo", Script: "../SKILL.md"}, "没有"},
+		{"not executable", SkillRunArgs{Skill: "demo", Script: "notes.txt"}, "不是可执行脚本"},
+		{"cwd escape", SkillRunArgs{Skill: "demo", Script: "echo.sh", Cwd: "../"}, "不在项目目录内"},
+		{"denied env", SkillRunArgs{Skill: "demo", Script: "echo.sh", Env: map[string]string{"LD_PRELOAD": "x"}}, "不允许"},
+	}
+	for _, c := range errCases {
+		t.Run(c.name, func(t *testing.T) {
+			if _, err := runSkillScript(ctx, sm, c.args); err == nil || !strings.Contains(err.Error(), c.want) {
+				t.Errorf("err = %v, want %q", err, c.want)
+			}
+		})
+	}
+}
+
+func TestCappedBuffer(t *testing.T) {
+	b := &cappedBuffer{limit: 5}
+	_, _ = b.Write([]byte("abc"))
+	_, _ = b.Write([]byte("defg"))
+	if b.String() != "abcde" || !b.truncated {
+		t.Errorf("buffer = %q truncated=%v", b.String(), b.truncated)
+	}
+}
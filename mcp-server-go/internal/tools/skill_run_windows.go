@@ -0,0 +1,37 @@
+//go:build windows
+
+package tools
+
+import (
+	"os/exec"
+	"sync/atomic"
+
+	"golang.org/x/sys/windows"
+)
+
+// startProcessGroup 脚本启动后加入 Job Object，取消时终止整个 Job，
+// 避免脚本派生的子进程在超时后继续运行
+func startProcessGroup(cmd *exec.Cmd) (release func(), err error) {
+	job, err := windows.CreateJobObject(nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	var inJob atomic.Bool
+	cmd.Cancel = func() error {
+		if inJob.Load() {
+			return windows.TerminateJobObject(job, 1)
+		}
+		return cmd.Process.Kill() // 未能加入 Job 时退回只结束直接子进程
+	}
+	if err := cmd.Start(); err != nil {
+		windows.CloseHandle(job)
+		return nil, err
+	}
+
+	proc, err := windows.OpenProcess(windows.PROCESS_SET_QUOTA|windows.PROCESS_TERMINATE, false, uint32(cmd.Process.Pid))
+	if err == nil {
+		inJob.Store(windows.AssignProcessToJobObject(job, proc) == nil)
+		windows.CloseHandle(proc)
+	}
+	return func() { windows.CloseHandle(job) }, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"mcp-server-go/internal/i18n"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -29,6 +30,7 @@ type SkillEntry struct {
 	Metadata  SkillMetadata       `json:"metadata"`
 	FilePath  string              `json:"file_path"`
 	Resources map[string][]string `json:"resources"`
+	Scope     string              `json:"scope"` // install | global | project
 }
 
 // SkillLoadArgs 加载技能参数
@@ -39,10 +41,17 @@ type SkillLoadArgs struct {
 	Refresh  bool   `json:"refresh" jsonschema:"description=是否强制刷新缓存"`
 }
 
+// SkillSearchArgs 技能搜索参数
+type SkillSearchArgs struct {
+	Query  string `json:"query" jsonschema:"required,description=任务描述或关键词"`
+	Intent string `json:"intent" jsonschema:"description=可选，任务意图 (如 DESIGN)，参与分类匹配"`
+	Limit  int    `json:"limit" jsonschema:"description=最多返回条数，默认 5"`
+}
+
 // RegisterSkillTools 注册技能库工具
 func RegisterSkillTools(s *server.MCPServer, sm *SessionManager) {
-	s.AddTool(mcp.NewTool("skill_list",
-		mcp.WithDescription(`skill_list - 列出可用技能库 (领域知识)
+	addTool(s, mcp.NewTool("skill_list",
+		mcp.WithDescription(i18n.Desc("skill_list", `skill_list - 列出可用技能库 (领域知识)
 
 用途：
   扫描并列出所有可用的技能（Skill）。技能是针对特定领域或任务的专家级指导文档。
@@ -51,19 +60,20 @@ func RegisterSkillTools(s *server.MCPServer, sm *SessionManager) {
   无
 
 说明：
-  - 会同时扫描项目本地和全局的技能目录。
-  - 项目本地的技能会优先覆盖同名的全局技能。
+  - 会同时扫描 MPM 安装目录、全局 (~/.mpm/skills) 和项目本地的技能目录，并标注每个技能的来源范围。
+  - 同名技能按 项目 > 全局 > 安装目录 取用，被遮蔽的技能与目录名冲突会在列表末尾列出。
+  - SKILL.md 修改后自动重新加载，无需 refresh。
 
 示例：
   skill_list()
     -> 查看所有可用技能及其简要描述
 
 触发词：
-  "mpm 技能列表", "mpm skills"`),
+  "mpm 技能列表", "mpm skills"`)),
 	), wrapSkillList(sm))
 
-	s.AddTool(mcp.NewTool("skill_load",
-		mcp.WithDescription(`skill_load - 加载并阅读技能文档 (专家指导)
+	addTool(s, mcp.NewTool("skill_load",
+		mcp.WithDescription(i18n.Desc("skill_load", `skill_load - 加载并阅读技能文档 (专家指导)
 
 用途：
   【必备】在处理不熟悉的技术或领域时，加载对应的技能文档以获取专家的详细指导和最佳实践。
@@ -80,7 +90,7 @@ func RegisterSkillTools(s *server.MCPServer, sm *SessionManager) {
     指定加载该技能下的特定子资源文件（如 references/manual.md）。
   
   refresh (默认: false)
-    设为 true 以强制刷新技能缓存。
+    设为 true 以丢弃缓存、重新解析全部技能 (一般无需设置，文件变化会自动重载)。
 
 说明：
   - 加载技能后，LLM 必须仔细阅读其内容，严禁在阅读前采取任何实质行动。
@@ -91,137 +101,116 @@ func RegisterSkillTools(s *server.MCPServer, sm *SessionManager) {
     -> 获取重构专家的详细指导
 
 触发词：
-  "mpm 加载技能", "mpm skill", "mpm loadskill"`),
+  "mpm 加载技能", "mpm skill", "mpm loadskill"`)),
 		mcp.WithInputSchema[SkillLoadArgs](),
 	), wrapSkillLoad(sm))
-}
 
-var (
-	skillCache []SkillEntry
-	skillMap   map[string]*SkillEntry
-)
+	addTool(s, mcp.NewTool("skill_search",
+		mcp.WithDescription(i18n.Desc("skill_search", `skill_search - 按任务描述匹配技能
 
-func scanSkills(sm *SessionManager) error {
-	if sm.ProjectRoot == "" {
-		return fmt.Errorf("项目尚未初始化")
-	}
+用途：
+  根据任务描述给每个技能打分并排序，找出最该加载的技能，避免凭名字猜。
 
-	// 定义扫描源 (优先级从低到高：MPM安装目录 < ~/.mpm < 项目本地)
-	// 先扫描优先级低的，后扫描的会覆盖 map 中的同名 Key
-	paths := []string{}
+参数：
+  query (必填)
+    任务描述或关键词。
 
-	// 1. MPM 安装目录下的 skills/ (随 MPM 分发的官方 Skills)
-	if exePath, err := os.Executable(); err == nil {
-		exeDir := filepath.Dir(exePath)
-		// 可执行文件在 bin/ 下，skills 在 mcp-server-go/ 下
-		mpmSkills := filepath.Join(exeDir, "..", "skills")
-		paths = append(paths, mpmSkills)
-	}
+  intent (可选)
+    任务意图 (如 DESIGN、DEBUG)，参与分类匹配。
 
-	// 2. 用户全局 Skills 目录 (~/.mpm/skills/)
-	if home, err := os.UserHomeDir(); err == nil {
-		globalSkills := filepath.Join(home, ".mpm", "skills")
-		paths = append(paths, globalSkills)
-	}
+  limit (默认: 5)
+    最多返回条数。
 
-	// 3. 项目本地 Skills (优先级最高)
-	projectLocal := filepath.Join(sm.ProjectRoot, "skills")
-	paths = append(paths, projectLocal)
+说明：
+  - 评分依据：frontmatter 中的 trigger 触发词 (权重最高)、名称与描述词项 (按区分度加权)、category 分类。
+  - manager_analyze 与 prompt_enhance 会自动附带前 3 个推荐。
 
-	// 临时 Map 用于去重和覆盖
-	tempMap := make(map[string]SkillEntry)
+示例：
+  skill_search(query="给登录页写端到端测试")
+    -> webapp-testing 等相关技能及命中原因
 
-	for _, root := range paths {
-		if _, err := os.Stat(root); os.IsNotExist(err) {
-			continue
-		}
+触发词：
+  "mpm 找技能", "mpm skill search"`)),
+		mcp.WithInputSchema[SkillSearchArgs](),
+	), wrapSkillSearch(sm))
 
-		entries, err := os.ReadDir(root)
-		if err != nil {
-			continue
-		}
+	addTool(s, mcp.NewTool("skill_manage",
+		mcp.WithDescription(i18n.Desc("skill_manage", `skill_manage - 技能包安装、校验与卸载
 
-		for _, entry := range entries {
-			if !entry.IsDir() {
-				continue
-			}
+用途：
+  从本地目录或压缩包安装技能到项目或全局范围，安装前校验 SKILL.md 与资源布局，并记录版本。
 
-			skillDir := filepath.Join(root, entry.Name())
-			skillFile := filepath.Join(skillDir, "SKILL.md")
-			if _, err := os.Stat(skillFile); os.IsNotExist(err) {
-				skillFile = filepath.Join(skillDir, "skill.md")
-				if _, err := os.Stat(skillFile); os.IsNotExist(err) {
-					continue
-				}
-			}
+参数：
+  mode (必填)
+    - install: 安装技能包 (需 source)
+    - uninstall: 卸载技能 (需 name)
+    - versions: 按范围列出已安装技能及版本
+    - validate: 只校验不安装 (需 source)
 
-			content, err := os.ReadFile(skillFile)
-			if err != nil {
-				continue
-			}
+  source (install/validate)
+    技能包路径：目录、.zip、.tar、.tar.gz/.tgz。压缩包内可以多包一层目录。
 
-			// 移除 BOM
-			if len(content) >= 3 && content[0] == 0xEF && content[1] == 0xBB && content[2] == 0xBF {
-				content = content[3:]
-			}
+  scope (默认: project)
+    - project: 项目 skills/ 目录
+    - global: ~/.mpm/skills (可用 MPM_HOME 改目录)
 
-			meta := parseFrontmatter(string(content))
-			if meta.Name == "" {
-				meta.Name = entry.Name()
-			}
+  force (默认: false)
+    允许降级或重装相同版本。
 
-			// 如果是 Global 路径，标记 Category
-			if strings.Contains(root, "mcp-expert-server") && meta.Category == "global" {
-				meta.Category = "global (shared)"
-			}
+说明：
+  - frontmatter 必须包含 name、description；version 需为语义化版本 (如 1.2.0)，未填按 0.0.0 记录。
+  - 资源目录约定为 references/ scripts/ assets/；不支持符号链接。
+  - 安装记录写入技能目录下的 .mpm-install.json。
 
-			resources := scanResources(skillDir)
+示例：
+  skill_manage(mode="install", source="~/Downloads/sql-tuning.zip", scope="global")
+    -> 安装到全局技能库
 
-			skillEntry := SkillEntry{
-				Metadata:  meta,
-				FilePath:  skillFile,
-				Resources: resources,
-			}
+触发词：
+  "mpm 安装技能", "mpm skill install"`)),
+		mcp.WithInputSchema[SkillManageArgs](),
+	), wrapSkillManage(sm))
 
-			// 存入 Map (后扫描的 Local 会覆盖 Global)
-			tempMap[meta.Name] = skillEntry
-			// 同时支持目录名作为 Key (如果与 Name 不同)
-			if entry.Name() != meta.Name {
-				// 注意：如果目录名冲突，也会覆盖。这是预期的。
-				// 但主要 Key 应该是 Name。
-				// 这里为了保持 skill_load 能用目录名索引，我们稍微做点妥协
-				// 只在 Name 不存在时才用目录名? 或者都存?
-			}
-		}
-	}
+	addTool(s, mcp.NewTool("skill_run",
+		mcp.WithDescription(i18n.Desc("skill_run", `skill_run - 执行技能自带的脚本
 
-	// 重建 Cache 和 最终 Map
-	newCache := make([]SkillEntry, 0, len(tempMap))
-	for _, v := range tempMap {
-		newCache = append(newCache, v)
-	}
+用途：
+  运行技能 scripts/ 目录下的辅助脚本 (如 webapp-testing 的 with_server.py)，
+  无需手动拼命令。返回 stdout、stderr 与退出码。
 
-	// 排序
-	sort.Slice(newCache, func(i, j int) bool {
-		return newCache[i].Metadata.Name < newCache[j].Metadata.Name
-	})
-
-	// 重建索引 Map (指向 Cache 中的稳定地址)
-	newMap := make(map[string]*SkillEntry)
-	for i := range newCache {
-		entry := &newCache[i]
-		newMap[entry.Metadata.Name] = entry
-
-		// 尝试从路径获取目录名，也作为索引
-		dirName := filepath.Base(filepath.Dir(entry.FilePath))
-		if dirName != entry.Metadata.Name {
-			newMap[dirName] = entry
-		}
-	}
+参数：
+  skill (必填)
+    技能名称。
+
+  script (必填)
+    scripts/ 下的脚本文件名，只能是 skill_load 列出的脚本。
+
+  args (可选)
+    传给脚本的参数列表。
+
+  cwd (可选)
+    工作目录，相对项目根；默认项目根，不能越出项目。
+
+  timeout_seconds (默认: 60，最大 600)
+    超时后终止脚本，结果中 timed_out=true。
 
-	skillCache = newCache
-	skillMap = newMap
-	return nil
+  env (可选)
+    额外环境变量。宿主环境只继承 PATH、HOME、LANG 等白名单变量；
+    LD_PRELOAD、NODE_OPTIONS 等可劫持解释器的变量不允许设置。
+    脚本可读取 MPM_PROJECT_ROOT 与 MPM_SKILL_DIR。
+
+说明：
+  - 按扩展名选择解释器：.py → python3，.sh → bash，.js → node，.rb → ruby，.ps1 → pwsh。
+  - 输出各保留前 64KB，超出时标记 *_truncated。
+  - 脚本以当前用户权限运行，执行前请确认脚本用途。
+
+返回：
+  JSON：command、cwd、exit_code、timed_out、duration_ms、stdout、stderr
+
+触发词：
+  "mpm 运行技能脚本", "mpm skill run"`)),
+		mcp.WithInputSchema[SkillRunArgs](),
+	), wrapSkillRun(sm))
 }
 
 func parseFrontmatter(content string) SkillMetadata {
@@ -270,20 +259,64 @@ func scanResources(dir string) map[string][]string {
 
 func wrapSkillList(sm *SessionManager) server.ToolHandlerFunc {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		if skillCache == nil {
-			if err := scanSkills(sm); err != nil {
-				return mcp.NewToolResultError(fmt.Sprintf("扫描技能库失败: %v", err)), nil
-			}
+		reg, err := sm.skillRegistry()
+		if err != nil {
+			return mcp.NewToolResultError(i18n.T("扫描技能库失败: %v", err)), nil
 		}
+		skills := reg.List()
 
 		var sb strings.Builder
-		sb.WriteString(fmt.Sprintf("#### 发现 %d 个可用技能\n\n", len(skillCache)))
-		for _, s := range skillCache {
-			sb.WriteString(fmt.Sprintf("- **%s**: %s\n", s.Metadata.Name, s.Metadata.Description))
+		sb.WriteString(i18n.T("#### 发现 %d 个可用技能\n\n", len(skills)))
+		for _, s := range skills {
+			sb.WriteString(fmt.Sprintf("- **%s** [%s]: %s\n", s.Metadata.Name, s.Scope, s.Metadata.Description))
+		}
+		if conflicts := reg.Conflicts(); len(conflicts) > 0 {
+			sb.WriteString(i18n.T("\n**⚠️ 冲突 (%d)**:\n", len(conflicts)))
+			for _, c := range conflicts {
+				sb.WriteString(renderSkillConflict(c))
+			}
+		}
+		sb.WriteString(i18n.T("\n> 使用 `skill_load(name=\"...\")` 加载完整内容。"))
+
+		data := &skillListData{Skills: skills, Conflicts: reg.Conflicts()}
+		return mcp.NewToolResultStructured(data, sb.String()), nil
+	}
+}
+
+func wrapSkillSearch(sm *SessionManager) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var args SkillSearchArgs
+		if err := request.BindArguments(&args); err != nil {
+			return mcp.NewToolResultError(i18n.T("参数错误: %v", err)), nil
+		}
+		if strings.TrimSpace(args.Query) == "" {
+			return mcp.NewToolResultError(i18n.T("请提供 query")), nil
+		}
+		reg, err := sm.skillRegistry()
+		if err != nil {
+			return mcp.NewToolResultError(i18n.T("扫描技能库失败: %v", err)), nil
+		}
+		skills := reg.List()
+
+		limit := args.Limit
+		if limit <= 0 {
+			limit = defaultSkillSearchLimit
+		}
+		matches := matchSkills(skills, args.Query, args.Intent, limit)
+		if len(matches) == 0 {
+			data := &skillSearchData{Query: args.Query, Total: len(skills), Matches: []SkillMatch{}}
+			return mcp.NewToolResultStructured(data, i18n.T("未找到与 \"%s\" 相关的技能 (共 %d 个技能)。可用 `skill_list()` 浏览全部。", args.Query, len(skills))), nil
 		}
-		sb.WriteString("\n> 使用 `skill_load(name=\"...\")` 加载完整内容。")
 
-		return mcp.NewToolResultText(sb.String()), nil
+		var sb strings.Builder
+		sb.WriteString(i18n.T("#### 🔎 技能匹配 (%d/%d)\n\n", len(matches), len(skills)))
+		for i, m := range matches {
+			sb.WriteString(fmt.Sprintf("%d. **%s** (%.1f): %s\n", i+1, m.Entry.Metadata.Name, m.Score, truncateLine(m.Entry.Metadata.Description, 120)))
+			sb.WriteString(i18n.T("   - 命中: %s\n", strings.Join(m.Reasons, "; ")))
+		}
+		sb.WriteString(i18n.T("\n> 使用 `skill_load(name=\"%s\")` 加载最匹配的技能。", matches[0].Entry.Metadata.Name))
+		data := &skillSearchData{Query: args.Query, Total: len(skills), Matches: matches}
+		return mcp.NewToolResultStructured(data, sb.String()), nil
 	}
 }
 
@@ -291,29 +324,32 @@ func wrapSkillLoad(sm *SessionManager) server.ToolHandlerFunc {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		var args SkillLoadArgs
 		if err := request.BindArguments(&args); err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("参数错误: %v", err)), nil
+			return mcp.NewToolResultError(i18n.T("参数错误: %v", err)), nil
 		}
 
-		if args.Refresh || skillCache == nil {
-			if err := scanSkills(sm); err != nil {
-				return mcp.NewToolResultError(fmt.Sprintf("扫描技能库失败: %v", err)), nil
-			}
+		reg, err := sm.skillRegistry()
+		if err != nil {
+			return mcp.NewToolResultError(i18n.T("扫描技能库失败: %v", err)), nil
+		}
+		if args.Refresh {
+			reg.Reload()
 		}
 
-		entry, ok := skillMap[args.Name]
+		entry, ok := reg.Get(args.Name)
 		if !ok {
 			// 简单的模糊匹配建议
 			var suggestions []string
-			for k := range skillMap {
+			for _, k := range reg.Keys() {
 				if strings.Contains(strings.ToLower(k), strings.ToLower(args.Name)) {
 					suggestions = append(suggestions, k)
 				}
 			}
-			msg := fmt.Sprintf("未匹配到技能 \"%s\"。", args.Name)
+			msg := i18n.T("未匹配到技能 \"%s\"。", args.Name)
 			if len(suggestions) > 0 {
-				msg += fmt.Sprintf(" 你是不是想找: %s?", strings.Join(suggestions, ", "))
+				msg += i18n.T(" 你是不是想找: %s?", strings.Join(suggestions, ", "))
 			}
-			return mcp.NewToolResultText(msg), nil
+			data := map[string]any{"name": args.Name, "found": false, "suggestions": suggestions}
+			return mcp.NewToolResultStructured(data, msg), nil
 		}
 
 		skillDir := filepath.Dir(entry.FilePath)
@@ -326,7 +362,7 @@ func wrapSkillLoad(sm *SessionManager) server.ToolHandlerFunc {
 			absSkillDir, _ := filepath.Abs(skillDir)
 
 			if !strings.HasPrefix(absTarget, absSkillDir) {
-				return mcp.NewToolResultError("禁止访问技能目录外的资源"), nil
+				return mcp.NewToolResultError(i18n.T("禁止访问技能目录外的资源")), nil
 			}
 
 			if _, err := os.Stat(targetPath); os.IsNotExist(err) {
@@ -342,16 +378,17 @@ func wrapSkillLoad(sm *SessionManager) server.ToolHandlerFunc {
 
 			content, err := os.ReadFile(targetPath)
 			if err != nil {
-				return mcp.NewToolResultError(fmt.Sprintf("无法加载资源: %s", args.Resource)), nil
+				return mcp.NewToolResultError(i18n.T("无法加载资源: %s", args.Resource)), nil
 			}
 
-			return mcp.NewToolResultText(fmt.Sprintf("### Resource: %s\n\n%s", args.Resource, string(content))), nil
+			data := &skillLoadData{Skill: entry, Resource: args.Resource, Content: string(content)}
+			return mcp.NewToolResultStructured(data, fmt.Sprintf("### Resource: %s\n\n%s", args.Resource, string(content))), nil
 		}
 
 		// 情况 2: 加载主文档
 		content, err := os.ReadFile(entry.FilePath)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("读取技能文件失败: %v", err)), nil
+			return mcp.NewToolResultError(i18n.T("读取技能文件失败: %v", err)), nil
 		}
 
 		body := regexp.MustCompile(`(?s)^---\s*\n.*?\n---\s*\n`).ReplaceAllString(string(content), "")
@@ -369,7 +406,7 @@ func wrapSkillLoad(sm *SessionManager) server.ToolHandlerFunc {
 		sb.WriteString(body)
 
 		if len(entry.Resources) > 0 {
-			sb.WriteString("\n\n## 可用资源 (Bundled Resources)\n")
+			sb.WriteString(i18n.T("\n\n## 可用资源 (Bundled Resources)\n"))
 			keys := make([]string, 0, len(entry.Resources))
 			for k := range entry.Resources {
 				keys = append(keys, k)
@@ -380,18 +417,43 @@ func wrapSkillLoad(sm *SessionManager) server.ToolHandlerFunc {
 				v := entry.Resources[k]
 				sb.WriteString(fmt.Sprintf("- **%s**: %s\n", k, strings.Join(v, ", ")))
 			}
-			sb.WriteString("\n> 若需加载子资源，请使用 `skill_load(name=\"...\", resource=\"references/xxx.md\")`。")
+			sb.WriteString(i18n.T("\n> 若需加载子资源，请使用 `skill_load(name=\"...\", resource=\"references/xxx.md\")`。"))
+			if len(entry.Resources["scripts"]) > 0 {
+				sb.WriteString(i18n.T("\n> scripts/ 中的脚本可用 `skill_run(skill=\"%s\", script=\"...\")` 执行。", entry.Metadata.Name))
+			}
 		}
 
-		return mcp.NewToolResultText(sb.String()), nil
+		data := &skillLoadData{Skill: entry, Content: body}
+		return mcp.NewToolResultStructured(data, sb.String()), nil
 	}
 }
 
+// skillListData skill_list 的结构化结果 (output_format=json)
+type skillListData struct {
+	Skills    []SkillEntry    `json:"skills"`
+	Conflicts []SkillConflict `json:"conflicts,omitempty"`
+}
+
+// skillSearchData skill_search 的结构化结果
+type skillSearchData struct {
+	Query   string       `json:"query"`
+	Total   int          `json:"total"` // 技能库中的技能总数
+	Matches []SkillMatch `json:"matches"`
+}
+
+// skillLoadData skill_load 的结构化结果：Resource 为空时 Content 是去掉 frontmatter 的主文档
+type skillLoadData struct {
+	Skill    SkillEntry `json:"skill"`
+	Resource string     `json:"resource,omitempty"`
+	Content  string     `json:"content"`
+}
+
 func (sm *SessionManager) GetSkillContent(name string) (string, error) {
-	if skillMap == nil {
-		scanSkills(sm)
+	reg, err := sm.skillRegistry()
+	if err != nil {
+		return "", err
 	}
-	entry, ok := skillMap[name]
+	entry, ok := reg.Get(name)
 	if !ok {
 		return "", fmt.Errorf("skill not found")
 	}
@@ -401,3 +463,14 @@ func (sm *SessionManager) GetSkillContent(name string) (string, error) {
 	}
 	return string(content), nil
 }
+
+func renderSkillConflict(c SkillConflict) string {
+	kind := i18n.T("同名技能")
+	if c.Alias {
+		kind = i18n.T("目录别名")
+	}
+	if c.Winner == "" {
+		return i18n.T("- %s `%s` 有歧义，已停用: %s\n", kind, c.Name, strings.Join(c.Shadowed, "; "))
+	}
+	return i18n.T("- %s `%s` 使用 %s，已遮蔽: %s\n", kind, c.Name, c.Winner, strings.Join(c.Shadowed, "; "))
+}
@@ -0,0 +1,181 @@
+package tools
+
+import (
+	"fmt"
+	"mcp-server-go/internal/i18n"
+	"mcp-server-go/internal/services"
+	"strings"
+)
+
+// summarizeStructureSinceLastInit 对比上次初始化的结构快照并记录本次快照，返回附加到初始化结果的摘要
+func summarizeStructureSinceLastInit(sm *SessionManager, root string) string {
+	sm.StructureBaselineID = 0
+
+	prev, _, err := services.FindStructureSnapshot(root, "")
+	var summary string
+	if err == nil && prev != nil {
+		sm.StructureBaselineID = prev.ID
+		if diff, err := services.DiffStructure(root, prev.ID, "", false); err == nil {
+			summary = "\n\n" + renderStructureDiff(diff, 5)
+		}
+	}
+
+	if _, _, err := services.RecordStructureSnapshot(root); err != nil {
+		return summary + i18n.T("\n\n⚠️ 结构快照记录失败: %v", err)
+	}
+	if prev == nil {
+		return i18n.T("\n\n📸 已记录结构快照，下次初始化时将汇总期间的结构变化 (structure_diff)。")
+	}
+	return summary
+}
+
+// renderStructureSnapshotList 渲染结构快照列表
+func renderStructureSnapshotList(snapshots []services.StructureSnapshot) string {
+	var sb strings.Builder
+	sb.WriteString(i18n.T("### 📸 结构快照\n\n"))
+	if len(snapshots) == 0 {
+		sb.WriteString(i18n.T("暂无快照。每次 initialize_project 会在结构变化时自动记录。\n"))
+		return sb.String()
+	}
+	sb.WriteString(i18n.T("| ID | 时间 | Git | 文件 | 符号 |\n"))
+	sb.WriteString("|---|---|---|---|---|\n")
+	for _, s := range snapshots {
+		rev := s.GitRevision
+		if rev == "" {
+			rev = "-"
+		}
+		sb.WriteString(fmt.Sprintf("| #%d | %s | `%s` | %d | %d |\n",
+			s.ID, s.CreatedAt, rev, s.TotalFiles, s.TotalSymbols))
+	}
+	return sb.String()
+}
+
+// renderStructureDiff 渲染结构差异，每类最多 limit 条
+func renderStructureDiff(d *services.StructureDiff, limit int) string {
+	var sb strings.Builder
+
+	from := fmt.Sprintf("#%d (%s)", d.From.ID, d.From.CreatedAt)
+	if d.From.GitRevision != "" {
+		from = fmt.Sprintf("#%d (%s @ `%s`)", d.From.ID, d.From.CreatedAt, d.From.GitRevision)
+	}
+
+	sb.WriteString(i18n.T("### 🧭 结构变化\n\n"))
+	sb.WriteString(i18n.T("**对比**: 快照 %s → 当前代码\n", from))
+	if d.Scope != "" {
+		sb.WriteString(i18n.T("**范围**: `%s`\n", d.Scope))
+	}
+	if d.PublicOnly {
+		sb.WriteString(i18n.T("**过滤**: 仅公开符号\n"))
+	}
+	sb.WriteString(i18n.T("**概览**: 文件 +%d/-%d | 符号 +%d/-%d | ↪%d 移动 | ✏️%d 签名变化\n\n",
+		len(d.AddedFiles), len(d.RemovedFiles), len(d.Added), len(d.Removed), len(d.Moved), len(d.SignatureChanged)))
+
+	if d.Empty() {
+		sb.WriteString(i18n.T("✅ 快照之后没有结构变化\n"))
+		return sb.String()
+	}
+
+	more := func(total int) {
+		if total > limit {
+			sb.WriteString(i18n.T("- ... 还有 %d 个\n", total-limit))
+		}
+	}
+	symLine := func(s services.StructureSymbol) string {
+		return fmt.Sprintf("`%s` (%s) @ %s:%d", s.QualifiedName, s.SymbolType, s.FilePath, s.LineStart)
+	}
+
+	if len(d.Modules) > 0 {
+		sb.WriteString(i18n.T("#### 📁 模块\n"))
+		status := map[string]string{"added": "🆕 ", "removed": "🗑️ ", "changed": ""}
+		for i, m := range d.Modules {
+			if i >= limit {
+				break
+			}
+			var parts []string
+			if m.FilesAdded+m.FilesRemoved > 0 {
+				parts = append(parts, i18n.T("文件 +%d/-%d", m.FilesAdded, m.FilesRemoved))
+			}
+			if m.Added+m.Removed > 0 {
+				parts = append(parts, i18n.T("符号 +%d/-%d", m.Added, m.Removed))
+			}
+			if m.Moved > 0 {
+				parts = append(parts, i18n.T("移入 %d", m.Moved))
+			}
+			if m.Changed > 0 {
+				parts = append(parts, i18n.T("签名变化 %d", m.Changed))
+			}
+			sb.WriteString(fmt.Sprintf("- %s**%s/**: %s\n", status[m.Status], m.Dir, strings.Join(parts, ", ")))
+		}
+		more(len(d.Modules))
+		sb.WriteString("\n")
+	}
+
+	if len(d.AddedFiles)+len(d.RemovedFiles) > 0 {
+		sb.WriteString(i18n.T("#### 📄 文件\n"))
+		for i, f := range d.AddedFiles {
+			if i >= limit {
+				break
+			}
+			sb.WriteString(fmt.Sprintf("- ➕ %s\n", f))
+		}
+		more(len(d.AddedFiles))
+		for i, f := range d.RemovedFiles {
+			if i >= limit {
+				break
+			}
+			sb.WriteString(fmt.Sprintf("- ➖ %s\n", f))
+		}
+		more(len(d.RemovedFiles))
+		sb.WriteString("\n")
+	}
+
+	if len(d.SignatureChanged) > 0 {
+		sb.WriteString(i18n.T("#### ✏️ 签名变化\n"))
+		for i, c := range d.SignatureChanged {
+			if i >= limit {
+				break
+			}
+			sb.WriteString(i18n.T("- %s\n  - 旧: `%s`\n  - 新: `%s`\n",
+				symLine(c.Symbol), truncateLine(c.Before, 120), truncateLine(c.After, 120)))
+		}
+		more(len(d.SignatureChanged))
+		sb.WriteString("\n")
+	}
+
+	if len(d.Moved) > 0 {
+		sb.WriteString(i18n.T("#### ↪ 移动\n"))
+		for i, m := range d.Moved {
+			if i >= limit {
+				break
+			}
+			sb.WriteString(fmt.Sprintf("- `%s`: %s → %s\n", m.Symbol.QualifiedName, m.FromFile, m.Symbol.FilePath))
+		}
+		more(len(d.Moved))
+		sb.WriteString("\n")
+	}
+
+	if len(d.Added) > 0 {
+		sb.WriteString(i18n.T("#### ➕ 新增符号\n"))
+		for i, s := range d.Added {
+			if i >= limit {
+				break
+			}
+			sb.WriteString("- " + symLine(s) + "\n")
+		}
+		more(len(d.Added))
+		sb.WriteString("\n")
+	}
+
+	if len(d.Removed) > 0 {
+		sb.WriteString(i18n.T("#### ➖ 删除符号\n"))
+		for i, s := range d.Removed {
+			if i >= limit {
+				break
+			}
+			sb.WriteString(fmt.Sprintf("- `%s` (%s) @ %s\n", s.QualifiedName, s.SymbolType, s.FilePath))
+		}
+		more(len(d.Removed))
+	}
+
+	return strings.TrimRight(sb.String(), "\n") + "\n"
+}
@@ -2,13 +2,16 @@ package tools
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"mcp-server-go/internal/core"
+	"mcp-server-go/internal/i18n"
 	"mcp-server-go/internal/services"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
@@ -29,11 +32,58 @@ type InitArgs struct {
 
 // SessionManager 管理项目上下文（项目根路径与记忆层）
 type SessionManager struct {
-	Memory        *core.MemoryLayer
-	ProjectRoot   string
-	TaskChains    map[string]*TaskChain     // V1 版本（向后兼容）
-	TaskChainsV2  map[string]*TaskChainV2   // V2 自适应版本
-	AnalysisState map[string]*AnalysisState // manager_analyze 两步调用的中间状态
+	Memory       *core.MemoryLayer
+	ProjectRoot  string
+	TaskChains   map[string]*TaskChain   // V1 版本（向后兼容）
+	TaskChainsV2 map[string]*TaskChainV2 // V2 自适应版本
+
+	StructureBaselineID int64     // 本次会话初始化前的结构快照 ID (structure_diff 默认基准)
+	PrevSessionAt       time.Time // 上一次 initialize_project 的时间 (会话交接基准)
+	InitializedAt       time.Time // 本次 initialize_project 的时间
+
+	IntentRules *services.IntentRules // 意图/禁令规则 (内置 < 全局 < 项目)
+
+	Skills   *SkillRegistry // 技能注册表 (首次使用时创建，切换项目后重建)
+	skillsMu sync.Mutex
+}
+
+// ReloadIntentRules 按当前项目重新加载意图规则，返回被忽略的规则文件及原因
+func (sm *SessionManager) ReloadIntentRules() []string {
+	rules, warnings := services.LoadIntentRules(sm.ProjectRoot)
+	sm.IntentRules = rules
+	return warnings
+}
+
+// intentRules 当前生效的意图规则，未加载时使用内置默认
+func (sm *SessionManager) intentRules() *services.IntentRules {
+	if sm.IntentRules == nil {
+		sm.IntentRules = services.DefaultIntentRules()
+	}
+	return sm.IntentRules
+}
+
+// ReloadConfig 丢弃缓存并重新加载项目配置 (mpm.yaml)，同时把 dev-log 条数绑定到配置；
+// 返回被忽略的配置文件及原因
+func (sm *SessionManager) ReloadConfig() []string {
+	cfg, warnings := services.ReloadProjectConfig(sm.ProjectRoot)
+	if sm.Memory != nil {
+		sm.Memory.SetDevLogWindow(func() int { return sm.config().DevLog.Window })
+	}
+
+	// 界面语言：扩展目录随配置一起重载；工具描述在注册时确定，切换语言后需重启才会更新
+	projectConfigDir := ""
+	if sm.ProjectRoot != "" {
+		projectConfigDir = services.ProjectConfigDir(sm.ProjectRoot)
+	}
+	warnings = append(warnings, i18n.LoadExternal(services.GlobalConfigDir(), projectConfigDir)...)
+	i18n.SetLocale(i18n.ResolveLocale(cfg.Locale))
+	return warnings
+}
+
+// config 当前生效的项目配置 (内置 < 全局 < 项目)，配置文件变化后自动重载
+func (sm *SessionManager) config() *services.ProjectConfig {
+	cfg, _ := services.ProjectConfigFor(sm.ProjectRoot)
+	return cfg
 }
 
 // TaskChain 任务链状态（V1 版本，向后兼容）
@@ -44,15 +94,17 @@ type TaskChain struct {
 	Status      string   `json:"status"` // running, paused, finished
 }
 
-// AnalysisState 第一步分析结果（临时存储）
+// AnalysisState 第一步分析结果（持久化于 analysis_briefings）
 type AnalysisState struct {
 	Intent         string                 `json:"intent"`
 	UserDirective  string                 `json:"user_directive"`
 	ContextAnchors []CodeAnchor           `json:"context_anchors"`
 	VerifiedFacts  []string               `json:"verified_facts"`
+	RelatedMemos   []string               `json:"related_memos,omitempty"`
 	Telemetry      map[string]interface{} `json:"telemetry"`
 	Guardrails     Guardrails             `json:"guardrails"`
 	Alerts         []string               `json:"alerts"`
+	Skills         []SkillSuggestion      `json:"suggested_skills,omitempty"`
 }
 
 // CodeAnchor 代码锚点
@@ -73,13 +125,13 @@ type Guardrails struct {
 type SystemRecallArgs struct {
 	Keywords string `json:"keywords" jsonschema:"required,description=检索关键词"`
 	Category string `json:"category" jsonschema:"description=过滤类型 (开发/重构/避坑等)"`
-	Limit    int    `json:"limit" jsonschema:"default=20,description=返回条数"`
+	Limit    int    `json:"limit" jsonschema:"default=20,description=返回条数 (默认取 mpm.yaml 的 recall.limit)"`
 }
 
 // RegisterSystemTools 注册系统工具
 func RegisterSystemTools(s *server.MCPServer, sm *SessionManager, ai *services.ASTIndexer) {
-	s.AddTool(mcp.NewTool("initialize_project",
-		mcp.WithDescription(`initialize_project - 初始化项目环境与数据库
+	addTool(s, mcp.NewTool("initialize_project",
+		mcp.WithDescription(i18n.Desc("initialize_project", `initialize_project - 初始化项目环境与数据库
 
 用途：
   任何其他 MPM 操作前，必须先调用此工具初始化项目环境。它会建立数据库索引、检测技术栈并生成项目规则。
@@ -97,12 +149,12 @@ func RegisterSystemTools(s *server.MCPServer, sm *SessionManager, ai *services.A
     -> 初始化指定路径的项目
 
 触发词：
-  "mpm 初始化", "mpm init"`),
+  "mpm 初始化", "mpm init"`)),
 		mcp.WithInputSchema[InitArgs](),
 	), wrapInit(sm, ai))
 
-	s.AddTool(mcp.NewTool("open_timeline",
-		mcp.WithDescription(`open_timeline - 项目演进可视化界面
+	addTool(s, mcp.NewTool("open_timeline",
+		mcp.WithDescription(i18n.Desc("open_timeline", `open_timeline - 项目演进可视化界面
 
 用途：
   生成并展示交互式时间线，可视化项目的开发历史和决策演进。
@@ -119,11 +171,11 @@ func RegisterSystemTools(s *server.MCPServer, sm *SessionManager, ai *services.A
     -> 在浏览器中打开项目演进时间线
 
 触发词：
-  "mpm 时间线", "mpm timeline"`),
+  "mpm 时间线", "mpm timeline"`)),
 	), wrapOpenTimeline(sm))
 
-	s.AddTool(mcp.NewTool("system_recall",
-		mcp.WithDescription(`system_recall - 你的记忆回溯器 (少走弯路)
+	addTool(s, mcp.NewTool("system_recall",
+		mcp.WithDescription(i18n.Desc("system_recall", `system_recall - 你的记忆回溯器 (少走弯路)
 
 用途：
   【下手前推荐】想改某个功能，但不确定以前有没有类似的逻辑？或者怕踩到以前的坑？
@@ -137,16 +189,18 @@ func RegisterSystemTools(s *server.MCPServer, sm *SessionManager, ai *services.A
     缩小范围：如 "避坑" / "开发" / "决策"
 
 触发词：
-  "mpm 召回", "mpm 历史", "mpm recall"`),
+  "mpm 召回", "mpm 历史", "mpm recall"`)),
 		mcp.WithInputSchema[SystemRecallArgs](),
 	), wrapSystemRecall(sm))
+
+	registerConfigTool(s, sm)
 }
 
 func wrapInit(sm *SessionManager, ai *services.ASTIndexer) server.ToolHandlerFunc {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		var args InitArgs
 		if err := request.BindArguments(&args); err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("参数格式错误： %v", err)), nil
+			return mcp.NewToolResultError(i18n.T("参数格式错误: %v", err)), nil
 		}
 
 		root := args.ProjectRoot
@@ -166,13 +220,13 @@ func wrapInit(sm *SessionManager, ai *services.ASTIndexer) server.ToolHandlerFun
 		}
 
 		if root == "" {
-			return mcp.NewToolResultText("❌ 无法自动识别项目路径，请手动指定 project_root（需为绝对路径）。"), nil
+			return mcp.NewToolResultError(i18n.T("❌ 无法自动识别项目路径，请手动指定 project_root（需为绝对路径）。")), nil
 		}
 
 		// 1. 路径统一化 (Path Normalization)
 		absRoot, err := filepath.Abs(root)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("路径解析失败： %v", err)), nil
+			return mcp.NewToolResultError(i18n.T("路径解析失败： %v", err)), nil
 		}
 
 		absRoot = filepath.ToSlash(filepath.Clean(absRoot))
@@ -183,44 +237,56 @@ func wrapInit(sm *SessionManager, ai *services.ASTIndexer) server.ToolHandlerFun
 
 		// 2. 校验路径安全性
 		if !core.ValidateProjectPath(absRoot) {
-			return mcp.NewToolResultError(fmt.Sprintf("⛔ 敏感路径（系统或 IDE 目录），禁止在此初始化项目： %s", absRoot)), nil
+			return mcp.NewToolResultError(i18n.T("⛔ 敏感路径（系统或 IDE 目录），禁止在此初始化项目： %s", absRoot)), nil
 		}
 
 		// 3. 确保 .mcp-data 存在
 		mcpDataDir := filepath.Join(absRoot, ".mcp-data")
 		if err := os.MkdirAll(mcpDataDir, 0755); err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("创建数据目录失败： %v", err)), nil
+			return mcp.NewToolResultError(i18n.T("创建数据目录失败： %v", err)), nil
 		}
 
-		// 4. 持久化项目配置
-		configPath := filepath.Join(mcpDataDir, "project_config.json")
-		configContent := fmt.Sprintf(`{
-  "project_root": "%s",
-  "initialized_at": "%s"
-}`, absRoot, time.Now().Format(time.RFC3339))
-		_ = os.WriteFile(configPath, []byte(configContent), 0644)
+		// 4. 项目配置已统一到 .mcp-config/mpm.yaml，旧版 project_config.json 只记录根路径与初始化时间，直接清理
+		_ = os.Remove(filepath.Join(mcpDataDir, legacyProjectConfigFile))
 
 		// 5. 初始化记忆层
 		mem, err := core.NewMemoryLayer(absRoot)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("初始化记忆层失败： %v", err)), nil
+			return mcp.NewToolResultError(i18n.T("初始化记忆层失败： %v", err)), nil
 		}
 
 		sm.Memory = mem
 		sm.ProjectRoot = absRoot
+		sm.InitializedAt = time.Now()
 		if sm.TaskChains == nil {
 			sm.TaskChains = make(map[string]*TaskChain)
 		}
 		if sm.TaskChainsV2 == nil {
 			sm.TaskChainsV2 = make(map[string]*TaskChainV2)
 		}
+		restoreTaskChainsV2(ctx, sm)
+		sm.PrevSessionAt = markSessionStart(ctx, sm)
+
+		var rulesWarn string
+		if warnings := sm.ReloadIntentRules(); len(warnings) > 0 {
+			rulesWarn = i18n.T("\n\n⚠️ 意图规则文件有误，已回退:\n- %s", strings.Join(warnings, "\n- "))
+		}
+		if warnings := sm.ReloadConfig(); len(warnings) > 0 {
+			rulesWarn += i18n.T("\n\n⚠️ 项目配置 (mpm.yaml) 有误，已回退:\n- %s", strings.Join(warnings, "\n- "))
+		}
 
 		// 6. 🆕 【关键】刷新 AST 索引数据库
 		// 确保 symbols.db 是最新的，否则所有代码工具都会查询到旧数据
 		_, indexErr := ai.Index(absRoot)
 		indexStatus := "✅"
 		if indexErr != nil {
-			indexStatus = fmt.Sprintf("⚠️ (索引失败: %v)", indexErr)
+			indexStatus = i18n.T("⚠️ (索引失败: %v)", indexErr)
+		}
+
+		// 结构变化：先与上次初始化的快照对比，再记录本次快照
+		var structureMsg string
+		if indexErr == nil {
+			structureMsg = summarizeStructureSinceLastInit(sm, absRoot)
 		}
 
 		// 7. 植入 visualize_history.py (Timeline 生成脚本)
@@ -238,11 +304,15 @@ func wrapInit(sm *SessionManager, ai *services.ASTIndexer) server.ToolHandlerFun
 		analysis, err := ai.AnalyzeNamingStyle(absRoot)
 		if err == nil {
 			if err := generateProjectRules(rulesPath, analysis); err == nil {
-				rulesMsg = "\n\n[NEW] 已同步项目规则模板: _MPM_PROJECT_RULES.md\nIDE 将自动加载更新后的规则。"
+				rulesMsg = i18n.T("\n\n[NEW] 已同步项目规则模板: _MPM_PROJECT_RULES.md\nIDE 将自动加载更新后的规则。")
 			}
 		}
 
-		return mcp.NewToolResultText(fmt.Sprintf("✅ 项目初始化成功！\n\n项目目录: %s\n数据库已准备就绪。\nAST 索引: %s%s", absRoot, indexStatus, rulesMsg)), nil
+		briefing := "\n\n" + buildSessionBriefing(ctx, sm)
+		personaMsg := restoreActivePersona(ctx, sm)
+
+		data := map[string]interface{}{"project_root": absRoot, "index_ok": indexErr == nil}
+		return mcp.NewToolResultStructured(data, i18n.T("✅ 项目初始化成功！\n\n项目目录: %s\n数据库已准备就绪。\nAST 索引: %s%s%s%s%s%s", absRoot, indexStatus, rulesMsg, rulesWarn, briefing, structureMsg, personaMsg)), nil
 	}
 }
 
@@ -360,7 +430,7 @@ func wrapOpenTimeline(sm *SessionManager) server.ToolHandlerFunc {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		root := sm.ProjectRoot
 		if root == "" {
-			return mcp.NewToolResultError("❌ 项目未初始化，请先调用 initialize_project"), nil
+			return mcp.NewToolResultError(i18n.T("❌ 项目未初始化，请先调用 initialize_project")), nil
 		}
 
 		// 1. 定位脚本 (优先 scripts/, 其次 root)
@@ -368,22 +438,26 @@ func wrapOpenTimeline(sm *SessionManager) server.ToolHandlerFunc {
 		if _, err := os.Stat(scriptPath); os.IsNotExist(err) {
 			scriptPath = filepath.Join(root, "visualize_history.py")
 			if _, err := os.Stat(scriptPath); os.IsNotExist(err) {
-				return mcp.NewToolResultError(fmt.Sprintf("❌ 找不到生成脚本: %s (checked scripts/ and root)", "visualize_history.py")), nil
+				return mcp.NewToolResultError(i18n.T("❌ 找不到生成脚本: %s (checked scripts/ and root)", "visualize_history.py")), nil
 			}
 		}
 
-		// 2. 生成 HTML (Python)
+		// 2. 生成 HTML (Python)，被忽略规则命中的文件相关 memo 不进入 Timeline
 		cmd := exec.Command("python", scriptPath)
 		cmd.Dir = root
+		if excluded := ignoredMemoPaths(ctx, sm); len(excluded) > 0 {
+			data, _ := json.Marshal(excluded)
+			cmd.Env = append(os.Environ(), "MPM_TIMELINE_EXCLUDE="+string(data))
+		}
 		output, err := cmd.CombinedOutput()
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("❌ 生成 Timeline 失败:\n%s\nOutput: %s", err, string(output))), nil
+			return mcp.NewToolResultError(i18n.T("❌ 生成 Timeline 失败:\n%s\nOutput: %s", err, string(output))), nil
 		}
 
 		// 3. 定位 HTML
 		htmlPath := filepath.Join(root, "project_timeline.html")
 		if _, err := os.Stat(htmlPath); os.IsNotExist(err) {
-			return mcp.NewToolResultError("❌ 脚本执行成功但未生成 project_timeline.html"), nil
+			return mcp.NewToolResultError(i18n.T("❌ 脚本执行成功但未生成 project_timeline.html")), nil
 		}
 
 		// 4. 打开浏览器
@@ -392,40 +466,71 @@ func wrapOpenTimeline(sm *SessionManager) server.ToolHandlerFunc {
 		if err := edgeCmd.Start(); err != nil {
 			fallbackCmd := exec.Command("cmd", "/c", "start", htmlURL)
 			if err := fallbackCmd.Start(); err != nil {
-				return mcp.NewToolResultText(fmt.Sprintf("⚠️ Timeline 已生成但无法自动打开。\n路径: %s", htmlPath)), nil
+				data := map[string]any{"path": htmlPath, "opened": false}
+				return mcp.NewToolResultStructured(data, i18n.T("⚠️ Timeline 已生成但无法自动打开。\n路径: %s", htmlPath)), nil
 			}
 		}
 
-		return mcp.NewToolResultText(fmt.Sprintf("✅ Timeline 已生成并尝试打开。\n文件: %s", htmlPath)), nil
+		data := map[string]any{"path": htmlPath, "opened": true}
+		return mcp.NewToolResultStructured(data, i18n.T("✅ Timeline 已生成并尝试打开。\n文件: %s", htmlPath)), nil
 	}
 }
 
+// ignoredMemoPaths 返回 memo 中被 .gitignore / .mpmignore 忽略的文件路径 (按 memo 原样)
+func ignoredMemoPaths(ctx context.Context, sm *SessionManager) []string {
+	if sm.Memory == nil {
+		return nil
+	}
+	paths, err := sm.Memory.ListMemoPaths(ctx)
+	if err != nil {
+		return nil
+	}
+
+	matcher := services.NewIgnoreMatcher(sm.ProjectRoot)
+	var excluded []string
+	for _, p := range paths {
+		candidate := filepath.FromSlash(strings.TrimSpace(p))
+		if !filepath.IsAbs(candidate) {
+			candidate = filepath.Join(sm.ProjectRoot, candidate)
+		}
+		info, err := os.Stat(candidate)
+		if matcher.MatchPath(candidate, err == nil && info.IsDir()) {
+			excluded = append(excluded, p)
+		}
+	}
+	return excluded
+}
+
 func wrapSystemRecall(sm *SessionManager) server.ToolHandlerFunc {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		var args SystemRecallArgs
 		if err := request.BindArguments(&args); err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("参数错误: %v", err)), nil
+			return mcp.NewToolResultError(i18n.T("参数错误: %v", err)), nil
 		}
 
 		if sm.ProjectRoot == "" {
-			return mcp.NewToolResultError("项目未初始化"), nil
+			return mcp.NewToolResultError(i18n.T("项目未初始化")), nil
 		}
 
 		// 1. 查询 Memos（历史修改记录）
+		if args.Limit <= 0 {
+			args.Limit = sm.config().Recall.Limit
+		}
 		memos, err := sm.Memory.SearchMemos(ctx, args.Keywords, args.Category, args.Limit)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("检索 memos 失败: %v", err)), nil
+			return mcp.NewToolResultError(i18n.T("检索 memos 失败: %v", err)), nil
 		}
 
 		// 2. 查询 Known Facts（铁律/避坑经验）
 		facts, err := sm.Memory.QueryFacts(ctx, args.Keywords, args.Limit)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("检索 known_facts 失败: %v", err)), nil
+			return mcp.NewToolResultError(i18n.T("检索 known_facts 失败: %v", err)), nil
 		}
 
 		// 3. 检查是否有结果
+		data := newRecallData(args.Keywords, args.Category, facts, memos)
 		if len(memos) == 0 && len(facts) == 0 {
-			return mcp.NewToolResultText("未找到相关记录"), nil
+			return mcp.NewToolResultStructured(data, i18n.T("未找到相关记录")), nil
 		}
 
 		// 4. 构建返回结果
@@ -457,6 +562,50 @@ func wrapSystemRecall(sm *SessionManager) server.ToolHandlerFunc {
 			}
 		}
 
-		return mcp.NewToolResultText(sb.String()), nil
+		return mcp.NewToolResultStructured(data, sb.String()), nil
+	}
+}
+
+// recallData system_recall 的结构化结果 (output_format=json)
+type recallData struct {
+	Keywords string       `json:"keywords"`
+	Category string       `json:"category,omitempty"`
+	Facts    []recallFact `json:"facts"`
+	Memos    []recallMemo `json:"memos"`
+}
+
+type recallFact struct {
+	ID        int64  `json:"id"`
+	Type      string `json:"type"`
+	Summary   string `json:"summary"`
+	CreatedAt string `json:"created_at"`
+}
+
+type recallMemo struct {
+	ID        int64  `json:"id"`
+	Timestamp string `json:"timestamp"`
+	Category  string `json:"category"`
+	Entity    string `json:"entity"`
+	Act       string `json:"act"`
+	Path      string `json:"path"`
+	Content   string `json:"content"`
+}
+
+func newRecallData(keywords, category string, facts []core.KnownFact, memos []core.Memo) *recallData {
+	data := &recallData{Keywords: keywords, Category: category, Facts: []recallFact{}, Memos: []recallMemo{}}
+	for _, f := range facts {
+		data.Facts = append(data.Facts, recallFact{ID: f.ID, Type: f.Type, Summary: f.Summarize, CreatedAt: f.CreatedAt.Format(time.RFC3339)})
+	}
+	for _, m := range memos {
+		data.Memos = append(data.Memos, recallMemo{
+			ID:        m.ID,
+			Timestamp: m.Timestamp.Format(time.RFC3339),
+			Category:  m.Category,
+			Entity:    m.Entity,
+			Act:       m.Act,
+			Path:      m.Path,
+			Content:   m.Content,
+		})
 	}
+	return data
 }
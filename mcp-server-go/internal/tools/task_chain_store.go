@@ -0,0 +1,108 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"mcp-server-go/internal/i18n"
+	"os"
+	"strings"
+	"time"
+)
+
+// taskChainStateCategory 任务链在 system_state 中的分类
+const taskChainStateCategory = "task_chain"
+
+func taskChainStateKey(taskID string) string {
+	return "task_chain:" + taskID
+}
+
+// saveTaskChainV2 持久化任务链，供下次会话恢复（记忆层未初始化时跳过）
+func saveTaskChainV2(sm *SessionManager, chain *TaskChainV2) {
+	if chain == nil {
+		return
+	}
+	chain.UpdatedAt = time.Now()
+	if sm.Memory == nil {
+		return
+	}
+	data, err := json.Marshal(chain)
+	if err != nil {
+		return
+	}
+	if err := sm.Memory.SaveState(context.Background(), taskChainStateKey(chain.TaskID), string(data), taskChainStateCategory); err != nil {
+		fmt.Fprintf(os.Stderr, "[TaskChain][WARN] persist %s failed: %v\n", chain.TaskID, err)
+	}
+}
+
+// restoreTaskChainsV2 从记忆层恢复未完成的任务链，已在内存中的以内存为准
+func restoreTaskChainsV2(ctx context.Context, sm *SessionManager) int {
+	if sm.Memory == nil {
+		return 0
+	}
+	if sm.TaskChainsV2 == nil {
+		sm.TaskChainsV2 = make(map[string]*TaskChainV2)
+	}
+	entries, err := sm.Memory.ListStates(ctx, taskChainStateCategory)
+	if err != nil {
+		return 0
+	}
+
+	restored := 0
+	for _, e := range entries {
+		var chain TaskChainV2
+		if err := json.Unmarshal([]byte(e.Value), &chain); err != nil || chain.TaskID == "" {
+			continue
+		}
+		if chain.Status == "finished" {
+			continue
+		}
+		if _, ok := sm.TaskChainsV2[chain.TaskID]; ok {
+			continue
+		}
+		sm.TaskChainsV2[chain.TaskID] = &chain
+		restored++
+	}
+	return restored
+}
+
+// chainProgress 已完成步骤数 / 总步骤数
+func chainProgress(chain *TaskChainV2) (done, total int) {
+	for _, step := range chain.Steps {
+		if step.Status == StepStatusComplete {
+			done++
+		}
+	}
+	return done, len(chain.Steps)
+}
+
+// chainNextAction 根据步骤状态给出下一步调用
+func chainNextAction(chain *TaskChainV2) string {
+	for _, step := range chain.Steps {
+		if step.Status == StepStatusInProgress {
+			return fmt.Sprintf(`task_chain(mode="complete", task_id="%s", step_number=%.1f, summary="...")`, chain.TaskID, step.Number)
+		}
+	}
+	for _, step := range chain.Steps {
+		if step.Status == StepStatusTodo {
+			return fmt.Sprintf(`task_chain(mode="start", task_id="%s", step_number=%.1f)`, chain.TaskID, step.Number)
+		}
+	}
+	return fmt.Sprintf(`task_chain(mode="finish", task_id="%s")`, chain.TaskID)
+}
+
+// currentStepLabel 当前步骤描述（进行中优先，其次第一个待执行）
+func currentStepLabel(chain *TaskChainV2) string {
+	for _, status := range []StepStatus{StepStatusInProgress, StepStatusTodo} {
+		for _, step := range chain.Steps {
+			if step.Status == status {
+				label := i18n.T("执行中")
+				if status == StepStatusTodo {
+					label = i18n.T("待开始")
+				}
+				return fmt.Sprintf("Step %.1f %s (%s)", step.Number, strings.TrimSpace(step.Name), label)
+			}
+		}
+	}
+	return i18n.T("所有步骤已完成，待 finish")
+}
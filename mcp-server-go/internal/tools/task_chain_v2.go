@@ -1,5 +1,7 @@
 package tools
 
+import "time"
+
 // StepStatus 步骤状态
 type StepStatus string
 
@@ -26,6 +28,9 @@ type TaskChainV2 struct {
 	Steps       []Step  `json:"steps"`        // 步骤列表（按编号排序）
 	CurrentStep float64 `json:"current_step"` // 当前执行到的步骤编号
 	Status      string  `json:"status"`       // 任务状态：running, paused, finished
+	AnalysisID  string  `json:"analysis_id,omitempty"` // 关联的 manager_analyze 简报
+	CreatedAt   time.Time `json:"created_at"` // 创建时间
+	UpdatedAt   time.Time `json:"updated_at"` // 最近一次变更时间（每次持久化时更新）
 }
 
 // TaskChainArgsV2 任务链参数（V2 版本）
@@ -3,6 +3,9 @@ package tools
 import (
 	"context"
 	"fmt"
+	"mcp-server-go/internal/core"
+	"mcp-server-go/internal/i18n"
+	"sort"
 	"strings"
 	"time"
 
@@ -45,13 +48,14 @@ type TaskChainArgs struct {
 	Summary     string                   `json:"summary" jsonschema:"description=步骤总结 (complete模式)"`
 	After       float64                  `json:"after" jsonschema:"description=插入到某步骤之后 (insert模式)"`
 	From        float64                  `json:"from" jsonschema:"description=从某步骤开始更新 (update模式)"`
+	AnalysisID  string                   `json:"analysis_id" jsonschema:"description=关联的 manager_analyze 简报 task_id (step模式，可选)"`
 }
 
 // RegisterTaskTools 注册任务管理工具
 func RegisterTaskTools(s *server.MCPServer, sm *SessionManager) {
 	// Hook 系列
-	s.AddTool(mcp.NewTool("manager_create_hook",
-		mcp.WithDescription(`manager_create_hook - 创建并挂起待办事项 (钩子)
+	addTool(s, mcp.NewTool("manager_create_hook",
+		mcp.WithDescription(i18n.Desc("manager_create_hook", `manager_create_hook - 创建并挂起待办事项 (钩子)
 
 用途：
   当任务由于缺少信息、等待用户确认或遇到阻塞无法继续时，创建一个“钩子”挂起当前进度。这确保了任务可以在未来的会话中被恢复。
@@ -80,12 +84,12 @@ func RegisterTaskTools(s *server.MCPServer, sm *SessionManager) {
     -> 创建一个高优先级的阻塞项
 
 触发词：
-  "mpm 挂起", "mpm 待办", "mpm hook"`),
+  "mpm 挂起", "mpm 待办", "mpm hook"`)),
 		mcp.WithInputSchema[HookCreateArgs](),
 	), wrapCreateHook(sm))
 
-	s.AddTool(mcp.NewTool("manager_list_hooks",
-		mcp.WithDescription(`manager_list_hooks - 查看待办钩子列表
+	addTool(s, mcp.NewTool("manager_list_hooks",
+		mcp.WithDescription(i18n.Desc("manager_list_hooks", `manager_list_hooks - 查看待办钩子列表
 
 用途：
   列出当前项目中所有处于挂起或已闭合状态的任务钩子。
@@ -102,12 +106,12 @@ func RegisterTaskTools(s *server.MCPServer, sm *SessionManager) {
     -> 列出所有打开的待办项
 
 触发词：
-  "mpm 待办列表", "mpm listhooks"`),
+  "mpm 待办列表", "mpm listhooks"`)),
 		mcp.WithInputSchema[HookListArgs](),
 	), wrapListHooks(sm))
 
-	s.AddTool(mcp.NewTool("manager_release_hook",
-		mcp.WithDescription(`manager_release_hook - 释放并闭合待办钩子
+	addTool(s, mcp.NewTool("manager_release_hook",
+		mcp.WithDescription(i18n.Desc("manager_release_hook", `manager_release_hook - 释放并闭合待办钩子
 
 用途：
   当挂起的待办事项已完成或阻塞点已消除时，闭合对应的钩子，并记录执行结果。
@@ -127,13 +131,13 @@ func RegisterTaskTools(s *server.MCPServer, sm *SessionManager) {
     -> 释放指定的待办项
 
 触发词：
-  "mpm 释放", "mpm 完成"`),
+  "mpm 释放", "mpm 完成"`)),
 		mcp.WithInputSchema[HookReleaseArgs](),
 	), wrapReleaseHook(sm))
 
 	// Task Chain - 顺序任务链执行器（分步推进，避免并发冲突）
-	s.AddTool(mcp.NewTool("task_chain",
-		mcp.WithDescription(`task_chain - 顺序任务执行器 V2 (自适应任务链)
+	addTool(s, mcp.NewTool("task_chain",
+		mcp.WithDescription(i18n.Desc("task_chain", `task_chain - 顺序任务执行器 V2 (自适应任务链)
 
 用途：
   【多步任务】与其一次性让 LLM 并发执行所有操作（容易冲突），不如交给我来按顺序推进。
@@ -148,6 +152,7 @@ func RegisterTaskTools(s *server.MCPServer, sm *SessionManager) {
     - insert: 插入步骤（需要 task_id + after + insert_plan，支持小数编号 1.1, 1.2）
     - update: 更新步骤（需要 task_id + from + update_plan）
     - delete: 删除步骤（需要 task_id + step_to_delete 或 delete_scope）
+    - continue: 上下文丢失/新会话时恢复，返回真实状态：运行中的任务链与当前步骤、待办钩子、最近备忘等
 
     【V1 模式 - 向后兼容】
     - next: 执行下一步（V1 模式）
@@ -164,6 +169,9 @@ func RegisterTaskTools(s *server.MCPServer, sm *SessionManager) {
   description (step 模式可选)
     任务整体描述
 
+  analysis_id (step 模式可选)
+    manager_analyze 返回的 task_id，关联后简报不再过期，可随时重新生成策略
+
   step_number (start/complete 模式必填)
     步骤编号（支持小数：1.0, 1.5, 2.0 等）
 
@@ -182,6 +190,7 @@ func RegisterTaskTools(s *server.MCPServer, sm *SessionManager) {
     - 完成后必须提交 summary，强制沉淀知识
     - 每个检查点都是决策点，可以动态调整后续步骤
     - 支持小数编号（1.1, 1.2），灵活插入步骤
+    - 任务链进度持久化，重启后 initialize_project 自动恢复
 
   V1 兼容模式：
     - 保持上下文连贯，自动记录进度
@@ -206,7 +215,7 @@ func RegisterTaskTools(s *server.MCPServer, sm *SessionManager) {
   → 插入 Step 1.1
 
 触发词：
-  "mpm 任务链", "mpm 续传", "mpm chain"`),
+  "mpm 任务链", "mpm 续传", "mpm chain"`)),
 		mcp.WithInputSchema[TaskChainArgs](),
 	), wrapTaskChain(sm))
 }
@@ -215,19 +224,20 @@ func wrapCreateHook(sm *SessionManager) server.ToolHandlerFunc {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		var args HookCreateArgs
 		if err := request.BindArguments(&args); err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("参数错误: %v", err)), nil
+			return mcp.NewToolResultError(i18n.T("参数错误: %v", err)), nil
 		}
 
 		if sm.Memory == nil {
-			return mcp.NewToolResultError("记忆层尚未初始化"), nil
+			return mcp.NewToolResultError(i18n.T("记忆层尚未初始化")), nil
 		}
 
 		id, err := sm.Memory.CreateHook(ctx, args.Description, args.Priority, args.Tag, args.TaskID, args.ExpiresInHours)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("创建 Hook 失败: %v", err)), nil
+			return mcp.NewToolResultError(i18n.T("创建 Hook 失败: %v", err)), nil
 		}
 
-		return mcp.NewToolResultText(fmt.Sprintf("📌 Hook 已创建 (ID: %s)\n\n**描述**: %s\n**优先级**: %s\n\n> 使用 `manager_release_hook(hook_id=\"%s\")` 释放此 Hook。", id, args.Description, args.Priority, id)), nil
+		return mcp.NewToolResultStructured(map[string]interface{}{"hook_id": id, "description": args.Description, "priority": args.Priority},
+			i18n.T("📌 Hook 已创建 (ID: %s)\n\n**描述**: %s\n**优先级**: %s\n\n> 使用 `manager_release_hook(hook_id=\"%s\")` 释放此 Hook。", id, args.Description, args.Priority, id)), nil
 	}
 }
 
@@ -241,20 +251,21 @@ func wrapListHooks(sm *SessionManager) server.ToolHandlerFunc {
 		}
 
 		if sm.Memory == nil {
-			return mcp.NewToolResultError("记忆层尚未初始化"), nil
+			return mcp.NewToolResultError(i18n.T("记忆层尚未初始化")), nil
 		}
 
 		hooks, err := sm.Memory.ListHooks(ctx, args.Status)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("查询 Hook 失败: %v", err)), nil
+			return mcp.NewToolResultError(i18n.T("查询 Hook 失败: %v", err)), nil
 		}
 
+		data := map[string]interface{}{"status": args.Status, "hooks": hookViews(hooks)}
 		if len(hooks) == 0 {
-			return mcp.NewToolResultText(fmt.Sprintf("暂无 %s 状态的 Hook。", args.Status)), nil
+			return mcp.NewToolResultStructured(data, i18n.T("暂无 %s 状态的 Hook。", args.Status)), nil
 		}
 
 		var sb strings.Builder
-		sb.WriteString(fmt.Sprintf("### 📋 Hook 列表 (%s)\n\n", args.Status))
+		sb.WriteString(i18n.T("### 📋 Hook 列表 (%s)\n\n", args.Status))
 		for _, h := range hooks {
 			expiration := ""
 			if h.ExpiresAt.Valid {
@@ -278,27 +289,64 @@ func wrapListHooks(sm *SessionManager) server.ToolHandlerFunc {
 			sb.WriteString(fmt.Sprintf("- **%s** (ID: %s) [%s]%s %s%s\n", displayID, h.HookID, h.Priority, taskDraft, h.Description, expiration))
 		}
 
-		return mcp.NewToolResultText(sb.String()), nil
+		return mcp.NewToolResultStructured(data, sb.String()), nil
 	}
 }
 
+// hookView Hook 的结构化视图 (output_format=json)
+type hookView struct {
+	HookID      string `json:"hook_id"`
+	Summary     string `json:"summary,omitempty"`
+	Description string `json:"description"`
+	Priority    string `json:"priority"`
+	Tag         string `json:"tag,omitempty"`
+	Status      string `json:"status"`
+	TaskID      string `json:"task_id,omitempty"`
+	CreatedAt   string `json:"created_at"`
+	ExpiresAt   string `json:"expires_at,omitempty"`
+	Expired     bool   `json:"expired"`
+}
+
+func hookViews(hooks []core.Hook) []hookView {
+	views := make([]hookView, 0, len(hooks))
+	for _, h := range hooks {
+		v := hookView{
+			HookID:      h.HookID,
+			Summary:     h.Summary,
+			Description: h.Description,
+			Priority:    h.Priority,
+			Tag:         h.Tag,
+			Status:      h.Status,
+			TaskID:      h.RelatedTaskID,
+			CreatedAt:   h.CreatedAt.Format(time.RFC3339),
+		}
+		if h.ExpiresAt.Valid {
+			v.ExpiresAt = h.ExpiresAt.Time.Format(time.RFC3339)
+			v.Expired = time.Now().After(h.ExpiresAt.Time)
+		}
+		views = append(views, v)
+	}
+	return views
+}
+
 func wrapReleaseHook(sm *SessionManager) server.ToolHandlerFunc {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		var args HookReleaseArgs
 		if err := request.BindArguments(&args); err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("参数错误: %v", err)), nil
+			return mcp.NewToolResultError(i18n.T("参数错误: %v", err)), nil
 		}
 
 		if sm.Memory == nil {
-			return mcp.NewToolResultError("记忆层尚未初始化"), nil
+			return mcp.NewToolResultError(i18n.T("记忆层尚未初始化")), nil
 		}
 
 		// 直接使用传入的 String ID
 		if err := sm.Memory.ReleaseHook(ctx, args.HookID, args.ResultSummary); err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("释放 Hook 失败: %v", err)), nil
+			return mcp.NewToolResultError(i18n.T("释放 Hook 失败: %v", err)), nil
 		}
 
-		return mcp.NewToolResultText(fmt.Sprintf("✅ Hook %s 已释放。\n\n**结果摘要**: %s", args.HookID, args.ResultSummary)), nil
+		return mcp.NewToolResultStructured(map[string]interface{}{"hook_id": args.HookID, "result_summary": args.ResultSummary},
+			i18n.T("✅ Hook %s 已释放。\n\n**结果摘要**: %s", args.HookID, args.ResultSummary)), nil
 	}
 }
 
@@ -306,63 +354,118 @@ func wrapTaskChain(sm *SessionManager) server.ToolHandlerFunc {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		var args TaskChainArgs
 		if err := request.BindArguments(&args); err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("参数错误: %v", err)), nil
-		}
-
-		switch args.Mode {
-		case "start":
-			// V2 新模式：开始指定步骤
-			return startStepV2(sm, args.TaskID, args.StepNumber)
-		case "complete":
-			// V2 新模式：完成步骤并提交 summary
-			return completeStepV2(sm, args.TaskID, args.StepNumber, args.Summary)
-		case "continue":
-			return continueExecution()
-		case "step":
-			// V2 模式：初始化任务链并自动开始第一步
-			return initTaskChainV2(sm, args.TaskID, args.Description, args.Plan)
-		case "next":
-			return getNextStep(sm, args.TaskID)
-		case "resume":
-			return resumeTask(sm, args.TaskID)
-		case "insert":
-			// V2 模式：插入步骤（支持小数编号）
-			return insertStepsV2(sm, args.TaskID, args.After, args.InsertPlan)
-		case "update":
-			// V2 新模式：更新步骤
-			return updateStepsV2(sm, args.TaskID, args.From, args.UpdatePlan)
-		case "delete":
-			// V2 模式：删除步骤
-			return deleteStepsV2(sm, args.TaskID, args.StepNumber, args.DeleteScope)
-		case "finish":
-			return finishChain(sm, args.TaskID)
-		default:
-			return mcp.NewToolResultError(fmt.Sprintf("未知模式: %s", args.Mode)), nil
+			return mcp.NewToolResultError(i18n.T("参数错误: %v", err)), nil
+		}
+
+		res, err := dispatchTaskChain(ctx, sm, args)
+		if err != nil || res == nil || res.IsError {
+			return res, err
 		}
+		res.StructuredContent = newTaskChainData(ctx, sm, args)
+		return res, nil
 	}
 }
 
-func continueExecution() (*mcp.CallToolResult, error) {
-	directive := `
-══════════════════════════════════════════════════════════════
-                    【执行指令】上下文已恢复
-══════════════════════════════════════════════════════════════
+// taskChainData task_chain 的结构化结果：操作后的任务链状态
+type taskChainData struct {
+	Mode   string         `json:"mode"`
+	TaskID string         `json:"task_id,omitempty"`
+	Chain  *TaskChainV2   `json:"chain,omitempty"`        // V2 任务链 (已删除时为空)
+	Legacy *TaskChain     `json:"legacy_chain,omitempty"` // V1 任务链 (next/resume)
+	Chains []*TaskChainV2 `json:"chains,omitempty"`       // continue: 全部任务链
+
+	Briefing *sessionBriefingData `json:"briefing,omitempty"` // continue: 待办钩子、最近备忘与铁律、变更文件
+}
+
+func newTaskChainData(ctx context.Context, sm *SessionManager, args TaskChainArgs) *taskChainData {
+	data := &taskChainData{Mode: args.Mode, TaskID: args.TaskID}
+	if args.Mode == "continue" {
+		data.TaskID = ""
+		data.Chains = make([]*TaskChainV2, 0, len(sm.TaskChainsV2))
+		for _, chain := range sm.TaskChainsV2 {
+			data.Chains = append(data.Chains, chain)
+		}
+		sort.Slice(data.Chains, func(i, j int) bool { return data.Chains[i].TaskID < data.Chains[j].TaskID })
+		data.Briefing = collectSessionBriefing(ctx, sm).data(sm)
+		return data
+	}
+	data.Chain = sm.TaskChainsV2[args.TaskID]
+	data.Legacy = sm.TaskChains[args.TaskID]
+	return data
+}
 
-请回顾上方对话中的【行动纲领】，判断当前进度，然后：
+// dispatchTaskChain 按 mode 分派到各操作
+func dispatchTaskChain(ctx context.Context, sm *SessionManager, args TaskChainArgs) (*mcp.CallToolResult, error) {
+	switch args.Mode {
+	case "start":
+		// V2 新模式：开始指定步骤
+		return startStepV2(sm, args.TaskID, args.StepNumber)
+	case "complete":
+		// V2 新模式：完成步骤并提交 summary
+		return completeStepV2(sm, args.TaskID, args.StepNumber, args.Summary)
+	case "continue":
+		return continueExecution(ctx, sm)
+	case "step":
+		// V2 模式：初始化任务链并自动开始第一步
+		return initTaskChainV2(sm, args.TaskID, args.Description, args.Plan, args.AnalysisID)
+	case "next":
+		return getNextStep(sm, args.TaskID)
+	case "resume":
+		return resumeTask(sm, args.TaskID)
+	case "insert":
+		// V2 模式：插入步骤（支持小数编号）
+		return insertStepsV2(sm, args.TaskID, args.After, args.InsertPlan)
+	case "update":
+		// V2 新模式：更新步骤
+		return updateStepsV2(sm, args.TaskID, args.From, args.UpdatePlan)
+	case "delete":
+		// V2 模式：删除步骤
+		return deleteStepsV2(sm, args.TaskID, args.StepNumber, args.DeleteScope)
+	case "finish":
+		return finishChain(sm, args.TaskID)
+	default:
+		return mcp.NewToolResultError(i18n.T("未知模式: %s", args.Mode)), nil
+	}
+}
 
-1️⃣ 如果有步骤尚未完成：
-   → 调用对应的专家工具执行下一步
+// continueExecution 从记忆层恢复真实状态（任务链/钩子/备忘），而不是让 AI 回忆对话
+func continueExecution(ctx context.Context, sm *SessionManager) (*mcp.CallToolResult, error) {
+	if sm.Memory == nil || sm.ProjectRoot == "" {
+		return mcp.NewToolResultError(i18n.T("项目未初始化，请先执行 initialize_project")), nil
+	}
+	restoreTaskChainsV2(ctx, sm)
 
-2️⃣ 如果所有步骤已完成：
-   → 调用 memo 工具记录最终结果
-   → 向用户汇报任务完成
+	directive := i18n.T(`
+---
+**下一步**:
+1️⃣ 有进行中的任务链 → 按上方 → 提示的调用继续
+2️⃣ 有到期/待办钩子 → 先处理或用 manager_release_hook 释放
+3️⃣ 都已完成 → 调用 memo 记录结果并向用户汇报
+`)
+	return mcp.NewToolResultText("⚡ Context Recovered!\n\n" + buildSessionBriefing(ctx, sm) + directive), nil
+}
 
-3️⃣ 如果遇到问题无法继续：
-   → 调用 manager_create_hook 挂起任务
+// stepHintKeywords 步骤名中用于推断工具参数的关键词 (匹配用户写的步骤名，不是界面文本)
+var stepHintKeywords = map[string][]string{
+	"map":      {"扫描", "map", "结构"},
+	"full":     {"核对", "审核", "对比", "对齐"},
+	"overview": {"浏览", "快速"},
+	"search":   {"搜索", "定位", "查找"},
+	"function": {"函数", "类"},
+	"class":    {"类"},
+	"impact":   {"影响", "依赖"},
+	"backward": {"谁调用了", "被哪里"},
+	"forward":  {"调用了谁", "会影响"},
+}
 
-══════════════════════════════════════════════════════════════
-`
-	return mcp.NewToolResultText("⚡ Context Recovered! " + directive), nil
+// stepMentions 步骤名是否包含某类关键词
+func stepMentions(lowerName, kind string) bool {
+	for _, kw := range stepHintKeywords[kind] {
+		if strings.Contains(lowerName, kw) {
+			return true
+		}
+	}
+	return false
 }
 
 // enhanceStepDescription 轻量意图解析：根据关键词补充执行细节
@@ -370,36 +473,36 @@ func enhanceStepDescription(name string, step map[string]interface{}) string {
 	lowerName := strings.ToLower(name)
 
 	// project_map 模式推断
-	if strings.Contains(lowerName, "扫描") || strings.Contains(lowerName, "map") || strings.Contains(lowerName, "结构") {
-		if strings.Contains(lowerName, "核对") || strings.Contains(lowerName, "审核") || strings.Contains(lowerName, "对比") || strings.Contains(lowerName, "对齐") {
+	if stepMentions(lowerName, "map") {
+		if stepMentions(lowerName, "full") {
 			// 需要查看完整代码内容
-			return name + " (用 full 模式查看完整代码)"
+			return i18n.T("%s (用 full 模式查看完整代码)", name)
 		}
-		if strings.Contains(lowerName, "浏览") || strings.Contains(lowerName, "快速") {
+		if stepMentions(lowerName, "overview") {
 			// 只需要概览
-			return name + " (用 overview 模式)"
+			return i18n.T("%s (用 overview 模式)", name)
 		}
 		// 默认用 standard
-		return name + " (用 standard 模式)"
+		return i18n.T("%s (用 standard 模式)", name)
 	}
 
 	// code_search 精度推断
-	if strings.Contains(lowerName, "搜索") || strings.Contains(lowerName, "定位") || strings.Contains(lowerName, "查找") {
-		if strings.Contains(lowerName, "函数") || strings.Contains(lowerName, "类") {
-			return name + " (设置 search_type=function)"
+	if stepMentions(lowerName, "search") {
+		if stepMentions(lowerName, "function") {
+			return i18n.T("%s (设置 search_type=function)", name)
 		}
-		if strings.Contains(lowerName, "类") {
-			return name + " (设置 search_type=class)"
+		if stepMentions(lowerName, "class") {
+			return i18n.T("%s (设置 search_type=class)", name)
 		}
 	}
 
 	// code_impact 方向推断
-	if strings.Contains(lowerName, "影响") || strings.Contains(lowerName, "依赖") {
-		if strings.Contains(lowerName, "谁调用了") || strings.Contains(lowerName, "被哪里") {
-			return name + " (设置 direction=backward)"
+	if stepMentions(lowerName, "impact") {
+		if stepMentions(lowerName, "backward") {
+			return i18n.T("%s (设置 direction=backward)", name)
 		}
-		if strings.Contains(lowerName, "调用了谁") || strings.Contains(lowerName, "会影响") {
-			return name + " (设置 direction=forward)"
+		if stepMentions(lowerName, "forward") {
+			return i18n.T("%s (设置 direction=forward)", name)
 		}
 	}
 
@@ -409,10 +512,10 @@ func enhanceStepDescription(name string, step map[string]interface{}) string {
 
 func initTaskChain(sm *SessionManager, taskID string, plan []map[string]interface{}) (*mcp.CallToolResult, error) {
 	if taskID == "" {
-		return mcp.NewToolResultError("step 模式需要 task_id 参数"), nil
+		return mcp.NewToolResultError(i18n.T("step 模式需要 task_id 参数")), nil
 	}
 	if len(plan) == 0 {
-		return mcp.NewToolResultError("step 模式需要 plan 参数"), nil
+		return mcp.NewToolResultError(i18n.T("step 模式需要 plan 参数")), nil
 	}
 
 	// 1. 解析 Plan 并增强意图
@@ -443,18 +546,18 @@ func initTaskChain(sm *SessionManager, taskID string, plan []map[string]interfac
 	}
 
 	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("### 🚀 任务链已初始化: %s\n\n", taskID))
-	sb.WriteString(fmt.Sprintf("**总步骤**: %d\n\n", len(plan)))
-	sb.WriteString("**执行计划**:\n")
+	sb.WriteString(i18n.T("### 🚀 任务链已初始化: %s\n\n", taskID))
+	sb.WriteString(i18n.T("**总步骤**: %d\n\n", len(plan)))
+	sb.WriteString(i18n.T("**执行计划**:\n"))
 	sb.WriteString(strings.Join(displaySteps, "\n"))
-	sb.WriteString("\n\n> 请执行第 1 步，完成后调用 `task_chain(mode=\"next\", task_id=\"" + taskID + "\")`。")
+	sb.WriteString(i18n.T("\n\n> 请执行第 1 步，完成后调用 `task_chain(mode=\"next\", task_id=\"%s\")`。", taskID))
 
 	return mcp.NewToolResultText(sb.String()), nil
 }
 
 func getNextStep(sm *SessionManager, taskID string) (*mcp.CallToolResult, error) {
 	if taskID == "" {
-		return mcp.NewToolResultError("next 模式需要 task_id 参数"), nil
+		return mcp.NewToolResultError(i18n.T("next 模式需要 task_id 参数")), nil
 	}
 
 	// 1. 获取状态
@@ -466,7 +569,7 @@ func getNextStep(sm *SessionManager, taskID string) (*mcp.CallToolResult, error)
 	// 如果没有状态，回退到无状态模式 (或者报错?)
 	// 为了兼容性，如果没有找到，我们假设用户是“无状态”调用，只给通用 Prompt
 	if !ok {
-		return mcp.NewToolResultText(fmt.Sprintf("📍 任务 %s 进行中 (无状态模式)。\n\n请继续执行下一步。完成后再次调用此工具。", taskID)), nil
+		return mcp.NewToolResultText(i18n.T("📍 任务 %s 进行中 (无状态模式)。\n\n请继续执行下一步。完成后再次调用此工具。", taskID)), nil
 	}
 
 	// 2. 推进步骤
@@ -482,15 +585,7 @@ func getNextStep(sm *SessionManager, taskID string) (*mcp.CallToolResult, error)
 	nextStep := chain.Plan[chain.CurrentStep]
 	remaining := len(chain.Plan) - chain.CurrentStep - 1
 
-	display := fmt.Sprintf(`👉 **Next Step (%d/%d)**: %s
-
-_(Remaining Steps: %d)_
-
----
-💡 **Dynamic Decision**:
-- 如步骤合理 -> **执行**
-- 如发现遗漏 -> 调用 `+"`task_chain(mode='insert')`"+` **增加**步骤
-- 如步骤多余 -> 调用 `+"`task_chain(mode='delete')`"+` **跳过**`,
+	display := i18n.T("👉 **Next Step (%d/%d)**: %s\n\n_(Remaining Steps: %d)_\n\n---\n💡 **Dynamic Decision**:\n- 如步骤合理 -> **执行**\n- 如发现遗漏 -> 调用 `task_chain(mode='insert')` **增加**步骤\n- 如步骤多余 -> 调用 `task_chain(mode='delete')` **跳过**",
 		chain.CurrentStep+1, len(chain.Plan), nextStep, remaining)
 
 	return mcp.NewToolResultText(display), nil
@@ -498,25 +593,25 @@ _(Remaining Steps: %d)_
 
 func resumeTask(sm *SessionManager, taskID string) (*mcp.CallToolResult, error) {
 	if taskID == "" {
-		return mcp.NewToolResultError("resume 模式需要 task_id 参数"), nil
+		return mcp.NewToolResultError(i18n.T("resume 模式需要 task_id 参数")), nil
 	}
 
 	// 尝试获取状态
-	stateInfo := "(无内存状态)"
+	stateInfo := i18n.T("(无内存状态)")
 	if chain, ok := sm.TaskChains[taskID]; ok {
-		stateInfo = fmt.Sprintf("进度: %d/%d, 当前步: %s",
+		stateInfo = i18n.T("进度: %d/%d, 当前步: %s",
 			chain.CurrentStep+1, len(chain.Plan), chain.Plan[chain.CurrentStep])
 	}
 
-	return mcp.NewToolResultText(fmt.Sprintf("🔄 正在恢复任务 %s...\n%s\n\n请根据上下文判断当前进度并继续执行。", taskID, stateInfo)), nil
+	return mcp.NewToolResultText(i18n.T("🔄 正在恢复任务 %s...\n%s\n\n请根据上下文判断当前进度并继续执行。", taskID, stateInfo)), nil
 }
 
 func insertSteps(sm *SessionManager, taskID string, insertPlan []map[string]interface{}) (*mcp.CallToolResult, error) {
 	if taskID == "" {
-		return mcp.NewToolResultError("insert 模式需要 task_id 参数"), nil
+		return mcp.NewToolResultError(i18n.T("insert 模式需要 task_id 参数")), nil
 	}
 	if len(insertPlan) == 0 {
-		return mcp.NewToolResultError("insert 模式需要 insert_plan 参数"), nil
+		return mcp.NewToolResultError(i18n.T("insert 模式需要 insert_plan 参数")), nil
 	}
 
 	// 1. 解析新步骤
@@ -541,17 +636,17 @@ func insertSteps(sm *SessionManager, taskID string, insertPlan []map[string]inte
 		rear := append([]string{}, chain.Plan[insertPos:]...)
 		chain.Plan = append(chain.Plan[:insertPos], append(newSteps, rear...)...)
 
-		msg = fmt.Sprintf("✅ 已插入 %d 个新步骤到当前位置之后 (Total: %d)。", len(insertPlan), len(chain.Plan))
+		msg = i18n.T("✅ 已插入 %d 个新步骤到当前位置之后 (Total: %d)。", len(insertPlan), len(chain.Plan))
 	} else {
-		msg = fmt.Sprintf("✅ 已插入 %d 个新步骤 (无状态模式)。", len(insertPlan))
+		msg = i18n.T("✅ 已插入 %d 个新步骤 (无状态模式)。", len(insertPlan))
 	}
 
-	return mcp.NewToolResultText(fmt.Sprintf("%s\n新增: %s", msg, strings.Join(newSteps, ", "))), nil
+	return mcp.NewToolResultText(i18n.T("%s\n新增: %s", msg, strings.Join(newSteps, ", "))), nil
 }
 
 func deleteSteps(sm *SessionManager, taskID, subtaskID string, stepOrder int, deleteScope string) (*mcp.CallToolResult, error) {
 	if taskID == "" {
-		return mcp.NewToolResultError("delete 模式需要 task_id 参数"), nil
+		return mcp.NewToolResultError(i18n.T("delete 模式需要 task_id 参数")), nil
 	}
 
 	// 尝试更新状态
@@ -561,29 +656,29 @@ func deleteSteps(sm *SessionManager, taskID, subtaskID string, stepOrder int, de
 			if chain.CurrentStep+1 < len(chain.Plan) {
 				chain.Plan = chain.Plan[:chain.CurrentStep+1]
 			}
-			return mcp.NewToolResultText(fmt.Sprintf("✅ 已删除任务 %s 的所有剩余步骤。", taskID)), nil
+			return mcp.NewToolResultText(i18n.T("✅ 已删除任务 %s 的所有剩余步骤。", taskID)), nil
 		}
 		// 其他细粒度删除太复杂，暂不支持修改 Plan 数组中间的元素（容易乱序）
 	}
 
 	if deleteScope == "remaining" {
-		return mcp.NewToolResultText(fmt.Sprintf("✅ 已删除任务 %s 的所有剩余步骤。", taskID)), nil
+		return mcp.NewToolResultText(i18n.T("✅ 已删除任务 %s 的所有剩余步骤。", taskID)), nil
 	}
 
 	if stepOrder > 0 {
-		return mcp.NewToolResultText(fmt.Sprintf("✅ 已删除任务 %s 的第 %d 步。", taskID, stepOrder)), nil
+		return mcp.NewToolResultText(i18n.T("✅ 已删除任务 %s 的第 %d 步。", taskID, stepOrder)), nil
 	}
 
 	if subtaskID != "" {
-		return mcp.NewToolResultText(fmt.Sprintf("✅ 已删除子任务 %s。", subtaskID)), nil
+		return mcp.NewToolResultText(i18n.T("✅ 已删除子任务 %s。", subtaskID)), nil
 	}
 
-	return mcp.NewToolResultError("请指定删除目标：subtask_id、step_order 或 delete_scope=\"remaining\""), nil
+	return mcp.NewToolResultError(i18n.T("请指定删除目标：subtask_id、step_order 或 delete_scope=\"remaining\"")), nil
 }
 
 func finishChain(sm *SessionManager, taskID string) (*mcp.CallToolResult, error) {
 	if taskID == "" {
-		return mcp.NewToolResultError("finish 模式需要 task_id 参数"), nil
+		return mcp.NewToolResultError(i18n.T("finish 模式需要 task_id 参数")), nil
 	}
 
 	// 标记状态
@@ -591,8 +686,12 @@ func finishChain(sm *SessionManager, taskID string) (*mcp.CallToolResult, error)
 		chain.Status = "finished"
 		// 也可以 delete(sm.TaskChains, taskID) 来清理内存
 	}
+	if chain, ok := sm.TaskChainsV2[taskID]; ok {
+		chain.Status = "finished"
+		saveTaskChainV2(sm, chain)
+	}
 
-	return mcp.NewToolResultText(fmt.Sprintf(`
+	return mcp.NewToolResultText(i18n.T(`
 ══════════════════════════════════════════════════════════════
                     【任务链完成】%s
 ══════════════════════════════════════════════════════════════
@@ -608,12 +707,12 @@ func finishChain(sm *SessionManager, taskID string) (*mcp.CallToolResult, error)
 // ==================== V2 自适应任务链函数 ====================
 
 // initTaskChainV2 初始化 V2 任务链
-func initTaskChainV2(sm *SessionManager, taskID, description string, plan []map[string]interface{}) (*mcp.CallToolResult, error) {
+func initTaskChainV2(sm *SessionManager, taskID, description string, plan []map[string]interface{}, analysisID string) (*mcp.CallToolResult, error) {
 	if taskID == "" {
-		return mcp.NewToolResultError("step 模式需要 task_id 参数"), nil
+		return mcp.NewToolResultError(i18n.T("step 模式需要 task_id 参数")), nil
 	}
 	if len(plan) == 0 {
-		return mcp.NewToolResultError("step 模式需要 plan 参数"), nil
+		return mcp.NewToolResultError(i18n.T("step 模式需要 plan 参数")), nil
 	}
 
 	// 1. 解析 Plan 并创建 Steps（支持小数编号）
@@ -637,13 +736,20 @@ func initTaskChainV2(sm *SessionManager, taskID, description string, plan []map[
 	if sm.TaskChainsV2 == nil {
 		sm.TaskChainsV2 = make(map[string]*TaskChainV2)
 	}
-	sm.TaskChainsV2[taskID] = &TaskChainV2{
+	chain := &TaskChainV2{
 		TaskID:      taskID,
 		Description: description,
 		Steps:       steps,
 		CurrentStep: 1.0,
 		Status:      "running",
+		CreatedAt:   time.Now(),
 	}
+	if analysisID != "" {
+		if err := linkBriefingToChain(context.Background(), sm, analysisID, chain); err != nil {
+			return mcp.NewToolResultError(i18n.T("关联分析简报失败: %v", err)), nil
+		}
+	}
+	sm.TaskChainsV2[taskID] = chain
 
 	// 3. 自动开始第一步
 	return startStepV2(sm, taskID, 1.0)
@@ -652,7 +758,7 @@ func initTaskChainV2(sm *SessionManager, taskID, description string, plan []map[
 // startStepV2 开始执行指定步骤
 func startStepV2(sm *SessionManager, taskID string, stepNumber float64) (*mcp.CallToolResult, error) {
 	if taskID == "" {
-		return mcp.NewToolResultError("start 模式需要 task_id 参数"), nil
+		return mcp.NewToolResultError(i18n.T("start 模式需要 task_id 参数")), nil
 	}
 
 	// 获取任务链
@@ -661,7 +767,7 @@ func startStepV2(sm *SessionManager, taskID string, stepNumber float64) (*mcp.Ca
 	}
 	chain, ok := sm.TaskChainsV2[taskID]
 	if !ok {
-		return mcp.NewToolResultError(fmt.Sprintf("任务 %s 不存在，请先使用 mode='step' 初始化", taskID)), nil
+		return mcp.NewToolResultError(i18n.T("任务 %s 不存在，请先使用 mode='step' 初始化", taskID)), nil
 	}
 
 	// 查找目标步骤
@@ -673,21 +779,22 @@ func startStepV2(sm *SessionManager, taskID string, stepNumber float64) (*mcp.Ca
 		}
 	}
 	if targetStep == nil {
-		return mcp.NewToolResultError(fmt.Sprintf("步骤 %.1f 不存在", stepNumber)), nil
+		return mcp.NewToolResultError(i18n.T("步骤 %.1f 不存在", stepNumber)), nil
 	}
 
 	// 检查状态
 	if targetStep.Status != StepStatusTodo {
-		return mcp.NewToolResultError(fmt.Sprintf("步骤 %.1f 状态为 %s，无法开始", stepNumber, targetStep.Status)), nil
+		return mcp.NewToolResultError(i18n.T("步骤 %.1f 状态为 %s，无法开始", stepNumber, targetStep.Status)), nil
 	}
 
 	// 更新状态
 	targetStep.Status = StepStatusInProgress
 	chain.CurrentStep = stepNumber
+	saveTaskChainV2(sm, chain)
 
 	// 构建输出
 	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf(`
+	sb.WriteString(i18n.T(`
 ══════════════════════════════════════════════════════════════
                     【Step %.1f 开始】%s
 ══════════════════════════════════════════════════════════════
@@ -697,11 +804,15 @@ func startStepV2(sm *SessionManager, taskID string, stepNumber float64) (*mcp.Ca
 **当前步骤**: %s
 `, stepNumber, targetStep.Name, chain.Description, targetStep.Name))
 
+	if chain.AnalysisID != "" {
+		sb.WriteString(i18n.T("**分析简报**: %s (manager_analyze(step=2, task_id=\"%s\") 可重新生成策略)\n", chain.AnalysisID, chain.AnalysisID))
+	}
+
 	if targetStep.Input != "" {
-		sb.WriteString(fmt.Sprintf("\n**建议调用**: %s\n", targetStep.Input))
+		sb.WriteString(i18n.T("\n**建议调用**: %s\n", targetStep.Input))
 	}
 
-	sb.WriteString(fmt.Sprintf(`
+	sb.WriteString(i18n.T(`
 ---
 
 ⚠️ **重要**: 完成此步骤后，必须调用：
@@ -725,16 +836,16 @@ task_chain(mode="complete", task_id="%s", step_number=%.1f, summary="你的总
 // completeStepV2 完成步骤并提交 summary
 func completeStepV2(sm *SessionManager, taskID string, stepNumber float64, summary string) (*mcp.CallToolResult, error) {
 	if taskID == "" {
-		return mcp.NewToolResultError("complete 模式需要 task_id 参数"), nil
+		return mcp.NewToolResultError(i18n.T("complete 模式需要 task_id 参数")), nil
 	}
 	if summary == "" {
-		return mcp.NewToolResultError("complete 模式必须提供 summary 参数"), nil
+		return mcp.NewToolResultError(i18n.T("complete 模式必须提供 summary 参数")), nil
 	}
 
 	// 获取任务链
 	chain, ok := sm.TaskChainsV2[taskID]
 	if !ok {
-		return mcp.NewToolResultError(fmt.Sprintf("任务 %s 不存在", taskID)), nil
+		return mcp.NewToolResultError(i18n.T("任务 %s 不存在", taskID)), nil
 	}
 
 	// 查找目标步骤
@@ -748,17 +859,18 @@ func completeStepV2(sm *SessionManager, taskID string, stepNumber float64, summa
 		}
 	}
 	if targetStep == nil {
-		return mcp.NewToolResultError(fmt.Sprintf("步骤 %.1f 不存在", stepNumber)), nil
+		return mcp.NewToolResultError(i18n.T("步骤 %.1f 不存在", stepNumber)), nil
 	}
 
 	// 检查状态
 	if targetStep.Status != StepStatusInProgress {
-		return mcp.NewToolResultError(fmt.Sprintf("步骤 %.1f 状态为 %s，无法完成", stepNumber, targetStep.Status)), nil
+		return mcp.NewToolResultError(i18n.T("步骤 %.1f 状态为 %s，无法完成", stepNumber, targetStep.Status)), nil
 	}
 
 	// 更新状态
 	targetStep.Summary = summary
 	targetStep.Status = StepStatusComplete
+	saveTaskChainV2(sm, chain)
 
 	// 返回决策点界面
 	return renderDecisionPoint(chain, targetIdx)
@@ -769,7 +881,7 @@ func renderDecisionPoint(chain *TaskChainV2, completedIdx int) (*mcp.CallToolRes
 	completedStep := chain.Steps[completedIdx]
 
 	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf(`
+	sb.WriteString(i18n.T(`
 ══════════════════════════════════════════════════════════════
                     【Step %.1f 已完成】%s
 ══════════════════════════════════════════════════════════════
@@ -794,18 +906,18 @@ func renderDecisionPoint(chain *TaskChainV2, completedIdx int) (*mcp.CallToolRes
 	}
 
 	if nextStep != nil {
-		sb.WriteString(fmt.Sprintf(`1️⃣ **继续下一步** (Step %.1f)
+		sb.WriteString(i18n.T(`1️⃣ **继续下一步** (Step %.1f)
    task_chain(mode="start", task_id="%s", step_number=%.1f)
 
 `, nextStep.Number, chain.TaskID, nextStep.Number))
 	} else {
-		sb.WriteString(fmt.Sprintf(`1️⃣ **完成整个任务链**
+		sb.WriteString(i18n.T(`1️⃣ **完成整个任务链**
    task_chain(mode="finish", task_id="%s")
 
 `, chain.TaskID))
 	}
 
-	sb.WriteString(fmt.Sprintf(`2️⃣ **插入新步骤**（在当前步骤 %.1f 之后）
+	sb.WriteString(i18n.T(`2️⃣ **插入新步骤**（在当前步骤 %.1f 之后）
    task_chain(mode="insert", task_id="%s", after=%.1f, insert_plan=[
      {name: "新步骤名称", input: "建议的工具调用"}
    ])
@@ -832,7 +944,7 @@ func renderDecisionPoint(chain *TaskChainV2, completedIdx int) (*mcp.CallToolRes
 		}
 	}
 
-	sb.WriteString("\n## 📋 剩余步骤预览\n\n")
+	sb.WriteString(i18n.T("\n## 📋 剩余步骤预览\n\n"))
 	if nextStep != nil {
 		// 显示剩余步骤
 		for _, step := range chain.Steps {
@@ -845,7 +957,7 @@ func renderDecisionPoint(chain *TaskChainV2, completedIdx int) (*mcp.CallToolRes
 			}
 		}
 	} else {
-		sb.WriteString("🎉 所有步骤已完成！\n")
+		sb.WriteString(i18n.T("🎉 所有步骤已完成！\n"))
 	}
 
 	sb.WriteString("\n══════════════════════════════════════════════════════════════\n")
@@ -856,15 +968,15 @@ func renderDecisionPoint(chain *TaskChainV2, completedIdx int) (*mcp.CallToolRes
 // insertStepsV2 插入步骤（支持小数编号）
 func insertStepsV2(sm *SessionManager, taskID string, after float64, insertPlan []map[string]interface{}) (*mcp.CallToolResult, error) {
 	if taskID == "" {
-		return mcp.NewToolResultError("insert 模式需要 task_id 参数"), nil
+		return mcp.NewToolResultError(i18n.T("insert 模式需要 task_id 参数")), nil
 	}
 	if len(insertPlan) == 0 {
-		return mcp.NewToolResultError("insert 模式需要 insert_plan 参数"), nil
+		return mcp.NewToolResultError(i18n.T("insert 模式需要 insert_plan 参数")), nil
 	}
 
 	chain, ok := sm.TaskChainsV2[taskID]
 	if !ok {
-		return mcp.NewToolResultError(fmt.Sprintf("任务 %s 不存在", taskID)), nil
+		return mcp.NewToolResultError(i18n.T("任务 %s 不存在", taskID)), nil
 	}
 
 	// 查找插入位置
@@ -878,7 +990,7 @@ func insertStepsV2(sm *SessionManager, taskID string, after float64, insertPlan
 		}
 	}
 	if afterStep == nil {
-		return mcp.NewToolResultError(fmt.Sprintf("步骤 %.1f 不存在", after)), nil
+		return mcp.NewToolResultError(i18n.T("步骤 %.1f 不存在", after)), nil
 	}
 
 	// 生成小数编号（1.1, 1.2, ... 或 2.1, 2.2, ...）
@@ -903,14 +1015,15 @@ func insertStepsV2(sm *SessionManager, taskID string, after float64, insertPlan
 
 	// 插入到步骤列表
 	chain.Steps = append(chain.Steps[:insertIdx], append(newSteps, chain.Steps[insertIdx:]...)...)
+	saveTaskChainV2(sm, chain)
 
 	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("✅ 已插入 %d 个新步骤到 Step %.1f 之后\n\n", len(insertPlan), after))
-	sb.WriteString("**新增步骤**:\n")
+	sb.WriteString(i18n.T("✅ 已插入 %d 个新步骤到 Step %.1f 之后\n\n", len(insertPlan), after))
+	sb.WriteString(i18n.T("**新增步骤**:\n"))
 	for _, step := range newSteps {
 		sb.WriteString(fmt.Sprintf("  • %.1f: %s\n", step.Number, step.Name))
 	}
-	sb.WriteString(fmt.Sprintf("\n**当前步骤总数**: %d\n", len(chain.Steps)))
+	sb.WriteString(i18n.T("\n**当前步骤总数**: %d\n", len(chain.Steps)))
 
 	return mcp.NewToolResultText(sb.String()), nil
 }
@@ -918,15 +1031,15 @@ func insertStepsV2(sm *SessionManager, taskID string, after float64, insertPlan
 // updateStepsV2 更新步骤
 func updateStepsV2(sm *SessionManager, taskID string, from float64, updatePlan []map[string]interface{}) (*mcp.CallToolResult, error) {
 	if taskID == "" {
-		return mcp.NewToolResultError("update 模式需要 task_id 参数"), nil
+		return mcp.NewToolResultError(i18n.T("update 模式需要 task_id 参数")), nil
 	}
 	if len(updatePlan) == 0 {
-		return mcp.NewToolResultError("update 模式需要 update_plan 参数"), nil
+		return mcp.NewToolResultError(i18n.T("update 模式需要 update_plan 参数")), nil
 	}
 
 	chain, ok := sm.TaskChainsV2[taskID]
 	if !ok {
-		return mcp.NewToolResultError(fmt.Sprintf("任务 %s 不存在", taskID)), nil
+		return mcp.NewToolResultError(i18n.T("任务 %s 不存在", taskID)), nil
 	}
 
 	// 查找起始位置
@@ -959,10 +1072,11 @@ func updateStepsV2(sm *SessionManager, taskID string, from float64, updatePlan [
 	// 保留已完成和正在执行的步骤，替换后续步骤
 	keptSteps := chain.Steps[:startIdx+1]
 	chain.Steps = append(keptSteps, newSteps...)
+	saveTaskChainV2(sm, chain)
 
 	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("✅ 已从 Step %.1f 开始更新 %d 个步骤\n\n", from, len(updatePlan)))
-	sb.WriteString("**更新后的步骤**:\n")
+	sb.WriteString(i18n.T("✅ 已从 Step %.1f 开始更新 %d 个步骤\n\n", from, len(updatePlan)))
+	sb.WriteString(i18n.T("**更新后的步骤**:\n"))
 	for _, step := range newSteps {
 		sb.WriteString(fmt.Sprintf("  • %.1f: %s\n", step.Number, step.Name))
 	}
@@ -973,12 +1087,12 @@ func updateStepsV2(sm *SessionManager, taskID string, from float64, updatePlan [
 // deleteStepsV2 删除步骤
 func deleteStepsV2(sm *SessionManager, taskID string, stepToDelete float64, deleteScope string) (*mcp.CallToolResult, error) {
 	if taskID == "" {
-		return mcp.NewToolResultError("delete 模式需要 task_id 参数"), nil
+		return mcp.NewToolResultError(i18n.T("delete 模式需要 task_id 参数")), nil
 	}
 
 	chain, ok := sm.TaskChainsV2[taskID]
 	if !ok {
-		return mcp.NewToolResultError(fmt.Sprintf("任务 %s 不存在", taskID)), nil
+		return mcp.NewToolResultError(i18n.T("任务 %s 不存在", taskID)), nil
 	}
 
 	if deleteScope == "remaining" {
@@ -991,7 +1105,8 @@ func deleteStepsV2(sm *SessionManager, taskID string, stepToDelete float64, dele
 		}
 		deleted := len(chain.Steps) - len(newSteps)
 		chain.Steps = newSteps
-		return mcp.NewToolResultText(fmt.Sprintf("✅ 已删除 %d 个待执行步骤，保留 %d 个已完成/进行中的步骤", deleted, len(newSteps))), nil
+		saveTaskChainV2(sm, chain)
+		return mcp.NewToolResultText(i18n.T("✅ 已删除 %d 个待执行步骤，保留 %d 个已完成/进行中的步骤", deleted, len(newSteps))), nil
 	}
 
 	// 删除单个步骤
@@ -999,14 +1114,15 @@ func deleteStepsV2(sm *SessionManager, taskID string, stepToDelete float64, dele
 		for i, step := range chain.Steps {
 			if step.Number == stepToDelete {
 				if step.Status == StepStatusInProgress {
-					return mcp.NewToolResultError(fmt.Sprintf("无法删除正在执行的步骤 %.1f，请先完成", stepToDelete)), nil
+					return mcp.NewToolResultError(i18n.T("无法删除正在执行的步骤 %.1f，请先完成", stepToDelete)), nil
 				}
 				chain.Steps = append(chain.Steps[:i], chain.Steps[i+1:]...)
-				return mcp.NewToolResultText(fmt.Sprintf("✅ 已删除步骤 %.1f: %s", stepToDelete, step.Name)), nil
+				saveTaskChainV2(sm, chain)
+				return mcp.NewToolResultText(i18n.T("✅ 已删除步骤 %.1f: %s", stepToDelete, step.Name)), nil
 			}
 		}
-		return mcp.NewToolResultError(fmt.Sprintf("步骤 %.1f 不存在", stepToDelete)), nil
+		return mcp.NewToolResultError(i18n.T("步骤 %.1f 不存在", stepToDelete)), nil
 	}
 
-	return mcp.NewToolResultError("请指定删除目标：step_to_delete 或 delete_scope=\"remaining\""), nil
+	return mcp.NewToolResultError(i18n.T("请指定删除目标：step_to_delete 或 delete_scope=\"remaining\"")), nil
 }
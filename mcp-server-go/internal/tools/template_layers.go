@@ -0,0 +1,93 @@
+package tools
+
+import (
+	"errors"
+	"fmt"
+	"mcp-server-go/internal/i18n"
+	"mcp-server-go/internal/services"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ============================================================================
+// 分层 Markdown 模板 (协议模板、Wiki 书写风格)
+// 内置 < 全局 (~/.mpm/<dir>) < 项目 (.mcp-config/<dir>)，同名者后加载的覆盖先加载的
+// ============================================================================
+
+// 模板来源
+const (
+	templateSourceBuiltin = "builtin"
+	templateSourceGlobal  = "global"
+	templateSourceProject = "project"
+)
+
+var (
+	templateFrontmatterRe = regexp.MustCompile(`(?s)^---\s*\n(.*?)\n---\s*\n?`)
+	templateNameRe        = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+)
+
+// loadTemplateFiles 按 全局 < 项目 的顺序把 dirName 下扩展名属于 exts 的文件交给 read，
+// read 返回错误的文件跳过并记为警告
+func loadTemplateFiles(projectRoot, dirName string, exts []string, read func(source, path string) error) []string {
+	var layers []struct{ source, dir string }
+	if dir := services.GlobalConfigDir(); dir != "" {
+		layers = append(layers, struct{ source, dir string }{templateSourceGlobal, filepath.Join(dir, dirName)})
+	}
+	if projectRoot != "" {
+		layers = append(layers, struct{ source, dir string }{templateSourceProject, filepath.Join(services.ProjectConfigDir(projectRoot), dirName)})
+	}
+
+	var warnings []string
+	for _, layer := range layers {
+		entries, err := os.ReadDir(layer.dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			if e.IsDir() || !containsString(exts, strings.ToLower(filepath.Ext(e.Name()))) {
+				continue
+			}
+			path := filepath.Join(layer.dir, e.Name())
+			if err := read(layer.source, path); err != nil {
+				warnings = append(warnings, fmt.Sprintf("%s: %v", path, err))
+			}
+		}
+	}
+	return warnings
+}
+
+// readTemplateFile 读取模板文件：可选的 YAML frontmatter 解析到 meta，返回其后的正文
+func readTemplateFile(path string, meta any) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	content := strings.TrimPrefix(string(data), "\ufeff")
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+
+	if m := templateFrontmatterRe.FindStringSubmatch(content); m != nil {
+		if err := yaml.Unmarshal([]byte(m[1]), meta); err != nil {
+			return "", errors.New(i18n.T("frontmatter 解析失败: %v", err))
+		}
+		content = content[len(m[0]):]
+	}
+	return content, nil
+}
+
+// templateFileName frontmatter 未指定 name 时使用的名称 (去掉扩展名的文件名)
+func templateFileName(path string) string {
+	return strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+}
+
+func containsString(items []string, s string) bool {
+	for _, item := range items {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,434 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mcp-server-go/internal/core"
+	"mcp-server-go/internal/i18n"
+	"mcp-server-go/internal/services"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	// wikiIndexPage 索引页文件名
+	wikiIndexPage = "README.md"
+	// wikiRootPage 项目根目录 (".") 对应的页面
+	wikiRootPage = "_root.md"
+
+	wikiSymbolLimit   = 200
+	wikiDecisionLimit = 10
+	wikiMemoPool      = 500
+
+	wikiBlockBegin = "<!-- mpm:begin %s -->"
+	wikiBlockEnd   = "<!-- mpm:end %s -->"
+)
+
+// wikiBlockNotice 生成区块的首行说明
+func wikiBlockNotice() string {
+	return i18n.T("> 🤖 本区块由 `wiki_writer(mode=\"generate\")` 根据索引生成，重新生成时会被覆盖；区块外的手写内容会保留。\n")
+}
+
+// wikiCodeLang 代码块语言标记
+var wikiCodeLang = map[string]string{
+	".go": "go", ".py": "python", ".rs": "rust", ".js": "javascript", ".jsx": "jsx", ".mjs": "javascript",
+	".ts": "typescript", ".tsx": "tsx", ".java": "java", ".kt": "kotlin", ".cs": "csharp",
+	".c": "c", ".h": "c", ".cpp": "cpp", ".hpp": "cpp", ".cc": "cpp",
+}
+
+// WikiGenerateReport 生成结果
+type WikiGenerateReport struct {
+	OutputDir string   `json:"output_dir"`
+	Created   []string `json:"created"`
+	Updated   []string `json:"updated"`
+	Unchanged []string `json:"unchanged"`
+	Stale     []string `json:"stale"`   // 对应目录已不在索引中的生成页 (保留未删除)
+	Skipped   []string `json:"skipped"` // 区块标记损坏或没有区块 (非生成页) 而跳过的页面
+}
+
+// generateWiki 按目录生成 Wiki 页面：生成区块整体替换，区块外的内容保留
+func generateWiki(ctx context.Context, sm *SessionManager, ai *services.ASTIndexer, outputDir, scope string) (*WikiGenerateReport, error) {
+	if sm.ProjectRoot == "" {
+		return nil, errors.New(i18n.T("项目尚未初始化"))
+	}
+	if strings.TrimSpace(outputDir) == "" {
+		outputDir = sm.config().Wiki.OutputDir
+	}
+	relOut, err := services.RelativeEditPath(sm.ProjectRoot, outputDir)
+	if err != nil {
+		return nil, errors.New(i18n.T("输出目录无效: %v", err))
+	}
+	if relOut == "." {
+		return nil, errors.New(i18n.T("输出目录不能是项目根目录，请指定子目录 (如 docs/wiki)"))
+	}
+	absOut := filepath.Join(sm.ProjectRoot, filepath.FromSlash(relOut))
+
+	packages, err := ai.CollectPackages(sm.ProjectRoot, scope)
+	if err != nil {
+		return nil, err
+	}
+	if len(packages) == 0 {
+		return nil, errors.New(i18n.T("索引中没有符号 (scope=%q)", scope))
+	}
+
+	var memos []core.Memo
+	if sm.Memory != nil {
+		memos, _ = sm.Memory.QueryMemos(ctx, "", "", wikiMemoPool)
+	}
+	known := make(map[string]bool, len(packages))
+	for _, pkg := range packages {
+		known[pkg.Dir] = true
+	}
+
+	report := &WikiGenerateReport{OutputDir: relOut}
+	pages := make(map[string]bool)
+	for _, pkg := range packages {
+		page := wikiPagePath(pkg.Dir)
+		pages[page] = true
+		decisions := wikiDecisionMemos(sm.ProjectRoot, pkg, memos)
+		if err := writeWikiPage(absOut, page, renderWikiPackage(pkg, page, known, decisions), wikiPackageSkeleton(pkg), report); err != nil {
+			return nil, err
+		}
+	}
+	pages[wikiIndexPage] = true
+	if err := writeWikiPage(absOut, wikiIndexPage, renderWikiIndex(packages), wikiIndexSkeleton(sm.ProjectRoot), report); err != nil {
+		return nil, err
+	}
+
+	// 只在全量生成时检查失效页面，避免 scope 生成把范围外的页面误报为失效
+	if strings.Trim(strings.TrimSpace(scope), "./") == "" {
+		report.Stale = staleWikiPages(absOut, pages)
+	}
+	return report, nil
+}
+
+// wikiPagePath 目录对应的页面 (相对输出目录)
+func wikiPagePath(dir string) string {
+	if dir == "." || dir == "" {
+		return wikiRootPage
+	}
+	return dir + ".md"
+}
+
+// wikiLink 从 fromPage 指向 toPage 的相对链接
+func wikiLink(fromPage, toPage string) string {
+	rel, err := filepath.Rel(filepath.Dir(filepath.FromSlash(fromPage)), filepath.FromSlash(toPage))
+	if err != nil {
+		return toPage
+	}
+	return filepath.ToSlash(rel)
+}
+
+func wikiBlock(name, body string) string {
+	return fmt.Sprintf(wikiBlockBegin, name) + "\n" + strings.TrimRight(body, "\n") + "\n" + fmt.Sprintf(wikiBlockEnd, name)
+}
+
+// wikiPackageSkeleton 新页面的完整骨架 (生成区块之间预留手写区)
+func wikiPackageSkeleton(pkg services.PackageInfo) string {
+	title := pkg.Dir
+	if title == "." {
+		title = i18n.T("(项目根目录)")
+	}
+	return i18n.T("# 📦 %s\n\n%s\n\n## 概述\n\n_在此补充本包的职责与使用方式（手写内容，重新生成时保留）。_\n\n%s\n\n%s\n\n%s\n",
+		title, "{{overview}}", "{{symbols}}", "{{dependencies}}", "{{decisions}}")
+}
+
+func wikiIndexSkeleton(projectRoot string) string {
+	return i18n.T("# 📚 %s Wiki\n\n_在此补充项目简介（手写内容，重新生成时保留）。_\n\n{{index}}\n", filepath.Base(projectRoot))
+}
+
+// renderWikiPackage 包页面的各生成区块 (区块名 -> 内容)，按出现顺序返回
+func renderWikiPackage(pkg services.PackageInfo, page string, known map[string]bool, decisions []core.Memo) []wikiSection {
+	var exported []services.Node
+	for _, n := range pkg.Symbols {
+		if services.IsExportedSymbol(n) {
+			exported = append(exported, n)
+		}
+	}
+
+	var overview strings.Builder
+	overview.WriteString(wikiBlockNotice() + "\n")
+	files := make([]string, len(pkg.Files))
+	for i, f := range pkg.Files {
+		files[i] = "`" + path.Base(f) + "`"
+	}
+	overview.WriteString(i18n.T("- **文件** (%d): %s\n", len(pkg.Files), strings.Join(files, ", ")))
+	overview.WriteString(i18n.T("- **符号**: %d 个，其中导出 %d 个\n", len(pkg.Symbols), len(exported)))
+
+	var symbols strings.Builder
+	symbols.WriteString(i18n.T("## 导出符号\n\n"))
+	if len(exported) == 0 {
+		symbols.WriteString(i18n.T("_暂无导出符号_\n"))
+	}
+	currentFile := ""
+	for i, n := range exported {
+		if i >= wikiSymbolLimit {
+			symbols.WriteString(i18n.T("\n_... 还有 %d 个导出符号未列出，可用 `code_search` 查询_\n", len(exported)-i))
+			break
+		}
+		if n.FilePath != currentFile {
+			currentFile = n.FilePath
+			symbols.WriteString(fmt.Sprintf("### `%s`\n\n", path.Base(n.FilePath)))
+		}
+		name := n.Name
+		if n.QualifiedName != "" && n.QualifiedName != n.Name {
+			name = n.QualifiedName
+		}
+		symbols.WriteString(fmt.Sprintf("#### `%s`\n\n_%s · L%d_\n\n", name, n.NodeType, n.LineStart))
+		if sig := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(n.Signature), "{")); sig != "" {
+			symbols.WriteString(fmt.Sprintf("```%s\n%s\n```\n\n", wikiCodeLang[strings.ToLower(path.Ext(n.FilePath))], sig))
+		}
+		if doc := strings.TrimSpace(n.Doc); doc != "" {
+			symbols.WriteString(doc + "\n\n")
+		}
+	}
+
+	var deps strings.Builder
+	deps.WriteString(i18n.T("## 依赖关系\n\n"))
+	renderDeps := func(title string, list []services.PackageDep) {
+		if len(list) == 0 {
+			return
+		}
+		deps.WriteString(title + "\n\n")
+		for _, d := range list {
+			target := "`" + d.Dir + "`"
+			if known[d.Dir] {
+				target = fmt.Sprintf("[`%s`](%s)", d.Dir, wikiLink(page, wikiPagePath(d.Dir)))
+			}
+			deps.WriteString(i18n.T("- %s — %d 次调用\n", target, d.Calls))
+		}
+		deps.WriteString("\n")
+	}
+	renderDeps(i18n.T("**依赖** (本包调用的其他包):"), pkg.DependsOn)
+	renderDeps(i18n.T("**被依赖** (调用本包的其他包):"), pkg.UsedBy)
+	if len(pkg.DependsOn) == 0 && len(pkg.UsedBy) == 0 {
+		deps.WriteString(i18n.T("_索引中没有跨包调用_\n"))
+	}
+
+	var dec strings.Builder
+	dec.WriteString(i18n.T("## 设计决策\n\n"))
+	if len(decisions) == 0 {
+		dec.WriteString(i18n.T("_暂无相关备忘（用 `memo` 记录本包的变更与决策后重新生成）_\n"))
+	}
+	for _, m := range decisions {
+		entity := ""
+		if m.Entity != "" {
+			entity = fmt.Sprintf(" `%s`", m.Entity)
+		}
+		dec.WriteString(fmt.Sprintf("- **%s** [%s]%s %s: %s _(#%d)_\n",
+			m.Timestamp.Format("2006-01-02"), m.Category, entity, m.Act, truncateLine(m.Content, 200), m.ID))
+	}
+
+	return []wikiSection{
+		{"overview", overview.String()},
+		{"symbols", symbols.String()},
+		{"dependencies", deps.String()},
+		{"decisions", dec.String()},
+	}
+}
+
+func renderWikiIndex(packages []services.PackageInfo) []wikiSection {
+	var sb strings.Builder
+	sb.WriteString(wikiBlockNotice() + "\n")
+	sb.WriteString(i18n.T("## 包索引\n\n| 包 | 文件 | 导出符号 | 依赖 | 被依赖 |\n|----|------|---------|------|--------|\n"))
+	for _, pkg := range packages {
+		exported := 0
+		for _, n := range pkg.Symbols {
+			if services.IsExportedSymbol(n) {
+				exported++
+			}
+		}
+		sb.WriteString(fmt.Sprintf("| [`%s`](%s) | %d | %d | %d | %d |\n",
+			pkg.Dir, wikiPagePath(pkg.Dir), len(pkg.Files), exported, len(pkg.DependsOn), len(pkg.UsedBy)))
+	}
+	return []wikiSection{{"index", sb.String()}}
+}
+
+type wikiSection struct {
+	Name string
+	Body string
+}
+
+// writeWikiPage 新页面按骨架写入；已有页面只替换生成区块
+func writeWikiPage(absOut, page string, sections []wikiSection, skeleton string, report *WikiGenerateReport) error {
+	full := filepath.Join(absOut, filepath.FromSlash(page))
+	existing, err := os.ReadFile(full)
+	isNew := os.IsNotExist(err)
+	var content string
+	switch {
+	case isNew:
+		content = skeleton
+		for _, s := range sections {
+			content = strings.Replace(content, "{{"+s.Name+"}}", wikiBlock(s.Name, s.Body), 1)
+		}
+	case err != nil:
+		return err
+	default:
+		merged, mergeErr := mergeWikiBlocks(string(existing), sections)
+		if mergeErr != nil {
+			report.Skipped = append(report.Skipped, fmt.Sprintf("%s (%v)", page, mergeErr))
+			return nil
+		}
+		if merged == string(existing) {
+			report.Unchanged = append(report.Unchanged, page)
+			return nil
+		}
+		content = merged
+	}
+
+	if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+		return err
+	}
+	if isNew {
+		report.Created = append(report.Created, page)
+	} else {
+		report.Updated = append(report.Updated, page)
+	}
+	return nil
+}
+
+// mergeWikiBlocks 用新内容替换已有页面中的同名生成区块；页面中缺失的区块追加到末尾。
+// 区块标记不成对，或页面中一个区块都没有 (不是生成页，如项目自己的 README.md) 时返回错误，避免改动手写文件。
+func mergeWikiBlocks(existing string, sections []wikiSection) (string, error) {
+	bodies := make(map[string]string, len(sections))
+	for _, s := range sections {
+		bodies[s.Name] = s.Body
+	}
+	used := make(map[string]bool)
+
+	var out strings.Builder
+	rest := existing
+	for {
+		start := strings.Index(rest, "<!-- mpm:begin ")
+		if start < 0 {
+			out.WriteString(rest)
+			break
+		}
+		headEnd := strings.Index(rest[start:], "-->")
+		if headEnd < 0 {
+			return "", errors.New(i18n.T("区块起始标记不完整"))
+		}
+		name := strings.TrimSpace(strings.TrimPrefix(rest[start:start+headEnd], "<!-- mpm:begin "))
+		endMarker := fmt.Sprintf(wikiBlockEnd, name)
+		end := strings.Index(rest[start:], endMarker)
+		if end < 0 {
+			return "", errors.New(i18n.T("区块 %s 缺少结束标记", name))
+		}
+		if used[name] {
+			return "", errors.New(i18n.T("区块 %s 重复", name))
+		}
+		used[name] = true
+		end += start + len(endMarker)
+
+		out.WriteString(rest[:start])
+		if body, ok := bodies[name]; ok {
+			out.WriteString(wikiBlock(name, body))
+		} else {
+			out.WriteString(rest[start:end])
+		}
+		rest = rest[end:]
+	}
+
+	if len(used) == 0 {
+		return "", errors.New(i18n.T("页面中没有 mpm:begin 区块，不是 wiki_writer 生成的文件"))
+	}
+
+	merged := out.String()
+	for _, s := range sections {
+		if !used[s.Name] {
+			merged = strings.TrimRight(merged, "\n") + "\n\n" + wikiBlock(s.Name, s.Body) + "\n"
+		}
+	}
+	return merged, nil
+}
+
+// wikiDecisionMemos 路径落在该目录下的备忘 (最新在前)；没有路径时按实体名匹配导出符号
+func wikiDecisionMemos(projectRoot string, pkg services.PackageInfo, memos []core.Memo) []core.Memo {
+	symbols := make(map[string]bool)
+	for _, n := range pkg.Symbols {
+		if services.IsExportedSymbol(n) {
+			symbols[n.Name] = true
+		}
+	}
+	var matched []core.Memo
+	for _, m := range memos {
+		hit := false
+		for _, p := range strings.FieldsFunc(m.Path, func(r rune) bool { return r == ',' || r == ';' || r == '\n' }) {
+			rel, err := services.RelativeEditPath(projectRoot, strings.TrimSpace(p))
+			if err != nil || rel == "" {
+				continue
+			}
+			if rel == pkg.Dir || path.Dir(rel) == pkg.Dir {
+				hit = true
+				break
+			}
+		}
+		if !hit && strings.TrimSpace(m.Path) == "" && symbols[strings.TrimSpace(m.Entity)] {
+			hit = true
+		}
+		if hit {
+			matched = append(matched, m)
+			if len(matched) >= wikiDecisionLimit {
+				break
+			}
+		}
+	}
+	return matched
+}
+
+// staleWikiPages 输出目录中由生成器创建、但对应目录已不在索引中的页面
+func staleWikiPages(absOut string, pages map[string]bool) []string {
+	var stale []string
+	marker := fmt.Sprintf(wikiBlockBegin, "overview")
+	_ = filepath.WalkDir(absOut, func(p string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(p, ".md") {
+			return nil
+		}
+		rel, err := filepath.Rel(absOut, p)
+		if err != nil {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if pages[rel] {
+			return nil
+		}
+		if data, err := os.ReadFile(p); err == nil && strings.Contains(string(data), marker) {
+			stale = append(stale, rel)
+		}
+		return nil
+	})
+	sort.Strings(stale)
+	return stale
+}
+
+// renderWikiReport 生成结果摘要
+func renderWikiReport(r *WikiGenerateReport) string {
+	var sb strings.Builder
+	sb.WriteString(i18n.T("✅ Wiki 已生成到 `%s/` (新建 %d，更新 %d，未变化 %d)\n", r.OutputDir, len(r.Created), len(r.Updated), len(r.Unchanged)))
+	list := func(title string, items []string) {
+		if len(items) == 0 {
+			return
+		}
+		sb.WriteString(fmt.Sprintf("\n**%s** (%d):\n", title, len(items)))
+		for i, p := range items {
+			if i >= 20 {
+				sb.WriteString(i18n.T("- ... 还有 %d 个\n", len(items)-i))
+				break
+			}
+			sb.WriteString("- " + p + "\n")
+		}
+	}
+	list(i18n.T("新建"), r.Created)
+	list(i18n.T("更新"), r.Updated)
+	list(i18n.T("⚠️ 已跳过 (未写入)"), r.Skipped)
+	list(i18n.T("⚠️ 对应目录已不在索引中 (未删除，请手动处理)"), r.Stale)
+	sb.WriteString(i18n.T("\n> 手写内容请写在 `<!-- mpm:begin/end -->` 区块之外，重新生成时会保留。\n"))
+	return sb.String()
+}
@@ -0,0 +1,174 @@
+package tools
+
+import (
+	"context"
+	"database/sql"
+	"mcp-server-go/internal/core"
+	"mcp-server-go/internal/services"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func setupWikiProject(t *testing.T, symbols string) string {
+	t.Helper()
+	root := t.TempDir()
+	for rel, content := range map[string]string{
+		"core/store.go":  "package core\n\n// Store 持久化存储\ntype Store struct{}\n\n// Save 保存记录\nfunc (s *Store) Save() {}\n",
+		"api/handler.go": "package api\n\n// Handle 处理请求\nfunc Handle() {\n\tnew(core.Store).Save()\n}\n",
+	} {
+		p := filepath.Join(root, rel)
+		_ = os.MkdirAll(filepath.Dir(p), 0755)
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeWikiIndexDB(t, root, symbols)
+	return root
+}
+
+func writeWikiIndexDB(t *testing.T, root, symbols string) {
+	t.Helper()
+	_ = os.MkdirAll(filepath.Join(root, ".mcp-data"), 0755)
+	dbPath := filepath.Join(root, ".mcp-data", "symbols.db")
+	_ = os.Remove(dbPath)
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	for _, stmt := range []string{
+		`CREATE TABLE files (file_id INTEGER PRIMARY KEY, file_path TEXT UNIQUE)`,
+		`CREATE TABLE symbols (symbol_id INTEGER PRIMARY KEY, file_id INTEGER, name TEXT, qualified_name TEXT,
+			canonical_id TEXT, symbol_type TEXT, line_start INTEGER, line_end INTEGER, signature TEXT)`,
+		`CREATE TABLE calls (call_id INTEGER PRIMARY KEY, caller_id INTEGER, callee_name TEXT, call_line INTEGER, callee_id TEXT)`,
+		`INSERT INTO files VALUES (1, 'core/store.go'), (2, 'api/handler.go')`,
+		symbols,
+		`INSERT INTO calls VALUES (1, 3, 'Save', 5, 'func:core/store.go::Store.Save')`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("setup %q: %v", stmt, err)
+		}
+	}
+}
+
+func TestGenerateWikiPreservesHandWrittenContent(t *testing.T) {
+	root := setupWikiProject(t, `INSERT INTO symbols VALUES
+		(1, 1, 'Store', 'Store', 'struct:core/store.go::Store', 'struct', 4, 4, 'type Store struct{}'),
+		(2, 1, 'Save', 'Store.Save', 'func:core/store.go::Store.Save', 'method', 7, 7, 'func (s *Store) Save() {}'),
+		(3, 2, 'Handle', 'Handle', 'func:api/handler.go::Handle', 'function', 4, 6, 'func Handle() {')`)
+	mem, err := core.NewMemoryLayer(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if _, err := mem.AddMemos(ctx, []core.Memo{
+		{Category: "决策", Entity: "Store", Act: "选型", Path: "core/store.go", Content: "存储改用 SQLite WAL 模式"},
+		{Category: "修复", Entity: "Handle", Act: "修复", Path: "api/handler.go", Content: "处理空请求"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	sm := &SessionManager{Memory: mem, ProjectRoot: root}
+	ai := &services.ASTIndexer{}
+
+	report, err := generateWiki(ctx, sm, ai, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Created) != 3 {
+		t.Fatalf("expected core, api and index pages: %+v", report)
+	}
+	corePage := filepath.Join(root, "docs", "wiki", "core.md")
+	data, err := os.ReadFile(corePage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := string(data)
+	for _, want := range []string{"`Store.Save`", "func (s *Store) Save() {}", "Save 保存记录", "[`api`](api.md) — 1 次调用", "存储改用 SQLite WAL 模式"} {
+		if !strings.Contains(page, want) {
+			t.Fatalf("core page missing %q:\n%s", want, page)
+		}
+	}
+	if strings.Contains(page, "处理空请求") {
+		t.Fatalf("memos of other packages should not leak:\n%s", page)
+	}
+	index, _ := os.ReadFile(filepath.Join(root, "docs", "wiki", "README.md"))
+	if !strings.Contains(string(index), "[`core`](core.md)") {
+		t.Fatalf("index should link package pages:\n%s", index)
+	}
+
+	// 手写内容 + 新增符号后重新生成
+	page = strings.Replace(page, "_在此补充本包的职责与使用方式（手写内容，重新生成时保留）。_", "核心存储层，所有写入都经过 Store。", 1)
+	page += "\n## 运维备注\n\n备份每天凌晨执行。\n"
+	if err := os.WriteFile(corePage, []byte(page), 0644); err != nil {
+		t.Fatal(err)
+	}
+	writeWikiIndexDB(t, root, `INSERT INTO symbols VALUES
+		(1, 1, 'Store', 'Store', 'struct:core/store.go::Store', 'struct', 4, 4, 'type Store struct{}'),
+		(2, 1, 'Save', 'Store.Save', 'func:core/store.go::Store.Save', 'method', 7, 7, 'func (s *Store) Save() {}'),
+		(4, 1, 'Load', 'Store.Load', 'func:core/store.go::Store.Load', 'method', 9, 9, 'func (s *Store) Load() error {'),
+		(3, 2, 'Handle', 'Handle', 'func:api/handler.go::Handle', 'function', 4, 6, 'func Handle() {')`)
+
+	report, err = generateWiki(ctx, sm, ai, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Updated) != 2 || report.Updated[0] != "core.md" || len(report.Unchanged) != 1 || report.Unchanged[0] != "api.md" {
+		t.Fatalf("only core page and index should change: %+v", report)
+	}
+	data, _ = os.ReadFile(corePage)
+	page = string(data)
+	for _, want := range []string{"核心存储层，所有写入都经过 Store。", "备份每天凌晨执行。", "func (s *Store) Load() error"} {
+		if !strings.Contains(page, want) {
+			t.Fatalf("regenerated page missing %q:\n%s", want, page)
+		}
+	}
+	if strings.Count(page, "<!-- mpm:begin symbols -->") != 1 {
+		t.Fatalf("generated block should be replaced, not duplicated:\n%s", page)
+	}
+
+	// 删除的目录只提示；标记损坏的页面跳过
+	stale := filepath.Join(root, "docs", "wiki", "old.md")
+	_ = os.WriteFile(stale, []byte("<!-- mpm:begin overview -->\nx\n<!-- mpm:end overview -->\n"), 0644)
+	apiPage := filepath.Join(root, "docs", "wiki", "api.md")
+	_ = os.WriteFile(apiPage, []byte("# api\n<!-- mpm:begin symbols -->\n手写\n"), 0644)
+	report, err = generateWiki(ctx, sm, ai, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Stale) != 1 || report.Stale[0] != "old.md" {
+		t.Fatalf("stale page should be reported: %+v", report)
+	}
+	if len(report.Skipped) != 1 || !strings.Contains(report.Skipped[0], "api.md") {
+		t.Fatalf("broken page should be skipped: %+v", report)
+	}
+	if data, _ := os.ReadFile(apiPage); !strings.Contains(string(data), "手写") {
+		t.Fatalf("skipped page must stay untouched: %s", data)
+	}
+	if text := renderWikiReport(report); !strings.Contains(text, "old.md") || !strings.Contains(text, "缺少结束标记") {
+		t.Fatalf("report should mention stale and skipped pages: %s", text)
+	}
+
+	if _, err := generateWiki(ctx, sm, ai, "../outside", ""); err == nil {
+		t.Fatalf("output dir outside project should be rejected")
+	}
+	if _, err := generateWiki(ctx, sm, ai, ".", ""); err == nil {
+		t.Fatalf("project root should be rejected as output dir")
+	}
+
+	// 已有但没有生成区块的文件 (项目自己的 README) 不合并，记为跳过
+	readme := filepath.Join(root, "src", "README.md")
+	_ = os.MkdirAll(filepath.Dir(readme), 0755)
+	_ = os.WriteFile(readme, []byte("# 手写说明\n"), 0644)
+	report, err = generateWiki(ctx, sm, ai, "src", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Skipped) != 1 || !strings.Contains(report.Skipped[0], "README.md") {
+		t.Fatalf("file without markers should be skipped: %+v", report)
+	}
+	if data, _ := os.ReadFile(readme); string(data) != "# 手写说明\n" {
+		t.Fatalf("file without markers must stay untouched: %s", data)
+	}
+}
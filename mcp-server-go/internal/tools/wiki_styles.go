@@ -0,0 +1,289 @@
+package tools
+
+import (
+	"errors"
+	"fmt"
+	"mcp-server-go/internal/i18n"
+	"sort"
+	"strings"
+)
+
+const (
+	// wikiStyleDirName 全局 (~/.mpm/wiki_styles) 与项目 (.mcp-config/wiki_styles) 的风格模板目录
+	wikiStyleDirName = "wiki_styles"
+	// maxWikiStyleInheritDepth 继承链最大深度，防止过长或成环
+	maxWikiStyleInheritDepth = 8
+)
+
+// builtinWikiStyleOrder 内置风格的展示顺序 (与 outline 中的编号对应)
+var builtinWikiStyleOrder = []string{"technical", "tutorial", "reference", "blog"}
+
+// builtinWikiStyleDescription 内置风格的简介
+func builtinWikiStyleDescription(name string) string {
+	switch name {
+	case "technical":
+		return i18n.T("技术文档风格（简洁专业）")
+	case "tutorial":
+		return i18n.T("教程指南风格（循序渐进）")
+	case "reference":
+		return i18n.T("参考资料风格（详细完整）")
+	case "blog":
+		return i18n.T("博客风格（轻松活泼）")
+	}
+	return ""
+}
+
+// WikiStyle Wiki 书写风格模板
+type WikiStyle struct {
+	Name        string `yaml:"name" json:"name"`
+	Description string `yaml:"description" json:"description,omitempty"`
+	Extends     string `yaml:"extends" json:"extends,omitempty"` // 父风格名，按 "## " 章节合并：同名章节覆盖，新章节追加
+	Body        string `yaml:"-" json:"-"`
+	Source      string `yaml:"-" json:"source"` // builtin / global / project
+	Path        string `yaml:"-" json:"path,omitempty"`
+
+	overrides *WikiStyle // 被本风格覆盖的下一层同名风格，供 "extends: 自身名称" 继承
+}
+
+// loadWikiStyles 按 内置 < 全局 < 项目 加载风格模板，同名者后加载的覆盖先加载的。
+// 解析失败的文件跳过并返回警告。
+func loadWikiStyles(projectRoot string) ([]*WikiStyle, []string) {
+	byName := make(map[string]*WikiStyle)
+	for _, name := range builtinWikiStyleOrder {
+		byName[name] = &WikiStyle{
+			Name:        name,
+			Description: builtinWikiStyleDescription(name),
+			Body:        WikiStyleTemplates[name],
+			Source:      templateSourceBuiltin,
+		}
+	}
+	warnings := loadTemplateFiles(projectRoot, wikiStyleDirName, []string{".md"}, func(source, path string) error {
+		style, err := readWikiStyle(path)
+		if err != nil {
+			return err
+		}
+		style.Source = source
+		key := strings.ToLower(style.Name)
+		style.overrides = byName[key]
+		byName[key] = style
+		return nil
+	})
+
+	list := make([]*WikiStyle, 0, len(byName))
+	for _, style := range byName {
+		list = append(list, style)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		bi, bj := list[i].Source == templateSourceBuiltin, list[j].Source == templateSourceBuiltin
+		if bi != bj {
+			return bi
+		}
+		return list[i].Name < list[j].Name
+	})
+	return list, warnings
+}
+
+// readWikiStyle 读取风格文件：可选 YAML frontmatter (name/description/extends) + Markdown 正文
+func readWikiStyle(path string) (*WikiStyle, error) {
+	style := &WikiStyle{}
+	content, err := readTemplateFile(path, style)
+	if err != nil {
+		return nil, err
+	}
+	if style.Name == "" {
+		style.Name = templateFileName(path)
+	}
+	if !templateNameRe.MatchString(style.Name) {
+		return nil, errors.New(i18n.T("风格名无效: %q (仅限字母、数字、- 和 _)", style.Name))
+	}
+	if strings.TrimSpace(content) == "" && style.Extends == "" {
+		return nil, errors.New(i18n.T("风格正文为空且未继承其他风格"))
+	}
+	style.Extends = strings.TrimSpace(style.Extends)
+	style.Body = content
+	style.Path = path
+	return style, nil
+}
+
+func findWikiStyle(styles []*WikiStyle, name string) *WikiStyle {
+	for _, style := range styles {
+		if strings.EqualFold(style.Name, name) {
+			return style
+		}
+	}
+	return nil
+}
+
+// wikiStyleChain 返回从根祖先到 name 自身的继承链；
+// extends 与自身同名时继承被它覆盖的下一层 (项目 -> 全局 -> 内置)，便于只改内置风格的个别章节
+func wikiStyleChain(styles []*WikiStyle, name string) ([]*WikiStyle, error) {
+	style := findWikiStyle(styles, name)
+	if style == nil {
+		return nil, errors.New(i18n.T("风格 %s 不存在", name))
+	}
+	chain := []*WikiStyle{style}
+	seen := map[*WikiStyle]bool{style: true}
+	for style.Extends != "" {
+		parent := findWikiStyle(styles, style.Extends)
+		if strings.EqualFold(style.Extends, style.Name) {
+			parent = style.overrides
+		}
+		if parent == nil {
+			return nil, errors.New(i18n.T("风格 %s 继承的 %s 不存在", style.Name, style.Extends))
+		}
+		if seen[parent] {
+			return nil, errors.New(i18n.T("风格 %s 存在循环继承", name))
+		}
+		if len(chain) >= maxWikiStyleInheritDepth {
+			return nil, errors.New(i18n.T("风格 %s 继承层级超过 %d", name, maxWikiStyleInheritDepth))
+		}
+		seen[parent] = true
+		chain = append([]*WikiStyle{parent}, chain...)
+		style = parent
+	}
+	return chain, nil
+}
+
+// resolveWikiStyle 展开继承链，返回合并后的书写指南
+func resolveWikiStyle(styles []*WikiStyle, name string) (string, error) {
+	chain, err := wikiStyleChain(styles, name)
+	if err != nil {
+		return "", err
+	}
+	body := ""
+	for _, style := range chain {
+		body = mergeWikiStyleSections(body, style.Body)
+	}
+	return body, nil
+}
+
+// mergeWikiStyleSections 按 "## " 章节合并：子风格的同名章节覆盖父风格，新章节追加在末尾；
+// 子风格的标题段 (首个章节之前的内容) 非空时替换父风格的标题段。
+func mergeWikiStyleSections(parent, child string) string {
+	if strings.TrimSpace(parent) == "" {
+		return child
+	}
+	pHead, pSections := splitWikiStyleSections(parent)
+	cHead, cSections := splitWikiStyleSections(child)
+
+	head := pHead
+	if strings.TrimSpace(cHead) != "" {
+		head = cHead
+	}
+	override := make(map[string]string, len(cSections))
+	for _, s := range cSections {
+		override[s[0]] = s[1]
+	}
+
+	var sb strings.Builder
+	sb.WriteString(strings.TrimRight(head, "\n"))
+	sb.WriteString("\n")
+	used := make(map[string]bool)
+	for _, s := range pSections {
+		body := s[1]
+		if o, ok := override[s[0]]; ok {
+			body = o
+			used[s[0]] = true
+		}
+		sb.WriteString("\n")
+		sb.WriteString(strings.TrimRight(body, "\n"))
+		sb.WriteString("\n")
+	}
+	for _, s := range cSections {
+		if !used[s[0]] {
+			sb.WriteString("\n")
+			sb.WriteString(strings.TrimRight(s[1], "\n"))
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
+
+// splitWikiStyleSections 拆分为标题段与 [标题, 章节全文] 列表
+func splitWikiStyleSections(body string) (string, [][2]string) {
+	var head strings.Builder
+	var sections [][2]string
+	for _, line := range strings.SplitAfter(body, "\n") {
+		if strings.HasPrefix(line, "## ") {
+			sections = append(sections, [2]string{strings.TrimSpace(line[3:]), line})
+			continue
+		}
+		if len(sections) == 0 {
+			head.WriteString(line)
+		} else {
+			sections[len(sections)-1][1] += line
+		}
+	}
+	return head.String(), sections
+}
+
+// validateWikiStyles 检查继承链是否可解析，返回问题列表 (含加载阶段的警告)
+func validateWikiStyles(styles []*WikiStyle, warnings []string) []string {
+	problems := append([]string(nil), warnings...)
+	for _, style := range styles {
+		if style.Extends == "" {
+			continue
+		}
+		if _, err := wikiStyleChain(styles, style.Name); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", wikiStyleLocation(style), err))
+		}
+	}
+	return problems
+}
+
+func wikiStyleLocation(style *WikiStyle) string {
+	if style.Path == "" {
+		return style.Source
+	}
+	return fmt.Sprintf("%s:%s", style.Source, style.Path)
+}
+
+// generateStyleGuide 生成书写指南：命中风格名 (内置或自定义) 时展开继承链，否则视为自定义要求
+func generateStyleGuide(projectRoot, style string) (string, error) {
+	styles, _ := loadWikiStyles(projectRoot)
+	name := strings.TrimSpace(style)
+	if i := indexOfBuiltinNumber(name); i >= 0 {
+		name = builtinWikiStyleOrder[i]
+	}
+	if findWikiStyle(styles, name) != nil {
+		return resolveWikiStyle(styles, name)
+	}
+	return MergeWikiStyleTemplate(style), nil
+}
+
+// indexOfBuiltinNumber 将 outline 中的编号 (1-4) 映射为内置风格下标
+func indexOfBuiltinNumber(s string) int {
+	if len(s) == 1 && s[0] >= '1' && int(s[0]-'1') < len(builtinWikiStyleOrder) {
+		return int(s[0] - '1')
+	}
+	return -1
+}
+
+// renderWikiStyles 列出全部风格及校验结果
+func renderWikiStyles(styles []*WikiStyle, problems []string) string {
+	var sb strings.Builder
+	sb.WriteString(i18n.T("### 🎨 Wiki 书写风格\n\n"))
+	for _, style := range styles {
+		sb.WriteString(fmt.Sprintf("- `%s` [%s]", style.Name, style.Source))
+		if style.Extends != "" {
+			sb.WriteString(i18n.T(" ← 继承 `%s`", style.Extends))
+		}
+		if style.Description != "" {
+			sb.WriteString(" — " + style.Description)
+		}
+		if style.Path != "" {
+			sb.WriteString(fmt.Sprintf("\n  `%s`", style.Path))
+		}
+		sb.WriteString("\n")
+	}
+	if len(problems) == 0 {
+		sb.WriteString(i18n.T("\n✅ 全部风格校验通过\n"))
+	} else {
+		sb.WriteString(i18n.T("\n⚠️ 发现 %d 个问题：\n", len(problems)))
+		for _, p := range problems {
+			sb.WriteString("- " + p + "\n")
+		}
+	}
+	sb.WriteString(i18n.T("\n> 自定义风格放在 `.mcp-config/wiki_styles/*.md` (项目) 或 `~/.mpm/wiki_styles/*.md` (全局)，同名覆盖内置风格。\n"))
+	return sb.String()
+}
@@ -0,0 +1,88 @@
+package tools
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestWikiStyles(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("MPM_HOME", home)
+	root := t.TempDir()
+
+	globalDir := filepath.Join(home, "wiki_styles")
+	projectDir := filepath.Join(root, ".mcp-config", "wiki_styles")
+	writeProtocol(t, globalDir, "company.md", "---\ndescription: 公司统一风格\nextends: technical\n---\n# 公司文档规范\n\n## 语言风格\n- 使用\"我们\"作为主语\n\n## 术语表\n- MPM: 项目管理器\n")
+	writeProtocol(t, projectDir, "team.md", "---\nname: team\ndescription: 团队风格\nextends: company\n---\n## 格式要求\n- 每页以一句话摘要开头\n")
+	writeProtocol(t, projectDir, "loop-a.md", "---\nextends: loop-b\n---\n## A\n")
+	writeProtocol(t, projectDir, "loop-b.md", "---\nextends: loop-a\n---\n## B\n")
+	writeProtocol(t, projectDir, "orphan.md", "---\nextends: missing\n---\n## X\n")
+	writeProtocol(t, projectDir, "bad name.md", "---\nname: bad name\n---\n## X\n")
+
+	guide, err := generateStyleGuide(root, "team")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"# 公司文档规范", "使用\"我们\"作为主语", "## 写作手法", "每页以一句话摘要开头", "MPM: 项目管理器"} {
+		if !strings.Contains(guide, want) {
+			t.Fatalf("resolved style missing %q:\n%s", want, guide)
+		}
+	}
+	if strings.Contains(guide, "保持客观中立") || strings.Contains(guide, "标题层级规范") {
+		t.Fatalf("overridden sections should replace the parent's:\n%s", guide)
+	}
+	if strings.Index(guide, "## 格式要求") > strings.Index(guide, "## 术语表") {
+		t.Fatalf("overridden section should keep the parent's position:\n%s", guide)
+	}
+
+	if guide, _ := generateStyleGuide(root, "2"); !strings.Contains(guide, "教程指南风格") {
+		t.Fatalf("number should select builtin style: %s", guide)
+	}
+	if guide, _ := generateStyleGuide(root, "多用 emoji"); !strings.Contains(guide, "## 用户要求\n多用 emoji") {
+		t.Fatalf("free text should merge with default template: %s", guide)
+	}
+	if _, err := generateStyleGuide(root, "loop-a"); err == nil || !strings.Contains(err.Error(), "循环继承") {
+		t.Fatalf("cyclic inheritance should fail: %v", err)
+	}
+
+	sm := &SessionManager{ProjectRoot: root}
+	res, err := wrapWikiWriter(sm, nil)(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{
+		Name:      "wiki_writer",
+		Arguments: map[string]any{"mode": "styles"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := getTextResult(t, res)
+	for _, want := range []string{"`technical` [builtin]", "`company` [global] ← 继承 `technical` — 公司统一风格", "`team` [project]", "循环继承", "orphan 继承的 missing 不存在", "bad name.md", "发现 4 个问题"} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("styles listing missing %q:\n%s", want, text)
+		}
+	}
+
+	// extends 自身名称时继承下一层的同名风格 (项目 -> 全局 -> 内置)，而不是判为循环
+	writeProtocol(t, globalDir, "technical.md", "---\nextends: technical\n---\n## 术语表\n- 全局术语\n")
+	writeProtocol(t, projectDir, "technical.md", "---\nname: technical\nextends: technical\n---\n## 格式要求\n- 项目格式\n")
+	guide, err = generateStyleGuide(root, "technical")
+	if err != nil {
+		t.Fatalf("extends of the same name should resolve to the lower layer: %v", err)
+	}
+	for _, want := range []string{"保持客观中立", "## 术语表\n- 全局术语", "## 格式要求\n- 项目格式"} {
+		if !strings.Contains(guide, want) {
+			t.Fatalf("layered technical style missing %q:\n%s", want, guide)
+		}
+	}
+	if strings.Contains(guide, "标题层级规范") {
+		t.Fatalf("project section should replace the builtin one:\n%s", guide)
+	}
+
+	// 项目级同名文件覆盖内置风格
+	writeProtocol(t, projectDir, "blog.md", "# 我们的博客风格\n")
+	if guide, _ := generateStyleGuide(root, "blog"); strings.TrimSpace(guide) != "# 我们的博客风格" {
+		t.Fatalf("project style should override builtin: %s", guide)
+	}
+}
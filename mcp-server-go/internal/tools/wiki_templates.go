@@ -123,7 +123,7 @@ const DefaultWikiStyleTemplate = `
 - 列表对齐
 `
 
-// GetWikiStyleTemplate 获取指定的风格模板
+// GetWikiStyleTemplate 获取指定的内置风格模板（自定义风格见 loadWikiStyles）
 func GetWikiStyleTemplate(style string) string {
 	if tpl, ok := WikiStyleTemplates[style]; ok {
 		return tpl
@@ -136,10 +136,16 @@ func MergeWikiStyleTemplate(userRequirements string) string {
 	return `
 # Wiki 书写指南（个性化定制）
 
+## 用户要求
 ` + userRequirements + `
 
 ---
 
+## 基础规范（与默认模板融合）
+- 简洁准确，避免冗余
+- 代码示例带注释
+- 章节结构清晰
+
 **注**：以上要求已与默认写作规范融合，确保文档质量与个性化需求的平衡。
 `
 }